@@ -0,0 +1,11 @@
+// Package rsvp holds the files compiled into the RSVP binaries: the HTML templates and the static assets served
+// under /static/. The assets package serves them, optionally overridden file by file from a directory on disk.
+package rsvp
+
+import "embed"
+
+// EmbeddedFiles contains the templates and static directories. The templates directory is embedded with "all:" so
+// the partials, whose names start with an underscore, are included.
+//
+//go:embed all:templates static
+var EmbeddedFiles embed.FS
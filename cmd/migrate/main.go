@@ -0,0 +1,100 @@
+// Package main is the entry point for the schema migration command.
+// It applies, rolls back and reports the numbered migrations in pkg/migrations against the
+// database selected by the same DB_* environment variables the web server uses.
+//
+// Usage:
+//
+//	migrate status
+//	migrate up [-to VERSION]
+//	migrate down [-steps N]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/migrations"
+	"github.com/temirov/RSVP/pkg/services"
+	"github.com/temirov/RSVP/pkg/utils"
+)
+
+const usageText = `Usage:
+  migrate status               show applied and pending migrations
+  migrate up [-to VERSION]     apply pending migrations (all, or up to VERSION)
+  migrate down [-steps N]      roll back the last N applied migrations (default 1)
+`
+
+// main parses the subcommand and runs it against the configured database.
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usageText)
+		os.Exit(2)
+	}
+	applicationLogger := utils.NewLogger()
+	databaseConfig := config.NewDatabaseConfig(applicationLogger)
+	databaseConnection, connectionError := services.OpenDatabase(databaseConfig)
+	if connectionError != nil {
+		applicationLogger.Fatalf("Failed to connect to %s database: %v", databaseConfig.Driver, connectionError)
+	}
+	applicationLogger.Printf("Using database %s", services.DescribeDatabase(databaseConfig))
+
+	subcommandName, subcommandArguments := os.Args[1], os.Args[2:]
+	switch subcommandName {
+	case "status":
+		statusReport, reportError := migrations.Report(databaseConnection)
+		if reportError != nil {
+			applicationLogger.Fatalf("Failed to read migration status: %v", reportError)
+		}
+		currentVersion, versionError := migrations.CurrentVersion(databaseConnection)
+		if versionError != nil {
+			applicationLogger.Fatalf("Failed to read schema version: %v", versionError)
+		}
+		fmt.Printf("Schema version %d (latest known %d)\n", currentVersion, migrations.LatestVersion())
+		for _, migrationStatus := range statusReport {
+			appliedText := "pending"
+			if migrationStatus.AppliedAt != nil {
+				appliedText = "applied " + migrationStatus.AppliedAt.Format("2006-01-02 15:04:05 MST")
+			}
+			fmt.Printf("  %04d_%-30s %s\n", migrationStatus.Version, migrationStatus.Name, appliedText)
+		}
+		if currentVersion > migrations.LatestVersion() {
+			fmt.Println("WARNING: the database has migrations applied that this binary does not know.")
+		}
+
+	case "up":
+		upFlags := flag.NewFlagSet("up", flag.ExitOnError)
+		targetVersion := upFlags.Int("to", 0, "apply migrations up to and including this version (0 = latest)")
+		_ = upFlags.Parse(subcommandArguments)
+		appliedMigrations, applyError := migrations.Apply(databaseConnection, *targetVersion, applicationLogger)
+		for _, appliedMigration := range appliedMigrations {
+			fmt.Printf("applied %04d_%s\n", appliedMigration.Version, appliedMigration.Name)
+		}
+		if applyError != nil {
+			applicationLogger.Fatalf("Migration failed: %v", applyError)
+		}
+		if len(appliedMigrations) == 0 {
+			fmt.Println("No pending migrations.")
+		}
+
+	case "down":
+		downFlags := flag.NewFlagSet("down", flag.ExitOnError)
+		rollbackSteps := downFlags.Int("steps", 1, "number of migrations to roll back")
+		_ = downFlags.Parse(subcommandArguments)
+		revertedMigrations, rollbackError := migrations.Rollback(databaseConnection, *rollbackSteps, applicationLogger)
+		for _, revertedMigration := range revertedMigrations {
+			fmt.Printf("reverted %04d_%s\n", revertedMigration.Version, revertedMigration.Name)
+		}
+		if rollbackError != nil {
+			applicationLogger.Fatalf("Rollback failed: %v", rollbackError)
+		}
+		if len(revertedMigrations) == 0 {
+			fmt.Println("Nothing to roll back.")
+		}
+
+	default:
+		fmt.Fprintf(os.Stderr, "unknown subcommand %q\n\n%s", subcommandName, usageText)
+		os.Exit(2)
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/temirov/RSVP/models"
+	"github.com/temirov/RSVP/pkg/portability"
+)
+
+var accountCommands = map[string]command{
+	"export": {usage: "[-o <file>] <user>", description: "write a user's venues, events and RSVPs as a JSON account export", run: runAccountExport},
+	"import": {usage: "[-create] <user> <file>", description: "import an account export into a user (\"-\" reads standard input)", run: runAccountImport},
+}
+
+func runAccountExport(commandCtx *commandContext, arguments []string) error {
+	exportFlags := flag.NewFlagSet("account export", flag.ContinueOnError)
+	outputPath := exportFlags.String("o", "", "write to this file instead of standard output")
+	positionalArguments, err := parseCommandFlags(exportFlags, arguments, 1)
+	if err != nil {
+		return err
+	}
+	ownerUser, err := resolveUser(commandCtx.database, positionalArguments[0])
+	if err != nil {
+		return err
+	}
+	exportDocument, err := portability.Export(commandCtx.database, ownerUser)
+	if err != nil {
+		return err
+	}
+
+	var exportWriter io.Writer = commandCtx.output.writer
+	if *outputPath != "" {
+		exportFile, createError := os.Create(*outputPath)
+		if createError != nil {
+			return createError
+		}
+		defer exportFile.Close()
+		exportWriter = exportFile
+	}
+	jsonEncoder := json.NewEncoder(exportWriter)
+	jsonEncoder.SetIndent("", "  ")
+	if err := jsonEncoder.Encode(exportDocument); err != nil {
+		return err
+	}
+	if *outputPath != "" {
+		commandCtx.logger.Printf("Exported %d venue(s) and %d event(s) of %s to %s",
+			len(exportDocument.Venues), len(exportDocument.Events), ownerUser.Email, *outputPath)
+	}
+	return nil
+}
+
+func runAccountImport(commandCtx *commandContext, arguments []string) error {
+	importFlags := flag.NewFlagSet("account import", flag.ContinueOnError)
+	createMissingUser := importFlags.Bool("create", false, "create the user if no user has this email address")
+	positionalArguments, err := parseCommandFlags(importFlags, arguments, 2)
+	if err != nil {
+		return err
+	}
+	targetUser, err := resolveImportTarget(commandCtx, positionalArguments[0], *createMissingUser)
+	if err != nil {
+		return err
+	}
+
+	var documentReader io.Reader = os.Stdin
+	if documentPath := positionalArguments[1]; documentPath != "-" {
+		documentFile, openError := os.Open(documentPath)
+		if openError != nil {
+			return openError
+		}
+		defer documentFile.Close()
+		documentReader = documentFile
+	}
+	var exportDocument portability.Document
+	if err := json.NewDecoder(documentReader).Decode(&exportDocument); err != nil {
+		return fmt.Errorf("not a valid account export: %w", err)
+	}
+	importReport, err := portability.Import(commandCtx.database, &exportDocument, targetUser.ID)
+	if err != nil {
+		return err
+	}
+
+	if commandCtx.output.asJSON {
+		return commandCtx.output.json(importReport)
+	}
+	fmt.Fprintf(commandCtx.output.writer, "Imported into %s (%s): %d venue(s), %d event(s), %d RSVP(s).\n",
+		targetUser.Email, targetUser.ID, importReport.Created.Venues, importReport.Created.Events, importReport.Created.RSVPs)
+	for _, remappedID := range importReport.Remapped {
+		fmt.Fprintf(commandCtx.output.writer, "  remapped %s %s -> %s\n", remappedID.Kind, remappedID.OldID, remappedID.NewID)
+	}
+	for _, skippedRecord := range importReport.Skipped {
+		fmt.Fprintf(commandCtx.output.writer, "  skipped %s %s: %s\n", skippedRecord.Kind, skippedRecord.ID, skippedRecord.Reason)
+	}
+	return nil
+}
+
+// resolveImportTarget finds the user an import goes to, creating it by email address when allowed.
+func resolveImportTarget(commandCtx *commandContext, userIdentifier string, createMissingUser bool) (*models.User, error) {
+	targetUser, err := resolveUser(commandCtx.database, userIdentifier)
+	if err == nil || !createMissingUser || !strings.Contains(userIdentifier, "@") {
+		return targetUser, err
+	}
+	newUser := models.User{Email: userIdentifier}
+	if createError := newUser.Create(commandCtx.database); createError != nil {
+		return nil, createError
+	}
+	commandCtx.logger.Printf("Created user %s (%s)", newUser.Email, newUser.ID)
+	return &newUser, nil
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+
+	"github.com/temirov/RSVP/pkg/backup"
+)
+
+var backupCommands = map[string]command{
+	"create":  {usage: "", description: "take a consistent online backup (safe while the server runs)", run: runBackupsCreate},
+	"list":    {usage: "", description: "list backups, newest first", run: runBackupsList},
+	"prune":   {usage: "-keep <n>", description: "delete all but the newest n backups", run: runBackupsPrune},
+	"restore": {usage: "-confirm <backup>", description: "replace the database with a backup (stop the server first)", run: runBackupsRestore},
+}
+
+// newBackupManager builds a backup manager for the configured database.
+func newBackupManager(commandCtx *commandContext) *backup.Manager {
+	return backup.NewManager(commandCtx.database, commandCtx.databaseConfig, commandCtx.backupConfig, commandCtx.logger)
+}
+
+func runBackupsCreate(commandCtx *commandContext, arguments []string) error {
+	if _, err := parseCommandFlags(flag.NewFlagSet("backups create", flag.ContinueOnError), arguments, 0); err != nil {
+		return err
+	}
+	createdBackup, err := newBackupManager(commandCtx).Create()
+	if err != nil {
+		return err
+	}
+	return commandCtx.output.result(createdBackup, "Backup written to %s (%d bytes).", createdBackup.Path, createdBackup.SizeBytes)
+}
+
+func runBackupsList(commandCtx *commandContext, arguments []string) error {
+	if _, err := parseCommandFlags(flag.NewFlagSet("backups list", flag.ContinueOnError), arguments, 0); err != nil {
+		return err
+	}
+	existingBackups, err := newBackupManager(commandCtx).List()
+	if err != nil {
+		return err
+	}
+	if existingBackups == nil {
+		existingBackups = []backup.Info{}
+	}
+	tableRows := make([][]string, 0, len(existingBackups))
+	for backupIndex := range existingBackups {
+		tableRows = append(tableRows, []string{
+			existingBackups[backupIndex].Name,
+			formatTime(&existingBackups[backupIndex].CreatedAt),
+			strconv.FormatInt(existingBackups[backupIndex].SizeBytes, 10),
+		})
+	}
+	return commandCtx.output.table([]string{"NAME", "CREATED", "BYTES"}, tableRows, existingBackups)
+}
+
+func runBackupsPrune(commandCtx *commandContext, arguments []string) error {
+	pruneFlags := flag.NewFlagSet("backups prune", flag.ContinueOnError)
+	keepCount := pruneFlags.Int("keep", -1, "number of most recent backups to keep")
+	if _, err := parseCommandFlags(pruneFlags, arguments, 0); err != nil {
+		return err
+	}
+	if *keepCount < 0 {
+		return errUsage
+	}
+	removedNames, err := newBackupManager(commandCtx).Prune(*keepCount)
+	if err != nil {
+		return err
+	}
+	if removedNames == nil {
+		removedNames = []string{}
+	}
+	return commandCtx.output.result(map[string]interface{}{"removed": removedNames}, "Removed %d backup(s).", len(removedNames))
+}
+
+func runBackupsRestore(commandCtx *commandContext, arguments []string) error {
+	restoreFlags := flag.NewFlagSet("backups restore", flag.ContinueOnError)
+	isConfirmed := restoreFlags.Bool("confirm", false, "confirm that the server is stopped and the database may be replaced")
+	positionalArguments, err := parseCommandFlags(restoreFlags, arguments, 1)
+	if err != nil {
+		return err
+	}
+	backupPath, err := backup.Resolve(commandCtx.backupConfig.Directory, positionalArguments[0])
+	if err != nil {
+		return err
+	}
+	schemaVersion, err := backup.Validate(backupPath)
+	if err != nil {
+		return err
+	}
+	if !*isConfirmed {
+		return fmt.Errorf("%s is valid (schema version %d); stop the server and re-run with -confirm to restore it", backupPath, schemaVersion)
+	}
+
+	// Release our own handle on the database file before it is swapped out.
+	if sqlDatabase, sqlError := commandCtx.database.DB(); sqlError == nil {
+		_ = sqlDatabase.Close()
+	}
+	safetyCopyPath, err := backup.Restore(commandCtx.databaseConfig, backupPath)
+	if err != nil {
+		return err
+	}
+	return commandCtx.output.result(map[string]interface{}{
+		"restoredFrom":  backupPath,
+		"schemaVersion": schemaVersion,
+		"previousCopy":  safetyCopyPath,
+	}, "Restored %s (schema version %d). The previous database was kept at %s. Pending migrations run at the next server start.",
+		backupPath, schemaVersion, safetyCopyPath)
+}
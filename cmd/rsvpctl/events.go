@@ -0,0 +1,204 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/temirov/RSVP/models"
+	"gorm.io/gorm"
+)
+
+// eventRow is the JSON shape of an event.
+type eventRow struct {
+	ID            string     `json:"id"`
+	Title         string     `json:"title"`
+	OwnerID       string     `json:"ownerId"`
+	StartTime     time.Time  `json:"startTime"`
+	EndTime       time.Time  `json:"endTime"`
+	VenueID       *string    `json:"venueId,omitempty"`
+	RSVPCount     int64      `json:"rsvpCount"`
+	AnsweredCount int64      `json:"answeredCount"`
+	DeletedAt     *time.Time `json:"deletedAt,omitempty"`
+}
+
+var eventCommands = map[string]command{
+	"list":     {usage: "[-owner <user>] [-deleted]", description: "list events, optionally of one owner or only deleted ones", run: runEventsList},
+	"show":     {usage: "<event-id>", description: "show one event with RSVP counts", run: runEventsShow},
+	"transfer": {usage: "<event-id> <new-owner>", description: "move an event and its RSVPs to another user", run: runEventsTransfer},
+	"delete":   {usage: "<event-id>", description: "soft-delete an event and its RSVPs", run: runEventsDelete},
+	"restore":  {usage: "<event-id>", description: "restore a soft-deleted event and its RSVPs", run: runEventsRestore},
+}
+
+// findEvent loads an event by ID, including soft-deleted ones when includeDeleted is set.
+func findEvent(databaseConnection *gorm.DB, eventIdentifier string, includeDeleted bool) (*models.Event, error) {
+	if includeDeleted {
+		databaseConnection = databaseConnection.Unscoped()
+	}
+	var eventRecord models.Event
+	if err := eventRecord.FindByID(databaseConnection, eventIdentifier); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("event %q not found", eventIdentifier)
+		}
+		return nil, err
+	}
+	return &eventRecord, nil
+}
+
+// buildEventRow loads the RSVP counts shown next to an event.
+func buildEventRow(databaseConnection *gorm.DB, eventRecord *models.Event) (eventRow, error) {
+	row := eventRow{
+		ID:        eventRecord.ID,
+		Title:     eventRecord.Title,
+		OwnerID:   eventRecord.UserID,
+		StartTime: eventRecord.StartTime,
+		EndTime:   eventRecord.EndTime,
+		VenueID:   eventRecord.VenueID,
+		DeletedAt: deletedAtPointer(eventRecord.DeletedAt),
+	}
+	totalCount, answeredCount, err := models.CountRSVPsByEventID(databaseConnection, eventRecord.ID)
+	row.RSVPCount, row.AnsweredCount = totalCount, answeredCount
+	return row, err
+}
+
+func runEventsList(commandCtx *commandContext, arguments []string) error {
+	listFlags := flag.NewFlagSet("events list", flag.ContinueOnError)
+	ownerIdentifier := listFlags.String("owner", "", "only events owned by this user")
+	onlyDeleted := listFlags.Bool("deleted", false, "list soft-deleted events instead")
+	if _, err := parseCommandFlags(listFlags, arguments, 0); err != nil {
+		return err
+	}
+	eventQuery := commandCtx.database.Order("start_time DESC")
+	if *onlyDeleted {
+		eventQuery = eventQuery.Unscoped().Where("deleted_at IS NOT NULL")
+	}
+	if *ownerIdentifier != "" {
+		ownerRecord, err := resolveUser(commandCtx.database, *ownerIdentifier)
+		if err != nil {
+			return err
+		}
+		eventQuery = eventQuery.Where("user_id = ?", ownerRecord.ID)
+	}
+	var eventRecords []models.Event
+	if err := eventQuery.Find(&eventRecords).Error; err != nil {
+		return err
+	}
+	eventRows := make([]eventRow, 0, len(eventRecords))
+	tableRows := make([][]string, 0, len(eventRecords))
+	for eventIndex := range eventRecords {
+		row, err := buildEventRow(commandCtx.database, &eventRecords[eventIndex])
+		if err != nil {
+			return err
+		}
+		eventRows = append(eventRows, row)
+		tableRows = append(tableRows, []string{
+			row.ID, row.Title, row.OwnerID, formatTime(&row.StartTime), formatOptional(row.VenueID),
+			fmt.Sprintf("%d/%d", row.AnsweredCount, row.RSVPCount), formatTime(row.DeletedAt),
+		})
+	}
+	return commandCtx.output.table([]string{"ID", "TITLE", "OWNER", "START", "VENUE", "ANSWERED", "DELETED"}, tableRows, eventRows)
+}
+
+func runEventsShow(commandCtx *commandContext, arguments []string) error {
+	positionalArguments, err := parseCommandFlags(flag.NewFlagSet("events show", flag.ContinueOnError), arguments, 1)
+	if err != nil {
+		return err
+	}
+	eventRecord, err := findEvent(commandCtx.database, positionalArguments[0], true)
+	if err != nil {
+		return err
+	}
+	row, err := buildEventRow(commandCtx.database, eventRecord)
+	if err != nil {
+		return err
+	}
+	return commandCtx.output.record([][2]string{
+		{"ID", row.ID},
+		{"Title", row.Title},
+		{"Owner", row.OwnerID},
+		{"Start", formatTime(&row.StartTime)},
+		{"End", formatTime(&row.EndTime)},
+		{"Venue", formatOptional(row.VenueID)},
+		{"RSVPs", strconv.FormatInt(row.RSVPCount, 10)},
+		{"Answered", strconv.FormatInt(row.AnsweredCount, 10)},
+		{"Deleted", formatTime(row.DeletedAt)},
+	}, row)
+}
+
+func runEventsTransfer(commandCtx *commandContext, arguments []string) error {
+	positionalArguments, err := parseCommandFlags(flag.NewFlagSet("events transfer", flag.ContinueOnError), arguments, 2)
+	if err != nil {
+		return err
+	}
+	eventRecord, err := findEvent(commandCtx.database, positionalArguments[0], false)
+	if err != nil {
+		return err
+	}
+	newOwner, err := resolveUser(commandCtx.database, positionalArguments[1])
+	if err != nil {
+		return err
+	}
+	if eventRecord.UserID == newOwner.ID {
+		return fmt.Errorf("event %s is already owned by %s", eventRecord.ID, newOwner.Email)
+	}
+	previousOwnerID := eventRecord.UserID
+	revokedTokenCount, err := eventRecord.TransferOwnership(commandCtx.database, newOwner.ID)
+	if err != nil {
+		return err
+	}
+	if eventRecord.VenueID != nil {
+		var eventVenue models.Venue
+		if venueError := eventVenue.FindByID(commandCtx.database, *eventRecord.VenueID); venueError == nil && eventVenue.UserID != newOwner.ID {
+			commandCtx.logger.Printf("WARN: Venue %s of event %s is still owned by %s; transfer it with 'rsvpctl venues transfer' if needed",
+				eventVenue.ID, eventRecord.ID, eventVenue.UserID)
+		}
+	}
+	return commandCtx.output.result(map[string]interface{}{
+		"eventId":       eventRecord.ID,
+		"previousOwner": previousOwnerID,
+		"newOwner":      newOwner.ID,
+		"revokedTokens": revokedTokenCount,
+	}, "Transferred event %s from %s to %s (%d API token(s) revoked).", eventRecord.ID, previousOwnerID, newOwner.Email, revokedTokenCount)
+}
+
+func runEventsDelete(commandCtx *commandContext, arguments []string) error {
+	positionalArguments, err := parseCommandFlags(flag.NewFlagSet("events delete", flag.ContinueOnError), arguments, 1)
+	if err != nil {
+		return err
+	}
+	eventRecord, err := findEvent(commandCtx.database, positionalArguments[0], false)
+	if err != nil {
+		return err
+	}
+	if err := eventRecord.DeleteWithRSVPs(commandCtx.database); err != nil {
+		return err
+	}
+	return commandCtx.output.result(map[string]string{"eventId": eventRecord.ID, "status": "deleted"},
+		"Deleted event %s and its RSVPs.", eventRecord.ID)
+}
+
+func runEventsRestore(commandCtx *commandContext, arguments []string) error {
+	positionalArguments, err := parseCommandFlags(flag.NewFlagSet("events restore", flag.ContinueOnError), arguments, 1)
+	if err != nil {
+		return err
+	}
+	restoredRSVPCount, err := models.RestoreEventWithRSVPs(commandCtx.database, positionalArguments[0])
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("event %q is not deleted", positionalArguments[0])
+	}
+	if err != nil {
+		return err
+	}
+	return commandCtx.output.result(map[string]interface{}{"eventId": positionalArguments[0], "status": "restored", "restoredRsvps": restoredRSVPCount},
+		"Restored event %s and %d RSVP(s).", positionalArguments[0], restoredRSVPCount)
+}
+
+// deletedAtPointer converts a soft-delete marker to an optional timestamp.
+func deletedAtPointer(deletedAt gorm.DeletedAt) *time.Time {
+	if !deletedAt.Valid {
+		return nil
+	}
+	return &deletedAt.Time
+}
@@ -0,0 +1,161 @@
+// Package main is the entry point for rsvpctl, the operator command-line tool.
+// It works directly against the application database, selected by the same DB_* environment
+// variables the web server uses, through the shared models package.
+//
+// Usage:
+//
+//	rsvpctl [-json] <resource> <action> [flags] [arguments]
+//
+// Run rsvpctl without arguments for the list of commands.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/migrations"
+	"github.com/temirov/RSVP/pkg/services"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// errUsage signals that the command line was malformed; the command's usage is printed.
+var errUsage = errors.New("invalid usage")
+
+// commandContext carries the shared dependencies of every command.
+type commandContext struct {
+	database       *gorm.DB
+	databaseConfig config.DatabaseConfig
+	backupConfig   config.BackupConfig
+	output         *outputPrinter
+	logger         *log.Logger
+}
+
+// command is a single "<resource> <action>" entry.
+type command struct {
+	usage       string
+	description string
+	run         func(commandCtx *commandContext, arguments []string) error
+}
+
+// commandTable maps "<resource> <action>" to its implementation.
+var commandTable = map[string]command{}
+
+// registerCommands adds a resource's commands to commandTable.
+func registerCommands(resourceName string, resourceCommands map[string]command) {
+	for actionName, resourceCommand := range resourceCommands {
+		commandTable[strings.TrimSpace(resourceName+" "+actionName)] = resourceCommand
+	}
+}
+
+// main parses global flags, connects to the database and dispatches the command.
+func main() {
+	registerCommands("users", userCommands)
+	registerCommands("events", eventCommands)
+	registerCommands("venues", venueCommands)
+	registerCommands("rsvps", rsvpCommands)
+	registerCommands("stats", statsCommands)
+	registerCommands("backups", backupCommands)
+	registerCommands("account", accountCommands)
+	registerCommands("trash", trashCommands)
+
+	globalFlags := flag.NewFlagSet("rsvpctl", flag.ExitOnError)
+	jsonOutput := globalFlags.Bool("json", false, "print machine-readable JSON instead of tables")
+	globalFlags.Usage = func() { printUsage(os.Stderr) }
+	_ = globalFlags.Parse(os.Args[1:])
+
+	commandName, commandArguments := resolveCommandName(globalFlags.Args())
+	selectedCommand, isKnown := commandTable[commandName]
+	if !isKnown {
+		printUsage(os.Stderr)
+		os.Exit(2)
+	}
+
+	// Diagnostics go to stderr so stdout stays parseable in JSON mode.
+	diagnosticsLogger := log.New(os.Stderr, config.LogPrefixApp, log.LstdFlags)
+	databaseConfig := config.NewDatabaseConfig(diagnosticsLogger)
+	databaseConnection, connectionError := services.OpenDatabase(databaseConfig)
+	if connectionError != nil {
+		diagnosticsLogger.Fatalf("Failed to connect to %s database: %v", databaseConfig.Driver, connectionError)
+	}
+	databaseConnection.Logger = gormlogger.New(diagnosticsLogger, gormlogger.Config{
+		SlowThreshold:             time.Second,
+		LogLevel:                  gormlogger.Error,
+		IgnoreRecordNotFoundError: true,
+	})
+	if compatibilityError := migrations.CheckCompatibility(databaseConnection); compatibilityError != nil {
+		diagnosticsLogger.Fatalf("Refusing to run: %v", compatibilityError)
+	}
+	if pendingMigrations, pendingError := migrations.Pending(databaseConnection); pendingError == nil && len(pendingMigrations) > 0 {
+		diagnosticsLogger.Printf("WARN: %d pending migration(s); some commands may fail until they are applied", len(pendingMigrations))
+	}
+
+	commandCtx := &commandContext{
+		database:       databaseConnection,
+		databaseConfig: databaseConfig,
+		backupConfig:   config.NewBackupConfig(diagnosticsLogger, databaseConfig),
+		output:         &outputPrinter{asJSON: *jsonOutput, writer: os.Stdout},
+		logger:         diagnosticsLogger,
+	}
+	if runError := selectedCommand.run(commandCtx, commandArguments); runError != nil {
+		if errors.Is(runError, errUsage) {
+			fmt.Fprintf(os.Stderr, "usage: rsvpctl %s %s\n", commandName, selectedCommand.usage)
+			os.Exit(2)
+		}
+		fmt.Fprintf(os.Stderr, "error: %v\n", runError)
+		os.Exit(1)
+	}
+}
+
+// resolveCommandName splits positional arguments into a command key and its remaining arguments.
+// Single-word commands such as "stats" are looked up before two-word ones.
+func resolveCommandName(positionalArguments []string) (string, []string) {
+	if len(positionalArguments) == 0 {
+		return "", nil
+	}
+	if len(positionalArguments) >= 2 {
+		twoWordName := positionalArguments[0] + " " + positionalArguments[1]
+		if _, isKnown := commandTable[twoWordName]; isKnown {
+			return twoWordName, positionalArguments[2:]
+		}
+	}
+	return positionalArguments[0], positionalArguments[1:]
+}
+
+// printUsage lists every registered command.
+func printUsage(writer io.Writer) {
+	fmt.Fprintln(writer, "usage: rsvpctl [-json] <resource> <action> [flags] [arguments]")
+	fmt.Fprintln(writer)
+	commandNames := make([]string, 0, len(commandTable))
+	for commandName := range commandTable {
+		commandNames = append(commandNames, commandName)
+	}
+	sort.Strings(commandNames)
+	for _, commandName := range commandNames {
+		registeredCommand := commandTable[commandName]
+		fmt.Fprintf(writer, "  %-45s %s\n", strings.TrimSpace(commandName+" "+registeredCommand.usage), registeredCommand.description)
+	}
+	fmt.Fprintln(writer)
+	fmt.Fprintln(writer, "Users may be given by ID or email address. The database is selected with DB_DRIVER, DB_DSN and DB_NAME;")
+	fmt.Fprintln(writer, "backups use BACKUP_DIR and BACKUP_RETENTION; trash purge uses TRASH_RETENTION.")
+}
+
+// parseCommandFlags parses flags that precede positional arguments and checks the positional count.
+func parseCommandFlags(flagSet *flag.FlagSet, arguments []string, expectedPositional int) ([]string, error) {
+	flagSet.SetOutput(io.Discard)
+	if err := flagSet.Parse(arguments); err != nil {
+		return nil, errUsage
+	}
+	if flagSet.NArg() != expectedPositional {
+		return nil, errUsage
+	}
+	return flagSet.Args(), nil
+}
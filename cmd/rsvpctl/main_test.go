@@ -0,0 +1,304 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/temirov/RSVP/models"
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/testdb"
+)
+
+// testFixture holds a migrated database with two users, a venue and an event of the first user with two RSVPs.
+type testFixture struct {
+	commandCtx   *commandContext
+	output       *bytes.Buffer
+	owner        models.User
+	newOwner     models.User
+	ownerVenue   models.Venue
+	ownerEvent   models.Event
+	answeredRSVP models.RSVP
+	pendingRSVP  models.RSVP
+}
+
+func newTestFixture(t *testing.T) *testFixture {
+	t.Helper()
+	registerCommands("users", userCommands)
+	registerCommands("events", eventCommands)
+	registerCommands("venues", venueCommands)
+	registerCommands("rsvps", rsvpCommands)
+	registerCommands("stats", statsCommands)
+
+	databaseConnection := testdb.OpenMigrated(t)
+	fixture := &testFixture{output: &bytes.Buffer{}}
+	fixture.commandCtx = &commandContext{
+		database: databaseConnection,
+		output:   &outputPrinter{writer: fixture.output},
+		logger:   log.New(io.Discard, "", 0),
+	}
+	fixture.owner = models.User{Email: "owner@example.com", Name: "Owner"}
+	fixture.newOwner = models.User{Email: "new@example.com", Name: "New Owner"}
+	for _, userRecord := range []*models.User{&fixture.owner, &fixture.newOwner} {
+		if err := databaseConnection.Create(userRecord).Error; err != nil {
+			t.Fatalf("creating %s: %v", userRecord.Email, err)
+		}
+	}
+	fixture.ownerVenue = models.Venue{UserID: fixture.owner.ID, Name: "Hall"}
+	if err := databaseConnection.Create(&fixture.ownerVenue).Error; err != nil {
+		t.Fatalf("creating the venue: %v", err)
+	}
+	startTime := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	fixture.ownerEvent = models.Event{Title: "Party", StartTime: startTime, EndTime: startTime.Add(time.Hour), UserID: fixture.owner.ID, VenueID: &fixture.ownerVenue.ID}
+	if err := fixture.ownerEvent.Create(databaseConnection); err != nil {
+		t.Fatalf("creating the event: %v", err)
+	}
+	fixture.answeredRSVP = models.RSVP{Name: "Ann", EventID: fixture.ownerEvent.ID, Response: config.RSVPResponseYesPrefix + "2", ExtraGuests: 2}
+	fixture.pendingRSVP = models.RSVP{Name: "Bob", EventID: fixture.ownerEvent.ID, Response: config.RSVPResponsePending}
+	for _, rsvpRecord := range []*models.RSVP{&fixture.answeredRSVP, &fixture.pendingRSVP} {
+		if err := rsvpRecord.Create(databaseConnection); err != nil {
+			t.Fatalf("creating the RSVP of %s: %v", rsvpRecord.Name, err)
+		}
+	}
+	return fixture
+}
+
+// run dispatches the command line as main does and returns what the command printed.
+func (fixture *testFixture) run(asJSON bool, commandLine ...string) (string, error) {
+	fixture.output.Reset()
+	fixture.commandCtx.output.asJSON = asJSON
+	commandName, commandArguments := resolveCommandName(commandLine)
+	selectedCommand, isKnown := commandTable[commandName]
+	if !isKnown {
+		return "", fmt.Errorf("unknown command %q", commandName)
+	}
+	runError := selectedCommand.run(fixture.commandCtx, commandArguments)
+	return fixture.output.String(), runError
+}
+
+// mustRun runs the command line and fails the test if it returns an error.
+func (fixture *testFixture) mustRun(t *testing.T, asJSON bool, commandLine ...string) string {
+	t.Helper()
+	commandOutput, runError := fixture.run(asJSON, commandLine...)
+	if runError != nil {
+		t.Fatalf("rsvpctl %s: %v", strings.Join(commandLine, " "), runError)
+	}
+	return commandOutput
+}
+
+// decodeJSON decodes the JSON output of a command into target.
+func decodeJSON(t *testing.T, commandOutput string, target interface{}) {
+	t.Helper()
+	if err := json.Unmarshal([]byte(commandOutput), target); err != nil {
+		t.Fatalf("decoding %q: %v", commandOutput, err)
+	}
+}
+
+func TestResolveCommandName(t *testing.T) {
+	newTestFixture(t)
+	testCases := []struct {
+		positionalArguments []string
+		wantCommandName     string
+		wantArguments       []string
+	}{
+		{positionalArguments: nil, wantCommandName: ""},
+		{positionalArguments: []string{"users", "list"}, wantCommandName: "users list", wantArguments: []string{}},
+		{positionalArguments: []string{"events", "transfer", "abc123", "new@example.com"}, wantCommandName: "events transfer", wantArguments: []string{"abc123", "new@example.com"}},
+		{positionalArguments: []string{"rsvps", "list", "-event", "abc123"}, wantCommandName: "rsvps list", wantArguments: []string{"-event", "abc123"}},
+		{positionalArguments: []string{"stats"}, wantCommandName: "stats", wantArguments: []string{}},
+		{positionalArguments: []string{"stats", "extra"}, wantCommandName: "stats", wantArguments: []string{"extra"}},
+		{positionalArguments: []string{"users", "fly"}, wantCommandName: "users", wantArguments: []string{"fly"}},
+	}
+	for _, testCase := range testCases {
+		commandName, commandArguments := resolveCommandName(testCase.positionalArguments)
+		if commandName != testCase.wantCommandName || !reflect.DeepEqual(commandArguments, testCase.wantArguments) {
+			t.Errorf("resolveCommandName(%q) = %q, %q; want %q, %q", testCase.positionalArguments, commandName, commandArguments, testCase.wantCommandName, testCase.wantArguments)
+		}
+	}
+	if _, isKnown := commandTable["users"]; isKnown {
+		t.Error(`"users" without an action resolves to a command`)
+	}
+}
+
+func TestParseCommandFlags(t *testing.T) {
+	newListFlags := func() (*flag.FlagSet, *string) {
+		listFlags := flag.NewFlagSet("events list", flag.ContinueOnError)
+		return listFlags, listFlags.String("owner", "", "")
+	}
+	listFlags, ownerIdentifier := newListFlags()
+	if _, err := parseCommandFlags(listFlags, []string{"-owner", "owner@example.com"}, 0); err != nil || *ownerIdentifier != "owner@example.com" {
+		t.Errorf("parsing -owner: owner = %q, error = %v", *ownerIdentifier, err)
+	}
+	showFlags := flag.NewFlagSet("events show", flag.ContinueOnError)
+	if positionalArguments, err := parseCommandFlags(showFlags, []string{"abc123"}, 1); err != nil || !reflect.DeepEqual(positionalArguments, []string{"abc123"}) {
+		t.Errorf("parsing one positional argument = %q, %v", positionalArguments, err)
+	}
+
+	for _, testCase := range []struct {
+		name               string
+		arguments          []string
+		expectedPositional int
+	}{
+		{name: "unknown flag", arguments: []string{"-colour", "red"}, expectedPositional: 0},
+		{name: "flag without its value", arguments: []string{"-owner"}, expectedPositional: 0},
+		{name: "missing positional argument", arguments: nil, expectedPositional: 1},
+		{name: "extra positional argument", arguments: []string{"abc123", "def456"}, expectedPositional: 1},
+		{name: "flag after a positional argument", arguments: []string{"abc123", "-owner", "owner@example.com"}, expectedPositional: 1},
+	} {
+		testFlags, _ := newListFlags()
+		if _, err := parseCommandFlags(testFlags, testCase.arguments, testCase.expectedPositional); !errors.Is(err, errUsage) {
+			t.Errorf("%s: error = %v, want errUsage", testCase.name, err)
+		}
+	}
+}
+
+func TestMalformedCommandLinesAreUsageErrors(t *testing.T) {
+	fixture := newTestFixture(t)
+	for _, commandLine := range [][]string{
+		{"users", "show"},
+		{"events", "show", "a", "b"},
+		{"events", "transfer", "abc123"},
+		{"events", "list", "-deleted=maybe"},
+		{"rsvps", "list"},
+		{"stats", "extra"},
+	} {
+		if _, err := fixture.run(false, commandLine...); !errors.Is(err, errUsage) {
+			t.Errorf("rsvpctl %s: error = %v, want errUsage", strings.Join(commandLine, " "), err)
+		}
+	}
+}
+
+func TestUsersCommandsFindUsersByIDOrEmail(t *testing.T) {
+	fixture := newTestFixture(t)
+	var listedUsers []userRow
+	decodeJSON(t, fixture.mustRun(t, true, "users", "list"), &listedUsers)
+	if len(listedUsers) != 2 || listedUsers[0].Email != "new@example.com" || listedUsers[1].EventCount != 1 || listedUsers[1].VenueCount != 1 {
+		t.Errorf("users list = %+v, want both users by email with the owner's counts", listedUsers)
+	}
+
+	byEmail := fixture.mustRun(t, false, "users", "show", fixture.owner.Email)
+	byID := fixture.mustRun(t, false, "users", "show", fixture.owner.ID)
+	if byEmail != byID || !strings.Contains(byEmail, "Email:") || !strings.Contains(byEmail, fixture.owner.Email) {
+		t.Errorf("users show by email and by ID differ or lack the email:\n%s\n%s", byEmail, byID)
+	}
+	if _, err := fixture.run(false, "users", "show", "nobody@example.com"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("users show of an unknown user: error = %v, want not found", err)
+	}
+}
+
+func TestEventsCommandsTransferDeleteAndRestore(t *testing.T) {
+	fixture := newTestFixture(t)
+	databaseConnection := fixture.commandCtx.database
+
+	var ownerEvents []eventRow
+	decodeJSON(t, fixture.mustRun(t, true, "events", "list", "-owner", fixture.owner.Email), &ownerEvents)
+	if len(ownerEvents) != 1 || ownerEvents[0].ID != fixture.ownerEvent.ID || ownerEvents[0].RSVPCount != 2 || ownerEvents[0].AnsweredCount != 1 {
+		t.Fatalf("events list -owner = %+v, want the event with 1 of 2 RSVPs answered", ownerEvents)
+	}
+
+	fixture.mustRun(t, false, "events", "transfer", fixture.ownerEvent.ID, fixture.newOwner.Email)
+	var transferredEvent models.Event
+	if err := transferredEvent.FindByID(databaseConnection, fixture.ownerEvent.ID); err != nil || transferredEvent.UserID != fixture.newOwner.ID {
+		t.Fatalf("event owner after transfer = %q, %v; want %q", transferredEvent.UserID, err, fixture.newOwner.ID)
+	}
+	if _, err := fixture.run(false, "events", "transfer", fixture.ownerEvent.ID, fixture.newOwner.ID); err == nil || !strings.Contains(err.Error(), "already owned") {
+		t.Errorf("transferring to the current owner: error = %v, want already owned", err)
+	}
+
+	fixture.mustRun(t, false, "events", "delete", fixture.ownerEvent.ID)
+	var deletedEvents []eventRow
+	decodeJSON(t, fixture.mustRun(t, true, "events", "list", "-deleted"), &deletedEvents)
+	if len(deletedEvents) != 1 || deletedEvents[0].DeletedAt == nil {
+		t.Fatalf("events list -deleted = %+v, want the deleted event", deletedEvents)
+	}
+	if _, err := fixture.run(false, "events", "transfer", fixture.ownerEvent.ID, fixture.owner.Email); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("transferring a deleted event: error = %v, want not found", err)
+	}
+
+	var restoreResult struct {
+		RestoredRSVPs int64 `json:"restoredRsvps"`
+	}
+	decodeJSON(t, fixture.mustRun(t, true, "events", "restore", fixture.ownerEvent.ID), &restoreResult)
+	if restoreResult.RestoredRSVPs != 2 {
+		t.Errorf("restored %d RSVPs, want 2", restoreResult.RestoredRSVPs)
+	}
+	if _, err := fixture.run(false, "events", "restore", fixture.ownerEvent.ID); err == nil || !strings.Contains(err.Error(), "is not deleted") {
+		t.Errorf("restoring a live event: error = %v, want is not deleted", err)
+	}
+}
+
+func TestVenuesTransferMovesOnlyTheVenue(t *testing.T) {
+	fixture := newTestFixture(t)
+	fixture.mustRun(t, false, "venues", "transfer", fixture.ownerVenue.ID, fixture.newOwner.ID)
+	var newOwnerVenues []venueRow
+	decodeJSON(t, fixture.mustRun(t, true, "venues", "list", "-owner", fixture.newOwner.Email), &newOwnerVenues)
+	if len(newOwnerVenues) != 1 || newOwnerVenues[0].ID != fixture.ownerVenue.ID {
+		t.Errorf("venues of the new owner = %+v, want the transferred venue", newOwnerVenues)
+	}
+	var ownerEvent models.Event
+	if err := ownerEvent.FindByID(fixture.commandCtx.database, fixture.ownerEvent.ID); err != nil || ownerEvent.UserID != fixture.owner.ID {
+		t.Errorf("event owner after the venue transfer = %q, %v; want it unchanged", ownerEvent.UserID, err)
+	}
+}
+
+func TestRSVPsCommandsRegenerateDeleteAndRestore(t *testing.T) {
+	fixture := newTestFixture(t)
+	databaseConnection := fixture.commandCtx.database
+
+	var regenerateResult struct {
+		PreviousCode string `json:"previousCode"`
+		Code         string `json:"code"`
+	}
+	decodeJSON(t, fixture.mustRun(t, true, "rsvps", "regenerate-code", fixture.pendingRSVP.ID), &regenerateResult)
+	if regenerateResult.PreviousCode != fixture.pendingRSVP.ID || regenerateResult.Code == fixture.pendingRSVP.ID || regenerateResult.Code == "" {
+		t.Fatalf("regenerate-code = %+v, want a new code for %s", regenerateResult, fixture.pendingRSVP.ID)
+	}
+	if _, err := fixture.run(false, "rsvps", "show", fixture.pendingRSVP.ID); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("showing the old code: error = %v, want not found", err)
+	}
+	var regeneratedRSVP rsvpRow
+	decodeJSON(t, fixture.mustRun(t, true, "rsvps", "show", regenerateResult.Code), &regeneratedRSVP)
+	if regeneratedRSVP.Name != "Bob" || regeneratedRSVP.EventID != fixture.ownerEvent.ID {
+		t.Errorf("rsvps show of the new code = %+v, want Bob's RSVP", regeneratedRSVP)
+	}
+
+	fixture.mustRun(t, false, "rsvps", "delete", fixture.answeredRSVP.ID)
+	var liveRSVPs, deletedRSVPs []rsvpRow
+	decodeJSON(t, fixture.mustRun(t, true, "rsvps", "list", "-event", fixture.ownerEvent.ID), &liveRSVPs)
+	decodeJSON(t, fixture.mustRun(t, true, "rsvps", "list", "-event", fixture.ownerEvent.ID, "-deleted"), &deletedRSVPs)
+	if len(liveRSVPs) != 1 || liveRSVPs[0].Code != regenerateResult.Code || len(deletedRSVPs) != 1 || deletedRSVPs[0].Code != fixture.answeredRSVP.ID {
+		t.Fatalf("live RSVPs = %+v, deleted RSVPs = %+v; want Bob live and Ann deleted", liveRSVPs, deletedRSVPs)
+	}
+	fixture.mustRun(t, false, "rsvps", "restore", fixture.answeredRSVP.ID)
+	var restoredRSVP models.RSVP
+	if err := restoredRSVP.FindByCode(databaseConnection, fixture.answeredRSVP.ID); err != nil {
+		t.Errorf("finding the restored RSVP: %v", err)
+	}
+	if _, err := fixture.run(false, "rsvps", "restore", fixture.answeredRSVP.ID); err == nil || !strings.Contains(err.Error(), "is not deleted") {
+		t.Errorf("restoring a live RSVP: error = %v, want is not deleted", err)
+	}
+}
+
+func TestStatsCountsTheInstallation(t *testing.T) {
+	fixture := newTestFixture(t)
+	if err := fixture.commandCtx.database.Delete(&fixture.pendingRSVP).Error; err != nil {
+		t.Fatalf("deleting an RSVP: %v", err)
+	}
+	var report statsReport
+	decodeJSON(t, fixture.mustRun(t, true, "stats"), &report)
+	wantReport := statsReport{Users: 2, Events: 1, UpcomingEvents: 1, Venues: 1, RSVPs: 1, AnsweredRSVPs: 1, AttendingRSVPs: 1, ExpectedGuests: 3}
+	if report != wantReport {
+		t.Errorf("stats = %+v, want %+v", report, wantReport)
+	}
+	if textReport := fixture.mustRun(t, false, "stats"); !strings.Contains(textReport, "Expected guests:") {
+		t.Errorf("text stats lack the expected guests:\n%s", textReport)
+	}
+}
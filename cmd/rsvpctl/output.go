@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// outputPrinter renders command results either as aligned text or as JSON.
+type outputPrinter struct {
+	asJSON bool
+	writer io.Writer
+}
+
+// table prints rows under headers, or jsonValue when JSON output is requested.
+func (printer *outputPrinter) table(headers []string, rows [][]string, jsonValue interface{}) error {
+	if printer.asJSON {
+		return printer.json(jsonValue)
+	}
+	tableWriter := tabwriter.NewWriter(printer.writer, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tableWriter, strings.Join(headers, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(tableWriter, strings.Join(row, "\t"))
+	}
+	return tableWriter.Flush()
+}
+
+// record prints label/value pairs of a single object, or jsonValue when JSON output is requested.
+func (printer *outputPrinter) record(labeledValues [][2]string, jsonValue interface{}) error {
+	if printer.asJSON {
+		return printer.json(jsonValue)
+	}
+	tableWriter := tabwriter.NewWriter(printer.writer, 0, 0, 2, ' ', 0)
+	for _, labeledValue := range labeledValues {
+		fmt.Fprintf(tableWriter, "%s:\t%s\n", labeledValue[0], labeledValue[1])
+	}
+	return tableWriter.Flush()
+}
+
+// result reports the outcome of a state-changing command.
+func (printer *outputPrinter) result(jsonValue interface{}, messageFormat string, messageArguments ...interface{}) error {
+	if printer.asJSON {
+		return printer.json(jsonValue)
+	}
+	_, err := fmt.Fprintf(printer.writer, messageFormat+"\n", messageArguments...)
+	return err
+}
+
+// json writes the value as indented JSON.
+func (printer *outputPrinter) json(jsonValue interface{}) error {
+	jsonEncoder := json.NewEncoder(printer.writer)
+	jsonEncoder.SetIndent("", "  ")
+	return jsonEncoder.Encode(jsonValue)
+}
+
+// formatTime renders an optional timestamp for text output.
+func formatTime(timestamp *time.Time) string {
+	if timestamp == nil || timestamp.IsZero() {
+		return "-"
+	}
+	return timestamp.Local().Format("2006-01-02 15:04")
+}
+
+// formatOptional renders an optional string for text output.
+func formatOptional(value *string) string {
+	if value == nil || *value == "" {
+		return "-"
+	}
+	return *value
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/temirov/RSVP/models"
+	"gorm.io/gorm"
+)
+
+// rsvpRow is the JSON shape of an RSVP.
+type rsvpRow struct {
+	Code        string     `json:"code"`
+	EventID     string     `json:"eventId"`
+	Name        string     `json:"name"`
+	Response    string     `json:"response"`
+	ExtraGuests int        `json:"extraGuests"`
+	ViewCount   int        `json:"viewCount"`
+	RespondedAt *time.Time `json:"respondedAt,omitempty"`
+	DeletedAt   *time.Time `json:"deletedAt,omitempty"`
+}
+
+var rsvpCommands = map[string]command{
+	"list":            {usage: "-event <event-id> [-deleted]", description: "list the RSVPs of an event", run: runRSVPsList},
+	"show":            {usage: "<code>", description: "show one RSVP", run: runRSVPsShow},
+	"regenerate-code": {usage: "<code>", description: "issue a new RSVP code, invalidating the old link and QR code", run: runRSVPsRegenerateCode},
+	"delete":          {usage: "<code>", description: "soft-delete an RSVP", run: runRSVPsDelete},
+	"restore":         {usage: "<code>", description: "restore a soft-deleted RSVP", run: runRSVPsRestore},
+}
+
+// findRSVP loads an RSVP by code, including soft-deleted ones when includeDeleted is set.
+func findRSVP(databaseConnection *gorm.DB, rsvpCode string, includeDeleted bool) (*models.RSVP, error) {
+	if includeDeleted {
+		databaseConnection = databaseConnection.Unscoped()
+	}
+	var rsvpRecord models.RSVP
+	if err := rsvpRecord.FindByCode(databaseConnection, rsvpCode); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("RSVP %q not found", rsvpCode)
+		}
+		return nil, err
+	}
+	return &rsvpRecord, nil
+}
+
+// newRSVPRow converts an RSVP to its JSON shape.
+func newRSVPRow(rsvpRecord *models.RSVP) rsvpRow {
+	return rsvpRow{
+		Code:        rsvpRecord.ID,
+		EventID:     rsvpRecord.EventID,
+		Name:        rsvpRecord.Name,
+		Response:    rsvpRecord.Response,
+		ExtraGuests: rsvpRecord.ExtraGuests,
+		ViewCount:   rsvpRecord.ViewCount,
+		RespondedAt: rsvpRecord.RespondedAt,
+		DeletedAt:   deletedAtPointer(rsvpRecord.DeletedAt),
+	}
+}
+
+func runRSVPsList(commandCtx *commandContext, arguments []string) error {
+	listFlags := flag.NewFlagSet("rsvps list", flag.ContinueOnError)
+	eventIdentifier := listFlags.String("event", "", "event whose RSVPs to list (required)")
+	onlyDeleted := listFlags.Bool("deleted", false, "list soft-deleted RSVPs instead")
+	if _, err := parseCommandFlags(listFlags, arguments, 0); err != nil {
+		return err
+	}
+	if *eventIdentifier == "" {
+		return errUsage
+	}
+	rsvpQuery := commandCtx.database.Where("event_id = ?", *eventIdentifier).Order("name")
+	if *onlyDeleted {
+		rsvpQuery = rsvpQuery.Unscoped().Where("deleted_at IS NOT NULL")
+	}
+	var rsvpRecords []models.RSVP
+	if err := rsvpQuery.Find(&rsvpRecords).Error; err != nil {
+		return err
+	}
+	rsvpRows := make([]rsvpRow, 0, len(rsvpRecords))
+	tableRows := make([][]string, 0, len(rsvpRecords))
+	for rsvpIndex := range rsvpRecords {
+		row := newRSVPRow(&rsvpRecords[rsvpIndex])
+		rsvpRows = append(rsvpRows, row)
+		tableRows = append(tableRows, []string{
+			row.Code, row.Name, row.Response, strconv.Itoa(row.ExtraGuests), strconv.Itoa(row.ViewCount), formatTime(row.RespondedAt), formatTime(row.DeletedAt),
+		})
+	}
+	return commandCtx.output.table([]string{"CODE", "NAME", "RESPONSE", "GUESTS", "VIEWS", "RESPONDED", "DELETED"}, tableRows, rsvpRows)
+}
+
+func runRSVPsShow(commandCtx *commandContext, arguments []string) error {
+	positionalArguments, err := parseCommandFlags(flag.NewFlagSet("rsvps show", flag.ContinueOnError), arguments, 1)
+	if err != nil {
+		return err
+	}
+	rsvpRecord, err := findRSVP(commandCtx.database, positionalArguments[0], true)
+	if err != nil {
+		return err
+	}
+	row := newRSVPRow(rsvpRecord)
+	return commandCtx.output.record([][2]string{
+		{"Code", row.Code},
+		{"Event", row.EventID},
+		{"Name", row.Name},
+		{"Response", row.Response},
+		{"Extra guests", strconv.Itoa(row.ExtraGuests)},
+		{"Views", strconv.Itoa(row.ViewCount)},
+		{"Responded", formatTime(row.RespondedAt)},
+		{"Deleted", formatTime(row.DeletedAt)},
+	}, row)
+}
+
+func runRSVPsRegenerateCode(commandCtx *commandContext, arguments []string) error {
+	positionalArguments, err := parseCommandFlags(flag.NewFlagSet("rsvps regenerate-code", flag.ContinueOnError), arguments, 1)
+	if err != nil {
+		return err
+	}
+	rsvpRecord, err := findRSVP(commandCtx.database, positionalArguments[0], false)
+	if err != nil {
+		return err
+	}
+	previousCode, err := rsvpRecord.RegenerateCode(commandCtx.database)
+	if err != nil {
+		return err
+	}
+	return commandCtx.output.result(map[string]string{"previousCode": previousCode, "code": rsvpRecord.ID},
+		"RSVP %s now has code %s; the old link and QR code no longer work.", previousCode, rsvpRecord.ID)
+}
+
+func runRSVPsDelete(commandCtx *commandContext, arguments []string) error {
+	positionalArguments, err := parseCommandFlags(flag.NewFlagSet("rsvps delete", flag.ContinueOnError), arguments, 1)
+	if err != nil {
+		return err
+	}
+	rsvpRecord, err := findRSVP(commandCtx.database, positionalArguments[0], false)
+	if err != nil {
+		return err
+	}
+	if err := commandCtx.database.Delete(rsvpRecord).Error; err != nil {
+		return err
+	}
+	return commandCtx.output.result(map[string]string{"code": rsvpRecord.ID, "status": "deleted"}, "Deleted RSVP %s.", rsvpRecord.ID)
+}
+
+func runRSVPsRestore(commandCtx *commandContext, arguments []string) error {
+	positionalArguments, err := parseCommandFlags(flag.NewFlagSet("rsvps restore", flag.ContinueOnError), arguments, 1)
+	if err != nil {
+		return err
+	}
+	restoreError := models.RestoreRSVP(commandCtx.database, positionalArguments[0])
+	if errors.Is(restoreError, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("RSVP %q is not deleted", positionalArguments[0])
+	}
+	if restoreError != nil {
+		return restoreError
+	}
+	return commandCtx.output.result(map[string]string{"code": positionalArguments[0], "status": "restored"}, "Restored RSVP %s.", positionalArguments[0])
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"flag"
+	"strconv"
+	"time"
+
+	"github.com/temirov/RSVP/models"
+	"github.com/temirov/RSVP/pkg/config"
+)
+
+// statsReport is the JSON shape of the stats command.
+type statsReport struct {
+	Users           int64 `json:"users"`
+	Events          int64 `json:"events"`
+	UpcomingEvents  int64 `json:"upcomingEvents"`
+	DeletedEvents   int64 `json:"deletedEvents"`
+	Venues          int64 `json:"venues"`
+	RSVPs           int64 `json:"rsvps"`
+	AnsweredRSVPs   int64 `json:"answeredRsvps"`
+	AttendingRSVPs  int64 `json:"attendingRsvps"`
+	ExpectedGuests  int64 `json:"expectedGuests"`
+	OpenedRSVPs     int64 `json:"openedRsvps"`
+	ActiveAPITokens int64 `json:"activeApiTokens"`
+}
+
+var statsCommands = map[string]command{
+	"": {usage: "", description: "print record counts across the whole installation", run: runStats},
+}
+
+func runStats(commandCtx *commandContext, arguments []string) error {
+	if _, err := parseCommandFlags(flag.NewFlagSet("stats", flag.ContinueOnError), arguments, 0); err != nil {
+		return err
+	}
+	databaseConnection := commandCtx.database
+	var report statsReport
+	countQueries := []struct {
+		target *int64
+		run    func(target *int64) error
+	}{
+		{&report.Users, func(target *int64) error { return databaseConnection.Model(&models.User{}).Count(target).Error }},
+		{&report.Events, func(target *int64) error { return databaseConnection.Model(&models.Event{}).Count(target).Error }},
+		{&report.UpcomingEvents, func(target *int64) error {
+			return databaseConnection.Model(&models.Event{}).Where("start_time > ?", time.Now()).Count(target).Error
+		}},
+		{&report.DeletedEvents, func(target *int64) error {
+			return databaseConnection.Unscoped().Model(&models.Event{}).Where("deleted_at IS NOT NULL").Count(target).Error
+		}},
+		{&report.Venues, func(target *int64) error { return databaseConnection.Model(&models.Venue{}).Count(target).Error }},
+		{&report.RSVPs, func(target *int64) error { return databaseConnection.Model(&models.RSVP{}).Count(target).Error }},
+		{&report.AnsweredRSVPs, func(target *int64) error {
+			return databaseConnection.Model(&models.RSVP{}).
+				Where("response <> '' AND response <> ?", config.RSVPResponsePending).Count(target).Error
+		}},
+		{&report.AttendingRSVPs, func(target *int64) error {
+			return databaseConnection.Model(&models.RSVP{}).Where("response LIKE ?", config.RSVPResponseYesPrefix+"%").Count(target).Error
+		}},
+		{&report.ExpectedGuests, func(target *int64) error {
+			return databaseConnection.Model(&models.RSVP{}).Where("response LIKE ?", config.RSVPResponseYesPrefix+"%").
+				Select("COALESCE(SUM(extra_guests + 1), 0)").Scan(target).Error
+		}},
+		{&report.OpenedRSVPs, func(target *int64) error {
+			return databaseConnection.Model(&models.RSVP{}).Where("first_viewed_at IS NOT NULL").Count(target).Error
+		}},
+		{&report.ActiveAPITokens, func(target *int64) error {
+			return databaseConnection.Model(&models.APIToken{}).Where("revoked_at IS NULL").Count(target).Error
+		}},
+	}
+	for _, countQuery := range countQueries {
+		if err := countQuery.run(countQuery.target); err != nil {
+			return err
+		}
+	}
+	return commandCtx.output.record([][2]string{
+		{"Users", strconv.FormatInt(report.Users, 10)},
+		{"Events", strconv.FormatInt(report.Events, 10)},
+		{"Upcoming events", strconv.FormatInt(report.UpcomingEvents, 10)},
+		{"Deleted events", strconv.FormatInt(report.DeletedEvents, 10)},
+		{"Venues", strconv.FormatInt(report.Venues, 10)},
+		{"RSVPs", strconv.FormatInt(report.RSVPs, 10)},
+		{"Answered RSVPs", strconv.FormatInt(report.AnsweredRSVPs, 10)},
+		{"Attending RSVPs", strconv.FormatInt(report.AttendingRSVPs, 10)},
+		{"Expected guests", strconv.FormatInt(report.ExpectedGuests, 10)},
+		{"Opened RSVPs", strconv.FormatInt(report.OpenedRSVPs, 10)},
+		{"Active API tokens", strconv.FormatInt(report.ActiveAPITokens, 10)},
+	}, report)
+}
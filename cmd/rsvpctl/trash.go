@@ -0,0 +1,70 @@
+package main
+
+import (
+	"flag"
+	"strconv"
+	"time"
+
+	"github.com/temirov/RSVP/models"
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/trash"
+)
+
+var trashCommands = map[string]command{
+	"list":  {usage: "<user>", description: "list a user's deleted events, venues and RSVPs", run: runTrashList},
+	"purge": {usage: "[-older-than <duration>]", description: "permanently delete trash older than TRASH_RETENTION or the given duration", run: runTrashPurge},
+}
+
+func runTrashList(commandCtx *commandContext, arguments []string) error {
+	positionalArguments, err := parseCommandFlags(flag.NewFlagSet("trash list", flag.ContinueOnError), arguments, 1)
+	if err != nil {
+		return err
+	}
+	ownerUser, err := resolveUser(commandCtx.database, positionalArguments[0])
+	if err != nil {
+		return err
+	}
+	trashContents, err := models.FindTrash(commandCtx.database, ownerUser.ID, nil)
+	if err != nil {
+		return err
+	}
+
+	var tableRows [][]string
+	for itemIndex := range trashContents.Events {
+		trashedEvent := &trashContents.Events[itemIndex]
+		tableRows = append(tableRows, []string{config.TrashItemTypeEvent, trashedEvent.ID, trashedEvent.Title,
+			strconv.FormatInt(trashedEvent.RSVPCount, 10) + " RSVP(s)", formatTime(deletedAtPointer(trashedEvent.DeletedAt))})
+	}
+	for itemIndex := range trashContents.Venues {
+		trashedVenue := &trashContents.Venues[itemIndex]
+		tableRows = append(tableRows, []string{config.TrashItemTypeVenue, trashedVenue.ID, trashedVenue.Name,
+			trashedVenue.Address, formatTime(deletedAtPointer(trashedVenue.DeletedAt))})
+	}
+	for itemIndex := range trashContents.RSVPs {
+		trashedRSVP := &trashContents.RSVPs[itemIndex]
+		tableRows = append(tableRows, []string{config.TrashItemTypeRSVP, trashedRSVP.ID, trashedRSVP.Name,
+			trashedRSVP.EventTitle, formatTime(deletedAtPointer(trashedRSVP.DeletedAt))})
+	}
+	return commandCtx.output.table([]string{"TYPE", "ID", "NAME", "DETAILS", "DELETED"}, tableRows, trashContents)
+}
+
+func runTrashPurge(commandCtx *commandContext, arguments []string) error {
+	purgeFlags := flag.NewFlagSet("trash purge", flag.ContinueOnError)
+	olderThan := purgeFlags.Duration("older-than", 0, "purge items deleted longer ago than this (default TRASH_RETENTION)")
+	if _, err := parseCommandFlags(purgeFlags, arguments, 0); err != nil {
+		return err
+	}
+	trashConfig := config.NewTrashConfig(commandCtx.logger)
+	if *olderThan > 0 {
+		trashConfig.Retention = *olderThan
+	}
+	if trashConfig.Retention <= 0 {
+		return errUsage
+	}
+	purgeCounts, err := trash.NewPurger(commandCtx.database, trashConfig, commandCtx.logger).PurgeExpired()
+	if err != nil {
+		return err
+	}
+	return commandCtx.output.result(purgeCounts, "Purged %d event(s), %d venue(s) and %d RSVP(s) deleted before %s.",
+		purgeCounts.Events, purgeCounts.Venues, purgeCounts.RSVPs, time.Now().Add(-trashConfig.Retention).Format("2006-01-02 15:04"))
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/temirov/RSVP/models"
+	"gorm.io/gorm"
+)
+
+// userRow is the JSON shape of a user.
+type userRow struct {
+	ID           string     `json:"id"`
+	Email        string     `json:"email"`
+	Name         string     `json:"name"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	LastSignInAt *time.Time `json:"lastSignInAt,omitempty"`
+	SuspendedAt  *time.Time `json:"suspendedAt,omitempty"`
+	EventCount   int64      `json:"eventCount"`
+	VenueCount   int64      `json:"venueCount"`
+}
+
+var userCommands = map[string]command{
+	"list":      {usage: "", description: "list all users", run: runUsersList},
+	"show":      {usage: "<user>", description: "show one user with event and venue counts", run: runUsersShow},
+	"suspend":   {usage: "<user>", description: "block a user from signing in and using API tokens", run: runUsersSuspend},
+	"reinstate": {usage: "<user>", description: "lift a user's suspension", run: runUsersReinstate},
+}
+
+// resolveUser finds a user by ID or, if the identifier contains "@", by email address.
+func resolveUser(databaseConnection *gorm.DB, userIdentifier string) (*models.User, error) {
+	var userRecord models.User
+	var findError error
+	if strings.Contains(userIdentifier, "@") {
+		findError = userRecord.FindByEmail(databaseConnection, userIdentifier)
+	} else {
+		findError = userRecord.FindByID(databaseConnection, userIdentifier)
+	}
+	if errors.Is(findError, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("user %q not found", userIdentifier)
+	}
+	if findError != nil {
+		return nil, findError
+	}
+	return &userRecord, nil
+}
+
+// buildUserRow loads the ownership counts shown next to a user.
+func buildUserRow(databaseConnection *gorm.DB, userRecord *models.User) (userRow, error) {
+	row := userRow{
+		ID:           userRecord.ID,
+		Email:        userRecord.Email,
+		Name:         userRecord.Name,
+		CreatedAt:    userRecord.CreatedAt,
+		LastSignInAt: userRecord.LastSignInAt,
+		SuspendedAt:  userRecord.SuspendedAt,
+	}
+	if err := databaseConnection.Model(&models.Event{}).Where("user_id = ?", userRecord.ID).Count(&row.EventCount).Error; err != nil {
+		return row, err
+	}
+	if err := databaseConnection.Model(&models.Venue{}).Where("user_id = ?", userRecord.ID).Count(&row.VenueCount).Error; err != nil {
+		return row, err
+	}
+	return row, nil
+}
+
+func runUsersList(commandCtx *commandContext, arguments []string) error {
+	if _, err := parseCommandFlags(flag.NewFlagSet("users list", flag.ContinueOnError), arguments, 0); err != nil {
+		return err
+	}
+	var userRecords []models.User
+	if err := commandCtx.database.Order("email").Find(&userRecords).Error; err != nil {
+		return err
+	}
+	userRows := make([]userRow, 0, len(userRecords))
+	tableRows := make([][]string, 0, len(userRecords))
+	for userIndex := range userRecords {
+		row, err := buildUserRow(commandCtx.database, &userRecords[userIndex])
+		if err != nil {
+			return err
+		}
+		userRows = append(userRows, row)
+		tableRows = append(tableRows, []string{
+			row.ID, row.Email, row.Name, strconv.FormatInt(row.EventCount, 10), strconv.FormatInt(row.VenueCount, 10),
+			formatTime(&row.CreatedAt), formatTime(row.LastSignInAt), formatTime(row.SuspendedAt),
+		})
+	}
+	return commandCtx.output.table([]string{"ID", "EMAIL", "NAME", "EVENTS", "VENUES", "CREATED", "LAST SIGN-IN", "SUSPENDED"}, tableRows, userRows)
+}
+
+func runUsersShow(commandCtx *commandContext, arguments []string) error {
+	positionalArguments, err := parseCommandFlags(flag.NewFlagSet("users show", flag.ContinueOnError), arguments, 1)
+	if err != nil {
+		return err
+	}
+	userRecord, err := resolveUser(commandCtx.database, positionalArguments[0])
+	if err != nil {
+		return err
+	}
+	row, err := buildUserRow(commandCtx.database, userRecord)
+	if err != nil {
+		return err
+	}
+	return commandCtx.output.record([][2]string{
+		{"ID", row.ID},
+		{"Email", row.Email},
+		{"Name", row.Name},
+		{"Created", formatTime(&row.CreatedAt)},
+		{"Last sign-in", formatTime(row.LastSignInAt)},
+		{"Suspended", formatTime(row.SuspendedAt)},
+		{"Events", strconv.FormatInt(row.EventCount, 10)},
+		{"Venues", strconv.FormatInt(row.VenueCount, 10)},
+	}, row)
+}
+
+func runUsersSuspend(commandCtx *commandContext, arguments []string) error {
+	return setUserSuspended(commandCtx, "users suspend", arguments, true)
+}
+
+func runUsersReinstate(commandCtx *commandContext, arguments []string) error {
+	return setUserSuspended(commandCtx, "users reinstate", arguments, false)
+}
+
+// setUserSuspended suspends or reinstates the user named by the single positional argument.
+func setUserSuspended(commandCtx *commandContext, commandName string, arguments []string, suspended bool) error {
+	positionalArguments, err := parseCommandFlags(flag.NewFlagSet(commandName, flag.ContinueOnError), arguments, 1)
+	if err != nil {
+		return err
+	}
+	userRecord, err := resolveUser(commandCtx.database, positionalArguments[0])
+	if err != nil {
+		return err
+	}
+	if err := userRecord.SetSuspended(commandCtx.database, suspended); err != nil {
+		return err
+	}
+	if suspended {
+		return commandCtx.output.result(map[string]string{"userId": userRecord.ID, "status": "suspended"},
+			"Suspended user %s (%s).", userRecord.ID, userRecord.Email)
+	}
+	return commandCtx.output.result(map[string]string{"userId": userRecord.ID, "status": "active"},
+		"Reinstated user %s (%s).", userRecord.ID, userRecord.Email)
+}
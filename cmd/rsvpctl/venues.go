@@ -0,0 +1,177 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/temirov/RSVP/models"
+	"gorm.io/gorm"
+)
+
+// venueRow is the JSON shape of a venue.
+type venueRow struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Address    string     `json:"address"`
+	OwnerID    string     `json:"ownerId"`
+	EventCount int64      `json:"eventCount"`
+	DeletedAt  *time.Time `json:"deletedAt,omitempty"`
+}
+
+var venueCommands = map[string]command{
+	"list":     {usage: "[-owner <user>] [-deleted]", description: "list venues, optionally of one owner or only deleted ones", run: runVenuesList},
+	"show":     {usage: "<venue-id>", description: "show one venue", run: runVenuesShow},
+	"transfer": {usage: "<venue-id> <new-owner>", description: "assign a venue to another user", run: runVenuesTransfer},
+	"delete":   {usage: "<venue-id>", description: "soft-delete a venue and detach it from its events", run: runVenuesDelete},
+	"restore":  {usage: "<venue-id>", description: "restore a soft-deleted venue", run: runVenuesRestore},
+}
+
+// findVenue loads a venue by ID, including soft-deleted ones when includeDeleted is set.
+func findVenue(databaseConnection *gorm.DB, venueIdentifier string, includeDeleted bool) (*models.Venue, error) {
+	if includeDeleted {
+		databaseConnection = databaseConnection.Unscoped()
+	}
+	var venueRecord models.Venue
+	if err := venueRecord.FindByID(databaseConnection, venueIdentifier); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("venue %q not found", venueIdentifier)
+		}
+		return nil, err
+	}
+	return &venueRecord, nil
+}
+
+// buildVenueRow loads the number of events held at a venue.
+func buildVenueRow(databaseConnection *gorm.DB, venueRecord *models.Venue) (venueRow, error) {
+	row := venueRow{
+		ID:        venueRecord.ID,
+		Name:      venueRecord.Name,
+		Address:   venueRecord.Address,
+		OwnerID:   venueRecord.UserID,
+		DeletedAt: deletedAtPointer(venueRecord.DeletedAt),
+	}
+	err := databaseConnection.Model(&models.Event{}).Where("venue_id = ?", venueRecord.ID).Count(&row.EventCount).Error
+	return row, err
+}
+
+func runVenuesList(commandCtx *commandContext, arguments []string) error {
+	listFlags := flag.NewFlagSet("venues list", flag.ContinueOnError)
+	ownerIdentifier := listFlags.String("owner", "", "only venues owned by this user")
+	onlyDeleted := listFlags.Bool("deleted", false, "list soft-deleted venues instead")
+	if _, err := parseCommandFlags(listFlags, arguments, 0); err != nil {
+		return err
+	}
+	venueQuery := commandCtx.database.Order("name")
+	if *onlyDeleted {
+		venueQuery = venueQuery.Unscoped().Where("deleted_at IS NOT NULL")
+	}
+	if *ownerIdentifier != "" {
+		ownerRecord, err := resolveUser(commandCtx.database, *ownerIdentifier)
+		if err != nil {
+			return err
+		}
+		venueQuery = venueQuery.Where("user_id = ?", ownerRecord.ID)
+	}
+	var venueRecords []models.Venue
+	if err := venueQuery.Find(&venueRecords).Error; err != nil {
+		return err
+	}
+	venueRows := make([]venueRow, 0, len(venueRecords))
+	tableRows := make([][]string, 0, len(venueRecords))
+	for venueIndex := range venueRecords {
+		row, err := buildVenueRow(commandCtx.database, &venueRecords[venueIndex])
+		if err != nil {
+			return err
+		}
+		venueRows = append(venueRows, row)
+		tableRows = append(tableRows, []string{
+			row.ID, row.Name, row.OwnerID, strconv.FormatInt(row.EventCount, 10), formatTime(row.DeletedAt),
+		})
+	}
+	return commandCtx.output.table([]string{"ID", "NAME", "OWNER", "EVENTS", "DELETED"}, tableRows, venueRows)
+}
+
+func runVenuesShow(commandCtx *commandContext, arguments []string) error {
+	positionalArguments, err := parseCommandFlags(flag.NewFlagSet("venues show", flag.ContinueOnError), arguments, 1)
+	if err != nil {
+		return err
+	}
+	venueRecord, err := findVenue(commandCtx.database, positionalArguments[0], true)
+	if err != nil {
+		return err
+	}
+	row, err := buildVenueRow(commandCtx.database, venueRecord)
+	if err != nil {
+		return err
+	}
+	return commandCtx.output.record([][2]string{
+		{"ID", row.ID},
+		{"Name", row.Name},
+		{"Address", row.Address},
+		{"Owner", row.OwnerID},
+		{"Events", strconv.FormatInt(row.EventCount, 10)},
+		{"Deleted", formatTime(row.DeletedAt)},
+	}, row)
+}
+
+func runVenuesTransfer(commandCtx *commandContext, arguments []string) error {
+	positionalArguments, err := parseCommandFlags(flag.NewFlagSet("venues transfer", flag.ContinueOnError), arguments, 2)
+	if err != nil {
+		return err
+	}
+	venueRecord, err := findVenue(commandCtx.database, positionalArguments[0], false)
+	if err != nil {
+		return err
+	}
+	newOwner, err := resolveUser(commandCtx.database, positionalArguments[1])
+	if err != nil {
+		return err
+	}
+	if venueRecord.UserID == newOwner.ID {
+		return fmt.Errorf("venue %s is already owned by %s", venueRecord.ID, newOwner.Email)
+	}
+	previousOwnerID := venueRecord.UserID
+	if err := venueRecord.TransferOwnership(commandCtx.database, newOwner.ID); err != nil {
+		return err
+	}
+	return commandCtx.output.result(map[string]string{
+		"venueId":       venueRecord.ID,
+		"previousOwner": previousOwnerID,
+		"newOwner":      newOwner.ID,
+	}, "Transferred venue %s from %s to %s.", venueRecord.ID, previousOwnerID, newOwner.Email)
+}
+
+func runVenuesDelete(commandCtx *commandContext, arguments []string) error {
+	positionalArguments, err := parseCommandFlags(flag.NewFlagSet("venues delete", flag.ContinueOnError), arguments, 1)
+	if err != nil {
+		return err
+	}
+	venueRecord, err := findVenue(commandCtx.database, positionalArguments[0], false)
+	if err != nil {
+		return err
+	}
+	if err := venueRecord.Delete(commandCtx.database); err != nil {
+		return err
+	}
+	return commandCtx.output.result(map[string]string{"venueId": venueRecord.ID, "status": "deleted"},
+		"Deleted venue %s; its events no longer reference it.", venueRecord.ID)
+}
+
+func runVenuesRestore(commandCtx *commandContext, arguments []string) error {
+	positionalArguments, err := parseCommandFlags(flag.NewFlagSet("venues restore", flag.ContinueOnError), arguments, 1)
+	if err != nil {
+		return err
+	}
+	restoreError := models.RestoreSoftDeleted(commandCtx.database, &models.Venue{}, positionalArguments[0])
+	if errors.Is(restoreError, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("venue %q is not deleted", positionalArguments[0])
+	}
+	if restoreError != nil {
+		return restoreError
+	}
+	return commandCtx.output.result(map[string]string{"venueId": positionalArguments[0], "status": "restored"},
+		"Restored venue %s. Events detached when it was deleted must be re-linked by their owners.", positionalArguments[0])
+}
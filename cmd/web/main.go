@@ -6,69 +6,195 @@ package main
 import (
 	"context"
 	"errors"
+	"flag"
 	"fmt"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/temirov/GAuss/pkg/session"
+	"github.com/temirov/RSVP/pkg/assets"
+	"github.com/temirov/RSVP/pkg/backup"
 	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/invitation"
+	"github.com/temirov/RSVP/pkg/logging"
+	"github.com/temirov/RSVP/pkg/metrics"
+	"github.com/temirov/RSVP/pkg/realtime"
 	"github.com/temirov/RSVP/pkg/routes"
 	"github.com/temirov/RSVP/pkg/services"
 	"github.com/temirov/RSVP/pkg/templates"
-	"github.com/temirov/RSVP/pkg/utils"
+	"github.com/temirov/RSVP/pkg/tracing"
+	"github.com/temirov/RSVP/pkg/trash"
 )
 
 // main is the primary function that sets up and runs the web server.
 func main() {
-	applicationLogger := utils.NewLogger()
-	environmentConfiguration := config.NewEnvConfig(applicationLogger)
+	// Settings come from the command-line flags, the environment, the configuration file and the defaults, in that
+	// order of precedence.
+	settings, settingsError := config.LoadSettings(os.Args[1:])
+	if errors.Is(settingsError, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if settingsError != nil {
+		fmt.Fprintln(os.Stderr, settingsError)
+		os.Exit(2)
+	}
+	// The logging settings are read first so that everything after them logs in the configured format.
+	structuredLogger := logging.New(settings.LoggingConfig(), os.Stdout)
+	// Libraries that write through the standard log package end up in the structured log too.
+	slog.SetDefault(structuredLogger)
+	// The database and the background jobs take a *log.Logger; their level markers become record levels.
+	applicationLogger := logging.NewStandardLogger(structuredLogger)
+	environmentConfiguration := settings.EnvConfig()
+
+	if settings.PrintRequested() {
+		if writeError := settings.WriteEffective(os.Stdout); writeError != nil {
+			fmt.Fprintln(os.Stderr, writeError)
+			os.Exit(1)
+		}
+		for _, configurationProblem := range settings.Problems() {
+			fmt.Fprintln(os.Stderr, "invalid configuration:", configurationProblem)
+		}
+		if len(settings.Problems()) > 0 {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	// Every problem is reported before exiting, so that a broken configuration can be fixed in one go.
+	if configurationProblems := settings.Problems(); len(configurationProblems) > 0 {
+		for _, configurationProblem := range configurationProblems {
+			structuredLogger.Error("Invalid configuration", "problem", configurationProblem)
+		}
+		os.Exit(1)
+	}
+
+	// Install the trace exporter before anything that records spans; with tracing disabled this does nothing.
+	shutdownTracing, tracingError := tracing.Setup(context.Background(), environmentConfiguration.Tracing)
+	if tracingError != nil {
+		structuredLogger.Error("Setting up tracing failed", "error", tracingError)
+		os.Exit(1)
+	}
 
 	// Initialize session management using the secret key from environment configuration.
 	session.NewSession([]byte(environmentConfiguration.SessionSecret))
 
-	// Initialize the SQLite database connection and run auto-migrations for models.
-	databaseConnection := services.InitDatabase(environmentConfiguration.Database.Name, applicationLogger)
+	// Hold the database for as long as the server runs so rsvpctl cannot restore a backup over it.
+	databaseLock, lockError := backup.HoldDatabase(environmentConfiguration.Database)
+	if lockError != nil {
+		structuredLogger.Error("Locking the database failed; is a restore in progress?", "error", lockError)
+		os.Exit(1)
+	}
+	defer databaseLock.Release()
+
+	// Initialize the configured database connection and run auto-migrations for models.
+	databaseConnection := services.InitDatabase(environmentConfiguration.Database, applicationLogger)
+
+	if environmentConfiguration.Tracing.Enabled() {
+		if pluginError := databaseConnection.Use(tracing.NewGormPlugin()); pluginError != nil {
+			structuredLogger.Error("Registering the database tracing plugin failed", "error", pluginError)
+			os.Exit(1)
+		}
+	}
 
-	// Pre-parse all application template sets (layout, partials, views) exactly once at startup.
-	templates.LoadAllPrecompiledTemplates(config.TemplatesDir)
+	// Collect Prometheus metrics, including the latency of every database statement, when the endpoint is enabled.
+	var applicationMetrics *metrics.Metrics
+	if environmentConfiguration.Metrics.Enabled() {
+		applicationMetrics = metrics.New()
+		if pluginError := databaseConnection.Use(metrics.NewGormPlugin(applicationMetrics)); pluginError != nil {
+			structuredLogger.Error("Registering the database metrics plugin failed", "error", pluginError)
+			os.Exit(1)
+		}
+	}
+
+	// Templates and static files are compiled into the binary; ASSETS_OVERRIDE_DIR can replace any of them.
+	assetFiles, assetsError := assets.New(environmentConfiguration.Branding.AssetsOverrideDirectory)
+	if assetsError != nil {
+		structuredLogger.Error("Opening the assets failed", "error", assetsError)
+		os.Exit(1)
+	}
+	templateFiles, templateFilesError := assets.Templates(assetFiles)
+	if templateFilesError != nil {
+		structuredLogger.Error("Opening the templates failed", "error", templateFilesError)
+		os.Exit(1)
+	}
+	// Pre-parse all application template sets (layout, partials, views, landing page) exactly once at startup.
+	templates.LoadAllPrecompiledTemplates(templateFiles)
 
 	// Build the application context containing shared resources like database connection and logger.
 	// Handlers will access this context. Template rendering retrieves from templates.PrecompiledTemplatesMap.
 	applicationContext := &config.ApplicationContext{
 		Database:   databaseConnection,
-		Logger:     applicationLogger,
+		Logger:     structuredLogger,
 		AppBaseURL: environmentConfiguration.AppBaseURL, // Pass base URL to context
+		AppTitle:   environmentConfiguration.Branding.AppTitle,
+		Assets:     assetFiles,
+		Realtime:   realtime.NewBroker(),
+		DevAuth:    environmentConfiguration.Auth.IsEnabled(config.AuthProviderDev),
+		Invitations: invitation.NewSigner(environmentConfiguration.Invitation.SigningKey,
+			environmentConfiguration.Invitation.ExpireAfterEvent),
+		Metrics: applicationMetrics,
 	}
 
 	// Set up the HTTP request multiplexer (router).
 	httpServeMuxRouter := http.NewServeMux()
 
 	// Create the routes instance and register middleware (like authentication) and application routes.
-	routesInstance := routes.New(applicationContext, *environmentConfiguration)
+	backupManager := backup.NewManager(databaseConnection, environmentConfiguration.Database, environmentConfiguration.Backup, applicationLogger)
+	routesInstance := routes.New(applicationContext, *environmentConfiguration, backupManager)
 	routesInstance.RegisterMiddleware(httpServeMuxRouter) // Order matters: GAuss/Auth middleware first
 	routesInstance.RegisterRoutes(httpServeMuxRouter)     // Then application routes
 
 	// Configure the HTTP server details.
-	serverAddress := fmt.Sprintf("%s:%d", config.ServerHTTPAddress, config.ServerHTTPPort)
+	serverAddress := environmentConfiguration.Server.ListenAddress()
+	// Request contexts derive from serverContext so long-lived live update streams end when shutdown begins.
+	serverContext, cancelServerContext := context.WithCancel(context.Background())
+	defer cancelServerContext()
 	httpServerInstance := &http.Server{
-		Addr:    serverAddress,
-		Handler: httpServeMuxRouter, // Use the configured mux as the handler
+		Addr:              serverAddress,
+		Handler:           routesInstance.WrapHandler(httpServeMuxRouter), // The configured mux behind the global middleware
+		ReadHeaderTimeout: environmentConfiguration.Server.ReadHeaderTimeout,
+		IdleTimeout:       environmentConfiguration.Server.IdleTimeout,
+		BaseContext:       func(net.Listener) context.Context { return serverContext },
+	}
+	httpServerInstance.RegisterOnShutdown(cancelServerContext)
+
+	// A separate METRICS_ADDRESS gets its own listener serving nothing but the metrics, for scrapers on a private network.
+	var metricsServerInstance *http.Server
+	if applicationMetrics != nil && environmentConfiguration.Metrics.Address != "" {
+		metricsServeMux := http.NewServeMux()
+		metricsServeMux.Handle(config.WebMetrics, applicationMetrics.Handler(environmentConfiguration.Metrics.Token))
+		metricsServerInstance = &http.Server{
+			Addr:              environmentConfiguration.Metrics.Address,
+			Handler:           metricsServeMux,
+			ReadHeaderTimeout: time.Duration(config.MetricsReadHeaderTimeout),
+		}
+		structuredLogger.Info("Starting metrics server", "url", "http://"+environmentConfiguration.Metrics.Address+config.WebMetrics)
+		go func() {
+			listenAndServeError := metricsServerInstance.ListenAndServe()
+			if listenAndServeError != nil && !errors.Is(listenAndServeError, http.ErrServerClosed) {
+				structuredLogger.Error("Metrics server failed", "error", listenAndServeError)
+			}
+		}()
 	}
+	go backupManager.RunSchedule(serverContext)
+	go trash.NewPurger(databaseConnection, environmentConfiguration.Trash, applicationLogger).RunSchedule(serverContext)
 
 	// Start the server in a goroutine. Choose between HTTP and HTTPS based on certificate configuration.
 	if environmentConfiguration.CertificateFilePath == "" || environmentConfiguration.KeyFilePath == "" {
-		applicationLogger.Printf("Starting HTTP server on http://%s", serverAddress)
+		structuredLogger.Info("Starting HTTP server", "url", "http://"+serverAddress)
 		go func() {
 			listenAndServeError := httpServerInstance.ListenAndServe()
 			// Log errors unless it's the expected server closed error during shutdown.
 			if listenAndServeError != nil && !errors.Is(listenAndServeError, http.ErrServerClosed) {
-				applicationLogger.Printf("HTTP ListenAndServe error: %v", listenAndServeError)
+				structuredLogger.Error("HTTP server failed", "error", listenAndServeError)
 			}
 		}()
 	} else {
-		applicationLogger.Printf("Starting HTTPS server on https://%s", serverAddress)
+		structuredLogger.Info("Starting HTTPS server", "url", "https://"+serverAddress)
 		go func() {
 			listenAndServeTLSError := httpServerInstance.ListenAndServeTLS(
 				environmentConfiguration.CertificateFilePath,
@@ -76,7 +202,7 @@ func main() {
 			)
 			// Log errors unless it's the expected server closed error during shutdown.
 			if listenAndServeTLSError != nil && !errors.Is(listenAndServeTLSError, http.ErrServerClosed) {
-				applicationLogger.Printf("HTTPS ListenAndServeTLS error: %v", listenAndServeTLSError)
+				structuredLogger.Error("HTTPS server failed", "error", listenAndServeTLSError)
 			}
 		}()
 	}
@@ -87,17 +213,34 @@ func main() {
 
 	// Block until a shutdown signal is received.
 	<-shutdownSignalChannel
-	applicationLogger.Println("Shutdown signal received; commencing graceful shutdown...")
+	structuredLogger.Info("Shutdown signal received; commencing graceful shutdown")
+
+	// Fail readiness checks first and keep serving for the drain delay, so that load balancers stop routing new
+	// requests here before the listener closes.
+	routesInstance.Readiness.MarkShuttingDown()
+	if environmentConfiguration.ShutdownDrainDelay > 0 {
+		structuredLogger.Info("Draining before shutdown", "delay", environmentConfiguration.ShutdownDrainDelay)
+		time.Sleep(environmentConfiguration.ShutdownDrainDelay)
+	}
 
 	// Create a context with a timeout for the shutdown process.
-	shutdownContext, cancelShutdown := context.WithTimeout(context.Background(), config.ServerGracefulShutdownTimeout)
+	shutdownContext, cancelShutdown := context.WithTimeout(context.Background(), environmentConfiguration.Server.ShutdownTimeout)
 	defer cancelShutdown()
 
 	// Attempt to gracefully shut down the server.
 	shutdownError := httpServerInstance.Shutdown(shutdownContext)
+	if metricsServerInstance != nil {
+		if metricsShutdownError := metricsServerInstance.Shutdown(shutdownContext); metricsShutdownError != nil {
+			structuredLogger.Error("Metrics server shutdown failed", "error", metricsShutdownError)
+		}
+	}
 	if shutdownError != nil {
-		applicationLogger.Printf("Error during server shutdown: %v", shutdownError)
+		structuredLogger.Error("Server shutdown failed", "error", shutdownError)
 	} else {
-		applicationLogger.Println("Server shutdown completed successfully.")
+		structuredLogger.Info("Server shutdown completed")
+	}
+	// Export the spans still buffered, so the last requests are not missing from the traces.
+	if tracingShutdownError := shutdownTracing(shutdownContext); tracingShutdownError != nil {
+		structuredLogger.Error("Flushing traces failed", "error", tracingShutdownError)
 	}
 }
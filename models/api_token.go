@@ -0,0 +1,126 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/temirov/RSVP/pkg/config"
+	"gorm.io/gorm"
+)
+
+// APIToken is a personal access token that lets scripts and integrations act on behalf of a user
+// without a browser session. Only a SHA-256 hash of the secret is stored; the plaintext value is
+// shown to the user exactly once, when the token is created.
+type APIToken struct {
+	BaseModel
+	// UserID links the token to the user it authenticates as.
+	UserID string `gorm:"type:varchar(8);not null;index"`
+	// Name is a user-supplied label used to tell tokens apart on the management page.
+	Name string `gorm:"size:100;not null"`
+	// TokenHash is the hex-encoded SHA-256 digest of the plaintext token.
+	TokenHash string `gorm:"size:64;not null;uniqueIndex"`
+	// DisplayPrefix holds the first characters of the plaintext token so users can recognise it.
+	DisplayPrefix string `gorm:"size:16"`
+	// Scope is either config.TokenScopeRead or config.TokenScopeReadWrite.
+	Scope string `gorm:"size:20;not null"`
+	// EventID optionally restricts the token to a single event and its RSVPs.
+	EventID *string `gorm:"type:varchar(8);index"`
+	// LastUsedAt records when the token last authenticated a request.
+	LastUsedAt *time.Time
+	// LastUsedIP records the client address of the last authenticated request.
+	LastUsedIP string `gorm:"size:64"`
+	// RevokedAt is set when the user revokes the token; revoked tokens never authenticate.
+	RevokedAt *time.Time
+	// Event is the optional event the token is restricted to.
+	Event *Event `gorm:"foreignKey:EventID;references:id"`
+}
+
+// GetTableName returns the database table name for the APIToken model.
+func (apiToken *APIToken) GetTableName() string {
+	return config.TableTokens
+}
+
+// GetIDGeneratorFunc returns the unique ID generation function for the APIToken model.
+func (apiToken *APIToken) GetIDGeneratorFunc() func(int) (string, error) {
+	return GenerateBase62ID
+}
+
+// BeforeCreate is a GORM hook to ensure the token has a unique ID before creation.
+func (apiToken *APIToken) BeforeCreate(databaseTransaction *gorm.DB) error {
+	return apiToken.BaseModel.GenerateID(databaseTransaction, apiToken)
+}
+
+// IsRevoked reports whether the token has been revoked.
+func (apiToken *APIToken) IsRevoked() bool {
+	return apiToken.RevokedAt != nil
+}
+
+// AllowsWrites reports whether the token may be used for state-changing requests.
+func (apiToken *APIToken) AllowsWrites() bool {
+	return apiToken.Scope == config.TokenScopeReadWrite
+}
+
+// HashAPIToken returns the hex-encoded SHA-256 digest used to store and look up a plaintext token.
+func HashAPIToken(plaintextToken string) string {
+	tokenDigest := sha256.Sum256([]byte(plaintextToken))
+	return hex.EncodeToString(tokenDigest[:])
+}
+
+// IssueAPIToken generates a new random token secret, stores its hash for the given user and
+// returns the persisted record together with the plaintext value, which cannot be recovered later.
+func IssueAPIToken(databaseConnection *gorm.DB, ownerUserID string, tokenName string, tokenScope string, eventIdentifier *string) (*APIToken, string, error) {
+	tokenSecret, generationError := GenerateBase62ID(config.TokenSecretLength)
+	if generationError != nil {
+		return nil, "", generationError
+	}
+	plaintextToken := config.TokenPlaintextPrefix + tokenSecret
+
+	newToken := APIToken{
+		UserID:        ownerUserID,
+		Name:          tokenName,
+		TokenHash:     HashAPIToken(plaintextToken),
+		DisplayPrefix: plaintextToken[:config.TokenDisplayPrefixLen],
+		Scope:         tokenScope,
+		EventID:       eventIdentifier,
+	}
+	if creationError := databaseConnection.Create(&newToken).Error; creationError != nil {
+		return nil, "", creationError
+	}
+	return &newToken, plaintextToken, nil
+}
+
+// FindByPlaintext retrieves the token record matching the given plaintext token value.
+func (apiToken *APIToken) FindByPlaintext(databaseConnection *gorm.DB, plaintextToken string) error {
+	return databaseConnection.Where("token_hash = ?", HashAPIToken(plaintextToken)).First(apiToken).Error
+}
+
+// FindByIDAndOwner retrieves a token by its identifier ensuring it belongs to the specified owner.
+func (apiToken *APIToken) FindByIDAndOwner(databaseConnection *gorm.DB, tokenIdentifier string, ownerUserID string) error {
+	return databaseConnection.Where("id = ? AND user_id = ?", tokenIdentifier, ownerUserID).First(apiToken).Error
+}
+
+// FindAPITokensByOwner retrieves all tokens belonging to a user, newest first, with their event preloaded.
+func FindAPITokensByOwner(databaseConnection *gorm.DB, ownerUserID string) ([]APIToken, error) {
+	var ownerTokens []APIToken
+	queryError := databaseConnection.Preload("Event").Where("user_id = ?", ownerUserID).Order("created_at DESC").Find(&ownerTokens).Error
+	return ownerTokens, queryError
+}
+
+// Revoke marks the token as revoked so it can no longer authenticate requests.
+func (apiToken *APIToken) Revoke(databaseConnection *gorm.DB) error {
+	revocationTime := time.Now()
+	apiToken.RevokedAt = &revocationTime
+	return databaseConnection.Model(apiToken).Update("revoked_at", revocationTime).Error
+}
+
+// RecordUsage stores the time and client address of the latest request authenticated by the token.
+func (apiToken *APIToken) RecordUsage(databaseConnection *gorm.DB, clientAddress string) error {
+	usageTime := time.Now()
+	apiToken.LastUsedAt = &usageTime
+	apiToken.LastUsedIP = clientAddress
+	return databaseConnection.Model(apiToken).UpdateColumns(map[string]interface{}{
+		"last_used_at": usageTime,
+		"last_used_ip": clientAddress,
+	}).Error
+}
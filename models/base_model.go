@@ -86,7 +86,7 @@ func generateRandomID(desiredLength int, characterSet string) (string, error) {
 func EnsureUniqueID(databaseConnection *gorm.DB, tableName string, generateFunc func(int) (string, error)) (string, error) {
 	var generatedID string
 	var generationError error
-	var idExists bool
+	var matchingRowCount int64
 
 	// Attempt to generate a unique ID up to the maximum allowed attempts.
 	for attemptIndex := 0; attemptIndex < config.MaxIDGenerationAttempts; attemptIndex++ {
@@ -97,17 +97,16 @@ func EnsureUniqueID(databaseConnection *gorm.DB, tableName string, generateFunc
 		}
 
 		// Check if the generated ID already exists in the specified table.
-		// This query checks for the existence efficiently without retrieving the full record.
+		// A plain COUNT is used because boolean expressions in the select list are not portable across dialects.
 		queryError := databaseConnection.Table(tableName).
-			Select("count(*) > 0"). // Select a boolean indicating existence.
 			Where("id = ?", generatedID).
-			Scan(&idExists).Error // Scan the result into the idExists variable.
+			Count(&matchingRowCount).Error
 		if queryError != nil {
 			return "", queryError // Return error if the database query fails.
 		}
 
 		// If the ID does not exist, it's unique. Return it.
-		if !idExists {
+		if matchingRowCount == 0 {
 			return generatedID, nil
 		}
 		// If the ID exists, the loop continues to the next attempt.
@@ -116,3 +115,19 @@ func EnsureUniqueID(databaseConnection *gorm.DB, tableName string, generateFunc
 	// If no unique ID was found after all attempts, return an error.
 	return "", ErrFailedToGenerateUniqueID
 }
+
+// RestoreSoftDeleted clears deleted_at on the soft-deleted record of the given model type.
+// Update hooks are skipped because model is only a table selector, not a loaded record.
+// It returns gorm.ErrRecordNotFound if no soft-deleted record with that ID exists.
+func RestoreSoftDeleted(databaseConnection *gorm.DB, model interface{}, recordIdentifier string) error {
+	restoreResult := databaseConnection.Unscoped().Model(model).
+		Where("id = ? AND deleted_at IS NOT NULL", recordIdentifier).
+		UpdateColumn("deleted_at", nil)
+	if restoreResult.Error != nil {
+		return restoreResult.Error
+	}
+	if restoreResult.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
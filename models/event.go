@@ -1,6 +1,7 @@
 package models
 
 import (
+	"errors"
 	"time"
 
 	"github.com/temirov/RSVP/pkg/config"
@@ -16,9 +17,15 @@ type Event struct {
 	EndTime     time.Time `gorm:"not null"`
 	UserID      string    `gorm:"not null;index"`
 	VenueID     *string   `gorm:"type:varchar(8);index"`
-	RSVPs       []RSVP    `gorm:"foreignKey:EventID"`
-	User        User      `gorm:"foreignKey:UserID"`
-	Venue       *Venue    `gorm:"foreignKey:VenueID;references:id"`
+	// OrganizationID is set when the event belongs to an organization's workspace rather than to UserID's
+	// personal workspace. UserID then records who created the event.
+	OrganizationID *string `gorm:"type:varchar(8);index"`
+	// AcceptBareCodes lets guests respond with a link carrying only their RSVP code, as printed on cards,
+	// instead of a signed invitation token.
+	AcceptBareCodes bool   `gorm:"column:accept_bare_codes;not null;default:false"`
+	RSVPs           []RSVP `gorm:"foreignKey:EventID"`
+	User            User   `gorm:"foreignKey:UserID"`
+	Venue           *Venue `gorm:"foreignKey:VenueID;references:id"`
 }
 
 // DurationHours returns the event duration in hours.
@@ -59,10 +66,11 @@ func (eventInstance *Event) FindByIDAndOwner(databaseConnection *gorm.DB, eventI
 	return queryError
 }
 
-// FindEventsByUserID retrieves all events for a given user identifier.
+// FindEventsByUserID retrieves the events in the personal workspace of a given user identifier.
+// Events the user created for an organization belong to the organization and are not included.
 func FindEventsByUserID(databaseConnection *gorm.DB, ownerUserID string, preloadRSVPs bool, preloadVenues bool) ([]Event, error) {
 	var userEvents []Event
-	queryBuilder := databaseConnection.Where("user_id = ?", ownerUserID).Order("start_time DESC")
+	queryBuilder := databaseConnection.Where("user_id = ? AND organization_id IS NULL", ownerUserID).Order("start_time DESC")
 	if preloadRSVPs {
 		queryBuilder = queryBuilder.Preload("RSVPs")
 	}
@@ -112,3 +120,104 @@ func FindVenueIDsAssociatedWithUserEvents(databaseConnection *gorm.DB, ownerUser
 		Pluck("venue_id", &venueIdentifierList).Error
 	return venueIdentifierList, queryError
 }
+
+// TransferOwnership moves the event, together with its RSVPs, to another user.
+// API tokens of the previous owner that were restricted to this event are revoked, since they would
+// otherwise keep acting on an event their owner no longer controls. It returns the number of revoked tokens.
+func (eventInstance *Event) TransferOwnership(databaseConnection *gorm.DB, newOwnerUserID string) (int64, error) {
+	previousOwnerID := eventInstance.UserID
+	var revokedTokenCount int64
+	transactionError := databaseConnection.Transaction(func(databaseTransaction *gorm.DB) error {
+		if err := databaseTransaction.Model(eventInstance).Update("user_id", newOwnerUserID).Error; err != nil {
+			return err
+		}
+		// The new owner no longer needs a co-host membership on their own event.
+		if err := databaseTransaction.Unscoped().Where("event_id = ? AND user_id = ?", eventInstance.ID, newOwnerUserID).Delete(&EventMembership{}).Error; err != nil {
+			return err
+		}
+		revokeResult := databaseTransaction.Model(&APIToken{}).
+			Where("user_id = ? AND event_id = ? AND revoked_at IS NULL", previousOwnerID, eventInstance.ID).
+			Update("revoked_at", time.Now())
+		revokedTokenCount = revokeResult.RowsAffected
+		return revokeResult.Error
+	})
+	if transactionError != nil {
+		eventInstance.UserID = previousOwnerID
+		return 0, transactionError
+	}
+	eventInstance.UserID = newOwnerUserID
+	return revokedTokenCount, nil
+}
+
+// DeleteWithRSVPs soft-deletes the event and all of its RSVPs in one transaction.
+func (eventInstance *Event) DeleteWithRSVPs(databaseConnection *gorm.DB) error {
+	return databaseConnection.Transaction(func(databaseTransaction *gorm.DB) error {
+		if err := databaseTransaction.Where("event_id = ?", eventInstance.ID).Delete(&RSVP{}).Error; err != nil {
+			return err
+		}
+		return databaseTransaction.Delete(eventInstance).Error
+	})
+}
+
+// RestoreEventWithRSVPs restores a soft-deleted event and the RSVPs that were deleted together with it.
+// RSVPs count as deleted together with the event when their deletion time is within
+// config.CascadeRestoreWindow of the event's; RSVPs removed individually earlier stay deleted.
+// If the event's venue is in the trash as well and belongs to the same workspace, it is restored too so the event
+// keeps its venue; a venue that is gone for good is unlinked.
+// It returns the number of restored RSVPs, or gorm.ErrRecordNotFound if the event is not in the trash.
+func RestoreEventWithRSVPs(databaseConnection *gorm.DB, eventIdentifier string) (int64, error) {
+	var deletedEvent Event
+	if err := databaseConnection.Unscoped().
+		Where("id = ? AND deleted_at IS NOT NULL", eventIdentifier).
+		First(&deletedEvent).Error; err != nil {
+		return 0, err
+	}
+	var restoredRSVPCount int64
+	transactionError := databaseConnection.Transaction(func(databaseTransaction *gorm.DB) error {
+		restoreRSVPsResult := databaseTransaction.Unscoped().Model(&RSVP{}).
+			Where("event_id = ? AND deleted_at >= ?", deletedEvent.ID, cascadeWindowStart(&deletedEvent)).
+			Update("deleted_at", nil)
+		if restoreRSVPsResult.Error != nil {
+			return restoreRSVPsResult.Error
+		}
+		restoredRSVPCount = restoreRSVPsResult.RowsAffected
+		if err := restoreVenueLink(databaseTransaction, &deletedEvent); err != nil {
+			return err
+		}
+		return RestoreSoftDeleted(databaseTransaction, &Event{}, deletedEvent.ID)
+	})
+	return restoredRSVPCount, transactionError
+}
+
+// restoreVenueLink makes sure a restored event points at a live venue.
+func restoreVenueLink(databaseTransaction *gorm.DB, deletedEvent *Event) error {
+	if deletedEvent.VenueID == nil {
+		return nil
+	}
+	var linkedVenue Venue
+	findError := databaseTransaction.Unscoped().Where("id = ?", *deletedEvent.VenueID).First(&linkedVenue).Error
+	if findError != nil && !errors.Is(findError, gorm.ErrRecordNotFound) {
+		return findError
+	}
+	if findError == nil && !linkedVenue.DeletedAt.Valid {
+		return nil
+	}
+	if findError == nil && sameWorkspace(&linkedVenue, deletedEvent) {
+		return RestoreSoftDeleted(databaseTransaction, &Venue{}, linkedVenue.ID)
+	}
+	return databaseTransaction.Unscoped().Model(&Event{}).Where("id = ?", deletedEvent.ID).UpdateColumn("venue_id", nil).Error
+}
+
+// sameWorkspace reports whether the venue belongs to the event's workspace: the same organization, or the
+// personal workspace of the same owner.
+func sameWorkspace(venueRecord *Venue, eventRecord *Event) bool {
+	if venueRecord.OrganizationID != nil || eventRecord.OrganizationID != nil {
+		return venueRecord.OrganizationID != nil && eventRecord.OrganizationID != nil && *venueRecord.OrganizationID == *eventRecord.OrganizationID
+	}
+	return venueRecord.UserID == eventRecord.UserID
+}
+
+// cascadeWindowStart returns the earliest deletion time of RSVPs that count as deleted together with the event.
+func cascadeWindowStart(deletedEvent *Event) time.Time {
+	return deletedEvent.DeletedAt.Time.Add(-config.CascadeRestoreWindow)
+}
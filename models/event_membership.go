@@ -0,0 +1,320 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/temirov/RSVP/pkg/config"
+	"gorm.io/gorm"
+)
+
+// ErrCohostIsOwner is returned when an organizer tries to invite the event's owner as a co-host.
+var ErrCohostIsOwner = errors.New("the event owner cannot be invited as a co-host")
+
+// EventPermission is an action on an event, its RSVPs or its QR codes that a role may or may not allow.
+type EventPermission int
+
+const (
+	// PermissionViewEvent allows seeing the event in the events list and its response funnel.
+	PermissionViewEvent EventPermission = iota
+	// PermissionEditEvent allows changing the event's details and venue.
+	PermissionEditEvent
+	// PermissionDeleteEvent allows moving the event to the trash.
+	PermissionDeleteEvent
+	// PermissionManageCohosts allows inviting and removing co-hosts.
+	PermissionManageCohosts
+	// PermissionViewRSVPs allows reading the guest list and following its live updates.
+	PermissionViewRSVPs
+	// PermissionManageRSVPs allows adding, renaming and deleting RSVPs.
+	PermissionManageRSVPs
+	// PermissionRecordResponses allows recording a guest's response, e.g. when checking them in at the door.
+	PermissionRecordResponses
+	// PermissionViewQRCodes allows opening the QR code of an RSVP.
+	PermissionViewQRCodes
+)
+
+// rolePermissions lists what each event role may do. The owner may do everything.
+var rolePermissions = map[string][]EventPermission{
+	config.EventRoleOwner: {
+		PermissionViewEvent, PermissionEditEvent, PermissionDeleteEvent, PermissionManageCohosts,
+		PermissionViewRSVPs, PermissionManageRSVPs, PermissionRecordResponses, PermissionViewQRCodes,
+	},
+	config.EventRoleEditor: {
+		PermissionViewEvent, PermissionEditEvent,
+		PermissionViewRSVPs, PermissionManageRSVPs, PermissionRecordResponses, PermissionViewQRCodes,
+	},
+	config.EventRoleViewer: {
+		PermissionViewEvent, PermissionViewRSVPs,
+	},
+	config.EventRoleCheckIn: {
+		PermissionViewEvent, PermissionViewRSVPs, PermissionRecordResponses, PermissionViewQRCodes,
+	},
+}
+
+// RoleAllows reports whether the given event role grants the permission. An empty role grants nothing.
+func RoleAllows(eventRole string, permission EventPermission) bool {
+	for _, grantedPermission := range rolePermissions[eventRole] {
+		if grantedPermission == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// EventMembership grants a co-host a role on an event owned by someone else.
+// An invitation is addressed to an email address and stays pending (UserID is nil) until a user
+// with that address signs in, at which point it is bound to their account.
+type EventMembership struct {
+	BaseModel
+	// EventID is the shared event.
+	EventID string `gorm:"type:varchar(8);not null;index;uniqueIndex:idx_event_memberships_event_email"`
+	// UserID is the co-host's user once the invitation has been accepted, nil while it is pending.
+	UserID *string `gorm:"type:varchar(8);index"`
+	// InvitedEmail is the lower-cased address the invitation was sent to.
+	InvitedEmail string `gorm:"size:255;not null;index;uniqueIndex:idx_event_memberships_event_email"`
+	// Role is one of config.EventRoleEditor, config.EventRoleViewer or config.EventRoleCheckIn.
+	Role string `gorm:"size:20;not null"`
+	// InvitedByUserID records which organizer sent the invitation.
+	InvitedByUserID string `gorm:"type:varchar(8);not null"`
+	// AcceptedAt is set when the invitation is bound to a user account.
+	AcceptedAt *time.Time
+	Event      Event `gorm:"foreignKey:EventID;references:id"`
+	User       *User `gorm:"foreignKey:UserID;references:id"`
+}
+
+// GetTableName returns the database table name for the EventMembership model.
+func (membership *EventMembership) GetTableName() string {
+	return config.TableMembers
+}
+
+// GetIDGeneratorFunc returns the unique ID generation function for the EventMembership model.
+func (membership *EventMembership) GetIDGeneratorFunc() func(int) (string, error) {
+	return GenerateBase62ID
+}
+
+// BeforeCreate is a GORM hook to ensure the membership has a unique ID before creation.
+func (membership *EventMembership) BeforeCreate(databaseTransaction *gorm.DB) error {
+	return membership.BaseModel.GenerateID(databaseTransaction, membership)
+}
+
+// IsPending reports whether the invitation has not been accepted yet.
+func (membership *EventMembership) IsPending() bool {
+	return membership.UserID == nil
+}
+
+// BelongsTo reports whether the membership has been accepted by the given user.
+func (membership *EventMembership) BelongsTo(userIdentifier string) bool {
+	return membership.UserID != nil && *membership.UserID == userIdentifier
+}
+
+// FindByIDAndEvent retrieves a membership of the given event by its identifier.
+func (membership *EventMembership) FindByIDAndEvent(databaseConnection *gorm.DB, membershipIdentifier string, eventIdentifier string) error {
+	return databaseConnection.Where("id = ? AND event_id = ?", membershipIdentifier, eventIdentifier).First(membership).Error
+}
+
+// Remove permanently deletes the membership. Memberships are not kept in the trash, so the same
+// address can be invited again straight away.
+func (membership *EventMembership) Remove(databaseConnection *gorm.DB) error {
+	return databaseConnection.Unscoped().Delete(membership).Error
+}
+
+// NormalizeMemberEmail lower-cases and trims an email address so invitations match sign-ins regardless of case.
+func NormalizeMemberEmail(emailAddress string) string {
+	return strings.ToLower(strings.TrimSpace(emailAddress))
+}
+
+// InviteCohost invites the given address to the event with a role. Inviting an address that already has
+// a membership changes its role instead of creating a second one.
+func InviteCohost(databaseConnection *gorm.DB, eventRecord *Event, emailAddress string, cohostRole string, invitedByUserID string) (*EventMembership, error) {
+	normalizedEmail := NormalizeMemberEmail(emailAddress)
+	var eventOwner User
+	if err := eventOwner.FindByID(databaseConnection, eventRecord.UserID); err != nil {
+		return nil, err
+	}
+	if NormalizeMemberEmail(eventOwner.Email) == normalizedEmail {
+		return nil, ErrCohostIsOwner
+	}
+
+	var existingMembership EventMembership
+	findError := databaseConnection.Where("event_id = ? AND invited_email = ?", eventRecord.ID, normalizedEmail).First(&existingMembership).Error
+	if findError == nil {
+		if err := databaseConnection.Model(&existingMembership).Update("role", cohostRole).Error; err != nil {
+			return nil, err
+		}
+		return &existingMembership, nil
+	}
+	if !errors.Is(findError, gorm.ErrRecordNotFound) {
+		return nil, findError
+	}
+
+	newMembership := EventMembership{
+		EventID:         eventRecord.ID,
+		InvitedEmail:    normalizedEmail,
+		Role:            cohostRole,
+		InvitedByUserID: invitedByUserID,
+	}
+	if err := databaseConnection.Create(&newMembership).Error; err != nil {
+		return nil, err
+	}
+	return &newMembership, nil
+}
+
+// AcceptPendingInvitations binds every pending invitation addressed to the user's email to their account.
+// It runs on each authenticated request, so the common case of nothing pending is a single indexed read.
+// It returns the number of accepted invitations.
+func AcceptPendingInvitations(databaseConnection *gorm.DB, userRecord *User) (int64, error) {
+	normalizedEmail := NormalizeMemberEmail(userRecord.Email)
+	var pendingCount int64
+	if err := databaseConnection.Model(&EventMembership{}).
+		Where("invited_email = ? AND user_id IS NULL", normalizedEmail).
+		Count(&pendingCount).Error; err != nil || pendingCount == 0 {
+		return 0, err
+	}
+	acceptResult := databaseConnection.Model(&EventMembership{}).
+		Where("invited_email = ? AND user_id IS NULL", normalizedEmail).
+		Updates(map[string]interface{}{"user_id": userRecord.ID, "accepted_at": time.Now()})
+	return acceptResult.RowsAffected, acceptResult.Error
+}
+
+// EventRoleForUser returns the role the user holds on the event: config.EventRoleOwner for the owner of a
+// personal event or an owner or admin of the event's organization, config.EventRoleEditor for other
+// organization members, the accepted membership's role for a co-host, or an empty string if the user has
+// no access.
+func EventRoleForUser(databaseConnection *gorm.DB, eventRecord *Event, userIdentifier string) (string, error) {
+	if userIdentifier == "" {
+		return "", nil
+	}
+	if eventRecord.OrganizationID != nil {
+		organizationRole, err := OrganizationRoleForUser(databaseConnection, *eventRecord.OrganizationID, userIdentifier)
+		if err != nil {
+			return "", err
+		}
+		if organizationRole != "" {
+			return EventRoleForOrganizationRole(organizationRole), nil
+		}
+	} else if eventRecord.UserID == userIdentifier {
+		return config.EventRoleOwner, nil
+	}
+	var membership EventMembership
+	findError := databaseConnection.Where("event_id = ? AND user_id = ?", eventRecord.ID, userIdentifier).First(&membership).Error
+	if errors.Is(findError, gorm.ErrRecordNotFound) {
+		return "", nil
+	}
+	if findError != nil {
+		return "", findError
+	}
+	return membership.Role, nil
+}
+
+// EventRoleForOrganizationRole maps an organization role to the role it implies on the organization's events.
+func EventRoleForOrganizationRole(organizationRole string) string {
+	if OrgRoleAllowsManagement(organizationRole) {
+		return config.EventRoleOwner
+	}
+	return config.EventRoleEditor
+}
+
+// FindEventRolesForMember maps the IDs of events shared with the user to the role they hold on each.
+// Events the user owns are not included.
+func FindEventRolesForMember(databaseConnection *gorm.DB, userIdentifier string) (map[string]string, error) {
+	var acceptedMemberships []EventMembership
+	if err := databaseConnection.Where("user_id = ?", userIdentifier).Find(&acceptedMemberships).Error; err != nil {
+		return nil, err
+	}
+	memberRoles := make(map[string]string, len(acceptedMemberships))
+	for _, membership := range acceptedMemberships {
+		memberRoles[membership.EventID] = membership.Role
+	}
+	return memberRoles, nil
+}
+
+// FindEventsForMember retrieves the events in the user's personal workspace together with the events shared with them.
+func FindEventsForMember(databaseConnection *gorm.DB, userIdentifier string, preloadRSVPs bool, preloadVenues bool) ([]Event, error) {
+	var memberEvents []Event
+	sharedEventIDs := databaseConnection.Model(&EventMembership{}).Select("event_id").Where("user_id = ?", userIdentifier)
+	queryBuilder := databaseConnection.Where("(user_id = ? AND organization_id IS NULL) OR id IN (?)", userIdentifier, sharedEventIDs).Order("start_time DESC")
+	if preloadRSVPs {
+		queryBuilder = queryBuilder.Preload("RSVPs")
+	}
+	if preloadVenues {
+		queryBuilder = queryBuilder.Preload("Venue")
+	}
+	queryError := queryBuilder.Find(&memberEvents).Error
+	return memberEvents, queryError
+}
+
+// FindMembershipsByEventID lists the co-hosts of an event, accepted and pending, in invitation order.
+func FindMembershipsByEventID(databaseConnection *gorm.DB, eventIdentifier string) ([]EventMembership, error) {
+	var eventMemberships []EventMembership
+	queryError := databaseConnection.Preload("User").Where("event_id = ?", eventIdentifier).Order("created_at ASC").Find(&eventMemberships).Error
+	return eventMemberships, queryError
+}
+
+// FindMemberUserIDsByEventID returns the user IDs of the event's accepted co-hosts.
+func FindMemberUserIDsByEventID(databaseConnection *gorm.DB, eventIdentifier string) ([]string, error) {
+	var memberUserIDs []string
+	queryError := databaseConnection.Model(&EventMembership{}).
+		Where("event_id = ? AND user_id IS NOT NULL", eventIdentifier).
+		Pluck("user_id", &memberUserIDs).Error
+	return memberUserIDs, queryError
+}
+
+// FindViewerUserIDsForEvent returns the IDs of every user EventRoleForUser grants a role on the event, each once:
+// the owner of a personal event or the members of the event's organization, and the accepted co-hosts.
+func FindViewerUserIDsForEvent(databaseConnection *gorm.DB, eventRecord *Event) ([]string, error) {
+	viewerUserIDs := []string{}
+	if eventRecord.OrganizationID != nil {
+		organizationUserIDs, err := FindOrganizationMemberUserIDs(databaseConnection, *eventRecord.OrganizationID)
+		if err != nil {
+			return nil, err
+		}
+		viewerUserIDs = append(viewerUserIDs, organizationUserIDs...)
+	} else {
+		viewerUserIDs = append(viewerUserIDs, eventRecord.UserID)
+	}
+	cohostUserIDs, err := FindMemberUserIDsByEventID(databaseConnection, eventRecord.ID)
+	if err != nil {
+		return nil, err
+	}
+	seenUserIDs := make(map[string]bool, len(viewerUserIDs)+len(cohostUserIDs))
+	uniqueUserIDs := viewerUserIDs[:0]
+	for _, viewerUserID := range append(viewerUserIDs, cohostUserIDs...) {
+		if !seenUserIDs[viewerUserID] {
+			seenUserIDs[viewerUserID] = true
+			uniqueUserIDs = append(uniqueUserIDs, viewerUserID)
+		}
+	}
+	return uniqueUserIDs, nil
+}
+
+// FindVenueIDsEditableByMember returns the IDs of venues used by events the user may edit as a co-host or as a
+// member of the event's organization, with roles decided by EventRoleForUser, so everyone who may edit a shared
+// event can keep its venue details up to date.
+func FindVenueIDsEditableByMember(databaseConnection *gorm.DB, userIdentifier string) ([]string, error) {
+	sharedEventIDs := databaseConnection.Model(&EventMembership{}).Select("event_id").Where("user_id = ?", userIdentifier)
+	memberOrganizationIDs := databaseConnection.Model(&OrganizationMember{}).Select("organization_id").Where("user_id = ?", userIdentifier)
+	var candidateEvents []Event
+	if err := databaseConnection.
+		Where("venue_id IS NOT NULL AND (id IN (?) OR organization_id IN (?))", sharedEventIDs, memberOrganizationIDs).
+		Find(&candidateEvents).Error; err != nil {
+		return nil, err
+	}
+	editableVenueIDs := []string{}
+	seenVenueIDs := make(map[string]bool, len(candidateEvents))
+	for eventIndex := range candidateEvents {
+		candidateEvent := &candidateEvents[eventIndex]
+		if seenVenueIDs[*candidateEvent.VenueID] {
+			continue
+		}
+		eventRole, err := EventRoleForUser(databaseConnection, candidateEvent, userIdentifier)
+		if err != nil {
+			return nil, err
+		}
+		if RoleAllows(eventRole, PermissionEditEvent) {
+			seenVenueIDs[*candidateEvent.VenueID] = true
+			editableVenueIDs = append(editableVenueIDs, *candidateEvent.VenueID)
+		}
+	}
+	return editableVenueIDs, nil
+}
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/temirov/RSVP/pkg/config"
+	"gorm.io/gorm"
+)
+
+// ErrLoginLinkInvalid is returned when a sign-in link is unknown, expired or already used.
+var ErrLoginLinkInvalid = errors.New("this sign-in link is invalid or has expired")
+
+// LoginLink is a one-time sign-in link sent by email. Like API tokens, only a SHA-256 hash of the secret
+// is stored; the plaintext value exists only in the email.
+type LoginLink struct {
+	BaseModel
+	// Email is the lower-cased address the link was sent to and signs in as.
+	Email string `gorm:"size:255;not null;index"`
+	// TokenHash is the hex-encoded SHA-256 digest of the secret in the link.
+	TokenHash string    `gorm:"size:64;not null;uniqueIndex"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+	// UsedAt is set when the link signs someone in; used links never work again.
+	UsedAt *time.Time
+}
+
+// GetTableName returns the database table name for the LoginLink model.
+func (loginLink *LoginLink) GetTableName() string {
+	return config.TableLogins
+}
+
+// GetIDGeneratorFunc returns the unique ID generation function for the LoginLink model.
+func (loginLink *LoginLink) GetIDGeneratorFunc() func(int) (string, error) {
+	return GenerateBase62ID
+}
+
+// BeforeCreate is a GORM hook to ensure the link has a unique ID before creation.
+func (loginLink *LoginLink) BeforeCreate(databaseTransaction *gorm.DB) error {
+	return loginLink.BaseModel.GenerateID(databaseTransaction, loginLink)
+}
+
+// IssueLoginLink stores a new sign-in link for the email address, valid for the given lifetime, and returns
+// the plaintext secret to put in the link. Expired links are removed along the way.
+func IssueLoginLink(databaseConnection *gorm.DB, emailAddress string, linkLifetime time.Duration) (string, error) {
+	linkSecret, generationError := GenerateBase62ID(config.LoginLinkSecretLength)
+	if generationError != nil {
+		return "", generationError
+	}
+	issuedAt := time.Now()
+	if err := databaseConnection.Unscoped().Where("expires_at < ?", issuedAt).Delete(&LoginLink{}).Error; err != nil {
+		return "", err
+	}
+	newLink := LoginLink{
+		Email:     NormalizeMemberEmail(emailAddress),
+		TokenHash: HashAPIToken(linkSecret),
+		ExpiresAt: issuedAt.Add(linkLifetime),
+	}
+	if err := databaseConnection.Create(&newLink).Error; err != nil {
+		return "", err
+	}
+	return linkSecret, nil
+}
+
+// ConsumeLoginLink marks the link with the given secret as used and returns the email address it signs in as.
+// A link can be consumed once, and only before it expires; otherwise ErrLoginLinkInvalid is returned.
+func ConsumeLoginLink(databaseConnection *gorm.DB, linkSecret string) (string, error) {
+	var loginLink LoginLink
+	findError := databaseConnection.Where("token_hash = ?", HashAPIToken(linkSecret)).First(&loginLink).Error
+	if errors.Is(findError, gorm.ErrRecordNotFound) {
+		return "", ErrLoginLinkInvalid
+	}
+	if findError != nil {
+		return "", findError
+	}
+	usedAt := time.Now()
+	consumeResult := databaseConnection.Model(&LoginLink{}).
+		Where("id = ? AND used_at IS NULL AND expires_at > ?", loginLink.ID, usedAt).
+		UpdateColumn("used_at", usedAt)
+	if consumeResult.Error != nil {
+		return "", consumeResult.Error
+	}
+	if consumeResult.RowsAffected == 0 {
+		return "", ErrLoginLinkInvalid
+	}
+	return loginLink.Email, nil
+}
@@ -0,0 +1,342 @@
+package models_test
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/temirov/RSVP/models"
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/testdb"
+	"gorm.io/gorm"
+)
+
+// createTestEvent stores a user and a personal event owned by them.
+func createTestEvent(t *testing.T, databaseConnection *gorm.DB, ownerEmail string) (models.User, models.Event) {
+	t.Helper()
+	eventOwner := models.User{Email: ownerEmail, Name: "Owner"}
+	if err := databaseConnection.Create(&eventOwner).Error; err != nil {
+		t.Fatalf("creating the owner: %v", err)
+	}
+	startTime := time.Date(2026, time.June, 1, 18, 0, 0, 0, time.UTC)
+	eventRecord := models.Event{Title: "Summer party", StartTime: startTime, EndTime: startTime.Add(4 * time.Hour), UserID: eventOwner.ID}
+	if err := eventRecord.Create(databaseConnection); err != nil {
+		t.Fatalf("creating the event: %v", err)
+	}
+	return eventOwner, eventRecord
+}
+
+func TestRSVPCodesAreGeneratedAndFound(t *testing.T) {
+	databaseConnection := testdb.OpenMigrated(t)
+	_, eventRecord := createTestEvent(t, databaseConnection, "owner@example.com")
+	rsvpCodePattern := regexp.MustCompile(config.RSVPCodeValidationRegexPattern)
+	seenCodes := make(map[string]bool)
+	for guestIndex, guestName := range []string{"Carol", "Alice", "Bob"} {
+		rsvpRecord := models.RSVP{Name: guestName, EventID: eventRecord.ID, Response: config.RSVPResponsePending}
+		if err := rsvpRecord.Create(databaseConnection); err != nil {
+			t.Fatalf("creating RSVP %d: %v", guestIndex+1, err)
+		}
+		if !rsvpCodePattern.MatchString(rsvpRecord.ID) || seenCodes[rsvpRecord.ID] {
+			t.Errorf("RSVP code %q is not a fresh valid code", rsvpRecord.ID)
+		}
+		seenCodes[rsvpRecord.ID] = true
+		var foundRSVP models.RSVP
+		if err := foundRSVP.FindByCode(databaseConnection, rsvpRecord.ID); err != nil || foundRSVP.Name != guestName {
+			t.Errorf("FindByCode(%q) = %q, %v, want %s", rsvpRecord.ID, foundRSVP.Name, err, guestName)
+		}
+	}
+
+	eventRSVPs, err := models.FindRSVPsByEventID(databaseConnection, eventRecord.ID)
+	if err != nil || len(eventRSVPs) != 3 || eventRSVPs[0].Name != "Alice" || eventRSVPs[2].Name != "Carol" {
+		t.Errorf("FindRSVPsByEventID() = %v, %v, want the three RSVPs by name", eventRSVPs, err)
+	}
+	answeredRSVP := eventRSVPs[0]
+	answeredRSVP.Response = config.RSVPResponseYesPlusOne
+	if err := answeredRSVP.Save(databaseConnection); err != nil {
+		t.Fatalf("saving the answer: %v", err)
+	}
+	if totalCount, answeredCount, err := models.CountRSVPsByEventID(databaseConnection, eventRecord.ID); err != nil || totalCount != 3 || answeredCount != 1 {
+		t.Errorf("CountRSVPsByEventID() = %d, %d, %v, want 3, 1", totalCount, answeredCount, err)
+	}
+}
+
+func TestRecordViewCountsViews(t *testing.T) {
+	databaseConnection := testdb.OpenMigrated(t)
+	_, eventRecord := createTestEvent(t, databaseConnection, "owner@example.com")
+	rsvpRecord := models.RSVP{Name: "Guest", EventID: eventRecord.ID, Response: config.RSVPResponsePending}
+	if err := rsvpRecord.Create(databaseConnection); err != nil {
+		t.Fatalf("creating the RSVP: %v", err)
+	}
+	firstView := time.Date(2026, time.May, 1, 9, 0, 0, 0, time.UTC)
+	for viewIndex := 0; viewIndex < 2; viewIndex++ {
+		if err := rsvpRecord.RecordView(databaseConnection, firstView.Add(time.Duration(viewIndex)*time.Hour)); err != nil {
+			t.Fatalf("RecordView() error = %v", err)
+		}
+	}
+	var storedRSVP models.RSVP
+	if err := storedRSVP.FindByCode(databaseConnection, rsvpRecord.ID); err != nil {
+		t.Fatalf("FindByCode() error = %v", err)
+	}
+	if storedRSVP.ViewCount != 2 || storedRSVP.FirstViewedAt == nil || !storedRSVP.FirstViewedAt.Equal(firstView) ||
+		storedRSVP.LastViewedAt == nil || !storedRSVP.LastViewedAt.Equal(firstView.Add(time.Hour)) {
+		t.Errorf("stored views = %d from %v to %v, want 2 from %v to %v",
+			storedRSVP.ViewCount, storedRSVP.FirstViewedAt, storedRSVP.LastViewedAt, firstView, firstView.Add(time.Hour))
+	}
+}
+
+func TestReissueLinkRevokesOldLinks(t *testing.T) {
+	databaseConnection := testdb.OpenMigrated(t)
+	_, eventRecord := createTestEvent(t, databaseConnection, "owner@example.com")
+	rsvpRecord := models.RSVP{Name: "Guest", EventID: eventRecord.ID, Response: config.RSVPResponsePending}
+	if err := rsvpRecord.Create(databaseConnection); err != nil {
+		t.Fatalf("creating the RSVP: %v", err)
+	}
+	originalCode := rsvpRecord.ID
+
+	if previousCode, err := rsvpRecord.ReissueLink(databaseConnection, false); err != nil || previousCode != originalCode || rsvpRecord.ID != originalCode {
+		t.Fatalf("ReissueLink(false) = %q, %v, want the code kept", previousCode, err)
+	}
+	var storedRSVP models.RSVP
+	if err := storedRSVP.FindByCode(databaseConnection, originalCode); err != nil || storedRSVP.LinkVersion != 1 {
+		t.Errorf("link version after reissuing = %d, %v, want 1", storedRSVP.LinkVersion, err)
+	}
+
+	if previousCode, err := rsvpRecord.ReissueLink(databaseConnection, true); err != nil || previousCode != originalCode || rsvpRecord.ID == originalCode {
+		t.Fatalf("ReissueLink(true) = %q, %v with new code %q, want the code replaced", previousCode, err, rsvpRecord.ID)
+	}
+	if err := new(models.RSVP).FindByCode(databaseConnection, originalCode); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("FindByCode(old code) error = %v, want ErrRecordNotFound", err)
+	}
+	var reissuedRSVP models.RSVP
+	if err := reissuedRSVP.FindByCode(databaseConnection, rsvpRecord.ID); err != nil || reissuedRSVP.LinkVersion != 2 {
+		t.Errorf("new code has link version %d, %v, want 2", reissuedRSVP.LinkVersion, err)
+	}
+}
+
+func TestLoginLinksWorkOnceBeforeExpiring(t *testing.T) {
+	databaseConnection := testdb.OpenMigrated(t)
+	linkSecret, err := models.IssueLoginLink(databaseConnection, " Ada@Example.com ", time.Hour)
+	if err != nil {
+		t.Fatalf("IssueLoginLink() error = %v", err)
+	}
+	if signedInAddress, err := models.ConsumeLoginLink(databaseConnection, linkSecret); err != nil || signedInAddress != "ada@example.com" {
+		t.Fatalf("ConsumeLoginLink() = %q, %v, want ada@example.com", signedInAddress, err)
+	}
+	if _, err := models.ConsumeLoginLink(databaseConnection, linkSecret); !errors.Is(err, models.ErrLoginLinkInvalid) {
+		t.Errorf("second ConsumeLoginLink() error = %v, want ErrLoginLinkInvalid", err)
+	}
+	if _, err := models.ConsumeLoginLink(databaseConnection, "unknown-secret"); !errors.Is(err, models.ErrLoginLinkInvalid) {
+		t.Errorf("ConsumeLoginLink(unknown) error = %v, want ErrLoginLinkInvalid", err)
+	}
+
+	expiredSecret, err := models.IssueLoginLink(databaseConnection, "ada@example.com", -time.Minute)
+	if err != nil {
+		t.Fatalf("IssueLoginLink() error = %v", err)
+	}
+	if _, err := models.ConsumeLoginLink(databaseConnection, expiredSecret); !errors.Is(err, models.ErrLoginLinkInvalid) {
+		t.Errorf("ConsumeLoginLink(expired) error = %v, want ErrLoginLinkInvalid", err)
+	}
+}
+
+func TestEventRoleForUser(t *testing.T) {
+	databaseConnection := testdb.OpenMigrated(t)
+	eventOwner, eventRecord := createTestEvent(t, databaseConnection, "owner@example.com")
+	cohost := models.User{Email: "cohost@example.com"}
+	stranger := models.User{Email: "stranger@example.com"}
+	for _, userRecord := range []*models.User{&cohost, &stranger} {
+		if err := databaseConnection.Create(userRecord).Error; err != nil {
+			t.Fatalf("creating %s: %v", userRecord.Email, err)
+		}
+	}
+	if _, err := models.InviteCohost(databaseConnection, &eventRecord, "owner@example.com", config.EventRoleEditor, eventOwner.ID); !errors.Is(err, models.ErrCohostIsOwner) {
+		t.Errorf("InviteCohost(owner) error = %v, want ErrCohostIsOwner", err)
+	}
+	if _, err := models.InviteCohost(databaseConnection, &eventRecord, "CoHost@example.com", config.EventRoleViewer, eventOwner.ID); err != nil {
+		t.Fatalf("InviteCohost() error = %v", err)
+	}
+
+	assertRole := func(userIdentifier string, expectedRole string) {
+		t.Helper()
+		if eventRole, err := models.EventRoleForUser(databaseConnection, &eventRecord, userIdentifier); err != nil || eventRole != expectedRole {
+			t.Errorf("EventRoleForUser(%q) = %q, %v, want %q", userIdentifier, eventRole, err, expectedRole)
+		}
+	}
+	assertRole(eventOwner.ID, config.EventRoleOwner)
+	// The invitation counts once it is accepted.
+	assertRole(cohost.ID, "")
+	if acceptedCount, err := models.AcceptPendingInvitations(databaseConnection, &cohost); err != nil || acceptedCount != 1 {
+		t.Fatalf("AcceptPendingInvitations() = %d, %v, want 1", acceptedCount, err)
+	}
+	assertRole(cohost.ID, config.EventRoleViewer)
+	if _, err := models.InviteCohost(databaseConnection, &eventRecord, "cohost@example.com", config.EventRoleEditor, eventOwner.ID); err != nil {
+		t.Fatalf("changing the co-host's role: %v", err)
+	}
+	assertRole(cohost.ID, config.EventRoleEditor)
+	assertRole(stranger.ID, "")
+	assertRole("", "")
+
+	// On an organization's event the organization role decides: owners and admins own it, members edit it,
+	// the creator holds no role of their own once they leave, and co-hosts from outside keep their membership role.
+	organizationAdmin := createTestUser(t, databaseConnection, "admin@example.com")
+	organizationMember := createTestUser(t, databaseConnection, "member@example.com")
+	eventCreator := createTestUser(t, databaseConnection, "creator@example.com")
+	organization, organizationEvent, _ := createTestOrganizationEvent(t, databaseConnection, &eventOwner, &eventCreator)
+	addTestMember(t, databaseConnection, organization, &organizationAdmin, config.OrgRoleAdmin)
+	addTestMember(t, databaseConnection, organization, &organizationMember, config.OrgRoleMember)
+	creatorMembership := addTestMember(t, databaseConnection, organization, &eventCreator, config.OrgRoleMember)
+	if _, err := models.InviteCohost(databaseConnection, &organizationEvent, cohost.Email, config.EventRoleCheckIn, eventOwner.ID); err != nil {
+		t.Fatalf("inviting the co-host to the organization event: %v", err)
+	}
+	if _, err := models.AcceptPendingInvitations(databaseConnection, &cohost); err != nil {
+		t.Fatalf("accepting the organization event invitation: %v", err)
+	}
+	assertOrganizationRole := func(userIdentifier string, expectedRole string) {
+		t.Helper()
+		if eventRole, err := models.EventRoleForUser(databaseConnection, &organizationEvent, userIdentifier); err != nil || eventRole != expectedRole {
+			t.Errorf("EventRoleForUser(organization event, %q) = %q, %v, want %q", userIdentifier, eventRole, err, expectedRole)
+		}
+	}
+	assertOrganizationRole(eventOwner.ID, config.EventRoleOwner)
+	assertOrganizationRole(organizationAdmin.ID, config.EventRoleOwner)
+	assertOrganizationRole(organizationMember.ID, config.EventRoleEditor)
+	assertOrganizationRole(eventCreator.ID, config.EventRoleEditor)
+	assertOrganizationRole(cohost.ID, config.EventRoleCheckIn)
+	assertOrganizationRole(stranger.ID, "")
+	if _, err := models.RemoveOrganizationMember(databaseConnection, creatorMembership); err != nil {
+		t.Fatalf("removing the creator: %v", err)
+	}
+	assertOrganizationRole(eventCreator.ID, "")
+
+	permissionTestCases := []struct {
+		eventRole  string
+		permission models.EventPermission
+		allowed    bool
+	}{
+		{config.EventRoleOwner, models.PermissionDeleteEvent, true},
+		{config.EventRoleOwner, models.PermissionManageCohosts, true},
+		{config.EventRoleEditor, models.PermissionEditEvent, true},
+		{config.EventRoleEditor, models.PermissionManageRSVPs, true},
+		{config.EventRoleEditor, models.PermissionDeleteEvent, false},
+		{config.EventRoleEditor, models.PermissionManageCohosts, false},
+		{config.EventRoleViewer, models.PermissionViewRSVPs, true},
+		{config.EventRoleViewer, models.PermissionRecordResponses, false},
+		{config.EventRoleViewer, models.PermissionEditEvent, false},
+		{config.EventRoleCheckIn, models.PermissionRecordResponses, true},
+		{config.EventRoleCheckIn, models.PermissionViewQRCodes, true},
+		{config.EventRoleCheckIn, models.PermissionManageRSVPs, false},
+		{"", models.PermissionViewEvent, false},
+		{"unknown", models.PermissionViewEvent, false},
+	}
+	for _, testCase := range permissionTestCases {
+		if allowed := models.RoleAllows(testCase.eventRole, testCase.permission); allowed != testCase.allowed {
+			t.Errorf("RoleAllows(%q, %v) = %t, want %t", testCase.eventRole, testCase.permission, allowed, testCase.allowed)
+		}
+	}
+}
+
+func TestFindVenueIDsEditableByMember(t *testing.T) {
+	databaseConnection := testdb.OpenMigrated(t)
+	eventOwner, personalEvent := createTestEvent(t, databaseConnection, "owner@example.com")
+	editor := createTestUser(t, databaseConnection, "editor@example.com")
+	viewer := createTestUser(t, databaseConnection, "viewer@example.com")
+	organizationMember := createTestUser(t, databaseConnection, "member@example.com")
+	personalVenue := models.Venue{Name: "Home", UserID: eventOwner.ID}
+	if err := personalVenue.Create(databaseConnection); err != nil {
+		t.Fatalf("creating the personal venue: %v", err)
+	}
+	personalEvent.VenueID = &personalVenue.ID
+	if err := databaseConnection.Save(&personalEvent).Error; err != nil {
+		t.Fatalf("linking the personal venue: %v", err)
+	}
+	for cohostEmail, cohostRole := range map[string]string{editor.Email: config.EventRoleEditor, viewer.Email: config.EventRoleViewer} {
+		if _, err := models.InviteCohost(databaseConnection, &personalEvent, cohostEmail, cohostRole, eventOwner.ID); err != nil {
+			t.Fatalf("inviting %s: %v", cohostEmail, err)
+		}
+	}
+	for _, cohost := range []*models.User{&editor, &viewer} {
+		if _, err := models.AcceptPendingInvitations(databaseConnection, cohost); err != nil {
+			t.Fatalf("accepting the invitation of %s: %v", cohost.Email, err)
+		}
+	}
+	organization, organizationEvent, _ := createTestOrganizationEvent(t, databaseConnection, &eventOwner, &eventOwner)
+	addTestMember(t, databaseConnection, organization, &organizationMember, config.OrgRoleMember)
+
+	assertEditableVenues := func(userRecord models.User, expectedVenueIDs ...string) {
+		t.Helper()
+		editableVenueIDs, err := models.FindVenueIDsEditableByMember(databaseConnection, userRecord.ID)
+		if err != nil {
+			t.Fatalf("FindVenueIDsEditableByMember(%s) error = %v", userRecord.Email, err)
+		}
+		sort.Strings(editableVenueIDs)
+		sort.Strings(expectedVenueIDs)
+		if strings.Join(editableVenueIDs, ",") != strings.Join(expectedVenueIDs, ",") {
+			t.Errorf("FindVenueIDsEditableByMember(%s) = %v, want %v", userRecord.Email, editableVenueIDs, expectedVenueIDs)
+		}
+	}
+	assertEditableVenues(editor, personalVenue.ID)
+	assertEditableVenues(viewer)
+	assertEditableVenues(organizationMember, *organizationEvent.VenueID)
+	// A viewing co-host of an organization's event who is also a member edits it as a member.
+	if _, err := models.InviteCohost(databaseConnection, &organizationEvent, organizationMember.Email, config.EventRoleViewer, eventOwner.ID); err != nil {
+		t.Fatalf("inviting the member as a viewer: %v", err)
+	}
+	if _, err := models.AcceptPendingInvitations(databaseConnection, &organizationMember); err != nil {
+		t.Fatalf("accepting the viewer invitation: %v", err)
+	}
+	assertEditableVenues(organizationMember, *organizationEvent.VenueID)
+}
+
+func TestFindViewerUserIDsForEvent(t *testing.T) {
+	databaseConnection := testdb.OpenMigrated(t)
+	personalOwner, personalEvent := createTestEvent(t, databaseConnection, "owner@example.com")
+	cohost := createTestUser(t, databaseConnection, "cohost@example.com")
+	organizationOwner := createTestUser(t, databaseConnection, "org-owner@example.com")
+	organizationMember := createTestUser(t, databaseConnection, "member@example.com")
+	createTestUser(t, databaseConnection, "stranger@example.com")
+	if _, err := models.InviteCohost(databaseConnection, &personalEvent, cohost.Email, config.EventRoleCheckIn, personalOwner.ID); err != nil {
+		t.Fatalf("inviting the co-host: %v", err)
+	}
+	if _, err := models.AcceptPendingInvitations(databaseConnection, &cohost); err != nil {
+		t.Fatalf("accepting the co-host invitation: %v", err)
+	}
+	organization, organizationEvent, _ := createTestOrganizationEvent(t, databaseConnection, &organizationOwner, &organizationMember)
+	addTestMember(t, databaseConnection, organization, &organizationMember, config.OrgRoleMember)
+
+	assertViewers := func(eventRecord *models.Event, expectedUserIDs ...string) {
+		t.Helper()
+		viewerUserIDs, err := models.FindViewerUserIDsForEvent(databaseConnection, eventRecord)
+		if err != nil {
+			t.Fatalf("FindViewerUserIDsForEvent(%q) error = %v", eventRecord.Title, err)
+		}
+		sort.Strings(viewerUserIDs)
+		sort.Strings(expectedUserIDs)
+		if strings.Join(viewerUserIDs, ",") != strings.Join(expectedUserIDs, ",") {
+			t.Errorf("FindViewerUserIDsForEvent(%q) = %v, want %v", eventRecord.Title, viewerUserIDs, expectedUserIDs)
+		}
+		for _, viewerUserID := range viewerUserIDs {
+			if eventRole, _ := models.EventRoleForUser(databaseConnection, eventRecord, viewerUserID); !models.RoleAllows(eventRole, models.PermissionViewEvent) {
+				t.Errorf("viewer %q holds role %q, which cannot view %q", viewerUserID, eventRole, eventRecord.Title)
+			}
+		}
+	}
+	assertViewers(&personalEvent, personalOwner.ID, cohost.ID)
+	assertViewers(&organizationEvent, organizationOwner.ID, organizationMember.ID)
+
+	// A co-host of an organization event who is not in the organization still sees it, and only once.
+	if _, err := models.InviteCohost(databaseConnection, &organizationEvent, cohost.Email, config.EventRoleViewer, organizationOwner.ID); err != nil {
+		t.Fatalf("inviting the co-host to the organization event: %v", err)
+	}
+	if _, err := models.AcceptPendingInvitations(databaseConnection, &cohost); err != nil {
+		t.Fatalf("accepting the organization event invitation: %v", err)
+	}
+	if _, err := models.InviteCohost(databaseConnection, &organizationEvent, organizationOwner.Email, config.EventRoleViewer, organizationMember.ID); err != nil {
+		t.Fatalf("inviting the organization owner as a co-host: %v", err)
+	}
+	if _, err := models.AcceptPendingInvitations(databaseConnection, &organizationOwner); err != nil {
+		t.Fatalf("accepting the organization owner's co-host invitation: %v", err)
+	}
+	assertViewers(&organizationEvent, organizationOwner.ID, organizationMember.ID, cohost.ID)
+}
@@ -0,0 +1,274 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/temirov/RSVP/pkg/config"
+	"gorm.io/gorm"
+)
+
+// ErrOrganizationOwner is returned when an admin tries to remove the organization's owner or change their role.
+var ErrOrganizationOwner = errors.New("the organization owner cannot be removed or given another role")
+
+// Organization is a shared workspace whose events and venues belong to the organization rather than
+// to the member who created them.
+type Organization struct {
+	BaseModel
+	Name string `gorm:"size:100;not null"`
+	// CreatedByUserID records who created the organization; they are its first owner.
+	CreatedByUserID string `gorm:"type:varchar(8);not null"`
+}
+
+// GetTableName returns the database table name for the Organization model.
+func (organization *Organization) GetTableName() string {
+	return config.TableOrgs
+}
+
+// GetIDGeneratorFunc returns the unique ID generation function for the Organization model.
+func (organization *Organization) GetIDGeneratorFunc() func(int) (string, error) {
+	return GenerateBase62ID
+}
+
+// BeforeCreate is a GORM hook to ensure the organization has a unique ID before creation.
+func (organization *Organization) BeforeCreate(databaseTransaction *gorm.DB) error {
+	return organization.BaseModel.GenerateID(databaseTransaction, organization)
+}
+
+// FindByID retrieves an Organization record by its identifier.
+func (organization *Organization) FindByID(databaseConnection *gorm.DB, organizationIdentifier string) error {
+	return databaseConnection.Where("id = ?", organizationIdentifier).First(organization).Error
+}
+
+// OrganizationMember grants a user a role in an organization. Like co-host invitations, a membership is
+// addressed to an email address and stays pending (UserID is nil) until a user with that address signs in.
+type OrganizationMember struct {
+	BaseModel
+	OrganizationID string `gorm:"type:varchar(8);not null;index;uniqueIndex:idx_organization_members_org_email"`
+	// UserID is the member's user once the invitation has been accepted, nil while it is pending.
+	UserID *string `gorm:"type:varchar(8);index"`
+	// InvitedEmail is the lower-cased address the invitation was sent to.
+	InvitedEmail string `gorm:"size:255;not null;index;uniqueIndex:idx_organization_members_org_email"`
+	// Role is one of config.OrgRoleOwner, config.OrgRoleAdmin or config.OrgRoleMember.
+	Role            string `gorm:"size:20;not null"`
+	InvitedByUserID string `gorm:"type:varchar(8);not null"`
+	AcceptedAt      *time.Time
+	Organization    Organization `gorm:"foreignKey:OrganizationID;references:id"`
+	User            *User        `gorm:"foreignKey:UserID;references:id"`
+}
+
+// GetTableName returns the database table name for the OrganizationMember model.
+func (member *OrganizationMember) GetTableName() string {
+	return config.TableOrgUsers
+}
+
+// GetIDGeneratorFunc returns the unique ID generation function for the OrganizationMember model.
+func (member *OrganizationMember) GetIDGeneratorFunc() func(int) (string, error) {
+	return GenerateBase62ID
+}
+
+// BeforeCreate is a GORM hook to ensure the member has a unique ID before creation.
+func (member *OrganizationMember) BeforeCreate(databaseTransaction *gorm.DB) error {
+	return member.BaseModel.GenerateID(databaseTransaction, member)
+}
+
+// IsPending reports whether the invitation has not been accepted yet.
+func (member *OrganizationMember) IsPending() bool {
+	return member.UserID == nil
+}
+
+// BelongsTo reports whether the membership has been accepted by the given user.
+func (member *OrganizationMember) BelongsTo(userIdentifier string) bool {
+	return member.UserID != nil && *member.UserID == userIdentifier
+}
+
+// FindByIDAndOrganization retrieves a member of the given organization by its identifier.
+func (member *OrganizationMember) FindByIDAndOrganization(databaseConnection *gorm.DB, memberIdentifier string, organizationIdentifier string) error {
+	return databaseConnection.Where("id = ? AND organization_id = ?", memberIdentifier, organizationIdentifier).First(member).Error
+}
+
+// OrgRoleAllowsManagement reports whether an organization role may manage members and delete the
+// organization's events and venues.
+func OrgRoleAllowsManagement(organizationRole string) bool {
+	return organizationRole == config.OrgRoleOwner || organizationRole == config.OrgRoleAdmin
+}
+
+// CreateOrganization creates an organization with the given user as its accepted owner.
+func CreateOrganization(databaseConnection *gorm.DB, organizationName string, creator *User) (*Organization, error) {
+	newOrganization := Organization{Name: organizationName, CreatedByUserID: creator.ID}
+	transactionError := databaseConnection.Transaction(func(databaseTransaction *gorm.DB) error {
+		if err := databaseTransaction.Create(&newOrganization).Error; err != nil {
+			return err
+		}
+		acceptedAt := time.Now()
+		ownerMembership := OrganizationMember{
+			OrganizationID:  newOrganization.ID,
+			UserID:          &creator.ID,
+			InvitedEmail:    NormalizeMemberEmail(creator.Email),
+			Role:            config.OrgRoleOwner,
+			InvitedByUserID: creator.ID,
+			AcceptedAt:      &acceptedAt,
+		}
+		return databaseTransaction.Create(&ownerMembership).Error
+	})
+	if transactionError != nil {
+		return nil, transactionError
+	}
+	return &newOrganization, nil
+}
+
+// FindOrganizationsForUser lists the organizations in which the user holds an accepted membership, by name.
+func FindOrganizationsForUser(databaseConnection *gorm.DB, userIdentifier string) ([]Organization, error) {
+	var memberOrganizations []Organization
+	memberOrganizationIDs := databaseConnection.Model(&OrganizationMember{}).Select("organization_id").Where("user_id = ?", userIdentifier)
+	queryError := databaseConnection.Where("id IN (?)", memberOrganizationIDs).Order("name ASC").Find(&memberOrganizations).Error
+	return memberOrganizations, queryError
+}
+
+// OrganizationRoleForUser returns the user's role in the organization, or an empty string if they are not
+// an accepted member.
+func OrganizationRoleForUser(databaseConnection *gorm.DB, organizationIdentifier string, userIdentifier string) (string, error) {
+	if userIdentifier == "" {
+		return "", nil
+	}
+	var membership OrganizationMember
+	findError := databaseConnection.Where("organization_id = ? AND user_id = ?", organizationIdentifier, userIdentifier).First(&membership).Error
+	if errors.Is(findError, gorm.ErrRecordNotFound) {
+		return "", nil
+	}
+	if findError != nil {
+		return "", findError
+	}
+	return membership.Role, nil
+}
+
+// FindOrganizationMembers lists the members of an organization, accepted and pending, in invitation order.
+func FindOrganizationMembers(databaseConnection *gorm.DB, organizationIdentifier string) ([]OrganizationMember, error) {
+	var organizationMembers []OrganizationMember
+	queryError := databaseConnection.Preload("User").Where("organization_id = ?", organizationIdentifier).Order("created_at ASC").Find(&organizationMembers).Error
+	return organizationMembers, queryError
+}
+
+// FindOrganizationMemberUserIDs returns the user IDs of the organization's accepted members.
+func FindOrganizationMemberUserIDs(databaseConnection *gorm.DB, organizationIdentifier string) ([]string, error) {
+	var memberUserIDs []string
+	queryError := databaseConnection.Model(&OrganizationMember{}).
+		Where("organization_id = ? AND user_id IS NOT NULL", organizationIdentifier).
+		Pluck("user_id", &memberUserIDs).Error
+	return memberUserIDs, queryError
+}
+
+// InviteOrganizationMember invites the given address to the organization with a role. Inviting an address
+// that already has a membership changes its role instead, except for the owner's.
+func InviteOrganizationMember(databaseConnection *gorm.DB, organizationIdentifier string, emailAddress string, memberRole string, invitedByUserID string) (*OrganizationMember, error) {
+	normalizedEmail := NormalizeMemberEmail(emailAddress)
+	var existingMember OrganizationMember
+	findError := databaseConnection.Where("organization_id = ? AND invited_email = ?", organizationIdentifier, normalizedEmail).First(&existingMember).Error
+	if findError == nil {
+		if existingMember.Role == config.OrgRoleOwner {
+			return nil, ErrOrganizationOwner
+		}
+		if err := databaseConnection.Model(&existingMember).Update("role", memberRole).Error; err != nil {
+			return nil, err
+		}
+		return &existingMember, nil
+	}
+	if !errors.Is(findError, gorm.ErrRecordNotFound) {
+		return nil, findError
+	}
+
+	newMember := OrganizationMember{
+		OrganizationID:  organizationIdentifier,
+		InvitedEmail:    normalizedEmail,
+		Role:            memberRole,
+		InvitedByUserID: invitedByUserID,
+	}
+	if err := databaseConnection.Create(&newMember).Error; err != nil {
+		return nil, err
+	}
+	return &newMember, nil
+}
+
+// AcceptPendingOrganizationInvitations binds every pending organization invitation addressed to the user's
+// email to their account. It returns the number of accepted invitations.
+func AcceptPendingOrganizationInvitations(databaseConnection *gorm.DB, userRecord *User) (int64, error) {
+	normalizedEmail := NormalizeMemberEmail(userRecord.Email)
+	var pendingCount int64
+	if err := databaseConnection.Model(&OrganizationMember{}).
+		Where("invited_email = ? AND user_id IS NULL", normalizedEmail).
+		Count(&pendingCount).Error; err != nil || pendingCount == 0 {
+		return 0, err
+	}
+	acceptResult := databaseConnection.Model(&OrganizationMember{}).
+		Where("invited_email = ? AND user_id IS NULL", normalizedEmail).
+		Updates(map[string]interface{}{"user_id": userRecord.ID, "accepted_at": time.Now()})
+	return acceptResult.RowsAffected, acceptResult.Error
+}
+
+// RemoveOrganizationMember permanently deletes a membership. The events and venues the member created in
+// the organization, including those in the trash, stay in it and are handed over to the organization's
+// owner, so nothing is orphaned. The member's API tokens restricted to the organization's events are revoked,
+// since they could no longer be used anyway. It returns the number of events that changed hands.
+func RemoveOrganizationMember(databaseConnection *gorm.DB, member *OrganizationMember) (int64, error) {
+	if member.Role == config.OrgRoleOwner {
+		return 0, ErrOrganizationOwner
+	}
+	var reassignedEventCount int64
+	transactionError := databaseConnection.Transaction(func(databaseTransaction *gorm.DB) error {
+		if member.UserID != nil {
+			var ownerMembership OrganizationMember
+			if err := databaseTransaction.Where("organization_id = ? AND role = ?", member.OrganizationID, config.OrgRoleOwner).First(&ownerMembership).Error; err != nil {
+				return err
+			}
+			organizationEventIDs := databaseTransaction.Unscoped().Model(&Event{}).Select("id").Where("organization_id = ?", member.OrganizationID)
+			if err := databaseTransaction.Model(&APIToken{}).
+				Where("user_id = ? AND event_id IN (?) AND revoked_at IS NULL", *member.UserID, organizationEventIDs).
+				Update("revoked_at", time.Now()).Error; err != nil {
+				return err
+			}
+			reassignResult := databaseTransaction.Unscoped().Model(&Event{}).
+				Where("organization_id = ? AND user_id = ?", member.OrganizationID, *member.UserID).
+				UpdateColumn("user_id", *ownerMembership.UserID)
+			if reassignResult.Error != nil {
+				return reassignResult.Error
+			}
+			reassignedEventCount = reassignResult.RowsAffected
+			if err := databaseTransaction.Unscoped().Model(&Venue{}).
+				Where("organization_id = ? AND user_id = ?", member.OrganizationID, *member.UserID).
+				UpdateColumn("user_id", *ownerMembership.UserID).Error; err != nil {
+				return err
+			}
+		}
+		return databaseTransaction.Unscoped().Delete(member).Error
+	})
+	return reassignedEventCount, transactionError
+}
+
+// FindEventsInOrganization retrieves the events of an organization's workspace.
+func FindEventsInOrganization(databaseConnection *gorm.DB, organizationIdentifier string, preloadRSVPs bool, preloadVenues bool) ([]Event, error) {
+	var organizationEvents []Event
+	queryBuilder := databaseConnection.Where("organization_id = ?", organizationIdentifier).Order("start_time DESC")
+	if preloadRSVPs {
+		queryBuilder = queryBuilder.Preload("RSVPs")
+	}
+	if preloadVenues {
+		queryBuilder = queryBuilder.Preload("Venue")
+	}
+	queryError := queryBuilder.Find(&organizationEvents).Error
+	return organizationEvents, queryError
+}
+
+// FindEventsInUserOrganizations retrieves the events of every organization the user is an accepted member of.
+func FindEventsInUserOrganizations(databaseConnection *gorm.DB, userIdentifier string) ([]Event, error) {
+	var organizationEvents []Event
+	memberOrganizationIDs := databaseConnection.Model(&OrganizationMember{}).Select("organization_id").Where("user_id = ?", userIdentifier)
+	queryError := databaseConnection.Where("organization_id IN (?)", memberOrganizationIDs).Order("start_time DESC").Find(&organizationEvents).Error
+	return organizationEvents, queryError
+}
+
+// FindVenuesInOrganization retrieves the venues of an organization's workspace.
+func FindVenuesInOrganization(databaseConnection *gorm.DB, organizationIdentifier string) ([]Venue, error) {
+	var organizationVenues []Venue
+	queryError := databaseConnection.Where("organization_id = ?", organizationIdentifier).Order("name ASC").Find(&organizationVenues).Error
+	return organizationVenues, queryError
+}
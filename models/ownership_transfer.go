@@ -0,0 +1,308 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/temirov/RSVP/pkg/config"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrTransferToSelf is returned when an owner addresses a transfer to their own email address.
+	ErrTransferToSelf = errors.New("you already own this item")
+	// ErrTransferAlreadyPending is returned when the item already has a transfer waiting to be accepted.
+	ErrTransferAlreadyPending = errors.New("this item already has a pending transfer; cancel it first")
+	// ErrTransferNotTransferable is returned for items that are not in their owner's personal workspace.
+	ErrTransferNotTransferable = errors.New("only events and venues in your personal workspace can be transferred")
+	// ErrTransferStale is returned on acceptance when the item was deleted or changed hands since the transfer was offered.
+	ErrTransferStale = errors.New("this item is no longer available for transfer")
+)
+
+// OwnershipTransfer is an offer from an owner to hand a personal event or venue to another user.
+// It is addressed to an email address and takes effect only when a user with that address accepts it.
+type OwnershipTransfer struct {
+	BaseModel
+	// ResourceType is config.TransferResourceEvent or config.TransferResourceVenue.
+	ResourceType string `gorm:"size:10;not null;index:idx_ownership_transfers_resource"`
+	ResourceID   string `gorm:"type:varchar(8);not null;index:idx_ownership_transfers_resource"`
+	FromUserID   string `gorm:"type:varchar(8);not null;index"`
+	// RecipientEmail is the lower-cased address of the intended new owner.
+	RecipientEmail string `gorm:"size:255;not null;index"`
+	// IncludeLinkedEvents moves the owner's events at a transferred venue together with it.
+	IncludeLinkedEvents bool `gorm:"not null;default:false"`
+	// Status is one of the config.TransferStatus values; only pending transfers can be accepted or cancelled.
+	Status     string `gorm:"size:20;not null;index"`
+	ResolvedAt *time.Time
+	FromUser   User `gorm:"foreignKey:FromUserID;references:id"`
+}
+
+// TransferOutcome summarizes what an accepted transfer moved.
+type TransferOutcome struct {
+	MovedEventCount   int
+	RevokedTokenCount int64
+	// VenueCopied is set when a venue still used by events that stayed behind was duplicated instead of moved.
+	VenueCopied bool
+}
+
+// GetTableName returns the database table name for the OwnershipTransfer model.
+func (transfer *OwnershipTransfer) GetTableName() string {
+	return config.TableTransfers
+}
+
+// GetIDGeneratorFunc returns the unique ID generation function for the OwnershipTransfer model.
+func (transfer *OwnershipTransfer) GetIDGeneratorFunc() func(int) (string, error) {
+	return GenerateBase62ID
+}
+
+// BeforeCreate is a GORM hook to ensure the transfer has a unique ID before creation.
+func (transfer *OwnershipTransfer) BeforeCreate(databaseTransaction *gorm.DB) error {
+	return transfer.BaseModel.GenerateID(databaseTransaction, transfer)
+}
+
+// FindPendingByID retrieves a pending transfer that has not lapsed by its identifier.
+func (transfer *OwnershipTransfer) FindPendingByID(databaseConnection *gorm.DB, transferIdentifier string) error {
+	return pendingTransfers(databaseConnection).Preload("FromUser").
+		Where("id = ?", transferIdentifier).
+		First(transfer).Error
+}
+
+// ExpiresAt returns the time after which the transfer can no longer be accepted.
+func (transfer *OwnershipTransfer) ExpiresAt() time.Time {
+	return transfer.CreatedAt.Add(config.TransferOfferLifetime)
+}
+
+// IsAddressedTo reports whether the user is the intended recipient of the transfer.
+func (transfer *OwnershipTransfer) IsAddressedTo(userRecord *User) bool {
+	return transfer.RecipientEmail == NormalizeMemberEmail(userRecord.Email)
+}
+
+// Resolve closes a pending transfer without moving anything, as config.TransferStatusCancelled by the owner
+// or config.TransferStatusDeclined by the recipient.
+func (transfer *OwnershipTransfer) Resolve(databaseConnection *gorm.DB, finalStatus string) error {
+	resolvedAt := time.Now()
+	resolveResult := databaseConnection.Model(&OwnershipTransfer{}).
+		Where("id = ? AND status = ?", transfer.ID, config.TransferStatusPending).
+		Updates(map[string]interface{}{"status": finalStatus, "resolved_at": resolvedAt})
+	if resolveResult.Error != nil {
+		return resolveResult.Error
+	}
+	if resolveResult.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	transfer.Status = finalStatus
+	transfer.ResolvedAt = &resolvedAt
+	return nil
+}
+
+// OfferOwnershipTransfer records a pending transfer of the owner's personal event or venue to the given address.
+// The caller must have checked that the resource exists and belongs to the owner's personal workspace.
+func OfferOwnershipTransfer(databaseConnection *gorm.DB, resourceType string, resourceIdentifier string, owner *User, recipientEmail string, includeLinkedEvents bool) (*OwnershipTransfer, error) {
+	normalizedEmail := NormalizeMemberEmail(recipientEmail)
+	if normalizedEmail == NormalizeMemberEmail(owner.Email) {
+		return nil, ErrTransferToSelf
+	}
+	var pendingCount int64
+	if err := pendingTransfers(databaseConnection).Model(&OwnershipTransfer{}).
+		Where("resource_type = ? AND resource_id = ?", resourceType, resourceIdentifier).
+		Count(&pendingCount).Error; err != nil {
+		return nil, err
+	}
+	if pendingCount > 0 {
+		return nil, ErrTransferAlreadyPending
+	}
+	newTransfer := OwnershipTransfer{
+		ResourceType:        resourceType,
+		ResourceID:          resourceIdentifier,
+		FromUserID:          owner.ID,
+		RecipientEmail:      normalizedEmail,
+		IncludeLinkedEvents: includeLinkedEvents && resourceType == config.TransferResourceVenue,
+		Status:              config.TransferStatusPending,
+	}
+	if err := databaseConnection.Create(&newTransfer).Error; err != nil {
+		return nil, err
+	}
+	return &newTransfer, nil
+}
+
+// FindPendingTransfersFromUser lists the transfers the user has offered that are still waiting, newest first.
+func FindPendingTransfersFromUser(databaseConnection *gorm.DB, userIdentifier string) ([]OwnershipTransfer, error) {
+	var outgoingTransfers []OwnershipTransfer
+	queryError := pendingTransfers(databaseConnection).
+		Where("from_user_id = ?", userIdentifier).
+		Order("created_at DESC").Find(&outgoingTransfers).Error
+	return outgoingTransfers, queryError
+}
+
+// FindPendingTransfersForUser lists the transfers waiting for the user to accept or decline, newest first.
+func FindPendingTransfersForUser(databaseConnection *gorm.DB, userRecord *User) ([]OwnershipTransfer, error) {
+	var incomingTransfers []OwnershipTransfer
+	queryError := pendingTransfers(databaseConnection).Preload("FromUser").
+		Where("recipient_email = ?", NormalizeMemberEmail(userRecord.Email)).
+		Order("created_at DESC").Find(&incomingTransfers).Error
+	return incomingTransfers, queryError
+}
+
+// Accept moves the event or venue to the recipient in one transaction and marks the transfer accepted.
+// A transfer that lapsed in the meantime fails with ErrTransferStale and moves nothing.
+// Venue references stay consistent with ownership: an event keeps a venue owned by its owner, so a venue
+// still used by events that stay with the previous owner is duplicated for whichever side does not get it.
+func (transfer *OwnershipTransfer) Accept(databaseConnection *gorm.DB, recipient *User) (TransferOutcome, error) {
+	var transferOutcome TransferOutcome
+	transactionError := databaseConnection.Transaction(func(databaseTransaction *gorm.DB) error {
+		var err error
+		switch transfer.ResourceType {
+		case config.TransferResourceEvent:
+			transferOutcome, err = acceptEventTransfer(databaseTransaction, transfer, recipient)
+		case config.TransferResourceVenue:
+			transferOutcome, err = acceptVenueTransfer(databaseTransaction, transfer, recipient)
+		default:
+			err = ErrTransferStale
+		}
+		if err != nil {
+			return err
+		}
+		acceptResult := pendingTransfers(databaseTransaction).Model(&OwnershipTransfer{}).
+			Where("id = ?", transfer.ID).
+			Updates(map[string]interface{}{"status": config.TransferStatusAccepted, "resolved_at": time.Now()})
+		if acceptResult.Error != nil {
+			return acceptResult.Error
+		}
+		if acceptResult.RowsAffected == 0 {
+			return ErrTransferStale
+		}
+		return nil
+	})
+	if transactionError != nil {
+		return TransferOutcome{}, transactionError
+	}
+	transfer.Status = config.TransferStatusAccepted
+	return transferOutcome, nil
+}
+
+// acceptEventTransfer moves a personal event, its RSVPs and, when no other event of the previous owner uses it,
+// its venue. A venue that is shared with the previous owner's other events is copied for the recipient instead.
+func acceptEventTransfer(databaseTransaction *gorm.DB, transfer *OwnershipTransfer, recipient *User) (TransferOutcome, error) {
+	var transferOutcome TransferOutcome
+	var transferredEvent Event
+	if err := findTransferableResource(databaseTransaction, &transferredEvent, transfer); err != nil {
+		return transferOutcome, err
+	}
+	if transferredEvent.VenueID != nil {
+		var eventVenue Venue
+		venueError := databaseTransaction.Where("id = ? AND user_id = ? AND organization_id IS NULL", *transferredEvent.VenueID, transfer.FromUserID).First(&eventVenue).Error
+		if venueError != nil && !errors.Is(venueError, gorm.ErrRecordNotFound) {
+			return transferOutcome, venueError
+		}
+		if venueError == nil {
+			var otherEventCount int64
+			if err := databaseTransaction.Unscoped().Model(&Event{}).
+				Where("venue_id = ? AND user_id = ? AND id <> ?", eventVenue.ID, transfer.FromUserID, transferredEvent.ID).
+				Count(&otherEventCount).Error; err != nil {
+				return transferOutcome, err
+			}
+			if otherEventCount == 0 {
+				if err := eventVenue.TransferOwnership(databaseTransaction, recipient.ID); err != nil {
+					return transferOutcome, err
+				}
+				if err := cancelPendingTransfers(databaseTransaction, transfer.FromUserID, config.TransferResourceVenue, []string{eventVenue.ID}); err != nil {
+					return transferOutcome, err
+				}
+			} else {
+				venueCopy, err := eventVenue.copyFor(databaseTransaction, recipient.ID)
+				if err != nil {
+					return transferOutcome, err
+				}
+				if err := databaseTransaction.Model(&transferredEvent).UpdateColumn("venue_id", venueCopy.ID).Error; err != nil {
+					return transferOutcome, err
+				}
+				transferOutcome.VenueCopied = true
+			}
+		}
+	}
+	revokedTokenCount, err := transferredEvent.TransferOwnership(databaseTransaction, recipient.ID)
+	if err != nil {
+		return transferOutcome, err
+	}
+	transferOutcome.MovedEventCount = 1
+	transferOutcome.RevokedTokenCount = revokedTokenCount
+	return transferOutcome, nil
+}
+
+// acceptVenueTransfer moves a personal venue and, if requested, the previous owner's events held there.
+// Events that stay with the previous owner, including ones in the trash, are pointed at a copy of the venue.
+func acceptVenueTransfer(databaseTransaction *gorm.DB, transfer *OwnershipTransfer, recipient *User) (TransferOutcome, error) {
+	var transferOutcome TransferOutcome
+	var transferredVenue Venue
+	if err := findTransferableResource(databaseTransaction, &transferredVenue, transfer); err != nil {
+		return transferOutcome, err
+	}
+	if transfer.IncludeLinkedEvents {
+		var linkedEvents []Event
+		if err := databaseTransaction.Where("venue_id = ? AND user_id = ? AND organization_id IS NULL", transferredVenue.ID, transfer.FromUserID).Find(&linkedEvents).Error; err != nil {
+			return transferOutcome, err
+		}
+		movedEventIDs := make([]string, 0, len(linkedEvents))
+		for eventIndex := range linkedEvents {
+			revokedTokenCount, err := linkedEvents[eventIndex].TransferOwnership(databaseTransaction, recipient.ID)
+			if err != nil {
+				return transferOutcome, err
+			}
+			transferOutcome.RevokedTokenCount += revokedTokenCount
+			movedEventIDs = append(movedEventIDs, linkedEvents[eventIndex].ID)
+		}
+		transferOutcome.MovedEventCount = len(linkedEvents)
+		if err := cancelPendingTransfers(databaseTransaction, transfer.FromUserID, config.TransferResourceEvent, movedEventIDs); err != nil {
+			return transferOutcome, err
+		}
+	}
+
+	var remainingEventCount int64
+	if err := databaseTransaction.Unscoped().Model(&Event{}).
+		Where("venue_id = ? AND user_id = ?", transferredVenue.ID, transfer.FromUserID).
+		Count(&remainingEventCount).Error; err != nil {
+		return transferOutcome, err
+	}
+	if remainingEventCount > 0 {
+		venueCopy, err := transferredVenue.copyFor(databaseTransaction, transfer.FromUserID)
+		if err != nil {
+			return transferOutcome, err
+		}
+		if err := databaseTransaction.Unscoped().Model(&Event{}).
+			Where("venue_id = ? AND user_id = ?", transferredVenue.ID, transfer.FromUserID).
+			UpdateColumn("venue_id", venueCopy.ID).Error; err != nil {
+			return transferOutcome, err
+		}
+		transferOutcome.VenueCopied = true
+	}
+	return transferOutcome, transferredVenue.TransferOwnership(databaseTransaction, recipient.ID)
+}
+
+// pendingTransfers scopes a query to transfers that are pending and younger than config.TransferOfferLifetime.
+// Lapsed transfers keep their pending status but can no longer be listed, accepted or resolved.
+func pendingTransfers(databaseConnection *gorm.DB) *gorm.DB {
+	return databaseConnection.Where(config.TableTransfers+".status = ? AND "+config.TableTransfers+".created_at > ?",
+		config.TransferStatusPending, time.Now().Add(-config.TransferOfferLifetime))
+}
+
+// findTransferableResource loads the transfer's event or venue if it is still live, owned by the user who
+// offered it and in their personal workspace.
+func findTransferableResource(databaseTransaction *gorm.DB, resourceRecord interface{}, transfer *OwnershipTransfer) error {
+	findError := databaseTransaction.
+		Where("id = ? AND user_id = ? AND organization_id IS NULL", transfer.ResourceID, transfer.FromUserID).
+		First(resourceRecord).Error
+	if errors.Is(findError, gorm.ErrRecordNotFound) {
+		return ErrTransferStale
+	}
+	return findError
+}
+
+// cancelPendingTransfers closes the owner's pending transfers of items that have just moved along with another one.
+func cancelPendingTransfers(databaseTransaction *gorm.DB, ownerUserID string, resourceType string, resourceIdentifiers []string) error {
+	if len(resourceIdentifiers) == 0 {
+		return nil
+	}
+	return databaseTransaction.Model(&OwnershipTransfer{}).
+		Where("from_user_id = ? AND resource_type = ? AND resource_id IN ? AND status = ?", ownerUserID, resourceType, resourceIdentifiers, config.TransferStatusPending).
+		Updates(map[string]interface{}{"status": config.TransferStatusCancelled, "resolved_at": time.Now()}).Error
+}
@@ -0,0 +1,253 @@
+package models_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/temirov/RSVP/models"
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/testdb"
+	"gorm.io/gorm"
+)
+
+// transferTestAccount is an owner with a venue used by two of their events, one of which has an RSVP and a
+// token restricted to it, and the user the owner hands things to.
+type transferTestAccount struct {
+	databaseConnection *gorm.DB
+	previousOwner      models.User
+	recipient          models.User
+	sharedVenue        models.Venue
+	offeredEvent       models.Event
+	remainingEvent     models.Event
+	scopedToken        *models.APIToken
+}
+
+func newTransferTestAccount(t *testing.T) *transferTestAccount {
+	t.Helper()
+	databaseConnection := testdb.OpenMigrated(t)
+	testAccount := &transferTestAccount{databaseConnection: databaseConnection}
+	testAccount.previousOwner, testAccount.offeredEvent = createTestEvent(t, databaseConnection, "owner@example.com")
+	testAccount.recipient = createTestUser(t, databaseConnection, "recipient@example.com")
+	testAccount.sharedVenue = models.Venue{Name: "Hall", Address: "1 Main St", UserID: testAccount.previousOwner.ID}
+	if err := testAccount.sharedVenue.Create(databaseConnection); err != nil {
+		t.Fatalf("creating the venue: %v", err)
+	}
+	testAccount.offeredEvent.VenueID = &testAccount.sharedVenue.ID
+	if err := databaseConnection.Save(&testAccount.offeredEvent).Error; err != nil {
+		t.Fatalf("linking the venue: %v", err)
+	}
+	testAccount.remainingEvent = models.Event{Title: "Other party", StartTime: testAccount.offeredEvent.StartTime, EndTime: testAccount.offeredEvent.EndTime,
+		UserID: testAccount.previousOwner.ID, VenueID: &testAccount.sharedVenue.ID}
+	if err := testAccount.remainingEvent.Create(databaseConnection); err != nil {
+		t.Fatalf("creating the other event: %v", err)
+	}
+	rsvpRecord := models.RSVP{Name: "Guest", EventID: testAccount.offeredEvent.ID}
+	if err := rsvpRecord.Create(databaseConnection); err != nil {
+		t.Fatalf("creating the RSVP: %v", err)
+	}
+	scopedToken, _, err := models.IssueAPIToken(databaseConnection, testAccount.previousOwner.ID, "scoped", config.TokenScopeReadWrite, &testAccount.offeredEvent.ID)
+	if err != nil {
+		t.Fatalf("issuing the token: %v", err)
+	}
+	testAccount.scopedToken = scopedToken
+	return testAccount
+}
+
+// offer records a pending transfer of the resource from the previous owner to the recipient.
+func (testAccount *transferTestAccount) offer(t *testing.T, resourceType string, resourceIdentifier string, includeLinkedEvents bool) *models.OwnershipTransfer {
+	t.Helper()
+	pendingTransfer, err := models.OfferOwnershipTransfer(testAccount.databaseConnection, resourceType, resourceIdentifier, &testAccount.previousOwner, "Recipient@Example.com", includeLinkedEvents)
+	if err != nil {
+		t.Fatalf("offering the %s: %v", resourceType, err)
+	}
+	if !pendingTransfer.IsAddressedTo(&testAccount.recipient) || pendingTransfer.IsAddressedTo(&testAccount.previousOwner) {
+		t.Fatalf("the transfer is addressed to %q", pendingTransfer.RecipientEmail)
+	}
+	return pendingTransfer
+}
+
+// reloadEvent returns the stored state of the event.
+func (testAccount *transferTestAccount) reloadEvent(t *testing.T, eventIdentifier string) models.Event {
+	t.Helper()
+	var storedEvent models.Event
+	if err := storedEvent.FindByID(testAccount.databaseConnection, eventIdentifier); err != nil {
+		t.Fatalf("loading event %s: %v", eventIdentifier, err)
+	}
+	return storedEvent
+}
+
+// reloadVenue returns the stored state of the venue.
+func (testAccount *transferTestAccount) reloadVenue(t *testing.T, venueIdentifier string) models.Venue {
+	t.Helper()
+	var storedVenue models.Venue
+	if err := storedVenue.FindByID(testAccount.databaseConnection, venueIdentifier); err != nil {
+		t.Fatalf("loading venue %s: %v", venueIdentifier, err)
+	}
+	return storedVenue
+}
+
+func TestAcceptEventTransferCopiesASharedVenueAndRevokesTokens(t *testing.T) {
+	testAccount := newTransferTestAccount(t)
+	pendingTransfer := testAccount.offer(t, config.TransferResourceEvent, testAccount.offeredEvent.ID, false)
+	if _, err := models.OfferOwnershipTransfer(testAccount.databaseConnection, config.TransferResourceEvent, testAccount.offeredEvent.ID, &testAccount.previousOwner, "someone@example.com", false); !errors.Is(err, models.ErrTransferAlreadyPending) {
+		t.Errorf("a second offer of the event: error = %v, want ErrTransferAlreadyPending", err)
+	}
+	if _, err := models.OfferOwnershipTransfer(testAccount.databaseConnection, config.TransferResourceVenue, testAccount.sharedVenue.ID, &testAccount.previousOwner, "OWNER@example.com", false); !errors.Is(err, models.ErrTransferToSelf) {
+		t.Errorf("an offer to the owner: error = %v, want ErrTransferToSelf", err)
+	}
+
+	transferOutcome, err := pendingTransfer.Accept(testAccount.databaseConnection, &testAccount.recipient)
+	if err != nil {
+		t.Fatalf("Accept() error = %v", err)
+	}
+	if transferOutcome != (models.TransferOutcome{MovedEventCount: 1, RevokedTokenCount: 1, VenueCopied: true}) {
+		t.Errorf("Accept() = %+v, want one moved event, one revoked token and a copied venue", transferOutcome)
+	}
+	movedEvent := testAccount.reloadEvent(t, testAccount.offeredEvent.ID)
+	if movedEvent.UserID != testAccount.recipient.ID {
+		t.Errorf("the event belongs to %q, want the recipient", movedEvent.UserID)
+	}
+	if movedEvent.VenueID == nil || *movedEvent.VenueID == testAccount.sharedVenue.ID {
+		t.Fatalf("the moved event points at %v, want a copy of the shared venue", movedEvent.VenueID)
+	}
+	venueCopy := testAccount.reloadVenue(t, *movedEvent.VenueID)
+	if venueCopy.UserID != testAccount.recipient.ID || venueCopy.Name != testAccount.sharedVenue.Name || venueCopy.Address != testAccount.sharedVenue.Address {
+		t.Errorf("venue copy = %+v, want the recipient's copy of %+v", venueCopy, testAccount.sharedVenue)
+	}
+	if keptVenue := testAccount.reloadVenue(t, testAccount.sharedVenue.ID); keptVenue.UserID != testAccount.previousOwner.ID {
+		t.Errorf("the shared venue moved to %q", keptVenue.UserID)
+	}
+	if remainingEvent := testAccount.reloadEvent(t, testAccount.remainingEvent.ID); remainingEvent.VenueID == nil || *remainingEvent.VenueID != testAccount.sharedVenue.ID {
+		t.Errorf("the event that stayed points at %v, want the shared venue", remainingEvent.VenueID)
+	}
+	if rsvpCount := countRows(t, testAccount.databaseConnection, &models.RSVP{}, "event_id = ?", testAccount.offeredEvent.ID); rsvpCount != 1 {
+		t.Errorf("the moved event has %d RSVPs, want 1", rsvpCount)
+	}
+	var storedToken models.APIToken
+	if err := testAccount.databaseConnection.First(&storedToken, "id = ?", testAccount.scopedToken.ID).Error; err != nil || !storedToken.IsRevoked() {
+		t.Errorf("the previous owner's token of the event is not revoked: %v", err)
+	}
+	if _, err := pendingTransfer.Accept(testAccount.databaseConnection, &testAccount.recipient); !errors.Is(err, models.ErrTransferStale) {
+		t.Errorf("accepting twice: error = %v, want ErrTransferStale", err)
+	}
+}
+
+func TestAcceptEventTransferMovesAnUnsharedVenue(t *testing.T) {
+	testAccount := newTransferTestAccount(t)
+	testAccount.remainingEvent.VenueID = nil
+	if err := testAccount.databaseConnection.Save(&testAccount.remainingEvent).Error; err != nil {
+		t.Fatalf("unlinking the other event: %v", err)
+	}
+	venueTransfer := testAccount.offer(t, config.TransferResourceVenue, testAccount.sharedVenue.ID, false)
+	eventTransfer, err := models.OfferOwnershipTransfer(testAccount.databaseConnection, config.TransferResourceEvent, testAccount.offeredEvent.ID, &testAccount.previousOwner, testAccount.recipient.Email, false)
+	if err != nil {
+		t.Fatalf("offering the event: %v", err)
+	}
+
+	transferOutcome, err := eventTransfer.Accept(testAccount.databaseConnection, &testAccount.recipient)
+	if err != nil || transferOutcome.VenueCopied {
+		t.Fatalf("Accept() = %+v, %v, want the venue moved rather than copied", transferOutcome, err)
+	}
+	if movedVenue := testAccount.reloadVenue(t, testAccount.sharedVenue.ID); movedVenue.UserID != testAccount.recipient.ID {
+		t.Errorf("the venue belongs to %q, want the recipient", movedVenue.UserID)
+	}
+	// The venue went along with the event, so its own transfer is closed.
+	if err := new(models.OwnershipTransfer).FindPendingByID(testAccount.databaseConnection, venueTransfer.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("the venue transfer is still pending: %v", err)
+	}
+}
+
+func TestAcceptVenueTransfer(t *testing.T) {
+	testCases := []struct {
+		name                string
+		includeLinkedEvents bool
+		wantOutcome         models.TransferOutcome
+		wantEventsMoved     bool
+	}{
+		{name: "venue only", includeLinkedEvents: false, wantOutcome: models.TransferOutcome{VenueCopied: true}},
+		{name: "with its events", includeLinkedEvents: true, wantOutcome: models.TransferOutcome{MovedEventCount: 2, RevokedTokenCount: 1}, wantEventsMoved: true},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			testAccount := newTransferTestAccount(t)
+			pendingTransfer := testAccount.offer(t, config.TransferResourceVenue, testAccount.sharedVenue.ID, testCase.includeLinkedEvents)
+			transferOutcome, err := pendingTransfer.Accept(testAccount.databaseConnection, &testAccount.recipient)
+			if err != nil {
+				t.Fatalf("Accept() error = %v", err)
+			}
+			if transferOutcome != testCase.wantOutcome {
+				t.Errorf("Accept() = %+v, want %+v", transferOutcome, testCase.wantOutcome)
+			}
+			if movedVenue := testAccount.reloadVenue(t, testAccount.sharedVenue.ID); movedVenue.UserID != testAccount.recipient.ID {
+				t.Errorf("the venue belongs to %q, want the recipient", movedVenue.UserID)
+			}
+			for _, eventIdentifier := range []string{testAccount.offeredEvent.ID, testAccount.remainingEvent.ID} {
+				storedEvent := testAccount.reloadEvent(t, eventIdentifier)
+				if (storedEvent.UserID == testAccount.recipient.ID) != testCase.wantEventsMoved {
+					t.Errorf("event %s belongs to %q", eventIdentifier, storedEvent.UserID)
+				}
+				// An event and its venue always share an owner.
+				if storedEvent.VenueID == nil {
+					t.Fatalf("event %s lost its venue", eventIdentifier)
+				}
+				if eventVenue := testAccount.reloadVenue(t, *storedEvent.VenueID); eventVenue.UserID != storedEvent.UserID {
+					t.Errorf("event %s of %q is held at a venue of %q", eventIdentifier, storedEvent.UserID, eventVenue.UserID)
+				}
+			}
+		})
+	}
+}
+
+func TestDeclinedAndLapsedTransfersMoveNothing(t *testing.T) {
+	testAccount := newTransferTestAccount(t)
+	declinedTransfer := testAccount.offer(t, config.TransferResourceEvent, testAccount.offeredEvent.ID, false)
+	if err := declinedTransfer.Resolve(testAccount.databaseConnection, config.TransferStatusDeclined); err != nil {
+		t.Fatalf("declining: %v", err)
+	}
+	if err := declinedTransfer.Resolve(testAccount.databaseConnection, config.TransferStatusCancelled); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("cancelling a declined transfer: error = %v, want gorm.ErrRecordNotFound", err)
+	}
+	if _, err := declinedTransfer.Accept(testAccount.databaseConnection, &testAccount.recipient); !errors.Is(err, models.ErrTransferStale) {
+		t.Errorf("accepting a declined transfer: error = %v, want ErrTransferStale", err)
+	}
+	if storedEvent := testAccount.reloadEvent(t, testAccount.offeredEvent.ID); storedEvent.UserID != testAccount.previousOwner.ID {
+		t.Errorf("a declined transfer moved the event to %q", storedEvent.UserID)
+	}
+
+	// The owner may offer again; an offer nobody answers within config.TransferOfferLifetime lapses.
+	lapsedTransfer := testAccount.offer(t, config.TransferResourceEvent, testAccount.offeredEvent.ID, false)
+	if !lapsedTransfer.ExpiresAt().Equal(lapsedTransfer.CreatedAt.Add(config.TransferOfferLifetime)) {
+		t.Errorf("ExpiresAt() = %v, want %v after the offer", lapsedTransfer.ExpiresAt(), time.Duration(config.TransferOfferLifetime))
+	}
+	if err := testAccount.databaseConnection.Model(lapsedTransfer).UpdateColumn("created_at", time.Now().Add(-config.TransferOfferLifetime-time.Minute)).Error; err != nil {
+		t.Fatalf("backdating the offer: %v", err)
+	}
+	if err := new(models.OwnershipTransfer).FindPendingByID(testAccount.databaseConnection, lapsedTransfer.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("FindPendingByID(lapsed) error = %v, want gorm.ErrRecordNotFound", err)
+	}
+	if incomingTransfers, err := models.FindPendingTransfersForUser(testAccount.databaseConnection, &testAccount.recipient); err != nil || len(incomingTransfers) != 0 {
+		t.Errorf("FindPendingTransfersForUser() = %d transfers, %v, want none", len(incomingTransfers), err)
+	}
+	if outgoingTransfers, err := models.FindPendingTransfersFromUser(testAccount.databaseConnection, testAccount.previousOwner.ID); err != nil || len(outgoingTransfers) != 0 {
+		t.Errorf("FindPendingTransfersFromUser() = %d transfers, %v, want none", len(outgoingTransfers), err)
+	}
+	if _, err := lapsedTransfer.Accept(testAccount.databaseConnection, &testAccount.recipient); !errors.Is(err, models.ErrTransferStale) {
+		t.Errorf("accepting a lapsed transfer: error = %v, want ErrTransferStale", err)
+	}
+	if storedEvent := testAccount.reloadEvent(t, testAccount.offeredEvent.ID); storedEvent.UserID != testAccount.previousOwner.ID {
+		t.Errorf("a lapsed transfer moved the event to %q", storedEvent.UserID)
+	}
+	testAccount.offer(t, config.TransferResourceEvent, testAccount.offeredEvent.ID, false)
+}
+
+func TestTransferOfADeletedEventIsStale(t *testing.T) {
+	testAccount := newTransferTestAccount(t)
+	pendingTransfer := testAccount.offer(t, config.TransferResourceEvent, testAccount.offeredEvent.ID, false)
+	if err := testAccount.offeredEvent.DeleteWithRSVPs(testAccount.databaseConnection); err != nil {
+		t.Fatalf("deleting the event: %v", err)
+	}
+	if _, err := pendingTransfer.Accept(testAccount.databaseConnection, &testAccount.recipient); !errors.Is(err, models.ErrTransferStale) {
+		t.Errorf("accepting the transfer of a deleted event: error = %v, want ErrTransferStale", err)
+	}
+}
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"time"
+
 	"github.com/temirov/RSVP/pkg/config" // Import config
 	"gorm.io/gorm"
 )
@@ -21,6 +23,17 @@ type RSVP struct {
 	ExtraGuests int `gorm:"column:extra_guests;default:0"`
 	// EventID links the RSVP to the parent Event (required). Indexed for performance.
 	EventID string `gorm:"type:varchar(8);not null;index"`
+	// FirstViewedAt is when the invitee first opened their invitation link (nil if never opened).
+	FirstViewedAt *time.Time `gorm:"column:first_viewed_at"`
+	// LastViewedAt is when the invitee most recently opened their invitation link.
+	LastViewedAt *time.Time `gorm:"column:last_viewed_at"`
+	// ViewCount is the number of times the invitee opened their invitation link.
+	// Organizer previews and link-preview bots are not counted.
+	ViewCount int `gorm:"column:view_count;default:0"`
+	// RespondedAt is when the RSVP first received a Yes/No answer; cleared if it is reset to Pending.
+	RespondedAt *time.Time `gorm:"column:responded_at"`
+	// LinkVersion is signed into invitation links; raising it revokes every link issued before.
+	LinkVersion int `gorm:"column:link_version;not null;default:0"`
 }
 
 // BeforeCreate is a GORM hook executed before a new RSVP record is inserted.
@@ -42,6 +55,38 @@ func (rsvpRecord *RSVP) BeforeCreate(dbTransaction *gorm.DB) error {
 	return nil
 }
 
+// IsAnswered reports whether the RSVP holds a Yes/No answer rather than Pending.
+func (rsvpRecord *RSVP) IsAnswered() bool {
+	return rsvpRecord.Response != "" && rsvpRecord.Response != config.RSVPResponsePending
+}
+
+// TrackResponseTime keeps RespondedAt in step with the current response:
+// it is stamped the first time the RSVP is answered and cleared when the RSVP returns to Pending.
+func (rsvpRecord *RSVP) TrackResponseTime(currentTime time.Time) {
+	if !rsvpRecord.IsAnswered() {
+		rsvpRecord.RespondedAt = nil
+		return
+	}
+	if rsvpRecord.RespondedAt == nil {
+		rsvpRecord.RespondedAt = &currentTime
+	}
+}
+
+// RecordView registers that the invitee opened their invitation link.
+// Only the view columns are written, so UpdatedAt keeps reflecting real changes to the RSVP.
+func (rsvpRecord *RSVP) RecordView(databaseConnection *gorm.DB, viewTime time.Time) error {
+	if rsvpRecord.FirstViewedAt == nil {
+		rsvpRecord.FirstViewedAt = &viewTime
+	}
+	rsvpRecord.LastViewedAt = &viewTime
+	rsvpRecord.ViewCount++
+	return databaseConnection.Model(rsvpRecord).UpdateColumns(map[string]interface{}{
+		"first_viewed_at": gorm.Expr("COALESCE(first_viewed_at, ?)", viewTime),
+		"last_viewed_at":  viewTime,
+		"view_count":      gorm.Expr("view_count + 1"),
+	}).Error
+}
+
 // FindByCode retrieves a single RSVP record from the database based on its ID (which serves as the public code).
 // It populates the receiver 'rsvpRecord' struct with the found data.
 // Returns an error (like gorm.ErrRecordNotFound) if the record is not found or if a database error occurs.
@@ -69,6 +114,22 @@ func FindRSVPsByEventID(databaseConnection *gorm.DB, parentEventID string) ([]RS
 	return eventRSVPs, result.Error
 }
 
+// CountRSVPsByEventID returns the total number of RSVPs for an event and how many of them have been answered.
+// An RSVP counts as answered when its response is neither empty nor Pending, matching the events list statistics.
+func CountRSVPsByEventID(databaseConnection *gorm.DB, parentEventID string) (int64, int64, error) {
+	var totalCount int64
+	if err := databaseConnection.Model(&RSVP{}).Where("event_id = ?", parentEventID).Count(&totalCount).Error; err != nil {
+		return 0, 0, err
+	}
+	var answeredCount int64
+	answeredQuery := databaseConnection.Model(&RSVP{}).
+		Where("event_id = ? AND response <> '' AND response <> ?", parentEventID, config.RSVPResponsePending)
+	if err := answeredQuery.Count(&answeredCount).Error; err != nil {
+		return 0, 0, err
+	}
+	return totalCount, answeredCount, nil
+}
+
 // Create inserts the current RSVP struct instance (the receiver 'rsvpRecord') as a new record into the database.
 // Triggers the BeforeCreate hook to generate an ID if necessary.
 // Returns an error if the database insertion fails.
@@ -82,3 +143,36 @@ func (rsvpRecord *RSVP) Create(databaseConnection *gorm.DB) error {
 func (rsvpRecord *RSVP) Save(databaseConnection *gorm.DB) error {
 	return databaseConnection.Save(rsvpRecord).Error
 }
+
+// RegenerateCode replaces the RSVP's public code (its ID) with a new unique one, invalidating the old link and QR code.
+// It returns the previous code.
+func (rsvpRecord *RSVP) RegenerateCode(databaseConnection *gorm.DB) (string, error) {
+	previousCode := rsvpRecord.ID
+	newCode, generateError := EnsureUniqueID(databaseConnection, config.TableRSVPs, GenerateBase36ID)
+	if generateError != nil {
+		return "", generateError
+	}
+	if err := databaseConnection.Unscoped().Model(&RSVP{}).
+		Where("id = ?", previousCode).
+		UpdateColumns(map[string]interface{}{"id": newCode, "updated_at": time.Now()}).Error; err != nil {
+		return "", err
+	}
+	rsvpRecord.ID = newCode
+	return previousCode, nil
+}
+
+// ReissueLink revokes the RSVP's invitation links and QR codes by raising its link version. When the event accepts
+// bare codes, the code itself is replaced too, since the old one would keep working on its own. It returns the
+// code in use before, which equals the current one when only the version changed.
+func (rsvpRecord *RSVP) ReissueLink(databaseConnection *gorm.DB, acceptsBareCodes bool) (string, error) {
+	previousCode := rsvpRecord.ID
+	if err := databaseConnection.Model(&RSVP{}).Where("id = ?", previousCode).
+		UpdateColumns(map[string]interface{}{"link_version": gorm.Expr("link_version + 1"), "updated_at": time.Now()}).Error; err != nil {
+		return "", err
+	}
+	rsvpRecord.LinkVersion++
+	if acceptsBareCodes {
+		return rsvpRecord.RegenerateCode(databaseConnection)
+	}
+	return previousCode, nil
+}
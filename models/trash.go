@@ -0,0 +1,333 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/temirov/RSVP/pkg/config"
+	"gorm.io/gorm"
+)
+
+// ErrParentEventDeleted is returned when restoring an RSVP whose event is itself in the trash.
+var ErrParentEventDeleted = errors.New("the RSVP's event is deleted; restore the event first")
+
+// TrashedEvent is a soft-deleted event together with the number of RSVPs deleted along with it.
+type TrashedEvent struct {
+	Event
+	RSVPCount int64
+}
+
+// TrashedRSVP is a soft-deleted RSVP of a live event, with the event's title for display.
+type TrashedRSVP struct {
+	RSVP
+	EventTitle string
+}
+
+// TrashContents lists everything an organizer has deleted, most recently deleted first.
+type TrashContents struct {
+	Events []TrashedEvent
+	Venues []Venue
+	RSVPs  []TrashedRSVP
+}
+
+// PurgeCounts tallies permanently deleted records.
+type PurgeCounts struct {
+	Events int64 `json:"events"`
+	Venues int64 `json:"venues"`
+	RSVPs  int64 `json:"rsvps"`
+}
+
+// FindTrash loads the soft-deleted events, venues and RSVPs of a workspace that the user may restore: those of
+// their personal workspace when organizationIdentifier is nil, otherwise those of the organization. In an
+// organization, events are listed to members whose role lets them delete events, RSVPs to members who may manage
+// RSVPs and venues to owners and admins.
+// RSVPs of deleted events are not listed on their own; they come back when the event is restored.
+func FindTrash(databaseConnection *gorm.DB, userIdentifier string, organizationIdentifier *string) (*TrashContents, error) {
+	trashContents := &TrashContents{}
+	workspaceCondition := databaseConnection.Where("user_id = ? AND organization_id IS NULL", userIdentifier)
+	eventWorkspaceCondition := databaseConnection.Where("events.user_id = ? AND events.organization_id IS NULL", userIdentifier)
+	eventRole, mayManageVenues := config.EventRoleOwner, true
+	if organizationIdentifier != nil {
+		organizationRole, err := OrganizationRoleForUser(databaseConnection, *organizationIdentifier, userIdentifier)
+		if err != nil {
+			return nil, err
+		}
+		if organizationRole == "" {
+			return trashContents, nil
+		}
+		eventRole, mayManageVenues = EventRoleForOrganizationRole(organizationRole), OrgRoleAllowsManagement(organizationRole)
+		workspaceCondition = databaseConnection.Where("organization_id = ?", *organizationIdentifier)
+		eventWorkspaceCondition = databaseConnection.Where("events.organization_id = ?", *organizationIdentifier)
+	}
+
+	if RoleAllows(eventRole, PermissionDeleteEvent) {
+		var deletedEvents []Event
+		if err := databaseConnection.Unscoped().Where(workspaceCondition).
+			Where("deleted_at IS NOT NULL").
+			Order("deleted_at DESC").Find(&deletedEvents).Error; err != nil {
+			return nil, err
+		}
+		for _, deletedEvent := range deletedEvents {
+			cascadeRSVPCount, err := countCascadeDeletedRSVPs(databaseConnection, &deletedEvent)
+			if err != nil {
+				return nil, err
+			}
+			trashContents.Events = append(trashContents.Events, TrashedEvent{Event: deletedEvent, RSVPCount: cascadeRSVPCount})
+		}
+	}
+
+	if mayManageVenues {
+		if err := databaseConnection.Unscoped().Where(workspaceCondition).
+			Where("deleted_at IS NOT NULL").
+			Order("deleted_at DESC").Find(&trashContents.Venues).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	if RoleAllows(eventRole, PermissionManageRSVPs) {
+		if err := databaseConnection.Unscoped().Model(&RSVP{}).
+			Select("rsvps.*, events.title AS event_title").
+			Joins("JOIN events ON events.id = rsvps.event_id").
+			Where(eventWorkspaceCondition).
+			Where("events.deleted_at IS NULL AND rsvps.deleted_at IS NOT NULL").
+			Order("rsvps.deleted_at DESC").
+			Scan(&trashContents.RSVPs).Error; err != nil {
+			return nil, err
+		}
+	}
+	return trashContents, nil
+}
+
+// FindDeletedEventForUser retrieves a soft-deleted event the user may restore or purge, which takes the role that
+// allows deleting it. The creator of an organization's event needs that role in the organization like anyone else.
+// It returns gorm.ErrRecordNotFound when the event is not in the trash or the user lacks the role.
+func FindDeletedEventForUser(databaseConnection *gorm.DB, eventIdentifier string, userIdentifier string) (*Event, error) {
+	var deletedEvent Event
+	if err := databaseConnection.Unscoped().
+		Where("id = ? AND deleted_at IS NOT NULL", eventIdentifier).
+		First(&deletedEvent).Error; err != nil {
+		return nil, err
+	}
+	eventRole, err := EventRoleForUser(databaseConnection, &deletedEvent, userIdentifier)
+	if err != nil {
+		return nil, err
+	}
+	if !RoleAllows(eventRole, PermissionDeleteEvent) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &deletedEvent, nil
+}
+
+// FindDeletedVenueForUser retrieves a soft-deleted venue the user may restore or purge: their own personal venue,
+// or a venue of an organization in which they are an owner or admin.
+// It returns gorm.ErrRecordNotFound when the venue is not in the trash or the user may not manage it.
+func FindDeletedVenueForUser(databaseConnection *gorm.DB, venueIdentifier string, userIdentifier string) (*Venue, error) {
+	var deletedVenue Venue
+	if err := databaseConnection.Unscoped().
+		Where("id = ? AND deleted_at IS NOT NULL", venueIdentifier).
+		First(&deletedVenue).Error; err != nil {
+		return nil, err
+	}
+	if deletedVenue.OrganizationID == nil {
+		if deletedVenue.UserID != userIdentifier {
+			return nil, gorm.ErrRecordNotFound
+		}
+		return &deletedVenue, nil
+	}
+	organizationRole, err := OrganizationRoleForUser(databaseConnection, *deletedVenue.OrganizationID, userIdentifier)
+	if err != nil {
+		return nil, err
+	}
+	if !OrgRoleAllowsManagement(organizationRole) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &deletedVenue, nil
+}
+
+// FindDeletedRSVPForUser retrieves a soft-deleted RSVP the user may restore or purge, which takes a role on its
+// event, deleted or not, that allows managing RSVPs.
+// It returns gorm.ErrRecordNotFound when the RSVP is not in the trash or the user lacks the role.
+func FindDeletedRSVPForUser(databaseConnection *gorm.DB, rsvpIdentifier string, userIdentifier string) (*RSVP, error) {
+	var deletedRSVP RSVP
+	if err := databaseConnection.Unscoped().
+		Where("id = ? AND deleted_at IS NOT NULL", rsvpIdentifier).
+		First(&deletedRSVP).Error; err != nil {
+		return nil, err
+	}
+	var parentEvent Event
+	if err := databaseConnection.Unscoped().Where("id = ?", deletedRSVP.EventID).First(&parentEvent).Error; err != nil {
+		return nil, err
+	}
+	eventRole, err := EventRoleForUser(databaseConnection, &parentEvent, userIdentifier)
+	if err != nil {
+		return nil, err
+	}
+	if !RoleAllows(eventRole, PermissionManageRSVPs) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &deletedRSVP, nil
+}
+
+// RestoreRSVP restores a soft-deleted RSVP. It fails with ErrParentEventDeleted while the RSVP's event is in the trash.
+func RestoreRSVP(databaseConnection *gorm.DB, rsvpIdentifier string) error {
+	var deletedRSVP RSVP
+	if err := databaseConnection.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", rsvpIdentifier).First(&deletedRSVP).Error; err != nil {
+		return err
+	}
+	var parentEvent Event
+	if err := parentEvent.FindByID(databaseConnection, deletedRSVP.EventID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrParentEventDeleted
+		}
+		return err
+	}
+	return RestoreSoftDeleted(databaseConnection, &RSVP{}, deletedRSVP.ID)
+}
+
+// PurgeEvent permanently deletes a soft-deleted event with all of its RSVPs, its co-host memberships, its pending
+// ownership transfers and the API tokens restricted to it.
+func PurgeEvent(databaseConnection *gorm.DB, eventIdentifier string) error {
+	return databaseConnection.Transaction(func(databaseTransaction *gorm.DB) error {
+		_, err := purgeEvents(databaseTransaction, []string{eventIdentifier})
+		return err
+	})
+}
+
+// PurgeVenue permanently deletes a soft-deleted venue and its pending ownership transfers. Deleted events that still
+// point at it lose the link.
+func PurgeVenue(databaseConnection *gorm.DB, venueIdentifier string) error {
+	return databaseConnection.Transaction(func(databaseTransaction *gorm.DB) error {
+		purgedCount, err := purgeVenues(databaseTransaction, []string{venueIdentifier})
+		if err == nil && purgedCount == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return err
+	})
+}
+
+// PurgeRSVP permanently deletes a soft-deleted RSVP.
+func PurgeRSVP(databaseConnection *gorm.DB, rsvpIdentifier string) error {
+	purgeResult := databaseConnection.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", rsvpIdentifier).Delete(&RSVP{})
+	if purgeResult.Error == nil && purgeResult.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return purgeResult.Error
+}
+
+// PurgeTrashOlderThan permanently deletes every event, venue and RSVP that was soft-deleted before the cutoff.
+func PurgeTrashOlderThan(databaseConnection *gorm.DB, cutoffTime time.Time) (PurgeCounts, error) {
+	var purgeCounts PurgeCounts
+	transactionError := databaseConnection.Transaction(func(databaseTransaction *gorm.DB) error {
+		var expiredEventIDs []string
+		if err := databaseTransaction.Unscoped().Model(&Event{}).
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoffTime).
+			Pluck("id", &expiredEventIDs).Error; err != nil {
+			return err
+		}
+		purgedRSVPCount, err := purgeEvents(databaseTransaction, expiredEventIDs)
+		if err != nil {
+			return err
+		}
+		purgeCounts.Events = int64(len(expiredEventIDs))
+
+		expiredRSVPsResult := databaseTransaction.Unscoped().
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoffTime).
+			Delete(&RSVP{})
+		if expiredRSVPsResult.Error != nil {
+			return expiredRSVPsResult.Error
+		}
+		purgeCounts.RSVPs = purgedRSVPCount + expiredRSVPsResult.RowsAffected
+
+		var expiredVenueIDs []string
+		if err := databaseTransaction.Unscoped().Model(&Venue{}).
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoffTime).
+			Pluck("id", &expiredVenueIDs).Error; err != nil {
+			return err
+		}
+		purgeCounts.Venues, err = purgeVenues(databaseTransaction, expiredVenueIDs)
+		return err
+	})
+	if transactionError != nil {
+		return PurgeCounts{}, transactionError
+	}
+	return purgeCounts, nil
+}
+
+// purgeEvents permanently deletes the given soft-deleted events, their RSVPs, memberships, pending transfers and the
+// tokens restricted to them.
+// Rows referencing the events go first so that enforced foreign keys are never violated.
+// It returns the number of purged RSVPs, or gorm.ErrRecordNotFound if none of the events is in the trash.
+func purgeEvents(databaseTransaction *gorm.DB, eventIdentifiers []string) (int64, error) {
+	if len(eventIdentifiers) == 0 {
+		return 0, nil
+	}
+	var trashedEventIDs []string
+	if err := databaseTransaction.Unscoped().Model(&Event{}).
+		Where("id IN ? AND deleted_at IS NOT NULL", eventIdentifiers).
+		Pluck("id", &trashedEventIDs).Error; err != nil {
+		return 0, err
+	}
+	if len(trashedEventIDs) == 0 {
+		return 0, gorm.ErrRecordNotFound
+	}
+	if err := databaseTransaction.Unscoped().Where("event_id IN ?", trashedEventIDs).Delete(&APIToken{}).Error; err != nil {
+		return 0, err
+	}
+	if err := databaseTransaction.Unscoped().Where("event_id IN ?", trashedEventIDs).Delete(&EventMembership{}).Error; err != nil {
+		return 0, err
+	}
+	if err := purgePendingTransfers(databaseTransaction, config.TransferResourceEvent, trashedEventIDs); err != nil {
+		return 0, err
+	}
+	rsvpPurgeResult := databaseTransaction.Unscoped().Where("event_id IN ?", trashedEventIDs).Delete(&RSVP{})
+	if rsvpPurgeResult.Error != nil {
+		return 0, rsvpPurgeResult.Error
+	}
+	if err := databaseTransaction.Unscoped().Where("id IN ?", trashedEventIDs).Delete(&Event{}).Error; err != nil {
+		return 0, err
+	}
+	return rsvpPurgeResult.RowsAffected, nil
+}
+
+// purgeVenues permanently deletes the given soft-deleted venues after unlinking any events that still reference them.
+func purgeVenues(databaseTransaction *gorm.DB, venueIdentifiers []string) (int64, error) {
+	if len(venueIdentifiers) == 0 {
+		return 0, nil
+	}
+	var trashedVenueIDs []string
+	if err := databaseTransaction.Unscoped().Model(&Venue{}).
+		Where("id IN ? AND deleted_at IS NOT NULL", venueIdentifiers).
+		Pluck("id", &trashedVenueIDs).Error; err != nil {
+		return 0, err
+	}
+	if len(trashedVenueIDs) == 0 {
+		return 0, nil
+	}
+	if err := databaseTransaction.Unscoped().Model(&Event{}).
+		Where("venue_id IN ?", trashedVenueIDs).
+		UpdateColumn("venue_id", nil).Error; err != nil {
+		return 0, err
+	}
+	if err := purgePendingTransfers(databaseTransaction, config.TransferResourceVenue, trashedVenueIDs); err != nil {
+		return 0, err
+	}
+	venuePurgeResult := databaseTransaction.Unscoped().Where("id IN ?", trashedVenueIDs).Delete(&Venue{})
+	return venuePurgeResult.RowsAffected, venuePurgeResult.Error
+}
+
+// purgePendingTransfers deletes the pending transfers of purged items, which would otherwise linger in both users' lists.
+// Resolved transfers stay as a record of past handovers.
+func purgePendingTransfers(databaseTransaction *gorm.DB, resourceType string, resourceIdentifiers []string) error {
+	return databaseTransaction.Unscoped().
+		Where("resource_type = ? AND resource_id IN ? AND status = ?", resourceType, resourceIdentifiers, config.TransferStatusPending).
+		Delete(&OwnershipTransfer{}).Error
+}
+
+// countCascadeDeletedRSVPs counts the RSVPs that RestoreEventWithRSVPs would bring back with the event.
+func countCascadeDeletedRSVPs(databaseConnection *gorm.DB, deletedEvent *Event) (int64, error) {
+	var cascadeRSVPCount int64
+	err := databaseConnection.Unscoped().Model(&RSVP{}).
+		Where("event_id = ? AND deleted_at >= ?", deletedEvent.ID, cascadeWindowStart(deletedEvent)).
+		Count(&cascadeRSVPCount).Error
+	return cascadeRSVPCount, err
+}
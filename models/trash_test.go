@@ -0,0 +1,378 @@
+package models_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/temirov/RSVP/models"
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/testdb"
+	"gorm.io/gorm"
+)
+
+// createTestUser stores a user with the given address.
+func createTestUser(t *testing.T, databaseConnection *gorm.DB, emailAddress string) models.User {
+	t.Helper()
+	userRecord := models.User{Email: emailAddress}
+	if err := databaseConnection.Create(&userRecord).Error; err != nil {
+		t.Fatalf("creating %s: %v", emailAddress, err)
+	}
+	return userRecord
+}
+
+// addTestMember makes the user an accepted member of the organization with the role.
+func addTestMember(t *testing.T, databaseConnection *gorm.DB, organization *models.Organization, memberUser *models.User, memberRole string) *models.OrganizationMember {
+	t.Helper()
+	member, err := models.InviteOrganizationMember(databaseConnection, organization.ID, memberUser.Email, memberRole, organization.CreatedByUserID)
+	if err != nil {
+		t.Fatalf("inviting %s: %v", memberUser.Email, err)
+	}
+	if _, err := models.AcceptPendingOrganizationInvitations(databaseConnection, memberUser); err != nil {
+		t.Fatalf("accepting the invitation of %s: %v", memberUser.Email, err)
+	}
+	if err := databaseConnection.First(member, "id = ?", member.ID).Error; err != nil {
+		t.Fatalf("reloading the membership of %s: %v", memberUser.Email, err)
+	}
+	return member
+}
+
+// createTestOrganizationEvent stores an organization owned by ownerUser and an event in it created by creatorUser,
+// with a venue of the organization and one RSVP.
+func createTestOrganizationEvent(t *testing.T, databaseConnection *gorm.DB, ownerUser *models.User, creatorUser *models.User) (*models.Organization, models.Event, models.RSVP) {
+	t.Helper()
+	organization, err := models.CreateOrganization(databaseConnection, "Club", ownerUser)
+	if err != nil {
+		t.Fatalf("creating the organization: %v", err)
+	}
+	organizationVenue := models.Venue{Name: "Hall", UserID: creatorUser.ID, OrganizationID: &organization.ID}
+	if err := databaseConnection.Create(&organizationVenue).Error; err != nil {
+		t.Fatalf("creating the venue: %v", err)
+	}
+	startTime := time.Date(2026, time.June, 1, 18, 0, 0, 0, time.UTC)
+	eventRecord := models.Event{Title: "Club night", StartTime: startTime, EndTime: startTime.Add(3 * time.Hour),
+		UserID: creatorUser.ID, OrganizationID: &organization.ID, VenueID: &organizationVenue.ID}
+	if err := eventRecord.Create(databaseConnection); err != nil {
+		t.Fatalf("creating the event: %v", err)
+	}
+	rsvpRecord := models.RSVP{Name: "Guest", EventID: eventRecord.ID, Response: config.RSVPResponsePending}
+	if err := rsvpRecord.Create(databaseConnection); err != nil {
+		t.Fatalf("creating the RSVP: %v", err)
+	}
+	return organization, eventRecord, rsvpRecord
+}
+
+func TestOrganizationTrashFollowsOrganizationRoles(t *testing.T) {
+	databaseConnection := testdb.OpenMigrated(t)
+	organizationOwner := createTestUser(t, databaseConnection, "owner@example.com")
+	organizationAdmin := createTestUser(t, databaseConnection, "admin@example.com")
+	eventCreator := createTestUser(t, databaseConnection, "creator@example.com")
+	outsider := createTestUser(t, databaseConnection, "outsider@example.com")
+	organization, eventRecord, rsvpRecord := createTestOrganizationEvent(t, databaseConnection, &organizationOwner, &eventCreator)
+	addTestMember(t, databaseConnection, organization, &organizationAdmin, config.OrgRoleAdmin)
+	creatorMembership := addTestMember(t, databaseConnection, organization, &eventCreator, config.OrgRoleMember)
+
+	if err := databaseConnection.Delete(&models.RSVP{}, "id = ?", rsvpRecord.ID).Error; err != nil {
+		t.Fatalf("deleting the RSVP: %v", err)
+	}
+	if err := databaseConnection.Delete(&models.Venue{}, "id = ?", *eventRecord.VenueID).Error; err != nil {
+		t.Fatalf("deleting the venue: %v", err)
+	}
+	if err := eventRecord.DeleteWithRSVPs(databaseConnection); err != nil {
+		t.Fatalf("deleting the event: %v", err)
+	}
+
+	adminTrash, err := models.FindTrash(databaseConnection, organizationAdmin.ID, &organization.ID)
+	if err != nil {
+		t.Fatalf("FindTrash(admin) error = %v", err)
+	}
+	if len(adminTrash.Events) != 1 || len(adminTrash.Venues) != 1 {
+		t.Errorf("the admin's organization trash has %d events and %d venues, want 1 and 1", len(adminTrash.Events), len(adminTrash.Venues))
+	}
+	// Members edit but do not delete organization events or venues.
+	creatorTrash, err := models.FindTrash(databaseConnection, eventCreator.ID, &organization.ID)
+	if err != nil {
+		t.Fatalf("FindTrash(member) error = %v", err)
+	}
+	if len(creatorTrash.Events) != 0 || len(creatorTrash.Venues) != 0 {
+		t.Errorf("a member's organization trash has %d events and %d venues, want none", len(creatorTrash.Events), len(creatorTrash.Venues))
+	}
+	// The personal workspace of the creator never shows the organization's items.
+	if personalTrash, err := models.FindTrash(databaseConnection, eventCreator.ID, nil); err != nil || len(personalTrash.Events) != 0 {
+		t.Errorf("FindTrash(creator, personal) = %+v, %v, want no events", personalTrash, err)
+	}
+	if outsiderTrash, err := models.FindTrash(databaseConnection, outsider.ID, &organization.ID); err != nil || len(outsiderTrash.Events)+len(outsiderTrash.Venues)+len(outsiderTrash.RSVPs) != 0 {
+		t.Errorf("FindTrash(outsider) = %+v, %v, want nothing", outsiderTrash, err)
+	}
+
+	if _, err := models.FindDeletedEventForUser(databaseConnection, eventRecord.ID, organizationAdmin.ID); err != nil {
+		t.Errorf("FindDeletedEventForUser(admin) error = %v", err)
+	}
+	if _, err := models.FindDeletedVenueForUser(databaseConnection, *eventRecord.VenueID, organizationAdmin.ID); err != nil {
+		t.Errorf("FindDeletedVenueForUser(admin) error = %v", err)
+	}
+	if _, err := models.FindDeletedRSVPForUser(databaseConnection, rsvpRecord.ID, organizationAdmin.ID); err != nil {
+		t.Errorf("FindDeletedRSVPForUser(admin) error = %v", err)
+	}
+
+	// A creator removed from the organization loses every claim on its trash.
+	if _, err := models.RemoveOrganizationMember(databaseConnection, creatorMembership); err != nil {
+		t.Fatalf("removing the creator: %v", err)
+	}
+	if _, err := models.FindDeletedEventForUser(databaseConnection, eventRecord.ID, eventCreator.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("FindDeletedEventForUser(removed creator) error = %v, want gorm.ErrRecordNotFound", err)
+	}
+	if _, err := models.FindDeletedVenueForUser(databaseConnection, *eventRecord.VenueID, eventCreator.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("FindDeletedVenueForUser(removed creator) error = %v, want gorm.ErrRecordNotFound", err)
+	}
+	if _, err := models.FindDeletedRSVPForUser(databaseConnection, rsvpRecord.ID, eventCreator.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("FindDeletedRSVPForUser(removed creator) error = %v, want gorm.ErrRecordNotFound", err)
+	}
+}
+
+func TestPersonalTrashBelongsToTheOwner(t *testing.T) {
+	databaseConnection := testdb.OpenMigrated(t)
+	eventOwner, eventRecord := createTestEvent(t, databaseConnection, "owner@example.com")
+	cohost := createTestUser(t, databaseConnection, "cohost@example.com")
+	if _, err := models.InviteCohost(databaseConnection, &eventRecord, cohost.Email, config.EventRoleEditor, eventOwner.ID); err != nil {
+		t.Fatalf("inviting the co-host: %v", err)
+	}
+	if _, err := models.AcceptPendingInvitations(databaseConnection, &cohost); err != nil {
+		t.Fatalf("accepting the co-host invitation: %v", err)
+	}
+	rsvpRecord := models.RSVP{Name: "Guest", EventID: eventRecord.ID, Response: config.RSVPResponsePending}
+	if err := rsvpRecord.Create(databaseConnection); err != nil {
+		t.Fatalf("creating the RSVP: %v", err)
+	}
+	if err := databaseConnection.Delete(&rsvpRecord).Error; err != nil {
+		t.Fatalf("deleting the RSVP: %v", err)
+	}
+
+	ownerTrash, err := models.FindTrash(databaseConnection, eventOwner.ID, nil)
+	if err != nil || len(ownerTrash.RSVPs) != 1 || ownerTrash.RSVPs[0].EventTitle != eventRecord.Title {
+		t.Errorf("FindTrash(owner) = %+v, %v, want the deleted RSVP with its event title", ownerTrash, err)
+	}
+	// An editing co-host may bring back RSVPs, which they may delete, but not the event.
+	if _, err := models.FindDeletedRSVPForUser(databaseConnection, rsvpRecord.ID, cohost.ID); err != nil {
+		t.Errorf("FindDeletedRSVPForUser(editor) error = %v", err)
+	}
+	if err := eventRecord.DeleteWithRSVPs(databaseConnection); err != nil {
+		t.Fatalf("deleting the event: %v", err)
+	}
+	if _, err := models.FindDeletedEventForUser(databaseConnection, eventRecord.ID, cohost.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("FindDeletedEventForUser(editor) error = %v, want gorm.ErrRecordNotFound", err)
+	}
+	if _, err := models.FindDeletedEventForUser(databaseConnection, eventRecord.ID, eventOwner.ID); err != nil {
+		t.Errorf("FindDeletedEventForUser(owner) error = %v", err)
+	}
+}
+
+// backdateDeletion moves the deletion time of a soft-deleted row of the table into the past.
+func backdateDeletion(t *testing.T, databaseConnection *gorm.DB, tableName string, recordIdentifier string, deletedAt time.Time) {
+	t.Helper()
+	if err := databaseConnection.Table(tableName).Where("id = ?", recordIdentifier).UpdateColumn("deleted_at", deletedAt).Error; err != nil {
+		t.Fatalf("backdating %s %s: %v", tableName, recordIdentifier, err)
+	}
+}
+
+// countRows counts the rows of the model matching the condition, soft-deleted ones included.
+func countRows(t *testing.T, databaseConnection *gorm.DB, modelRecord interface{}, query string, queryArgs ...interface{}) int64 {
+	t.Helper()
+	var rowCount int64
+	if err := databaseConnection.Unscoped().Model(modelRecord).Where(query, queryArgs...).Count(&rowCount).Error; err != nil {
+		t.Fatalf("counting rows: %v", err)
+	}
+	return rowCount
+}
+
+func TestRestoreEventBringsBackOnlyTheRSVPsDeletedWithIt(t *testing.T) {
+	databaseConnection := testdb.OpenMigrated(t)
+	_, eventRecord := createTestEvent(t, databaseConnection, "owner@example.com")
+	var eventRSVPs []models.RSVP
+	for _, guestName := range []string{"Ann", "Bob", "Cid"} {
+		rsvpRecord := models.RSVP{Name: guestName, EventID: eventRecord.ID}
+		if err := rsvpRecord.Create(databaseConnection); err != nil {
+			t.Fatalf("creating the RSVP of %s: %v", guestName, err)
+		}
+		eventRSVPs = append(eventRSVPs, rsvpRecord)
+	}
+	// Ann was removed on her own an hour before the event was deleted.
+	if err := databaseConnection.Delete(&eventRSVPs[0]).Error; err != nil {
+		t.Fatalf("deleting Ann's RSVP: %v", err)
+	}
+	backdateDeletion(t, databaseConnection, config.TableRSVPs, eventRSVPs[0].ID, time.Now().Add(-time.Hour))
+	if err := eventRecord.DeleteWithRSVPs(databaseConnection); err != nil {
+		t.Fatalf("deleting the event: %v", err)
+	}
+
+	ownerTrash, err := models.FindTrash(databaseConnection, eventRecord.UserID, nil)
+	if err != nil || len(ownerTrash.Events) != 1 || ownerTrash.Events[0].RSVPCount != 2 {
+		t.Fatalf("FindTrash() = %+v, %v, want the event with the 2 RSVPs deleted with it", ownerTrash, err)
+	}
+	restoredCount, err := models.RestoreEventWithRSVPs(databaseConnection, eventRecord.ID)
+	if err != nil || restoredCount != 2 {
+		t.Fatalf("RestoreEventWithRSVPs() = %d, %v, want 2", restoredCount, err)
+	}
+	if liveCount := countRows(t, databaseConnection, &models.RSVP{}, "event_id = ? AND deleted_at IS NULL", eventRecord.ID); liveCount != 2 {
+		t.Errorf("the restored event has %d live RSVPs, want 2", liveCount)
+	}
+	if _, err := models.FindDeletedRSVPForUser(databaseConnection, eventRSVPs[0].ID, eventRecord.UserID); err != nil {
+		t.Errorf("Ann's RSVP left the trash with the event: %v", err)
+	}
+	if _, err := models.RestoreEventWithRSVPs(databaseConnection, eventRecord.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("restoring a live event: error = %v, want gorm.ErrRecordNotFound", err)
+	}
+}
+
+func TestRestoreEventRelinksItsVenue(t *testing.T) {
+	databaseConnection := testdb.OpenMigrated(t)
+	eventOwner, eventRecord := createTestEvent(t, databaseConnection, "owner@example.com")
+	otherUser := createTestUser(t, databaseConnection, "other@example.com")
+	ownVenue := models.Venue{Name: "Own hall", UserID: eventOwner.ID}
+	foreignVenue := models.Venue{Name: "Their hall", UserID: otherUser.ID}
+	for _, venueRecord := range []*models.Venue{&ownVenue, &foreignVenue} {
+		if err := venueRecord.Create(databaseConnection); err != nil {
+			t.Fatalf("creating %s: %v", venueRecord.Name, err)
+		}
+	}
+	secondEvent := models.Event{Title: "Second", StartTime: eventRecord.StartTime, EndTime: eventRecord.EndTime, UserID: eventOwner.ID, VenueID: &foreignVenue.ID}
+	eventRecord.VenueID = &ownVenue.ID
+	if err := databaseConnection.Save(&eventRecord).Error; err != nil {
+		t.Fatalf("linking the venue: %v", err)
+	}
+	if err := secondEvent.Create(databaseConnection); err != nil {
+		t.Fatalf("creating the second event: %v", err)
+	}
+	// Both events go to the trash before their venues, so they keep pointing at them.
+	for _, deletedEvent := range []*models.Event{&eventRecord, &secondEvent} {
+		if err := deletedEvent.DeleteWithRSVPs(databaseConnection); err != nil {
+			t.Fatalf("deleting %s: %v", deletedEvent.Title, err)
+		}
+	}
+	for _, deletedVenue := range []*models.Venue{&ownVenue, &foreignVenue} {
+		if err := deletedVenue.Delete(databaseConnection); err != nil {
+			t.Fatalf("deleting %s: %v", deletedVenue.Name, err)
+		}
+	}
+
+	if _, err := models.RestoreEventWithRSVPs(databaseConnection, eventRecord.ID); err != nil {
+		t.Fatalf("restoring the event: %v", err)
+	}
+	var restoredEvent models.Event
+	if err := restoredEvent.FindByID(databaseConnection, eventRecord.ID); err != nil || restoredEvent.VenueID == nil || *restoredEvent.VenueID != ownVenue.ID {
+		t.Errorf("restored event venue = %v, %v, want %q", restoredEvent.VenueID, err, ownVenue.ID)
+	}
+	if err := new(models.Venue).FindByID(databaseConnection, ownVenue.ID); err != nil {
+		t.Errorf("the owner's venue was not restored with the event: %v", err)
+	}
+
+	// A trashed venue of another user is never restored on the owner's behalf; the event loses it instead.
+	if _, err := models.RestoreEventWithRSVPs(databaseConnection, secondEvent.ID); err != nil {
+		t.Fatalf("restoring the second event: %v", err)
+	}
+	var restoredSecondEvent models.Event
+	if err := restoredSecondEvent.FindByID(databaseConnection, secondEvent.ID); err != nil || restoredSecondEvent.VenueID != nil {
+		t.Errorf("second event venue = %v, %v, want none", restoredSecondEvent.VenueID, err)
+	}
+	if err := new(models.Venue).FindByID(databaseConnection, foreignVenue.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("the other user's venue left the trash: %v", err)
+	}
+}
+
+func TestPurgeTrashOlderThan(t *testing.T) {
+	databaseConnection := testdb.OpenMigrated(t)
+	eventOwner, expiredEvent := createTestEvent(t, databaseConnection, "owner@example.com")
+	cohost := createTestUser(t, databaseConnection, "cohost@example.com")
+	recentEvent := models.Event{Title: "Recent", StartTime: expiredEvent.StartTime, EndTime: expiredEvent.EndTime, UserID: eventOwner.ID}
+	liveEvent := models.Event{Title: "Live", StartTime: expiredEvent.StartTime, EndTime: expiredEvent.EndTime, UserID: eventOwner.ID}
+	for _, eventRecord := range []*models.Event{&recentEvent, &liveEvent} {
+		if err := eventRecord.Create(databaseConnection); err != nil {
+			t.Fatalf("creating %s: %v", eventRecord.Title, err)
+		}
+	}
+	expiredVenue := models.Venue{Name: "Old hall", UserID: eventOwner.ID}
+	if err := expiredVenue.Create(databaseConnection); err != nil {
+		t.Fatalf("creating the venue: %v", err)
+	}
+	expiredRSVP := models.RSVP{Name: "Ann", EventID: expiredEvent.ID}
+	expiredLiveEventRSVP := models.RSVP{Name: "Bob", EventID: liveEvent.ID}
+	for _, rsvpRecord := range []*models.RSVP{&expiredRSVP, &expiredLiveEventRSVP} {
+		if err := rsvpRecord.Create(databaseConnection); err != nil {
+			t.Fatalf("creating the RSVP of %s: %v", rsvpRecord.Name, err)
+		}
+	}
+	if _, err := models.InviteCohost(databaseConnection, &expiredEvent, cohost.Email, config.EventRoleEditor, eventOwner.ID); err != nil {
+		t.Fatalf("inviting the co-host: %v", err)
+	}
+	if _, _, err := models.IssueAPIToken(databaseConnection, eventOwner.ID, "scoped", config.TokenScopeRead, &expiredEvent.ID); err != nil {
+		t.Fatalf("issuing the scoped token: %v", err)
+	}
+	for _, offeredTransfer := range []struct{ resourceType, resourceID string }{
+		{config.TransferResourceEvent, expiredEvent.ID}, {config.TransferResourceVenue, expiredVenue.ID}, {config.TransferResourceEvent, recentEvent.ID},
+	} {
+		if _, err := models.OfferOwnershipTransfer(databaseConnection, offeredTransfer.resourceType, offeredTransfer.resourceID, &eventOwner, cohost.Email, false); err != nil {
+			t.Fatalf("offering the %s %s: %v", offeredTransfer.resourceType, offeredTransfer.resourceID, err)
+		}
+	}
+
+	for _, deletedEvent := range []*models.Event{&expiredEvent, &recentEvent} {
+		if err := deletedEvent.DeleteWithRSVPs(databaseConnection); err != nil {
+			t.Fatalf("deleting %s: %v", deletedEvent.Title, err)
+		}
+	}
+	if err := expiredVenue.Delete(databaseConnection); err != nil {
+		t.Fatalf("deleting the venue: %v", err)
+	}
+	if err := databaseConnection.Delete(&expiredLiveEventRSVP).Error; err != nil {
+		t.Fatalf("deleting Bob's RSVP: %v", err)
+	}
+	longAgo := time.Now().Add(-48 * time.Hour)
+	backdateDeletion(t, databaseConnection, config.TableEvents, expiredEvent.ID, longAgo)
+	backdateDeletion(t, databaseConnection, config.TableRSVPs, expiredRSVP.ID, longAgo)
+	backdateDeletion(t, databaseConnection, config.TableVenues, expiredVenue.ID, longAgo)
+	backdateDeletion(t, databaseConnection, config.TableRSVPs, expiredLiveEventRSVP.ID, longAgo)
+
+	purgeCounts, err := models.PurgeTrashOlderThan(databaseConnection, time.Now().Add(-24*time.Hour))
+	if err != nil {
+		t.Fatalf("PurgeTrashOlderThan() error = %v", err)
+	}
+	if purgeCounts != (models.PurgeCounts{Events: 1, Venues: 1, RSVPs: 2}) {
+		t.Errorf("PurgeTrashOlderThan() = %+v, want 1 event, 1 venue and 2 RSVPs", purgeCounts)
+	}
+	if remainingCount := countRows(t, databaseConnection, &models.Event{}, "id = ?", expiredEvent.ID); remainingCount != 0 {
+		t.Error("the expired event is still stored")
+	}
+	if remainingCount := countRows(t, databaseConnection, &models.Event{}, "id IN ?", []string{recentEvent.ID, liveEvent.ID}); remainingCount != 2 {
+		t.Errorf("%d of the recent and live events remain, want both", remainingCount)
+	}
+	if remainingCount := countRows(t, databaseConnection, &models.EventMembership{}, "event_id = ?", expiredEvent.ID); remainingCount != 0 {
+		t.Errorf("%d memberships of the purged event remain", remainingCount)
+	}
+	if remainingCount := countRows(t, databaseConnection, &models.APIToken{}, "event_id = ?", expiredEvent.ID); remainingCount != 0 {
+		t.Errorf("%d tokens of the purged event remain", remainingCount)
+	}
+	if remainingCount := countRows(t, databaseConnection, &models.OwnershipTransfer{}, "resource_id IN ?", []string{expiredEvent.ID, expiredVenue.ID}); remainingCount != 0 {
+		t.Errorf("%d pending transfers of purged items remain", remainingCount)
+	}
+	if remainingCount := countRows(t, databaseConnection, &models.OwnershipTransfer{}, "resource_id = ?", recentEvent.ID); remainingCount != 1 {
+		t.Errorf("the transfer of the recently deleted event was purged")
+	}
+}
+
+func TestPurgeEventRemovesItsPendingTransfer(t *testing.T) {
+	databaseConnection := testdb.OpenMigrated(t)
+	eventOwner, eventRecord := createTestEvent(t, databaseConnection, "owner@example.com")
+	recipient := createTestUser(t, databaseConnection, "recipient@example.com")
+	if _, err := models.OfferOwnershipTransfer(databaseConnection, config.TransferResourceEvent, eventRecord.ID, &eventOwner, recipient.Email, false); err != nil {
+		t.Fatalf("offering the event: %v", err)
+	}
+	if err := eventRecord.DeleteWithRSVPs(databaseConnection); err != nil {
+		t.Fatalf("deleting the event: %v", err)
+	}
+	if err := models.PurgeEvent(databaseConnection, eventRecord.ID); err != nil {
+		t.Fatalf("PurgeEvent() error = %v", err)
+	}
+	if incomingTransfers, err := models.FindPendingTransfersForUser(databaseConnection, &recipient); err != nil || len(incomingTransfers) != 0 {
+		t.Errorf("FindPendingTransfersForUser() = %d transfers, %v, want none after the purge", len(incomingTransfers), err)
+	}
+}
@@ -5,6 +5,7 @@ package models
 import (
 	"errors"
 	"log" // Keep log for UpsertUser specific logging
+	"time"
 
 	"github.com/temirov/RSVP/pkg/config"
 	"gorm.io/gorm"
@@ -21,6 +22,10 @@ type User struct {
 	Name string `gorm:"size:255"`
 	// Picture is the URL to the user's profile picture, provided by the authentication provider.
 	Picture string `gorm:"size:512"`
+	// LastSignInAt is when the user last signed in with any provider.
+	LastSignInAt *time.Time
+	// SuspendedAt is set while an administrator has suspended the user, who can then neither sign in nor use API tokens.
+	SuspendedAt *time.Time
 	// Events is a slice containing all Event records created by this user.
 	// GORM automatically handles the foreign key relationship (UserID on Event model).
 	// Cascade constraints ensure Events (and their RSVPs) are deleted if the User is deleted.
@@ -68,6 +73,53 @@ func (userRecord *User) Update(databaseConnection *gorm.DB) error {
 	return databaseConnection.Save(userRecord).Error
 }
 
+// IsSuspended reports whether an administrator has suspended the user.
+func (userRecord *User) IsSuspended() bool {
+	return userRecord.SuspendedAt != nil
+}
+
+// SetSuspended suspends or reinstates the user. Suspending an already suspended user keeps the original time.
+func (userRecord *User) SetSuspended(databaseConnection *gorm.DB, suspended bool) error {
+	if suspended == userRecord.IsSuspended() {
+		return nil
+	}
+	var suspendedAt *time.Time
+	if suspended {
+		suspensionTime := time.Now()
+		suspendedAt = &suspensionTime
+	}
+	if err := databaseConnection.Model(userRecord).UpdateColumn("suspended_at", suspendedAt).Error; err != nil {
+		return err
+	}
+	userRecord.SuspendedAt = suspendedAt
+	return nil
+}
+
+// RecordSignIn sets the user's last sign-in time to now.
+func (userRecord *User) RecordSignIn(databaseConnection *gorm.DB) error {
+	signInTime := time.Now()
+	if err := databaseConnection.Model(userRecord).UpdateColumn("last_sign_in_at", signInTime).Error; err != nil {
+		return err
+	}
+	userRecord.LastSignInAt = &signInTime
+	return nil
+}
+
+// HasAccountOrInvitation reports whether the email address belongs to an existing user or has a pending co-host
+// or organization invitation. It decides who may sign in when sign-in is by invitation only.
+func HasAccountOrInvitation(databaseConnection *gorm.DB, emailAddress string) (bool, error) {
+	normalizedEmail := NormalizeMemberEmail(emailAddress)
+	var matchCount int64
+	if err := databaseConnection.Model(&User{}).Where("LOWER(email) = ?", normalizedEmail).Count(&matchCount).Error; err != nil || matchCount > 0 {
+		return matchCount > 0, err
+	}
+	if err := databaseConnection.Model(&EventMembership{}).Where("invited_email = ? AND user_id IS NULL", normalizedEmail).Count(&matchCount).Error; err != nil || matchCount > 0 {
+		return matchCount > 0, err
+	}
+	err := databaseConnection.Model(&OrganizationMember{}).Where("invited_email = ? AND user_id IS NULL", normalizedEmail).Count(&matchCount).Error
+	return matchCount > 0, err
+}
+
 // UpsertUser finds a user by email or creates a new one if not found.
 // If the user exists, it updates their Name and Picture if the provided values differ.
 func UpsertUser(
@@ -8,15 +8,17 @@ import (
 
 type Venue struct {
 	BaseModel
-	UserID      string `gorm:"not null;index"`
-	Name        string `gorm:"not null"`
-	Address     string
-	Capacity    int
-	Website     string
-	Phone       string
-	Email       string
-	Description string
-	Events      []Event `gorm:"foreignKey:VenueID"`
+	UserID string `gorm:"not null;index"`
+	// OrganizationID is set when the venue belongs to an organization's workspace.
+	OrganizationID *string `gorm:"type:varchar(8);index"`
+	Name           string  `gorm:"not null"`
+	Address        string
+	Capacity       int
+	Website        string
+	Phone          string
+	Email          string
+	Description    string
+	Events         []Event `gorm:"foreignKey:VenueID"`
 }
 
 func (venue *Venue) GetTableName() string {
@@ -54,6 +56,15 @@ func (venue *Venue) FindByIDAndOwner(databaseConnection *gorm.DB, venueIdentifie
 	return databaseConnection.Where("id = ? AND user_id = ?", venueIdentifier, ownerUserID).First(venue).Error
 }
 
+// FindByIDInWorkspace retrieves a Venue record by its identifier ensuring it belongs to the given workspace:
+// the organization's when organizationIdentifier is set, otherwise the owner's personal workspace.
+func (venue *Venue) FindByIDInWorkspace(databaseConnection *gorm.DB, venueIdentifier string, ownerUserID string, organizationIdentifier *string) error {
+	if organizationIdentifier != nil {
+		return databaseConnection.Where("id = ? AND organization_id = ?", venueIdentifier, *organizationIdentifier).First(venue).Error
+	}
+	return databaseConnection.Where("id = ? AND user_id = ? AND organization_id IS NULL", venueIdentifier, ownerUserID).First(venue).Error
+}
+
 func (venue *Venue) Create(databaseConnection *gorm.DB) error {
 	if venue.UserID == "" {
 		return utils.ErrUserIDRequired
@@ -74,9 +85,10 @@ func FindVenuesByIDs(databaseConnection *gorm.DB, venueIDs []string) ([]Venue, e
 	return venues, result.Error
 }
 
+// FindVenuesByOwner retrieves the venues in the personal workspace of the given user.
 func FindVenuesByOwner(databaseConnection *gorm.DB, ownerID string) ([]Venue, error) {
 	var venues []Venue
-	err := databaseConnection.Where("user_id = ?", ownerID).Order("name ASC").Find(&venues).Error
+	err := databaseConnection.Where("user_id = ? AND organization_id IS NULL", ownerID).Order("name ASC").Find(&venues).Error
 	return venues, err
 }
 
@@ -89,3 +101,31 @@ func (venue *Venue) Delete(db *gorm.DB) error {
 	}
 	return nil
 }
+
+// TransferOwnership assigns the venue to another user. Events that use the venue keep referencing it.
+func (venue *Venue) TransferOwnership(databaseConnection *gorm.DB, newOwnerUserID string) error {
+	if err := databaseConnection.Model(venue).UpdateColumn("user_id", newOwnerUserID).Error; err != nil {
+		return err
+	}
+	venue.UserID = newOwnerUserID
+	return nil
+}
+
+// copyFor creates a personal copy of the venue's details owned by another user, so events that end up with a
+// different owner than the venue can keep their location.
+func (venue *Venue) copyFor(databaseConnection *gorm.DB, ownerUserID string) (*Venue, error) {
+	venueCopy := Venue{
+		UserID:      ownerUserID,
+		Name:        venue.Name,
+		Address:     venue.Address,
+		Capacity:    venue.Capacity,
+		Website:     venue.Website,
+		Phone:       venue.Phone,
+		Email:       venue.Email,
+		Description: venue.Description,
+	}
+	if err := venueCopy.Create(databaseConnection); err != nil {
+		return nil, err
+	}
+	return &venueCopy, nil
+}
@@ -0,0 +1,90 @@
+// Package assets provides the templates and static files the server uses. They are compiled into the binary, so it
+// runs from any working directory, and any of them can be replaced by a file of the same name in an override
+// directory on disk.
+package assets
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+
+	rsvp "github.com/temirov/RSVP"
+	"github.com/temirov/RSVP/pkg/config"
+)
+
+// New returns the embedded files, overlaid by the files in overrideDirectory when it is not empty. The override
+// directory mirrors the embedded layout: templates/layout.tmpl replaces the layout, static/css/custom.css adds styles.
+func New(overrideDirectory string) (fs.FS, error) {
+	if overrideDirectory == "" {
+		return rsvp.EmbeddedFiles, nil
+	}
+	directoryInfo, statError := os.Stat(overrideDirectory)
+	if statError != nil {
+		return nil, fmt.Errorf("reading the assets override directory: %w", statError)
+	}
+	if !directoryInfo.IsDir() {
+		return nil, fmt.Errorf("the assets override %s is not a directory", overrideDirectory)
+	}
+	return overlayFS{override: os.DirFS(overrideDirectory), base: rsvp.EmbeddedFiles}, nil
+}
+
+// Templates returns the templates directory of the asset files.
+func Templates(assetFiles fs.FS) (fs.FS, error) {
+	return fs.Sub(assetFiles, config.TemplatesDir)
+}
+
+// Static returns the directory of the asset files served under /static/.
+func Static(assetFiles fs.FS) (fs.FS, error) {
+	return fs.Sub(assetFiles, config.StaticDir)
+}
+
+// overlayFS serves each file from override when it exists there, and from base otherwise. Directory listings
+// combine both, so that files added in the override directory are found when the templates are walked.
+type overlayFS struct {
+	override fs.FS
+	base     fs.FS
+}
+
+// Open opens the named file from the override directory, falling back to the base files.
+func (overlay overlayFS) Open(name string) (fs.File, error) {
+	overrideFile, openError := overlay.override.Open(name)
+	if openError == nil {
+		return overrideFile, nil
+	}
+	if !errors.Is(openError, fs.ErrNotExist) {
+		return nil, openError
+	}
+	return overlay.base.Open(name)
+}
+
+// ReadDir lists the named directory of both file systems, preferring the override's entry when both have one.
+func (overlay overlayFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	overrideEntries, overrideError := fs.ReadDir(overlay.override, name)
+	if overrideError != nil && !errors.Is(overrideError, fs.ErrNotExist) {
+		return nil, overrideError
+	}
+	baseEntries, baseError := fs.ReadDir(overlay.base, name)
+	if baseError != nil && !errors.Is(baseError, fs.ErrNotExist) {
+		return nil, baseError
+	}
+	if overrideError != nil && baseError != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	entriesByName := make(map[string]fs.DirEntry, len(overrideEntries)+len(baseEntries))
+	for _, baseEntry := range baseEntries {
+		entriesByName[baseEntry.Name()] = baseEntry
+	}
+	for _, overrideEntry := range overrideEntries {
+		entriesByName[overrideEntry.Name()] = overrideEntry
+	}
+	mergedEntries := make([]fs.DirEntry, 0, len(entriesByName))
+	for _, directoryEntry := range entriesByName {
+		mergedEntries = append(mergedEntries, directoryEntry)
+	}
+	sort.Slice(mergedEntries, func(leftIndex, rightIndex int) bool {
+		return mergedEntries[leftIndex].Name() < mergedEntries[rightIndex].Name()
+	})
+	return mergedEntries, nil
+}
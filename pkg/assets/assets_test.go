@@ -0,0 +1,81 @@
+package assets
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/temirov/RSVP/pkg/config"
+)
+
+// repositoryTemplatesDir is the templates directory on disk, relative to this package.
+var repositoryTemplatesDir = filepath.Join("..", "..", config.TemplatesDir)
+
+func TestEveryTemplateOnDiskIsEmbedded(t *testing.T) {
+	assetFiles, newError := New("")
+	if newError != nil {
+		t.Fatalf("New: %v", newError)
+	}
+	templateFiles, templatesError := Templates(assetFiles)
+	if templatesError != nil {
+		t.Fatalf("Templates: %v", templatesError)
+	}
+	walkError := fs.WalkDir(os.DirFS(repositoryTemplatesDir), ".", func(filePath string, directoryEntry fs.DirEntry, walkError error) error {
+		if walkError != nil || directoryEntry.IsDir() {
+			return walkError
+		}
+		if _, statError := fs.Stat(templateFiles, filePath); statError != nil {
+			t.Errorf("template %s is on disk but not embedded: %v", filePath, statError)
+		}
+		return nil
+	})
+	if walkError != nil {
+		t.Fatalf("walking %s: %v", repositoryTemplatesDir, walkError)
+	}
+}
+
+func TestOverrideDirectoryReplacesAndAddsFiles(t *testing.T) {
+	overrideDirectory := t.TempDir()
+	partialsDirectory := filepath.Join(overrideDirectory, config.TemplatesDir, config.PartialsDir)
+	if err := os.MkdirAll(partialsDirectory, 0o755); err != nil {
+		t.Fatalf("creating the override directory: %v", err)
+	}
+	overriddenFooter := []byte(`{{ define "partials/footer.tmpl" }}custom footer{{ end }}`)
+	if err := os.WriteFile(filepath.Join(partialsDirectory, "footer.tmpl"), overriddenFooter, 0o644); err != nil {
+		t.Fatalf("writing the footer: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(partialsDirectory, "_extra.tmpl"), []byte("extra"), 0o644); err != nil {
+		t.Fatalf("writing the extra partial: %v", err)
+	}
+
+	assetFiles, newError := New(overrideDirectory)
+	if newError != nil {
+		t.Fatalf("New: %v", newError)
+	}
+	templateFiles, templatesError := Templates(assetFiles)
+	if templatesError != nil {
+		t.Fatalf("Templates: %v", templatesError)
+	}
+	footerContent, readError := fs.ReadFile(templateFiles, config.PartialsDir+"/footer.tmpl")
+	if readError != nil || string(footerContent) != string(overriddenFooter) {
+		t.Fatalf("footer = %q, %v; want the override", footerContent, readError)
+	}
+	partialEntries, readDirError := fs.ReadDir(templateFiles, config.PartialsDir)
+	if readDirError != nil {
+		t.Fatalf("ReadDir: %v", readDirError)
+	}
+	listedNames := make(map[string]bool)
+	for _, partialEntry := range partialEntries {
+		listedNames[partialEntry.Name()] = true
+	}
+	for _, expectedName := range []string{"_extra.tmpl", "_edit_rsvp_form.tmpl", "footer.tmpl", "header.tmpl"} {
+		if !listedNames[expectedName] {
+			t.Errorf("partials listing lacks %s; got %v", expectedName, listedNames)
+		}
+	}
+
+	if _, err := New(filepath.Join(overrideDirectory, "missing")); err == nil {
+		t.Fatal("New with a missing override directory succeeded")
+	}
+}
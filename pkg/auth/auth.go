@@ -0,0 +1,161 @@
+// Package auth provides the sign-in providers selectable with AUTH_PROVIDERS: Google through GAuss, a generic
+// OpenID Connect provider, one-time links sent by email and, for local development, a fake identity provider.
+// Whatever the provider, a successful sign-in stores the user's email, name and picture under the GAuss session
+// keys, which is all the rest of the application reads.
+package auth
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	gconstants "github.com/temirov/GAuss/pkg/constants"
+	"github.com/temirov/GAuss/pkg/session"
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/logging"
+	"gorm.io/gorm"
+)
+
+// Provider is one way of signing in.
+type Provider interface {
+	// Name is the provider's AUTH_PROVIDERS value.
+	Name() string
+	// RegisterRoutes adds the provider's sign-in and callback endpoints to the router.
+	RegisterRoutes(mux *http.ServeMux)
+}
+
+// ButtonProvider is a provider started from a button on the sign-in page that redirects to an identity provider.
+type ButtonProvider interface {
+	Provider
+	SignInButton() SignInButton
+}
+
+// SignInButton describes a sign-in button: its label, its Bootstrap icon and the path that starts the sign-in.
+type SignInButton struct {
+	Label string
+	Icon  string
+	URL   string
+}
+
+// EmailSignInForm describes the form that requests a sign-in link by email.
+type EmailSignInForm struct {
+	URL            string
+	ParamNameEmail string
+	MaxEmailLength int
+}
+
+// Identity is the verified profile a provider signs a user in with.
+type Identity struct {
+	Email   string
+	Name    string
+	Picture string
+}
+
+// LoginPageData is what the sign-in page shows besides an error or notice message.
+type LoginPageData struct {
+	Buttons []SignInButton
+	// EmailForm is set when sign-in links by email are enabled.
+	EmailForm *EmailSignInForm
+	// DevForm is set in development mode.
+	DevForm *DevSignInForm
+}
+
+// Providers holds the enabled providers in the order of AUTH_PROVIDERS.
+type Providers struct {
+	enabledProviders []Provider
+	logger           *slog.Logger
+}
+
+// NewProviders creates the providers enabled in the configuration.
+func NewProviders(envConfig *config.EnvConfig, databaseConnection *gorm.DB, logger *slog.Logger) (*Providers, error) {
+	configuredProviders := &Providers{logger: logger}
+	for _, providerName := range envConfig.Auth.Providers {
+		var enabledProvider Provider
+		var err error
+		switch providerName {
+		case config.AuthProviderGoogle:
+			enabledProvider, err = NewGoogleProvider(envConfig)
+		case config.AuthProviderOIDC:
+			enabledProvider, err = NewOIDCProvider(envConfig.Auth.OIDC, envConfig.AppBaseURL, http.DefaultClient)
+		case config.AuthProviderEmail:
+			enabledProvider = NewEmailProvider(envConfig.Auth.Email, envConfig.AppBaseURL, envConfig.Branding.AppTitle, databaseConnection, NewSMTPMailer(envConfig.Auth.Email.SMTP), logger)
+		case config.AuthProviderDev:
+			enabledProvider = NewDevProvider(envConfig.Auth.DevUsers, logger)
+		default:
+			err = fmt.Errorf("unsupported authentication provider %q", providerName)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("initializing %s sign-in: %w", providerName, err)
+		}
+		configuredProviders.enabledProviders = append(configuredProviders.enabledProviders, enabledProvider)
+	}
+	return configuredProviders, nil
+}
+
+// RegisterRoutes adds the endpoints of every enabled provider and the shared logout endpoint.
+func (configuredProviders *Providers) RegisterRoutes(mux *http.ServeMux) {
+	for _, enabledProvider := range configuredProviders.enabledProviders {
+		enabledProvider.RegisterRoutes(mux)
+		configuredProviders.logger.Info("Sign-in provider registered", "provider", enabledProvider.Name())
+	}
+	mux.HandleFunc(config.WebLogout, LogoutHandler)
+}
+
+// LoginPageData returns the buttons and forms the sign-in page shows for the enabled providers.
+func (configuredProviders *Providers) LoginPageData() LoginPageData {
+	var loginPageData LoginPageData
+	for _, enabledProvider := range configuredProviders.enabledProviders {
+		switch typedProvider := enabledProvider.(type) {
+		case ButtonProvider:
+			loginPageData.Buttons = append(loginPageData.Buttons, typedProvider.SignInButton())
+		case *EmailProvider:
+			loginPageData.EmailForm = &EmailSignInForm{
+				URL:            config.WebAuthEmail,
+				ParamNameEmail: config.LoginEmailParam,
+				MaxEmailLength: config.MaxEmailLength,
+			}
+		case *DevProvider:
+			loginPageData.DevForm = typedProvider.SignInForm()
+		}
+	}
+	return loginPageData
+}
+
+// LogoutHandler clears the session and returns to the sign-in page.
+func LogoutHandler(responseWriter http.ResponseWriter, request *http.Request) {
+	webSession, _ := session.Store().Get(request, gconstants.SessionName)
+	webSession.Options.MaxAge = -1
+	if sessionSaveError := webSession.Save(request, responseWriter); sessionSaveError != nil {
+		http.Error(responseWriter, sessionSaveError.Error(), http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(responseWriter, request, config.WebLogin, http.StatusFound)
+}
+
+// completeSignIn stores the identity under the GAuss session keys and continues to the events page.
+func completeSignIn(responseWriter http.ResponseWriter, request *http.Request, signedInIdentity Identity) {
+	webSession, _ := session.Store().Get(request, gconstants.SessionName)
+	webSession.Values[gconstants.SessionKeyUserEmail] = strings.ToLower(strings.TrimSpace(signedInIdentity.Email))
+	webSession.Values[gconstants.SessionKeyUserName] = signedInIdentity.Name
+	webSession.Values[gconstants.SessionKeyUserPicture] = signedInIdentity.Picture
+	// A fresh sign-in is recorded again even when it reuses a session.
+	delete(webSession.Values, config.SessionKeySignInRecorded)
+	if sessionSaveError := webSession.Save(request, responseWriter); sessionSaveError != nil {
+		logging.FromContext(request.Context()).Error("Saving the session failed", "email", signedInIdentity.Email, "error", sessionSaveError)
+		redirectToLogin(responseWriter, request, config.ErrorQueryParam, "Your session could not be saved. Please try again.")
+		return
+	}
+	http.Redirect(responseWriter, request, config.WebEvents, http.StatusFound)
+}
+
+// redirectToLogin returns to the sign-in page with an error or notice message.
+func redirectToLogin(responseWriter http.ResponseWriter, request *http.Request, messageParam string, message string) {
+	http.Redirect(responseWriter, request, config.WebLogin+"?"+url.Values{messageParam: {message}}.Encode(), http.StatusFound)
+}
+
+// absoluteURL joins a path of this application to its public base URL.
+func absoluteURL(appBaseURL string, applicationPath string) string {
+	return strings.TrimSuffix(appBaseURL, "/") + applicationPath
+}
@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"log/slog"
+	"net/http"
+	"net/mail"
+	"strings"
+
+	"github.com/temirov/RSVP/models"
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/logging"
+	"github.com/temirov/RSVP/pkg/utils"
+)
+
+// DevSignInForm describes the development sign-in form: one-click test identities and a free-form one.
+type DevSignInForm struct {
+	URL            string
+	ParamNameEmail string
+	ParamNameName  string
+	MaxEmailLength int
+	Identities     []Identity
+}
+
+// DevProvider signs in as whichever identity is picked or typed on the sign-in page, without asking anyone.
+// It stands in for the Google flow during local development and in handler integration tests, which can sign
+// in by posting an email address to /auth/dev. The configuration refuses it outside localhost.
+type DevProvider struct {
+	identities []Identity
+}
+
+// NewDevProvider creates the development provider offering the given test identities.
+func NewDevProvider(devUsers []*mail.Address, logger *slog.Logger) *DevProvider {
+	devProvider := &DevProvider{}
+	for _, devUser := range devUsers {
+		devProvider.identities = append(devProvider.identities, Identity{Email: devUser.Address, Name: devUser.Name})
+	}
+	logger.Warn(config.LabelDevAuth + " is enabled. Anyone who can reach this server can sign in as any user.")
+	return devProvider
+}
+
+// Name returns the provider's AUTH_PROVIDERS value.
+func (devProvider *DevProvider) Name() string {
+	return config.AuthProviderDev
+}
+
+// RegisterRoutes adds the endpoint the development sign-in form posts to.
+func (devProvider *DevProvider) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc(config.WebAuthDev, devProvider.SignInHandler)
+}
+
+// SignInForm describes the form listing the test identities.
+func (devProvider *DevProvider) SignInForm() *DevSignInForm {
+	return &DevSignInForm{
+		URL:            config.WebAuthDev,
+		ParamNameEmail: config.LoginEmailParam,
+		ParamNameName:  config.NameParam,
+		MaxEmailLength: config.MaxEmailLength,
+		Identities:     devProvider.identities,
+	}
+}
+
+// SignInHandler handles POST requests from the development sign-in form and signs in as the posted identity.
+// Without a name, the part of the address before the @ is used.
+func (devProvider *DevProvider) SignInHandler(responseWriter http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodPost {
+		utils.HandleError(responseWriter, request, nil, utils.MethodNotAllowedError, http.StatusText(http.StatusMethodNotAllowed))
+		return
+	}
+	emailAddress := models.NormalizeMemberEmail(request.FormValue(config.LoginEmailParam))
+	if validationError := utils.ValidateLoginEmail(emailAddress); validationError != nil {
+		redirectToLogin(responseWriter, request, config.ErrorQueryParam, validationError.Error())
+		return
+	}
+	displayName := strings.TrimSpace(request.FormValue(config.NameParam))
+	if displayName == "" {
+		displayName, _, _ = strings.Cut(emailAddress, "@")
+	}
+	logging.FromContext(request.Context()).Info(config.LabelDevAuth+": signing in", "email", emailAddress)
+	completeSignIn(responseWriter, request, Identity{Email: emailAddress, Name: displayName})
+}
@@ -0,0 +1,115 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/temirov/RSVP/models"
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/logging"
+	"github.com/temirov/RSVP/pkg/ratelimit"
+	"github.com/temirov/RSVP/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// loginLinkSentNotice is shown after a link is requested, whether or not the address has an account,
+// so the form does not reveal who uses the application.
+const loginLinkSentNotice = "If the address is valid, a sign-in link is on its way. Check your inbox."
+
+// EmailProvider signs users in with one-time links sent to their email address. Anyone who can read mail
+// sent to an address can sign in as it, as with Google accounts.
+type EmailProvider struct {
+	settings           config.EmailAuthConfig
+	appBaseURL         string
+	appTitle           string
+	databaseConnection *gorm.DB
+	mailer             Mailer
+	// linkLimiter holds the hourly quotas of link requests, keyed by client address and by recipient.
+	linkLimiter *ratelimit.Limiter
+}
+
+// NewEmailProvider creates the email link provider delivering links through the mailer.
+func NewEmailProvider(settings config.EmailAuthConfig, appBaseURL string, appTitle string, databaseConnection *gorm.DB, mailer Mailer, logger *slog.Logger) *EmailProvider {
+	return &EmailProvider{
+		settings:           settings,
+		appBaseURL:         appBaseURL,
+		appTitle:           appTitle,
+		databaseConnection: databaseConnection,
+		mailer:             mailer,
+		linkLimiter: ratelimit.NewLimiter(config.RateLimitConfig{},
+			ratelimit.NewMemoryStore(time.Duration(config.LoginLinkLimitPeriod)), logger),
+	}
+}
+
+// Name returns the provider's AUTH_PROVIDERS value.
+func (emailProvider *EmailProvider) Name() string {
+	return config.AuthProviderEmail
+}
+
+// RegisterRoutes adds the endpoints that send a link and sign in with it.
+func (emailProvider *EmailProvider) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc(config.WebAuthEmail, emailProvider.RequestLinkHandler)
+	mux.HandleFunc(config.WebAuthEmailSignIn, emailProvider.SignInHandler)
+}
+
+// RequestLinkHandler handles POST requests from the sign-in page's email form and mails a sign-in link.
+func (emailProvider *EmailProvider) RequestLinkHandler(responseWriter http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodPost {
+		utils.HandleError(responseWriter, request, nil, utils.MethodNotAllowedError, http.StatusText(http.StatusMethodNotAllowed))
+		return
+	}
+	clientAddress := utils.ClientIP(request)
+	if allowed, _ := emailProvider.linkLimiter.AllowQuota("client:"+clientAddress, emailProvider.settings.LinksPerClient,
+		time.Duration(config.LoginLinkLimitPeriod)); !allowed {
+		logging.FromContext(request.Context()).Warn("Sign-in link requests limited", "client_ip", clientAddress)
+		redirectToLogin(responseWriter, request, config.ErrorQueryParam, config.ErrMsgTooManyLoginLinks)
+		return
+	}
+	emailAddress := models.NormalizeMemberEmail(request.FormValue(config.LoginEmailParam))
+	if validationError := utils.ValidateLoginEmail(emailAddress); validationError != nil {
+		redirectToLogin(responseWriter, request, config.ErrorQueryParam, validationError.Error())
+		return
+	}
+	// The recipient's quota stops anyone from flooding an inbox from many addresses. Reaching it is reported like
+	// the client limit, which says nothing about whether the address has an account.
+	if allowed, _ := emailProvider.linkLimiter.AllowQuota("recipient:"+emailAddress, emailProvider.settings.LinksPerRecipient,
+		time.Duration(config.LoginLinkLimitPeriod)); !allowed {
+		logging.FromContext(request.Context()).Warn("Sign-in links to one address limited", "email", emailAddress, "client_ip", clientAddress)
+		redirectToLogin(responseWriter, request, config.ErrorQueryParam, config.ErrMsgTooManyLoginLinks)
+		return
+	}
+	linkSecret, err := models.IssueLoginLink(emailProvider.databaseConnection, emailAddress, emailProvider.settings.LinkLifetime)
+	if err != nil {
+		logging.FromContext(request.Context()).Error("Issuing a sign-in link failed", "email", emailAddress, "error", err)
+		redirectToLogin(responseWriter, request, config.ErrorQueryParam, "A sign-in link could not be sent. Please try again.")
+		return
+	}
+	signInURL := absoluteURL(emailProvider.appBaseURL, config.WebAuthEmailSignIn) + "?" + url.Values{config.LoginTokenParam: {linkSecret}}.Encode()
+	messageBody := fmt.Sprintf("Use this link to sign in to %s:\r\n\r\n%s\r\n\r\nThe link works once and expires in %s. "+
+		"If you did not ask to sign in, you can ignore this message.\r\n",
+		emailProvider.appTitle, signInURL, emailProvider.settings.LinkLifetime.Round(time.Minute))
+	if err := emailProvider.mailer.Send(emailAddress, "Sign in to "+emailProvider.appTitle, messageBody); err != nil {
+		logging.FromContext(request.Context()).Error("Sending a sign-in link failed", "email", emailAddress, "error", err)
+		redirectToLogin(responseWriter, request, config.ErrorQueryParam, "A sign-in link could not be sent. Please try again.")
+		return
+	}
+	logging.FromContext(request.Context()).Info("Sign-in link sent", "email", emailAddress)
+	redirectToLogin(responseWriter, request, config.NoticeQueryParam, loginLinkSentNotice)
+}
+
+// SignInHandler handles the link from the email: it uses up the link and signs its address in.
+func (emailProvider *EmailProvider) SignInHandler(responseWriter http.ResponseWriter, request *http.Request) {
+	emailAddress, err := models.ConsumeLoginLink(emailProvider.databaseConnection, request.URL.Query().Get(config.LoginTokenParam))
+	if err != nil {
+		if !errors.Is(err, models.ErrLoginLinkInvalid) {
+			logging.FromContext(request.Context()).Error("Checking a sign-in link failed", "error", err)
+		}
+		redirectToLogin(responseWriter, request, config.ErrorQueryParam, models.ErrLoginLinkInvalid.Error()+" Request a new one.")
+		return
+	}
+	completeSignIn(responseWriter, request, Identity{Email: emailAddress})
+}
@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/testdb"
+)
+
+// recordingMailer remembers the recipients of the messages it was asked to send.
+type recordingMailer struct {
+	recipients []string
+}
+
+func (mailer *recordingMailer) Send(recipientAddress string, _ string, _ string) error {
+	mailer.recipients = append(mailer.recipients, recipientAddress)
+	return nil
+}
+
+func newTestEmailProvider(t *testing.T, linksPerClient int, linksPerRecipient int) (*EmailProvider, *recordingMailer) {
+	t.Helper()
+	databaseConnection := testdb.OpenMigrated(t)
+	mailer := &recordingMailer{}
+	emailSettings := config.EmailAuthConfig{
+		LinkLifetime:      time.Duration(config.DefaultLoginLinkLifetime),
+		LinksPerClient:    linksPerClient,
+		LinksPerRecipient: linksPerRecipient,
+	}
+	emailProvider := NewEmailProvider(emailSettings, "http://localhost/", "RSVP", databaseConnection, mailer,
+		slog.New(slog.NewTextHandler(io.Discard, nil)))
+	return emailProvider, mailer
+}
+
+func requestLink(emailProvider *EmailProvider, clientAddress string, emailAddress string) *httptest.ResponseRecorder {
+	linkRequest := httptest.NewRequest(http.MethodPost, config.WebAuthEmail,
+		strings.NewReader(url.Values{config.LoginEmailParam: {emailAddress}}.Encode()))
+	linkRequest.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	linkRequest.RemoteAddr = clientAddress + ":40000"
+	responseRecorder := httptest.NewRecorder()
+	emailProvider.RequestLinkHandler(responseRecorder, linkRequest)
+	return responseRecorder
+}
+
+func isLimited(responseRecorder *httptest.ResponseRecorder) bool {
+	redirectURL, err := url.Parse(responseRecorder.Header().Get("Location"))
+	return err == nil && redirectURL.Query().Get(config.ErrorQueryParam) == config.ErrMsgTooManyLoginLinks
+}
+
+func TestRequestLinkHandlerLimitsClients(t *testing.T) {
+	emailProvider, mailer := newTestEmailProvider(t, 2, 0)
+	for requestIndex, emailAddress := range []string{"a@example.com", "b@example.com"} {
+		if responseRecorder := requestLink(emailProvider, "198.51.100.1", emailAddress); isLimited(responseRecorder) {
+			t.Fatalf("request %d was limited before reaching the quota", requestIndex+1)
+		}
+	}
+	if responseRecorder := requestLink(emailProvider, "198.51.100.1", "c@example.com"); !isLimited(responseRecorder) {
+		t.Errorf("third request from one client was not limited")
+	}
+	if responseRecorder := requestLink(emailProvider, "198.51.100.2", "c@example.com"); isLimited(responseRecorder) {
+		t.Errorf("another client was limited by the first client's quota")
+	}
+	if len(mailer.recipients) != 3 {
+		t.Errorf("sent %d links, want 3", len(mailer.recipients))
+	}
+}
+
+func TestRequestLinkHandlerLimitsRecipients(t *testing.T) {
+	emailProvider, mailer := newTestEmailProvider(t, 0, 2)
+	for requestIndex, clientAddress := range []string{"198.51.100.1", "198.51.100.2"} {
+		if responseRecorder := requestLink(emailProvider, clientAddress, "victim@example.com"); isLimited(responseRecorder) {
+			t.Fatalf("request %d was limited before reaching the quota", requestIndex+1)
+		}
+	}
+	if responseRecorder := requestLink(emailProvider, "198.51.100.3", "Victim@Example.com"); !isLimited(responseRecorder) {
+		t.Errorf("third link to one address from a new client was not limited")
+	}
+	if responseRecorder := requestLink(emailProvider, "198.51.100.3", "other@example.com"); isLimited(responseRecorder) {
+		t.Errorf("another recipient was limited by the first recipient's quota")
+	}
+	if len(mailer.recipients) != 3 {
+		t.Errorf("sent %d links, want 3", len(mailer.recipients))
+	}
+}
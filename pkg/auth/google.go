@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"net/http"
+
+	gconstants "github.com/temirov/GAuss/pkg/constants"
+	"github.com/temirov/GAuss/pkg/gauss"
+	"github.com/temirov/RSVP/pkg/config"
+)
+
+// GoogleProvider signs users in with their Google account through GAuss.
+type GoogleProvider struct {
+	gaussHandlers *gauss.Handlers
+}
+
+// NewGoogleProvider configures GAuss with the Google OAuth credentials of the environment. GAuss is given no login
+// template, so it uses its own embedded one; it is never shown, because the sign-in page is served by this application.
+func NewGoogleProvider(envConfig *config.EnvConfig) (*GoogleProvider, error) {
+	authenticationService, err := gauss.NewService(
+		envConfig.GoogleClientID,
+		envConfig.GoogleClientSecret,
+		envConfig.GoogleOauth2Base,
+		config.WebEvents,
+		"",
+	)
+	if err != nil {
+		return nil, err
+	}
+	gaussHandlers, err := gauss.NewHandlers(authenticationService)
+	if err != nil {
+		return nil, err
+	}
+	return &GoogleProvider{gaussHandlers: gaussHandlers}, nil
+}
+
+// Name returns the provider's AUTH_PROVIDERS value.
+func (googleProvider *GoogleProvider) Name() string {
+	return config.AuthProviderGoogle
+}
+
+// RegisterRoutes adds the GAuss sign-in and callback endpoints. The sign-in page and logout are shared by
+// all providers, so the GAuss versions of those are not registered.
+func (googleProvider *GoogleProvider) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc(gconstants.GoogleAuthPath, googleProvider.gaussHandlers.Login)
+	mux.HandleFunc(gconstants.CallbackPath, googleProvider.gaussHandlers.Callback)
+}
+
+// SignInButton describes the "Sign in with Google" button.
+func (googleProvider *GoogleProvider) SignInButton() SignInButton {
+	return SignInButton{Label: "Sign in with Google", Icon: "bi-google", URL: gconstants.GoogleAuthPath}
+}
@@ -0,0 +1,219 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// jsonWebKey holds the fields of a JSON Web Key needed to verify RSA and ECDSA signatures.
+type jsonWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	// RSA public key.
+	Modulus  string `json:"n"`
+	Exponent string `json:"e"`
+	// ECDSA public key.
+	Curve string `json:"crv"`
+	X     string `json:"x"`
+	Y     string `json:"y"`
+}
+
+// jwtHeader holds the fields of a JWT header that select the verification key.
+type jwtHeader struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+}
+
+// jwksCache holds the identity provider's signing keys. They are fetched on first use and again when a token names
+// a key that is not cached, which is how providers roll their keys over.
+type jwksCache struct {
+	jwksURL    string
+	httpClient *http.Client
+
+	keysMutex sync.Mutex
+	keys      []jsonWebKey
+}
+
+// verifyJWTSignature checks the signature of a compact JWT against the cached keys, refreshing them once if no
+// cached key matches. Only asymmetric algorithms are accepted, so a token cannot be signed with "none" or with a
+// shared secret.
+func (keyCache *jwksCache) verifyJWTSignature(requestContext context.Context, rawToken string) error {
+	tokenSegments := strings.Split(rawToken, ".")
+	if len(tokenSegments) != 3 {
+		return errors.New("ID token is not a JWT")
+	}
+	headerJSON, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(tokenSegments[0], "="))
+	if err != nil {
+		return fmt.Errorf("decoding ID token header: %w", err)
+	}
+	var tokenHeader jwtHeader
+	if err := json.Unmarshal(headerJSON, &tokenHeader); err != nil {
+		return fmt.Errorf("parsing ID token header: %w", err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(tokenSegments[2], "="))
+	if err != nil {
+		return fmt.Errorf("decoding ID token signature: %w", err)
+	}
+	signedContent := []byte(tokenSegments[0] + "." + tokenSegments[1])
+
+	for attempt := 0; attempt < 2; attempt++ {
+		candidateKeys, err := keyCache.signingKeys(requestContext, attempt > 0)
+		if err != nil {
+			return err
+		}
+		for _, candidateKey := range candidateKeys {
+			if tokenHeader.KeyID != "" && candidateKey.KeyID != tokenHeader.KeyID {
+				continue
+			}
+			if candidateKey.Use != "" && candidateKey.Use != "sig" {
+				continue
+			}
+			if candidateKey.Algorithm != "" && candidateKey.Algorithm != tokenHeader.Algorithm {
+				continue
+			}
+			if verifyError := verifySignature(candidateKey, tokenHeader.Algorithm, signedContent, signature); verifyError == nil {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("ID token signature (%s, key %q) does not match any key of the identity provider", tokenHeader.Algorithm, tokenHeader.KeyID)
+}
+
+// signingKeys returns the cached keys, fetching them when there are none or when refresh is set.
+func (keyCache *jwksCache) signingKeys(requestContext context.Context, refresh bool) ([]jsonWebKey, error) {
+	keyCache.keysMutex.Lock()
+	defer keyCache.keysMutex.Unlock()
+	if keyCache.keys != nil && !refresh {
+		return keyCache.keys, nil
+	}
+	jwksRequest, err := http.NewRequestWithContext(requestContext, http.MethodGet, keyCache.jwksURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	jwksResponse, err := keyCache.httpClient.Do(jwksRequest)
+	if err != nil {
+		return nil, fmt.Errorf("fetching signing keys: %w", err)
+	}
+	defer jwksResponse.Body.Close()
+	if jwksResponse.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("signing keys endpoint returned status %d", jwksResponse.StatusCode)
+	}
+	var keySet struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.NewDecoder(jwksResponse.Body).Decode(&keySet); err != nil {
+		return nil, fmt.Errorf("decoding signing keys: %w", err)
+	}
+	keyCache.keys = keySet.Keys
+	return keyCache.keys, nil
+}
+
+// verifySignature verifies signature over signedContent with the key for the JWS algorithm.
+func verifySignature(webKey jsonWebKey, algorithm string, signedContent []byte, signature []byte) error {
+	var hashFunction crypto.Hash
+	switch algorithm {
+	case "RS256", "PS256", "ES256":
+		hashFunction = crypto.SHA256
+	case "RS384", "PS384", "ES384":
+		hashFunction = crypto.SHA384
+	case "RS512", "PS512", "ES512":
+		hashFunction = crypto.SHA512
+	default:
+		return fmt.Errorf("unsupported ID token algorithm %q", algorithm)
+	}
+	hasher := hashFunction.New()
+	hasher.Write(signedContent)
+	contentDigest := hasher.Sum(nil)
+
+	switch {
+	case strings.HasPrefix(algorithm, "RS"), strings.HasPrefix(algorithm, "PS"):
+		publicKey, err := webKey.rsaPublicKey()
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(algorithm, "PS") {
+			return rsa.VerifyPSS(publicKey, hashFunction, contentDigest, signature, nil)
+		}
+		return rsa.VerifyPKCS1v15(publicKey, hashFunction, contentDigest, signature)
+	default:
+		publicKey, err := webKey.ecdsaPublicKey()
+		if err != nil {
+			return err
+		}
+		// JWS encodes an ECDSA signature as the two fixed-length integers R and S, one after the other.
+		coordinateSize := (publicKey.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*coordinateSize {
+			return errors.New("ECDSA signature has the wrong length")
+		}
+		signatureR := new(big.Int).SetBytes(signature[:coordinateSize])
+		signatureS := new(big.Int).SetBytes(signature[coordinateSize:])
+		if !ecdsa.Verify(publicKey, contentDigest, signatureR, signatureS) {
+			return errors.New("ECDSA signature is invalid")
+		}
+		return nil
+	}
+}
+
+// rsaPublicKey decodes an RSA JSON Web Key.
+func (webKey jsonWebKey) rsaPublicKey() (*rsa.PublicKey, error) {
+	if webKey.KeyType != "RSA" {
+		return nil, fmt.Errorf("key %q is not an RSA key", webKey.KeyID)
+	}
+	modulusBytes, err := base64.RawURLEncoding.DecodeString(webKey.Modulus)
+	if err != nil {
+		return nil, fmt.Errorf("decoding RSA modulus: %w", err)
+	}
+	exponentBytes, err := base64.RawURLEncoding.DecodeString(webKey.Exponent)
+	if err != nil {
+		return nil, fmt.Errorf("decoding RSA exponent: %w", err)
+	}
+	publicExponent := new(big.Int).SetBytes(exponentBytes)
+	if !publicExponent.IsInt64() || publicExponent.Int64() > 1<<31-1 {
+		return nil, errors.New("RSA exponent is too large")
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(modulusBytes), E: int(publicExponent.Int64())}, nil
+}
+
+// ecdsaPublicKey decodes an elliptic curve JSON Web Key on P-256, P-384 or P-521.
+func (webKey jsonWebKey) ecdsaPublicKey() (*ecdsa.PublicKey, error) {
+	if webKey.KeyType != "EC" {
+		return nil, fmt.Errorf("key %q is not an elliptic curve key", webKey.KeyID)
+	}
+	var keyCurve elliptic.Curve
+	switch webKey.Curve {
+	case "P-256":
+		keyCurve = elliptic.P256()
+	case "P-384":
+		keyCurve = elliptic.P384()
+	case "P-521":
+		keyCurve = elliptic.P521()
+	default:
+		return nil, fmt.Errorf("unsupported curve %q", webKey.Curve)
+	}
+	xBytes, err := base64.RawURLEncoding.DecodeString(webKey.X)
+	if err != nil {
+		return nil, fmt.Errorf("decoding curve point: %w", err)
+	}
+	yBytes, err := base64.RawURLEncoding.DecodeString(webKey.Y)
+	if err != nil {
+		return nil, fmt.Errorf("decoding curve point: %w", err)
+	}
+	publicKey := &ecdsa.PublicKey{Curve: keyCurve, X: new(big.Int).SetBytes(xBytes), Y: new(big.Int).SetBytes(yBytes)}
+	if !keyCurve.IsOnCurve(publicKey.X, publicKey.Y) {
+		return nil, errors.New("elliptic curve key is not on its curve")
+	}
+	return publicKey, nil
+}
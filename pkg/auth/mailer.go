@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/temirov/RSVP/pkg/config"
+)
+
+// Mailer delivers plain-text email.
+type Mailer interface {
+	Send(recipientAddress string, subject string, body string) error
+}
+
+// SMTPMailer sends mail through an SMTP server, upgrading to TLS when the server offers it.
+type SMTPMailer struct {
+	settings config.SMTPConfig
+}
+
+// NewSMTPMailer creates a mailer for the configured server. It authenticates only when a username is set.
+func NewSMTPMailer(settings config.SMTPConfig) *SMTPMailer {
+	return &SMTPMailer{settings: settings}
+}
+
+// Send delivers one message.
+func (smtpMailer *SMTPMailer) Send(recipientAddress string, subject string, body string) error {
+	if strings.ContainsAny(recipientAddress+subject, "\r\n") {
+		return fmt.Errorf("refusing to send mail with a line break in its headers")
+	}
+	serverAddress := net.JoinHostPort(smtpMailer.settings.Host, strconv.Itoa(smtpMailer.settings.Port))
+	var smtpAuth smtp.Auth
+	if smtpMailer.settings.Username != "" {
+		smtpAuth = smtp.PlainAuth("", smtpMailer.settings.Username, smtpMailer.settings.Password, smtpMailer.settings.Host)
+	}
+	message := strings.Join([]string{
+		"From: " + smtpMailer.settings.From,
+		"To: " + recipientAddress,
+		"Subject: " + subject,
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		body,
+	}, "\r\n")
+	return smtp.SendMail(serverAddress, smtpAuth, smtpMailer.settings.From, []string{recipientAddress}, []byte(message))
+}
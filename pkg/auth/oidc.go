@@ -0,0 +1,406 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	gconstants "github.com/temirov/GAuss/pkg/constants"
+	"github.com/temirov/GAuss/pkg/session"
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/logging"
+	"golang.org/x/oauth2"
+)
+
+// oidcDiscoveryPath is appended to the issuer URL to find the provider's configuration.
+const oidcDiscoveryPath = "/.well-known/openid-configuration"
+
+// OIDCProvider signs users in with any OpenID Connect identity provider using the authorization code flow
+// with PKCE. Endpoints are found through issuer discovery the first time someone signs in, so the
+// application starts even while the identity provider is unreachable.
+type OIDCProvider struct {
+	settings    config.OIDCConfig
+	redirectURL string
+	// httpClient is used for every request to the identity provider, which lets tests point it at a fake issuer.
+	httpClient *http.Client
+
+	discoveryMutex sync.Mutex
+	discovered     *oidcDiscoveryDocument
+	signingKeys    *jwksCache
+}
+
+// oidcDiscoveryDocument holds the fields of the provider configuration that the sign-in uses.
+type oidcDiscoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// oidcClaims holds the ID token and userinfo claims that identify the user.
+type oidcClaims struct {
+	Issuer            string        `json:"iss"`
+	Subject           string        `json:"sub"`
+	Audience          oidcAudience  `json:"aud"`
+	AuthorizedParty   string        `json:"azp"`
+	ExpiresAt         float64       `json:"exp"`
+	Nonce             string        `json:"nonce"`
+	Email             string        `json:"email"`
+	EmailVerified     oidcClaimBool `json:"email_verified"`
+	Name              string        `json:"name"`
+	PreferredUsername string        `json:"preferred_username"`
+	Picture           string        `json:"picture"`
+}
+
+// oidcAudience is the "aud" claim, which is either a single string or an array of strings.
+type oidcAudience []string
+
+// UnmarshalJSON accepts both forms of the audience claim.
+func (audience *oidcAudience) UnmarshalJSON(rawClaim []byte) error {
+	var singleAudience string
+	if json.Unmarshal(rawClaim, &singleAudience) == nil {
+		*audience = oidcAudience{singleAudience}
+		return nil
+	}
+	var audienceList []string
+	if err := json.Unmarshal(rawClaim, &audienceList); err != nil {
+		return fmt.Errorf("invalid aud claim: %w", err)
+	}
+	*audience = audienceList
+	return nil
+}
+
+// oidcClaimBool is a boolean claim that some providers send as the string "true" or "false".
+// Unset means the provider did not say.
+type oidcClaimBool struct {
+	IsSet bool
+	Value bool
+}
+
+// UnmarshalJSON accepts booleans and their string forms.
+func (claimBool *oidcClaimBool) UnmarshalJSON(rawClaim []byte) error {
+	switch strings.Trim(string(rawClaim), `"`) {
+	case "true":
+		*claimBool = oidcClaimBool{IsSet: true, Value: true}
+	case "false":
+		*claimBool = oidcClaimBool{IsSet: true, Value: false}
+	case "null":
+		*claimBool = oidcClaimBool{}
+	default:
+		return fmt.Errorf("invalid boolean claim %s", rawClaim)
+	}
+	return nil
+}
+
+// NewOIDCProvider creates the OpenID Connect provider. The redirect URI registered with the identity provider
+// must be the application's base URL followed by /auth/oidc/callback. The issuer must be served over HTTPS.
+func NewOIDCProvider(settings config.OIDCConfig, appBaseURL string, httpClient *http.Client) (*OIDCProvider, error) {
+	issuerURL, err := url.Parse(settings.IssuerURL)
+	if err != nil || issuerURL.Scheme != "https" || issuerURL.Host == "" {
+		return nil, fmt.Errorf("invalid OIDC issuer URL %q (expected an https URL)", settings.IssuerURL)
+	}
+	settings.IssuerURL = strings.TrimSuffix(settings.IssuerURL, "/")
+	return &OIDCProvider{
+		settings:    settings,
+		redirectURL: absoluteURL(appBaseURL, config.WebAuthOIDCCallback),
+		httpClient:  httpClient,
+	}, nil
+}
+
+// Name returns the provider's AUTH_PROVIDERS value.
+func (oidcProvider *OIDCProvider) Name() string {
+	return config.AuthProviderOIDC
+}
+
+// RegisterRoutes adds the endpoints that start the sign-in and receive the identity provider's answer.
+func (oidcProvider *OIDCProvider) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc(config.WebAuthOIDC, oidcProvider.StartHandler)
+	mux.HandleFunc(config.WebAuthOIDCCallback, oidcProvider.CallbackHandler)
+}
+
+// SignInButton describes the button labelled with OIDC_LABEL.
+func (oidcProvider *OIDCProvider) SignInButton() SignInButton {
+	return SignInButton{Label: "Sign in with " + oidcProvider.settings.Label, Icon: "bi-key", URL: config.WebAuthOIDC}
+}
+
+// StartHandler redirects to the identity provider's authorization endpoint. The state, nonce and PKCE verifier
+// are kept in the session until the callback.
+func (oidcProvider *OIDCProvider) StartHandler(responseWriter http.ResponseWriter, request *http.Request) {
+	requestContext, cancelRequest := context.WithTimeout(request.Context(), config.OIDCRequestTimeout)
+	defer cancelRequest()
+	discoveryDocument, err := oidcProvider.discover(requestContext)
+	if err != nil {
+		logging.FromContext(request.Context()).Error("OIDC discovery failed", "issuer", oidcProvider.settings.IssuerURL, "error", err)
+		redirectToLogin(responseWriter, request, config.ErrorQueryParam, "The sign-in service is unavailable. Please try again later.")
+		return
+	}
+	stateValue, err := randomURLSafeString()
+	if err != nil {
+		logging.FromContext(request.Context()).Error("Generating OIDC state failed", "error", err)
+		redirectToLogin(responseWriter, request, config.ErrorQueryParam, "Sign-in could not be started. Please try again.")
+		return
+	}
+	nonceValue, err := randomURLSafeString()
+	if err != nil {
+		logging.FromContext(request.Context()).Error("Generating OIDC nonce failed", "error", err)
+		redirectToLogin(responseWriter, request, config.ErrorQueryParam, "Sign-in could not be started. Please try again.")
+		return
+	}
+	pkceVerifier := oauth2.GenerateVerifier()
+
+	webSession, _ := session.Store().Get(request, gconstants.SessionName)
+	webSession.Values[config.SessionKeyOIDCState] = stateValue
+	webSession.Values[config.SessionKeyOIDCNonce] = nonceValue
+	webSession.Values[config.SessionKeyOIDCVerifier] = pkceVerifier
+	if sessionSaveError := webSession.Save(request, responseWriter); sessionSaveError != nil {
+		logging.FromContext(request.Context()).Error("Saving the OIDC sign-in session failed", "error", sessionSaveError)
+		redirectToLogin(responseWriter, request, config.ErrorQueryParam, "Sign-in could not be started. Please try again.")
+		return
+	}
+	authorizationURL := oidcProvider.oauthConfig(discoveryDocument).AuthCodeURL(stateValue,
+		oauth2.SetAuthURLParam("nonce", nonceValue),
+		oauth2.S256ChallengeOption(pkceVerifier),
+	)
+	http.Redirect(responseWriter, request, authorizationURL, http.StatusFound)
+}
+
+// CallbackHandler exchanges the authorization code, validates the ID token and signs the user in.
+func (oidcProvider *OIDCProvider) CallbackHandler(responseWriter http.ResponseWriter, request *http.Request) {
+	webSession, _ := session.Store().Get(request, gconstants.SessionName)
+	storedState, _ := webSession.Values[config.SessionKeyOIDCState].(string)
+	storedNonce, _ := webSession.Values[config.SessionKeyOIDCNonce].(string)
+	pkceVerifier, _ := webSession.Values[config.SessionKeyOIDCVerifier].(string)
+	// The state, nonce and verifier are single-use, whatever the outcome.
+	delete(webSession.Values, config.SessionKeyOIDCState)
+	delete(webSession.Values, config.SessionKeyOIDCNonce)
+	delete(webSession.Values, config.SessionKeyOIDCVerifier)
+	if sessionSaveError := webSession.Save(request, responseWriter); sessionSaveError != nil {
+		logging.FromContext(request.Context()).Warn("Clearing the OIDC sign-in session failed", "error", sessionSaveError)
+	}
+
+	callbackQuery := request.URL.Query()
+	if providerError := callbackQuery.Get("error"); providerError != "" {
+		logging.FromContext(request.Context()).Warn("OIDC sign-in was refused by the identity provider", "error", providerError, "description", callbackQuery.Get("error_description"))
+		redirectToLogin(responseWriter, request, config.ErrorQueryParam, "Sign-in was cancelled or refused.")
+		return
+	}
+	if storedState == "" || callbackQuery.Get("state") != storedState {
+		logging.FromContext(request.Context()).Warn("OIDC callback state does not match the session")
+		redirectToLogin(responseWriter, request, config.ErrorQueryParam, "Sign-in expired. Please try again.")
+		return
+	}
+	authorizationCode := callbackQuery.Get("code")
+	if authorizationCode == "" {
+		logging.FromContext(request.Context()).Warn("OIDC callback without an authorization code")
+		redirectToLogin(responseWriter, request, config.ErrorQueryParam, "Sign-in failed. Please try again.")
+		return
+	}
+
+	requestContext, cancelRequest := context.WithTimeout(request.Context(), config.OIDCRequestTimeout)
+	defer cancelRequest()
+	signedInIdentity, err := oidcProvider.exchange(requestContext, authorizationCode, pkceVerifier, storedNonce)
+	if err != nil {
+		logging.FromContext(request.Context()).Error("OIDC sign-in failed", "issuer", oidcProvider.settings.IssuerURL, "error", err)
+		redirectToLogin(responseWriter, request, config.ErrorQueryParam, "Sign-in failed. Please try again.")
+		return
+	}
+	completeSignIn(responseWriter, request, signedInIdentity)
+}
+
+// exchange trades the authorization code for tokens and returns the identity they carry. The ID token's signature
+// is verified against the identity provider's published keys, and its issuer, audience, expiry and nonce are
+// validated. Users are matched by email address, so the provider must also vouch that the address is verified.
+func (oidcProvider *OIDCProvider) exchange(requestContext context.Context, authorizationCode string, pkceVerifier string, expectedNonce string) (Identity, error) {
+	discoveryDocument, err := oidcProvider.discover(requestContext)
+	if err != nil {
+		return Identity{}, err
+	}
+	clientContext := context.WithValue(requestContext, oauth2.HTTPClient, oidcProvider.httpClient)
+	oauthToken, err := oidcProvider.oauthConfig(discoveryDocument).Exchange(clientContext, authorizationCode, oauth2.VerifierOption(pkceVerifier))
+	if err != nil {
+		return Identity{}, fmt.Errorf("token exchange: %w", err)
+	}
+	rawIDToken, _ := oauthToken.Extra("id_token").(string)
+	if rawIDToken == "" {
+		return Identity{}, errors.New("token response has no id_token")
+	}
+	if err := oidcProvider.signingKeys.verifyJWTSignature(requestContext, rawIDToken); err != nil {
+		return Identity{}, err
+	}
+	idTokenClaims, err := decodeIDTokenClaims(rawIDToken)
+	if err != nil {
+		return Identity{}, err
+	}
+	if err := oidcProvider.validateIDTokenClaims(idTokenClaims, discoveryDocument.Issuer, expectedNonce); err != nil {
+		return Identity{}, err
+	}
+
+	// Providers may leave profile claims out of the ID token and serve them from the userinfo endpoint.
+	if (idTokenClaims.Email == "" || idTokenClaims.Name == "") && discoveryDocument.UserinfoEndpoint != "" {
+		userinfoClaims, err := oidcProvider.fetchUserinfo(clientContext, discoveryDocument.UserinfoEndpoint, oauthToken)
+		if err != nil {
+			return Identity{}, err
+		}
+		if userinfoClaims.Subject != idTokenClaims.Subject {
+			return Identity{}, errors.New("userinfo subject does not match the ID token")
+		}
+		if idTokenClaims.Email == "" {
+			idTokenClaims.Email = userinfoClaims.Email
+			idTokenClaims.EmailVerified = userinfoClaims.EmailVerified
+		}
+		if idTokenClaims.Name == "" {
+			idTokenClaims.Name = userinfoClaims.Name
+			idTokenClaims.PreferredUsername = userinfoClaims.PreferredUsername
+		}
+		if idTokenClaims.Picture == "" {
+			idTokenClaims.Picture = userinfoClaims.Picture
+		}
+	}
+	if idTokenClaims.Email == "" {
+		return Identity{}, errors.New("the identity provider did not return an email address; request the email scope")
+	}
+	if !idTokenClaims.EmailVerified.IsSet || !idTokenClaims.EmailVerified.Value {
+		return Identity{}, fmt.Errorf("email address %s is not verified by the identity provider", idTokenClaims.Email)
+	}
+	displayName := idTokenClaims.Name
+	if displayName == "" {
+		displayName = idTokenClaims.PreferredUsername
+	}
+	return Identity{Email: idTokenClaims.Email, Name: displayName, Picture: idTokenClaims.Picture}, nil
+}
+
+// validateIDTokenClaims checks that the ID token was issued by the configured issuer, for this client, for
+// this sign-in attempt, and has not expired.
+func (oidcProvider *OIDCProvider) validateIDTokenClaims(idTokenClaims oidcClaims, expectedIssuer string, expectedNonce string) error {
+	if idTokenClaims.Issuer != expectedIssuer {
+		return fmt.Errorf("ID token issuer %q does not match %q", idTokenClaims.Issuer, expectedIssuer)
+	}
+	audienceMatches := false
+	for _, tokenAudience := range idTokenClaims.Audience {
+		if tokenAudience == oidcProvider.settings.ClientID {
+			audienceMatches = true
+		}
+	}
+	if !audienceMatches {
+		return errors.New("ID token was not issued for this client")
+	}
+	if len(idTokenClaims.Audience) > 1 && idTokenClaims.AuthorizedParty != oidcProvider.settings.ClientID {
+		return errors.New("ID token was issued to another authorized party")
+	}
+	if time.Now().Unix() >= int64(idTokenClaims.ExpiresAt) {
+		return errors.New("ID token has expired")
+	}
+	if expectedNonce == "" || idTokenClaims.Nonce != expectedNonce {
+		return errors.New("ID token nonce does not match the sign-in attempt")
+	}
+	if idTokenClaims.Subject == "" {
+		return errors.New("ID token has no subject")
+	}
+	return nil
+}
+
+// fetchUserinfo reads the user's claims from the userinfo endpoint with the access token.
+func (oidcProvider *OIDCProvider) fetchUserinfo(clientContext context.Context, userinfoEndpoint string, oauthToken *oauth2.Token) (oidcClaims, error) {
+	var userinfoClaims oidcClaims
+	userinfoClient := oauth2.NewClient(clientContext, oauth2.StaticTokenSource(oauthToken))
+	userinfoRequest, err := http.NewRequestWithContext(clientContext, http.MethodGet, userinfoEndpoint, nil)
+	if err != nil {
+		return userinfoClaims, err
+	}
+	userinfoResponse, err := userinfoClient.Do(userinfoRequest)
+	if err != nil {
+		return userinfoClaims, fmt.Errorf("userinfo request: %w", err)
+	}
+	defer userinfoResponse.Body.Close()
+	if userinfoResponse.StatusCode != http.StatusOK {
+		return userinfoClaims, fmt.Errorf("userinfo endpoint returned status %d", userinfoResponse.StatusCode)
+	}
+	if err := json.NewDecoder(userinfoResponse.Body).Decode(&userinfoClaims); err != nil {
+		return userinfoClaims, fmt.Errorf("decoding userinfo: %w", err)
+	}
+	return userinfoClaims, nil
+}
+
+// discover fetches and caches the provider configuration. Failures are not cached, so a provider that was
+// down is retried on the next sign-in.
+func (oidcProvider *OIDCProvider) discover(requestContext context.Context) (*oidcDiscoveryDocument, error) {
+	oidcProvider.discoveryMutex.Lock()
+	defer oidcProvider.discoveryMutex.Unlock()
+	if oidcProvider.discovered != nil {
+		return oidcProvider.discovered, nil
+	}
+	discoveryRequest, err := http.NewRequestWithContext(requestContext, http.MethodGet, oidcProvider.settings.IssuerURL+oidcDiscoveryPath, nil)
+	if err != nil {
+		return nil, err
+	}
+	discoveryResponse, err := oidcProvider.httpClient.Do(discoveryRequest)
+	if err != nil {
+		return nil, err
+	}
+	defer discoveryResponse.Body.Close()
+	if discoveryResponse.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("discovery document returned status %d", discoveryResponse.StatusCode)
+	}
+	var discoveryDocument oidcDiscoveryDocument
+	if err := json.NewDecoder(discoveryResponse.Body).Decode(&discoveryDocument); err != nil {
+		return nil, fmt.Errorf("decoding discovery document: %w", err)
+	}
+	if strings.TrimSuffix(discoveryDocument.Issuer, "/") != oidcProvider.settings.IssuerURL {
+		return nil, fmt.Errorf("discovery document names issuer %q instead of %q", discoveryDocument.Issuer, oidcProvider.settings.IssuerURL)
+	}
+	if discoveryDocument.AuthorizationEndpoint == "" || discoveryDocument.TokenEndpoint == "" || discoveryDocument.JWKSURI == "" {
+		return nil, errors.New("discovery document lacks the authorization, token or signing keys endpoint")
+	}
+	oidcProvider.discovered = &discoveryDocument
+	oidcProvider.signingKeys = &jwksCache{jwksURL: discoveryDocument.JWKSURI, httpClient: oidcProvider.httpClient}
+	return oidcProvider.discovered, nil
+}
+
+// oauthConfig returns the OAuth2 client configuration for the discovered endpoints.
+func (oidcProvider *OIDCProvider) oauthConfig(discoveryDocument *oidcDiscoveryDocument) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     oidcProvider.settings.ClientID,
+		ClientSecret: oidcProvider.settings.ClientSecret,
+		RedirectURL:  oidcProvider.redirectURL,
+		Scopes:       oidcProvider.settings.Scopes,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  discoveryDocument.AuthorizationEndpoint,
+			TokenURL: discoveryDocument.TokenEndpoint,
+		},
+	}
+}
+
+// decodeIDTokenClaims reads the claims from the payload segment of a compact JWT.
+func decodeIDTokenClaims(rawIDToken string) (oidcClaims, error) {
+	var idTokenClaims oidcClaims
+	tokenSegments := strings.Split(rawIDToken, ".")
+	if len(tokenSegments) != 3 {
+		return idTokenClaims, errors.New("ID token is not a JWT")
+	}
+	claimsJSON, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(tokenSegments[1], "="))
+	if err != nil {
+		return idTokenClaims, fmt.Errorf("decoding ID token payload: %w", err)
+	}
+	if err := json.Unmarshal(claimsJSON, &idTokenClaims); err != nil {
+		return idTokenClaims, fmt.Errorf("parsing ID token claims: %w", err)
+	}
+	return idTokenClaims, nil
+}
+
+// randomURLSafeString returns 32 random bytes encoded for use in URLs, as state and nonce values.
+func randomURLSafeString() (string, error) {
+	randomBytes := make([]byte, 32)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(randomBytes), nil
+}
@@ -0,0 +1,342 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	gconstants "github.com/temirov/GAuss/pkg/constants"
+	"github.com/temirov/GAuss/pkg/session"
+	"github.com/temirov/RSVP/pkg/config"
+)
+
+const (
+	testOIDCClientID = "rsvp-test-client"
+	testOIDCKeyID    = "test-key"
+	testOIDCCode     = "test-authorization-code"
+)
+
+// fakeIssuer is an OpenID Connect identity provider serving discovery, signing keys, the token endpoint and
+// userinfo over TLS. It remembers the nonce and PKCE challenge of the last authorization request, as a real
+// provider would, and issues ID tokens for them.
+type fakeIssuer struct {
+	server     *httptest.Server
+	signingKey *rsa.PrivateKey
+
+	mutex sync.Mutex
+	// discoveredIssuer overrides the issuer named in the discovery document when it is not empty.
+	discoveredIssuer string
+	// claimOverrides replace or, when nil, remove claims of the ID token.
+	claimOverrides map[string]interface{}
+	// tokenSigningKey signs ID tokens instead of signingKey when it is set, as an attacker without the key would.
+	tokenSigningKey   *rsa.PrivateKey
+	authorizedNonce   string
+	pkceChallenge     string
+	tokenRequestCount int
+}
+
+func newFakeIssuer(t *testing.T) *fakeIssuer {
+	t.Helper()
+	signingKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generating the issuer's key: %v", err)
+	}
+	issuer := &fakeIssuer{signingKey: signingKey}
+	issuerMux := http.NewServeMux()
+	issuerMux.HandleFunc(oidcDiscoveryPath, issuer.serveDiscovery)
+	issuerMux.HandleFunc("/jwks", issuer.serveKeys)
+	issuerMux.HandleFunc("/token", issuer.serveToken)
+	issuerMux.HandleFunc("/userinfo", issuer.serveUserinfo)
+	issuer.server = httptest.NewTLSServer(issuerMux)
+	t.Cleanup(issuer.server.Close)
+	return issuer
+}
+
+func (issuer *fakeIssuer) serveDiscovery(responseWriter http.ResponseWriter, _ *http.Request) {
+	issuer.mutex.Lock()
+	discoveredIssuer := issuer.discoveredIssuer
+	issuer.mutex.Unlock()
+	if discoveredIssuer == "" {
+		discoveredIssuer = issuer.server.URL
+	}
+	writeTestJSON(responseWriter, map[string]string{
+		"issuer":                 discoveredIssuer,
+		"authorization_endpoint": issuer.server.URL + "/authorize",
+		"token_endpoint":         issuer.server.URL + "/token",
+		"userinfo_endpoint":      issuer.server.URL + "/userinfo",
+		"jwks_uri":               issuer.server.URL + "/jwks",
+	})
+}
+
+func (issuer *fakeIssuer) serveKeys(responseWriter http.ResponseWriter, _ *http.Request) {
+	publicKey := issuer.signingKey.PublicKey
+	writeTestJSON(responseWriter, map[string]interface{}{"keys": []map[string]string{{
+		"kty": "RSA",
+		"kid": testOIDCKeyID,
+		"use": "sig",
+		"alg": "RS256",
+		"n":   base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes()),
+	}}})
+}
+
+// serveToken checks the authorization code and the PKCE verifier, then returns an access token and a signed ID
+// token for the nonce of the authorization request.
+func (issuer *fakeIssuer) serveToken(responseWriter http.ResponseWriter, request *http.Request) {
+	issuer.mutex.Lock()
+	defer issuer.mutex.Unlock()
+	issuer.tokenRequestCount++
+	verifierDigest := sha256.Sum256([]byte(request.PostFormValue("code_verifier")))
+	if request.PostFormValue("code") != testOIDCCode ||
+		base64.RawURLEncoding.EncodeToString(verifierDigest[:]) != issuer.pkceChallenge {
+		responseWriter.WriteHeader(http.StatusBadRequest)
+		writeTestJSON(responseWriter, map[string]string{"error": "invalid_grant"})
+		return
+	}
+	idTokenClaims := map[string]interface{}{
+		"iss":            issuer.server.URL,
+		"sub":            "user-1",
+		"aud":            testOIDCClientID,
+		"exp":            time.Now().Add(time.Hour).Unix(),
+		"iat":            time.Now().Unix(),
+		"nonce":          issuer.authorizedNonce,
+		"email":          "ada@example.com",
+		"email_verified": true,
+		"name":           "Ada Lovelace",
+	}
+	for claimName, claimValue := range issuer.claimOverrides {
+		if claimValue == nil {
+			delete(idTokenClaims, claimName)
+		} else {
+			idTokenClaims[claimName] = claimValue
+		}
+	}
+	tokenSigningKey := issuer.signingKey
+	if issuer.tokenSigningKey != nil {
+		tokenSigningKey = issuer.tokenSigningKey
+	}
+	writeTestJSON(responseWriter, map[string]interface{}{
+		"access_token": "test-access-token",
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     signTestJWT(tokenSigningKey, idTokenClaims),
+	})
+}
+
+// serveUserinfo answers for the same user without vouching for the email address, so that a token lacking
+// email_verified cannot be rescued by userinfo.
+func (issuer *fakeIssuer) serveUserinfo(responseWriter http.ResponseWriter, request *http.Request) {
+	if request.Header.Get("Authorization") != "Bearer test-access-token" {
+		responseWriter.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	writeTestJSON(responseWriter, map[string]interface{}{"sub": "user-1", "email": "ada@example.com", "name": "Ada Lovelace"})
+}
+
+func writeTestJSON(responseWriter http.ResponseWriter, payload interface{}) {
+	responseWriter.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(responseWriter).Encode(payload)
+}
+
+// signTestJWT returns a compact RS256 JWT carrying the claims.
+func signTestJWT(signingKey *rsa.PrivateKey, tokenClaims map[string]interface{}) string {
+	headerJSON, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": testOIDCKeyID, "typ": "JWT"})
+	claimsJSON, _ := json.Marshal(tokenClaims)
+	signedContent := base64.RawURLEncoding.EncodeToString(headerJSON) + "." + base64.RawURLEncoding.EncodeToString(claimsJSON)
+	contentDigest := sha256.Sum256([]byte(signedContent))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, signingKey, crypto.SHA256, contentDigest[:])
+	if err != nil {
+		panic(err)
+	}
+	return signedContent + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func newTestOIDCProvider(t *testing.T, issuer *fakeIssuer) *OIDCProvider {
+	t.Helper()
+	session.NewSession([]byte("0123456789abcdef0123456789abcdef"))
+	oidcSettings := config.OIDCConfig{
+		IssuerURL:    issuer.server.URL,
+		ClientID:     testOIDCClientID,
+		ClientSecret: "test-secret",
+		Label:        "Test SSO",
+		Scopes:       []string{"openid", "email", "profile"},
+	}
+	oidcProvider, err := NewOIDCProvider(oidcSettings, "http://localhost:8080/", issuer.server.Client())
+	if err != nil {
+		t.Fatalf("NewOIDCProvider() error = %v", err)
+	}
+	return oidcProvider
+}
+
+// oidcSignInAttempt is the browser's side of a sign-in: the redirect to the identity provider and the callback.
+type oidcSignInAttempt struct {
+	authorizationURL *url.URL
+	sessionCookies   []*http.Cookie
+}
+
+// startOIDCSignIn follows the sign-in button and plays the identity provider's part of the authorization request.
+func startOIDCSignIn(t *testing.T, oidcProvider *OIDCProvider, issuer *fakeIssuer) *oidcSignInAttempt {
+	t.Helper()
+	responseRecorder := httptest.NewRecorder()
+	oidcProvider.StartHandler(responseRecorder, httptest.NewRequest(http.MethodGet, config.WebAuthOIDC, nil))
+	if responseRecorder.Code != http.StatusFound {
+		t.Fatalf("StartHandler() status = %d, want %d", responseRecorder.Code, http.StatusFound)
+	}
+	authorizationURL, err := url.Parse(responseRecorder.Header().Get("Location"))
+	if err != nil {
+		t.Fatalf("parsing the authorization redirect: %v", err)
+	}
+	if authorizationURL.Host != issuer.server.Listener.Addr().String() || authorizationURL.Path != "/authorize" {
+		t.Fatalf("StartHandler() redirected to %s, want the issuer's authorization endpoint", authorizationURL)
+	}
+	authorizationQuery := authorizationURL.Query()
+	issuer.mutex.Lock()
+	issuer.authorizedNonce = authorizationQuery.Get("nonce")
+	issuer.pkceChallenge = authorizationQuery.Get("code_challenge")
+	issuer.mutex.Unlock()
+	return &oidcSignInAttempt{authorizationURL: authorizationURL, sessionCookies: responseRecorder.Result().Cookies()}
+}
+
+// callback returns to the application from the identity provider with the given query.
+func (attempt *oidcSignInAttempt) callback(oidcProvider *OIDCProvider, callbackQuery url.Values) *httptest.ResponseRecorder {
+	callbackRequest := httptest.NewRequest(http.MethodGet, config.WebAuthOIDCCallback+"?"+callbackQuery.Encode(), nil)
+	for _, sessionCookie := range attempt.sessionCookies {
+		callbackRequest.AddCookie(sessionCookie)
+	}
+	responseRecorder := httptest.NewRecorder()
+	oidcProvider.CallbackHandler(responseRecorder, callbackRequest)
+	return responseRecorder
+}
+
+// complete returns to the application with the authorization code and the state of the request.
+func (attempt *oidcSignInAttempt) complete(oidcProvider *OIDCProvider) *httptest.ResponseRecorder {
+	return attempt.callback(oidcProvider, url.Values{"code": {testOIDCCode}, "state": {attempt.authorizationURL.Query().Get("state")}})
+}
+
+// signedInEmail returns the email address the response's session cookie signs in as. The callback may save the
+// session more than once; like a browser, the last cookie of a name wins.
+func signedInEmail(t *testing.T, responseRecorder *httptest.ResponseRecorder) string {
+	t.Helper()
+	latestCookies := make(map[string]*http.Cookie)
+	for _, sessionCookie := range responseRecorder.Result().Cookies() {
+		latestCookies[sessionCookie.Name] = sessionCookie
+	}
+	sessionRequest := httptest.NewRequest(http.MethodGet, config.WebEvents, nil)
+	for _, sessionCookie := range latestCookies {
+		sessionRequest.AddCookie(sessionCookie)
+	}
+	webSession, _ := session.Store().Get(sessionRequest, gconstants.SessionName)
+	signedInAddress, _ := webSession.Values[gconstants.SessionKeyUserEmail].(string)
+	return signedInAddress
+}
+
+// assertSignInRefused checks that the response sends the user back to the sign-in page with an error and
+// without a signed-in session.
+func assertSignInRefused(t *testing.T, responseRecorder *httptest.ResponseRecorder) {
+	t.Helper()
+	redirectURL, err := url.Parse(responseRecorder.Header().Get("Location"))
+	if err != nil || redirectURL.Path != config.WebLogin || redirectURL.Query().Get(config.ErrorQueryParam) == "" {
+		t.Errorf("redirect = %q, want the sign-in page with an error", responseRecorder.Header().Get("Location"))
+	}
+	if signedInAddress := signedInEmail(t, responseRecorder); signedInAddress != "" {
+		t.Errorf("the session signs in as %q, want nobody", signedInAddress)
+	}
+}
+
+func TestOIDCProviderRequiresHTTPSIssuer(t *testing.T) {
+	_, err := NewOIDCProvider(config.OIDCConfig{IssuerURL: "http://idp.example.com"}, "http://localhost:8080/", http.DefaultClient)
+	if err == nil {
+		t.Error("NewOIDCProvider() accepted an http issuer")
+	}
+}
+
+func TestOIDCSignIn(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	oidcProvider := newTestOIDCProvider(t, issuer)
+	signInAttempt := startOIDCSignIn(t, oidcProvider, issuer)
+
+	authorizationQuery := signInAttempt.authorizationURL.Query()
+	expectedParameters := map[string]string{
+		"client_id":             testOIDCClientID,
+		"redirect_uri":          "http://localhost:8080" + config.WebAuthOIDCCallback,
+		"response_type":         "code",
+		"code_challenge_method": "S256",
+		"scope":                 "openid email profile",
+	}
+	for parameterName, expectedValue := range expectedParameters {
+		if parameterValue := authorizationQuery.Get(parameterName); parameterValue != expectedValue {
+			t.Errorf("authorization request %s = %q, want %q", parameterName, parameterValue, expectedValue)
+		}
+	}
+	for _, parameterName := range []string{"state", "nonce", "code_challenge"} {
+		if authorizationQuery.Get(parameterName) == "" {
+			t.Errorf("authorization request has no %s", parameterName)
+		}
+	}
+
+	responseRecorder := signInAttempt.complete(oidcProvider)
+	if location := responseRecorder.Header().Get("Location"); location != config.WebEvents {
+		t.Fatalf("callback redirected to %q, want %q", location, config.WebEvents)
+	}
+	if signedInAddress := signedInEmail(t, responseRecorder); signedInAddress != "ada@example.com" {
+		t.Errorf("signed in as %q, want ada@example.com", signedInAddress)
+	}
+}
+
+func TestOIDCDiscoveryRejectsAnotherIssuer(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	issuer.discoveredIssuer = "https://attacker.example.com"
+	oidcProvider := newTestOIDCProvider(t, issuer)
+	responseRecorder := httptest.NewRecorder()
+	oidcProvider.StartHandler(responseRecorder, httptest.NewRequest(http.MethodGet, config.WebAuthOIDC, nil))
+	assertSignInRefused(t, responseRecorder)
+}
+
+func TestOIDCCallbackRejectsBadState(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	oidcProvider := newTestOIDCProvider(t, issuer)
+	signInAttempt := startOIDCSignIn(t, oidcProvider, issuer)
+	assertSignInRefused(t, signInAttempt.callback(oidcProvider, url.Values{"code": {testOIDCCode}, "state": {"forged"}}))
+	if issuer.tokenRequestCount != 0 {
+		t.Errorf("the code was exchanged %d times despite the bad state", issuer.tokenRequestCount)
+	}
+}
+
+func TestOIDCCallbackRejectsInvalidIDTokens(t *testing.T) {
+	attackerKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generating the attacker's key: %v", err)
+	}
+	testCases := map[string]func(issuer *fakeIssuer){
+		"bad nonce":              func(issuer *fakeIssuer) { issuer.claimOverrides = map[string]interface{}{"nonce": "replayed"} },
+		"email not verified":     func(issuer *fakeIssuer) { issuer.claimOverrides = map[string]interface{}{"email_verified": false} },
+		"email_verified missing": func(issuer *fakeIssuer) { issuer.claimOverrides = map[string]interface{}{"email_verified": nil} },
+		"other audience":         func(issuer *fakeIssuer) { issuer.claimOverrides = map[string]interface{}{"aud": "another-client"} },
+		"other issuer": func(issuer *fakeIssuer) {
+			issuer.claimOverrides = map[string]interface{}{"iss": "https://attacker.example.com"}
+		},
+		"expired": func(issuer *fakeIssuer) {
+			issuer.claimOverrides = map[string]interface{}{"exp": time.Now().Add(-time.Minute).Unix()}
+		},
+		"unknown signing key": func(issuer *fakeIssuer) { issuer.tokenSigningKey = attackerKey },
+	}
+	for name, tamper := range testCases {
+		t.Run(name, func(t *testing.T) {
+			issuer := newFakeIssuer(t)
+			tamper(issuer)
+			oidcProvider := newTestOIDCProvider(t, issuer)
+			signInAttempt := startOIDCSignIn(t, oidcProvider, issuer)
+			assertSignInRefused(t, signInAttempt.complete(oidcProvider))
+		})
+	}
+}
@@ -0,0 +1,340 @@
+// Package backup takes consistent online snapshots of the SQLite database and restores them offline.
+// Snapshots are written with VACUUM INTO, which reads from a single transaction and therefore never
+// produces a torn copy while the server keeps serving requests.
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/migrations"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// ErrUnsupportedDriver is returned for databases other than file-based SQLite; use the server's native tooling instead.
+var ErrUnsupportedDriver = errors.New("online backup is only supported for file-based SQLite databases")
+
+// ErrBackupNotFound is returned when a named backup does not exist in the backup directory.
+var ErrBackupNotFound = errors.New("backup not found")
+
+// Info describes a backup file.
+type Info struct {
+	Name      string    `json:"name"`
+	Path      string    `json:"path"`
+	SizeBytes int64     `json:"sizeBytes"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Manager creates, lists and prunes backups of one database.
+type Manager struct {
+	databaseConnection *gorm.DB
+	databaseConfig     config.DatabaseConfig
+	backupConfig       config.BackupConfig
+	logger             *log.Logger
+	mutex              sync.Mutex
+}
+
+// NewManager creates a Manager for the given database connection and settings.
+func NewManager(databaseConnection *gorm.DB, databaseConfig config.DatabaseConfig, backupConfig config.BackupConfig, applicationLogger *log.Logger) *Manager {
+	return &Manager{
+		databaseConnection: databaseConnection,
+		databaseConfig:     databaseConfig,
+		backupConfig:       backupConfig,
+		logger:             applicationLogger,
+	}
+}
+
+// Supported reports whether online backups are possible for the configured database.
+func Supported(databaseConfig config.DatabaseConfig) bool {
+	return databaseConfig.Driver == config.DatabaseDriverSQLite && databaseConfig.DSN == ""
+}
+
+// Create writes a new snapshot into the backup directory and prunes old ones according to the retention setting.
+func (manager *Manager) Create() (Info, error) {
+	if !Supported(manager.databaseConfig) {
+		return Info{}, ErrUnsupportedDriver
+	}
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+
+	if err := os.MkdirAll(manager.backupConfig.Directory, 0o750); err != nil {
+		return Info{}, err
+	}
+	createdAt := time.Now().UTC()
+	backupName := config.BackupFilePrefix + createdAt.Format(config.BackupTimestampLayout) + config.BackupFileExtension
+	backupPath := filepath.Join(manager.backupConfig.Directory, backupName)
+	partialPath := backupPath + config.BackupPartialSuffix
+	_ = os.Remove(partialPath)
+
+	if err := manager.databaseConnection.Exec("VACUUM INTO ?", partialPath).Error; err != nil {
+		_ = os.Remove(partialPath)
+		return Info{}, fmt.Errorf("snapshot failed: %w", err)
+	}
+	if err := os.Rename(partialPath, backupPath); err != nil {
+		_ = os.Remove(partialPath)
+		return Info{}, err
+	}
+	fileInfo, err := os.Stat(backupPath)
+	if err != nil {
+		return Info{}, err
+	}
+	manager.logger.Printf("Database backup written to %s (%d bytes)", backupPath, fileInfo.Size())
+
+	if manager.backupConfig.Retention > 0 {
+		if _, pruneError := manager.pruneLocked(manager.backupConfig.Retention); pruneError != nil {
+			manager.logger.Printf("WARN: Failed to prune old backups in %s: %v", manager.backupConfig.Directory, pruneError)
+		}
+	}
+	return Info{Name: backupName, Path: backupPath, SizeBytes: fileInfo.Size(), CreatedAt: createdAt}, nil
+}
+
+// List returns the backups in the backup directory, newest first.
+func (manager *Manager) List() ([]Info, error) {
+	return List(manager.backupConfig.Directory)
+}
+
+// Prune deletes all but the newest keepCount backups and returns the names of the deleted files.
+func (manager *Manager) Prune(keepCount int) ([]string, error) {
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+	return manager.pruneLocked(keepCount)
+}
+
+// pruneLocked implements Prune; the manager mutex must be held.
+func (manager *Manager) pruneLocked(keepCount int) ([]string, error) {
+	existingBackups, err := List(manager.backupConfig.Directory)
+	if err != nil || len(existingBackups) <= keepCount {
+		return nil, err
+	}
+	var removedNames []string
+	for _, expiredBackup := range existingBackups[keepCount:] {
+		if removeError := os.Remove(expiredBackup.Path); removeError != nil {
+			return removedNames, removeError
+		}
+		removedNames = append(removedNames, expiredBackup.Name)
+	}
+	manager.logger.Printf("Pruned %d old backup(s) from %s", len(removedNames), manager.backupConfig.Directory)
+	return removedNames, nil
+}
+
+// RunSchedule takes a backup every backupConfig.Interval until the context is cancelled.
+// It does nothing when the interval is zero or backups are not supported for the configured database.
+func (manager *Manager) RunSchedule(scheduleContext context.Context) {
+	if manager.backupConfig.Interval <= 0 {
+		return
+	}
+	if !Supported(manager.databaseConfig) {
+		manager.logger.Printf("WARN: Scheduled backups disabled: %v", ErrUnsupportedDriver)
+		return
+	}
+	manager.logger.Printf("Scheduled backups every %s into %s (keeping %d)",
+		manager.backupConfig.Interval, manager.backupConfig.Directory, manager.backupConfig.Retention)
+	backupTicker := time.NewTicker(manager.backupConfig.Interval)
+	defer backupTicker.Stop()
+	for {
+		select {
+		case <-scheduleContext.Done():
+			return
+		case <-backupTicker.C:
+			if _, err := manager.Create(); err != nil {
+				manager.logger.Printf("ERROR: Scheduled backup failed: %v", err)
+			}
+		}
+	}
+}
+
+// List returns the backups found in backupDirectory, newest first. A missing directory has no backups.
+func List(backupDirectory string) ([]Info, error) {
+	directoryEntries, err := os.ReadDir(backupDirectory)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var backupInfos []Info
+	for _, directoryEntry := range directoryEntries {
+		entryName := directoryEntry.Name()
+		if directoryEntry.IsDir() || !strings.HasPrefix(entryName, config.BackupFilePrefix) || !strings.HasSuffix(entryName, config.BackupFileExtension) {
+			continue
+		}
+		createdAt, parseError := time.Parse(config.BackupTimestampLayout,
+			strings.TrimSuffix(strings.TrimPrefix(entryName, config.BackupFilePrefix), config.BackupFileExtension))
+		if parseError != nil {
+			continue
+		}
+		fileInfo, statError := directoryEntry.Info()
+		if statError != nil {
+			return nil, statError
+		}
+		backupInfos = append(backupInfos, Info{
+			Name:      entryName,
+			Path:      filepath.Join(backupDirectory, entryName),
+			SizeBytes: fileInfo.Size(),
+			CreatedAt: createdAt,
+		})
+	}
+	sort.Slice(backupInfos, func(left, right int) bool {
+		return backupInfos[left].CreatedAt.After(backupInfos[right].CreatedAt)
+	})
+	return backupInfos, nil
+}
+
+// Resolve turns a backup name or path into the path of an existing backup file.
+// Bare names are looked up in backupDirectory.
+func Resolve(backupDirectory string, backupReference string) (string, error) {
+	backupPath := backupReference
+	if !strings.ContainsRune(backupReference, os.PathSeparator) {
+		backupPath = filepath.Join(backupDirectory, backupReference)
+	}
+	if _, err := os.Stat(backupPath); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("%w: %s", ErrBackupNotFound, backupReference)
+		}
+		return "", err
+	}
+	return backupPath, nil
+}
+
+// Validate opens a backup read-only, runs an integrity check and returns its schema version.
+// Backups written by a newer release than this binary are rejected with migrations.ErrSchemaTooNew.
+func Validate(backupPath string) (int, error) {
+	backupConnection, err := gorm.Open(sqlite.Open("file:"+backupPath+"?mode=ro"), &gorm.Config{
+		Logger: gormlogger.Default.LogMode(gormlogger.Silent),
+	})
+	if err != nil {
+		return 0, err
+	}
+	if sqlDatabase, sqlError := backupConnection.DB(); sqlError == nil {
+		defer sqlDatabase.Close()
+	}
+
+	var integrityResult string
+	if err := backupConnection.Raw("PRAGMA integrity_check").Scan(&integrityResult).Error; err != nil {
+		return 0, fmt.Errorf("integrity check failed: %w", err)
+	}
+	if integrityResult != "ok" {
+		return 0, fmt.Errorf("integrity check failed: %s", integrityResult)
+	}
+	if !backupConnection.Migrator().HasTable(config.TableSchemaMigrations) {
+		return 0, fmt.Errorf("%s has no %s table; it is not a backup of this application", backupPath, config.TableSchemaMigrations)
+	}
+	var schemaVersion int
+	if err := backupConnection.Table(config.TableSchemaMigrations).Select("COALESCE(MAX(version), 0)").Scan(&schemaVersion).Error; err != nil {
+		return 0, err
+	}
+	if latestVersion := migrations.LatestVersion(); schemaVersion > latestVersion {
+		return schemaVersion, fmt.Errorf("%w: backup is at version %d, application supports up to %d", migrations.ErrSchemaTooNew, schemaVersion, latestVersion)
+	}
+	return schemaVersion, nil
+}
+
+// Restore replaces the SQLite database file with a validated backup. The server must be stopped first; while one
+// holds the database, Restore returns ErrDatabaseInUse. The current database, with its write-ahead log folded in,
+// is kept next to it with a ".pre-restore-<timestamp>" suffix, and the copy is
+// swapped in with a rename so a failure never leaves a half-written database behind.
+// Older backups are brought up to date by the migrations that run at the next server start.
+// It returns the path of the safety copy of the previous database (empty if there was none).
+func Restore(databaseConfig config.DatabaseConfig, backupPath string) (string, error) {
+	if !Supported(databaseConfig) {
+		return "", ErrUnsupportedDriver
+	}
+	if _, err := Validate(backupPath); err != nil {
+		return "", err
+	}
+
+	databasePath := databaseConfig.Name
+	databaseLock, err := acquireDatabaseLock(databasePath, true)
+	if err != nil {
+		return "", err
+	}
+	defer databaseLock.Release()
+
+	stagingPath := databasePath + config.BackupPartialSuffix
+	if err := copyFile(backupPath, stagingPath); err != nil {
+		_ = os.Remove(stagingPath)
+		return "", err
+	}
+
+	var safetyCopyPath string
+	if _, err := os.Stat(databasePath); err == nil {
+		if err := checkpointDatabase(databasePath); err != nil {
+			_ = os.Remove(stagingPath)
+			return "", err
+		}
+		safetyCopyPath = databasePath + ".pre-restore-" + time.Now().UTC().Format(config.BackupTimestampLayout)
+		if err := copyFile(databasePath, safetyCopyPath); err != nil {
+			_ = os.Remove(stagingPath)
+			return "", fmt.Errorf("failed to keep a copy of the current database: %w", err)
+		}
+	}
+	// Stale journal files belong to the database being replaced and must not be applied to the restored one.
+	for _, journalSuffix := range []string{"-wal", "-shm", "-journal"} {
+		_ = os.Remove(databasePath + journalSuffix)
+	}
+	if err := os.Rename(stagingPath, databasePath); err != nil {
+		_ = os.Remove(stagingPath)
+		return safetyCopyPath, err
+	}
+	return safetyCopyPath, nil
+}
+
+// checkpointDatabase writes everything in the write-ahead log of the database at databasePath back into the
+// database file and empties the log, so a copy of the file alone holds every committed change.
+func checkpointDatabase(databasePath string) error {
+	databaseConnection, err := gorm.Open(sqlite.Open(databasePath), &gorm.Config{
+		Logger: gormlogger.Default.LogMode(gormlogger.Silent),
+	})
+	if err != nil {
+		return err
+	}
+	sqlDatabase, err := databaseConnection.DB()
+	if err != nil {
+		return err
+	}
+	defer sqlDatabase.Close()
+
+	// The pragma answers whether another connection kept it from finishing, then the log and checkpointed frames.
+	var checkpointBusy, logFrames, checkpointedFrames int
+	if err := databaseConnection.Raw("PRAGMA wal_checkpoint(TRUNCATE)").Row().Scan(&checkpointBusy, &logFrames, &checkpointedFrames); err != nil {
+		return fmt.Errorf("failed to checkpoint the current database: %w", err)
+	}
+	if checkpointBusy != 0 {
+		return ErrDatabaseInUse
+	}
+	return nil
+}
+
+// copyFile copies sourcePath to destinationPath and syncs it to disk.
+func copyFile(sourcePath string, destinationPath string) error {
+	sourceFile, err := os.Open(sourcePath)
+	if err != nil {
+		return err
+	}
+	defer sourceFile.Close()
+	destinationFile, err := os.OpenFile(destinationPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o640)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(destinationFile, sourceFile); err != nil {
+		_ = destinationFile.Close()
+		return err
+	}
+	if err := destinationFile.Sync(); err != nil {
+		_ = destinationFile.Close()
+		return err
+	}
+	return destinationFile.Close()
+}
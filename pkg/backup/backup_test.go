@@ -0,0 +1,104 @@
+package backup
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/temirov/RSVP/models"
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/migrations"
+	"github.com/temirov/RSVP/pkg/testdb"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// openMigratedFile opens the SQLite database file at databasePath in write-ahead log mode with every migration
+// applied, closing it when the test ends.
+func openMigratedFile(t *testing.T, databasePath string) *gorm.DB {
+	t.Helper()
+	databaseConnection, err := gorm.Open(sqlite.Open(databasePath), &gorm.Config{Logger: gormlogger.Discard})
+	if err != nil {
+		t.Fatalf("opening %s: %v", databasePath, err)
+	}
+	sqlDatabase, err := databaseConnection.DB()
+	if err != nil {
+		t.Fatalf("reaching %s: %v", databasePath, err)
+	}
+	t.Cleanup(func() { _ = sqlDatabase.Close() })
+	if err := databaseConnection.Exec("PRAGMA journal_mode=WAL").Error; err != nil {
+		t.Fatalf("switching %s to WAL: %v", databasePath, err)
+	}
+	if _, err := migrations.Apply(databaseConnection, 0, testdb.Logger()); err != nil {
+		t.Fatalf("migrating %s: %v", databasePath, err)
+	}
+	return databaseConnection
+}
+
+// newRestoreFixture returns the configuration of a migrated database and the path of a valid backup to restore.
+func newRestoreFixture(t *testing.T) (config.DatabaseConfig, string) {
+	t.Helper()
+	temporaryDirectory := t.TempDir()
+	databaseConfig := config.DatabaseConfig{Driver: config.DatabaseDriverSQLite, Name: filepath.Join(temporaryDirectory, "rsvps.db")}
+	backupPath := filepath.Join(temporaryDirectory, "backup.db")
+	backupConnection := openMigratedFile(t, backupPath)
+	if sqlDatabase, err := backupConnection.DB(); err == nil {
+		_ = sqlDatabase.Close()
+	}
+	return databaseConfig, backupPath
+}
+
+func TestRestoreRefusesWhileServerHoldsDatabase(t *testing.T) {
+	databaseConfig, backupPath := newRestoreFixture(t)
+	openMigratedFile(t, databaseConfig.Name)
+
+	serverLock, err := HoldDatabase(databaseConfig)
+	if err != nil {
+		t.Fatalf("HoldDatabase: %v", err)
+	}
+	if _, err := Restore(databaseConfig, backupPath); !errors.Is(err, ErrDatabaseInUse) {
+		t.Fatalf("Restore with the server running = %v, want ErrDatabaseInUse", err)
+	}
+	if err := serverLock.Release(); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+	if _, err := Restore(databaseConfig, backupPath); err != nil {
+		t.Fatalf("Restore after the server stopped: %v", err)
+	}
+	restartedLock, err := HoldDatabase(databaseConfig)
+	if err != nil {
+		t.Fatalf("HoldDatabase after the restore: %v", err)
+	}
+	_ = restartedLock.Release()
+}
+
+func TestRestoreSafetyCopyHoldsWriteAheadLog(t *testing.T) {
+	databaseConfig, backupPath := newRestoreFixture(t)
+	currentConnection := openMigratedFile(t, databaseConfig.Name)
+	if err := currentConnection.Exec("PRAGMA wal_autocheckpoint=0").Error; err != nil {
+		t.Fatalf("disabling automatic checkpoints: %v", err)
+	}
+	if err := currentConnection.Create(&models.User{Email: "kept@example.com"}).Error; err != nil {
+		t.Fatalf("creating user: %v", err)
+	}
+
+	safetyCopyPath, err := Restore(databaseConfig, backupPath)
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	safetyConnection, err := gorm.Open(sqlite.Open("file:"+safetyCopyPath+"?mode=ro"), &gorm.Config{Logger: gormlogger.Discard})
+	if err != nil {
+		t.Fatalf("opening the safety copy: %v", err)
+	}
+	if sqlDatabase, err := safetyConnection.DB(); err == nil {
+		defer sqlDatabase.Close()
+	}
+	var keptUsers int64
+	if err := safetyConnection.Model(&models.User{}).Where("email = ?", "kept@example.com").Count(&keptUsers).Error; err != nil {
+		t.Fatalf("counting users in the safety copy: %v", err)
+	}
+	if keptUsers != 1 {
+		t.Fatalf("safety copy holds %d users written before the restore, want 1", keptUsers)
+	}
+}
@@ -0,0 +1,59 @@
+package backup
+
+import (
+	"errors"
+	"os"
+
+	"github.com/temirov/RSVP/pkg/config"
+)
+
+// ErrDatabaseInUse is returned by Restore while a running server has the database open, and by HoldDatabase while
+// a restore is replacing it.
+var ErrDatabaseInUse = errors.New("the database is in use; stop the server before restoring")
+
+// DatabaseLock is held on the lock file next to a SQLite database for as long as it is in use.
+type DatabaseLock struct {
+	lockFile *os.File
+}
+
+// lockFilePath returns the path of the lock file guarding the database at databasePath. A separate file is locked
+// because SQLite manages the locks on the database file itself.
+func lockFilePath(databasePath string) string {
+	return databasePath + ".lock"
+}
+
+// openLockFile opens, creating it if needed, the lock file guarding the database at databasePath.
+func openLockFile(databasePath string) (*os.File, error) {
+	return os.OpenFile(lockFilePath(databasePath), os.O_CREATE|os.O_RDWR, 0o640)
+}
+
+// HoldDatabase takes a shared lock on the SQLite database for the life of the server. Several servers may hold it
+// at once; Restore needs it exclusively, so a restore and a running server never overlap. It returns nil for
+// databases that cannot be restored from a backup.
+func HoldDatabase(databaseConfig config.DatabaseConfig) (*DatabaseLock, error) {
+	if !Supported(databaseConfig) {
+		return nil, nil
+	}
+	return acquireDatabaseLock(databaseConfig.Name, false)
+}
+
+// acquireDatabaseLock locks the lock file of the database at databasePath, shared or exclusively, without waiting.
+func acquireDatabaseLock(databasePath string, exclusive bool) (*DatabaseLock, error) {
+	lockFile, err := openLockFile(databasePath)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFileRange(lockFile, exclusive); err != nil {
+		_ = lockFile.Close()
+		return nil, err
+	}
+	return &DatabaseLock{lockFile: lockFile}, nil
+}
+
+// Release gives the lock up.
+func (databaseLock *DatabaseLock) Release() error {
+	if databaseLock == nil {
+		return nil
+	}
+	return databaseLock.lockFile.Close()
+}
@@ -0,0 +1,11 @@
+//go:build !unix
+
+package backup
+
+import "os"
+
+// lockFileRange does nothing where advisory file locks are unavailable; stopping the server before a restore is
+// then left to the operator.
+func lockFileRange(lockFile *os.File, exclusive bool) error {
+	return nil
+}
@@ -0,0 +1,22 @@
+//go:build unix
+
+package backup
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// lockFileRange places an advisory lock on lockFile, returning ErrDatabaseInUse when a conflicting lock is held.
+func lockFileRange(lockFile *os.File, exclusive bool) error {
+	lockMode := syscall.LOCK_SH
+	if exclusive {
+		lockMode = syscall.LOCK_EX
+	}
+	err := syscall.Flock(int(lockFile.Fd()), lockMode|syscall.LOCK_NB)
+	if errors.Is(err, syscall.EWOULDBLOCK) {
+		return ErrDatabaseInUse
+	}
+	return err
+}
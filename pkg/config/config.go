@@ -2,31 +2,279 @@
 package config
 
 import (
+	"io/fs"
 	"log"
+	"log/slog"
+	"net"
+	"net/mail"
+	"net/netip"
+	"net/url"
 	"os"
-	"strings" // Import strings package
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/temirov/RSVP/pkg/invitation"
+	"github.com/temirov/RSVP/pkg/metrics"
+	"github.com/temirov/RSVP/pkg/realtime"
 	"gorm.io/gorm"
 )
 
 // DatabaseConfig holds database configuration.
 type DatabaseConfig struct {
-	// Name specifies the filename or connection string for the database.
+	// Driver selects the database backend: DatabaseDriverSQLite, DatabaseDriverPostgres or DatabaseDriverMySQL.
+	Driver string
+	// Name specifies the SQLite database filename. It is used only by the SQLite driver when DSN is empty.
 	Name string
+	// DSN is the driver-specific connection string. It is required for PostgreSQL and MySQL.
+	DSN string
+	// AutoMigrate applies pending schema migrations at startup. When disabled, the server refuses
+	// to start until migrations have been applied with the migrate command.
+	AutoMigrate bool
+}
+
+// TrashConfig holds settings for soft-deleted records.
+type TrashConfig struct {
+	// Retention is how long deleted events, venues and RSVPs stay restorable before they are purged; zero keeps them forever.
+	Retention time.Duration
+}
+
+// BackupConfig holds settings for database backups.
+type BackupConfig struct {
+	// Directory is where backup files are written; it defaults to a "backups" folder next to the SQLite file.
+	Directory string
+	// Interval between scheduled backups taken by the server; zero disables the schedule.
+	Interval time.Duration
+	// Retention is the number of most recent backups to keep; zero keeps all of them.
+	Retention int
+}
+
+// ServerConfig holds the address the server listens on and its timeouts.
+type ServerConfig struct {
+	// Address is the host name or IP address to listen on; "0.0.0.0" listens on every interface.
+	Address string
+	Port    int
+	// ReadHeaderTimeout bounds how long a client may take to send its request headers.
+	ReadHeaderTimeout time.Duration
+	// IdleTimeout is how long an idle keep-alive connection stays open.
+	IdleTimeout time.Duration
+	// ShutdownTimeout is how long open requests get to finish once the listener has closed.
+	ShutdownTimeout time.Duration
+	// TrustedProxies are the reverse proxies whose X-Forwarded-For header names the client. Requests from anywhere
+	// else are attributed to their connection address, whatever the header says.
+	TrustedProxies []netip.Prefix
+}
+
+// ListenAddress returns the address in the host:port form taken by http.Server.
+func (serverConfig ServerConfig) ListenAddress() string {
+	return net.JoinHostPort(serverConfig.Address, strconv.Itoa(serverConfig.Port))
+}
+
+// BrandingConfig holds the names the application shows to its users.
+type BrandingConfig struct {
+	// AppTitle names the application in page titles, headers and sign-in emails.
+	AppTitle string
+	// AssetsOverrideDirectory holds templates and static files that replace the ones compiled into the binary;
+	// empty uses only the compiled-in files.
+	AssetsOverrideDirectory string
+}
+
+// AuthConfig selects the sign-in providers and holds the settings of the ones that are not Google.
+type AuthConfig struct {
+	// Providers lists the enabled providers in the order their buttons appear on the sign-in page.
+	Providers []string
+	// OIDC configures the generic OpenID Connect provider.
+	OIDC OIDCConfig
+	// Email configures sign-in with one-time links sent by email.
+	Email EmailAuthConfig
+	// DevUsers are the test identities offered on the sign-in page in development mode.
+	DevUsers []*mail.Address
+}
+
+// RateLimitConfig limits requests to the public invitation pages. A zero value disables the limit it sets.
+type RateLimitConfig struct {
+	// PerClientPerMinute is how many requests one client address may make a minute.
+	PerClientPerMinute int
+	// GlobalPerMinute is how many requests all clients together may make a minute.
+	GlobalPerMinute int
+	// FailureThreshold is how many unknown invitation codes a client may ask for before it is locked out.
+	FailureThreshold int
+}
+
+// InvitationConfig controls the signed tokens in guest invitation links.
+type InvitationConfig struct {
+	// SigningKey is the secret the tokens are signed with. It defaults to SESSION_SECRET.
+	SigningKey string
+	// ExpireAfterEvent is how long after its event ends a link stops working; zero keeps links working forever.
+	ExpireAfterEvent time.Duration
+}
+
+// SecurityHeadersConfig controls the security headers sent with every response.
+type SecurityHeadersConfig struct {
+	// HTTPS is set when the server terminates TLS itself or APP_BASE_URL uses https.
+	HTTPS bool
+	// HSTSMaxAge is the max-age of Strict-Transport-Security, sent only over HTTPS; zero disables the header.
+	HSTSMaxAge time.Duration
+	// FrameAncestors lists the pages allowed to frame this application, as CSP sources; 'none' forbids framing.
+	FrameAncestors []string
+	// ExtraSources adds sources to Content Security Policy directives, keyed by directive name.
+	ExtraSources map[string][]string
+	// ReportOnly sends the policy as Content-Security-Policy-Report-Only, so violations are reported, not blocked.
+	ReportOnly bool
+	// ReportURI is where browsers report policy violations; empty disables reports.
+	ReportURI string
+	// AllowInlineStyles replaces the nonce in style-src with 'unsafe-inline', so style attributes are applied.
+	AllowInlineStyles bool
+	// ReferrerPolicy is the Referrer-Policy header value.
+	ReferrerPolicy string
+}
+
+// LoggingConfig selects the format and minimum level of the application's structured logs.
+type LoggingConfig struct {
+	// Format is LogFormatText or LogFormatJSON.
+	Format string
+	// Level is the least severe level written.
+	Level slog.Level
+}
+
+// MetricsConfig controls the Prometheus metrics endpoint, which is disabled unless Address or Token is set.
+type MetricsConfig struct {
+	// Address is a separate listen address, such as "127.0.0.1:9090", serving only the metrics. When it is empty
+	// the metrics are served on the main listener.
+	Address string
+	// Token is the bearer token scrapers must send. It is required when the metrics share the main listener.
+	Token string
+}
+
+// Enabled reports whether the metrics endpoint is served.
+func (metricsConfig MetricsConfig) Enabled() bool {
+	return metricsConfig.Address != "" || metricsConfig.Token != ""
+}
+
+// TracingConfig controls OpenTelemetry tracing, which is disabled unless Exporter is set.
+type TracingConfig struct {
+	// Exporter is TracingExporterOTLP, TracingExporterStdout or empty for no tracing.
+	Exporter string
+	// ServiceName names this installation in the traces.
+	ServiceName string
+	// SampleRatio is the fraction of new traces recorded, from 0 to 1. Requests continuing a sampled trace are
+	// always recorded.
+	SampleRatio float64
+}
+
+// Enabled reports whether spans are recorded and exported.
+func (tracingConfig TracingConfig) Enabled() bool {
+	return tracingConfig.Exporter != ""
+}
+
+// AccessConfig restricts who may sign in. Without any rule every address may; administrators always may.
+type AccessConfig struct {
+	// AllowedDomains lists the lower-cased email domains whose addresses may sign in.
+	AllowedDomains []string
+	// AllowedEmails lists lower-cased addresses that may sign in whatever their domain.
+	AllowedEmails []string
+	// InviteOnly admits addresses that already have an account or a pending co-host or organization invitation.
+	InviteOnly bool
+}
+
+// IsRestricted reports whether any sign-in rule is configured.
+func (accessConfig AccessConfig) IsRestricted() bool {
+	return len(accessConfig.AllowedDomains) > 0 || len(accessConfig.AllowedEmails) > 0 || accessConfig.InviteOnly
+}
+
+// IsListed reports whether the lower-cased address is on the allowlist or in one of the allowed domains.
+func (accessConfig AccessConfig) IsListed(emailAddress string) bool {
+	for _, allowedEmail := range accessConfig.AllowedEmails {
+		if emailAddress == allowedEmail {
+			return true
+		}
+	}
+	atIndex := strings.LastIndex(emailAddress, "@")
+	if atIndex < 0 {
+		return false
+	}
+	for _, allowedDomain := range accessConfig.AllowedDomains {
+		if emailAddress[atIndex+1:] == allowedDomain {
+			return true
+		}
+	}
+	return false
+}
+
+// OIDCConfig holds the settings of an OpenID Connect identity provider found through issuer discovery.
+type OIDCConfig struct {
+	// IssuerURL is the issuer identifier; its /.well-known/openid-configuration document lists the endpoints.
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	// Label names the provider on the sign-in button.
+	Label  string
+	Scopes []string
+}
+
+// EmailAuthConfig holds the settings of sign-in links sent by email.
+type EmailAuthConfig struct {
+	// LinkLifetime is how long a sign-in link stays valid.
+	LinkLifetime time.Duration
+	// LinksPerClient is how many links one client address may request an hour; zero disables the limit.
+	LinksPerClient int
+	// LinksPerRecipient is how many links are sent to one email address an hour; zero disables the limit.
+	LinksPerRecipient int
+	SMTP              SMTPConfig
+}
+
+// SMTPConfig holds the mail server used to deliver sign-in links.
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	// From is the sender address of outgoing mail.
+	From string
+}
+
+// IsEnabled reports whether the named provider is among the enabled ones.
+func (authConfig AuthConfig) IsEnabled(providerName string) bool {
+	for _, enabledProvider := range authConfig.Providers {
+		if enabledProvider == providerName {
+			return true
+		}
+	}
+	return false
 }
 
 // ApplicationContext holds shared dependencies accessible across handlers.
 type ApplicationContext struct {
 	// Database is the active GORM database connection instance.
 	Database *gorm.DB
-	// Logger is the application-wide logger instance.
-	Logger *log.Logger
+	// Logger is the application-wide structured logger. Code serving a request should prefer the request's logger
+	// from logging.FromContext, which is tagged with the request ID and user.
+	Logger *slog.Logger
 	// AppBaseURL is the public base URL of the application, including trailing slash.
 	AppBaseURL string // Added to centralize access
+	// AppTitle names the application on every page and in emails.
+	AppTitle string
+	// Realtime fans out live RSVP updates to organizers' open pages.
+	Realtime *realtime.Broker
+	// DevAuth is set when the development sign-in is enabled, so that every page can say so.
+	DevAuth bool
+	// Assets holds the templates and static files: the compiled-in ones, overlaid by ASSETS_OVERRIDE_DIR if set.
+	Assets fs.FS
+	// Invitations signs and verifies the tokens in guest invitation links.
+	Invitations *invitation.Signer
+	// Metrics collects the Prometheus metrics; it is nil, and records nothing, when the endpoint is disabled.
+	Metrics *metrics.Metrics
 }
 
-// EnvConfig holds configuration values sourced from environment variables.
+// EnvConfig holds the server configuration, resolved from command-line flags, environment variables, the
+// configuration file and the defaults by Settings.EnvConfig.
 type EnvConfig struct {
+	// Server holds the listen address, port and timeouts.
+	Server ServerConfig
+	// Branding holds the application's name.
+	Branding BrandingConfig
 	// SessionSecret is the secret key used for securing user sessions.
 	SessionSecret string
 	// GoogleClientID is the Client ID obtained from Google Cloud Console for OAuth.
@@ -43,51 +291,508 @@ type EnvConfig struct {
 	AppBaseURL string
 	// Database contains database-specific configuration.
 	Database DatabaseConfig
+	// Backup contains backup directory, schedule and retention settings.
+	Backup BackupConfig
+	// Trash contains the retention period of deleted records.
+	Trash TrashConfig
+	// AdminEmails lists the (lower-cased) email addresses allowed to use administrative endpoints.
+	AdminEmails []string
+	// Auth selects the sign-in providers and holds their settings.
+	Auth AuthConfig
+	// Access restricts which email addresses may sign in.
+	Access AccessConfig
+	// RateLimit throttles the public invitation pages.
+	RateLimit RateLimitConfig
+	// Invitation signs guest invitation links.
+	Invitation InvitationConfig
+	// SecurityHeaders controls HSTS, the Content Security Policy and the other security headers.
+	SecurityHeaders SecurityHeadersConfig
+	// Metrics controls where the Prometheus metrics are served and who may read them.
+	Metrics MetricsConfig
+	// Tracing selects where OpenTelemetry spans are exported.
+	Tracing TracingConfig
+	// ShutdownDrainDelay is how long /readyz reports failure after a shutdown signal before the server stops accepting
+	// connections, so that load balancers can take it out of rotation first.
+	ShutdownDrainDelay time.Duration
 }
 
-// NewEnvConfig creates a new EnvConfig instance, populating it with values
-// from environment variables and applying default settings where necessary.
-// It ensures required environment variables are set, logging a fatal error if not.
-func NewEnvConfig(applicationLogger *log.Logger) *EnvConfig {
-	databaseName := DefaultDBName
-	if envDatabaseName := os.Getenv("DB_NAME"); envDatabaseName != "" {
-		databaseName = envDatabaseName
-	}
-
+// EnvConfig builds the server configuration from the settings. Missing and invalid values are recorded as problems,
+// reported by Err, and replaced by their defaults so that every problem can be found in one pass.
+func (settings *Settings) EnvConfig() *EnvConfig {
 	// Ensure APP_BASE_URL ends with a slash if set
-	appBaseURL := os.Getenv("APP_BASE_URL")
+	appBaseURL := settings.lookup("APP_BASE_URL")
 	if appBaseURL != "" && !strings.HasSuffix(appBaseURL, "/") {
 		appBaseURL += "/"
 	}
 
 	envConfigData := &EnvConfig{
-		SessionSecret:       os.Getenv("SESSION_SECRET"),
-		GoogleClientID:      os.Getenv("GOOGLE_CLIENT_ID"),
-		GoogleClientSecret:  os.Getenv("GOOGLE_CLIENT_SECRET"),
-		GoogleOauth2Base:    os.Getenv("GOOGLE_OAUTH2_BASE"),
-		CertificateFilePath: os.Getenv("TLS_CERT_PATH"),
-		KeyFilePath:         os.Getenv("TLS_KEY_PATH"),
+		Server:              settings.ServerConfig(),
+		Branding:            BrandingConfig{AppTitle: settings.lookup("APP_TITLE"), AssetsOverrideDirectory: settings.lookup("ASSETS_OVERRIDE_DIR")},
+		SessionSecret:       settings.lookup("SESSION_SECRET"),
+		GoogleClientID:      settings.lookup("GOOGLE_CLIENT_ID"),
+		GoogleClientSecret:  settings.lookup("GOOGLE_CLIENT_SECRET"),
+		GoogleOauth2Base:    settings.lookup("GOOGLE_OAUTH2_BASE"),
+		CertificateFilePath: settings.lookup("TLS_CERT_PATH"),
+		KeyFilePath:         settings.lookup("TLS_KEY_PATH"),
 		AppBaseURL:          appBaseURL, // Use the processed base URL
-		Database: DatabaseConfig{
-			Name: databaseName,
+		Database:            settings.DatabaseConfig(),
+		Trash:               settings.TrashConfig(),
+		AdminEmails:         splitEmailList(settings.lookup("ADMIN_EMAILS")),
+		Auth:                settings.AuthConfig(),
+		Access:              settings.AccessConfig(),
+		RateLimit:           settings.RateLimitConfig(),
+		Invitation:          settings.InvitationConfig(),
+		SecurityHeaders:     settings.SecurityHeadersConfig(),
+		Metrics:             MetricsConfig{Address: settings.lookup("METRICS_ADDRESS"), Token: settings.lookup("METRICS_TOKEN")},
+		Tracing:             settings.TracingConfig(),
+		ShutdownDrainDelay:  settings.duration("SHUTDOWN_DRAIN_DELAY", DefaultShutdownDrain, "a duration such as 5s, or 0 to stop at once"),
+	}
+	if envConfigData.Branding.AppTitle = strings.TrimSpace(envConfigData.Branding.AppTitle); envConfigData.Branding.AppTitle == "" {
+		settings.invalidf("%s must not be blank", settingLabel("APP_TITLE"))
+		envConfigData.Branding.AppTitle = DefaultAppTitle
+	}
+	if overrideDirectory := envConfigData.Branding.AssetsOverrideDirectory; overrideDirectory != "" {
+		if directoryInfo, statError := os.Stat(overrideDirectory); statError != nil || !directoryInfo.IsDir() {
+			settings.invalidf("Invalid %s value %q (expected an existing directory)", settingLabel("ASSETS_OVERRIDE_DIR"), overrideDirectory)
+		}
+	}
+	if appBaseURL != "" {
+		if parsedBaseURL, parseError := url.Parse(appBaseURL); parseError != nil || parsedBaseURL.Host == "" ||
+			(parsedBaseURL.Scheme != "http" && parsedBaseURL.Scheme != "https") {
+			settings.invalidf("Invalid %s value %q (expected an absolute http or https URL such as https://rsvp.example.com/)", settingLabel("APP_BASE_URL"), appBaseURL)
+		}
+	}
+	if (envConfigData.CertificateFilePath == "") != (envConfigData.KeyFilePath == "") {
+		settings.invalidf("%s and %s must be set together", settingLabel("TLS_CERT_PATH"), settingLabel("TLS_KEY_PATH"))
+	}
+	envConfigData.SecurityHeaders.HTTPS = (envConfigData.CertificateFilePath != "" && envConfigData.KeyFilePath != "") ||
+		strings.HasPrefix(strings.ToLower(envConfigData.AppBaseURL), "https://")
+	if envConfigData.Invitation.SigningKey == "" {
+		envConfigData.Invitation.SigningKey = envConfigData.SessionSecret
+	}
+	envConfigData.Backup = settings.BackupConfig(envConfigData.Database)
+
+	// Define required settings and their corresponding values from the config struct.
+	requiredSettings := map[string]string{
+		"SESSION_SECRET": envConfigData.SessionSecret,
+		"APP_BASE_URL":   envConfigData.AppBaseURL, // Make AppBaseURL required
+	}
+	// The development sign-in lets anyone in as anyone, so it only runs where nobody else can reach it: the server
+	// listens on the loopback interface, and links point there too. Without a SERVER_ADDRESS of its own it binds to
+	// 127.0.0.1 instead of every interface.
+	if envConfigData.Auth.IsEnabled(AuthProviderDev) {
+		if envConfigData.AppBaseURL != "" && !isLocalhostURL(envConfigData.AppBaseURL) {
+			settings.invalidf("AUTH_PROVIDERS=%s requires APP_BASE_URL to point at localhost, got %q", AuthProviderDev, envConfigData.AppBaseURL)
+		}
+		if settings.values["SERVER_ADDRESS"].origin == SettingOriginDefault {
+			envConfigData.Server.Address = DevServerAddress
+		} else if !isLoopbackHost(envConfigData.Server.Address) {
+			settings.invalidf("AUTH_PROVIDERS=%s requires %s to be a loopback address such as %s, got %q",
+				AuthProviderDev, settingLabel("SERVER_ADDRESS"), DevServerAddress, envConfigData.Server.Address)
+		}
+	}
+	// Google credentials are needed only when Google sign-in is enabled.
+	if envConfigData.Auth.IsEnabled(AuthProviderGoogle) {
+		requiredSettings["GOOGLE_CLIENT_ID"] = envConfigData.GoogleClientID
+		requiredSettings["GOOGLE_CLIENT_SECRET"] = envConfigData.GoogleClientSecret
+		requiredSettings["GOOGLE_OAUTH2_BASE"] = envConfigData.GoogleOauth2Base
+	}
+	settings.requireAll(requiredSettings, "")
+	return envConfigData
+}
+
+// ServerConfig reads the listener settings: SERVER_ADDRESS, SERVER_PORT, SERVER_READ_HEADER_TIMEOUT,
+// SERVER_IDLE_TIMEOUT, SHUTDOWN_TIMEOUT and TRUSTED_PROXIES.
+func (settings *Settings) ServerConfig() ServerConfig {
+	serverConfig := ServerConfig{
+		Address:           settings.lookup("SERVER_ADDRESS"),
+		Port:              settings.port("SERVER_PORT", DefaultServerPort),
+		ReadHeaderTimeout: settings.duration("SERVER_READ_HEADER_TIMEOUT", DefaultReadHeaderTimeout, "a positive duration such as 10s"),
+		IdleTimeout:       settings.duration("SERVER_IDLE_TIMEOUT", DefaultIdleTimeout, "a duration such as 120s"),
+		ShutdownTimeout:   settings.duration("SHUTDOWN_TIMEOUT", DefaultShutdownTimeout, "a positive duration such as 10s"),
+	}
+	for _, trustedProxy := range strings.Split(settings.lookup("TRUSTED_PROXIES"), ",") {
+		if trustedProxy = strings.TrimSpace(trustedProxy); trustedProxy == "" {
+			continue
+		}
+		proxyPrefix, parseError := netip.ParsePrefix(trustedProxy)
+		if parseError != nil {
+			proxyAddress, addressError := netip.ParseAddr(trustedProxy)
+			if addressError != nil {
+				settings.invalidf("Invalid %s entry %q (expected an IP address or CIDR range such as 10.0.0.0/8)", settingLabel("TRUSTED_PROXIES"), trustedProxy)
+				continue
+			}
+			proxyAddress = proxyAddress.Unmap()
+			proxyPrefix = netip.PrefixFrom(proxyAddress, proxyAddress.BitLen())
+		}
+		serverConfig.TrustedProxies = append(serverConfig.TrustedProxies, proxyPrefix.Masked())
+	}
+	if serverConfig.Address != "" && net.ParseIP(serverConfig.Address) == nil && strings.ContainsAny(serverConfig.Address, ":/ ") {
+		settings.invalidf("Invalid %s value %q (expected a host name or IP address without a port)", settingLabel("SERVER_ADDRESS"), serverConfig.Address)
+	}
+	if serverConfig.ReadHeaderTimeout == 0 {
+		settings.invalidf("Invalid %s value %q (expected a positive duration such as 10s)", settingLabel("SERVER_READ_HEADER_TIMEOUT"), settings.lookup("SERVER_READ_HEADER_TIMEOUT"))
+	}
+	if serverConfig.ShutdownTimeout == 0 {
+		settings.invalidf("Invalid %s value %q (expected a positive duration such as 10s)", settingLabel("SHUTDOWN_TIMEOUT"), settings.lookup("SHUTDOWN_TIMEOUT"))
+	}
+	return serverConfig
+}
+
+// AuthConfig reads the sign-in settings: AUTH_PROVIDERS, a comma-separated list of
+// google, oidc and email (default google), or dev alone with the test identities in DEV_AUTH_USERS; OIDC_ISSUER_URL, OIDC_CLIENT_ID, OIDC_CLIENT_SECRET, OIDC_LABEL and
+// OIDC_SCOPES for OpenID Connect; and LOGIN_LINK_LIFETIME, LOGIN_LINK_LIMIT_PER_CLIENT, LOGIN_LINK_LIMIT_PER_RECIPIENT and SMTP_HOST, SMTP_PORT, SMTP_USERNAME, SMTP_PASSWORD
+// and SMTP_FROM for email sign-in links. Settings of a provider are required only when it is enabled.
+func (settings *Settings) AuthConfig() AuthConfig {
+	authConfig := AuthConfig{
+		OIDC: OIDCConfig{
+			IssuerURL:    strings.TrimSuffix(settings.lookup("OIDC_ISSUER_URL"), "/"),
+			ClientID:     settings.lookup("OIDC_CLIENT_ID"),
+			ClientSecret: settings.lookup("OIDC_CLIENT_SECRET"),
+			Label:        settings.lookup("OIDC_LABEL"),
+			Scopes:       strings.Fields(strings.ReplaceAll(settings.lookup("OIDC_SCOPES"), ",", " ")),
+		},
+		Email: EmailAuthConfig{
+			LinkLifetime:      settings.duration("LOGIN_LINK_LIFETIME", DefaultLoginLinkLifetime, "a positive duration such as 15m"),
+			LinksPerClient:    settings.count("LOGIN_LINK_LIMIT_PER_CLIENT", DefaultLoginLinkLimitPerClient, "a non-negative number, or 0 to disable"),
+			LinksPerRecipient: settings.count("LOGIN_LINK_LIMIT_PER_RECIPIENT", DefaultLoginLinkLimitPerRecipient, "a non-negative number, or 0 to disable"),
+			SMTP: SMTPConfig{
+				Host:     settings.lookup("SMTP_HOST"),
+				Port:     settings.port("SMTP_PORT", DefaultSMTPPort),
+				Username: settings.lookup("SMTP_USERNAME"),
+				Password: settings.lookup("SMTP_PASSWORD"),
+				From:     settings.lookup("SMTP_FROM"),
+			},
 		},
 	}
+	if authConfig.Email.LinkLifetime == 0 {
+		settings.invalidf("Invalid %s value %q (expected a positive duration such as 15m)", settingLabel("LOGIN_LINK_LIFETIME"), settings.lookup("LOGIN_LINK_LIFETIME"))
+		authConfig.Email.LinkLifetime = DefaultLoginLinkLifetime
+	}
+	for _, providerName := range strings.Split(settings.lookup("AUTH_PROVIDERS"), ",") {
+		providerName = strings.ToLower(strings.TrimSpace(providerName))
+		switch providerName {
+		case "":
+			continue
+		case AuthProviderGoogle, AuthProviderOIDC, AuthProviderEmail, AuthProviderDev:
+			if !authConfig.IsEnabled(providerName) {
+				authConfig.Providers = append(authConfig.Providers, providerName)
+			}
+		default:
+			settings.invalidf("Unsupported authentication provider %q in %s (expected %s, %s or %s)",
+				providerName, settingLabel("AUTH_PROVIDERS"), AuthProviderGoogle, AuthProviderOIDC, AuthProviderEmail)
+		}
+	}
+	if len(authConfig.Providers) == 0 {
+		settings.invalidf("%s must enable at least one of %s, %s or %s", settingLabel("AUTH_PROVIDERS"), AuthProviderGoogle, AuthProviderOIDC, AuthProviderEmail)
+	}
+	if authConfig.IsEnabled(AuthProviderDev) {
+		if len(authConfig.Providers) > 1 {
+			settings.invalidf("AUTH_PROVIDERS=%s cannot be combined with other providers", AuthProviderDev)
+		}
+		envDevUsers := settings.lookup("DEV_AUTH_USERS")
+		devUsers, parseError := mail.ParseAddressList(envDevUsers)
+		if parseError != nil {
+			settings.invalidf("Invalid %s value %q (expected addresses such as \"Alice <alice@example.com>, bob@example.com\"): %v", settingLabel("DEV_AUTH_USERS"), envDevUsers, parseError)
+		}
+		authConfig.DevUsers = devUsers
+	}
 
-	// Define required environment variables and their corresponding values from the config struct.
-	requiredEnvVars := map[string]string{
-		"SESSION_SECRET":       envConfigData.SessionSecret,
-		"GOOGLE_CLIENT_ID":     envConfigData.GoogleClientID,
-		"GOOGLE_CLIENT_SECRET": envConfigData.GoogleClientSecret,
-		"GOOGLE_OAUTH2_BASE":   envConfigData.GoogleOauth2Base,
-		"APP_BASE_URL":         envConfigData.AppBaseURL, // Make AppBaseURL required
+	if authConfig.IsEnabled(AuthProviderOIDC) {
+		settings.requireAll(map[string]string{
+			"OIDC_ISSUER_URL":    authConfig.OIDC.IssuerURL,
+			"OIDC_CLIENT_ID":     authConfig.OIDC.ClientID,
+			"OIDC_CLIENT_SECRET": authConfig.OIDC.ClientSecret,
+		}, AuthProviderOIDC)
+		// ID tokens carry the email address users are matched by, so the issuer and its keys must come over TLS.
+		if issuerURL := authConfig.OIDC.IssuerURL; issuerURL != "" && !strings.HasPrefix(strings.ToLower(issuerURL), "https://") {
+			settings.invalidf("Invalid %s value %q (expected an https URL)", settingLabel("OIDC_ISSUER_URL"), issuerURL)
+		}
+	}
+	if authConfig.IsEnabled(AuthProviderEmail) {
+		settings.requireAll(map[string]string{
+			"SMTP_HOST": authConfig.Email.SMTP.Host,
+			"SMTP_FROM": authConfig.Email.SMTP.From,
+		}, AuthProviderEmail)
 	}
+	return authConfig
+}
 
-	// Check if all required environment variables are set.
-	for envVarName, value := range requiredEnvVars {
-		if value == "" {
-			// Log a fatal error and exit if a required variable is missing.
-			applicationLogger.Fatalf("%s environment variable is not set", envVarName)
+// AccessConfig reads the sign-in rules: SIGNIN_ALLOWED_DOMAINS and SIGNIN_ALLOWED_EMAILS, comma-separated lists of
+// domains and addresses, and SIGNIN_INVITE_ONLY.
+func (settings *Settings) AccessConfig() AccessConfig {
+	accessConfig := AccessConfig{
+		AllowedEmails: splitEmailList(settings.lookup("SIGNIN_ALLOWED_EMAILS")),
+		InviteOnly:    settings.boolean("SIGNIN_INVITE_ONLY"),
+	}
+	for _, allowedDomain := range splitEmailList(settings.lookup("SIGNIN_ALLOWED_DOMAINS")) {
+		allowedDomain = strings.TrimPrefix(allowedDomain, "@")
+		if strings.Contains(allowedDomain, "@") {
+			settings.invalidf("Invalid domain %q in %s (expected a domain such as example.com)", allowedDomain, settingLabel("SIGNIN_ALLOWED_DOMAINS"))
+			continue
 		}
+		accessConfig.AllowedDomains = append(accessConfig.AllowedDomains, allowedDomain)
 	}
-	return envConfigData
+	return accessConfig
+}
+
+// RateLimitConfig reads the limits of the public invitation pages: PUBLIC_RATE_LIMIT_PER_CLIENT,
+// PUBLIC_RATE_LIMIT_GLOBAL and PUBLIC_LOOKUP_FAILURE_LIMIT. Zero disables a limit.
+func (settings *Settings) RateLimitConfig() RateLimitConfig {
+	return RateLimitConfig{
+		PerClientPerMinute: settings.count("PUBLIC_RATE_LIMIT_PER_CLIENT", DefaultRateLimitPerClient, "a non-negative number, or 0 to disable"),
+		GlobalPerMinute:    settings.count("PUBLIC_RATE_LIMIT_GLOBAL", DefaultRateLimitGlobal, "a non-negative number, or 0 to disable"),
+		FailureThreshold:   settings.count("PUBLIC_LOOKUP_FAILURE_LIMIT", DefaultLookupFailureLimit, "a non-negative number, or 0 to disable"),
+	}
+}
+
+// LoggingConfig reads LOG_FORMAT ("text" or "json") and LOG_LEVEL ("debug", "info", "warn" or "error").
+func (settings *Settings) LoggingConfig() LoggingConfig {
+	loggingConfig := LoggingConfig{Format: DefaultLogFormat}
+	switch envLogFormat := strings.ToLower(settings.lookup("LOG_FORMAT")); envLogFormat {
+	case LogFormatText, LogFormatJSON:
+		loggingConfig.Format = envLogFormat
+	default:
+		settings.invalidf("Invalid %s value %q (expected %q or %q)", settingLabel("LOG_FORMAT"), envLogFormat, LogFormatText, LogFormatJSON)
+	}
+	envLogLevel := settings.lookup("LOG_LEVEL")
+	if parseError := loggingConfig.Level.UnmarshalText([]byte(envLogLevel)); parseError != nil {
+		settings.invalidf("Invalid %s value %q (expected debug, info, warn or error)", settingLabel("LOG_LEVEL"), envLogLevel)
+		loggingConfig.Level = slog.LevelInfo
+	}
+	return loggingConfig
+}
+
+// TracingConfig reads the tracing settings: TRACING_EXPORTER ("otlp", "stdout", or "none" and empty for no
+// tracing), OTEL_SERVICE_NAME and TRACING_SAMPLE_RATIO. The OTLP exporter itself reads the standard
+// OTEL_EXPORTER_OTLP_* variables, such as OTEL_EXPORTER_OTLP_ENDPOINT.
+func (settings *Settings) TracingConfig() TracingConfig {
+	tracingConfig := TracingConfig{ServiceName: settings.lookup("OTEL_SERVICE_NAME"), SampleRatio: DefaultTracingSampleRatio}
+	switch envExporter := strings.ToLower(settings.lookup("TRACING_EXPORTER")); envExporter {
+	case "", TracingExporterNone:
+	case TracingExporterOTLP, TracingExporterStdout:
+		tracingConfig.Exporter = envExporter
+	default:
+		settings.invalidf("Invalid %s value %q (expected %q, %q or %q)", settingLabel("TRACING_EXPORTER"), envExporter, TracingExporterOTLP, TracingExporterStdout, TracingExporterNone)
+	}
+	envSampleRatio := settings.lookup("TRACING_SAMPLE_RATIO")
+	sampleRatio, parseError := strconv.ParseFloat(envSampleRatio, 64)
+	if parseError != nil || sampleRatio < 0 || sampleRatio > 1 {
+		settings.invalidf("Invalid %s value %q (expected a number from 0 to 1)", settingLabel("TRACING_SAMPLE_RATIO"), envSampleRatio)
+	} else {
+		tracingConfig.SampleRatio = sampleRatio
+	}
+	return tracingConfig
+}
+
+// InvitationConfig reads the invitation link settings: INVITATION_SIGNING_KEY and INVITATION_LINKS_EXPIRE_AFTER, a
+// duration after the event's end. An empty signing key is replaced by SESSION_SECRET in EnvConfig.
+func (settings *Settings) InvitationConfig() InvitationConfig {
+	return InvitationConfig{
+		SigningKey:       settings.lookup("INVITATION_SIGNING_KEY"),
+		ExpireAfterEvent: settings.duration("INVITATION_LINKS_EXPIRE_AFTER", 0, "a duration such as 720h, or 0 for links that never expire"),
+	}
+}
+
+// SecurityHeadersConfig reads the security header settings: HSTS_MAX_AGE, a duration (0 disables HSTS);
+// CSP_FRAME_ANCESTORS, the space-separated sources allowed to frame the pages; CSP_EXTRA_SOURCES, extra sources per
+// directive written like a policy ("script-src https://a.example; img-src https://b.example"); CSP_REPORT_ONLY and
+// CSP_REPORT_URI; CSP_ALLOW_INLINE_STYLES; and REFERRER_POLICY.
+func (settings *Settings) SecurityHeadersConfig() SecurityHeadersConfig {
+	securityHeadersConfig := SecurityHeadersConfig{
+		HSTSMaxAge:        settings.duration("HSTS_MAX_AGE", DefaultHSTSMaxAge, "a duration such as 8760h, or 0 to disable HSTS"),
+		FrameAncestors:    strings.Fields(settings.lookup("CSP_FRAME_ANCESTORS")),
+		ExtraSources:      map[string][]string{},
+		ReportOnly:        settings.boolean("CSP_REPORT_ONLY"),
+		ReportURI:         settings.lookup("CSP_REPORT_URI"),
+		AllowInlineStyles: settings.boolean("CSP_ALLOW_INLINE_STYLES"),
+		ReferrerPolicy:    settings.lookup("REFERRER_POLICY"),
+	}
+	if len(securityHeadersConfig.FrameAncestors) == 0 {
+		securityHeadersConfig.FrameAncestors = []string{CSPFrameAncestorsNone}
+	}
+	for _, directiveText := range strings.Split(settings.lookup("CSP_EXTRA_SOURCES"), ";") {
+		directiveFields := strings.Fields(directiveText)
+		if len(directiveFields) == 0 {
+			continue
+		}
+		if len(directiveFields) == 1 || !strings.HasSuffix(directiveFields[0], "-src") {
+			settings.invalidf("Invalid %s entry %q (expected a source directive followed by sources, such as \"img-src https://images.example.com\")", settingLabel("CSP_EXTRA_SOURCES"), strings.TrimSpace(directiveText))
+			continue
+		}
+		directiveName := strings.ToLower(directiveFields[0])
+		securityHeadersConfig.ExtraSources[directiveName] = append(securityHeadersConfig.ExtraSources[directiveName], directiveFields[1:]...)
+	}
+	return securityHeadersConfig
+}
+
+// NewDatabaseConfig reads the database settings (DB_DRIVER, DB_DSN, DB_NAME, DB_AUTO_MIGRATE) from the environment
+// and the file named by CONFIG_FILE. It is separate from NewEnvConfig so operational commands can reach the database
+// without web server settings.
+func NewDatabaseConfig(applicationLogger *log.Logger) DatabaseConfig {
+	loadedSettings := commandSettings(applicationLogger)
+	databaseConfig := loadedSettings.DatabaseConfig()
+	loadedSettings.fatalOnProblems(applicationLogger)
+	return databaseConfig
+}
+
+// DatabaseConfig reads the database settings: DB_DRIVER, DB_DSN, DB_NAME and DB_AUTO_MIGRATE.
+func (settings *Settings) DatabaseConfig() DatabaseConfig {
+	databaseConfig := DatabaseConfig{
+		Driver:      strings.ToLower(settings.lookup("DB_DRIVER")),
+		Name:        settings.lookup("DB_NAME"),
+		DSN:         settings.lookup("DB_DSN"),
+		AutoMigrate: settings.boolean("DB_AUTO_MIGRATE"),
+	}
+	switch databaseConfig.Driver {
+	case DatabaseDriverSQLite:
+	case DatabaseDriverPostgres, DatabaseDriverMySQL:
+		if databaseConfig.DSN == "" {
+			settings.invalidf("%s is required when DB_DRIVER is %s", settingLabel("DB_DSN"), databaseConfig.Driver)
+		}
+	default:
+		settings.invalidf("Unsupported %s %q (expected %s, %s or %s)", settingLabel("DB_DRIVER"),
+			databaseConfig.Driver, DatabaseDriverSQLite, DatabaseDriverPostgres, DatabaseDriverMySQL)
+	}
+	return databaseConfig
+}
+
+// NewTrashConfig reads the trash settings (TRASH_RETENTION) from the environment and the file named by CONFIG_FILE.
+func NewTrashConfig(applicationLogger *log.Logger) TrashConfig {
+	loadedSettings := commandSettings(applicationLogger)
+	trashConfig := loadedSettings.TrashConfig()
+	loadedSettings.fatalOnProblems(applicationLogger)
+	return trashConfig
+}
+
+// TrashConfig reads the trash settings: TRASH_RETENTION.
+func (settings *Settings) TrashConfig() TrashConfig {
+	return TrashConfig{Retention: settings.duration("TRASH_RETENTION", DefaultTrashRetention, "a duration such as 720h, or 0 to keep deleted items forever")}
+}
+
+// NewBackupConfig reads the backup settings (BACKUP_DIR, BACKUP_INTERVAL, BACKUP_RETENTION) from the environment
+// and the file named by CONFIG_FILE.
+func NewBackupConfig(applicationLogger *log.Logger, databaseConfig DatabaseConfig) BackupConfig {
+	loadedSettings := commandSettings(applicationLogger)
+	backupConfig := loadedSettings.BackupConfig(databaseConfig)
+	loadedSettings.fatalOnProblems(applicationLogger)
+	return backupConfig
+}
+
+// BackupConfig reads the backup settings: BACKUP_DIR, BACKUP_INTERVAL and BACKUP_RETENTION.
+func (settings *Settings) BackupConfig(databaseConfig DatabaseConfig) BackupConfig {
+	backupConfig := BackupConfig{
+		Directory: settings.lookup("BACKUP_DIR"),
+		Interval:  settings.duration("BACKUP_INTERVAL", DefaultBackupInterval, "a duration such as 24h, or 0 to disable"),
+		Retention: settings.count("BACKUP_RETENTION", DefaultBackupRetention, "a non-negative number"),
+	}
+	if backupConfig.Directory == "" {
+		backupConfig.Directory = filepath.Join(filepath.Dir(databaseConfig.Name), DefaultBackupDirectoryName)
+	}
+	return backupConfig
+}
+
+// requireAll records a problem for each setting in requiredSettings, keyed by environment variable name, that has no
+// value. A non-empty providerName says the settings are required because that sign-in provider is enabled.
+func (settings *Settings) requireAll(requiredSettings map[string]string, providerName string) {
+	requiredNames := make([]string, 0, len(requiredSettings))
+	for envVarName := range requiredSettings {
+		requiredNames = append(requiredNames, envVarName)
+	}
+	sort.Strings(requiredNames)
+	for _, envVarName := range requiredNames {
+		if requiredSettings[envVarName] != "" {
+			continue
+		}
+		if providerName != "" {
+			settings.invalidf("%s is required when AUTH_PROVIDERS includes %s", settingLabel(envVarName), providerName)
+		} else {
+			settings.invalidf("%s is not set", settingLabel(envVarName))
+		}
+	}
+}
+
+// duration parses a non-negative duration setting, recording a problem and returning defaultValue if it is invalid.
+func (settings *Settings) duration(envName string, defaultValue time.Duration, expectation string) time.Duration {
+	envValue := settings.lookup(envName)
+	if envValue == "" {
+		return defaultValue
+	}
+	parsedDuration, parseError := time.ParseDuration(envValue)
+	if parseError != nil || parsedDuration < 0 {
+		settings.invalidf("Invalid %s value %q (expected %s)", settingLabel(envName), envValue, expectation)
+		return defaultValue
+	}
+	return parsedDuration
+}
+
+// count parses a non-negative integer setting, recording a problem and returning defaultValue if it is invalid.
+func (settings *Settings) count(envName string, defaultValue int, expectation string) int {
+	envValue := settings.lookup(envName)
+	if envValue == "" {
+		return defaultValue
+	}
+	parsedCount, parseError := strconv.Atoi(envValue)
+	if parseError != nil || parsedCount < 0 {
+		settings.invalidf("Invalid %s value %q (expected %s)", settingLabel(envName), envValue, expectation)
+		return defaultValue
+	}
+	return parsedCount
+}
+
+// port parses a TCP port setting, recording a problem and returning defaultValue if it is invalid.
+func (settings *Settings) port(envName string, defaultValue int) int {
+	envValue := settings.lookup(envName)
+	if envValue == "" {
+		return defaultValue
+	}
+	parsedPort, parseError := strconv.Atoi(envValue)
+	if parseError != nil || parsedPort <= 0 || parsedPort > 65535 {
+		settings.invalidf("Invalid %s value %q (expected a port number)", settingLabel(envName), envValue)
+		return defaultValue
+	}
+	return parsedPort
+}
+
+// boolean parses a boolean setting, recording a problem and returning false if it is invalid.
+func (settings *Settings) boolean(envName string) bool {
+	envValue := settings.lookup(envName)
+	if envValue == "" {
+		return false
+	}
+	parsedBool, parseError := strconv.ParseBool(envValue)
+	if parseError != nil {
+		settings.invalidf("Invalid %s value %q (expected true or false)", settingLabel(envName), envValue)
+	}
+	return parsedBool
+}
+
+// isLocalhostURL reports whether the URL's host is localhost, a *.localhost name or a loopback address.
+func isLocalhostURL(rawURL string) bool {
+	parsedURL, parseError := url.Parse(rawURL)
+	if parseError != nil {
+		return false
+	}
+	return isLoopbackHost(parsedURL.Hostname())
+}
+
+// isLoopbackHost reports whether the host name or IP address names this machine's loopback interface.
+func isLoopbackHost(hostName string) bool {
+	hostName = strings.ToLower(hostName)
+	if hostName == "localhost" || strings.HasSuffix(hostName, ".localhost") {
+		return true
+	}
+	hostIP := net.ParseIP(hostName)
+	return hostIP != nil && hostIP.IsLoopback()
+}
+
+// splitEmailList parses a comma-separated list of email addresses into lower-cased, trimmed entries.
+func splitEmailList(emailList string) []string {
+	var emailAddresses []string
+	for _, emailAddress := range strings.Split(emailList, ",") {
+		if trimmedAddress := strings.ToLower(strings.TrimSpace(emailAddress)); trimmedAddress != "" {
+			emailAddresses = append(emailAddresses, trimmedAddress)
+		}
+	}
+	return emailAddresses
 }
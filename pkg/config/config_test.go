@@ -0,0 +1,73 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func loadTestConfig(t *testing.T, extraArguments ...string) (*EnvConfig, []string) {
+	t.Helper()
+	t.Setenv("CONFIG_FILE", "")
+	commandLineArguments := append([]string{
+		"-session.secret=0123456789abcdef0123456789abcdef",
+		"-auth.providers=dev",
+	}, extraArguments...)
+	settings, err := LoadSettings(commandLineArguments)
+	if err != nil {
+		t.Fatalf("LoadSettings() error = %v", err)
+	}
+	envConfig := settings.EnvConfig()
+	return envConfig, settings.Problems()
+}
+
+func TestDevSignInBindsToLoopbackByDefault(t *testing.T) {
+	envConfig, problems := loadTestConfig(t, "-server.base_url=http://localhost:8080/")
+	if len(problems) != 0 {
+		t.Fatalf("unexpected problems: %v", problems)
+	}
+	if envConfig.Server.Address != DevServerAddress {
+		t.Errorf("Server.Address = %q, want %q", envConfig.Server.Address, DevServerAddress)
+	}
+}
+
+func TestDevSignInAcceptsExplicitLoopbackAddress(t *testing.T) {
+	envConfig, problems := loadTestConfig(t, "-server.base_url=http://localhost:8080/", "-server.address=::1")
+	if len(problems) != 0 {
+		t.Fatalf("unexpected problems: %v", problems)
+	}
+	if envConfig.Server.Address != "::1" {
+		t.Errorf("Server.Address = %q, want ::1", envConfig.Server.Address)
+	}
+}
+
+func TestDevSignInRejectsPublicAddresses(t *testing.T) {
+	testCases := map[string][]string{
+		"every interface": {"-server.base_url=http://localhost:8080/", "-server.address=0.0.0.0"},
+		"public base URL": {"-server.base_url=https://rsvp.example.com/"},
+	}
+	for name, extraArguments := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, problems := loadTestConfig(t, extraArguments...)
+			if len(problems) != 1 || !strings.Contains(problems[0], "AUTH_PROVIDERS=dev") {
+				t.Errorf("problems = %v, want one about the dev provider", problems)
+			}
+		})
+	}
+}
+
+func TestIsLocalhostURL(t *testing.T) {
+	testCases := map[string]bool{
+		"http://localhost:8080/":       true,
+		"http://app.localhost/":        true,
+		"http://127.0.0.1:8080/":       true,
+		"http://[::1]:8080/":           true,
+		"https://rsvp.example.com/":    false,
+		"http://0.0.0.0:8080/":         false,
+		"http://localhost.example.com": false,
+	}
+	for rawURL, expected := range testCases {
+		if isLocal := isLocalhostURL(rawURL); isLocal != expected {
+			t.Errorf("isLocalhostURL(%q) = %t, want %t", rawURL, isLocal, expected)
+		}
+	}
+}
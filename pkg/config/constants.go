@@ -5,9 +5,35 @@ const (
 	WebEvents           = "/events/"
 	WebRSVPs            = "/rsvps/"
 	WebRSVPQR           = "/rsvps/qr/"
+	WebRSVPStream       = "/rsvps/stream/"
+	WebRSVPLink         = "/rsvps/link/"
+	WebEventsStream     = "/events/stream/"
 	WebResponse         = "/response/"
 	WebResponseThankYou = "/response/thankyou"
 	WebVenues           = "/venues/"
+	WebAdminBackups     = "/admin/backups/"
+	WebAdminUsers       = "/admin/users/"
+	WebTokens           = "/tokens/"
+	WebTrash            = "/trash/"
+	WebAccount          = "/account/"
+	WebAccountExport    = "/account/export"
+	WebAccountImport    = "/account/import"
+	WebEventCohosts     = "/events/cohosts/"
+	WebOrganizations    = "/organizations/"
+	WebOrgMembers       = "/organizations/members/"
+	WebWorkspace        = "/workspace/"
+	WebTransfers        = "/transfers/"
+	WebLogin            = "/login"
+	WebLogout           = "/logout"
+	WebAuthOIDC         = "/auth/oidc"
+	WebAuthOIDCCallback = "/auth/oidc/callback"
+	WebAuthEmail        = "/auth/email"
+	WebAuthEmailSignIn  = "/auth/email/callback"
+	WebAuthDev          = "/auth/dev"
+	WebMetrics          = "/metrics"
+	WebHealthz          = "/healthz"
+	WebReadyz           = "/readyz"
+	WebStatic           = "/static/"
 )
 
 const (
@@ -17,11 +43,22 @@ const (
 	TemplateResponse  = "response"
 	TemplateThankYou  = "thankyou"
 	TemplateVenues    = "venues"
+	TemplateTokens    = "tokens"
+	TemplateAccount   = "account"
+	TemplateTrash     = "trash"
+	TemplateCohosts   = "cohosts"
+	TemplateOrgs      = "organizations"
+	TemplateTransfers = "transfers"
+	TemplateUsers     = "users"
+	TemplateForgery   = "form_expired"
 	TemplateExtension = ".tmpl"
 	TemplateLayout    = "layout"
 	TemplateLanding   = "landing"
 	TemplatesDir      = "templates"
 	PartialsDir       = "partials"
+	StaticDir         = "static"
+	// StaticCacheMaxAge is how long browsers may reuse a static file, in seconds.
+	StaticCacheMaxAge = 3600
 )
 
 const (
@@ -37,6 +74,9 @@ const (
 	ExtraGuestsParam          = "extra_guests"
 	MethodOverrideParam       = "_method"
 	ErrorQueryParam           = "error"
+	NoticeQueryParam          = "notice"
+	LoginEmailParam           = "email"
+	LoginTokenParam           = "token"
 	VenueNameParam            = "venue_name"
 	VenueAddressParam         = "venue_address"
 	VenueCapacityParam        = "venue_capacity"
@@ -47,6 +87,33 @@ const (
 	VenueSelectCreateNewValue = "__CREATE_NEW__"
 	ActionQueryParam          = "action"
 	ActionManageVenue         = "manage_venue"
+	FunnelEventIDParam        = "funnel_event_id"
+	TokenIDParam              = "token_id"
+	TokenNameParam            = "token_name"
+	TokenScopeParam           = "token_scope"
+	TokenEventIDParam         = "token_event_id"
+	ImportFileParam           = "import_file"
+	TrashItemTypeParam        = "item_type"
+	TrashItemIDParam          = "item_id"
+	MembershipIDParam         = "membership_id"
+	CohostEmailParam          = "cohost_email"
+	CohostRoleParam           = "cohost_role"
+	WorkspaceIDParam          = "workspace_id"
+	OrganizationIDParam       = "organization_id"
+	OrganizationNameParam     = "organization_name"
+	OrgMemberIDParam          = "member_id"
+	OrgMemberEmailParam       = "member_email"
+	OrgMemberRoleParam        = "member_role"
+	TransferIDParam           = "transfer_id"
+	TransferTypeParam         = "resource_type"
+	TransferResourceIDParam   = "resource_id"
+	TransferEmailParam        = "recipient_email"
+	TransferLinkedEventsParam = "include_linked_events"
+	UserIDParam               = "user_id"
+	UserSuspendedParam        = "suspended"
+	CSRFTokenParam            = "csrf_token"
+	InvitationTokenParam      = "invite"
+	AcceptBareCodesParam      = "accept_bare_codes"
 )
 
 const (
@@ -55,22 +122,74 @@ const (
 	ErrMsgEventNotFound    = "Event not found"
 )
 
+// Supported values of the DB_DRIVER environment variable.
 const (
-	DefaultDBName = "rsvps.db"
-	TableEvents   = "events"
-	TableRSVPs    = "rsvps"
-	TableUsers    = "users"
-	TableVenues   = "venues"
+	DatabaseDriverSQLite   = "sqlite"
+	DatabaseDriverPostgres = "postgres"
+	DatabaseDriverMySQL    = "mysql"
+	DefaultDatabaseDriver  = DatabaseDriverSQLite
+	// MySQLDefaultStringSize is the VARCHAR length used for unsized indexed string columns, which MySQL cannot index as TEXT.
+	MySQLDefaultStringSize = 191
+)
+
+// CascadeRestoreWindow is how long before an event's deletion its RSVPs may have been deleted and still
+// be treated as part of the same cascade when the event is restored.
+const CascadeRestoreWindow = 5 * 1e9
+
+const (
+	DefaultTrashRetention = 30 * 24 * 3600 * 1e9
+	TrashPurgeInterval    = 3600 * 1e9
+	TrashItemTypeEvent    = "event"
+	TrashItemTypeVenue    = "venue"
+	TrashItemTypeRSVP     = "rsvp"
+)
+
+const (
+	DefaultBackupDirectoryName = "backups"
+	DefaultBackupInterval      = 24 * 3600 * 1e9
+	DefaultBackupRetention     = 7
+	BackupFilePrefix           = "rsvps-"
+	BackupFileExtension        = ".db"
+	BackupPartialSuffix        = ".partial"
+	BackupTimestampLayout      = "20060102T150405.000Z"
+)
+
+const (
+	DefaultDBName  = "rsvps.db"
+	TableEvents    = "events"
+	TableRSVPs     = "rsvps"
+	TableUsers     = "users"
+	TableVenues    = "venues"
+	TableTokens    = "api_tokens"
+	TableMembers   = "event_memberships"
+	TableOrgs      = "organizations"
+	TableOrgUsers  = "organization_members"
+	TableTransfers = "ownership_transfers"
+	TableLogins    = "login_links"
+
+	TableSchemaMigrations = "schema_migrations"
 )
 
 const (
 	ResourceNameEvent    = "Event"
 	ResourceNameRSVP     = "RSVP"
 	ResourceNameRSVPQR   = "RSVP QR Code"
+	ResourceNameRSVPLink = "Invitation Link"
 	ResourceNameResponse = "Response"
 	ResourceNameThankYou = "Thank You Page"
 	ResourceNameUser     = "User"
 	ResourceNameVenue    = "Venue"
+	ResourceNameToken    = "API Token"
+	ResourceNameBackup   = "Backup"
+	ResourceNameAccount  = "Account"
+	ResourceNameTrash    = "Trash"
+	ResourceNameCohost   = "Co-host"
+	ResourceNameOrg      = "Organization"
+	ResourceNameOrgUser  = "Organization Member"
+	ResourceNameTransfer = "Ownership Transfer"
+	ResourceNameForm     = "Form Submission"
+	ResourceNameStatic   = "Static File"
+	ResourceNameHealth   = "Health Check"
 )
 
 const (
@@ -88,6 +207,10 @@ const (
 	MaxEventDuration   = 4
 	TimeLayoutHTMLForm = "2006-01-02T15:04"
 	MaxVenueNameLength = 200
+	FunnelMaxChartDays = 30
+	// MaxImportedLinkVersion and MaxImportedViewCount bound the counters an account import may carry.
+	MaxImportedLinkVersion = 1000000
+	MaxImportedViewCount   = 1000000
 )
 
 const (
@@ -127,6 +250,7 @@ const (
 	ButtonUpdateEvent     = "Update Event"
 	ButtonDeleteVenue     = "Delete Venue"
 	ButtonUpdateVenue     = "Update Venue"
+	LabelAcceptBareCodes  = "Also accept links with only the RSVP code, such as on printed cards"
 	LabelAddVenue         = "Add Venue"
 	LabelDuration         = "Duration"
 	LabelEventDescription = "Event Description"
@@ -146,10 +270,17 @@ const (
 	OptionNoVenue         = "-- No Venue --"
 )
 
+// Server defaults, overridden by the server settings. Write timeouts are left unset so that live update streams
+// can stay open.
 const (
-	ServerHTTPPort                = 8080
-	ServerHTTPAddress             = "0.0.0.0"
-	ServerGracefulShutdownTimeout = 10 * 1e9
+	DefaultServerPort    = 8080
+	DefaultServerAddress = "0.0.0.0"
+	// DevServerAddress is where the server listens by default while the development sign-in is enabled.
+	DevServerAddress         = "127.0.0.1"
+	DefaultReadHeaderTimeout = 10 * 1e9
+	DefaultIdleTimeout       = 120 * 1e9
+	DefaultShutdownTimeout   = 10 * 1e9
+	StreamHeartbeatInterval  = 25 * 1e9
 )
 
 const (
@@ -160,6 +291,186 @@ const (
 	RSVPCodeValidationRegexPattern = `^[0-9a-zA-Z]{1,8}$`
 )
 
+// Event membership roles. The event's creator is always the owner; co-hosts are invited with one of the other roles.
+const (
+	EventRoleOwner   = "owner"
+	EventRoleEditor  = "editor"
+	EventRoleViewer  = "viewer"
+	EventRoleCheckIn = "checkin"
+	MaxEmailLength   = 255
+)
+
+// Organization roles. Owners and admins manage members and control every event and venue of the
+// organization; members create and edit them.
+const (
+	OrgRoleOwner              = "owner"
+	OrgRoleAdmin              = "admin"
+	OrgRoleMember             = "member"
+	MaxOrganizationNameLength = 100
+	// SessionKeyWorkspaceID holds the organization ID of the active workspace; absent means the personal workspace.
+	SessionKeyWorkspaceID = "workspace_id"
+)
+
+// Authentication providers selectable with AUTH_PROVIDERS. Every provider ends the sign-in by storing the
+// user's email, name and picture under the GAuss session keys read by the user context middleware.
+const (
+	AuthProviderGoogle = "google"
+	AuthProviderOIDC   = "oidc"
+	AuthProviderEmail  = "email"
+	// AuthProviderDev signs in as any identity without a password, for local development only. It cannot be
+	// combined with other providers and requires a localhost APP_BASE_URL.
+	AuthProviderDev      = "dev"
+	DefaultAuthProviders = AuthProviderGoogle
+	DefaultDevAuthUsers  = "Alice Organizer <alice@example.com>, Bob Cohost <bob@example.com>"
+	LabelDevAuth         = "DEV AUTH"
+	DefaultOIDCLabel     = "Single Sign-On"
+	DefaultOIDCScopes    = "openid profile email"
+	// OIDCRequestTimeout bounds discovery, token and userinfo requests to the identity provider.
+	OIDCRequestTimeout       = 10 * 1e9
+	SessionKeyOIDCState      = "oidc_state"
+	SessionKeyOIDCNonce      = "oidc_nonce"
+	SessionKeyOIDCVerifier   = "oidc_verifier"
+	DefaultLoginLinkLifetime = 15 * 60 * 1e9
+	LoginLinkSecretLength    = 32
+	DefaultSMTPPort          = 587
+)
+
+// Limits on sign-in link requests, so that the form cannot be used to flood an inbox or the mail server. Each
+// client address and each recipient address gets a quota of links an hour.
+const (
+	DefaultLoginLinkLimitPerClient    = 10
+	DefaultLoginLinkLimitPerRecipient = 5
+	LoginLinkLimitPeriod              = 3600 * 1e9
+	ErrMsgTooManyLoginLinks           = "Too many sign-in links were requested. Please wait a few minutes and try again."
+)
+
+// Sign-in restrictions set with SIGNIN_ALLOWED_DOMAINS, SIGNIN_ALLOWED_EMAILS and SIGNIN_INVITE_ONLY, and
+// suspension of individual users by an administrator.
+const (
+	// SessionKeySignInRecorded holds the email address whose sign-in time was recorded for the current session,
+	// so the time is written once per sign-in rather than on every request.
+	SessionKeySignInRecorded = "sign_in_recorded"
+	ErrMsgSignInNotAllowed   = "This email address is not allowed to sign in. Ask the administrator for an invitation."
+	ErrMsgAccountSuspended   = "Your account has been suspended. Contact the administrator if you think this is a mistake."
+)
+
+// Rate limits of the public invitation pages, in requests per minute, and the lockout of clients that keep
+// asking for invitation codes that do not exist.
+const (
+	DefaultRateLimitPerClient = 60
+	DefaultRateLimitGlobal    = 1200
+	DefaultLookupFailureLimit = 10
+	LookupFailureWindow       = 3600 * 1e9
+	LookupBackoffBase         = 2 * 1e9
+	LookupBackoffMax          = 15 * 60 * 1e9
+	ErrMsgInvitationNotFound  = "This invitation link is not valid. Check that you opened the whole link, or ask the host to send it again."
+	ErrMsgTooManyRequests     = "Too many requests. Please wait a moment and try again."
+	RetryAfterHeader          = "Retry-After"
+)
+
+// Invitation links carry a signed token; bare RSVP codes are accepted only for events that opt in.
+const (
+	ErrMsgInvitationExpired = "This invitation link has expired. Ask the host for a new one if you still need to respond."
+)
+
+// Security headers sent with every response. The Content Security Policy carries a fresh nonce per response,
+// which inline scripts and style blocks must repeat; CSP_EXTRA_SOURCES, CSP_FRAME_ANCESTORS and the other settings relax it.
+const (
+	CSPNonceLength                 = 16
+	CSPHeader                      = "Content-Security-Policy"
+	CSPReportOnlyHeader            = "Content-Security-Policy-Report-Only"
+	CSPFrameAncestorsDirective     = "frame-ancestors"
+	CSPFrameAncestorsNone          = "'none'"
+	CSPFrameAncestorsSelf          = "'self'"
+	DefaultHSTSMaxAge              = 365 * 24 * 3600 * 1e9
+	DefaultReferrerPolicy          = "strict-origin-when-cross-origin"
+	DefaultPermissionsPolicy       = "camera=(), microphone=(), geolocation=(), payment=()"
+	HSTSHeader                     = "Strict-Transport-Security"
+	FrameOptionsHeader             = "X-Frame-Options"
+	ContentTypeOptionsHeader       = "X-Content-Type-Options"
+	ReferrerPolicyHeader           = "Referrer-Policy"
+	PermissionsPolicyHeader        = "Permissions-Policy"
+	CrossOriginOpenerPolicyHeader  = "Cross-Origin-Opener-Policy"
+	DefaultCrossOriginOpenerPolicy = "same-origin-allow-popups"
+)
+
+// Structured logging. Every request gets an ID, taken from a valid X-Request-ID header or generated, which is sent
+// back in the same header and attached to every log line written while serving the request.
+const (
+	LogFormatText      = "text"
+	LogFormatJSON      = "json"
+	DefaultLogFormat   = LogFormatText
+	DefaultLogLevel    = "info"
+	RequestIDHeader    = "X-Request-ID"
+	RequestIDLength    = 12
+	MaxRequestIDLength = 128
+	// SlowQueryThreshold is how long a database query may take before the server logs it as slow.
+	SlowQueryThreshold = 200 * 1e6
+)
+
+// Prometheus metrics. RSVP responses are counted by who recorded them: the guest on the invitation page or an
+// organizer on the event's RSVP list.
+const (
+	MetricsSourceGuest     = "guest"
+	MetricsSourceOrganizer = "organizer"
+	// MetricsReadHeaderTimeout bounds how long the separate metrics listener waits for request headers.
+	MetricsReadHeaderTimeout = 10 * 1e9
+)
+
+// OpenTelemetry tracing. Spans cover each HTTP request, each view rendering and each database statement.
+const (
+	TracingExporterOTLP       = "otlp"
+	TracingExporterStdout     = "stdout"
+	TracingExporterNone       = "none"
+	DefaultTracingServiceName = "rsvp"
+	DefaultTracingSampleRatio = 1.0
+	// TracingInstrumentationName identifies the application's own spans.
+	TracingInstrumentationName = "github.com/temirov/RSVP"
+)
+
+// Health checks for container orchestration. /healthz answers while the process runs; /readyz also checks the
+// database, schema, templates and session secret, and fails from the moment shutdown begins, so that load balancers
+// stop sending traffic during DefaultShutdownDrain before the listener closes.
+const (
+	HealthStatusOK       = "ok"
+	HealthStatusFailing  = "failing"
+	ReadinessTimeout     = 2 * 1e9
+	DefaultShutdownDrain = 5 * 1e9
+)
+
+// Ownership transfers move a personal event or venue to another user once the recipient accepts.
+const (
+	TransferResourceEvent   = "event"
+	TransferResourceVenue   = "venue"
+	TransferStatusPending   = "pending"
+	TransferStatusAccepted  = "accepted"
+	TransferStatusCancelled = "cancelled"
+	TransferStatusDeclined  = "declined"
+	// TransferOfferLifetime is how long a pending transfer can be accepted; after that it lapses.
+	TransferOfferLifetime = 14 * 24 * 3600 * 1e9
+)
+
+const (
+	TokenScopeRead        = "read"
+	TokenScopeReadWrite   = "read_write"
+	TokenPlaintextPrefix  = "rsvp_"
+	TokenSecretLength     = 40
+	TokenDisplayPrefixLen = 12
+	MaxTokenNameLength    = 100
+	AuthorizationHeader   = "Authorization"
+	BearerSchemePrefix    = "Bearer "
+)
+
+// Cross-site request forgery protection. Every browser session gets a random token, kept in its own signed
+// cookie so that visitors without an account, who answer invitations, have one too.
+const (
+	CSRFSessionName = "rsvp_csrf"
+	CSRFHeader      = "X-CSRF-Token"
+	CSRFTokenLength = 32
+	// MaxFormBytes bounds url-encoded form bodies read before the handler runs.
+	MaxFormBytes = 10 << 20
+)
+
 const (
 	ContextKeyUser = "user"
 	DatabaseError  = "database_error"
@@ -168,7 +479,14 @@ const (
 const (
 	ResourceLabelEventManager = "Events"
 	ResourceLabelVenueManager = "Venues"
-	AppTitle                  = "RSVP Manager"
+	ResourceLabelTokenManager = "API Tokens"
+	ResourceLabelAccount      = "My Data"
+	ResourceLabelTrash        = "Trash"
+	ResourceLabelOrgs         = "Organizations"
+	ResourceLabelTransfers    = "Transfers"
+	ResourceLabelUsers        = "Users"
+	LabelPersonalWorkspace    = "Personal"
+	DefaultAppTitle           = "RSVP Manager"
 	LabelWelcome              = "Welcome,"
 	LabelSignOut              = "Sign Out"
 	LabelNotSignedIn          = "Not signed in"
@@ -177,3 +495,9 @@ const (
 const (
 	MapsSearchBaseURL = "https://www.google.com/maps/search/?api=1&query="
 )
+
+const (
+	ExportFormatName     = "rsvp-account-export"
+	ExportFormatVersion  = 1
+	ExportFileNamePrefix = "rsvp-export-"
+)
@@ -0,0 +1,349 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Where a setting's value came from, from the strongest to the weakest.
+const (
+	SettingOriginFlag        = "flag"
+	SettingOriginEnvironment = "environment"
+	SettingOriginFile        = "file"
+	SettingOriginDefault     = "default"
+)
+
+// redactedValue replaces secrets when the effective configuration is printed.
+const redactedValue = "[redacted]"
+
+// settingDefinition describes one setting. Each can be given as an environment variable, as a key in the
+// configuration file (nested: "server.port" is the port key of the server table) and as a command-line flag named
+// after the key.
+type settingDefinition struct {
+	// EnvName is the environment variable, which also identifies the setting in code.
+	EnvName string
+	// Key is the dotted key in the configuration file and the name of the command-line flag.
+	Key string
+	// Default is used when no source sets the setting; empty leaves the default to the code that reads it.
+	Default string
+	// ListSeparator joins list values in the configuration file into one string; it defaults to ",".
+	ListSeparator string
+	// Secret settings are redacted when the effective configuration is printed.
+	Secret bool
+	// Usage is the one-line description shown by -help.
+	Usage string
+}
+
+// settingDefinitions lists every setting, grouped as in the configuration file.
+var settingDefinitions = []settingDefinition{
+	{EnvName: "SERVER_ADDRESS", Key: "server.address", Default: DefaultServerAddress, Usage: "address the server listens on"},
+	{EnvName: "SERVER_PORT", Key: "server.port", Default: strconv.Itoa(DefaultServerPort), Usage: "port the server listens on"},
+	{EnvName: "APP_BASE_URL", Key: "server.base_url", Usage: "public base URL of the application (required)"},
+	{EnvName: "TLS_CERT_PATH", Key: "server.tls_cert_path", Usage: "TLS certificate file; serves HTTPS together with server.tls_key_path"},
+	{EnvName: "TLS_KEY_PATH", Key: "server.tls_key_path", Usage: "TLS private key file"},
+	{EnvName: "SERVER_READ_HEADER_TIMEOUT", Key: "server.read_header_timeout", Default: time.Duration(DefaultReadHeaderTimeout).String(), Usage: "time allowed to read request headers"},
+	{EnvName: "SERVER_IDLE_TIMEOUT", Key: "server.idle_timeout", Default: time.Duration(DefaultIdleTimeout).String(), Usage: "time an idle keep-alive connection stays open"},
+	{EnvName: "SHUTDOWN_TIMEOUT", Key: "server.shutdown_timeout", Default: time.Duration(DefaultShutdownTimeout).String(), Usage: "time open requests get to finish on shutdown"},
+	{EnvName: "SHUTDOWN_DRAIN_DELAY", Key: "server.shutdown_drain_delay", Default: time.Duration(DefaultShutdownDrain).String(), Usage: "time /readyz fails before the listener closes on shutdown"},
+	{EnvName: "TRUSTED_PROXIES", Key: "server.trusted_proxies", Usage: "comma-separated proxy addresses or CIDR ranges whose X-Forwarded-For is believed"},
+	{EnvName: "SESSION_SECRET", Key: "session.secret", Secret: true, Usage: "secret signing the session cookies (required)"},
+	{EnvName: "APP_TITLE", Key: "branding.app_title", Default: DefaultAppTitle, Usage: "application name shown on pages and in emails"},
+	{EnvName: "ASSETS_OVERRIDE_DIR", Key: "branding.assets_dir", Usage: "directory whose templates/ and static/ files replace the embedded ones"},
+	{EnvName: "DB_DRIVER", Key: "database.driver", Default: DefaultDatabaseDriver, Usage: "sqlite, postgres or mysql"},
+	{EnvName: "DB_NAME", Key: "database.name", Default: DefaultDBName, Usage: "SQLite database file"},
+	{EnvName: "DB_DSN", Key: "database.dsn", Secret: true, Usage: "connection string, required for postgres and mysql"},
+	{EnvName: "DB_AUTO_MIGRATE", Key: "database.auto_migrate", Default: "true", Usage: "apply pending migrations at startup"},
+	{EnvName: "BACKUP_DIR", Key: "backup.dir", Usage: "backup directory (default: backups next to the SQLite file)"},
+	{EnvName: "BACKUP_INTERVAL", Key: "backup.interval", Default: time.Duration(DefaultBackupInterval).String(), Usage: "time between scheduled backups, 0 to disable"},
+	{EnvName: "BACKUP_RETENTION", Key: "backup.retention", Default: strconv.Itoa(DefaultBackupRetention), Usage: "number of backups kept, 0 to keep all"},
+	{EnvName: "TRASH_RETENTION", Key: "trash.retention", Default: time.Duration(DefaultTrashRetention).String(), Usage: "time deleted items stay restorable, 0 to keep them forever"},
+	{EnvName: "ADMIN_EMAILS", Key: "admin.emails", Usage: "comma-separated administrator email addresses"},
+	{EnvName: "AUTH_PROVIDERS", Key: "auth.providers", Default: DefaultAuthProviders, Usage: "comma-separated sign-in providers: google, oidc, email, or dev alone"},
+	{EnvName: "DEV_AUTH_USERS", Key: "auth.dev_users", Default: DefaultDevAuthUsers, Usage: "test identities offered by the dev provider"},
+	{EnvName: "LOGIN_LINK_LIFETIME", Key: "auth.login_link_lifetime", Default: time.Duration(DefaultLoginLinkLifetime).String(), Usage: "lifetime of emailed sign-in links"},
+	{EnvName: "LOGIN_LINK_LIMIT_PER_CLIENT", Key: "auth.login_link_limit_per_client", Default: strconv.Itoa(DefaultLoginLinkLimitPerClient), Usage: "sign-in links an hour one client address may request, 0 to disable"},
+	{EnvName: "LOGIN_LINK_LIMIT_PER_RECIPIENT", Key: "auth.login_link_limit_per_recipient", Default: strconv.Itoa(DefaultLoginLinkLimitPerRecipient), Usage: "sign-in links an hour sent to one address, 0 to disable"},
+	{EnvName: "GOOGLE_CLIENT_ID", Key: "auth.google.client_id", Usage: "Google OAuth client ID"},
+	{EnvName: "GOOGLE_CLIENT_SECRET", Key: "auth.google.client_secret", Secret: true, Usage: "Google OAuth client secret"},
+	{EnvName: "GOOGLE_OAUTH2_BASE", Key: "auth.google.oauth2_base", Usage: "Google OAuth callback base URL"},
+	{EnvName: "OIDC_ISSUER_URL", Key: "auth.oidc.issuer_url", Usage: "OpenID Connect issuer URL"},
+	{EnvName: "OIDC_CLIENT_ID", Key: "auth.oidc.client_id", Usage: "OpenID Connect client ID"},
+	{EnvName: "OIDC_CLIENT_SECRET", Key: "auth.oidc.client_secret", Secret: true, Usage: "OpenID Connect client secret"},
+	{EnvName: "OIDC_LABEL", Key: "auth.oidc.label", Default: DefaultOIDCLabel, Usage: "label of the OpenID Connect sign-in button"},
+	{EnvName: "OIDC_SCOPES", Key: "auth.oidc.scopes", Default: DefaultOIDCScopes, ListSeparator: " ", Usage: "OpenID Connect scopes"},
+	{EnvName: "SMTP_HOST", Key: "smtp.host", Usage: "SMTP server for sign-in emails"},
+	{EnvName: "SMTP_PORT", Key: "smtp.port", Default: strconv.Itoa(DefaultSMTPPort), Usage: "SMTP server port"},
+	{EnvName: "SMTP_USERNAME", Key: "smtp.username", Usage: "SMTP user name"},
+	{EnvName: "SMTP_PASSWORD", Key: "smtp.password", Secret: true, Usage: "SMTP password"},
+	{EnvName: "SMTP_FROM", Key: "smtp.from", Usage: "sender address of sign-in emails"},
+	{EnvName: "SIGNIN_ALLOWED_DOMAINS", Key: "access.allowed_domains", Usage: "comma-separated email domains allowed to sign in"},
+	{EnvName: "SIGNIN_ALLOWED_EMAILS", Key: "access.allowed_emails", Usage: "comma-separated addresses allowed to sign in"},
+	{EnvName: "SIGNIN_INVITE_ONLY", Key: "access.invite_only", Default: "false", Usage: "only invited users may sign in"},
+	{EnvName: "PUBLIC_RATE_LIMIT_PER_CLIENT", Key: "rate_limit.per_client", Default: strconv.Itoa(DefaultRateLimitPerClient), Usage: "invitation page requests a minute per client, 0 to disable"},
+	{EnvName: "PUBLIC_RATE_LIMIT_GLOBAL", Key: "rate_limit.global", Default: strconv.Itoa(DefaultRateLimitGlobal), Usage: "invitation page requests a minute from all clients, 0 to disable"},
+	{EnvName: "PUBLIC_LOOKUP_FAILURE_LIMIT", Key: "rate_limit.lookup_failure_limit", Default: strconv.Itoa(DefaultLookupFailureLimit), Usage: "unknown invitation codes an hour before a client is locked out, 0 to disable"},
+	{EnvName: "INVITATION_SIGNING_KEY", Key: "invitation.signing_key", Secret: true, Usage: "key signing invitation links (default: session.secret)"},
+	{EnvName: "INVITATION_LINKS_EXPIRE_AFTER", Key: "invitation.links_expire_after", Default: "0s", Usage: "time after its event ends that an invitation link stops working, 0 for never"},
+	{EnvName: "HSTS_MAX_AGE", Key: "security_headers.hsts_max_age", Default: time.Duration(DefaultHSTSMaxAge).String(), Usage: "Strict-Transport-Security max-age over HTTPS, 0 to disable"},
+	{EnvName: "CSP_FRAME_ANCESTORS", Key: "security_headers.csp_frame_ancestors", Default: CSPFrameAncestorsNone, ListSeparator: " ", Usage: "sources allowed to frame the pages"},
+	{EnvName: "CSP_EXTRA_SOURCES", Key: "security_headers.csp_extra_sources", ListSeparator: "; ", Usage: "extra Content Security Policy sources, such as \"img-src https://images.example.com\""},
+	{EnvName: "CSP_REPORT_ONLY", Key: "security_headers.csp_report_only", Default: "false", Usage: "report policy violations without blocking them"},
+	{EnvName: "CSP_REPORT_URI", Key: "security_headers.csp_report_uri", Usage: "where browsers report policy violations"},
+	{EnvName: "CSP_ALLOW_INLINE_STYLES", Key: "security_headers.csp_allow_inline_styles", Default: "false", Usage: "allow style attributes and inline styles without the nonce, for overridden templates that use them"},
+	{EnvName: "REFERRER_POLICY", Key: "security_headers.referrer_policy", Default: DefaultReferrerPolicy, Usage: "Referrer-Policy header value"},
+	{EnvName: "LOG_FORMAT", Key: "logging.format", Default: DefaultLogFormat, Usage: "text or json"},
+	{EnvName: "LOG_LEVEL", Key: "logging.level", Default: DefaultLogLevel, Usage: "debug, info, warn or error"},
+	{EnvName: "METRICS_ADDRESS", Key: "metrics.address", Usage: "separate listen address serving only /metrics"},
+	{EnvName: "METRICS_TOKEN", Key: "metrics.token", Secret: true, Usage: "bearer token required to read /metrics"},
+	{EnvName: "TRACING_EXPORTER", Key: "tracing.exporter", Usage: "otlp, stdout or none"},
+	{EnvName: "OTEL_SERVICE_NAME", Key: "tracing.service_name", Default: DefaultTracingServiceName, Usage: "service name recorded on spans"},
+	{EnvName: "TRACING_SAMPLE_RATIO", Key: "tracing.sample_ratio", Default: strconv.FormatFloat(DefaultTracingSampleRatio, 'f', -1, 64), Usage: "fraction of new traces recorded, from 0 to 1"},
+}
+
+// settingValue is the resolved value of a setting and where it came from.
+type settingValue struct {
+	value  string
+	origin string
+}
+
+// Settings holds the resolved value of every setting and collects the problems found while turning them into
+// configuration, so that they can all be reported at once.
+type Settings struct {
+	values         map[string]settingValue
+	problems       []string
+	configFilePath string
+	printRequested bool
+}
+
+// LoadSettings resolves every setting from, in order of precedence, the command-line flags in
+// commandLineArguments, the environment, the configuration file named by -config or CONFIG_FILE, and the defaults.
+// The file is YAML, or TOML when its name ends in ".toml". It fails only when the command line or the file cannot
+// be read; problems with the values themselves are collected while the configuration is built and returned by Err.
+func LoadSettings(commandLineArguments []string) (*Settings, error) {
+	loadedSettings := &Settings{values: make(map[string]settingValue, len(settingDefinitions))}
+	for _, definition := range settingDefinitions {
+		if definition.Default != "" {
+			loadedSettings.values[definition.EnvName] = settingValue{value: definition.Default, origin: SettingOriginDefault}
+		}
+	}
+
+	flagSet := flag.NewFlagSet(filepath.Base(os.Args[0]), flag.ContinueOnError)
+	configFileFlag := flagSet.String("config", "", "configuration file, YAML or TOML (env CONFIG_FILE)")
+	printConfigFlag := flagSet.Bool("print-config", false, "print the effective configuration with secrets redacted, and exit")
+	for _, definition := range settingDefinitions {
+		flagSet.String(definition.Key, "", definition.Usage+" (env "+definition.EnvName+")")
+	}
+	if parseError := flagSet.Parse(commandLineArguments); parseError != nil {
+		return nil, parseError
+	}
+	if flagSet.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected argument %q", flagSet.Arg(0))
+	}
+	loadedSettings.printRequested = *printConfigFlag
+
+	loadedSettings.configFilePath = *configFileFlag
+	if loadedSettings.configFilePath == "" {
+		loadedSettings.configFilePath = os.Getenv("CONFIG_FILE")
+	}
+	if loadedSettings.configFilePath != "" {
+		if fileError := loadedSettings.loadFile(loadedSettings.configFilePath); fileError != nil {
+			return nil, fileError
+		}
+	}
+	for _, definition := range settingDefinitions {
+		if envValue := os.Getenv(definition.EnvName); envValue != "" {
+			loadedSettings.values[definition.EnvName] = settingValue{value: envValue, origin: SettingOriginEnvironment}
+		}
+	}
+	definitionsByKey := settingDefinitionsByKey()
+	flagSet.Visit(func(setFlag *flag.Flag) {
+		if definition, isSetting := definitionsByKey[setFlag.Name]; isSetting {
+			loadedSettings.values[definition.EnvName] = settingValue{value: setFlag.Value.String(), origin: SettingOriginFlag}
+		}
+	})
+	return loadedSettings, nil
+}
+
+// loadFile reads the configuration file. Unknown keys are reported as problems rather than ignored, so that a
+// misspelt key does not silently leave a default in place.
+func (settings *Settings) loadFile(configFilePath string) error {
+	fileContents, readError := os.ReadFile(configFilePath)
+	if readError != nil {
+		return fmt.Errorf("reading configuration file: %w", readError)
+	}
+	fileValues := map[string]interface{}{}
+	if strings.EqualFold(filepath.Ext(configFilePath), ".toml") {
+		if decodeError := toml.Unmarshal(fileContents, &fileValues); decodeError != nil {
+			return fmt.Errorf("parsing configuration file %s: %w", configFilePath, decodeError)
+		}
+	} else if decodeError := yaml.Unmarshal(fileContents, &fileValues); decodeError != nil {
+		return fmt.Errorf("parsing configuration file %s: %w", configFilePath, decodeError)
+	}
+	definitionsByKey := settingDefinitionsByKey()
+	flattenedValues := map[string]interface{}{}
+	flattenFileValues("", fileValues, flattenedValues)
+	for fileKey, fileValue := range flattenedValues {
+		definition, isSetting := definitionsByKey[fileKey]
+		if !isSetting {
+			settings.invalidf("Unknown setting %q in %s", fileKey, configFilePath)
+			continue
+		}
+		settings.values[definition.EnvName] = settingValue{value: fileValueString(fileValue, definition.ListSeparator), origin: SettingOriginFile}
+	}
+	return nil
+}
+
+// flattenFileValues turns nested tables into dotted keys.
+func flattenFileValues(keyPrefix string, nestedValues map[string]interface{}, flattenedValues map[string]interface{}) {
+	for nestedKey, nestedValue := range nestedValues {
+		if nestedTable, isTable := nestedValue.(map[string]interface{}); isTable {
+			flattenFileValues(keyPrefix+nestedKey+".", nestedTable, flattenedValues)
+			continue
+		}
+		flattenedValues[keyPrefix+nestedKey] = nestedValue
+	}
+}
+
+// fileValueString writes a value from the file the way it would be written in an environment variable.
+func fileValueString(fileValue interface{}, listSeparator string) string {
+	if listSeparator == "" {
+		listSeparator = ","
+	}
+	switch typedValue := fileValue.(type) {
+	case nil:
+		return ""
+	case []interface{}:
+		listItems := make([]string, 0, len(typedValue))
+		for _, listItem := range typedValue {
+			listItems = append(listItems, fileValueString(listItem, listSeparator))
+		}
+		return strings.Join(listItems, listSeparator)
+	case float64:
+		return strconv.FormatFloat(typedValue, 'f', -1, 64)
+	default:
+		return fmt.Sprint(typedValue)
+	}
+}
+
+// settingDefinitionsByKey indexes the definitions by file key.
+func settingDefinitionsByKey() map[string]settingDefinition {
+	definitionsByKey := make(map[string]settingDefinition, len(settingDefinitions))
+	for _, definition := range settingDefinitions {
+		definitionsByKey[definition.Key] = definition
+	}
+	return definitionsByKey
+}
+
+// lookup returns the resolved value of the setting with the given environment variable name, or an empty string.
+func (settings *Settings) lookup(envName string) string {
+	return settings.values[envName].value
+}
+
+// settingLabel names a setting by its environment variable and file key, for messages about missing values.
+func settingLabel(envName string) string {
+	for _, definition := range settingDefinitions {
+		if definition.EnvName == envName {
+			return envName + " (" + definition.Key + ")"
+		}
+	}
+	return envName
+}
+
+// invalidf records a problem with the configuration.
+func (settings *Settings) invalidf(format string, arguments ...interface{}) {
+	settings.problems = append(settings.problems, fmt.Sprintf(format, arguments...))
+}
+
+// Problems returns the problems recorded so far, one message each.
+func (settings *Settings) Problems() []string {
+	return settings.problems
+}
+
+// PrintRequested reports whether -print-config was given.
+func (settings *Settings) PrintRequested() bool {
+	return settings.printRequested
+}
+
+// WriteEffective writes the resolved settings as a configuration file, with secrets redacted and each value's
+// origin in a comment. Settings without a value are left out.
+func (settings *Settings) WriteEffective(output io.Writer) error {
+	var effectiveLines []string
+	effectiveLines = append(effectiveLines, "# Effective configuration. Precedence: flag > environment > file > default.")
+	if settings.configFilePath != "" {
+		effectiveLines = append(effectiveLines, "# Configuration file: "+settings.configFilePath)
+	}
+	var previousPath []string
+	for _, definition := range settings.sortedDefinitions() {
+		resolvedSetting, isSet := settings.values[definition.EnvName]
+		if !isSet || resolvedSetting.value == "" {
+			continue
+		}
+		keyPath := strings.Split(definition.Key, ".")
+		commonDepth := 0
+		for commonDepth < len(previousPath) && commonDepth < len(keyPath)-1 && previousPath[commonDepth] == keyPath[commonDepth] {
+			commonDepth++
+		}
+		for tableDepth := commonDepth; tableDepth < len(keyPath)-1; tableDepth++ {
+			effectiveLines = append(effectiveLines, strings.Repeat("  ", tableDepth)+keyPath[tableDepth]+":")
+		}
+		printedValue := resolvedSetting.value
+		if definition.Secret {
+			printedValue = redactedValue
+		}
+		effectiveLines = append(effectiveLines, fmt.Sprintf("%s%s: %s # %s, %s",
+			strings.Repeat("  ", len(keyPath)-1), keyPath[len(keyPath)-1], strconv.Quote(printedValue), resolvedSetting.origin, definition.EnvName))
+		previousPath = keyPath[:len(keyPath)-1]
+	}
+	_, writeError := io.WriteString(output, strings.Join(effectiveLines, "\n")+"\n")
+	return writeError
+}
+
+// sortedDefinitions returns the definitions with every table's keys together, keeping the order of the tables.
+func (settings *Settings) sortedDefinitions() []settingDefinition {
+	sortedDefinitions := append([]settingDefinition(nil), settingDefinitions...)
+	tableOrder := map[string]int{}
+	for definitionIndex, definition := range settingDefinitions {
+		tableName := definition.Key[:strings.LastIndex(definition.Key, ".")]
+		if _, isKnown := tableOrder[tableName]; !isKnown {
+			tableOrder[tableName] = definitionIndex
+		}
+	}
+	sort.SliceStable(sortedDefinitions, func(leftIndex, rightIndex int) bool {
+		leftKey, rightKey := sortedDefinitions[leftIndex].Key, sortedDefinitions[rightIndex].Key
+		leftTable, rightTable := leftKey[:strings.LastIndex(leftKey, ".")], rightKey[:strings.LastIndex(rightKey, ".")]
+		return tableOrder[leftTable] < tableOrder[rightTable]
+	})
+	return sortedDefinitions
+}
+
+// commandSettings loads the settings of a command-line tool, which takes no setting flags: the environment and the
+// configuration file named by CONFIG_FILE.
+func commandSettings(applicationLogger *log.Logger) *Settings {
+	loadedSettings, loadError := LoadSettings(nil)
+	if loadError != nil {
+		applicationLogger.Fatalf("Loading the configuration failed: %v", loadError)
+	}
+	return loadedSettings
+}
+
+// fatalOnProblems ends the program, listing every problem, if the configuration has any.
+func (settings *Settings) fatalOnProblems(applicationLogger *log.Logger) {
+	if len(settings.problems) == 0 {
+		return
+	}
+	for _, problem := range settings.problems {
+		applicationLogger.Printf("ERROR: %s", problem)
+	}
+	applicationLogger.Fatalf("Invalid configuration: %d problem(s)", len(settings.problems))
+}
@@ -0,0 +1,43 @@
+// Package account provides HTTP handlers that let users download their data and import data exported elsewhere.
+package account
+
+import (
+	"net/http"
+
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/handlers"
+	"github.com/temirov/RSVP/pkg/middleware"
+	"github.com/temirov/RSVP/pkg/portability"
+	"github.com/temirov/RSVP/pkg/utils"
+)
+
+// ViewData is passed to the "account" view template.
+type ViewData struct {
+	AccountLabel        string
+	URLForExport        string
+	URLForImport        string
+	ParamNameImportFile string
+	ImportReport        *portability.ImportReport
+}
+
+// renderAccountPage renders the data export and import page, with the report of an import that just ran if any.
+func renderAccountPage(baseHttpHandler *handlers.BaseHttpHandler, responseWriter http.ResponseWriter, request *http.Request, importReport *portability.ImportReport) {
+	viewData := ViewData{
+		AccountLabel:        config.ResourceLabelAccount,
+		URLForExport:        config.WebAccountExport,
+		URLForImport:        config.WebAccountImport,
+		ParamNameImportFile: config.ImportFileParam,
+		ImportReport:        importReport,
+	}
+	baseHttpHandler.RenderView(responseWriter, request, config.TemplateAccount, viewData)
+}
+
+// rejectEventScopedToken answers 403 for API tokens restricted to a single event, which must not reach account-wide data.
+// It returns true if the request was rejected.
+func rejectEventScopedToken(baseHttpHandler *handlers.BaseHttpHandler, responseWriter http.ResponseWriter, request *http.Request) bool {
+	if apiToken := middleware.APITokenFromContext(request.Context()); apiToken != nil && apiToken.EventID != nil {
+		baseHttpHandler.HandleError(responseWriter, request, nil, utils.ForbiddenError, "Forbidden: This API token is restricted to a single event.")
+		return true
+	}
+	return false
+}
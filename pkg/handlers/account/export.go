@@ -0,0 +1,39 @@
+package account
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/temirov/RSVP/models"
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/handlers"
+	"github.com/temirov/RSVP/pkg/logging"
+	"github.com/temirov/RSVP/pkg/middleware"
+	"github.com/temirov/RSVP/pkg/portability"
+	"github.com/temirov/RSVP/pkg/utils"
+)
+
+// ExportHandler handles GET requests for the current user's data as a downloadable JSON account export.
+func ExportHandler(applicationContext *config.ApplicationContext) http.HandlerFunc {
+	baseHttpHandler := handlers.NewBaseHttpHandler(applicationContext, config.ResourceNameAccount, config.WebAccount)
+	return func(responseWriter http.ResponseWriter, request *http.Request) {
+		if !baseHttpHandler.ValidateHttpMethod(responseWriter, request, http.MethodGet) {
+			return
+		}
+		if rejectEventScopedToken(&baseHttpHandler, responseWriter, request) {
+			return
+		}
+		currentUser := request.Context().Value(middleware.ContextKeyUser).(*models.User)
+
+		exportDocument, exportError := portability.Export(applicationContext.Database.WithContext(request.Context()), currentUser)
+		if exportError != nil {
+			baseHttpHandler.HandleError(responseWriter, request, exportError, utils.DatabaseError, "Failed to export your data.")
+			return
+		}
+		logging.FromContext(request.Context()).Info("Account exported", "venues", len(exportDocument.Venues), "events", len(exportDocument.Events))
+
+		exportFileName := config.ExportFileNamePrefix + time.Now().UTC().Format("2006-01-02") + ".json"
+		responseWriter.Header().Set("Content-Disposition", `attachment; filename="`+exportFileName+`"`)
+		baseHttpHandler.WriteJSON(responseWriter, request, http.StatusOK, exportDocument)
+	}
+}
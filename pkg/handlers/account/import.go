@@ -0,0 +1,70 @@
+package account
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/temirov/RSVP/models"
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/handlers"
+	"github.com/temirov/RSVP/pkg/logging"
+	"github.com/temirov/RSVP/pkg/middleware"
+	"github.com/temirov/RSVP/pkg/portability"
+	"github.com/temirov/RSVP/pkg/utils"
+)
+
+// ImportHandler handles POST requests that import an account export into the current user's account.
+// API clients send the document as an application/json body and receive the import report as JSON;
+// the upload form on the account page sends it as a file and gets the page back with the report.
+func ImportHandler(applicationContext *config.ApplicationContext) http.HandlerFunc {
+	baseHttpHandler := handlers.NewBaseHttpHandler(applicationContext, config.ResourceNameAccount, config.WebAccount)
+	return func(responseWriter http.ResponseWriter, request *http.Request) {
+		if !baseHttpHandler.ValidateHttpMethod(responseWriter, request, http.MethodPost) {
+			return
+		}
+		if rejectEventScopedToken(&baseHttpHandler, responseWriter, request) {
+			return
+		}
+		currentUser := request.Context().Value(middleware.ContextKeyUser).(*models.User)
+
+		mediaType, _, _ := mime.ParseMediaType(request.Header.Get("Content-Type"))
+		isJSONRequest := mediaType == "application/json"
+		var documentReader io.Reader = request.Body
+		if !isJSONRequest {
+			uploadedFile, _, formFileError := request.FormFile(config.ImportFileParam)
+			if formFileError != nil {
+				baseHttpHandler.HandleError(responseWriter, request, formFileError, utils.ValidationError, "Please choose an export file to import.")
+				return
+			}
+			defer uploadedFile.Close()
+			documentReader = uploadedFile
+		}
+
+		var exportDocument portability.Document
+		if decodeError := json.NewDecoder(documentReader).Decode(&exportDocument); decodeError != nil {
+			baseHttpHandler.HandleError(responseWriter, request, decodeError, utils.ValidationError, "The file is not a valid account export.")
+			return
+		}
+		importReport, importError := portability.Import(applicationContext.Database.WithContext(request.Context()), &exportDocument, currentUser.ID)
+		if errors.Is(importError, portability.ErrUnsupportedFormat) {
+			baseHttpHandler.HandleError(responseWriter, request, importError, utils.ValidationError, importError.Error())
+			return
+		}
+		if importError != nil {
+			baseHttpHandler.HandleError(responseWriter, request, importError, utils.DatabaseError, "Failed to import the data. Nothing was changed.")
+			return
+		}
+		logging.FromContext(request.Context()).Info("Account imported", "venues", importReport.Created.Venues,
+			"events", importReport.Created.Events, "rsvps", importReport.Created.RSVPs,
+			"remapped", len(importReport.Remapped), "skipped", len(importReport.Skipped))
+
+		if isJSONRequest {
+			baseHttpHandler.WriteJSON(responseWriter, request, http.StatusOK, importReport)
+			return
+		}
+		renderAccountPage(&baseHttpHandler, responseWriter, request, importReport)
+	}
+}
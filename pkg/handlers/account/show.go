@@ -0,0 +1,23 @@
+package account
+
+import (
+	"net/http"
+
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/handlers"
+)
+
+// ShowHandler handles GET requests for the page offering the data download and import.
+func ShowHandler(applicationContext *config.ApplicationContext) http.HandlerFunc {
+	baseHttpHandler := handlers.NewBaseHttpHandler(applicationContext, config.ResourceNameAccount, config.WebAccount)
+	return func(responseWriter http.ResponseWriter, request *http.Request) {
+		if !baseHttpHandler.ValidateHttpMethod(responseWriter, request, http.MethodGet) {
+			return
+		}
+		if request.URL.Path != config.WebAccount {
+			http.NotFound(responseWriter, request)
+			return
+		}
+		renderAccountPage(&baseHttpHandler, responseWriter, request, nil)
+	}
+}
@@ -0,0 +1,45 @@
+// Package admin contains handlers for installation-wide administrative endpoints.
+package admin
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/temirov/RSVP/pkg/backup"
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/handlers"
+	"github.com/temirov/RSVP/pkg/utils"
+)
+
+// BackupsHandler lists backups on GET and takes a new online backup on POST. Responses are JSON.
+func BackupsHandler(applicationContext *config.ApplicationContext, backupManager *backup.Manager) http.HandlerFunc {
+	baseHttpHandler := handlers.NewBaseHttpHandler(applicationContext, config.ResourceNameBackup, config.WebAdminBackups)
+	return func(httpResponseWriter http.ResponseWriter, httpRequest *http.Request) {
+		if !baseHttpHandler.ValidateHttpMethod(httpResponseWriter, httpRequest, http.MethodGet, http.MethodPost) {
+			return
+		}
+		if httpRequest.Method == http.MethodGet {
+			existingBackups, listError := backupManager.List()
+			if listError != nil {
+				baseHttpHandler.HandleError(httpResponseWriter, httpRequest, listError, utils.ServerError, "Failed to list backups.")
+				return
+			}
+			if existingBackups == nil {
+				existingBackups = []backup.Info{}
+			}
+			baseHttpHandler.WriteJSON(httpResponseWriter, httpRequest, http.StatusOK, existingBackups)
+			return
+		}
+
+		createdBackup, createError := backupManager.Create()
+		if errors.Is(createError, backup.ErrUnsupportedDriver) {
+			baseHttpHandler.HandleError(httpResponseWriter, httpRequest, createError, utils.ValidationError, createError.Error())
+			return
+		}
+		if createError != nil {
+			baseHttpHandler.HandleError(httpResponseWriter, httpRequest, createError, utils.ServerError, "Failed to create backup.")
+			return
+		}
+		baseHttpHandler.WriteJSON(httpResponseWriter, httpRequest, http.StatusCreated, createdBackup)
+	}
+}
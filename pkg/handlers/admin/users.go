@@ -0,0 +1,129 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/temirov/RSVP/models"
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/handlers"
+	"github.com/temirov/RSVP/pkg/logging"
+	"github.com/temirov/RSVP/pkg/middleware"
+	"github.com/temirov/RSVP/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// UsersViewData is passed to the "users" view template.
+type UsersViewData struct {
+	UserList []UserListEntry
+	// SignInRules describes, one line each, who the sign-in rules admit.
+	SignInRules             []string
+	UsersLabel              string
+	URLForUserActions       string
+	ParamNameMethodOverride string
+	ParamNameUserID         string
+	ParamNameUserSuspended  string
+}
+
+// UserListEntry is a user as listed on the users page.
+type UserListEntry struct {
+	models.User
+	// IsAdmin marks administrators, who cannot be suspended.
+	IsAdmin bool
+}
+
+// ListUsersHandler renders the page listing every user with their last sign-in and suspension state.
+func ListUsersHandler(applicationContext *config.ApplicationContext, accessConfig config.AccessConfig, adminEmails []string) http.HandlerFunc {
+	baseHttpHandler := handlers.NewBaseHttpHandler(applicationContext, config.ResourceNameUser, config.WebAdminUsers)
+	return func(responseWriter http.ResponseWriter, request *http.Request) {
+		if !baseHttpHandler.ValidateHttpMethod(responseWriter, request, http.MethodGet) {
+			return
+		}
+		var userRecords []models.User
+		if err := applicationContext.Database.WithContext(request.Context()).Order("email").Find(&userRecords).Error; err != nil {
+			baseHttpHandler.HandleError(responseWriter, request, err, utils.DatabaseError, "Failed to retrieve users.")
+			return
+		}
+		userList := make([]UserListEntry, 0, len(userRecords))
+		for userIndex := range userRecords {
+			userList = append(userList, UserListEntry{
+				User:    userRecords[userIndex],
+				IsAdmin: middleware.IsAdmin(&userRecords[userIndex], adminEmails),
+			})
+		}
+		viewData := UsersViewData{
+			UserList:                userList,
+			SignInRules:             describeSignInRules(accessConfig),
+			UsersLabel:              config.ResourceLabelUsers,
+			URLForUserActions:       config.WebAdminUsers,
+			ParamNameMethodOverride: config.MethodOverrideParam,
+			ParamNameUserID:         config.UserIDParam,
+			ParamNameUserSuspended:  config.UserSuspendedParam,
+		}
+		baseHttpHandler.RenderView(responseWriter, request, config.TemplateUsers, viewData)
+	}
+}
+
+// SuspendUserHandler handles PUT requests (or POST with _method=PUT override) that suspend a user or reinstate
+// them, depending on the suspended parameter. Administrators cannot be suspended.
+func SuspendUserHandler(applicationContext *config.ApplicationContext, adminEmails []string) http.HandlerFunc {
+	baseHttpHandler := handlers.NewBaseHttpHandler(applicationContext, config.ResourceNameUser, config.WebAdminUsers)
+	return func(responseWriter http.ResponseWriter, request *http.Request) {
+		if !baseHttpHandler.ValidateHttpMethod(responseWriter, request, http.MethodPut, http.MethodPatch) {
+			return
+		}
+		params, paramsOk := baseHttpHandler.RequireParams(responseWriter, request, config.UserIDParam, config.UserSuspendedParam)
+		if !paramsOk {
+			return
+		}
+		suspended, parseError := strconv.ParseBool(params[config.UserSuspendedParam])
+		if parseError != nil {
+			baseHttpHandler.HandleError(responseWriter, request, parseError, utils.ValidationError, "Invalid value for "+config.UserSuspendedParam+".")
+			return
+		}
+
+		var targetUser models.User
+		if findError := targetUser.FindByID(applicationContext.Database.WithContext(request.Context()), params[config.UserIDParam]); findError != nil {
+			if errors.Is(findError, gorm.ErrRecordNotFound) {
+				baseHttpHandler.HandleError(responseWriter, request, findError, utils.NotFoundError, "User not found.")
+			} else {
+				baseHttpHandler.HandleError(responseWriter, request, findError, utils.DatabaseError, "Error retrieving user.")
+			}
+			return
+		}
+		if suspended && middleware.IsAdmin(&targetUser, adminEmails) {
+			baseHttpHandler.HandleError(responseWriter, request, nil, utils.ValidationError, "Administrators cannot be suspended. Remove the address from ADMIN_EMAILS first.")
+			return
+		}
+		if err := targetUser.SetSuspended(applicationContext.Database.WithContext(request.Context()), suspended); err != nil {
+			baseHttpHandler.HandleError(responseWriter, request, err, utils.DatabaseError, "Failed to update the user.")
+			return
+		}
+		if suspended {
+			logging.FromContext(request.Context()).Info("User suspended by an administrator", "target_user_id", targetUser.ID)
+		} else {
+			logging.FromContext(request.Context()).Info("User reinstated by an administrator", "target_user_id", targetUser.ID)
+		}
+		baseHttpHandler.RedirectToList(responseWriter, request)
+	}
+}
+
+// describeSignInRules lists who may sign in under the configured rules.
+func describeSignInRules(accessConfig config.AccessConfig) []string {
+	if !accessConfig.IsRestricted() {
+		return []string{"Anyone who can sign in with an enabled provider."}
+	}
+	signInRules := []string{"Administrators listed in ADMIN_EMAILS."}
+	if len(accessConfig.AllowedDomains) > 0 {
+		signInRules = append(signInRules, "Addresses at "+strings.Join(accessConfig.AllowedDomains, ", ")+".")
+	}
+	if len(accessConfig.AllowedEmails) > 0 {
+		signInRules = append(signInRules, "The addresses "+strings.Join(accessConfig.AllowedEmails, ", ")+".")
+	}
+	if accessConfig.InviteOnly {
+		signInRules = append(signInRules, "Addresses that already have an account or a pending co-host or organization invitation.")
+	}
+	return signInRules
+}
@@ -3,24 +3,36 @@
 package handlers
 
 import (
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"strings"
 
 	gconstants "github.com/temirov/GAuss/pkg/constants"
 	"github.com/temirov/GAuss/pkg/session"
+	"github.com/temirov/RSVP/models"
 	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/logging"
+	"github.com/temirov/RSVP/pkg/middleware"
 	"github.com/temirov/RSVP/pkg/templates"
+	"github.com/temirov/RSVP/pkg/tracing"
 	"github.com/temirov/RSVP/pkg/utils"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // PageData is the top-level wrapper struct passed to the layout template execution.
 // It contains common data needed by the layout (like user info, CSRF token, common URLs)
 // and view-specific data (Data field). It now also includes header navigation fields.
 type PageData struct {
-	IsPublicPage        bool
-	UserName            string
-	UserPicture         string
-	CSRFToken           string
+	IsPublicPage       bool
+	UserName           string
+	UserPicture        string
+	CSRFToken          string
+	ParamNameCSRFToken string
+	// CSPNonce must be set on every inline script and style block, or the Content Security Policy blocks it.
+	CSPNonce            string
 	URLForLogout        string
 	URLForRoot          string
 	Data                interface{}
@@ -29,9 +41,33 @@ type PageData struct {
 	URLForEventsManager string
 	VenueManagerLabel   string
 	URLForVenueManager  string
+	TokenManagerLabel   string
+	URLForTokenManager  string
+	TrashLabel          string
+	URLForTrash         string
+	AccountLabel        string
+	URLForAccount       string
+	OrganizationsLabel  string
+	URLForOrganizations string
+	TransfersLabel      string
+	URLForTransfers     string
 	LabelWelcome        string
 	LabelSignOut        string
 	LabelNotSignedIn    string
+	// DevAuthLabel is set in development sign-in mode and shown on every page.
+	DevAuthLabel string
+	// Workspaces lists the personal workspace followed by the user's organizations for the header switcher.
+	Workspaces            []WorkspaceOption
+	ActiveWorkspaceName   string
+	URLForWorkspaceSwitch string
+	ParamNameWorkspaceID  string
+}
+
+// WorkspaceOption is one entry of the header's workspace switcher. The personal workspace has an empty ID.
+type WorkspaceOption struct {
+	ID       string
+	Name     string
+	IsActive bool
 }
 
 // LoggedUserData holds essential user information retrieved from the session.
@@ -93,35 +129,26 @@ func (handler *BaseHttpHandler) ValidateHttpMethod(responseWriter http.ResponseW
 			return true
 		}
 	}
-	handler.ApplicationContext.Logger.Printf("Method Not Allowed: Received %s, Expected %v for %s", currentMethod, allowedMethods, request.URL.Path)
+	logging.FromContext(request.Context()).Warn("Method not allowed", "method", currentMethod, "allowed", allowedMethods, "path", request.URL.Path)
 	http.Error(responseWriter, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
 	return false
 }
 
-// VerifyResourceOwnership checks if the currently authenticated user (currentUserID) owns the resource
-// identified by the resourceOwnerID.
-// It handles errors related to missing IDs or ownership mismatch by sending appropriate
-// HTTP error responses (400, 403) using HandleError.
-// Returns true if ownership is verified, false otherwise (response is sent).
-func (handler *BaseHttpHandler) VerifyResourceOwnership(responseWriter http.ResponseWriter, request *http.Request, resourceOwnerID string, currentUserID string) bool {
-	if resourceOwnerID == "" {
-		handler.ApplicationContext.Logger.Printf("Ownership check failed: Resource owner ID is missing for %s on path %s.", handler.ResourceNameForLogging, request.URL.Path)
-		handler.HandleError(responseWriter, nil, utils.ServerError, "Internal Server Error: Cannot verify ownership.")
-		return false
+// AuthorizeEventAccess checks that the user holds a role on the event that grants the permission,
+// either as its owner or as an accepted co-host.
+// It sends a 403 Forbidden response (or a 500 if the membership lookup fails) and returns an empty role
+// when access is denied. On success it returns the user's role on the event.
+func (handler *BaseHttpHandler) AuthorizeEventAccess(responseWriter http.ResponseWriter, request *http.Request, eventRecord *models.Event, currentUserID string, permission models.EventPermission) string {
+	eventRole, roleError := models.EventRoleForUser(handler.ApplicationContext.Database.WithContext(request.Context()), eventRecord, currentUserID)
+	if roleError != nil {
+		handler.HandleError(responseWriter, request, roleError, utils.DatabaseError, "Could not verify event permissions.")
+		return ""
 	}
-	if currentUserID == "" {
-		handler.ApplicationContext.Logger.Printf("Ownership check failed: Current user ID is missing for %s on path %s.", handler.ResourceNameForLogging, request.URL.Path)
-		handler.HandleError(responseWriter, nil, utils.ServerError, "Internal Server Error: Cannot verify ownership.")
-		return false
+	if !models.RoleAllows(eventRole, permission) {
+		handler.HandleError(responseWriter, request, fmt.Errorf("user %s with role %q on event %s", currentUserID, eventRole, eventRecord.ID), utils.ForbiddenError, "Forbidden: You do not have permission to access this "+handler.ResourceNameForLogging+".")
+		return ""
 	}
-
-	if resourceOwnerID != currentUserID {
-		handler.ApplicationContext.Logger.Printf("Forbidden: User %s attempted action on %s owned by %s at path %s", currentUserID, handler.ResourceNameForLogging, resourceOwnerID, request.URL.Path)
-		handler.HandleError(responseWriter, nil, utils.ForbiddenError, "Forbidden: You do not have permission to access this "+handler.ResourceNameForLogging+".")
-		return false
-	}
-
-	return true
+	return eventRole
 }
 
 // GetParam retrieves a single named parameter from the request, checking both URL query and form values by default.
@@ -151,7 +178,7 @@ func (handler *BaseHttpHandler) RequireParams(responseWriter http.ResponseWriter
 
 	if len(missingParameters) > 0 {
 		errorMessage := "Missing required parameter(s): " + strings.Join(missingParameters, ", ")
-		handler.HandleError(responseWriter, nil, utils.ValidationError, errorMessage)
+		handler.HandleError(responseWriter, request, nil, utils.ValidationError, errorMessage)
 		return parameters, false
 	}
 
@@ -172,64 +199,119 @@ func (handler *BaseHttpHandler) RedirectWithParams(responseWriter http.ResponseW
 }
 
 // HandleError provides consistent error logging and HTTP response generation based on the error type.
-// It delegates the core logic to utils.HandleError, passing the handler's logger.
-func (handler *BaseHttpHandler) HandleError(responseWriter http.ResponseWriter, err error, errorType utils.ErrorType, userMessage string) {
-	utils.HandleError(responseWriter, err, errorType, handler.ApplicationContext.Logger, userMessage)
+// It delegates the core logic to utils.HandleError, which logs with the request's logger.
+func (handler *BaseHttpHandler) HandleError(responseWriter http.ResponseWriter, request *http.Request, err error, errorType utils.ErrorType, userMessage string) {
+	utils.HandleError(responseWriter, request, err, errorType, userMessage)
+}
+
+// WriteJSON encodes payload as a JSON response body with the given status code.
+func (handler *BaseHttpHandler) WriteJSON(httpResponseWriter http.ResponseWriter, httpRequest *http.Request, statusCode int, payload interface{}) {
+	httpResponseWriter.Header().Set("Content-Type", "application/json")
+	httpResponseWriter.WriteHeader(statusCode)
+	if encodeError := json.NewEncoder(httpResponseWriter).Encode(payload); encodeError != nil {
+		logging.FromContext(httpRequest.Context()).Error("Failed to write JSON response", "resource", handler.ResourceNameForLogging, "error", encodeError)
+	}
+}
+
+// addWorkspaceSwitcher fills the header's workspace switcher with the personal workspace and the organizations
+// the current user belongs to, marking the active one.
+func (handler *BaseHttpHandler) addWorkspaceSwitcher(httpRequest *http.Request, pageData *PageData) {
+	currentUser, _ := httpRequest.Context().Value(middleware.ContextKeyUser).(*models.User)
+	if currentUser == nil {
+		return
+	}
+	activeWorkspaceID := ""
+	if activeOrganization := middleware.WorkspaceFromContext(httpRequest.Context()); activeOrganization != nil {
+		activeWorkspaceID = activeOrganization.ID
+	}
+	memberOrganizations, err := models.FindOrganizationsForUser(handler.ApplicationContext.Database.WithContext(httpRequest.Context()), currentUser.ID)
+	if err != nil {
+		logging.FromContext(httpRequest.Context()).Warn("Failed to load organizations for the workspace switcher", "error", err)
+	}
+	pageData.URLForWorkspaceSwitch = config.WebWorkspace
+	pageData.ParamNameWorkspaceID = config.WorkspaceIDParam
+	pageData.ActiveWorkspaceName = config.LabelPersonalWorkspace
+	pageData.Workspaces = []WorkspaceOption{{Name: config.LabelPersonalWorkspace, IsActive: activeWorkspaceID == ""}}
+	for _, memberOrganization := range memberOrganizations {
+		isActive := memberOrganization.ID == activeWorkspaceID
+		if isActive {
+			pageData.ActiveWorkspaceName = memberOrganization.Name
+		}
+		pageData.Workspaces = append(pageData.Workspaces, WorkspaceOption{ID: memberOrganization.ID, Name: memberOrganization.Name, IsActive: isActive})
+	}
 }
 
 // RenderView renders the specified view template using the main application layout.
 // It prepares the PageData struct, including user information for non-public pages and header navigation data,
 // retrieves the precompiled template set from templates.PrecompiledTemplatesMap,
-// and executes the "layout" template, passing PageData as the context.
+// and executes the "layout" template, passing PageData as the context. The rendering is traced as its own span.
 func (handler *BaseHttpHandler) RenderView(
 	httpResponseWriter http.ResponseWriter,
 	httpRequest *http.Request,
 	viewName string,
 	viewSpecificData interface{},
 ) {
+	renderContext, renderSpan := tracing.Tracer().Start(httpRequest.Context(), "render "+viewName,
+		trace.WithAttributes(attribute.String("template.view", viewName)))
+	defer renderSpan.End()
+	httpRequest = httpRequest.WithContext(renderContext)
+
 	publicViews := map[string]bool{
 		config.TemplateResponse: true,
 		config.TemplateThankYou: true,
 		config.TemplateLanding:  true,
+		config.TemplateForgery:  true,
 	}
 	isPublicPage := publicViews[viewName]
 
 	pageData := PageData{
 		IsPublicPage:        isPublicPage,
 		Data:                viewSpecificData,
-		URLForLogout:        gconstants.LogoutPath,
+		CSRFToken:           middleware.CSRFTokenFromContext(httpRequest.Context()),
+		ParamNameCSRFToken:  config.CSRFTokenParam,
+		CSPNonce:            middleware.CSPNonceFromContext(httpRequest.Context()),
+		URLForLogout:        config.WebLogout,
 		URLForRoot:          config.WebRoot,
-		AppTitle:            config.AppTitle,
+		AppTitle:            handler.ApplicationContext.AppTitle,
 		EventsManagerLabel:  config.ResourceLabelEventManager,
 		URLForEventsManager: config.WebEvents,
 		VenueManagerLabel:   config.ResourceLabelVenueManager,
 		URLForVenueManager:  config.WebVenues,
+		TokenManagerLabel:   config.ResourceLabelTokenManager,
+		URLForTokenManager:  config.WebTokens,
+		TrashLabel:          config.ResourceLabelTrash,
+		URLForTrash:         config.WebTrash,
+		AccountLabel:        config.ResourceLabelAccount,
+		URLForAccount:       config.WebAccount,
+		OrganizationsLabel:  config.ResourceLabelOrgs,
+		URLForOrganizations: config.WebOrganizations,
+		TransfersLabel:      config.ResourceLabelTransfers,
+		URLForTransfers:     config.WebTransfers,
 		LabelWelcome:        config.LabelWelcome,
 		LabelSignOut:        config.LabelSignOut,
 		LabelNotSignedIn:    config.LabelNotSignedIn,
 	}
+	if handler.ApplicationContext.DevAuth {
+		pageData.DevAuthLabel = config.LabelDevAuth
+	}
 	if !isPublicPage {
 		loggedUserData := handler.GetUserSessionData(httpRequest)
 		pageData.UserName = loggedUserData.UserName
 		pageData.UserPicture = loggedUserData.UserPicture
 		if pageData.UserName == "" && pageData.UserPicture == "" {
-			handler.ApplicationContext.Logger.Printf("WARN: Rendering non-public view '%s' but user session data (Name/Picture) seems incomplete for %s.", viewName, httpRequest.URL.Path)
+			logging.FromContext(httpRequest.Context()).Warn("Rendering a non-public view but the session has no user name or picture", "view", viewName, "path", httpRequest.URL.Path)
 		}
+		handler.addWorkspaceSwitcher(httpRequest, &pageData)
 	}
 	templateSet, exists := templates.PrecompiledTemplatesMap[viewName]
 	if !exists {
-		if viewName != config.TemplateLanding {
-			handler.ApplicationContext.Logger.Printf("WARN: Template set for view '%s' not found in PrecompiledTemplatesMap. Attempting to render landing page.", viewName)
-			handler.HandleError(httpResponseWriter, nil, utils.ServerError, utils.ErrMsgInternalServer)
-			return
-		}
-		handler.ApplicationContext.Logger.Printf("CRITICAL: Template set for view '%s' not found in PrecompiledTemplatesMap.", viewName)
-		handler.HandleError(httpResponseWriter, nil, utils.ServerError, utils.ErrMsgInternalServer)
+		handler.HandleError(httpResponseWriter, httpRequest, fmt.Errorf("template set for view %q not found", viewName), utils.ServerError, utils.ErrMsgInternalServer)
 		return
-
 	}
 	executionError := templateSet.ExecuteTemplate(httpResponseWriter, config.TemplateLayout, pageData)
 	if executionError != nil {
-		handler.ApplicationContext.Logger.Printf("ERROR: Failed to execute layout template for view '%s' (Resource: %s, Path: %s): %v", viewName, handler.ResourceNameForLogging, httpRequest.URL.Path, executionError)
+		renderSpan.RecordError(executionError)
+		renderSpan.SetStatus(codes.Error, "template execution failed")
+		logging.FromContext(httpRequest.Context()).Error("Failed to execute the layout template", "view", viewName, "resource", handler.ResourceNameForLogging, "path", httpRequest.URL.Path, "error", executionError)
 	}
 }
@@ -0,0 +1,61 @@
+// Package cohost provides HTTP handlers for sharing an event with co-hosts: listing, inviting and removing them.
+package cohost
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/temirov/RSVP/models"
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/handlers"
+	"github.com/temirov/RSVP/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// ListViewData is passed to the "cohosts" view template.
+type ListViewData struct {
+	Event                   models.Event
+	EventOwner              models.User
+	Memberships             []models.EventMembership
+	CurrentUserID           string
+	EventRole               string
+	CanManageCohosts        bool
+	URLForCohostActions     string
+	URLForEventList         string
+	ParamNameEventID        string
+	ParamNameMembershipID   string
+	ParamNameCohostEmail    string
+	ParamNameCohostRole     string
+	ParamNameMethodOverride string
+	RoleEditor              string
+	RoleViewer              string
+	RoleCheckIn             string
+}
+
+// loadAuthorizedEvent reads the event_id parameter, loads the event and checks the user's permission on it.
+// It returns the event and the user's role, or false if a response has already been sent.
+func loadAuthorizedEvent(baseHttpHandler *handlers.BaseHttpHandler, responseWriter http.ResponseWriter, request *http.Request, currentUserID string, permission models.EventPermission) (*models.Event, string, bool) {
+	params, paramsOk := baseHttpHandler.RequireParams(responseWriter, request, config.EventIDParam)
+	if !paramsOk {
+		return nil, "", false
+	}
+	var sharedEvent models.Event
+	if findError := sharedEvent.FindByID(baseHttpHandler.ApplicationContext.Database.WithContext(request.Context()), params[config.EventIDParam]); findError != nil {
+		if errors.Is(findError, gorm.ErrRecordNotFound) {
+			baseHttpHandler.HandleError(responseWriter, request, findError, utils.NotFoundError, config.ErrMsgEventNotFound)
+		} else {
+			baseHttpHandler.HandleError(responseWriter, request, findError, utils.DatabaseError, "Error retrieving event details.")
+		}
+		return nil, "", false
+	}
+	eventRole := baseHttpHandler.AuthorizeEventAccess(responseWriter, request, &sharedEvent, currentUserID, permission)
+	if eventRole == "" {
+		return nil, "", false
+	}
+	return &sharedEvent, eventRole, true
+}
+
+// redirectToEventCohosts sends the user back to the co-host list of the event.
+func redirectToEventCohosts(baseHttpHandler *handlers.BaseHttpHandler, responseWriter http.ResponseWriter, request *http.Request, eventIdentifier string) {
+	baseHttpHandler.RedirectWithParams(responseWriter, request, map[string]string{config.EventIDParam: eventIdentifier})
+}
@@ -0,0 +1,57 @@
+package cohost
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/temirov/RSVP/models"
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/handlers"
+	"github.com/temirov/RSVP/pkg/logging"
+	"github.com/temirov/RSVP/pkg/middleware"
+	"github.com/temirov/RSVP/pkg/utils"
+)
+
+// InviteHandler handles POST requests that invite a co-host to an event by email address.
+// The invitation is accepted automatically the next time a user with that address signs in.
+func InviteHandler(applicationContext *config.ApplicationContext) http.HandlerFunc {
+	baseHttpHandler := handlers.NewBaseHttpHandler(applicationContext, config.ResourceNameCohost, config.WebEventCohosts)
+	return func(responseWriter http.ResponseWriter, request *http.Request) {
+		if !baseHttpHandler.ValidateHttpMethod(responseWriter, request, http.MethodPost) {
+			return
+		}
+		currentUser := request.Context().Value(middleware.ContextKeyUser).(*models.User)
+		sharedEvent, _, eventOk := loadAuthorizedEvent(&baseHttpHandler, responseWriter, request, currentUser.ID, models.PermissionManageCohosts)
+		if !eventOk {
+			return
+		}
+
+		params, paramsOk := baseHttpHandler.RequireParams(responseWriter, request, config.CohostEmailParam, config.CohostRoleParam)
+		if !paramsOk {
+			return
+		}
+		invitedEmail := models.NormalizeMemberEmail(params[config.CohostEmailParam])
+		if validationError := utils.ValidateCohostEmail(invitedEmail); validationError != nil {
+			baseHttpHandler.HandleError(responseWriter, request, validationError, utils.ValidationError, validationError.Error())
+			return
+		}
+		cohostRole := params[config.CohostRoleParam]
+		if validationError := utils.ValidateCohostRole(cohostRole); validationError != nil {
+			baseHttpHandler.HandleError(responseWriter, request, validationError, utils.ValidationError, validationError.Error())
+			return
+		}
+
+		newMembership, inviteError := models.InviteCohost(applicationContext.Database.WithContext(request.Context()), sharedEvent, invitedEmail, cohostRole, currentUser.ID)
+		if inviteError != nil {
+			if errors.Is(inviteError, models.ErrCohostIsOwner) {
+				baseHttpHandler.HandleError(responseWriter, request, inviteError, utils.ValidationError, inviteError.Error())
+			} else {
+				baseHttpHandler.HandleError(responseWriter, request, inviteError, utils.DatabaseError, "Failed to invite the co-host.")
+			}
+			return
+		}
+		logging.FromContext(request.Context()).Info("Co-host invited", "email", invitedEmail, "role", cohostRole, "event_id", sharedEvent.ID, "membership_id", newMembership.ID)
+
+		redirectToEventCohosts(&baseHttpHandler, responseWriter, request, sharedEvent.ID)
+	}
+}
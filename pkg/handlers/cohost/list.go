@@ -0,0 +1,58 @@
+package cohost
+
+import (
+	"net/http"
+
+	"github.com/temirov/RSVP/models"
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/handlers"
+	"github.com/temirov/RSVP/pkg/middleware"
+	"github.com/temirov/RSVP/pkg/utils"
+)
+
+// ListHandler handles GET requests for the co-host page of an event (/events/cohosts/).
+// Every member of the event may see who else has access; only the owner may change it.
+func ListHandler(applicationContext *config.ApplicationContext) http.HandlerFunc {
+	baseHttpHandler := handlers.NewBaseHttpHandler(applicationContext, config.ResourceNameCohost, config.WebEventCohosts)
+	return func(responseWriter http.ResponseWriter, request *http.Request) {
+		if !baseHttpHandler.ValidateHttpMethod(responseWriter, request, http.MethodGet) {
+			return
+		}
+		currentUser := request.Context().Value(middleware.ContextKeyUser).(*models.User)
+		sharedEvent, eventRole, eventOk := loadAuthorizedEvent(&baseHttpHandler, responseWriter, request, currentUser.ID, models.PermissionViewEvent)
+		if !eventOk {
+			return
+		}
+
+		var eventOwner models.User
+		if err := eventOwner.FindByID(applicationContext.Database.WithContext(request.Context()), sharedEvent.UserID); err != nil {
+			baseHttpHandler.HandleError(responseWriter, request, err, utils.DatabaseError, "Failed to retrieve the event owner.")
+			return
+		}
+		eventMemberships, err := models.FindMembershipsByEventID(applicationContext.Database.WithContext(request.Context()), sharedEvent.ID)
+		if err != nil {
+			baseHttpHandler.HandleError(responseWriter, request, err, utils.DatabaseError, "Failed to retrieve co-hosts.")
+			return
+		}
+
+		viewData := ListViewData{
+			Event:                   *sharedEvent,
+			EventOwner:              eventOwner,
+			Memberships:             eventMemberships,
+			CurrentUserID:           currentUser.ID,
+			EventRole:               eventRole,
+			CanManageCohosts:        models.RoleAllows(eventRole, models.PermissionManageCohosts),
+			URLForCohostActions:     config.WebEventCohosts,
+			URLForEventList:         config.WebEvents,
+			ParamNameEventID:        config.EventIDParam,
+			ParamNameMembershipID:   config.MembershipIDParam,
+			ParamNameCohostEmail:    config.CohostEmailParam,
+			ParamNameCohostRole:     config.CohostRoleParam,
+			ParamNameMethodOverride: config.MethodOverrideParam,
+			RoleEditor:              config.EventRoleEditor,
+			RoleViewer:              config.EventRoleViewer,
+			RoleCheckIn:             config.EventRoleCheckIn,
+		}
+		baseHttpHandler.RenderView(responseWriter, request, config.TemplateCohosts, viewData)
+	}
+}
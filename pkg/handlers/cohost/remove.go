@@ -0,0 +1,61 @@
+package cohost
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/temirov/RSVP/models"
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/handlers"
+	"github.com/temirov/RSVP/pkg/logging"
+	"github.com/temirov/RSVP/pkg/middleware"
+	"github.com/temirov/RSVP/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// RemoveHandler handles DELETE requests that revoke a co-host's access or withdraw a pending invitation.
+// The owner may remove anyone; a co-host may remove only their own membership, leaving the event.
+func RemoveHandler(applicationContext *config.ApplicationContext) http.HandlerFunc {
+	baseHttpHandler := handlers.NewBaseHttpHandler(applicationContext, config.ResourceNameCohost, config.WebEventCohosts)
+	return func(responseWriter http.ResponseWriter, request *http.Request) {
+		if !baseHttpHandler.ValidateHttpMethod(responseWriter, request, http.MethodDelete) {
+			return
+		}
+		currentUser := request.Context().Value(middleware.ContextKeyUser).(*models.User)
+		sharedEvent, eventRole, eventOk := loadAuthorizedEvent(&baseHttpHandler, responseWriter, request, currentUser.ID, models.PermissionViewEvent)
+		if !eventOk {
+			return
+		}
+		params, paramsOk := baseHttpHandler.RequireParams(responseWriter, request, config.MembershipIDParam)
+		if !paramsOk {
+			return
+		}
+
+		var eventMembership models.EventMembership
+		if findError := eventMembership.FindByIDAndEvent(applicationContext.Database.WithContext(request.Context()), params[config.MembershipIDParam], sharedEvent.ID); findError != nil {
+			if errors.Is(findError, gorm.ErrRecordNotFound) {
+				baseHttpHandler.HandleError(responseWriter, request, findError, utils.NotFoundError, "Co-host not found.")
+			} else {
+				baseHttpHandler.HandleError(responseWriter, request, findError, utils.DatabaseError, "Error retrieving co-host.")
+			}
+			return
+		}
+		isLeaving := eventMembership.BelongsTo(currentUser.ID)
+		if !isLeaving && !models.RoleAllows(eventRole, models.PermissionManageCohosts) {
+			baseHttpHandler.HandleError(responseWriter, request, nil, utils.ForbiddenError, "Forbidden: Only the event owner can remove other co-hosts.")
+			return
+		}
+
+		if removeError := eventMembership.Remove(applicationContext.Database.WithContext(request.Context())); removeError != nil {
+			baseHttpHandler.HandleError(responseWriter, request, removeError, utils.DatabaseError, "Failed to remove the co-host.")
+			return
+		}
+		logging.FromContext(request.Context()).Info("Co-host removed", "email", eventMembership.InvitedEmail, "event_id", sharedEvent.ID, "membership_id", eventMembership.ID)
+
+		if isLeaving {
+			http.Redirect(responseWriter, request, config.WebEvents, http.StatusSeeOther)
+			return
+		}
+		redirectToEventCohosts(&baseHttpHandler, responseWriter, request, sharedEvent.ID)
+	}
+}
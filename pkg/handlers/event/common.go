@@ -2,9 +2,12 @@
 package event
 
 import (
+	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/temirov/RSVP/models"
+	"github.com/temirov/RSVP/pkg/config"
 )
 
 // StatisticsData holds event statistics.
@@ -16,6 +19,15 @@ type StatisticsData struct {
 	VenueName         string
 	RSVPCount         int
 	RSVPAnsweredCount int
+	RSVPOpenedCount   int
+	// Role is the current user's role on the event; IsShared marks events owned by someone else.
+	Role     string
+	IsShared bool
+	CanEdit  bool
+	// CanTransfer marks personal events the current user owns and may offer to another user.
+	CanTransfer bool
+	// AcceptBareCodes marks events that still accept invitation links carrying only the RSVP code.
+	AcceptBareCodes bool
 }
 
 // EnhancedEventData holds an event together with derived values.
@@ -23,6 +35,7 @@ type EnhancedEventData struct {
 	Event                     models.Event
 	CalculatedDurationInHours float64
 	SelectedVenueID           string
+	CanDelete                 bool
 }
 
 // ListViewData is passed to the main “events” view template.
@@ -38,19 +51,28 @@ type ListViewData struct {
 	URLForRSVPListBase string
 	URLForRSVPManager  string
 	URLForVenues       string
+	URLForEventsStream string
+	URLForCohosts      string
+	URLForTransfers    string
 
 	/* event & venue data */
 	EventList           []StatisticsData
 	SelectedItemForEdit *EnhancedEventData
 	UserReusedVenues    []models.Venue
+	Funnel              *FunnelData
 
 	/* form/input helpers */
 	ParamNameEventID          string
+	ParamNameFunnelEventID    string
+	ParamNameTransferType     string
+	ParamNameTransferItemID   string
+	TransferResourceType      string
 	ParamNameVenueID          string
 	ParamNameTitle            string
 	ParamNameDescription      string
 	ParamNameStartTime        string
 	ParamNameDuration         string
+	ParamNameAcceptBareCodes  string
 	ParamNameMethodOverride   string
 	ParamNameVenueName        string
 	ParamNameVenueAddress     string
@@ -65,6 +87,7 @@ type ListViewData struct {
 	LabelEventDescription string
 	LabelStartTime        string
 	LabelDuration         string
+	LabelAcceptBareCodes  string
 	LabelSelectVenue      string
 	LabelAddVenue         string
 	LabelVenueDetails     string
@@ -97,3 +120,14 @@ type EditViewData struct {
 	ActionAddExistingVenue string
 	ActionCreateNewVenue   string
 }
+
+// acceptBareCodesFromForm reads the bare code opt-in of the event form. The form sends a hidden "false" before the
+// checkbox, so the last value wins; the second result is false when the request does not set the option at all.
+func acceptBareCodesFromForm(httpRequest *http.Request) (bool, bool) {
+	submittedValues := httpRequest.Form[config.AcceptBareCodesParam]
+	if len(submittedValues) == 0 {
+		return false, false
+	}
+	acceptBareCodes, _ := strconv.ParseBool(submittedValues[len(submittedValues)-1])
+	return acceptBareCodes, true
+}
@@ -27,7 +27,7 @@ func CreateHandler(applicationContext *config.ApplicationContext) http.HandlerFu
 			return
 		}
 		if err := httpRequest.ParseForm(); err != nil {
-			baseHttpHandler.HandleError(httpResponseWriter, err, utils.ValidationError, utils.ErrMsgInvalidFormData)
+			baseHttpHandler.HandleError(httpResponseWriter, httpRequest, err, utils.ValidationError, utils.ErrMsgInvalidFormData)
 			return
 		}
 		eventTitle := httpRequest.FormValue(config.TitleParam)
@@ -39,49 +39,54 @@ func CreateHandler(applicationContext *config.ApplicationContext) http.HandlerFu
 		shouldCreateNewVenue := newVenueNameString != ""
 
 		if validationError := utils.ValidateEventTitle(eventTitle); validationError != nil {
-			baseHttpHandler.HandleError(httpResponseWriter, validationError, utils.ValidationError, validationError.Error())
+			baseHttpHandler.HandleError(httpResponseWriter, httpRequest, validationError, utils.ValidationError, validationError.Error())
 			return
 		}
 		parsedDurationHours, validationError := utils.ValidateAndParseEventDuration(durationHoursString)
 		if validationError != nil {
-			baseHttpHandler.HandleError(httpResponseWriter, validationError, utils.ValidationError, validationError.Error())
+			baseHttpHandler.HandleError(httpResponseWriter, httpRequest, validationError, utils.ValidationError, validationError.Error())
 			return
 		}
 		parsedStartTime, timeParseError := time.Parse(config.TimeLayoutHTMLForm, eventStartTimeString)
 		if timeParseError != nil {
-			baseHttpHandler.HandleError(httpResponseWriter, timeParseError, utils.ValidationError, utils.ErrMsgInvalidStartTimeFormat)
+			baseHttpHandler.HandleError(httpResponseWriter, httpRequest, timeParseError, utils.ValidationError, utils.ErrMsgInvalidStartTimeFormat)
 			return
 		}
 		if validationError := utils.ValidateEventStartTime(parsedStartTime); validationError != nil {
-			baseHttpHandler.HandleError(httpResponseWriter, validationError, utils.ValidationError, validationError.Error())
+			baseHttpHandler.HandleError(httpResponseWriter, httpRequest, validationError, utils.ValidationError, validationError.Error())
 			return
 		}
 
+		acceptBareCodes, _ := acceptBareCodesFromForm(httpRequest)
 		calculatedEndTime := parsedStartTime.Add(time.Duration(parsedDurationHours) * time.Hour)
 		currentUserIdentifier := httpRequest.Context().Value(middleware.ContextKeyUser).(*models.User).ID
+		workspaceIdentifier := middleware.WorkspaceIDFromContext(httpRequest.Context())
 
 		newEventRecord := models.Event{
-			Title:       eventTitle,
-			Description: eventDescription,
-			StartTime:   parsedStartTime,
-			EndTime:     calculatedEndTime,
-			UserID:      currentUserIdentifier,
-			VenueID:     nil,
+			Title:           eventTitle,
+			Description:     eventDescription,
+			StartTime:       parsedStartTime,
+			EndTime:         calculatedEndTime,
+			UserID:          currentUserIdentifier,
+			VenueID:         nil,
+			OrganizationID:  workspaceIdentifier,
+			AcceptBareCodes: acceptBareCodes,
 		}
 
-		transactionError := applicationContext.Database.Transaction(func(activeTransaction *gorm.DB) error {
+		transactionError := applicationContext.Database.WithContext(httpRequest.Context()).Transaction(func(activeTransaction *gorm.DB) error {
 			var venueIdentifierToAssociate *string
 
 			if shouldCreateNewVenue {
 				newVenueRecord := venueFromForm(httpRequest, createVenuePrefix)
 				newVenueRecord.UserID = currentUserIdentifier
+				newVenueRecord.OrganizationID = workspaceIdentifier
 				if err := newVenueRecord.Create(activeTransaction); err != nil {
 					return err
 				}
 				venueIdentifierToAssociate = &newVenueRecord.ID
 			} else if selectedVenueIdentifierString != "" {
 				var existingVenueRecord models.Venue
-				if err := existingVenueRecord.FindByIDAndOwner(activeTransaction, selectedVenueIdentifierString, currentUserIdentifier); err != nil {
+				if err := existingVenueRecord.FindByIDInWorkspace(activeTransaction, selectedVenueIdentifierString, currentUserIdentifier, workspaceIdentifier); err != nil {
 					if errors.Is(err, gorm.ErrRecordNotFound) {
 						return fmt.Errorf("you do not have permission to use the selected venue")
 					}
@@ -99,14 +104,15 @@ func CreateHandler(applicationContext *config.ApplicationContext) http.HandlerFu
 
 		if transactionError != nil {
 			if validationErr := isModelValidationError(transactionError); validationErr != nil {
-				baseHttpHandler.HandleError(httpResponseWriter, validationErr, utils.ValidationError, validationErr.Error())
+				baseHttpHandler.HandleError(httpResponseWriter, httpRequest, validationErr, utils.ValidationError, validationErr.Error())
 			} else if transactionError.Error() == "you do not have permission to use the selected venue" {
-				baseHttpHandler.HandleError(httpResponseWriter, transactionError, utils.ValidationError, transactionError.Error())
+				baseHttpHandler.HandleError(httpResponseWriter, httpRequest, transactionError, utils.ValidationError, transactionError.Error())
 			} else {
-				baseHttpHandler.HandleError(httpResponseWriter, transactionError, utils.DatabaseError, "Failed to save the event and/or associated venue.")
+				baseHttpHandler.HandleError(httpResponseWriter, httpRequest, transactionError, utils.DatabaseError, "Failed to save the event and/or associated venue.")
 			}
 			return
 		}
+		applicationContext.Metrics.CountEventCreated()
 		baseHttpHandler.RedirectToList(httpResponseWriter, httpRequest)
 	}
 }
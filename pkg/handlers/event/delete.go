@@ -24,38 +24,21 @@ func DeleteHandler(applicationContext *config.ApplicationContext) http.HandlerFu
 			return
 		}
 		targetEventID := params[config.EventIDParam]
-		tx := applicationContext.Database.Begin()
-		if tx.Error != nil {
-			baseHttpHandler.HandleError(httpResponseWriter, tx.Error, utils.DatabaseError, "Failed to start database transaction.")
-			return
-		}
 		var eventRecord models.Event
-		if err := eventRecord.FindByID(tx, targetEventID); err != nil {
-			tx.Rollback()
+		if err := eventRecord.FindByID(applicationContext.Database.WithContext(httpRequest.Context()), targetEventID); err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
-				baseHttpHandler.HandleError(httpResponseWriter, err, utils.NotFoundError, "Event not found.")
+				baseHttpHandler.HandleError(httpResponseWriter, httpRequest, err, utils.NotFoundError, "Event not found.")
 			} else {
-				baseHttpHandler.HandleError(httpResponseWriter, err, utils.DatabaseError, "Error retrieving event.")
+				baseHttpHandler.HandleError(httpResponseWriter, httpRequest, err, utils.DatabaseError, "Error retrieving event.")
 			}
 			return
 		}
-		if !baseHttpHandler.VerifyResourceOwnership(httpResponseWriter, httpRequest, eventRecord.UserID, currentUser.ID) {
-			tx.Rollback()
-			return
-		}
-		if deleteRSVPsErr := tx.Where("event_id = ?", targetEventID).Delete(&models.RSVP{}).Error; deleteRSVPsErr != nil {
-			tx.Rollback()
-			baseHttpHandler.HandleError(httpResponseWriter, deleteRSVPsErr, utils.DatabaseError, "Failed to delete associated RSVPs.")
-			return
-		}
-		if deleteEventErr := tx.Delete(&eventRecord).Error; deleteEventErr != nil {
-			tx.Rollback()
-			baseHttpHandler.HandleError(httpResponseWriter, deleteEventErr, utils.DatabaseError, "Failed to delete the event.")
+		if baseHttpHandler.AuthorizeEventAccess(httpResponseWriter, httpRequest, &eventRecord, currentUser.ID, models.PermissionDeleteEvent) == "" {
 			return
 		}
-		if commitErr := tx.Commit().Error; commitErr != nil {
-			applicationContext.Logger.Printf("CRITICAL: Failed to commit transaction for event deletion %s: %v", targetEventID, commitErr)
-			baseHttpHandler.HandleError(httpResponseWriter, commitErr, utils.DatabaseError, "Failed to finalize event deletion.")
+		// The event and its RSVPs are soft-deleted together so they can be restored together from the trash.
+		if deleteError := eventRecord.DeleteWithRSVPs(applicationContext.Database.WithContext(httpRequest.Context())); deleteError != nil {
+			baseHttpHandler.HandleError(httpResponseWriter, httpRequest, deleteError, utils.DatabaseError, "Failed to delete the event.")
 			return
 		}
 		baseHttpHandler.RedirectToList(httpResponseWriter, httpRequest)
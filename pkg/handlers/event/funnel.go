@@ -0,0 +1,146 @@
+package event
+
+import (
+	"time"
+
+	"github.com/temirov/RSVP/models"
+	"github.com/temirov/RSVP/pkg/config"
+)
+
+// DistributionBucket is one bar of the time-to-respond distribution.
+type DistributionBucket struct {
+	Label   string
+	Count   int
+	Percent int
+}
+
+// DailyResponseCount is the number of responses received on one calendar day.
+type DailyResponseCount struct {
+	Day     time.Time
+	Count   int
+	Percent int
+}
+
+// FunnelData describes how invitations for one event progressed from invited to opened to responded.
+type FunnelData struct {
+	EventID          string
+	EventTitle       string
+	InvitedCount     int
+	OpenedCount      int
+	RespondedCount   int
+	OpenedPercent    int
+	RespondedPercent int
+	// ResponseTimeBuckets groups responses by the time between the RSVP being created and first answered.
+	ResponseTimeBuckets []DistributionBucket
+	// DailyResponses covers the days between the first and the last response, counted in the location of the event's
+	// start time and capped at config.FunnelMaxChartDays.
+	DailyResponses []DailyResponseCount
+	ChartStartDay  time.Time
+	ChartEndDay    time.Time
+	// UntimedResponseCount counts answered RSVPs that predate response time tracking.
+	UntimedResponseCount int
+}
+
+// responseTimeBucketLimits defines the upper bounds of the time-to-respond buckets; the last bucket is open-ended.
+var responseTimeBucketLimits = []struct {
+	Label      string
+	UpperBound time.Duration
+}{
+	{Label: "< 1 hour", UpperBound: time.Hour},
+	{Label: "1–24 hours", UpperBound: 24 * time.Hour},
+	{Label: "1–3 days", UpperBound: 3 * 24 * time.Hour},
+	{Label: "3–7 days", UpperBound: 7 * 24 * time.Hour},
+	{Label: "> 7 days", UpperBound: 0},
+}
+
+// buildFunnelData computes funnel statistics for an event from its RSVPs.
+// An RSVP counts as opened if its link was viewed or if it was answered (an answer implies the invitation reached the guest).
+func buildFunnelData(eventRecord *models.Event, eventRSVPs []models.RSVP) *FunnelData {
+	funnelData := &FunnelData{
+		EventID:             eventRecord.ID,
+		EventTitle:          eventRecord.Title,
+		InvitedCount:        len(eventRSVPs),
+		ResponseTimeBuckets: make([]DistributionBucket, len(responseTimeBucketLimits)),
+	}
+	for bucketIndex, bucketLimit := range responseTimeBucketLimits {
+		funnelData.ResponseTimeBuckets[bucketIndex].Label = bucketLimit.Label
+	}
+
+	responsesPerDay := make(map[time.Time]int)
+	var firstResponseDay, lastResponseDay time.Time
+	for _, rsvpRecord := range eventRSVPs {
+		isAnswered := rsvpRecord.IsAnswered()
+		if rsvpRecord.FirstViewedAt != nil || isAnswered {
+			funnelData.OpenedCount++
+		}
+		if !isAnswered {
+			continue
+		}
+		funnelData.RespondedCount++
+		if rsvpRecord.RespondedAt == nil {
+			funnelData.UntimedResponseCount++
+			continue
+		}
+
+		timeToRespond := rsvpRecord.RespondedAt.Sub(rsvpRecord.CreatedAt)
+		for bucketIndex, bucketLimit := range responseTimeBucketLimits {
+			if bucketLimit.UpperBound == 0 || timeToRespond < bucketLimit.UpperBound {
+				funnelData.ResponseTimeBuckets[bucketIndex].Count++
+				break
+			}
+		}
+
+		responseDay := truncateToDay(rsvpRecord.RespondedAt.In(eventRecord.StartTime.Location()))
+		responsesPerDay[responseDay]++
+		if firstResponseDay.IsZero() || responseDay.Before(firstResponseDay) {
+			firstResponseDay = responseDay
+		}
+		if responseDay.After(lastResponseDay) {
+			lastResponseDay = responseDay
+		}
+	}
+
+	funnelData.OpenedPercent = percentOf(funnelData.OpenedCount, funnelData.InvitedCount)
+	funnelData.RespondedPercent = percentOf(funnelData.RespondedCount, funnelData.InvitedCount)
+	timedResponseCount := funnelData.RespondedCount - funnelData.UntimedResponseCount
+	for bucketIndex := range funnelData.ResponseTimeBuckets {
+		funnelData.ResponseTimeBuckets[bucketIndex].Percent = percentOf(funnelData.ResponseTimeBuckets[bucketIndex].Count, timedResponseCount)
+	}
+
+	if lastResponseDay.IsZero() {
+		return funnelData
+	}
+	chartStartDay := lastResponseDay.AddDate(0, 0, -(config.FunnelMaxChartDays - 1))
+	if firstResponseDay.After(chartStartDay) {
+		chartStartDay = firstResponseDay
+	}
+	funnelData.ChartStartDay = chartStartDay
+	funnelData.ChartEndDay = lastResponseDay
+	busiestDayCount := 0
+	for chartDay := chartStartDay; !chartDay.After(lastResponseDay); chartDay = chartDay.AddDate(0, 0, 1) {
+		dayCount := responsesPerDay[chartDay]
+		funnelData.DailyResponses = append(funnelData.DailyResponses, DailyResponseCount{Day: chartDay, Count: dayCount})
+		if dayCount > busiestDayCount {
+			busiestDayCount = dayCount
+		}
+	}
+	for dayIndex := range funnelData.DailyResponses {
+		funnelData.DailyResponses[dayIndex].Percent = percentOf(funnelData.DailyResponses[dayIndex].Count, busiestDayCount)
+	}
+	return funnelData
+}
+
+// truncateToDay returns midnight of the given time's calendar day in its own location. Callers convert response
+// times to the event's location first, so that the same day always gives the same map key.
+func truncateToDay(timestamp time.Time) time.Time {
+	year, month, day := timestamp.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, timestamp.Location())
+}
+
+// percentOf returns part as a whole-number percentage of total, or 0 when total is 0.
+func percentOf(part int, total int) int {
+	if total == 0 {
+		return 0
+	}
+	return part * 100 / total
+}
@@ -0,0 +1,140 @@
+package event
+
+import (
+	"testing"
+	"time"
+
+	"github.com/temirov/RSVP/models"
+	"github.com/temirov/RSVP/pkg/config"
+)
+
+// answeredAt returns an RSVP created at createdAt and answered Yes at respondedAt.
+func answeredAt(createdAt time.Time, respondedAt time.Time) models.RSVP {
+	answeredRSVP := models.RSVP{Response: "Yes,1", RespondedAt: &respondedAt}
+	answeredRSVP.CreatedAt = createdAt
+	return answeredRSVP
+}
+
+func TestBuildFunnelDataCountsTheFunnel(t *testing.T) {
+	invitedAt := time.Date(2026, time.May, 1, 9, 0, 0, 0, time.UTC)
+	viewedAt := invitedAt.Add(time.Hour)
+	eventRecord := &models.Event{Title: "Party", StartTime: invitedAt.AddDate(0, 1, 0)}
+	untimedRSVP := models.RSVP{Response: "No"}
+	eventRSVPs := []models.RSVP{
+		{Response: config.RSVPResponsePending},
+		{Response: config.RSVPResponsePending, FirstViewedAt: &viewedAt},
+		answeredAt(invitedAt, invitedAt.Add(30*time.Minute)),
+		answeredAt(invitedAt, invitedAt.Add(2*time.Hour)),
+		answeredAt(invitedAt, invitedAt.Add(24*time.Hour)),
+		answeredAt(invitedAt, invitedAt.Add(5*24*time.Hour)),
+		answeredAt(invitedAt, invitedAt.Add(7*24*time.Hour)),
+		untimedRSVP,
+	}
+
+	funnelData := buildFunnelData(eventRecord, eventRSVPs)
+	if funnelData.InvitedCount != 8 || funnelData.OpenedCount != 7 || funnelData.RespondedCount != 6 || funnelData.UntimedResponseCount != 1 {
+		t.Fatalf("invited/opened/responded/untimed = %d/%d/%d/%d, want 8/7/6/1",
+			funnelData.InvitedCount, funnelData.OpenedCount, funnelData.RespondedCount, funnelData.UntimedResponseCount)
+	}
+	if funnelData.OpenedPercent != 87 || funnelData.RespondedPercent != 75 {
+		t.Errorf("opened/responded percent = %d/%d, want 87/75", funnelData.OpenedPercent, funnelData.RespondedPercent)
+	}
+	wantBucketCounts := []int{1, 1, 1, 1, 1}
+	for bucketIndex, responseTimeBucket := range funnelData.ResponseTimeBuckets {
+		if responseTimeBucket.Count != wantBucketCounts[bucketIndex] || responseTimeBucket.Percent != 20 {
+			t.Errorf("bucket %q = %d (%d%%), want %d (20%%)", responseTimeBucket.Label, responseTimeBucket.Count, responseTimeBucket.Percent, wantBucketCounts[bucketIndex])
+		}
+	}
+}
+
+func TestBuildFunnelDataBucketsResponsesPerDay(t *testing.T) {
+	berlin, loadError := time.LoadLocation("Europe/Berlin")
+	if loadError != nil {
+		t.Skipf("time zone data unavailable: %v", loadError)
+	}
+	invitedAt := time.Date(2026, time.March, 20, 9, 0, 0, 0, berlin)
+	inBerlin := func(month time.Month, day int, hour int) time.Time {
+		return time.Date(2026, month, day, hour, 0, 0, 0, berlin)
+	}
+
+	testCases := []struct {
+		name          string
+		respondedAt   []time.Time
+		wantStartDay  time.Time
+		wantEndDay    time.Time
+		wantDayCounts []int
+	}{
+		{name: "no timed responses"},
+		{
+			name:          "one day",
+			respondedAt:   []time.Time{inBerlin(time.March, 21, 10), inBerlin(time.March, 21, 23)},
+			wantStartDay:  inBerlin(time.March, 21, 0),
+			wantEndDay:    inBerlin(time.March, 21, 0),
+			wantDayCounts: []int{2},
+		},
+		{
+			name:          "quiet days in between are zero",
+			respondedAt:   []time.Time{inBerlin(time.March, 24, 8), inBerlin(time.March, 21, 10), inBerlin(time.March, 24, 20)},
+			wantStartDay:  inBerlin(time.March, 21, 0),
+			wantEndDay:    inBerlin(time.March, 24, 0),
+			wantDayCounts: []int{1, 0, 0, 2},
+		},
+		{
+			// Berlin switches to summer time on March 29, 2026, so that day is 23 hours long.
+			name:          "days across a daylight saving change",
+			respondedAt:   []time.Time{inBerlin(time.March, 28, 23), inBerlin(time.March, 29, 1), inBerlin(time.March, 29, 23), inBerlin(time.March, 30, 0)},
+			wantStartDay:  inBerlin(time.March, 28, 0),
+			wantEndDay:    inBerlin(time.March, 30, 0),
+			wantDayCounts: []int{1, 2, 1},
+		},
+		{
+			name: "times stored in UTC count on the event's day",
+			respondedAt: []time.Time{
+				inBerlin(time.March, 21, 0).Add(30 * time.Minute).UTC(),
+				inBerlin(time.March, 21, 12),
+				inBerlin(time.March, 22, 0).Add(-30 * time.Minute).UTC(),
+			},
+			wantStartDay:  inBerlin(time.March, 21, 0),
+			wantEndDay:    inBerlin(time.March, 21, 0),
+			wantDayCounts: []int{3},
+		},
+		{
+			name:          "chart is capped to the most recent days",
+			respondedAt:   []time.Time{inBerlin(time.March, 21, 10), inBerlin(time.May, 31, 10)},
+			wantStartDay:  inBerlin(time.May, 31-config.FunnelMaxChartDays+1, 0),
+			wantEndDay:    inBerlin(time.May, 31, 0),
+			wantDayCounts: append(make([]int, config.FunnelMaxChartDays-1), 1),
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			eventRecord := &models.Event{Title: "Party", StartTime: inBerlin(time.June, 1, 18)}
+			var eventRSVPs []models.RSVP
+			for _, respondedAt := range testCase.respondedAt {
+				eventRSVPs = append(eventRSVPs, answeredAt(invitedAt, respondedAt))
+			}
+			funnelData := buildFunnelData(eventRecord, eventRSVPs)
+			if !funnelData.ChartStartDay.Equal(testCase.wantStartDay) || !funnelData.ChartEndDay.Equal(testCase.wantEndDay) {
+				t.Errorf("chart = %v – %v, want %v – %v", funnelData.ChartStartDay, funnelData.ChartEndDay, testCase.wantStartDay, testCase.wantEndDay)
+			}
+			if len(funnelData.DailyResponses) != len(testCase.wantDayCounts) {
+				t.Fatalf("got %d days, want %d: %+v", len(funnelData.DailyResponses), len(testCase.wantDayCounts), funnelData.DailyResponses)
+			}
+			busiestDayCount := 0
+			for _, wantDayCount := range testCase.wantDayCounts {
+				busiestDayCount = max(busiestDayCount, wantDayCount)
+			}
+			for dayIndex, dailyResponse := range funnelData.DailyResponses {
+				if dailyResponse.Count != testCase.wantDayCounts[dayIndex] {
+					t.Errorf("day %s: %d responses, want %d", dailyResponse.Day.Format(time.DateOnly), dailyResponse.Count, testCase.wantDayCounts[dayIndex])
+				}
+				if wantPercent := testCase.wantDayCounts[dayIndex] * 100 / busiestDayCount; dailyResponse.Percent != wantPercent {
+					t.Errorf("day %s: %d%%, want %d%%", dailyResponse.Day.Format(time.DateOnly), dailyResponse.Percent, wantPercent)
+				}
+				if dailyResponse.Day.Hour() != 0 || dailyResponse.Day.Location() != berlin {
+					t.Errorf("day %v is not midnight in the event's location", dailyResponse.Day)
+				}
+			}
+		})
+	}
+}
@@ -1,14 +1,17 @@
 package event
 
 import (
+	"context"
 	"net/http"
 	"strconv"
 
 	"github.com/temirov/RSVP/models"
 	"github.com/temirov/RSVP/pkg/config"
 	"github.com/temirov/RSVP/pkg/handlers"
+	"github.com/temirov/RSVP/pkg/logging"
 	"github.com/temirov/RSVP/pkg/middleware"
 	"github.com/temirov/RSVP/pkg/utils"
+	"gorm.io/gorm"
 )
 
 // ListEventsHandler returns the Events main page (list + optional edit panel).
@@ -18,24 +21,24 @@ func ListEventsHandler(applicationContext *config.ApplicationContext) http.Handl
 	return func(w http.ResponseWriter, r *http.Request) {
 		currentUser := r.Context().Value(middleware.ContextKeyUser).(*models.User)
 
+		activeOrganization := middleware.WorkspaceFromContext(r.Context())
 		requestedEventIDForEdit := r.URL.Query().Get(config.EventIDParam)
 		var selectedEventForEdit *EnhancedEventData
 
-		/* load user venues (for selector) */
-		userReusedVenues, err := models.FindVenuesByOwner(applicationContext.Database, currentUser.ID)
+		/* load workspace venues (for selector) */
+		userReusedVenues, err := findWorkspaceVenues(applicationContext.Database.WithContext(r.Context()), currentUser.ID, activeOrganization)
 		if err != nil {
-			baseHttpHandler.ApplicationContext.Logger.Printf(
-				"ERROR: Failed to retrieve venues owned by user %s: %v",
-				currentUser.ID, err,
-			)
+			logging.FromContext(r.Context()).Error("Failed to retrieve the venues of the active workspace", "error", err)
 			userReusedVenues = []models.Venue{}
 		}
 
 		/* if an event is selected for editing – load it */
 		if requestedEventIDForEdit != "" {
 			var eventToEdit models.Event
-			err = eventToEdit.FindByIDAndOwner(applicationContext.Database, requestedEventIDForEdit, currentUser.ID)
+			var editRole string
+			editRole, err = findEventForMember(applicationContext.Database.WithContext(r.Context()), &eventToEdit, requestedEventIDForEdit, currentUser.ID, models.PermissionEditEvent)
 			if err == nil {
+				userReusedVenues = appendEventWorkspaceVenues(&baseHttpHandler, r.Context(), userReusedVenues, &eventToEdit, currentUser.ID, activeOrganization)
 				venueID := ""
 				if eventToEdit.VenueID != nil {
 					venueID = *eventToEdit.VenueID
@@ -44,36 +47,83 @@ func ListEventsHandler(applicationContext *config.ApplicationContext) http.Handl
 					Event:                     eventToEdit,
 					CalculatedDurationInHours: float64(eventToEdit.DurationHours()),
 					SelectedVenueID:           venueID,
+					CanDelete:                 models.RoleAllows(editRole, models.PermissionDeleteEvent),
 				}
 			} else {
-				baseHttpHandler.ApplicationContext.Logger.Printf(
-					"WARN: Failed to find event %s for edit or user %s does not own it: %v",
-					requestedEventIDForEdit, currentUser.ID, err,
-				)
+				logging.FromContext(r.Context()).Warn("Event not found for editing or the user may not edit it", "event_id", requestedEventIDForEdit, "error", err)
 			}
 		}
 
-		/* gather statistics for list */
-		eventsOwnedByUser, err := models.FindEventsByUserID(applicationContext.Database, currentUser.ID, true, true)
-		if err != nil {
-			baseHttpHandler.HandleError(w, err, utils.DatabaseError, "Failed to retrieve events list.")
-			return
+		/* if an event is selected for the response funnel – load its RSVPs */
+		var funnelData *FunnelData
+		if requestedFunnelEventID := r.URL.Query().Get(config.FunnelEventIDParam); requestedFunnelEventID != "" {
+			var funnelEvent models.Event
+			if _, err = findEventForMember(applicationContext.Database.WithContext(r.Context()), &funnelEvent, requestedFunnelEventID, currentUser.ID, models.PermissionViewEvent); err != nil {
+				logging.FromContext(r.Context()).Warn("Event not found for the funnel or the user is not a member", "event_id", requestedFunnelEventID, "error", err)
+			} else if funnelRSVPs, rsvpErr := models.FindRSVPsByEventID(applicationContext.Database.WithContext(r.Context()), funnelEvent.ID); rsvpErr != nil {
+				logging.FromContext(r.Context()).Error("Failed to load the RSVPs for the funnel", "event_id", funnelEvent.ID, "error", rsvpErr)
+			} else {
+				funnelData = buildFunnelData(&funnelEvent, funnelRSVPs)
+			}
 		}
 
-		eventStatistics := make([]StatisticsData, len(eventsOwnedByUser))
-		for i, ev := range eventsOwnedByUser {
+		/* gather statistics for list: the organization's events, or personal and shared events */
+		var memberEvents []models.Event
+		var sharedEventRoles map[string]string
+		organizationEventRole := ""
+		if activeOrganization != nil {
+			memberEvents, err = models.FindEventsInOrganization(applicationContext.Database.WithContext(r.Context()), activeOrganization.ID, true, true)
+			if err != nil {
+				baseHttpHandler.HandleError(w, r, err, utils.DatabaseError, "Failed to retrieve events list.")
+				return
+			}
+			organizationRole, roleErr := models.OrganizationRoleForUser(applicationContext.Database.WithContext(r.Context()), activeOrganization.ID, currentUser.ID)
+			if roleErr != nil {
+				baseHttpHandler.HandleError(w, r, roleErr, utils.DatabaseError, "Failed to retrieve organization role.")
+				return
+			}
+			organizationEventRole = models.EventRoleForOrganizationRole(organizationRole)
+		} else {
+			memberEvents, err = models.FindEventsForMember(applicationContext.Database.WithContext(r.Context()), currentUser.ID, true, true)
+			if err != nil {
+				baseHttpHandler.HandleError(w, r, err, utils.DatabaseError, "Failed to retrieve events list.")
+				return
+			}
+			sharedEventRoles, err = models.FindEventRolesForMember(applicationContext.Database.WithContext(r.Context()), currentUser.ID)
+			if err != nil {
+				baseHttpHandler.HandleError(w, r, err, utils.DatabaseError, "Failed to retrieve shared events.")
+				return
+			}
+		}
+
+		eventStatistics := make([]StatisticsData, len(memberEvents))
+		for i, ev := range memberEvents {
 			total := len(ev.RSVPs)
 			answered := 0
+			opened := 0
 			for _, rsvp := range ev.RSVPs {
-				if rsvp.Response != "" && rsvp.Response != config.RSVPResponsePending {
+				if rsvp.IsAnswered() {
 					answered++
 				}
+				if rsvp.FirstViewedAt != nil || rsvp.IsAnswered() {
+					opened++
+				}
 			}
 			venueName := "N/A"
 			if ev.Venue != nil {
 				venueName = ev.Venue.Name
 			}
 
+			eventRole := organizationEventRole
+			isShared := false
+			if activeOrganization == nil {
+				eventRole = config.EventRoleOwner
+				if ev.OrganizationID != nil || ev.UserID != currentUser.ID {
+					eventRole = sharedEventRoles[ev.ID]
+					isShared = true
+				}
+			}
+
 			eventStatistics[i] = StatisticsData{
 				ID:                ev.ID,
 				Title:             ev.Title,
@@ -82,6 +132,12 @@ func ListEventsHandler(applicationContext *config.ApplicationContext) http.Handl
 				VenueName:         venueName,
 				RSVPCount:         total,
 				RSVPAnsweredCount: answered,
+				RSVPOpenedCount:   opened,
+				Role:              eventRole,
+				IsShared:          isShared,
+				CanEdit:           models.RoleAllows(eventRole, models.PermissionEditEvent),
+				CanTransfer:       activeOrganization == nil && !isShared,
+				AcceptBareCodes:   ev.AcceptBareCodes,
 			}
 		}
 
@@ -93,7 +149,7 @@ func ListEventsHandler(applicationContext *config.ApplicationContext) http.Handl
 
 		listViewData := ListViewData{
 			/* navigation */
-			AppTitle:           config.AppTitle,
+			AppTitle:           applicationContext.AppTitle,
 			EventsManagerLabel: config.ResourceLabelEventManager,
 			VenueManagerLabel:  config.ResourceLabelVenueManager,
 			RSVPManagerLabel:   "RSVPs",
@@ -102,19 +158,28 @@ func ListEventsHandler(applicationContext *config.ApplicationContext) http.Handl
 			URLForRSVPListBase: config.WebRSVPs,
 			URLForRSVPManager:  config.WebRSVPs,
 			URLForVenues:       config.WebVenues,
+			URLForEventsStream: config.WebEventsStream,
+			URLForCohosts:      config.WebEventCohosts,
+			URLForTransfers:    config.WebTransfers,
 
 			/* data */
 			EventList:           eventStatistics,
 			SelectedItemForEdit: selectedEventForEdit,
 			UserReusedVenues:    userReusedVenues,
+			Funnel:              funnelData,
 
 			/* helpers */
 			ParamNameEventID:          config.EventIDParam,
+			ParamNameFunnelEventID:    config.FunnelEventIDParam,
+			ParamNameTransferType:     config.TransferTypeParam,
+			ParamNameTransferItemID:   config.TransferResourceIDParam,
+			TransferResourceType:      config.TransferResourceEvent,
 			ParamNameVenueID:          config.VenueIDParam,
 			ParamNameTitle:            config.TitleParam,
 			ParamNameDescription:      config.DescriptionParam,
 			ParamNameStartTime:        config.StartTimeParam,
 			ParamNameDuration:         config.DurationParam,
+			ParamNameAcceptBareCodes:  config.AcceptBareCodesParam,
 			ParamNameMethodOverride:   config.MethodOverrideParam,
 			ParamNameVenueName:        config.VenueNameParam,
 			ParamNameVenueAddress:     config.VenueAddressParam,
@@ -129,6 +194,7 @@ func ListEventsHandler(applicationContext *config.ApplicationContext) http.Handl
 			LabelEventDescription: config.LabelEventDescription,
 			LabelStartTime:        config.LabelStartTime,
 			LabelDuration:         config.LabelDuration,
+			LabelAcceptBareCodes:  config.LabelAcceptBareCodes,
 			LabelSelectVenue:      config.LabelSelectVenue,
 			LabelAddVenue:         config.LabelAddVenue,
 			LabelVenueDetails:     config.LabelVenueDetails,
@@ -158,3 +224,51 @@ func ListEventsHandler(applicationContext *config.ApplicationContext) http.Handl
 		baseHttpHandler.RenderView(w, r, config.TemplateEvents, listViewData)
 	}
 }
+
+// findEventForMember loads an event with its venue if the user's role on it grants the permission, and
+// returns that role. It returns gorm.ErrRecordNotFound when the event does not exist or the user lacks the permission.
+func findEventForMember(databaseConnection *gorm.DB, eventRecord *models.Event, eventIdentifier string, currentUserID string, permission models.EventPermission) (string, error) {
+	if err := eventRecord.LoadWithVenue(databaseConnection, eventIdentifier); err != nil {
+		return "", err
+	}
+	eventRole, err := models.EventRoleForUser(databaseConnection, eventRecord, currentUserID)
+	if err != nil {
+		return "", err
+	}
+	if !models.RoleAllows(eventRole, permission) {
+		return "", gorm.ErrRecordNotFound
+	}
+	return eventRole, nil
+}
+
+// findWorkspaceVenues lists the venues of the active workspace: the organization's, or the user's personal ones.
+func findWorkspaceVenues(databaseConnection *gorm.DB, currentUserID string, activeOrganization *models.Organization) ([]models.Venue, error) {
+	if activeOrganization != nil {
+		return models.FindVenuesInOrganization(databaseConnection, activeOrganization.ID)
+	}
+	return models.FindVenuesByOwner(databaseConnection, currentUserID)
+}
+
+// appendEventWorkspaceVenues adds the venues of the workspace the edited event belongs to, when that is not the
+// active workspace, so a co-host editing a shared event can choose among the venues of its owner or organization.
+func appendEventWorkspaceVenues(baseHttpHandler *handlers.BaseHttpHandler, requestContext context.Context, selectableVenues []models.Venue, eventRecord *models.Event, currentUserID string, activeOrganization *models.Organization) []models.Venue {
+	databaseConnection := baseHttpHandler.ApplicationContext.Database.WithContext(requestContext)
+	var eventVenues []models.Venue
+	var err error
+	switch {
+	case eventRecord.OrganizationID != nil:
+		if activeOrganization != nil && activeOrganization.ID == *eventRecord.OrganizationID {
+			return selectableVenues
+		}
+		eventVenues, err = models.FindVenuesInOrganization(databaseConnection, *eventRecord.OrganizationID)
+	case activeOrganization == nil && eventRecord.UserID == currentUserID:
+		return selectableVenues
+	default:
+		eventVenues, err = models.FindVenuesByOwner(databaseConnection, eventRecord.UserID)
+	}
+	if err != nil {
+		logging.FromContext(requestContext).Error("Failed to retrieve the venues of the event's workspace", "event_id", eventRecord.ID, "error", err)
+		return selectableVenues
+	}
+	return append(selectableVenues, eventVenues...)
+}
@@ -0,0 +1,25 @@
+package event
+
+import (
+	"net/http"
+
+	"github.com/temirov/RSVP/models"
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/handlers"
+	"github.com/temirov/RSVP/pkg/middleware"
+	"github.com/temirov/RSVP/pkg/realtime"
+)
+
+// StreamHandler serves the live update stream for the events list (/events/stream/).
+// It carries updates for every event the current user can view: their own, co-hosted and organization events.
+func StreamHandler(applicationContext *config.ApplicationContext) http.HandlerFunc {
+	baseHttpHandler := handlers.NewBaseHttpHandler(applicationContext, config.ResourceNameEvent, config.WebEventsStream)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !baseHttpHandler.ValidateHttpMethod(w, r, http.MethodGet) {
+			return
+		}
+		currentUser := r.Context().Value(middleware.ContextKeyUser).(*models.User)
+		baseHttpHandler.StreamUpdates(w, r, realtime.OwnerTopic(currentUser.ID))
+	}
+}
@@ -25,24 +25,28 @@ func UpdateEventHandler(applicationContext *config.ApplicationContext) http.Hand
 
 		parseFormError := httpRequest.ParseForm()
 		if parseFormError != nil {
-			baseHttpHandler.HandleError(httpResponseWriter, parseFormError, utils.ValidationError, config.ErrMsgInvalidFormData)
+			baseHttpHandler.HandleError(httpResponseWriter, httpRequest, parseFormError, utils.ValidationError, config.ErrMsgInvalidFormData)
 			return
 		}
 
-		activeTransaction := applicationContext.Database.Begin()
+		activeTransaction := applicationContext.Database.WithContext(httpRequest.Context()).Begin()
 		if activeTransaction.Error != nil {
-			baseHttpHandler.HandleError(httpResponseWriter, activeTransaction.Error, utils.DatabaseError, config.ErrMsgTransactionStart)
+			baseHttpHandler.HandleError(httpResponseWriter, httpRequest, activeTransaction.Error, utils.DatabaseError, config.ErrMsgTransactionStart)
 			return
 		}
 
 		targetEventIdentifier := httpRequest.FormValue(config.EventIDParam)
 
 		var existingEventRecord models.Event
-		// FindByIDAndOwner preloads Venue, so existingEventRecord.Venue will be populated if associated.
-		findEventError := existingEventRecord.FindByIDAndOwner(activeTransaction, targetEventIdentifier, currentUser.ID)
+		// LoadWithVenue preloads Venue, so existingEventRecord.Venue will be populated if associated.
+		findEventError := existingEventRecord.LoadWithVenue(activeTransaction, targetEventIdentifier)
 		if findEventError != nil {
 			activeTransaction.Rollback()
-			baseHttpHandler.HandleError(httpResponseWriter, findEventError, utils.NotFoundError, config.ErrMsgEventNotFound)
+			baseHttpHandler.HandleError(httpResponseWriter, httpRequest, findEventError, utils.NotFoundError, config.ErrMsgEventNotFound)
+			return
+		}
+		if baseHttpHandler.AuthorizeEventAccess(httpResponseWriter, httpRequest, &existingEventRecord, currentUser.ID, models.PermissionEditEvent) == "" {
+			activeTransaction.Rollback()
 			return
 		}
 
@@ -52,18 +56,21 @@ func UpdateEventHandler(applicationContext *config.ApplicationContext) http.Hand
 		parsedDurationHours, durationValidationError := utils.ValidateAndParseEventDuration(httpRequest.FormValue(config.DurationParam))
 		if durationValidationError != nil {
 			activeTransaction.Rollback()
-			baseHttpHandler.HandleError(httpResponseWriter, durationValidationError, utils.ValidationError, durationValidationError.Error())
+			baseHttpHandler.HandleError(httpResponseWriter, httpRequest, durationValidationError, utils.ValidationError, durationValidationError.Error())
 			return
 		}
 
 		parsedStartTime, startTimeParseError := time.Parse(config.TimeLayoutHTMLForm, httpRequest.FormValue(config.StartTimeParam))
 		if startTimeParseError != nil {
 			activeTransaction.Rollback()
-			baseHttpHandler.HandleError(httpResponseWriter, startTimeParseError, utils.ValidationError, config.ErrMsgInvalidStartTimeFormat)
+			baseHttpHandler.HandleError(httpResponseWriter, httpRequest, startTimeParseError, utils.ValidationError, config.ErrMsgInvalidStartTimeFormat)
 			return
 		}
 
 		existingEventRecord.StartTime = parsedStartTime
+		if acceptBareCodes, optionSubmitted := acceptBareCodesFromForm(httpRequest); optionSubmitted {
+			existingEventRecord.AcceptBareCodes = acceptBareCodes
+		}
 		existingEventRecord.EndTime = parsedStartTime.Add(time.Duration(parsedDurationHours) * time.Hour)
 
 		if _, venueParameterPresent := httpRequest.Form[config.VenueIDParam]; venueParameterPresent {
@@ -73,11 +80,16 @@ func UpdateEventHandler(applicationContext *config.ApplicationContext) http.Hand
 				existingEventRecord.VenueID = nil
 			} else {
 				if existingEventRecord.VenueID == nil || *existingEventRecord.VenueID != selectedVenueIdentifierString {
+					// An organization's event uses the organization's venues. On a personal event, co-hosts may
+					// pick one of their own venues or one of the event owner's.
 					var verifiedVenueRecord models.Venue
-					findVenueError := verifiedVenueRecord.FindByIDAndOwner(activeTransaction, selectedVenueIdentifierString, currentUser.ID)
+					findVenueError := verifiedVenueRecord.FindByIDInWorkspace(activeTransaction, selectedVenueIdentifierString, currentUser.ID, existingEventRecord.OrganizationID)
+					if findVenueError != nil && existingEventRecord.OrganizationID == nil && existingEventRecord.UserID != currentUser.ID {
+						findVenueError = verifiedVenueRecord.FindByIDInWorkspace(activeTransaction, selectedVenueIdentifierString, existingEventRecord.UserID, nil)
+					}
 					if findVenueError != nil {
 						activeTransaction.Rollback()
-						baseHttpHandler.HandleError(httpResponseWriter, findVenueError, utils.ForbiddenError, config.ErrMsgVenuePermission)
+						baseHttpHandler.HandleError(httpResponseWriter, httpRequest, findVenueError, utils.ForbiddenError, config.ErrMsgVenuePermission)
 						return
 					}
 					existingEventRecord.VenueID = &selectedVenueIdentifierString
@@ -88,13 +100,13 @@ func UpdateEventHandler(applicationContext *config.ApplicationContext) http.Hand
 		updateEventError := existingEventRecord.Update(activeTransaction)
 		if updateEventError != nil {
 			activeTransaction.Rollback()
-			baseHttpHandler.HandleError(httpResponseWriter, updateEventError, utils.DatabaseError, config.ErrMsgEventUpdate)
+			baseHttpHandler.HandleError(httpResponseWriter, httpRequest, updateEventError, utils.DatabaseError, config.ErrMsgEventUpdate)
 			return
 		}
 
 		commitTransactionError := activeTransaction.Commit().Error
 		if commitTransactionError != nil {
-			baseHttpHandler.HandleError(httpResponseWriter, commitTransactionError, utils.DatabaseError, config.ErrMsgEventUpdate)
+			baseHttpHandler.HandleError(httpResponseWriter, httpRequest, commitTransactionError, utils.DatabaseError, config.ErrMsgEventUpdate)
 			return
 		}
 
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"mime"
+	"net/http"
+	"net/url"
+
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/utils"
+)
+
+// ForgeryViewData is passed to the "form_expired" view template.
+type ForgeryViewData struct {
+	// URLForReturn is the page the rejected form was submitted from, when it belongs to this application.
+	URLForReturn string
+}
+
+// ForgeryRejectedHandler answers requests that failed the CSRF check. Browsers get a page explaining that the
+// form has to be reloaded; JSON clients get a plain 403.
+func ForgeryRejectedHandler(applicationContext *config.ApplicationContext) http.HandlerFunc {
+	baseHttpHandler := NewBaseHttpHandler(applicationContext, config.ResourceNameForm, config.WebRoot)
+	return func(responseWriter http.ResponseWriter, request *http.Request) {
+		if acceptedType, _, _ := mime.ParseMediaType(request.Header.Get("Accept")); acceptedType == "application/json" {
+			baseHttpHandler.HandleError(responseWriter, request, nil, utils.ForbiddenError, "Forbidden: Missing or invalid "+config.CSRFHeader+" header.")
+			return
+		}
+		returnURL := config.WebRoot
+		if refererURL, parseError := url.Parse(request.Referer()); parseError == nil && refererURL.Host == request.Host && refererURL.Path != "" {
+			returnURL = refererURL.RequestURI()
+		}
+		responseWriter.WriteHeader(http.StatusForbidden)
+		baseHttpHandler.RenderView(responseWriter, request, config.TemplateForgery, ForgeryViewData{URLForReturn: returnURL})
+	}
+}
@@ -0,0 +1,137 @@
+// Package health contains the liveness and readiness endpoints probed by container orchestrators and load balancers.
+package health
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/handlers"
+	"github.com/temirov/RSVP/pkg/logging"
+	"github.com/temirov/RSVP/pkg/migrations"
+	"github.com/temirov/RSVP/pkg/templates"
+)
+
+// Readiness records whether the server has begun shutting down. It is shared by the readiness handler and main,
+// which marks it when a shutdown signal arrives.
+type Readiness struct {
+	shuttingDown atomic.Bool
+}
+
+// MarkShuttingDown makes every later readiness check fail.
+func (readiness *Readiness) MarkShuttingDown() {
+	readiness.shuttingDown.Store(true)
+}
+
+// ShuttingDown reports whether MarkShuttingDown has been called.
+func (readiness *Readiness) ShuttingDown() bool {
+	return readiness.shuttingDown.Load()
+}
+
+// CheckResult is the outcome of one readiness check.
+type CheckResult struct {
+	Status string `json:"status"`
+	// Detail says what is wrong with a failing check. It never contains connection strings or secrets.
+	Detail string `json:"detail,omitempty"`
+}
+
+// Report is the JSON body of the health endpoints.
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+// LivenessHandler answers 200 for as long as the process can serve requests at all.
+func LivenessHandler(applicationContext *config.ApplicationContext) http.HandlerFunc {
+	baseHttpHandler := handlers.NewBaseHttpHandler(applicationContext, config.ResourceNameHealth, config.WebHealthz)
+	return func(httpResponseWriter http.ResponseWriter, httpRequest *http.Request) {
+		if !baseHttpHandler.ValidateHttpMethod(httpResponseWriter, httpRequest, http.MethodGet, http.MethodHead) {
+			return
+		}
+		httpResponseWriter.Header().Set("Cache-Control", "no-store")
+		baseHttpHandler.WriteJSON(httpResponseWriter, httpRequest, http.StatusOK, Report{Status: config.HealthStatusOK})
+	}
+}
+
+// ReadinessHandler answers 200 when the server can serve pages: the database answers and is fully migrated, every
+// view template is loaded and a session secret is configured, and shutdown has not begun. Otherwise it answers 503.
+// Either way the body lists the outcome of each check.
+func ReadinessHandler(applicationContext *config.ApplicationContext, sessionSecret string, readiness *Readiness) http.HandlerFunc {
+	baseHttpHandler := handlers.NewBaseHttpHandler(applicationContext, config.ResourceNameHealth, config.WebReadyz)
+	return func(httpResponseWriter http.ResponseWriter, httpRequest *http.Request) {
+		if !baseHttpHandler.ValidateHttpMethod(httpResponseWriter, httpRequest, http.MethodGet, http.MethodHead) {
+			return
+		}
+		checkContext, cancelChecks := context.WithTimeout(httpRequest.Context(), time.Duration(config.ReadinessTimeout))
+		defer cancelChecks()
+
+		readinessReport := Report{Status: config.HealthStatusOK, Checks: map[string]CheckResult{
+			"database":       checkDatabase(checkContext, applicationContext),
+			"templates":      checkTemplates(),
+			"session_secret": checkSessionSecret(sessionSecret),
+			"shutdown":       checkShutdown(readiness),
+		}}
+		for checkName, checkResult := range readinessReport.Checks {
+			if checkResult.Status != config.HealthStatusOK {
+				readinessReport.Status = config.HealthStatusFailing
+				logging.FromContext(httpRequest.Context()).Debug("Readiness check failed", "check", checkName, "detail", checkResult.Detail)
+			}
+		}
+		responseStatus := http.StatusOK
+		if readinessReport.Status != config.HealthStatusOK {
+			responseStatus = http.StatusServiceUnavailable
+		}
+		httpResponseWriter.Header().Set("Cache-Control", "no-store")
+		baseHttpHandler.WriteJSON(httpResponseWriter, httpRequest, responseStatus, readinessReport)
+	}
+}
+
+// checkDatabase pings the database and compares its schema version with the latest migration this binary knows.
+func checkDatabase(checkContext context.Context, applicationContext *config.ApplicationContext) CheckResult {
+	sqlDatabase, handleError := applicationContext.Database.DB()
+	if handleError != nil {
+		logging.FromContext(checkContext).Warn("Readiness: getting the database handle failed", "error", handleError)
+		return CheckResult{Status: config.HealthStatusFailing, Detail: "database handle unavailable"}
+	}
+	if pingError := sqlDatabase.PingContext(checkContext); pingError != nil {
+		logging.FromContext(checkContext).Warn("Readiness: pinging the database failed", "error", pingError)
+		return CheckResult{Status: config.HealthStatusFailing, Detail: "database unreachable"}
+	}
+	currentVersion, versionError := migrations.CurrentVersion(applicationContext.Database.WithContext(checkContext))
+	if versionError != nil {
+		logging.FromContext(checkContext).Warn("Readiness: reading the schema version failed", "error", versionError)
+		return CheckResult{Status: config.HealthStatusFailing, Detail: "schema version unreadable"}
+	}
+	if latestVersion := migrations.LatestVersion(); currentVersion != latestVersion {
+		return CheckResult{Status: config.HealthStatusFailing, Detail: fmt.Sprintf("schema at version %d, expected %d", currentVersion, latestVersion)}
+	}
+	return CheckResult{Status: config.HealthStatusOK}
+}
+
+// checkTemplates verifies that every view has a parsed template set.
+func checkTemplates() CheckResult {
+	if missingViewNames := templates.MissingViews(); len(missingViewNames) > 0 {
+		return CheckResult{Status: config.HealthStatusFailing, Detail: "views not loaded: " + strings.Join(missingViewNames, ", ")}
+	}
+	return CheckResult{Status: config.HealthStatusOK}
+}
+
+// checkSessionSecret verifies that sessions can be signed.
+func checkSessionSecret(sessionSecret string) CheckResult {
+	if sessionSecret == "" {
+		return CheckResult{Status: config.HealthStatusFailing, Detail: "SESSION_SECRET is not set"}
+	}
+	return CheckResult{Status: config.HealthStatusOK}
+}
+
+// checkShutdown fails once the server has begun shutting down.
+func checkShutdown(readiness *Readiness) CheckResult {
+	if readiness.ShuttingDown() {
+		return CheckResult{Status: config.HealthStatusFailing, Detail: "shutting down"}
+	}
+	return CheckResult{Status: config.HealthStatusOK}
+}
@@ -0,0 +1,87 @@
+package health
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	rsvp "github.com/temirov/RSVP"
+	"github.com/temirov/RSVP/pkg/assets"
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/templates"
+	"github.com/temirov/RSVP/pkg/testdb"
+)
+
+// probe sends a request with the given method to handler and decodes the report it answers with.
+func probe(t *testing.T, healthHandler http.Handler, method string) (int, Report) {
+	t.Helper()
+	responseRecorder := httptest.NewRecorder()
+	healthHandler.ServeHTTP(responseRecorder, httptest.NewRequest(method, config.WebReadyz, nil))
+	var healthReport Report
+	if method == http.MethodGet {
+		if err := json.Unmarshal(responseRecorder.Body.Bytes(), &healthReport); err != nil {
+			t.Fatalf("decoding the report %q: %v", responseRecorder.Body.String(), err)
+		}
+	}
+	return responseRecorder.Code, healthReport
+}
+
+func newTestApplicationContext(t *testing.T) *config.ApplicationContext {
+	t.Helper()
+	templateFiles, err := assets.Templates(rsvp.EmbeddedFiles)
+	if err != nil {
+		t.Fatalf("opening the templates: %v", err)
+	}
+	templates.LoadAllPrecompiledTemplates(templateFiles)
+	return &config.ApplicationContext{
+		Database: testdb.OpenMigrated(t),
+		Logger:   slog.New(slog.NewTextHandler(io.Discard, nil)),
+		AppTitle: config.DefaultAppTitle,
+		Assets:   rsvp.EmbeddedFiles,
+	}
+}
+
+func TestReadinessFailsOnceShutdownBegins(t *testing.T) {
+	readiness := &Readiness{}
+	readinessHandler := ReadinessHandler(newTestApplicationContext(t), "0123456789abcdef0123456789abcdef", readiness)
+	if statusCode, readinessReport := probe(t, readinessHandler, http.MethodGet); statusCode != http.StatusOK || readinessReport.Status != config.HealthStatusOK {
+		t.Fatalf("ready server: status = %d, report = %+v; want every check passing", statusCode, readinessReport)
+	}
+
+	readiness.MarkShuttingDown()
+	statusCode, readinessReport := probe(t, readinessHandler, http.MethodGet)
+	if statusCode != http.StatusServiceUnavailable || readinessReport.Status != config.HealthStatusFailing {
+		t.Errorf("shutting down: status = %d, report status = %q; want %d and %q", statusCode, readinessReport.Status,
+			http.StatusServiceUnavailable, config.HealthStatusFailing)
+	}
+	if shutdownCheck := readinessReport.Checks["shutdown"]; shutdownCheck.Status != config.HealthStatusFailing {
+		t.Errorf("shutdown check = %+v, want it failing", shutdownCheck)
+	}
+	if databaseCheck := readinessReport.Checks["database"]; databaseCheck.Status != config.HealthStatusOK {
+		t.Errorf("database check = %+v, want it passing", databaseCheck)
+	}
+}
+
+func TestReadinessNeedsASessionSecret(t *testing.T) {
+	readinessHandler := ReadinessHandler(newTestApplicationContext(t), "", &Readiness{})
+	statusCode, readinessReport := probe(t, readinessHandler, http.MethodGet)
+	if statusCode != http.StatusServiceUnavailable || readinessReport.Checks["session_secret"].Status != config.HealthStatusFailing {
+		t.Errorf("status = %d, report = %+v; want the session secret check failing", statusCode, readinessReport)
+	}
+}
+
+func TestLivenessAnswersGetAndHeadOnly(t *testing.T) {
+	livenessHandler := LivenessHandler(newTestApplicationContext(t))
+	if statusCode, livenessReport := probe(t, livenessHandler, http.MethodGet); statusCode != http.StatusOK || livenessReport.Status != config.HealthStatusOK {
+		t.Errorf("GET: status = %d, report = %+v; want %d and %q", statusCode, livenessReport, http.StatusOK, config.HealthStatusOK)
+	}
+	if statusCode, _ := probe(t, livenessHandler, http.MethodHead); statusCode != http.StatusOK {
+		t.Errorf("HEAD: status = %d, want %d", statusCode, http.StatusOK)
+	}
+	if statusCode, _ := probe(t, livenessHandler, http.MethodPost); statusCode != http.StatusMethodNotAllowed {
+		t.Errorf("POST: status = %d, want %d", statusCode, http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"github.com/temirov/RSVP/models"
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/invitation"
+)
+
+// InvitationClaims returns what the RSVP's current invitation link vouches for.
+func InvitationClaims(applicationContext *config.ApplicationContext, rsvpRecord *models.RSVP, eventRecord *models.Event) invitation.Claims {
+	return invitation.Claims{
+		RSVPID:      rsvpRecord.ID,
+		LinkVersion: rsvpRecord.LinkVersion,
+		ExpiresAt:   applicationContext.Invitations.ExpiryFor(eventRecord.EndTime),
+	}
+}
+
+// InvitationLinkParams returns the query parameters of the RSVP's signed invitation link to the response page.
+func InvitationLinkParams(applicationContext *config.ApplicationContext, rsvpRecord *models.RSVP, eventRecord *models.Event) map[string]string {
+	return map[string]string{
+		config.InvitationTokenParam: applicationContext.Invitations.Sign(InvitationClaims(applicationContext, rsvpRecord, eventRecord)),
+	}
+}
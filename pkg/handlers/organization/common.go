@@ -0,0 +1,83 @@
+// Package organization provides HTTP handlers for organizations: creating them, managing their members and
+// switching the active workspace.
+package organization
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/temirov/RSVP/models"
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/handlers"
+	"github.com/temirov/RSVP/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// MembershipSummary is an organization the current user belongs to, together with their role in it.
+type MembershipSummary struct {
+	Organization models.Organization
+	Role         string
+}
+
+// ListViewData is passed to the "organizations" view template.
+type ListViewData struct {
+	Organizations           []MembershipSummary
+	SelectedOrganization    *models.Organization
+	SelectedRole            string
+	Members                 []models.OrganizationMember
+	CurrentUserID           string
+	CanManageMembers        bool
+	URLForOrganizations     string
+	URLForMemberActions     string
+	URLForWorkspaceSwitch   string
+	ParamNameOrganizationID string
+	ParamNameOrgName        string
+	ParamNameMemberID       string
+	ParamNameMemberEmail    string
+	ParamNameMemberRole     string
+	ParamNameWorkspaceID    string
+	ParamNameMethodOverride string
+	RoleOwner               string
+	RoleAdmin               string
+	RoleMember              string
+	MaxOrgNameLength        int
+}
+
+// loadAuthorizedOrganization reads the organization_id parameter, loads the organization and checks that the
+// user is an accepted member, and a manager (owner or admin) when requireManagement is set.
+// It returns the organization and the user's role, or false if a response has already been sent.
+func loadAuthorizedOrganization(baseHttpHandler *handlers.BaseHttpHandler, responseWriter http.ResponseWriter, request *http.Request, currentUserID string, requireManagement bool) (*models.Organization, string, bool) {
+	params, paramsOk := baseHttpHandler.RequireParams(responseWriter, request, config.OrganizationIDParam)
+	if !paramsOk {
+		return nil, "", false
+	}
+	organizationRole, roleError := models.OrganizationRoleForUser(baseHttpHandler.ApplicationContext.Database.WithContext(request.Context()), params[config.OrganizationIDParam], currentUserID)
+	if roleError != nil {
+		baseHttpHandler.HandleError(responseWriter, request, roleError, utils.DatabaseError, "Could not verify organization membership.")
+		return nil, "", false
+	}
+	if organizationRole == "" {
+		baseHttpHandler.HandleError(responseWriter, request, nil, utils.NotFoundError, "Organization not found.")
+		return nil, "", false
+	}
+	if requireManagement && !models.OrgRoleAllowsManagement(organizationRole) {
+		baseHttpHandler.HandleError(responseWriter, request, nil, utils.ForbiddenError, "Forbidden: Only organization owners and admins can manage members.")
+		return nil, "", false
+	}
+	var memberOrganization models.Organization
+	if findError := memberOrganization.FindByID(baseHttpHandler.ApplicationContext.Database.WithContext(request.Context()), params[config.OrganizationIDParam]); findError != nil {
+		if errors.Is(findError, gorm.ErrRecordNotFound) {
+			baseHttpHandler.HandleError(responseWriter, request, findError, utils.NotFoundError, "Organization not found.")
+		} else {
+			baseHttpHandler.HandleError(responseWriter, request, findError, utils.DatabaseError, "Error retrieving organization.")
+		}
+		return nil, "", false
+	}
+	return &memberOrganization, organizationRole, true
+}
+
+// redirectToOrganization sends the user back to the member list of the organization.
+func redirectToOrganization(responseWriter http.ResponseWriter, request *http.Request, organizationIdentifier string) {
+	redirectURL := utils.BuildRelativeURL(config.WebOrganizations, map[string]string{config.OrganizationIDParam: organizationIdentifier})
+	http.Redirect(responseWriter, request, redirectURL, http.StatusSeeOther)
+}
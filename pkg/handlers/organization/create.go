@@ -0,0 +1,42 @@
+package organization
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/temirov/RSVP/models"
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/handlers"
+	"github.com/temirov/RSVP/pkg/logging"
+	"github.com/temirov/RSVP/pkg/middleware"
+	"github.com/temirov/RSVP/pkg/utils"
+)
+
+// CreateHandler handles POST requests that create an organization owned by the current user.
+func CreateHandler(applicationContext *config.ApplicationContext) http.HandlerFunc {
+	baseHttpHandler := handlers.NewBaseHttpHandler(applicationContext, config.ResourceNameOrg, config.WebOrganizations)
+	return func(responseWriter http.ResponseWriter, request *http.Request) {
+		if !baseHttpHandler.ValidateHttpMethod(responseWriter, request, http.MethodPost) {
+			return
+		}
+		if err := request.ParseForm(); err != nil {
+			baseHttpHandler.HandleError(responseWriter, request, err, utils.ValidationError, utils.ErrMsgInvalidFormData)
+			return
+		}
+		currentUser := request.Context().Value(middleware.ContextKeyUser).(*models.User)
+		organizationName := strings.TrimSpace(request.FormValue(config.OrganizationNameParam))
+		if validationError := utils.ValidateOrganizationName(organizationName); validationError != nil {
+			baseHttpHandler.HandleError(responseWriter, request, validationError, utils.ValidationError, validationError.Error())
+			return
+		}
+
+		newOrganization, createError := models.CreateOrganization(applicationContext.Database.WithContext(request.Context()), organizationName, currentUser)
+		if createError != nil {
+			baseHttpHandler.HandleError(responseWriter, request, createError, utils.DatabaseError, "Failed to create the organization.")
+			return
+		}
+		logging.FromContext(request.Context()).Info("Organization created", "organization_id", newOrganization.ID)
+
+		redirectToOrganization(responseWriter, request, newOrganization.ID)
+	}
+}
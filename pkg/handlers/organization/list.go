@@ -0,0 +1,82 @@
+package organization
+
+import (
+	"net/http"
+
+	"github.com/temirov/RSVP/models"
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/handlers"
+	"github.com/temirov/RSVP/pkg/logging"
+	"github.com/temirov/RSVP/pkg/middleware"
+	"github.com/temirov/RSVP/pkg/utils"
+)
+
+// ListHandler handles GET requests for the organizations page (/organizations/). It lists the user's
+// organizations and the members of the selected one, which defaults to the active workspace.
+func ListHandler(applicationContext *config.ApplicationContext) http.HandlerFunc {
+	baseHttpHandler := handlers.NewBaseHttpHandler(applicationContext, config.ResourceNameOrg, config.WebOrganizations)
+	return func(responseWriter http.ResponseWriter, request *http.Request) {
+		if !baseHttpHandler.ValidateHttpMethod(responseWriter, request, http.MethodGet) {
+			return
+		}
+		currentUser := request.Context().Value(middleware.ContextKeyUser).(*models.User)
+
+		memberOrganizations, err := models.FindOrganizationsForUser(applicationContext.Database.WithContext(request.Context()), currentUser.ID)
+		if err != nil {
+			baseHttpHandler.HandleError(responseWriter, request, err, utils.DatabaseError, "Failed to retrieve organizations.")
+			return
+		}
+
+		selectedOrganizationID := request.URL.Query().Get(config.OrganizationIDParam)
+		if selectedOrganizationID == "" {
+			if activeOrganization := middleware.WorkspaceFromContext(request.Context()); activeOrganization != nil {
+				selectedOrganizationID = activeOrganization.ID
+			}
+		}
+
+		viewData := ListViewData{
+			Organizations:           make([]MembershipSummary, 0, len(memberOrganizations)),
+			CurrentUserID:           currentUser.ID,
+			URLForOrganizations:     config.WebOrganizations,
+			URLForMemberActions:     config.WebOrgMembers,
+			URLForWorkspaceSwitch:   config.WebWorkspace,
+			ParamNameOrganizationID: config.OrganizationIDParam,
+			ParamNameOrgName:        config.OrganizationNameParam,
+			ParamNameMemberID:       config.OrgMemberIDParam,
+			ParamNameMemberEmail:    config.OrgMemberEmailParam,
+			ParamNameMemberRole:     config.OrgMemberRoleParam,
+			ParamNameWorkspaceID:    config.WorkspaceIDParam,
+			ParamNameMethodOverride: config.MethodOverrideParam,
+			RoleOwner:               config.OrgRoleOwner,
+			RoleAdmin:               config.OrgRoleAdmin,
+			RoleMember:              config.OrgRoleMember,
+			MaxOrgNameLength:        config.MaxOrganizationNameLength,
+		}
+		for organizationIndex := range memberOrganizations {
+			memberOrganization := memberOrganizations[organizationIndex]
+			organizationRole, roleError := models.OrganizationRoleForUser(applicationContext.Database.WithContext(request.Context()), memberOrganization.ID, currentUser.ID)
+			if roleError != nil {
+				baseHttpHandler.HandleError(responseWriter, request, roleError, utils.DatabaseError, "Failed to retrieve organization roles.")
+				return
+			}
+			viewData.Organizations = append(viewData.Organizations, MembershipSummary{Organization: memberOrganization, Role: organizationRole})
+			if memberOrganization.ID == selectedOrganizationID {
+				viewData.SelectedOrganization = &memberOrganizations[organizationIndex]
+				viewData.SelectedRole = organizationRole
+			}
+		}
+
+		if viewData.SelectedOrganization != nil {
+			viewData.Members, err = models.FindOrganizationMembers(applicationContext.Database.WithContext(request.Context()), viewData.SelectedOrganization.ID)
+			if err != nil {
+				baseHttpHandler.HandleError(responseWriter, request, err, utils.DatabaseError, "Failed to retrieve organization members.")
+				return
+			}
+			viewData.CanManageMembers = models.OrgRoleAllowsManagement(viewData.SelectedRole)
+		} else if selectedOrganizationID != "" {
+			logging.FromContext(request.Context()).Warn("User requested an organization they are not a member of", "organization_id", selectedOrganizationID)
+		}
+
+		baseHttpHandler.RenderView(responseWriter, request, config.TemplateOrgs, viewData)
+	}
+}
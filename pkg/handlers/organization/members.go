@@ -0,0 +1,111 @@
+package organization
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/temirov/RSVP/models"
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/handlers"
+	"github.com/temirov/RSVP/pkg/logging"
+	"github.com/temirov/RSVP/pkg/middleware"
+	"github.com/temirov/RSVP/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// InviteMemberHandler handles POST requests that invite a member to an organization by email address.
+// Only owners and admins may invite; the invitation is accepted the next time the invitee signs in.
+func InviteMemberHandler(applicationContext *config.ApplicationContext) http.HandlerFunc {
+	baseHttpHandler := handlers.NewBaseHttpHandler(applicationContext, config.ResourceNameOrgUser, config.WebOrgMembers)
+	return func(responseWriter http.ResponseWriter, request *http.Request) {
+		if !baseHttpHandler.ValidateHttpMethod(responseWriter, request, http.MethodPost) {
+			return
+		}
+		currentUser := request.Context().Value(middleware.ContextKeyUser).(*models.User)
+		memberOrganization, _, organizationOk := loadAuthorizedOrganization(&baseHttpHandler, responseWriter, request, currentUser.ID, true)
+		if !organizationOk {
+			return
+		}
+
+		params, paramsOk := baseHttpHandler.RequireParams(responseWriter, request, config.OrgMemberEmailParam, config.OrgMemberRoleParam)
+		if !paramsOk {
+			return
+		}
+		invitedEmail := models.NormalizeMemberEmail(params[config.OrgMemberEmailParam])
+		if validationError := utils.ValidateOrgMemberEmail(invitedEmail); validationError != nil {
+			baseHttpHandler.HandleError(responseWriter, request, validationError, utils.ValidationError, validationError.Error())
+			return
+		}
+		memberRole := params[config.OrgMemberRoleParam]
+		if validationError := utils.ValidateOrgMemberRole(memberRole); validationError != nil {
+			baseHttpHandler.HandleError(responseWriter, request, validationError, utils.ValidationError, validationError.Error())
+			return
+		}
+
+		newMember, inviteError := models.InviteOrganizationMember(applicationContext.Database.WithContext(request.Context()), memberOrganization.ID, invitedEmail, memberRole, currentUser.ID)
+		if inviteError != nil {
+			if errors.Is(inviteError, models.ErrOrganizationOwner) {
+				baseHttpHandler.HandleError(responseWriter, request, inviteError, utils.ValidationError, inviteError.Error())
+			} else {
+				baseHttpHandler.HandleError(responseWriter, request, inviteError, utils.DatabaseError, "Failed to invite the member.")
+			}
+			return
+		}
+		logging.FromContext(request.Context()).Info("Organization member invited", "email", invitedEmail, "role", memberRole, "organization_id", memberOrganization.ID, "member_id", newMember.ID)
+
+		redirectToOrganization(responseWriter, request, memberOrganization.ID)
+	}
+}
+
+// RemoveMemberHandler handles DELETE requests that remove a member from an organization or withdraw a
+// pending invitation. Owners and admins may remove anyone but the owner; a member may remove only
+// themselves, leaving the organization. The events and venues of a removed member stay in the organization.
+func RemoveMemberHandler(applicationContext *config.ApplicationContext) http.HandlerFunc {
+	baseHttpHandler := handlers.NewBaseHttpHandler(applicationContext, config.ResourceNameOrgUser, config.WebOrgMembers)
+	return func(responseWriter http.ResponseWriter, request *http.Request) {
+		if !baseHttpHandler.ValidateHttpMethod(responseWriter, request, http.MethodDelete) {
+			return
+		}
+		currentUser := request.Context().Value(middleware.ContextKeyUser).(*models.User)
+		memberOrganization, organizationRole, organizationOk := loadAuthorizedOrganization(&baseHttpHandler, responseWriter, request, currentUser.ID, false)
+		if !organizationOk {
+			return
+		}
+		params, paramsOk := baseHttpHandler.RequireParams(responseWriter, request, config.OrgMemberIDParam)
+		if !paramsOk {
+			return
+		}
+
+		var organizationMember models.OrganizationMember
+		if findError := organizationMember.FindByIDAndOrganization(applicationContext.Database.WithContext(request.Context()), params[config.OrgMemberIDParam], memberOrganization.ID); findError != nil {
+			if errors.Is(findError, gorm.ErrRecordNotFound) {
+				baseHttpHandler.HandleError(responseWriter, request, findError, utils.NotFoundError, "Member not found.")
+			} else {
+				baseHttpHandler.HandleError(responseWriter, request, findError, utils.DatabaseError, "Error retrieving member.")
+			}
+			return
+		}
+		isLeaving := organizationMember.BelongsTo(currentUser.ID)
+		if !isLeaving && !models.OrgRoleAllowsManagement(organizationRole) {
+			baseHttpHandler.HandleError(responseWriter, request, nil, utils.ForbiddenError, "Forbidden: Only organization owners and admins can remove other members.")
+			return
+		}
+
+		reassignedEventCount, removeError := models.RemoveOrganizationMember(applicationContext.Database.WithContext(request.Context()), &organizationMember)
+		if removeError != nil {
+			if errors.Is(removeError, models.ErrOrganizationOwner) {
+				baseHttpHandler.HandleError(responseWriter, request, removeError, utils.ValidationError, removeError.Error())
+			} else {
+				baseHttpHandler.HandleError(responseWriter, request, removeError, utils.DatabaseError, "Failed to remove the member.")
+			}
+			return
+		}
+		logging.FromContext(request.Context()).Info("Organization member removed", "email", organizationMember.InvitedEmail, "organization_id", memberOrganization.ID, "member_id", organizationMember.ID, "events_handed_to_owner", reassignedEventCount)
+
+		if isLeaving {
+			http.Redirect(responseWriter, request, config.WebOrganizations, http.StatusSeeOther)
+			return
+		}
+		redirectToOrganization(responseWriter, request, memberOrganization.ID)
+	}
+}
@@ -0,0 +1,60 @@
+package organization
+
+import (
+	"net/http"
+
+	gconstants "github.com/temirov/GAuss/pkg/constants"
+	"github.com/temirov/GAuss/pkg/session"
+	"github.com/temirov/RSVP/models"
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/handlers"
+	"github.com/temirov/RSVP/pkg/logging"
+	"github.com/temirov/RSVP/pkg/middleware"
+	"github.com/temirov/RSVP/pkg/utils"
+)
+
+// SwitchWorkspaceHandler handles POST requests from the header's workspace switcher. It remembers the chosen
+// organization in the session, or the personal workspace when workspace_id is empty, and returns to the events page.
+func SwitchWorkspaceHandler(applicationContext *config.ApplicationContext) http.HandlerFunc {
+	baseHttpHandler := handlers.NewBaseHttpHandler(applicationContext, config.ResourceNameOrg, config.WebEvents)
+	return func(responseWriter http.ResponseWriter, request *http.Request) {
+		if !baseHttpHandler.ValidateHttpMethod(responseWriter, request, http.MethodPost) {
+			return
+		}
+		if err := request.ParseForm(); err != nil {
+			baseHttpHandler.HandleError(responseWriter, request, err, utils.ValidationError, utils.ErrMsgInvalidFormData)
+			return
+		}
+		currentUser := request.Context().Value(middleware.ContextKeyUser).(*models.User)
+		requestedWorkspaceID := request.PostFormValue(config.WorkspaceIDParam)
+		if requestedWorkspaceID != "" {
+			organizationRole, roleError := models.OrganizationRoleForUser(applicationContext.Database.WithContext(request.Context()), requestedWorkspaceID, currentUser.ID)
+			if roleError != nil {
+				baseHttpHandler.HandleError(responseWriter, request, roleError, utils.DatabaseError, "Could not verify organization membership.")
+				return
+			}
+			if organizationRole == "" {
+				baseHttpHandler.HandleError(responseWriter, request, nil, utils.ForbiddenError, "Forbidden: You are not a member of this organization.")
+				return
+			}
+		}
+
+		sessionInstance, sessionError := session.Store().Get(request, gconstants.SessionName)
+		if sessionError != nil {
+			baseHttpHandler.HandleError(responseWriter, request, sessionError, utils.ServerError, "Failed to process user session.")
+			return
+		}
+		if requestedWorkspaceID == "" {
+			delete(sessionInstance.Values, config.SessionKeyWorkspaceID)
+		} else {
+			sessionInstance.Values[config.SessionKeyWorkspaceID] = requestedWorkspaceID
+		}
+		if saveError := sessionInstance.Save(request, responseWriter); saveError != nil {
+			baseHttpHandler.HandleError(responseWriter, request, saveError, utils.ServerError, "Failed to save the active workspace.")
+			return
+		}
+		logging.FromContext(request.Context()).Info("Workspace switched", "workspace_id", requestedWorkspaceID)
+
+		baseHttpHandler.RedirectToList(responseWriter, request)
+	}
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/temirov/RSVP/models"
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/logging"
+	"github.com/temirov/RSVP/pkg/realtime"
+	"github.com/temirov/RSVP/pkg/utils"
+)
+
+// PublishRSVPChange notifies the open organizer pages of every user who can view the given event that one of its
+// RSVPs changed: the owner of a personal event or the members of the event's organization, and the co-hosts.
+// It recomputes the event's RSVP counts so list pages can refresh their statistics in place.
+// Failures are logged with the logger of requestContext and never affect the request that triggered the change.
+func PublishRSVPChange(requestContext context.Context, applicationContext *config.ApplicationContext, updateKind string, rsvpRecord *models.RSVP, parentEvent *models.Event) {
+	if applicationContext.Realtime == nil {
+		return
+	}
+	totalCount, answeredCount, countError := models.CountRSVPsByEventID(applicationContext.Database.WithContext(requestContext), parentEvent.ID)
+	if countError != nil {
+		logging.FromContext(requestContext).Warn("Failed to count RSVPs for a live update", "event_id", parentEvent.ID, "error", countError)
+		return
+	}
+	liveUpdate := realtime.Update{
+		Kind:    updateKind,
+		EventID: parentEvent.ID,
+		RSVP: &realtime.RSVPSnapshot{
+			ID:          rsvpRecord.ID,
+			Name:        rsvpRecord.Name,
+			Response:    rsvpRecord.Response,
+			ExtraGuests: rsvpRecord.ExtraGuests,
+		},
+		RSVPCount:         totalCount,
+		RSVPAnsweredCount: answeredCount,
+	}
+	updateTopics := []string{realtime.EventTopic(parentEvent.ID)}
+	viewerUserIDs, viewerError := models.FindViewerUserIDsForEvent(applicationContext.Database.WithContext(requestContext), parentEvent)
+	if viewerError != nil {
+		logging.FromContext(requestContext).Warn("Failed to load the users of a live update", "event_id", parentEvent.ID, "error", viewerError)
+	}
+	for _, viewerUserID := range viewerUserIDs {
+		updateTopics = append(updateTopics, realtime.OwnerTopic(viewerUserID))
+	}
+	applicationContext.Realtime.Publish(liveUpdate, updateTopics...)
+}
+
+// StreamUpdates serves a Server-Sent Events stream for the given topic until the client disconnects,
+// the server shuts down, or the broker drops the subscription because the client fell behind.
+// Every update is sent as an SSE message whose event name is the update kind.
+func (handler *BaseHttpHandler) StreamUpdates(responseWriter http.ResponseWriter, request *http.Request, topic string) {
+	if handler.ApplicationContext.Realtime == nil {
+		handler.HandleError(responseWriter, request, nil, utils.ServerError, "Live updates are not available.")
+		return
+	}
+	responseFlusher, canFlush := responseWriter.(http.Flusher)
+	if !canFlush {
+		handler.HandleError(responseWriter, request, nil, utils.ServerError, "Streaming is not supported by this connection.")
+		return
+	}
+
+	subscription := handler.ApplicationContext.Realtime.Subscribe(topic)
+	defer handler.ApplicationContext.Realtime.Unsubscribe(subscription)
+
+	responseHeaders := responseWriter.Header()
+	responseHeaders.Set("Content-Type", "text/event-stream")
+	responseHeaders.Set("Cache-Control", "no-cache")
+	responseHeaders.Set("Connection", "keep-alive")
+	responseHeaders.Set("X-Accel-Buffering", "no")
+	responseWriter.WriteHeader(http.StatusOK)
+	_, _ = fmt.Fprint(responseWriter, ": connected\n\n")
+	responseFlusher.Flush()
+
+	heartbeatTicker := time.NewTicker(config.StreamHeartbeatInterval)
+	defer heartbeatTicker.Stop()
+
+	for {
+		select {
+		case <-request.Context().Done():
+			return
+		case <-heartbeatTicker.C:
+			if _, writeError := fmt.Fprint(responseWriter, ": heartbeat\n\n"); writeError != nil {
+				return
+			}
+			responseFlusher.Flush()
+		case liveUpdate, isOpen := <-subscription.Updates:
+			if !isOpen {
+				logging.FromContext(request.Context()).Warn("Live update subscriber dropped", "topic", topic, "path", request.URL.Path)
+				return
+			}
+			encodedUpdate, encodeError := json.Marshal(liveUpdate)
+			if encodeError != nil {
+				logging.FromContext(request.Context()).Error("Failed to encode a live update", "topic", topic, "error", encodeError)
+				continue
+			}
+			if _, writeError := fmt.Fprintf(responseWriter, "event: %s\ndata: %s\n\n", liveUpdate.Kind, encodedUpdate); writeError != nil {
+				return
+			}
+			responseFlusher.Flush()
+		}
+	}
+}
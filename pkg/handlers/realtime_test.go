@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/temirov/RSVP/models"
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/realtime"
+	"github.com/temirov/RSVP/pkg/testdb"
+	"gorm.io/gorm"
+)
+
+// createStreamTestUser stores a user with the given address.
+func createStreamTestUser(t *testing.T, databaseConnection *gorm.DB, emailAddress string) *models.User {
+	t.Helper()
+	userRecord := &models.User{Email: emailAddress}
+	if err := databaseConnection.Create(userRecord).Error; err != nil {
+		t.Fatalf("creating %s: %v", emailAddress, err)
+	}
+	return userRecord
+}
+
+func TestPublishRSVPChangeReachesEveryoneWhoCanViewTheEvent(t *testing.T) {
+	databaseConnection := testdb.OpenMigrated(t)
+	applicationContext := &config.ApplicationContext{Database: databaseConnection, Realtime: realtime.NewBroker()}
+	organizationOwner := createStreamTestUser(t, databaseConnection, "owner@example.com")
+	organizationMember := createStreamTestUser(t, databaseConnection, "member@example.com")
+	cohost := createStreamTestUser(t, databaseConnection, "cohost@example.com")
+	stranger := createStreamTestUser(t, databaseConnection, "stranger@example.com")
+
+	organization, err := models.CreateOrganization(databaseConnection, "Club", organizationOwner)
+	if err != nil {
+		t.Fatalf("creating the organization: %v", err)
+	}
+	if _, err := models.InviteOrganizationMember(databaseConnection, organization.ID, organizationMember.Email, config.OrgRoleMember, organizationOwner.ID); err != nil {
+		t.Fatalf("inviting the member: %v", err)
+	}
+	if _, err := models.AcceptPendingOrganizationInvitations(databaseConnection, organizationMember); err != nil {
+		t.Fatalf("accepting the organization invitation: %v", err)
+	}
+	startTime := time.Date(2026, time.June, 1, 18, 0, 0, 0, time.UTC)
+	eventRecord := &models.Event{Title: "Club night", StartTime: startTime, EndTime: startTime.Add(time.Hour),
+		UserID: organizationMember.ID, OrganizationID: &organization.ID}
+	if err := eventRecord.Create(databaseConnection); err != nil {
+		t.Fatalf("creating the event: %v", err)
+	}
+	if _, err := models.InviteCohost(databaseConnection, eventRecord, cohost.Email, config.EventRoleCheckIn, organizationMember.ID); err != nil {
+		t.Fatalf("inviting the co-host: %v", err)
+	}
+	if _, err := models.AcceptPendingInvitations(databaseConnection, cohost); err != nil {
+		t.Fatalf("accepting the co-host invitation: %v", err)
+	}
+	rsvpRecord := &models.RSVP{Name: "Guest", EventID: eventRecord.ID, Response: config.RSVPResponsePending}
+	if err := rsvpRecord.Create(databaseConnection); err != nil {
+		t.Fatalf("creating the RSVP: %v", err)
+	}
+
+	listSubscriptions := map[string]*realtime.Subscription{}
+	for _, userRecord := range []*models.User{organizationOwner, organizationMember, cohost, stranger} {
+		listSubscriptions[userRecord.Email] = applicationContext.Realtime.Subscribe(realtime.OwnerTopic(userRecord.ID))
+	}
+	eventSubscription := applicationContext.Realtime.Subscribe(realtime.EventTopic(eventRecord.ID))
+
+	PublishRSVPChange(context.Background(), applicationContext, realtime.KindRSVPCreated, rsvpRecord, eventRecord)
+
+	for emailAddress, subscription := range listSubscriptions {
+		select {
+		case liveUpdate := <-subscription.Updates:
+			if emailAddress == stranger.Email {
+				t.Errorf("the events list of %s got %+v", emailAddress, liveUpdate)
+			} else if liveUpdate.EventID != eventRecord.ID || liveUpdate.RSVPCount != 1 {
+				t.Errorf("the events list of %s got %+v, want a count of 1 for the event", emailAddress, liveUpdate)
+			}
+		default:
+			if emailAddress != stranger.Email {
+				t.Errorf("the events list of %s got no update", emailAddress)
+			}
+		}
+	}
+	select {
+	case liveUpdate := <-eventSubscription.Updates:
+		if liveUpdate.RSVP == nil || liveUpdate.RSVP.ID != rsvpRecord.ID {
+			t.Errorf("the event page got %+v, want the RSVP", liveUpdate)
+		}
+	default:
+		t.Error("the event page got no update")
+	}
+}
+
+func TestStreamUpdatesUnsubscribesWhenTheClientDisconnects(t *testing.T) {
+	broker := realtime.NewBroker()
+	streamHandler := NewBaseHttpHandler(&config.ApplicationContext{Realtime: broker}, config.ResourceNameEvent, config.WebEventsStream)
+	streamTopic := realtime.OwnerTopic("user1")
+	streamServer := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		streamHandler.StreamUpdates(responseWriter, request, streamTopic)
+	}))
+	defer streamServer.Close()
+
+	streamResponse, err := http.Get(streamServer.URL)
+	if err != nil {
+		t.Fatalf("opening the stream: %v", err)
+	}
+	streamReader := bufio.NewReader(streamResponse.Body)
+	if connectedLine, err := streamReader.ReadString('\n'); err != nil || connectedLine != ": connected\n" {
+		t.Fatalf("first stream line = %q, %v, want the connected comment", connectedLine, err)
+	}
+	if subscriberCount := broker.SubscriberCount(); subscriberCount != 1 {
+		t.Fatalf("SubscriberCount() = %d while streaming, want 1", subscriberCount)
+	}
+	broker.Publish(realtime.Update{Kind: realtime.KindRSVPUpdated, EventID: "ev1"}, streamTopic)
+	for {
+		streamLine, err := streamReader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("reading the stream: %v", err)
+		}
+		if streamLine == "event: "+realtime.KindRSVPUpdated+"\n" {
+			break
+		}
+	}
+
+	_ = streamResponse.Body.Close()
+	waitDeadline := time.Now().Add(5 * time.Second)
+	for broker.SubscriberCount() != 0 {
+		if time.Now().After(waitDeadline) {
+			t.Fatal("the stream kept its subscription after the client disconnected")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,89 @@
+package response
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/temirov/RSVP/models"
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/handlers"
+	"github.com/temirov/RSVP/pkg/invitation"
+	"github.com/temirov/RSVP/pkg/logging"
+	"github.com/temirov/RSVP/pkg/ratelimit"
+	"github.com/temirov/RSVP/pkg/utils"
+)
+
+// openedInvitation is the RSVP an invitation link leads to, with its event and the query parameters that carry
+// the same link on to the response form and the thank-you page.
+type openedInvitation struct {
+	RSVP       models.RSVP
+	Event      models.Event
+	LinkParams map[string]string
+}
+
+// openInvitation resolves the invitation link of the request: a signed token in the invite parameter or, for events
+// that accept them, a bare RSVP code in the rsvp_id parameter. When the link leads nowhere it writes the error
+// response and returns false.
+func openInvitation(applicationContext *config.ApplicationContext, baseHandler *handlers.BaseHttpHandler, httpResponseWriter http.ResponseWriter, httpRequest *http.Request, publicLimiter *ratelimit.Limiter) (*openedInvitation, bool) {
+	queryValues := httpRequest.URL.Query()
+	var signedClaims *invitation.Claims
+	var rsvpCode string
+	var linkParams map[string]string
+	if invitationToken := queryValues.Get(config.InvitationTokenParam); invitationToken != "" {
+		verifiedClaims, verifyError := applicationContext.Invitations.Verify(invitationToken, time.Now())
+		if errors.Is(verifyError, invitation.ErrExpiredToken) {
+			baseHandler.HandleError(httpResponseWriter, httpRequest, verifyError, utils.NotFoundError, config.ErrMsgInvitationExpired)
+			return nil, false
+		}
+		if verifyError != nil {
+			rejectUnknownInvitation(baseHandler, httpResponseWriter, httpRequest, publicLimiter)
+			return nil, false
+		}
+		signedClaims = &verifiedClaims
+		rsvpCode = verifiedClaims.RSVPID
+		linkParams = map[string]string{config.InvitationTokenParam: invitationToken}
+	} else {
+		rsvpCode = queryValues.Get(config.RSVPIDParam)
+		linkParams = map[string]string{config.RSVPIDParam: rsvpCode}
+	}
+	if !handlers.ValidateRSVPCode(rsvpCode) {
+		rejectUnknownInvitation(baseHandler, httpResponseWriter, httpRequest, publicLimiter)
+		return nil, false
+	}
+
+	invitationOpened := &openedInvitation{LinkParams: linkParams}
+	findRsvpError := invitationOpened.RSVP.FindByCode(applicationContext.Database.WithContext(httpRequest.Context()), rsvpCode)
+	if findRsvpError != nil {
+		if errors.Is(findRsvpError, gorm.ErrRecordNotFound) {
+			rejectUnknownInvitation(baseHandler, httpResponseWriter, httpRequest, publicLimiter)
+		} else {
+			baseHandler.HandleError(httpResponseWriter, httpRequest, findRsvpError, utils.DatabaseError, "Sorry, we encountered an error retrieving the RSVP details.")
+		}
+		return nil, false
+	}
+	if signedClaims != nil && signedClaims.LinkVersion != invitationOpened.RSVP.LinkVersion {
+		// A revoked link is authentic, so it does not count toward the lockout, but it gets the same answer.
+		baseHandler.HandleError(httpResponseWriter, httpRequest, nil, utils.NotFoundError, config.ErrMsgInvitationNotFound)
+		return nil, false
+	}
+
+	eventError := invitationOpened.Event.LoadWithVenue(applicationContext.Database.WithContext(httpRequest.Context()), invitationOpened.RSVP.EventID)
+	if eventError != nil {
+		logging.FromContext(httpRequest.Context()).Error("Could not find the event of the RSVP", "event_id", invitationOpened.RSVP.EventID, "rsvp_id", rsvpCode, "error", eventError)
+		if errors.Is(eventError, gorm.ErrRecordNotFound) {
+			// The invitation of a deleted event looks like any unknown code, without counting against the guest.
+			baseHandler.HandleError(httpResponseWriter, httpRequest, eventError, utils.NotFoundError, config.ErrMsgInvitationNotFound)
+			return nil, false
+		}
+		baseHandler.HandleError(httpResponseWriter, httpRequest, eventError, utils.DatabaseError, "Sorry, we encountered an error loading event details.")
+		return nil, false
+	}
+	if signedClaims == nil && !invitationOpened.Event.AcceptBareCodes {
+		rejectUnknownInvitation(baseHandler, httpResponseWriter, httpRequest, publicLimiter)
+		return nil, false
+	}
+	return invitationOpened, true
+}
@@ -0,0 +1,32 @@
+package response
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/handlers"
+	"github.com/temirov/RSVP/pkg/ratelimit"
+	"github.com/temirov/RSVP/pkg/utils"
+)
+
+// allowPublicRequest applies the public page limits to the client and answers 429 with a Retry-After header when
+// they are exceeded. Refusals are counted by the limiter rather than logged one by one, so a flood of requests
+// does not flood the log too.
+func allowPublicRequest(httpResponseWriter http.ResponseWriter, httpRequest *http.Request, publicLimiter *ratelimit.Limiter) bool {
+	allowed, retryAfter := publicLimiter.Allow(utils.ClientIP(httpRequest))
+	if allowed {
+		return true
+	}
+	httpResponseWriter.Header().Set(config.RetryAfterHeader, strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	utils.HandleError(httpResponseWriter, httpRequest, nil, utils.TooManyRequestsError, config.ErrMsgTooManyRequests)
+	return false
+}
+
+// rejectUnknownInvitation answers a missing, malformed or unknown invitation code. Every such request gets the
+// same response, so the answer does not reveal whether a code exists, and counts toward the client's lockout.
+func rejectUnknownInvitation(baseHandler *handlers.BaseHttpHandler, httpResponseWriter http.ResponseWriter, httpRequest *http.Request, publicLimiter *ratelimit.Limiter) {
+	publicLimiter.RecordFailedLookup(utils.ClientIP(httpRequest))
+	baseHandler.HandleError(httpResponseWriter, httpRequest, nil, utils.NotFoundError, config.ErrMsgInvitationNotFound)
+}
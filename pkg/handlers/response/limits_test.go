@@ -0,0 +1,129 @@
+package response
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	rsvp "github.com/temirov/RSVP"
+	"github.com/temirov/RSVP/models"
+	"github.com/temirov/RSVP/pkg/assets"
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/invitation"
+	"github.com/temirov/RSVP/pkg/ratelimit"
+	"github.com/temirov/RSVP/pkg/templates"
+	"github.com/temirov/RSVP/pkg/testdb"
+)
+
+// newTestApplicationContext loads the embedded templates and returns an application context on a migrated test
+// database holding one event, which does not accept bare codes, with one RSVP.
+func newTestApplicationContext(t *testing.T) (*config.ApplicationContext, models.RSVP) {
+	t.Helper()
+	databaseConnection := testdb.OpenMigrated(t)
+	organizer := models.User{Email: "organizer@example.com"}
+	if err := databaseConnection.Create(&organizer).Error; err != nil {
+		t.Fatalf("creating the organizer: %v", err)
+	}
+	eventRecord := models.Event{Title: "Party", StartTime: time.Now().Add(24 * time.Hour), EndTime: time.Now().Add(26 * time.Hour), UserID: organizer.ID}
+	if err := eventRecord.Create(databaseConnection); err != nil {
+		t.Fatalf("creating the event: %v", err)
+	}
+	rsvpRecord := models.RSVP{Name: "Guest", EventID: eventRecord.ID, Response: config.RSVPResponsePending}
+	if err := rsvpRecord.Create(databaseConnection); err != nil {
+		t.Fatalf("creating the RSVP: %v", err)
+	}
+	templateFiles, err := assets.Templates(rsvp.EmbeddedFiles)
+	if err != nil {
+		t.Fatalf("opening the templates: %v", err)
+	}
+	templates.LoadAllPrecompiledTemplates(templateFiles)
+	applicationContext := &config.ApplicationContext{
+		Database:    databaseConnection,
+		Logger:      slog.New(slog.NewTextHandler(io.Discard, nil)),
+		AppBaseURL:  "http://localhost/",
+		AppTitle:    config.DefaultAppTitle,
+		Assets:      rsvp.EmbeddedFiles,
+		Invitations: invitation.NewSigner("test secret", 0),
+	}
+	return applicationContext, rsvpRecord
+}
+
+func newTestLimiter(settings config.RateLimitConfig) *ratelimit.Limiter {
+	return ratelimit.NewLimiter(settings, ratelimit.NewMemoryStore(time.Hour), slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func getInvitation(responseHandler http.Handler, queryValues url.Values) *httptest.ResponseRecorder {
+	invitationRequest := httptest.NewRequest(http.MethodGet, config.WebResponse+"?"+queryValues.Encode(), nil)
+	invitationRequest.RemoteAddr = "198.51.100.1:40000"
+	responseRecorder := httptest.NewRecorder()
+	responseHandler.ServeHTTP(responseRecorder, invitationRequest)
+	return responseRecorder
+}
+
+func TestUnknownAndInvalidInvitationsGetTheSameAnswer(t *testing.T) {
+	applicationContext, rsvpRecord := newTestApplicationContext(t)
+	responseHandler := Handler(applicationContext, newTestLimiter(config.RateLimitConfig{}))
+	testCases := map[string]url.Values{
+		"missing code":   {},
+		"malformed code": {config.RSVPIDParam: {"not-a-code!"}},
+		"unknown code":   {config.RSVPIDParam: {"zz9zz9"}},
+		"bare code of an event that needs signed links": {config.RSVPIDParam: {rsvpRecord.ID}},
+		"forged token": {config.InvitationTokenParam: {rsvpRecord.ID + ".0.0.forged"}},
+		"revoked token": {config.InvitationTokenParam: {applicationContext.Invitations.Sign(invitation.Claims{
+			RSVPID: rsvpRecord.ID, LinkVersion: rsvpRecord.LinkVersion + 1})}},
+	}
+	var expectedBody string
+	for name, queryValues := range testCases {
+		t.Run(name, func(t *testing.T) {
+			responseRecorder := getInvitation(responseHandler, queryValues)
+			if responseRecorder.Code != http.StatusNotFound {
+				t.Fatalf("status = %d, want %d", responseRecorder.Code, http.StatusNotFound)
+			}
+			if expectedBody == "" {
+				expectedBody = responseRecorder.Body.String()
+			}
+			if responseRecorder.Body.String() != expectedBody {
+				t.Errorf("body = %q, want the same body as the other invalid links, %q", responseRecorder.Body.String(), expectedBody)
+			}
+		})
+	}
+
+	validToken := applicationContext.Invitations.Sign(invitation.Claims{RSVPID: rsvpRecord.ID, LinkVersion: rsvpRecord.LinkVersion})
+	if responseRecorder := getInvitation(responseHandler, url.Values{config.InvitationTokenParam: {validToken}}); responseRecorder.Code != http.StatusOK {
+		t.Errorf("a valid invitation link was answered with %d", responseRecorder.Code)
+	}
+}
+
+func TestRateLimitedRequestsGetRetryAfter(t *testing.T) {
+	applicationContext, _ := newTestApplicationContext(t)
+	responseHandler := Handler(applicationContext, newTestLimiter(config.RateLimitConfig{PerClientPerMinute: 1}))
+	getInvitation(responseHandler, url.Values{config.RSVPIDParam: {"zz9zz9"}})
+	assertRetryAfter(t, getInvitation(responseHandler, url.Values{config.RSVPIDParam: {"zz9zz9"}}))
+}
+
+func TestLockedOutClientsGetRetryAfter(t *testing.T) {
+	applicationContext, _ := newTestApplicationContext(t)
+	responseHandler := Handler(applicationContext, newTestLimiter(config.RateLimitConfig{FailureThreshold: 2}))
+	for requestIndex := 0; requestIndex < 2; requestIndex++ {
+		if responseRecorder := getInvitation(responseHandler, url.Values{config.RSVPIDParam: {"zz9zz9"}}); responseRecorder.Code != http.StatusNotFound {
+			t.Fatalf("request %d: status = %d, want %d", requestIndex+1, responseRecorder.Code, http.StatusNotFound)
+		}
+	}
+	assertRetryAfter(t, getInvitation(responseHandler, url.Values{config.RSVPIDParam: {"zz9zz9"}}))
+}
+
+func assertRetryAfter(t *testing.T, responseRecorder *httptest.ResponseRecorder) {
+	t.Helper()
+	if responseRecorder.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want %d", responseRecorder.Code, http.StatusTooManyRequests)
+	}
+	retryAfterSeconds, err := strconv.Atoi(responseRecorder.Header().Get(config.RetryAfterHeader))
+	if err != nil || retryAfterSeconds < 1 {
+		t.Errorf("%s = %q, want a positive number of seconds", config.RetryAfterHeader, responseRecorder.Header().Get(config.RetryAfterHeader))
+	}
+}
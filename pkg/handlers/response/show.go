@@ -6,12 +6,14 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
-
-	"gorm.io/gorm"
+	"time"
 
 	"github.com/temirov/RSVP/models"
 	"github.com/temirov/RSVP/pkg/config"
 	"github.com/temirov/RSVP/pkg/handlers"
+	"github.com/temirov/RSVP/pkg/logging"
+	"github.com/temirov/RSVP/pkg/ratelimit"
+	"github.com/temirov/RSVP/pkg/realtime"
 	"github.com/temirov/RSVP/pkg/utils"
 )
 
@@ -38,50 +40,32 @@ type ThankYouViewData struct {
 
 // Handler processes requests for the public RSVP response page.
 // It handles GET requests to display the form and PUT requests (via POST override) to submit the response.
-// It requires a valid RSVP ID (code) in the query parameters.
+// It requires a signed invitation token, or a bare RSVP code for events that accept them, in the query parameters.
 // Assumes backend expects separate 'response' ('Yes'/'No') and 'extra_guests' parameters.
-func Handler(applicationContext *config.ApplicationContext) http.HandlerFunc {
+// Requests are throttled by publicLimiter, and unknown codes all get the same answer.
+func Handler(applicationContext *config.ApplicationContext, publicLimiter *ratelimit.Limiter) http.HandlerFunc {
 	baseHandler := handlers.NewBaseHttpHandler(applicationContext, config.ResourceNameResponse, config.WebResponse)
 
 	return func(httpResponseWriter http.ResponseWriter, httpRequest *http.Request) {
-		rsvpCode := httpRequest.URL.Query().Get(config.RSVPIDParam)
-		if rsvpCode == "" {
-			baseHandler.HandleError(httpResponseWriter, nil, utils.ValidationError, "RSVP identifier is missing from the URL.")
-			return
-		}
-		if !handlers.ValidateRSVPCode(rsvpCode) {
-			baseHandler.HandleError(httpResponseWriter, nil, utils.ValidationError, "Invalid RSVP identifier format.")
+		if !allowPublicRequest(httpResponseWriter, httpRequest, publicLimiter) {
 			return
 		}
-
-		var rsvpRecord models.RSVP
-		findRsvpError := rsvpRecord.FindByCode(applicationContext.Database, rsvpCode)
-		if findRsvpError != nil {
-			if errors.Is(findRsvpError, gorm.ErrRecordNotFound) {
-				baseHandler.HandleError(httpResponseWriter, findRsvpError, utils.NotFoundError, "Invalid or expired RSVP identifier.")
-			} else {
-				baseHandler.HandleError(httpResponseWriter, findRsvpError, utils.DatabaseError, "Sorry, we encountered an error retrieving the RSVP details.")
-			}
-			return
-		}
-
-		var eventRecord models.Event
-		eventError := eventRecord.LoadWithVenue(applicationContext.Database, rsvpRecord.EventID)
-		if eventError != nil {
-			applicationContext.Logger.Printf("ERROR: Could not find event %s associated with RSVP %s (using LoadWithVenue): %v", rsvpRecord.EventID, rsvpCode, eventError)
-			errorType := utils.DatabaseError
-			userMessage := "Sorry, we encountered an error loading event details."
-			if errors.Is(eventError, gorm.ErrRecordNotFound) {
-				errorType = utils.ServerError
-				userMessage = "Could not load event details: The associated event seems to be missing."
-			}
-			baseHandler.HandleError(httpResponseWriter, eventError, errorType, userMessage)
+		invitationOpened, invitationFound := openInvitation(applicationContext, &baseHandler, httpResponseWriter, httpRequest, publicLimiter)
+		if !invitationFound {
 			return
 		}
+		rsvpRecord := invitationOpened.RSVP
+		eventRecord := invitationOpened.Event
 
 		switch httpRequest.Method {
 		case http.MethodGet:
-			submitURL := utils.BuildRelativeURL(config.WebResponse, map[string]string{config.RSVPIDParam: rsvpCode})
+			if utils.IsLinkPreviewRequest(httpRequest) || isOrganizerPreview(applicationContext, httpRequest, &eventRecord) {
+				logging.FromContext(httpRequest.Context()).Debug("Not counting the view of the RSVP (preview or organizer)", "rsvp_id", rsvpRecord.ID)
+			} else if viewError := rsvpRecord.RecordView(applicationContext.Database.WithContext(httpRequest.Context()), time.Now()); viewError != nil {
+				logging.FromContext(httpRequest.Context()).Warn("Failed to record the view of the RSVP", "rsvp_id", rsvpRecord.ID, "error", viewError)
+			}
+
+			submitURL := utils.BuildRelativeURL(config.WebResponse, invitationOpened.LinkParams)
 
 			viewData := ViewData{
 				RSVP:                 rsvpRecord,
@@ -98,7 +82,7 @@ func Handler(applicationContext *config.ApplicationContext) http.HandlerFunc {
 		case http.MethodPut:
 			if parseError := httpRequest.ParseForm(); parseError != nil {
 				if !errors.Is(parseError, http.ErrNotMultipart) {
-					baseHandler.HandleError(httpResponseWriter, parseError, utils.ValidationError, "Invalid form submission data.")
+					baseHandler.HandleError(httpResponseWriter, httpRequest, parseError, utils.ValidationError, "Invalid form submission data.")
 					return
 				}
 			}
@@ -108,7 +92,7 @@ func Handler(applicationContext *config.ApplicationContext) http.HandlerFunc {
 			var extraGuests int = 0
 
 			if validationError := utils.ValidateRSVPResponseStatus(responseStatus); validationError != nil {
-				baseHandler.HandleError(httpResponseWriter, validationError, utils.ValidationError, validationError.Error())
+				baseHandler.HandleError(httpResponseWriter, httpRequest, validationError, utils.ValidationError, validationError.Error())
 				return
 			}
 
@@ -116,11 +100,11 @@ func Handler(applicationContext *config.ApplicationContext) http.HandlerFunc {
 				var parseErr error
 				extraGuests, parseErr = strconv.Atoi(extraGuestsStr)
 				if parseErr != nil {
-					baseHandler.HandleError(httpResponseWriter, parseErr, utils.ValidationError, "Invalid value provided for extra guests.")
+					baseHandler.HandleError(httpResponseWriter, httpRequest, parseErr, utils.ValidationError, "Invalid value provided for extra guests.")
 					return
 				}
 				if validationError := utils.ValidateExtraGuests(extraGuests); validationError != nil {
-					baseHandler.HandleError(httpResponseWriter, validationError, utils.ValidationError, validationError.Error())
+					baseHandler.HandleError(httpResponseWriter, httpRequest, validationError, utils.ValidationError, validationError.Error())
 					return
 				}
 				rsvpRecord.Response = config.RSVPResponseYesPrefix
@@ -129,16 +113,19 @@ func Handler(applicationContext *config.ApplicationContext) http.HandlerFunc {
 				rsvpRecord.Response = config.RSVPResponseNoCommaZero
 				rsvpRecord.ExtraGuests = 0
 			} else {
-				baseHandler.HandleError(httpResponseWriter, nil, utils.ValidationError, "Invalid response status submitted.")
+				baseHandler.HandleError(httpResponseWriter, httpRequest, nil, utils.ValidationError, "Invalid response status submitted.")
 				return
 			}
 
-			if saveError := rsvpRecord.Save(applicationContext.Database); saveError != nil {
-				baseHandler.HandleError(httpResponseWriter, saveError, utils.DatabaseError, "Failed to save your RSVP response. Please try again.")
+			rsvpRecord.TrackResponseTime(time.Now())
+			if saveError := rsvpRecord.Save(applicationContext.Database.WithContext(httpRequest.Context())); saveError != nil {
+				baseHandler.HandleError(httpResponseWriter, httpRequest, saveError, utils.DatabaseError, "Failed to save your RSVP response. Please try again.")
 				return
 			}
+			applicationContext.Metrics.CountRSVPResponse(rsvpRecord.Response, config.MetricsSourceGuest)
+			handlers.PublishRSVPChange(httpRequest.Context(), applicationContext, realtime.KindRSVPUpdated, &rsvpRecord, &eventRecord)
 
-			redirectURL := utils.BuildRelativeURL(config.WebResponseThankYou, map[string]string{config.RSVPIDParam: rsvpCode})
+			redirectURL := utils.BuildRelativeURL(config.WebResponseThankYou, invitationOpened.LinkParams)
 			http.Redirect(httpResponseWriter, httpRequest, redirectURL, http.StatusSeeOther)
 
 		default:
@@ -147,36 +134,42 @@ func Handler(applicationContext *config.ApplicationContext) http.HandlerFunc {
 	}
 }
 
+// isOrganizerPreview reports whether the request comes from a signed-in owner, co-host or organization member of the event,
+// who is checking what the invitation looks like rather than responding to it.
+func isOrganizerPreview(applicationContext *config.ApplicationContext, httpRequest *http.Request, eventRecord *models.Event) bool {
+	sessionUserData := handlers.GetUserData(httpRequest)
+	if sessionUserData.UserEmail == "" {
+		return false
+	}
+	var sessionUser models.User
+	if findError := sessionUser.FindByEmail(applicationContext.Database.WithContext(httpRequest.Context()), sessionUserData.UserEmail); findError != nil {
+		return false
+	}
+	eventRole, roleError := models.EventRoleForUser(applicationContext.Database.WithContext(httpRequest.Context()), eventRecord, sessionUser.ID)
+	if roleError != nil {
+		return false
+	}
+	return eventRole != ""
+}
+
 // ThankYouHandler handles GET requests for the public "Thank You" page displayed after RSVP submission.
-// It requires a valid RSVP ID (code) in the query parameters.
-func ThankYouHandler(applicationContext *config.ApplicationContext) http.HandlerFunc {
+// It reads the same invitation link as Handler and shares its limits.
+func ThankYouHandler(applicationContext *config.ApplicationContext, publicLimiter *ratelimit.Limiter) http.HandlerFunc {
 	baseHandler := handlers.NewBaseHttpHandler(applicationContext, config.ResourceNameThankYou, config.WebResponseThankYou)
 
 	return func(httpResponseWriter http.ResponseWriter, httpRequest *http.Request) {
 		if !baseHandler.ValidateHttpMethod(httpResponseWriter, httpRequest, http.MethodGet) {
 			return
 		}
-
-		rsvpCode := httpRequest.URL.Query().Get(config.RSVPIDParam)
-		if rsvpCode == "" {
-			baseHandler.HandleError(httpResponseWriter, nil, utils.ValidationError, "RSVP identifier is missing.")
-			return
-		}
-		if !handlers.ValidateRSVPCode(rsvpCode) {
-			baseHandler.HandleError(httpResponseWriter, nil, utils.ValidationError, "Invalid RSVP identifier format.")
+		if !allowPublicRequest(httpResponseWriter, httpRequest, publicLimiter) {
 			return
 		}
 
-		var rsvpRecord models.RSVP
-		findRsvpError := rsvpRecord.FindByCode(applicationContext.Database, rsvpCode)
-		if findRsvpError != nil {
-			if errors.Is(findRsvpError, gorm.ErrRecordNotFound) {
-				baseHandler.HandleError(httpResponseWriter, findRsvpError, utils.NotFoundError, "Invalid or expired RSVP identifier.")
-			} else {
-				baseHandler.HandleError(httpResponseWriter, findRsvpError, utils.DatabaseError, "Error retrieving RSVP details.")
-			}
+		invitationOpened, invitationFound := openInvitation(applicationContext, &baseHandler, httpResponseWriter, httpRequest, publicLimiter)
+		if !invitationFound {
 			return
 		}
+		rsvpRecord := invitationOpened.RSVP
 
 		var thankYouMessageText string
 		if rsvpRecord.Response == config.RSVPResponseYesPrefix {
@@ -192,7 +185,7 @@ func ThankYouHandler(applicationContext *config.ApplicationContext) http.Handler
 			thankYouMessageText = "Thank you for letting us know you can't make it."
 		}
 
-		changeResponseURL := utils.BuildRelativeURL(config.WebResponse, map[string]string{config.RSVPIDParam: rsvpCode})
+		changeResponseURL := utils.BuildRelativeURL(config.WebResponse, invitationOpened.LinkParams)
 
 		viewData := ThankYouViewData{
 			Name:                 rsvpRecord.Name,
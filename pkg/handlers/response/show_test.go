@@ -0,0 +1,96 @@
+package response
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	gconstants "github.com/temirov/GAuss/pkg/constants"
+	"github.com/temirov/GAuss/pkg/session"
+	"github.com/temirov/RSVP/models"
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/invitation"
+)
+
+// signedInCookies returns the session cookies of a user signed in with emailAddress.
+func signedInCookies(t *testing.T, emailAddress string) []*http.Cookie {
+	t.Helper()
+	session.NewSession([]byte("0123456789abcdef0123456789abcdef"))
+	sessionRequest := httptest.NewRequest(http.MethodGet, "/", nil)
+	webSession, err := session.Store().Get(sessionRequest, gconstants.SessionName)
+	if err != nil {
+		t.Fatalf("opening the session: %v", err)
+	}
+	webSession.Values[gconstants.SessionKeyUserEmail] = emailAddress
+	sessionRecorder := httptest.NewRecorder()
+	if err := webSession.Save(sessionRequest, sessionRecorder); err != nil {
+		t.Fatalf("saving the session: %v", err)
+	}
+	return sessionRecorder.Result().Cookies()
+}
+
+func TestOrganizersOpeningAnInvitationAreNotCountedAsViews(t *testing.T) {
+	applicationContext, rsvpRecord := newTestApplicationContext(t)
+	responseHandler := Handler(applicationContext, newTestLimiter(config.RateLimitConfig{}))
+	var eventRecord models.Event
+	if err := applicationContext.Database.First(&eventRecord, "id = ?", rsvpRecord.EventID).Error; err != nil {
+		t.Fatalf("loading the event: %v", err)
+	}
+	coHost := models.User{Email: "cohost@example.com"}
+	if err := applicationContext.Database.Create(&coHost).Error; err != nil {
+		t.Fatalf("creating the co-host: %v", err)
+	}
+	acceptedAt := time.Now()
+	coHostMembership := models.EventMembership{EventID: eventRecord.ID, UserID: &coHost.ID, InvitedEmail: coHost.Email,
+		Role: config.EventRoleCheckIn, InvitedByUserID: eventRecord.UserID, AcceptedAt: &acceptedAt}
+	if err := applicationContext.Database.Create(&coHostMembership).Error; err != nil {
+		t.Fatalf("creating the co-host membership: %v", err)
+	}
+	stranger := models.User{Email: "stranger@example.com"}
+	if err := applicationContext.Database.Create(&stranger).Error; err != nil {
+		t.Fatalf("creating the stranger: %v", err)
+	}
+
+	invitationToken := applicationContext.Invitations.Sign(invitation.Claims{RSVPID: rsvpRecord.ID, LinkVersion: rsvpRecord.LinkVersion})
+	testCases := []struct {
+		name         string
+		emailAddress string
+		wantCounted  bool
+	}{
+		{name: "owner", emailAddress: "organizer@example.com", wantCounted: false},
+		{name: "co-host", emailAddress: coHost.Email, wantCounted: false},
+		{name: "signed-in user without a role", emailAddress: stranger.Email, wantCounted: true},
+		{name: "guest", emailAddress: "", wantCounted: true},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var viewsBefore models.RSVP
+			if err := applicationContext.Database.First(&viewsBefore, "id = ?", rsvpRecord.ID).Error; err != nil {
+				t.Fatalf("loading the RSVP: %v", err)
+			}
+			invitationRequest := httptest.NewRequest(http.MethodGet, config.WebResponse+"?"+url.Values{config.InvitationTokenParam: {invitationToken}}.Encode(), nil)
+			invitationRequest.RemoteAddr = "198.51.100.1:40000"
+			// Requests without a user agent are taken for link-preview bots and never counted.
+			invitationRequest.Header.Set("User-Agent", "Mozilla/5.0")
+			if testCase.emailAddress != "" {
+				for _, sessionCookie := range signedInCookies(t, testCase.emailAddress) {
+					invitationRequest.AddCookie(sessionCookie)
+				}
+			}
+			responseRecorder := httptest.NewRecorder()
+			responseHandler.ServeHTTP(responseRecorder, invitationRequest)
+			if responseRecorder.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d", responseRecorder.Code, http.StatusOK)
+			}
+			var viewsAfter models.RSVP
+			if err := applicationContext.Database.First(&viewsAfter, "id = ?", rsvpRecord.ID).Error; err != nil {
+				t.Fatalf("loading the RSVP: %v", err)
+			}
+			if counted := viewsAfter.ViewCount > viewsBefore.ViewCount; counted != testCase.wantCounted {
+				t.Errorf("view counted = %t, want %t", counted, testCase.wantCounted)
+			}
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/temirov/RSVP/pkg/config"
 	"github.com/temirov/RSVP/pkg/handlers"
 	"github.com/temirov/RSVP/pkg/middleware"
+	"github.com/temirov/RSVP/pkg/realtime"
 	"github.com/temirov/RSVP/pkg/utils"
 	"gorm.io/gorm"
 )
@@ -25,7 +26,7 @@ func CreateHandler(applicationContext *config.ApplicationContext) http.HandlerFu
 		currentUser := httpRequest.Context().Value(middleware.ContextKeyUser).(*models.User)
 
 		if err := httpRequest.ParseForm(); err != nil {
-			baseHandler.HandleError(httpResponseWriter, err, utils.ValidationError, utils.ErrMsgInvalidFormData)
+			baseHandler.HandleError(httpResponseWriter, httpRequest, err, utils.ValidationError, utils.ErrMsgInvalidFormData)
 			return
 		}
 
@@ -33,29 +34,29 @@ func CreateHandler(applicationContext *config.ApplicationContext) http.HandlerFu
 		if eventID == "" {
 			eventID = httpRequest.URL.Query().Get(config.EventIDParam)
 			if eventID == "" {
-				baseHandler.HandleError(httpResponseWriter, nil, utils.ValidationError, "Event ID is required to create an RSVP.")
+				baseHandler.HandleError(httpResponseWriter, httpRequest, nil, utils.ValidationError, "Event ID is required to create an RSVP.")
 				return
 			}
 		}
 
 		var parentEvent models.Event
-		eventFindError := applicationContext.Database.First(&parentEvent, "id = ?", eventID).Error
+		eventFindError := applicationContext.Database.WithContext(httpRequest.Context()).First(&parentEvent, "id = ?", eventID).Error
 		if eventFindError != nil {
 			if errors.Is(eventFindError, gorm.ErrRecordNotFound) {
-				baseHandler.HandleError(httpResponseWriter, eventFindError, utils.NotFoundError, "Parent event not found.")
+				baseHandler.HandleError(httpResponseWriter, httpRequest, eventFindError, utils.NotFoundError, "Parent event not found.")
 			} else {
-				baseHandler.HandleError(httpResponseWriter, eventFindError, utils.DatabaseError, "Error retrieving parent event.")
+				baseHandler.HandleError(httpResponseWriter, httpRequest, eventFindError, utils.DatabaseError, "Error retrieving parent event.")
 			}
 			return
 		}
 
-		if !baseHandler.VerifyResourceOwnership(httpResponseWriter, httpRequest, parentEvent.UserID, currentUser.ID) {
+		if baseHandler.AuthorizeEventAccess(httpResponseWriter, httpRequest, &parentEvent, currentUser.ID, models.PermissionManageRSVPs) == "" {
 			return
 		}
 
 		rsvpName := httpRequest.FormValue(config.NameParam)
 		if validationError := utils.ValidateRSVPName(rsvpName); validationError != nil {
-			baseHandler.HandleError(httpResponseWriter, validationError, utils.ValidationError, validationError.Error())
+			baseHandler.HandleError(httpResponseWriter, httpRequest, validationError, utils.ValidationError, validationError.Error())
 			return
 		}
 
@@ -64,10 +65,11 @@ func CreateHandler(applicationContext *config.ApplicationContext) http.HandlerFu
 			EventID: eventID,
 		}
 
-		if createError := newRSVP.Create(applicationContext.Database); createError != nil {
-			baseHandler.HandleError(httpResponseWriter, createError, utils.DatabaseError, "Failed to create the RSVP.")
+		if createError := newRSVP.Create(applicationContext.Database.WithContext(httpRequest.Context())); createError != nil {
+			baseHandler.HandleError(httpResponseWriter, httpRequest, createError, utils.DatabaseError, "Failed to create the RSVP.")
 			return
 		}
+		handlers.PublishRSVPChange(httpRequest.Context(), applicationContext, realtime.KindRSVPCreated, &newRSVP, &parentEvent)
 
 		redirectParams := map[string]string{
 			config.EventIDParam: eventID,
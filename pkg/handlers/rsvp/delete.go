@@ -8,6 +8,7 @@ import (
 	"github.com/temirov/RSVP/pkg/config"
 	"github.com/temirov/RSVP/pkg/handlers"
 	"github.com/temirov/RSVP/pkg/middleware"
+	"github.com/temirov/RSVP/pkg/realtime"
 	"github.com/temirov/RSVP/pkg/utils"
 	"gorm.io/gorm"
 )
@@ -30,35 +31,36 @@ func DeleteHandler(applicationContext *config.ApplicationContext) http.HandlerFu
 		targetRsvpID := params[config.RSVPIDParam]
 
 		var rsvpRecord models.RSVP
-		if findError := applicationContext.Database.First(&rsvpRecord, "id = ?", targetRsvpID).Error; findError != nil {
+		if findError := applicationContext.Database.WithContext(httpRequest.Context()).First(&rsvpRecord, "id = ?", targetRsvpID).Error; findError != nil {
 			if errors.Is(findError, gorm.ErrRecordNotFound) {
-				baseHandler.HandleError(httpResponseWriter, findError, utils.NotFoundError, "RSVP not found.")
+				baseHandler.HandleError(httpResponseWriter, httpRequest, findError, utils.NotFoundError, "RSVP not found.")
 			} else {
-				baseHandler.HandleError(httpResponseWriter, findError, utils.DatabaseError, "Error retrieving RSVP details.")
+				baseHandler.HandleError(httpResponseWriter, httpRequest, findError, utils.DatabaseError, "Error retrieving RSVP details.")
 			}
 			return
 		}
 		parentEventID := rsvpRecord.EventID
 
 		var parentEvent models.Event
-		eventFindError := applicationContext.Database.First(&parentEvent, "id = ?", parentEventID).Error
+		eventFindError := applicationContext.Database.WithContext(httpRequest.Context()).First(&parentEvent, "id = ?", parentEventID).Error
 		if eventFindError != nil {
 			if errors.Is(eventFindError, gorm.ErrRecordNotFound) {
-				baseHandler.HandleError(httpResponseWriter, eventFindError, utils.NotFoundError, "Parent event not found for RSVP.")
+				baseHandler.HandleError(httpResponseWriter, httpRequest, eventFindError, utils.NotFoundError, "Parent event not found for RSVP.")
 			} else {
-				baseHandler.HandleError(httpResponseWriter, eventFindError, utils.DatabaseError, "Error retrieving parent event.")
+				baseHandler.HandleError(httpResponseWriter, httpRequest, eventFindError, utils.DatabaseError, "Error retrieving parent event.")
 			}
 			return
 		}
 
-		if !baseHandler.VerifyResourceOwnership(httpResponseWriter, httpRequest, parentEvent.UserID, currentUser.ID) {
+		if baseHandler.AuthorizeEventAccess(httpResponseWriter, httpRequest, &parentEvent, currentUser.ID, models.PermissionManageRSVPs) == "" {
 			return
 		}
 
-		if deleteError := applicationContext.Database.Delete(&rsvpRecord).Error; deleteError != nil {
-			baseHandler.HandleError(httpResponseWriter, deleteError, utils.DatabaseError, "Failed to delete the RSVP.")
+		if deleteError := applicationContext.Database.WithContext(httpRequest.Context()).Delete(&rsvpRecord).Error; deleteError != nil {
+			baseHandler.HandleError(httpResponseWriter, httpRequest, deleteError, utils.DatabaseError, "Failed to delete the RSVP.")
 			return
 		}
+		handlers.PublishRSVPChange(httpRequest.Context(), applicationContext, realtime.KindRSVPDeleted, &rsvpRecord, &parentEvent)
 
 		redirectParams := map[string]string{
 			config.EventIDParam: parentEventID,
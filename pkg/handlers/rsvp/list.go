@@ -9,6 +9,7 @@ import (
 	"github.com/temirov/RSVP/models"
 	"github.com/temirov/RSVP/pkg/config"
 	"github.com/temirov/RSVP/pkg/handlers"
+	"github.com/temirov/RSVP/pkg/logging"
 	"github.com/temirov/RSVP/pkg/middleware"
 	"github.com/temirov/RSVP/pkg/utils"
 )
@@ -20,7 +21,11 @@ type rsvpListViewData struct {
 	Event                   models.Event
 	URLForRSVPActions       string
 	URLForRSVPQRBase        string
+	URLForRSVPLinkReissue   string
 	URLForEventList         string
+	URLForEventEdit         string
+	URLForRSVPStream        string
+	RSVPAnsweredCount       int
 	ParamNameEventID        string
 	ParamNameRSVPID         string
 	ParamNameName           string
@@ -28,6 +33,11 @@ type rsvpListViewData struct {
 	ParamNameExtraGuests    string
 	ParamNameMethodOverride string
 	MaxGuestCount           int
+	EventRole               string
+	CanManageRSVPs          bool
+	CanRecordResponses      bool
+	CanViewQRCodes          bool
+	CanEditEvent            bool
 }
 
 // ListHandler handles GET requests for the RSVP list page (/rsvps/).
@@ -46,31 +56,33 @@ func ListHandler(applicationContext *config.ApplicationContext) http.HandlerFunc
 
 		var parentEvent models.Event
 		var selectedRsvpForEdit *models.RSVP
+		var eventRole string
 
 		if rsvpIDForEdit != "" {
 			var rsvpToEdit models.RSVP
-			rsvpFindError := applicationContext.Database.First(&rsvpToEdit, "id = ?", rsvpIDForEdit).Error
+			rsvpFindError := applicationContext.Database.WithContext(httpRequest.Context()).First(&rsvpToEdit, "id = ?", rsvpIDForEdit).Error
 			if rsvpFindError != nil {
 				if errors.Is(rsvpFindError, gorm.ErrRecordNotFound) {
-					baseHandler.HandleError(httpResponseWriter, rsvpFindError, utils.NotFoundError, "The specified RSVP was not found.")
+					baseHandler.HandleError(httpResponseWriter, httpRequest, rsvpFindError, utils.NotFoundError, "The specified RSVP was not found.")
 				} else {
-					baseHandler.HandleError(httpResponseWriter, rsvpFindError, utils.DatabaseError, "Error retrieving RSVP details for editing.")
+					baseHandler.HandleError(httpResponseWriter, httpRequest, rsvpFindError, utils.DatabaseError, "Error retrieving RSVP details for editing.")
 				}
 				return
 			}
 
-			eventFindError := applicationContext.Database.First(&parentEvent, "id = ?", rsvpToEdit.EventID).Error
+			eventFindError := applicationContext.Database.WithContext(httpRequest.Context()).First(&parentEvent, "id = ?", rsvpToEdit.EventID).Error
 			if eventFindError != nil {
-				applicationContext.Logger.Printf("ERROR: Could not find parent event %s for RSVP %s during edit request to %s", rsvpToEdit.EventID, rsvpIDForEdit, httpRequest.URL.Path)
+				logging.FromContext(httpRequest.Context()).Error("Could not find the parent event of the RSVP being edited", "event_id", rsvpToEdit.EventID, "rsvp_id", rsvpIDForEdit)
 				if errors.Is(eventFindError, gorm.ErrRecordNotFound) {
-					baseHandler.HandleError(httpResponseWriter, eventFindError, utils.NotFoundError, "Could not find the parent event for this RSVP.")
+					baseHandler.HandleError(httpResponseWriter, httpRequest, eventFindError, utils.NotFoundError, "Could not find the parent event for this RSVP.")
 				} else {
-					baseHandler.HandleError(httpResponseWriter, eventFindError, utils.DatabaseError, "Error retrieving parent event details.")
+					baseHandler.HandleError(httpResponseWriter, httpRequest, eventFindError, utils.DatabaseError, "Error retrieving parent event details.")
 				}
 				return
 			}
 
-			if !baseHandler.VerifyResourceOwnership(httpResponseWriter, httpRequest, parentEvent.UserID, currentUser.ID) {
+			eventRole = baseHandler.AuthorizeEventAccess(httpResponseWriter, httpRequest, &parentEvent, currentUser.ID, models.PermissionRecordResponses)
+			if eventRole == "" {
 				return
 			}
 
@@ -85,29 +97,30 @@ func ListHandler(applicationContext *config.ApplicationContext) http.HandlerFunc
 			eventID = parentEvent.ID
 
 		} else if eventID != "" {
-			eventFindError := applicationContext.Database.First(&parentEvent, "id = ?", eventID).Error
+			eventFindError := applicationContext.Database.WithContext(httpRequest.Context()).First(&parentEvent, "id = ?", eventID).Error
 			if eventFindError != nil {
 				if errors.Is(eventFindError, gorm.ErrRecordNotFound) {
-					baseHandler.HandleError(httpResponseWriter, eventFindError, utils.NotFoundError, "The specified event was not found.")
+					baseHandler.HandleError(httpResponseWriter, httpRequest, eventFindError, utils.NotFoundError, "The specified event was not found.")
 				} else {
-					baseHandler.HandleError(httpResponseWriter, eventFindError, utils.DatabaseError, "Error retrieving event details.")
+					baseHandler.HandleError(httpResponseWriter, httpRequest, eventFindError, utils.DatabaseError, "Error retrieving event details.")
 				}
 				return
 			}
 
-			if !baseHandler.VerifyResourceOwnership(httpResponseWriter, httpRequest, parentEvent.UserID, currentUser.ID) {
+			eventRole = baseHandler.AuthorizeEventAccess(httpResponseWriter, httpRequest, &parentEvent, currentUser.ID, models.PermissionViewRSVPs)
+			if eventRole == "" {
 				return
 			}
 			selectedRsvpForEdit = nil
 
 		} else {
-			baseHandler.HandleError(httpResponseWriter, nil, utils.ValidationError, "An event ID or RSVP ID must be specified to view RSVPs.")
+			baseHandler.HandleError(httpResponseWriter, httpRequest, nil, utils.ValidationError, "An event ID or RSVP ID must be specified to view RSVPs.")
 			return
 		}
 
-		rsvpRecords, rsvpRetrievalError := models.FindRSVPsByEventID(applicationContext.Database, eventID)
+		rsvpRecords, rsvpRetrievalError := models.FindRSVPsByEventID(applicationContext.Database.WithContext(httpRequest.Context()), eventID)
 		if rsvpRetrievalError != nil {
-			baseHandler.HandleError(httpResponseWriter, rsvpRetrievalError, utils.DatabaseError, "Could not retrieve the list of RSVPs for this event.")
+			baseHandler.HandleError(httpResponseWriter, httpRequest, rsvpRetrievalError, utils.DatabaseError, "Could not retrieve the list of RSVPs for this event.")
 			return
 		}
 
@@ -117,7 +130,11 @@ func ListHandler(applicationContext *config.ApplicationContext) http.HandlerFunc
 			Event:                   parentEvent,
 			URLForRSVPActions:       config.WebRSVPs,
 			URLForRSVPQRBase:        config.WebRSVPQR,
+			URLForRSVPLinkReissue:   config.WebRSVPLink,
 			URLForEventList:         config.WebEvents,
+			URLForEventEdit:         utils.BuildRelativeURL(config.WebEvents, map[string]string{config.EventIDParam: parentEvent.ID}),
+			URLForRSVPStream:        utils.BuildRelativeURL(config.WebRSVPStream, map[string]string{config.EventIDParam: eventID}),
+			RSVPAnsweredCount:       countAnsweredRSVPs(rsvpRecords),
 			ParamNameEventID:        config.EventIDParam,
 			ParamNameRSVPID:         config.RSVPIDParam,
 			ParamNameName:           config.NameParam,
@@ -125,8 +142,24 @@ func ListHandler(applicationContext *config.ApplicationContext) http.HandlerFunc
 			ParamNameExtraGuests:    config.ExtraGuestsParam,
 			ParamNameMethodOverride: config.MethodOverrideParam,
 			MaxGuestCount:           config.MaxGuestCount,
+			EventRole:               eventRole,
+			CanManageRSVPs:          models.RoleAllows(eventRole, models.PermissionManageRSVPs),
+			CanRecordResponses:      models.RoleAllows(eventRole, models.PermissionRecordResponses),
+			CanViewQRCodes:          models.RoleAllows(eventRole, models.PermissionViewQRCodes),
+			CanEditEvent:            models.RoleAllows(eventRole, models.PermissionEditEvent),
 		}
 
 		baseHandler.RenderView(httpResponseWriter, httpRequest, config.TemplateRSVPs, viewData)
 	}
 }
+
+// countAnsweredRSVPs returns how many RSVPs carry a response other than Pending.
+func countAnsweredRSVPs(rsvpRecords []models.RSVP) int {
+	answeredCount := 0
+	for _, rsvpRecord := range rsvpRecords {
+		if rsvpRecord.Response != "" && rsvpRecord.Response != config.RSVPResponsePending {
+			answeredCount++
+		}
+	}
+	return answeredCount
+}
@@ -0,0 +1,100 @@
+package rsvp
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	gconstants "github.com/temirov/GAuss/pkg/constants"
+	"github.com/temirov/GAuss/pkg/session"
+	rsvpapp "github.com/temirov/RSVP"
+	"github.com/temirov/RSVP/models"
+	"github.com/temirov/RSVP/pkg/assets"
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/middleware"
+	"github.com/temirov/RSVP/pkg/templates"
+	"github.com/temirov/RSVP/pkg/testdb"
+)
+
+// withSignedInSession adds the session cookies of a user signed in with emailAddress to the request.
+func withSignedInSession(t *testing.T, httpRequest *http.Request, emailAddress string) {
+	t.Helper()
+	session.NewSession([]byte("0123456789abcdef0123456789abcdef"))
+	webSession, err := session.Store().Get(httpRequest, gconstants.SessionName)
+	if err != nil {
+		t.Fatalf("opening the session: %v", err)
+	}
+	webSession.Values[gconstants.SessionKeyUserEmail] = emailAddress
+	sessionRecorder := httptest.NewRecorder()
+	if err := webSession.Save(httpRequest, sessionRecorder); err != nil {
+		t.Fatalf("saving the session: %v", err)
+	}
+	for _, sessionCookie := range sessionRecorder.Result().Cookies() {
+		httpRequest.AddCookie(sessionCookie)
+	}
+}
+
+func TestListShowsWhetherCodeOnlyLinksAreAccepted(t *testing.T) {
+	databaseConnection := testdb.OpenMigrated(t)
+	templateFiles, err := assets.Templates(rsvpapp.EmbeddedFiles)
+	if err != nil {
+		t.Fatalf("opening the templates: %v", err)
+	}
+	templates.LoadAllPrecompiledTemplates(templateFiles)
+	applicationContext := &config.ApplicationContext{
+		Database: databaseConnection,
+		Logger:   slog.New(slog.NewTextHandler(io.Discard, nil)),
+		AppTitle: config.DefaultAppTitle,
+		Assets:   rsvpapp.EmbeddedFiles,
+	}
+	organizer := &models.User{Email: "organizer@example.com"}
+	viewer := &models.User{Email: "viewer@example.com"}
+	for _, userRecord := range []*models.User{organizer, viewer} {
+		if err := databaseConnection.Create(userRecord).Error; err != nil {
+			t.Fatalf("creating %s: %v", userRecord.Email, err)
+		}
+	}
+	startTime := time.Now().Add(24 * time.Hour)
+	eventRecord := &models.Event{Title: "Party", StartTime: startTime, EndTime: startTime.Add(time.Hour), UserID: organizer.ID}
+	if err := eventRecord.Create(databaseConnection); err != nil {
+		t.Fatalf("creating the event: %v", err)
+	}
+	if _, err := models.InviteCohost(databaseConnection, eventRecord, viewer.Email, config.EventRoleViewer, organizer.ID); err != nil {
+		t.Fatalf("inviting the viewer: %v", err)
+	}
+	if _, err := models.AcceptPendingInvitations(databaseConnection, viewer); err != nil {
+		t.Fatalf("accepting the invitation: %v", err)
+	}
+	listHandler := ListHandler(applicationContext)
+	renderList := func(currentUser *models.User) string {
+		t.Helper()
+		listRequest := httptest.NewRequest(http.MethodGet, config.WebRSVPs+"?"+config.EventIDParam+"="+eventRecord.ID, nil)
+		withSignedInSession(t, listRequest, currentUser.Email)
+		listRequest = listRequest.WithContext(context.WithValue(listRequest.Context(), middleware.ContextKeyUser, currentUser))
+		responseRecorder := httptest.NewRecorder()
+		listHandler.ServeHTTP(responseRecorder, listRequest)
+		if responseRecorder.Code != http.StatusOK {
+			t.Fatalf("listing as %s: status = %d, body = %s", currentUser.Email, responseRecorder.Code, responseRecorder.Body.String())
+		}
+		return responseRecorder.Body.String()
+	}
+	editLink := `href="` + config.WebEvents + "?" + config.EventIDParam + "=" + eventRecord.ID + `"`
+
+	if pageBody := renderList(organizer); strings.Contains(pageBody, `id="bareCodesNotice"`) {
+		t.Error("the notice is shown for an event that only accepts signed links")
+	}
+	if err := databaseConnection.Model(eventRecord).UpdateColumn("accept_bare_codes", true).Error; err != nil {
+		t.Fatalf("turning code-only links on: %v", err)
+	}
+	if pageBody := renderList(organizer); !strings.Contains(pageBody, `id="bareCodesNotice"`) || !strings.Contains(pageBody, editLink) {
+		t.Error("the organizer is not told that code-only links are on, with a link to the event settings")
+	}
+	if pageBody := renderList(viewer); !strings.Contains(pageBody, `id="bareCodesNotice"`) || strings.Contains(pageBody, editLink) {
+		t.Error("a viewing co-host should see the notice without a link to settings they cannot change")
+	}
+}
@@ -0,0 +1,75 @@
+package rsvp
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/temirov/RSVP/models"
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/handlers"
+	"github.com/temirov/RSVP/pkg/logging"
+	"github.com/temirov/RSVP/pkg/middleware"
+	"github.com/temirov/RSVP/pkg/realtime"
+	"github.com/temirov/RSVP/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// ReissueLinkHandler handles POST requests to revoke an RSVP's invitation link and QR code and issue new ones.
+// It continues to the QR code page showing the new link.
+func ReissueLinkHandler(applicationContext *config.ApplicationContext) http.HandlerFunc {
+	baseHandler := handlers.NewBaseHttpHandler(applicationContext, config.ResourceNameRSVPLink, config.WebRSVPQR)
+
+	return func(httpResponseWriter http.ResponseWriter, httpRequest *http.Request) {
+		if !baseHandler.ValidateHttpMethod(httpResponseWriter, httpRequest, http.MethodPost) {
+			return
+		}
+
+		currentUser := httpRequest.Context().Value(middleware.ContextKeyUser).(*models.User)
+
+		params, paramsOk := baseHandler.RequireParams(httpResponseWriter, httpRequest, config.RSVPIDParam)
+		if !paramsOk {
+			return
+		}
+		targetRsvpID := params[config.RSVPIDParam]
+
+		var rsvpRecord models.RSVP
+		if findError := applicationContext.Database.WithContext(httpRequest.Context()).First(&rsvpRecord, "id = ?", targetRsvpID).Error; findError != nil {
+			if errors.Is(findError, gorm.ErrRecordNotFound) {
+				baseHandler.HandleError(httpResponseWriter, httpRequest, findError, utils.NotFoundError, "RSVP not found.")
+			} else {
+				baseHandler.HandleError(httpResponseWriter, httpRequest, findError, utils.DatabaseError, "Error retrieving RSVP details.")
+			}
+			return
+		}
+
+		var parentEvent models.Event
+		eventFindError := applicationContext.Database.WithContext(httpRequest.Context()).First(&parentEvent, "id = ?", rsvpRecord.EventID).Error
+		if eventFindError != nil {
+			if errors.Is(eventFindError, gorm.ErrRecordNotFound) {
+				baseHandler.HandleError(httpResponseWriter, httpRequest, eventFindError, utils.NotFoundError, "Parent event not found for RSVP.")
+			} else {
+				baseHandler.HandleError(httpResponseWriter, httpRequest, eventFindError, utils.DatabaseError, "Error retrieving parent event.")
+			}
+			return
+		}
+
+		if baseHandler.AuthorizeEventAccess(httpResponseWriter, httpRequest, &parentEvent, currentUser.ID, models.PermissionManageRSVPs) == "" {
+			return
+		}
+
+		previousRSVP := rsvpRecord
+		previousCode, reissueError := rsvpRecord.ReissueLink(applicationContext.Database.WithContext(httpRequest.Context()), parentEvent.AcceptBareCodes)
+		if reissueError != nil {
+			baseHandler.HandleError(httpResponseWriter, httpRequest, reissueError, utils.DatabaseError, "Failed to reissue the invitation link.")
+			return
+		}
+		logging.FromContext(httpRequest.Context()).Info("Invitation link reissued", "previous_rsvp_id", previousCode, "rsvp_id", rsvpRecord.ID)
+		if previousCode != rsvpRecord.ID {
+			// Open RSVP lists know the row by its code, so a new code replaces the row.
+			handlers.PublishRSVPChange(httpRequest.Context(), applicationContext, realtime.KindRSVPDeleted, &previousRSVP, &parentEvent)
+			handlers.PublishRSVPChange(httpRequest.Context(), applicationContext, realtime.KindRSVPCreated, &rsvpRecord, &parentEvent)
+		}
+
+		baseHandler.RedirectWithParams(httpResponseWriter, httpRequest, map[string]string{config.RSVPIDParam: rsvpRecord.ID})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/base64"
 	"errors"
 	"net/http"
+	"time"
 
 	"github.com/skip2/go-qrcode"
 	"gorm.io/gorm"
@@ -11,16 +12,19 @@ import (
 	"github.com/temirov/RSVP/models"
 	"github.com/temirov/RSVP/pkg/config"
 	"github.com/temirov/RSVP/pkg/handlers"
+	"github.com/temirov/RSVP/pkg/logging"
 	"github.com/temirov/RSVP/pkg/middleware"
 	"github.com/temirov/RSVP/pkg/utils"
 )
 
 // ShowViewData holds data for the rsvp.tmpl (QR code display) view.
 type ShowViewData struct {
-	RSVP           models.RSVP
-	Event          models.Event
-	QRCode         string
-	PublicURL      string
+	RSVP      models.RSVP
+	Event     models.Event
+	QRCode    string
+	PublicURL string
+	// LinkExpiresAt is when PublicURL stops working, or nil if it never does.
+	LinkExpiresAt  *time.Time
 	URLForRSVPList string
 	ParamEventID   string
 	ParamRSVPID    string
@@ -39,67 +43,74 @@ func ShowHandler(applicationContext *config.ApplicationContext) http.HandlerFunc
 
 		rsvpID := baseHandler.GetParam(httpRequest, config.RSVPIDParam)
 		if rsvpID == "" {
-			baseHandler.HandleError(httpResponseWriter, nil, utils.ValidationError, "RSVP ID is required to view the QR code.")
+			baseHandler.HandleError(httpResponseWriter, httpRequest, nil, utils.ValidationError, "RSVP ID is required to view the QR code.")
 			return
 		}
 		if !handlers.ValidateRSVPCode(rsvpID) {
-			baseHandler.HandleError(httpResponseWriter, nil, utils.ValidationError, "Invalid RSVP ID format.")
+			baseHandler.HandleError(httpResponseWriter, httpRequest, nil, utils.ValidationError, "Invalid RSVP ID format.")
 			return
 		}
 
 		var rsvpRecord models.RSVP
-		findRsvpError := applicationContext.Database.First(&rsvpRecord, "id = ?", rsvpID).Error
+		findRsvpError := applicationContext.Database.WithContext(httpRequest.Context()).First(&rsvpRecord, "id = ?", rsvpID).Error
 		if findRsvpError != nil {
 			if errors.Is(findRsvpError, gorm.ErrRecordNotFound) {
-				baseHandler.HandleError(httpResponseWriter, findRsvpError, utils.NotFoundError, "The specified RSVP was not found.")
+				baseHandler.HandleError(httpResponseWriter, httpRequest, findRsvpError, utils.NotFoundError, "The specified RSVP was not found.")
 			} else {
-				baseHandler.HandleError(httpResponseWriter, findRsvpError, utils.DatabaseError, "Error retrieving RSVP details.")
+				baseHandler.HandleError(httpResponseWriter, httpRequest, findRsvpError, utils.DatabaseError, "Error retrieving RSVP details.")
 			}
 			return
 		}
 
 		var eventRecord models.Event
-		eventFindError := applicationContext.Database.First(&eventRecord, "id = ?", rsvpRecord.EventID).Error
+		eventFindError := applicationContext.Database.WithContext(httpRequest.Context()).First(&eventRecord, "id = ?", rsvpRecord.EventID).Error
 		if eventFindError != nil {
-			applicationContext.Logger.Printf("ERROR: Could not find parent event %s for RSVP %s during QR code generation request to %s", rsvpRecord.EventID, rsvpID, httpRequest.URL.Path)
+			logging.FromContext(httpRequest.Context()).Error("Could not find the parent event of the RSVP for its QR code", "event_id", rsvpRecord.EventID, "rsvp_id", rsvpID)
 			if errors.Is(eventFindError, gorm.ErrRecordNotFound) {
-				baseHandler.HandleError(httpResponseWriter, eventFindError, utils.NotFoundError, "Could not find the parent event for this RSVP.")
+				baseHandler.HandleError(httpResponseWriter, httpRequest, eventFindError, utils.NotFoundError, "Could not find the parent event for this RSVP.")
 			} else {
-				baseHandler.HandleError(httpResponseWriter, eventFindError, utils.DatabaseError, "Error retrieving parent event details.")
+				baseHandler.HandleError(httpResponseWriter, httpRequest, eventFindError, utils.DatabaseError, "Error retrieving parent event details.")
 			}
 			return
 		}
 
-		if !baseHandler.VerifyResourceOwnership(httpResponseWriter, httpRequest, eventRecord.UserID, currentUser.ID) {
+		if baseHandler.AuthorizeEventAccess(httpResponseWriter, httpRequest, &eventRecord, currentUser.ID, models.PermissionViewQRCodes) == "" {
 			return
 		}
 
 		publicURLString, urlBuildError := utils.BuildPublicURL(
 			applicationContext.AppBaseURL,
 			config.WebResponse,
-			map[string]string{config.RSVPIDParam: rsvpRecord.ID},
+			handlers.InvitationLinkParams(applicationContext, &rsvpRecord, &eventRecord),
 		)
 		if urlBuildError != nil {
-			applicationContext.Logger.Printf("CRITICAL: Failed to build public URL: %v", urlBuildError)
-			baseHandler.HandleError(httpResponseWriter, urlBuildError, utils.ServerError, "Internal configuration error generating QR code URL.")
+			logging.FromContext(httpRequest.Context()).Error("Failed to build the public URL", "error", urlBuildError)
+			baseHandler.HandleError(httpResponseWriter, httpRequest, urlBuildError, utils.ServerError, "Internal configuration error generating QR code URL.")
 			return
 		}
 
 		qrCodePNG, qrError := qrcode.Encode(publicURLString, qrcode.Medium, 256)
 		if qrError != nil {
-			applicationContext.Logger.Printf("ERROR: Failed to generate QR code for URL '%s': %v", publicURLString, qrError)
-			baseHandler.HandleError(httpResponseWriter, qrError, utils.ServerError, "Failed to generate the QR code image.")
+			logging.FromContext(httpRequest.Context()).Error("Failed to generate the QR code", "url", publicURLString, "error", qrError)
+			baseHandler.HandleError(httpResponseWriter, httpRequest, qrError, utils.ServerError, "Failed to generate the QR code image.")
 			return
 		}
+		applicationContext.Metrics.CountQRCodeGenerated()
 		qrCodeBase64 := base64.StdEncoding.EncodeToString(qrCodePNG)
 
 		rsvpListURL := utils.BuildRelativeURL(config.WebRSVPs, map[string]string{config.EventIDParam: eventRecord.ID})
 
+		var linkExpiresAt *time.Time
+		if linkExpiry := applicationContext.Invitations.ExpiryFor(eventRecord.EndTime); !linkExpiry.IsZero() {
+			linkExpiresAt = &linkExpiry
+		}
+
 		viewData := ShowViewData{
 			RSVP:           rsvpRecord,
 			Event:          eventRecord,
 			QRCode:         qrCodeBase64,
 			PublicURL:      publicURLString,
+			LinkExpiresAt:  linkExpiresAt,
 			URLForRSVPList: rsvpListURL,
 			ParamEventID:   config.EventIDParam,
 			ParamRSVPID:    config.RSVPIDParam,
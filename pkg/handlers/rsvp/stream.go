@@ -0,0 +1,51 @@
+package rsvp
+
+import (
+	"errors"
+	"net/http"
+
+	"gorm.io/gorm"
+
+	"github.com/temirov/RSVP/models"
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/handlers"
+	"github.com/temirov/RSVP/pkg/middleware"
+	"github.com/temirov/RSVP/pkg/realtime"
+	"github.com/temirov/RSVP/pkg/utils"
+)
+
+// StreamHandler handles GET requests for the live RSVP update stream of a single event (/rsvps/stream/).
+// It applies the same membership check as ListHandler before subscribing the client.
+func StreamHandler(applicationContext *config.ApplicationContext) http.HandlerFunc {
+	baseHandler := handlers.NewBaseHttpHandler(applicationContext, config.ResourceNameRSVP, config.WebRSVPStream)
+
+	return func(httpResponseWriter http.ResponseWriter, httpRequest *http.Request) {
+		if !baseHandler.ValidateHttpMethod(httpResponseWriter, httpRequest, http.MethodGet) {
+			return
+		}
+
+		currentUser := httpRequest.Context().Value(middleware.ContextKeyUser).(*models.User)
+
+		params, paramsOk := baseHandler.RequireParams(httpResponseWriter, httpRequest, config.EventIDParam)
+		if !paramsOk {
+			return
+		}
+		eventID := params[config.EventIDParam]
+
+		var parentEvent models.Event
+		if eventFindError := parentEvent.FindByID(applicationContext.Database.WithContext(httpRequest.Context()), eventID); eventFindError != nil {
+			if errors.Is(eventFindError, gorm.ErrRecordNotFound) {
+				baseHandler.HandleError(httpResponseWriter, httpRequest, eventFindError, utils.NotFoundError, "The specified event was not found.")
+			} else {
+				baseHandler.HandleError(httpResponseWriter, httpRequest, eventFindError, utils.DatabaseError, "Error retrieving event details.")
+			}
+			return
+		}
+
+		if baseHandler.AuthorizeEventAccess(httpResponseWriter, httpRequest, &parentEvent, currentUser.ID, models.PermissionViewRSVPs) == "" {
+			return
+		}
+
+		baseHandler.StreamUpdates(httpResponseWriter, httpRequest, realtime.EventTopic(parentEvent.ID))
+	}
+}
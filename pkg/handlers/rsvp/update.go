@@ -4,11 +4,13 @@ import (
 	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/temirov/RSVP/models"
 	"github.com/temirov/RSVP/pkg/config"
 	"github.com/temirov/RSVP/pkg/handlers"
 	"github.com/temirov/RSVP/pkg/middleware"
+	"github.com/temirov/RSVP/pkg/realtime"
 	"github.com/temirov/RSVP/pkg/utils"
 	"gorm.io/gorm"
 )
@@ -30,51 +32,58 @@ func UpdateHandler(applicationContext *config.ApplicationContext) http.HandlerFu
 		targetRsvpID := params[config.RSVPIDParam]
 
 		var existingRSVP models.RSVP
-		if findError := applicationContext.Database.First(&existingRSVP, "id = ?", targetRsvpID).Error; findError != nil {
+		if findError := applicationContext.Database.WithContext(httpRequest.Context()).First(&existingRSVP, "id = ?", targetRsvpID).Error; findError != nil {
 			if errors.Is(findError, gorm.ErrRecordNotFound) {
-				baseHandler.HandleError(httpResponseWriter, findError, utils.NotFoundError, "RSVP not found.")
+				baseHandler.HandleError(httpResponseWriter, httpRequest, findError, utils.NotFoundError, "RSVP not found.")
 			} else {
-				baseHandler.HandleError(httpResponseWriter, findError, utils.DatabaseError, "Error retrieving RSVP details.")
+				baseHandler.HandleError(httpResponseWriter, httpRequest, findError, utils.DatabaseError, "Error retrieving RSVP details.")
 			}
 			return
 		}
 		parentEventID := existingRSVP.EventID
 
 		var parentEvent models.Event
-		eventFindError := applicationContext.Database.First(&parentEvent, "id = ?", parentEventID).Error
+		eventFindError := applicationContext.Database.WithContext(httpRequest.Context()).First(&parentEvent, "id = ?", parentEventID).Error
 		if eventFindError != nil {
 			if errors.Is(eventFindError, gorm.ErrRecordNotFound) {
-				baseHandler.HandleError(httpResponseWriter, eventFindError, utils.NotFoundError, "Parent event not found for RSVP.")
+				baseHandler.HandleError(httpResponseWriter, httpRequest, eventFindError, utils.NotFoundError, "Parent event not found for RSVP.")
 			} else {
-				baseHandler.HandleError(httpResponseWriter, eventFindError, utils.DatabaseError, "Error retrieving parent event.")
+				baseHandler.HandleError(httpResponseWriter, httpRequest, eventFindError, utils.DatabaseError, "Error retrieving parent event.")
 			}
 			return
 		}
 
-		if !baseHandler.VerifyResourceOwnership(httpResponseWriter, httpRequest, parentEvent.UserID, currentUser.ID) {
+		eventRole := baseHandler.AuthorizeEventAccess(httpResponseWriter, httpRequest, &parentEvent, currentUser.ID, models.PermissionRecordResponses)
+		if eventRole == "" {
 			return
 		}
 
 		if err := httpRequest.ParseForm(); err != nil {
-			baseHandler.HandleError(httpResponseWriter, err, utils.ValidationError, utils.ErrMsgInvalidFormData)
+			baseHandler.HandleError(httpResponseWriter, httpRequest, err, utils.ValidationError, utils.ErrMsgInvalidFormData)
 			return
 		}
 
 		newName := httpRequest.FormValue(config.NameParam)
-		if newName != "" {
+		if newName != "" && newName != existingRSVP.Name {
+			// Check-in staff may record responses but not rename guests.
+			if !models.RoleAllows(eventRole, models.PermissionManageRSVPs) {
+				baseHandler.HandleError(httpResponseWriter, httpRequest, nil, utils.ForbiddenError, "Forbidden: Your role on this event does not allow renaming RSVPs.")
+				return
+			}
 			if validationError := utils.ValidateRSVPName(newName); validationError != nil {
-				baseHandler.HandleError(httpResponseWriter, validationError, utils.ValidationError, validationError.Error())
+				baseHandler.HandleError(httpResponseWriter, httpRequest, validationError, utils.ValidationError, validationError.Error())
 				return
 			}
 			existingRSVP.Name = newName
 		}
 
+		previousResponse := existingRSVP.Response
 		newResponseStatus := httpRequest.FormValue(config.ResponseParam)
 		newExtraGuestsStr := httpRequest.FormValue(config.ExtraGuestsParam)
 		var newExtraGuests int = 0
 
 		if validationError := utils.ValidateRSVPResponseStatus(newResponseStatus); validationError != nil {
-			baseHandler.HandleError(httpResponseWriter, validationError, utils.ValidationError, validationError.Error())
+			baseHandler.HandleError(httpResponseWriter, httpRequest, validationError, utils.ValidationError, validationError.Error())
 			return
 		}
 
@@ -83,11 +92,11 @@ func UpdateHandler(applicationContext *config.ApplicationContext) http.HandlerFu
 			var parseErr error
 			newExtraGuests, parseErr = strconv.Atoi(newExtraGuestsStr)
 			if parseErr != nil {
-				baseHandler.HandleError(httpResponseWriter, parseErr, utils.ValidationError, utils.ErrGuestCountRequired.Error())
+				baseHandler.HandleError(httpResponseWriter, httpRequest, parseErr, utils.ValidationError, utils.ErrGuestCountRequired.Error())
 				return
 			}
 			if validationError := utils.ValidateExtraGuests(newExtraGuests); validationError != nil {
-				baseHandler.HandleError(httpResponseWriter, validationError, utils.ValidationError, validationError.Error())
+				baseHandler.HandleError(httpResponseWriter, httpRequest, validationError, utils.ValidationError, validationError.Error())
 				return
 			}
 			existingRSVP.Response = config.RSVPResponseYesPrefix // Store "Yes"
@@ -100,10 +109,19 @@ func UpdateHandler(applicationContext *config.ApplicationContext) http.HandlerFu
 			existingRSVP.ExtraGuests = 0
 		}
 
-		if saveError := existingRSVP.Save(applicationContext.Database); saveError != nil {
-			baseHandler.HandleError(httpResponseWriter, saveError, utils.DatabaseError, "Failed to update the RSVP.")
+		existingRSVP.TrackResponseTime(time.Now())
+		if saveError := existingRSVP.Save(applicationContext.Database.WithContext(httpRequest.Context())); saveError != nil {
+			baseHandler.HandleError(httpResponseWriter, httpRequest, saveError, utils.DatabaseError, "Failed to update the RSVP.")
 			return
 		}
+		if existingRSVP.Response != previousResponse {
+			applicationContext.Metrics.CountRSVPResponse(existingRSVP.Response, config.MetricsSourceOrganizer)
+		}
+		updateKind := realtime.KindRSVPUpdated
+		if eventRole == config.EventRoleCheckIn {
+			updateKind = realtime.KindRSVPCheckedIn
+		}
+		handlers.PublishRSVPChange(httpRequest.Context(), applicationContext, updateKind, &existingRSVP, &parentEvent)
 
 		redirectParams := map[string]string{
 			config.EventIDParam: parentEventID,
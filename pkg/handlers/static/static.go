@@ -0,0 +1,31 @@
+// Package static serves the stylesheets, images and other files under /static/, from the assets compiled into the
+// binary or their replacements in the override directory.
+package static
+
+import (
+	"io/fs"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/handlers"
+	"github.com/temirov/RSVP/pkg/utils"
+)
+
+// Handler serves the files of staticFiles under config.WebStatic. Directories are not listed.
+func Handler(applicationContext *config.ApplicationContext, staticFiles fs.FS) http.HandlerFunc {
+	baseHttpHandler := handlers.NewBaseHttpHandler(applicationContext, config.ResourceNameStatic, config.WebStatic)
+	fileServer := http.StripPrefix(strings.TrimSuffix(config.WebStatic, "/"), http.FileServerFS(staticFiles))
+	return func(httpResponseWriter http.ResponseWriter, httpRequest *http.Request) {
+		if !baseHttpHandler.ValidateHttpMethod(httpResponseWriter, httpRequest, http.MethodGet, http.MethodHead) {
+			return
+		}
+		if strings.HasSuffix(httpRequest.URL.Path, "/") {
+			baseHttpHandler.HandleError(httpResponseWriter, httpRequest, nil, utils.NotFoundError, "File not found.")
+			return
+		}
+		httpResponseWriter.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(config.StaticCacheMaxAge))
+		fileServer.ServeHTTP(httpResponseWriter, httpRequest)
+	}
+}
@@ -0,0 +1,69 @@
+// Package token provides HTTP handler logic for managing personal API tokens.
+package token
+
+import (
+	"net/http"
+
+	"github.com/temirov/RSVP/models"
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/handlers"
+	"github.com/temirov/RSVP/pkg/logging"
+	"github.com/temirov/RSVP/pkg/utils"
+)
+
+// ListViewData is passed to the "tokens" view template.
+type ListViewData struct {
+	TokenList               []models.APIToken
+	UserEvents              []models.Event
+	NewPlaintextToken       string
+	TokenManagerLabel       string
+	URLForTokenActions      string
+	ParamNameMethodOverride string
+	ParamNameTokenID        string
+	ParamNameTokenName      string
+	ParamNameTokenScope     string
+	ParamNameTokenEventID   string
+	ScopeRead               string
+	ScopeReadWrite          string
+	AuthorizationExample    string
+}
+
+// renderTokenList loads the user's tokens and events and renders the token management page.
+// newPlaintextToken is non-empty only right after creation, the single time the secret is displayed.
+func renderTokenList(baseHttpHandler *handlers.BaseHttpHandler, responseWriter http.ResponseWriter, request *http.Request, currentUser *models.User, newPlaintextToken string) {
+	databaseConnection := baseHttpHandler.ApplicationContext.Database.WithContext(request.Context())
+
+	tokenList, findTokensError := models.FindAPITokensByOwner(databaseConnection, currentUser.ID)
+	if findTokensError != nil {
+		baseHttpHandler.HandleError(responseWriter, request, findTokensError, utils.DatabaseError, "Failed to retrieve API tokens.")
+		return
+	}
+
+	userEvents, findEventsError := models.FindEventsForMember(databaseConnection, currentUser.ID, false, false)
+	if findEventsError != nil {
+		logging.FromContext(request.Context()).Error("Failed to retrieve the events tokens can be scoped to", "error", findEventsError)
+		userEvents = []models.Event{}
+	}
+	organizationEvents, findOrganizationEventsError := models.FindEventsInUserOrganizations(databaseConnection, currentUser.ID)
+	if findOrganizationEventsError != nil {
+		logging.FromContext(request.Context()).Error("Failed to retrieve the organization events tokens can be scoped to", "error", findOrganizationEventsError)
+	}
+	userEvents = append(userEvents, organizationEvents...)
+
+	viewData := ListViewData{
+		TokenList:               tokenList,
+		UserEvents:              userEvents,
+		NewPlaintextToken:       newPlaintextToken,
+		TokenManagerLabel:       config.ResourceLabelTokenManager,
+		URLForTokenActions:      config.WebTokens,
+		ParamNameMethodOverride: config.MethodOverrideParam,
+		ParamNameTokenID:        config.TokenIDParam,
+		ParamNameTokenName:      config.TokenNameParam,
+		ParamNameTokenScope:     config.TokenScopeParam,
+		ParamNameTokenEventID:   config.TokenEventIDParam,
+		ScopeRead:               config.TokenScopeRead,
+		ScopeReadWrite:          config.TokenScopeReadWrite,
+		AuthorizationExample:    config.AuthorizationHeader + ": " + config.BearerSchemePrefix,
+	}
+	baseHttpHandler.RenderView(responseWriter, request, config.TemplateTokens, viewData)
+}
@@ -0,0 +1,68 @@
+package token
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/temirov/RSVP/models"
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/handlers"
+	"github.com/temirov/RSVP/pkg/logging"
+	"github.com/temirov/RSVP/pkg/middleware"
+	"github.com/temirov/RSVP/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// CreateHandler handles POST requests to issue a new API token.
+// Instead of redirecting, it renders the list page directly so the plaintext token can be shown exactly once.
+func CreateHandler(applicationContext *config.ApplicationContext) http.HandlerFunc {
+	baseHttpHandler := handlers.NewBaseHttpHandler(applicationContext, config.ResourceNameToken, config.WebTokens)
+	return func(responseWriter http.ResponseWriter, request *http.Request) {
+		if !baseHttpHandler.ValidateHttpMethod(responseWriter, request, http.MethodPost) {
+			return
+		}
+		if err := request.ParseForm(); err != nil {
+			baseHttpHandler.HandleError(responseWriter, request, err, utils.ValidationError, utils.ErrMsgInvalidFormData)
+			return
+		}
+		currentUser := request.Context().Value(middleware.ContextKeyUser).(*models.User)
+
+		tokenName := request.FormValue(config.TokenNameParam)
+		if validationError := utils.ValidateTokenName(tokenName); validationError != nil {
+			baseHttpHandler.HandleError(responseWriter, request, validationError, utils.ValidationError, validationError.Error())
+			return
+		}
+		tokenScope := request.FormValue(config.TokenScopeParam)
+		if validationError := utils.ValidateTokenScope(tokenScope); validationError != nil {
+			baseHttpHandler.HandleError(responseWriter, request, validationError, utils.ValidationError, validationError.Error())
+			return
+		}
+
+		var restrictedEventID *string
+		if requestedEventID := request.FormValue(config.TokenEventIDParam); requestedEventID != "" {
+			var restrictedEvent models.Event
+			if findError := restrictedEvent.FindByID(applicationContext.Database.WithContext(request.Context()), requestedEventID); findError != nil {
+				if errors.Is(findError, gorm.ErrRecordNotFound) {
+					baseHttpHandler.HandleError(responseWriter, request, findError, utils.ForbiddenError, "You do not have permission to scope a token to the selected event.")
+				} else {
+					baseHttpHandler.HandleError(responseWriter, request, findError, utils.DatabaseError, "Could not verify event permissions.")
+				}
+				return
+			}
+			// A token acts with its owner's role, so co-hosts may scope tokens to the events shared with them.
+			if baseHttpHandler.AuthorizeEventAccess(responseWriter, request, &restrictedEvent, currentUser.ID, models.PermissionViewEvent) == "" {
+				return
+			}
+			restrictedEventID = &restrictedEvent.ID
+		}
+
+		newToken, plaintextToken, issueError := models.IssueAPIToken(applicationContext.Database.WithContext(request.Context()), currentUser.ID, tokenName, tokenScope, restrictedEventID)
+		if issueError != nil {
+			baseHttpHandler.HandleError(responseWriter, request, issueError, utils.DatabaseError, "Failed to create the API token.")
+			return
+		}
+		logging.FromContext(request.Context()).Info("API token issued", "token_id", newToken.ID, "scope", newToken.Scope)
+
+		renderTokenList(&baseHttpHandler, responseWriter, request, currentUser, plaintextToken)
+	}
+}
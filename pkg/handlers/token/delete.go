@@ -0,0 +1,51 @@
+package token
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/temirov/RSVP/models"
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/handlers"
+	"github.com/temirov/RSVP/pkg/logging"
+	"github.com/temirov/RSVP/pkg/middleware"
+	"github.com/temirov/RSVP/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// RevokeHandler handles DELETE requests (or POST with _method=DELETE override) to revoke an API token.
+// Revoked tokens are kept so their last usage remains visible on the management page.
+func RevokeHandler(applicationContext *config.ApplicationContext) http.HandlerFunc {
+	baseHttpHandler := handlers.NewBaseHttpHandler(applicationContext, config.ResourceNameToken, config.WebTokens)
+	return func(responseWriter http.ResponseWriter, request *http.Request) {
+		if !baseHttpHandler.ValidateHttpMethod(responseWriter, request, http.MethodDelete) {
+			return
+		}
+		currentUser := request.Context().Value(middleware.ContextKeyUser).(*models.User)
+		params, paramsOk := baseHttpHandler.RequireParams(responseWriter, request, config.TokenIDParam)
+		if !paramsOk {
+			return
+		}
+		targetTokenID := params[config.TokenIDParam]
+
+		var apiToken models.APIToken
+		if findError := apiToken.FindByIDAndOwner(applicationContext.Database.WithContext(request.Context()), targetTokenID, currentUser.ID); findError != nil {
+			if errors.Is(findError, gorm.ErrRecordNotFound) {
+				baseHttpHandler.HandleError(responseWriter, request, findError, utils.NotFoundError, "API token not found.")
+			} else {
+				baseHttpHandler.HandleError(responseWriter, request, findError, utils.DatabaseError, "Error retrieving API token.")
+			}
+			return
+		}
+
+		if !apiToken.IsRevoked() {
+			if revokeError := apiToken.Revoke(applicationContext.Database.WithContext(request.Context())); revokeError != nil {
+				baseHttpHandler.HandleError(responseWriter, request, revokeError, utils.DatabaseError, "Failed to revoke the API token.")
+				return
+			}
+			logging.FromContext(request.Context()).Info("API token revoked", "token_id", apiToken.ID)
+		}
+
+		baseHttpHandler.RedirectToList(responseWriter, request)
+	}
+}
@@ -0,0 +1,64 @@
+package transfer
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/temirov/RSVP/models"
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/handlers"
+	"github.com/temirov/RSVP/pkg/logging"
+	"github.com/temirov/RSVP/pkg/middleware"
+	"github.com/temirov/RSVP/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// AcceptHandler handles PUT requests in which the recipient accepts a pending transfer. The event or venue
+// moves to them atomically, and they are sent to the page listing it.
+func AcceptHandler(applicationContext *config.ApplicationContext) http.HandlerFunc {
+	baseHttpHandler := handlers.NewBaseHttpHandler(applicationContext, config.ResourceNameTransfer, config.WebTransfers)
+	return func(responseWriter http.ResponseWriter, request *http.Request) {
+		if !baseHttpHandler.ValidateHttpMethod(responseWriter, request, http.MethodPut, http.MethodPatch) {
+			return
+		}
+		currentUser := request.Context().Value(middleware.ContextKeyUser).(*models.User)
+		params, paramsOk := baseHttpHandler.RequireParams(responseWriter, request, config.TransferIDParam)
+		if !paramsOk {
+			return
+		}
+
+		var pendingTransfer models.OwnershipTransfer
+		findError := pendingTransfer.FindPendingByID(applicationContext.Database.WithContext(request.Context()), params[config.TransferIDParam])
+		if findError == nil && !pendingTransfer.IsAddressedTo(currentUser) {
+			findError = gorm.ErrRecordNotFound
+		}
+		if findError != nil {
+			if errors.Is(findError, gorm.ErrRecordNotFound) {
+				baseHttpHandler.HandleError(responseWriter, request, findError, utils.NotFoundError, "Transfer not found.")
+			} else {
+				baseHttpHandler.HandleError(responseWriter, request, findError, utils.DatabaseError, "Error retrieving transfer.")
+			}
+			return
+		}
+
+		transferOutcome, acceptError := pendingTransfer.Accept(applicationContext.Database.WithContext(request.Context()), currentUser)
+		if acceptError != nil {
+			if errors.Is(acceptError, models.ErrTransferStale) {
+				baseHttpHandler.HandleError(responseWriter, request, acceptError, utils.ValidationError, acceptError.Error())
+			} else {
+				baseHttpHandler.HandleError(responseWriter, request, acceptError, utils.DatabaseError, "Failed to complete the transfer.")
+			}
+			return
+		}
+		logging.FromContext(request.Context()).Info("Transfer accepted", "resource_type", pendingTransfer.ResourceType,
+			"resource_id", pendingTransfer.ResourceID, "from_user_id", pendingTransfer.FromUserID, "transfer_id", pendingTransfer.ID,
+			"events_moved", transferOutcome.MovedEventCount, "tokens_revoked", transferOutcome.RevokedTokenCount,
+			"venue_copied", transferOutcome.VenueCopied)
+
+		destinationURL := config.WebEvents
+		if pendingTransfer.ResourceType == config.TransferResourceVenue {
+			destinationURL = config.WebVenues
+		}
+		http.Redirect(responseWriter, request, destinationURL, http.StatusSeeOther)
+	}
+}
@@ -0,0 +1,55 @@
+package transfer
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/temirov/RSVP/models"
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/handlers"
+	"github.com/temirov/RSVP/pkg/logging"
+	"github.com/temirov/RSVP/pkg/middleware"
+	"github.com/temirov/RSVP/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// CancelHandler handles DELETE requests that close a pending transfer without moving anything.
+// The owner who offered it cancels it; the recipient declines it.
+func CancelHandler(applicationContext *config.ApplicationContext) http.HandlerFunc {
+	baseHttpHandler := handlers.NewBaseHttpHandler(applicationContext, config.ResourceNameTransfer, config.WebTransfers)
+	return func(responseWriter http.ResponseWriter, request *http.Request) {
+		if !baseHttpHandler.ValidateHttpMethod(responseWriter, request, http.MethodDelete) {
+			return
+		}
+		currentUser := request.Context().Value(middleware.ContextKeyUser).(*models.User)
+		params, paramsOk := baseHttpHandler.RequireParams(responseWriter, request, config.TransferIDParam)
+		if !paramsOk {
+			return
+		}
+
+		var pendingTransfer models.OwnershipTransfer
+		findError := pendingTransfer.FindPendingByID(applicationContext.Database.WithContext(request.Context()), params[config.TransferIDParam])
+		finalStatus := config.TransferStatusCancelled
+		if findError == nil && pendingTransfer.FromUserID != currentUser.ID {
+			if pendingTransfer.IsAddressedTo(currentUser) {
+				finalStatus = config.TransferStatusDeclined
+			} else {
+				findError = gorm.ErrRecordNotFound
+			}
+		}
+		if findError == nil {
+			findError = pendingTransfer.Resolve(applicationContext.Database.WithContext(request.Context()), finalStatus)
+		}
+		if findError != nil {
+			if errors.Is(findError, gorm.ErrRecordNotFound) {
+				baseHttpHandler.HandleError(responseWriter, request, findError, utils.NotFoundError, "Transfer not found.")
+			} else {
+				baseHttpHandler.HandleError(responseWriter, request, findError, utils.DatabaseError, "Failed to cancel the transfer.")
+			}
+			return
+		}
+		logging.FromContext(request.Context()).Info("Transfer closed", "status", finalStatus, "transfer_id", pendingTransfer.ID, "resource_type", pendingTransfer.ResourceType, "resource_id", pendingTransfer.ResourceID)
+
+		baseHttpHandler.RedirectToList(responseWriter, request)
+	}
+}
@@ -0,0 +1,110 @@
+// Package transfer provides HTTP handlers for handing personal events and venues over to another user:
+// offering a transfer, accepting or declining it, and cancelling it.
+package transfer
+
+import (
+	"errors"
+	"time"
+
+	"github.com/temirov/RSVP/models"
+	"github.com/temirov/RSVP/pkg/config"
+	"gorm.io/gorm"
+)
+
+// TransferItem is a pending transfer together with the name of the event or venue it concerns and the time it lapses.
+type TransferItem struct {
+	Transfer     models.OwnershipTransfer
+	ResourceName string
+	ExpiresAt    time.Time
+}
+
+// OfferForm describes the item the transfer form is prefilled with.
+type OfferForm struct {
+	ResourceType     string
+	ResourceID       string
+	ResourceName     string
+	LinkedEventCount int64
+}
+
+// ListViewData is passed to the "transfers" view template.
+type ListViewData struct {
+	Incoming                []TransferItem
+	Outgoing                []TransferItem
+	Offer                   *OfferForm
+	URLForTransferActions   string
+	URLForEvents            string
+	URLForVenues            string
+	ParamNameTransferID     string
+	ParamNameResourceType   string
+	ParamNameResourceID     string
+	ParamNameRecipientEmail string
+	ParamNameIncludeLinked  string
+	ParamNameMethodOverride string
+	ResourceTypeEvent       string
+	ResourceTypeVenue       string
+	TransferManagerLabel    string
+	MaxRecipientEmailLength int
+}
+
+// findOwnedResource loads the name of an event or venue that the user may offer for transfer: one they own
+// in their personal workspace. Items of an organization belong to it and cannot be transferred by a member.
+// It returns gorm.ErrRecordNotFound when the item does not exist or belongs to someone else.
+func findOwnedResource(databaseConnection *gorm.DB, resourceType string, resourceIdentifier string, ownerUserID string) (*OfferForm, error) {
+	offerForm := OfferForm{ResourceType: resourceType, ResourceID: resourceIdentifier}
+	var organizationID *string
+	switch resourceType {
+	case config.TransferResourceEvent:
+		var ownedEvent models.Event
+		if err := ownedEvent.FindByIDAndOwner(databaseConnection, resourceIdentifier, ownerUserID); err != nil {
+			return nil, err
+		}
+		offerForm.ResourceName = ownedEvent.Title
+		organizationID = ownedEvent.OrganizationID
+	case config.TransferResourceVenue:
+		var ownedVenue models.Venue
+		if err := ownedVenue.FindByIDAndOwner(databaseConnection, resourceIdentifier, ownerUserID); err != nil {
+			return nil, err
+		}
+		offerForm.ResourceName = ownedVenue.Name
+		organizationID = ownedVenue.OrganizationID
+		if err := databaseConnection.Model(&models.Event{}).
+			Where("venue_id = ? AND user_id = ? AND organization_id IS NULL", resourceIdentifier, ownerUserID).
+			Count(&offerForm.LinkedEventCount).Error; err != nil {
+			return nil, err
+		}
+	default:
+		return nil, gorm.ErrRecordNotFound
+	}
+	if organizationID != nil {
+		return nil, models.ErrTransferNotTransferable
+	}
+	return &offerForm, nil
+}
+
+// describeTransfers pairs each transfer with the name of its event or venue. Items deleted since the transfer
+// was offered are still named, from the trash, so the transfer can be recognized and cancelled.
+func describeTransfers(databaseConnection *gorm.DB, pendingTransfers []models.OwnershipTransfer) ([]TransferItem, error) {
+	transferItems := make([]TransferItem, 0, len(pendingTransfers))
+	for _, pendingTransfer := range pendingTransfers {
+		var resourceName string
+		var findError error
+		switch pendingTransfer.ResourceType {
+		case config.TransferResourceEvent:
+			var transferredEvent models.Event
+			findError = databaseConnection.Unscoped().Where("id = ?", pendingTransfer.ResourceID).First(&transferredEvent).Error
+			resourceName = transferredEvent.Title
+		case config.TransferResourceVenue:
+			var transferredVenue models.Venue
+			findError = databaseConnection.Unscoped().Where("id = ?", pendingTransfer.ResourceID).First(&transferredVenue).Error
+			resourceName = transferredVenue.Name
+		}
+		if findError != nil && !errors.Is(findError, gorm.ErrRecordNotFound) {
+			return nil, findError
+		}
+		if resourceName == "" {
+			resourceName = pendingTransfer.ResourceID
+		}
+		transferItems = append(transferItems, TransferItem{Transfer: pendingTransfer, ResourceName: resourceName, ExpiresAt: pendingTransfer.ExpiresAt()})
+	}
+	return transferItems, nil
+}
@@ -0,0 +1,67 @@
+package transfer
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/temirov/RSVP/models"
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/handlers"
+	"github.com/temirov/RSVP/pkg/logging"
+	"github.com/temirov/RSVP/pkg/middleware"
+	"github.com/temirov/RSVP/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// CreateHandler handles POST requests that offer one of the user's personal events or venues to another user.
+// Nothing moves until the recipient accepts.
+func CreateHandler(applicationContext *config.ApplicationContext) http.HandlerFunc {
+	baseHttpHandler := handlers.NewBaseHttpHandler(applicationContext, config.ResourceNameTransfer, config.WebTransfers)
+	return func(responseWriter http.ResponseWriter, request *http.Request) {
+		if !baseHttpHandler.ValidateHttpMethod(responseWriter, request, http.MethodPost) {
+			return
+		}
+		currentUser := request.Context().Value(middleware.ContextKeyUser).(*models.User)
+		params, paramsOk := baseHttpHandler.RequireParams(responseWriter, request, config.TransferTypeParam, config.TransferResourceIDParam, config.TransferEmailParam)
+		if !paramsOk {
+			return
+		}
+		resourceType := params[config.TransferTypeParam]
+		if validationError := utils.ValidateTransferType(resourceType); validationError != nil {
+			baseHttpHandler.HandleError(responseWriter, request, validationError, utils.ValidationError, validationError.Error())
+			return
+		}
+		recipientEmail := models.NormalizeMemberEmail(params[config.TransferEmailParam])
+		if validationError := utils.ValidateTransferEmail(recipientEmail); validationError != nil {
+			baseHttpHandler.HandleError(responseWriter, request, validationError, utils.ValidationError, validationError.Error())
+			return
+		}
+		includeLinkedEvents := baseHttpHandler.GetParam(request, config.TransferLinkedEventsParam) != ""
+
+		offerForm, findError := findOwnedResource(applicationContext.Database.WithContext(request.Context()), resourceType, params[config.TransferResourceIDParam], currentUser.ID)
+		if findError != nil {
+			switch {
+			case errors.Is(findError, gorm.ErrRecordNotFound):
+				baseHttpHandler.HandleError(responseWriter, request, findError, utils.NotFoundError, "Item not found or you do not own it.")
+			case errors.Is(findError, models.ErrTransferNotTransferable):
+				baseHttpHandler.HandleError(responseWriter, request, findError, utils.ValidationError, findError.Error())
+			default:
+				baseHttpHandler.HandleError(responseWriter, request, findError, utils.DatabaseError, "Failed to verify ownership.")
+			}
+			return
+		}
+
+		newTransfer, offerError := models.OfferOwnershipTransfer(applicationContext.Database.WithContext(request.Context()), resourceType, offerForm.ResourceID, currentUser, recipientEmail, includeLinkedEvents)
+		if offerError != nil {
+			if errors.Is(offerError, models.ErrTransferToSelf) || errors.Is(offerError, models.ErrTransferAlreadyPending) {
+				baseHttpHandler.HandleError(responseWriter, request, offerError, utils.ValidationError, offerError.Error())
+			} else {
+				baseHttpHandler.HandleError(responseWriter, request, offerError, utils.DatabaseError, "Failed to offer the transfer.")
+			}
+			return
+		}
+		logging.FromContext(request.Context()).Info("Transfer offered", "resource_type", resourceType, "resource_id", offerForm.ResourceID, "email", recipientEmail, "transfer_id", newTransfer.ID)
+
+		baseHttpHandler.RedirectToList(responseWriter, request)
+	}
+}
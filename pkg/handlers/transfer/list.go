@@ -0,0 +1,73 @@
+package transfer
+
+import (
+	"net/http"
+
+	"github.com/temirov/RSVP/models"
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/handlers"
+	"github.com/temirov/RSVP/pkg/logging"
+	"github.com/temirov/RSVP/pkg/middleware"
+	"github.com/temirov/RSVP/pkg/utils"
+)
+
+// ListHandler handles GET requests for the transfers page (/transfers/). It lists the transfers waiting for the
+// user's answer and the ones they offered. With resource_type and resource_id it also shows the form to offer
+// that event or venue to someone else.
+func ListHandler(applicationContext *config.ApplicationContext) http.HandlerFunc {
+	baseHttpHandler := handlers.NewBaseHttpHandler(applicationContext, config.ResourceNameTransfer, config.WebTransfers)
+	return func(responseWriter http.ResponseWriter, request *http.Request) {
+		if !baseHttpHandler.ValidateHttpMethod(responseWriter, request, http.MethodGet) {
+			return
+		}
+		currentUser := request.Context().Value(middleware.ContextKeyUser).(*models.User)
+
+		incomingTransfers, err := models.FindPendingTransfersForUser(applicationContext.Database.WithContext(request.Context()), currentUser)
+		if err != nil {
+			baseHttpHandler.HandleError(responseWriter, request, err, utils.DatabaseError, "Failed to retrieve incoming transfers.")
+			return
+		}
+		outgoingTransfers, err := models.FindPendingTransfersFromUser(applicationContext.Database.WithContext(request.Context()), currentUser.ID)
+		if err != nil {
+			baseHttpHandler.HandleError(responseWriter, request, err, utils.DatabaseError, "Failed to retrieve outgoing transfers.")
+			return
+		}
+
+		viewData := ListViewData{
+			URLForTransferActions:   config.WebTransfers,
+			URLForEvents:            config.WebEvents,
+			URLForVenues:            config.WebVenues,
+			ParamNameTransferID:     config.TransferIDParam,
+			ParamNameResourceType:   config.TransferTypeParam,
+			ParamNameResourceID:     config.TransferResourceIDParam,
+			ParamNameRecipientEmail: config.TransferEmailParam,
+			ParamNameIncludeLinked:  config.TransferLinkedEventsParam,
+			ParamNameMethodOverride: config.MethodOverrideParam,
+			ResourceTypeEvent:       config.TransferResourceEvent,
+			ResourceTypeVenue:       config.TransferResourceVenue,
+			TransferManagerLabel:    config.ResourceLabelTransfers,
+			MaxRecipientEmailLength: config.MaxEmailLength,
+		}
+		if viewData.Incoming, err = describeTransfers(applicationContext.Database.WithContext(request.Context()), incomingTransfers); err != nil {
+			baseHttpHandler.HandleError(responseWriter, request, err, utils.DatabaseError, "Failed to retrieve incoming transfers.")
+			return
+		}
+		if viewData.Outgoing, err = describeTransfers(applicationContext.Database.WithContext(request.Context()), outgoingTransfers); err != nil {
+			baseHttpHandler.HandleError(responseWriter, request, err, utils.DatabaseError, "Failed to retrieve outgoing transfers.")
+			return
+		}
+
+		requestedType := request.URL.Query().Get(config.TransferTypeParam)
+		requestedID := request.URL.Query().Get(config.TransferResourceIDParam)
+		if requestedType != "" && requestedID != "" {
+			offerForm, offerError := findOwnedResource(applicationContext.Database.WithContext(request.Context()), requestedType, requestedID, currentUser.ID)
+			if offerError != nil {
+				logging.FromContext(request.Context()).Warn("User cannot offer the item for transfer", "resource_type", requestedType, "resource_id", requestedID, "error", offerError)
+			} else {
+				viewData.Offer = offerForm
+			}
+		}
+
+		baseHttpHandler.RenderView(responseWriter, request, config.TemplateTransfers, viewData)
+	}
+}
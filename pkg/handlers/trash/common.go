@@ -0,0 +1,48 @@
+// Package trash provides HTTP handlers for listing, restoring and permanently deleting soft-deleted records.
+package trash
+
+import (
+	"net/http"
+
+	"github.com/temirov/RSVP/models"
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/handlers"
+	"github.com/temirov/RSVP/pkg/middleware"
+	"github.com/temirov/RSVP/pkg/utils"
+)
+
+// ListViewData is passed to the "trash" view template.
+type ListViewData struct {
+	Trash                   *models.TrashContents
+	TrashLabel              string
+	URLForTrashActions      string
+	ParamNameMethodOverride string
+	ParamNameItemType       string
+	ParamNameItemID         string
+	ItemTypeEvent           string
+	ItemTypeVenue           string
+	ItemTypeRSVP            string
+	// PurgeAfterDays is the retention period, rounded up to whole days, after which deleted items are removed; zero means never.
+	PurgeAfterDays int
+}
+
+// trashItemParams reads and validates the item type and ID of a restore or purge request.
+// It returns false if a response has already been sent.
+func trashItemParams(baseHttpHandler *handlers.BaseHttpHandler, responseWriter http.ResponseWriter, request *http.Request) (string, string, bool) {
+	if apiToken := middleware.APITokenFromContext(request.Context()); apiToken != nil && apiToken.EventID != nil {
+		baseHttpHandler.HandleError(responseWriter, request, nil, utils.ForbiddenError, "Forbidden: This API token is restricted to a single event.")
+		return "", "", false
+	}
+	params, paramsOk := baseHttpHandler.RequireParams(responseWriter, request, config.TrashItemTypeParam, config.TrashItemIDParam)
+	if !paramsOk {
+		return "", "", false
+	}
+	itemType := params[config.TrashItemTypeParam]
+	switch itemType {
+	case config.TrashItemTypeEvent, config.TrashItemTypeVenue, config.TrashItemTypeRSVP:
+		return itemType, params[config.TrashItemIDParam], true
+	default:
+		baseHttpHandler.HandleError(responseWriter, request, nil, utils.ValidationError, "Unknown item type: "+itemType)
+		return "", "", false
+	}
+}
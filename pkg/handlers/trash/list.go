@@ -0,0 +1,42 @@
+package trash
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/temirov/RSVP/models"
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/handlers"
+	"github.com/temirov/RSVP/pkg/middleware"
+	"github.com/temirov/RSVP/pkg/utils"
+)
+
+// ListHandler handles GET requests for the current user's trash.
+func ListHandler(applicationContext *config.ApplicationContext, trashConfig config.TrashConfig) http.HandlerFunc {
+	baseHttpHandler := handlers.NewBaseHttpHandler(applicationContext, config.ResourceNameTrash, config.WebTrash)
+	return func(responseWriter http.ResponseWriter, request *http.Request) {
+		if !baseHttpHandler.ValidateHttpMethod(responseWriter, request, http.MethodGet) {
+			return
+		}
+		currentUser := request.Context().Value(middleware.ContextKeyUser).(*models.User)
+
+		trashContents, findError := models.FindTrash(applicationContext.Database.WithContext(request.Context()), currentUser.ID, middleware.WorkspaceIDFromContext(request.Context()))
+		if findError != nil {
+			baseHttpHandler.HandleError(responseWriter, request, findError, utils.DatabaseError, "Failed to retrieve deleted items.")
+			return
+		}
+		viewData := ListViewData{
+			Trash:                   trashContents,
+			TrashLabel:              config.ResourceLabelTrash,
+			URLForTrashActions:      config.WebTrash,
+			ParamNameMethodOverride: config.MethodOverrideParam,
+			ParamNameItemType:       config.TrashItemTypeParam,
+			ParamNameItemID:         config.TrashItemIDParam,
+			ItemTypeEvent:           config.TrashItemTypeEvent,
+			ItemTypeVenue:           config.TrashItemTypeVenue,
+			ItemTypeRSVP:            config.TrashItemTypeRSVP,
+			PurgeAfterDays:          int((trashConfig.Retention + 24*time.Hour - 1) / (24 * time.Hour)),
+		}
+		baseHttpHandler.RenderView(responseWriter, request, config.TemplateTrash, viewData)
+	}
+}
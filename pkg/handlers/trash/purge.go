@@ -0,0 +1,56 @@
+package trash
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/temirov/RSVP/models"
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/handlers"
+	"github.com/temirov/RSVP/pkg/logging"
+	"github.com/temirov/RSVP/pkg/middleware"
+	"github.com/temirov/RSVP/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// PurgeHandler handles DELETE requests (or POST with _method=DELETE override) that permanently delete an item from the trash.
+func PurgeHandler(applicationContext *config.ApplicationContext) http.HandlerFunc {
+	baseHttpHandler := handlers.NewBaseHttpHandler(applicationContext, config.ResourceNameTrash, config.WebTrash)
+	return func(responseWriter http.ResponseWriter, request *http.Request) {
+		if !baseHttpHandler.ValidateHttpMethod(responseWriter, request, http.MethodDelete) {
+			return
+		}
+		itemType, itemID, paramsOk := trashItemParams(&baseHttpHandler, responseWriter, request)
+		if !paramsOk {
+			return
+		}
+		currentUser := request.Context().Value(middleware.ContextKeyUser).(*models.User)
+		databaseConnection := applicationContext.Database.WithContext(request.Context())
+
+		var purgeError error
+		switch itemType {
+		case config.TrashItemTypeEvent:
+			if _, purgeError = models.FindDeletedEventForUser(databaseConnection, itemID, currentUser.ID); purgeError == nil {
+				purgeError = models.PurgeEvent(databaseConnection, itemID)
+			}
+		case config.TrashItemTypeVenue:
+			if _, purgeError = models.FindDeletedVenueForUser(databaseConnection, itemID, currentUser.ID); purgeError == nil {
+				purgeError = models.PurgeVenue(databaseConnection, itemID)
+			}
+		case config.TrashItemTypeRSVP:
+			if _, purgeError = models.FindDeletedRSVPForUser(databaseConnection, itemID, currentUser.ID); purgeError == nil {
+				purgeError = models.PurgeRSVP(databaseConnection, itemID)
+			}
+		}
+
+		switch {
+		case errors.Is(purgeError, gorm.ErrRecordNotFound):
+			baseHttpHandler.HandleError(responseWriter, request, purgeError, utils.NotFoundError, "Deleted item not found.")
+		case purgeError != nil:
+			baseHttpHandler.HandleError(responseWriter, request, purgeError, utils.DatabaseError, "Failed to permanently delete the item.")
+		default:
+			logging.FromContext(request.Context()).Info("Item permanently deleted", "item_type", itemType, "item_id", itemID)
+			baseHttpHandler.RedirectToList(responseWriter, request)
+		}
+	}
+}
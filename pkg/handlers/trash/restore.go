@@ -0,0 +1,69 @@
+package trash
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/temirov/RSVP/models"
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/handlers"
+	"github.com/temirov/RSVP/pkg/logging"
+	"github.com/temirov/RSVP/pkg/middleware"
+	"github.com/temirov/RSVP/pkg/realtime"
+	"github.com/temirov/RSVP/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// RestoreHandler handles POST requests that take an event, venue or RSVP out of the trash.
+// Restoring an event also restores the RSVPs deleted with it and, if needed, its venue.
+func RestoreHandler(applicationContext *config.ApplicationContext) http.HandlerFunc {
+	baseHttpHandler := handlers.NewBaseHttpHandler(applicationContext, config.ResourceNameTrash, config.WebTrash)
+	return func(responseWriter http.ResponseWriter, request *http.Request) {
+		if !baseHttpHandler.ValidateHttpMethod(responseWriter, request, http.MethodPost) {
+			return
+		}
+		itemType, itemID, paramsOk := trashItemParams(&baseHttpHandler, responseWriter, request)
+		if !paramsOk {
+			return
+		}
+		currentUser := request.Context().Value(middleware.ContextKeyUser).(*models.User)
+		databaseConnection := applicationContext.Database.WithContext(request.Context())
+
+		var restoreError error
+		switch itemType {
+		case config.TrashItemTypeEvent:
+			if _, restoreError = models.FindDeletedEventForUser(databaseConnection, itemID, currentUser.ID); restoreError == nil {
+				var restoredRSVPCount int64
+				restoredRSVPCount, restoreError = models.RestoreEventWithRSVPs(databaseConnection, itemID)
+				if restoreError == nil {
+					logging.FromContext(request.Context()).Info("Event restored from the trash", "event_id", itemID, "rsvps", restoredRSVPCount)
+				}
+			}
+		case config.TrashItemTypeVenue:
+			if _, restoreError = models.FindDeletedVenueForUser(databaseConnection, itemID, currentUser.ID); restoreError == nil {
+				restoreError = models.RestoreSoftDeleted(databaseConnection, &models.Venue{}, itemID)
+			}
+		case config.TrashItemTypeRSVP:
+			var deletedRSVP *models.RSVP
+			if deletedRSVP, restoreError = models.FindDeletedRSVPForUser(databaseConnection, itemID, currentUser.ID); restoreError == nil {
+				if restoreError = models.RestoreRSVP(databaseConnection, itemID); restoreError == nil {
+					var parentEvent models.Event
+					if findError := parentEvent.FindByID(databaseConnection, deletedRSVP.EventID); findError == nil {
+						handlers.PublishRSVPChange(request.Context(), applicationContext, realtime.KindRSVPCreated, deletedRSVP, &parentEvent)
+					}
+				}
+			}
+		}
+
+		switch {
+		case errors.Is(restoreError, gorm.ErrRecordNotFound):
+			baseHttpHandler.HandleError(responseWriter, request, restoreError, utils.NotFoundError, "Deleted item not found.")
+		case errors.Is(restoreError, models.ErrParentEventDeleted):
+			baseHttpHandler.HandleError(responseWriter, request, restoreError, utils.ValidationError, restoreError.Error())
+		case restoreError != nil:
+			baseHttpHandler.HandleError(responseWriter, request, restoreError, utils.DatabaseError, "Failed to restore the item.")
+		default:
+			baseHttpHandler.RedirectToList(responseWriter, request)
+		}
+	}
+}
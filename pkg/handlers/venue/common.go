@@ -3,13 +3,17 @@ package venue
 import (
 	"github.com/temirov/RSVP/models"
 	"github.com/temirov/RSVP/pkg/config"
+	"gorm.io/gorm"
 )
 
 type ListViewData struct {
 	VenueList                 []models.Venue
 	SelectedItemForEdit       *models.Venue
+	CanDeleteSelected         bool
+	CanTransferSelected       bool
 	URLForVenueActions        string
 	URLForVenues              string
+	URLForTransfers           string
 	ParamNameMethodOverride   string
 	ParamNameVenueID          string
 	ParamNameVenueName        string
@@ -19,6 +23,9 @@ type ListViewData struct {
 	ParamNameVenuePhone       string
 	ParamNameVenueEmail       string
 	ParamNameVenueWebsite     string
+	ParamNameTransferType     string
+	ParamNameTransferItemID   string
+	TransferResourceType      string
 	ButtonCancelEdit          string
 	ButtonUpdateVenue         string
 	ButtonDeleteVenue         string
@@ -38,6 +45,7 @@ func NewListViewData(venueList []models.Venue, selectedVenue *models.Venue) List
 		SelectedItemForEdit:       selectedVenue,
 		URLForVenueActions:        config.WebVenues,
 		URLForVenues:              config.WebVenues,
+		URLForTransfers:           config.WebTransfers,
 		ParamNameMethodOverride:   config.MethodOverrideParam,
 		ParamNameVenueID:          config.VenueIDParam,
 		ParamNameVenueName:        config.VenueNameParam,
@@ -47,6 +55,9 @@ func NewListViewData(venueList []models.Venue, selectedVenue *models.Venue) List
 		ParamNameVenuePhone:       config.VenuePhoneParam,
 		ParamNameVenueEmail:       config.VenueEmailParam,
 		ParamNameVenueWebsite:     config.VenueWebsiteParam,
+		ParamNameTransferType:     config.TransferTypeParam,
+		ParamNameTransferItemID:   config.TransferResourceIDParam,
+		TransferResourceType:      config.TransferResourceVenue,
 		ButtonCancelEdit:          config.ButtonCancelEdit,
 		ButtonUpdateVenue:         config.ButtonUpdateVenue,
 		ButtonDeleteVenue:         config.ButtonDeleteVenue,
@@ -60,3 +71,83 @@ func NewListViewData(venueList []models.Venue, selectedVenue *models.Venue) List
 		VenueManagerLabel:         config.ResourceLabelVenueManager,
 	}
 }
+
+// findEditableVenue loads a venue the user may edit: one in their personal workspace, one of an organization
+// they belong to, or the venue of an event they may edit.
+// It returns gorm.ErrRecordNotFound when the venue does not exist or the user may not edit it.
+func findEditableVenue(databaseConnection *gorm.DB, venueRecord *models.Venue, venueIdentifier string, currentUserID string) error {
+	if err := venueRecord.FindByID(databaseConnection, venueIdentifier); err != nil {
+		return err
+	}
+	if venueRecord.OrganizationID != nil {
+		organizationRole, err := models.OrganizationRoleForUser(databaseConnection, *venueRecord.OrganizationID, currentUserID)
+		if err != nil {
+			return err
+		}
+		if organizationRole != "" {
+			return nil
+		}
+	} else if venueRecord.UserID == currentUserID {
+		return nil
+	}
+	editableVenueIDs, err := models.FindVenueIDsEditableByMember(databaseConnection, currentUserID)
+	if err != nil {
+		return err
+	}
+	for _, editableVenueID := range editableVenueIDs {
+		if editableVenueID == venueIdentifier {
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+
+// canDeleteVenue reports whether the user may delete the venue: its owner for a personal venue, an owner or
+// admin of the organization for an organization's venue. Co-hosts never may.
+func canDeleteVenue(databaseConnection *gorm.DB, venueRecord *models.Venue, currentUserID string) (bool, error) {
+	if venueRecord.OrganizationID == nil {
+		return venueRecord.UserID == currentUserID, nil
+	}
+	organizationRole, err := models.OrganizationRoleForUser(databaseConnection, *venueRecord.OrganizationID, currentUserID)
+	if err != nil {
+		return false, err
+	}
+	return models.OrgRoleAllowsManagement(organizationRole), nil
+}
+
+// findVenuesForMember lists the venues of the active workspace. In the personal workspace these are the venues
+// the user owns followed by the venues of events they may edit outside their own organizations.
+func findVenuesForMember(databaseConnection *gorm.DB, currentUserID string, activeOrganization *models.Organization) ([]models.Venue, error) {
+	if activeOrganization != nil {
+		return models.FindVenuesInOrganization(databaseConnection, activeOrganization.ID)
+	}
+	ownedVenues, err := models.FindVenuesByOwner(databaseConnection, currentUserID)
+	if err != nil {
+		return nil, err
+	}
+	editableVenueIDs, err := models.FindVenueIDsEditableByMember(databaseConnection, currentUserID)
+	if err != nil {
+		return nil, err
+	}
+	sharedVenues, err := models.FindVenuesByIDs(databaseConnection, editableVenueIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, sharedVenue := range sharedVenues {
+		if sharedVenue.OrganizationID == nil {
+			if sharedVenue.UserID != currentUserID {
+				ownedVenues = append(ownedVenues, sharedVenue)
+			}
+			continue
+		}
+		// Venues of the user's own organizations are listed in that organization's workspace instead.
+		organizationRole, err := models.OrganizationRoleForUser(databaseConnection, *sharedVenue.OrganizationID, currentUserID)
+		if err != nil {
+			return nil, err
+		}
+		if organizationRole == "" {
+			ownedVenues = append(ownedVenues, sharedVenue)
+		}
+	}
+	return ownedVenues, nil
+}
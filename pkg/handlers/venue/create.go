@@ -20,7 +20,7 @@ func CreateVenueHandler(applicationContext *config.ApplicationContext) http.Hand
 			return
 		}
 		if err := request.ParseForm(); err != nil {
-			baseHttpHandler.HandleError(responseWriter, err, utils.ValidationError, utils.ErrMsgInvalidFormData)
+			baseHttpHandler.HandleError(responseWriter, request, err, utils.ValidationError, utils.ErrMsgInvalidFormData)
 			return
 		}
 		currentUser := request.Context().Value(middleware.ContextKeyUser).(*models.User)
@@ -47,15 +47,17 @@ func CreateVenueHandler(applicationContext *config.ApplicationContext) http.Hand
 			Email:       venueEmail,
 			Website:     venueWebsite,
 			UserID:      currentUser.ID,
+			// Venues created in an organization's workspace belong to the organization.
+			OrganizationID: middleware.WorkspaceIDFromContext(request.Context()),
 		}
-		transactionError := applicationContext.Database.Transaction(func(databaseTransaction *gorm.DB) error {
+		transactionError := applicationContext.Database.WithContext(request.Context()).Transaction(func(databaseTransaction *gorm.DB) error {
 			if err := newVenue.Create(databaseTransaction); err != nil {
 				return err
 			}
 			return nil
 		})
 		if transactionError != nil {
-			baseHttpHandler.HandleError(responseWriter, transactionError, utils.DatabaseError, "Failed to create venue.")
+			baseHttpHandler.HandleError(responseWriter, request, transactionError, utils.DatabaseError, "Failed to create venue.")
 			return
 		}
 		http.Redirect(responseWriter, request, baseHttpHandler.ResourceBasePathForRoutes, http.StatusSeeOther)
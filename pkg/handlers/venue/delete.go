@@ -6,6 +6,7 @@ import (
 	"github.com/temirov/RSVP/models"
 	"github.com/temirov/RSVP/pkg/config"
 	"github.com/temirov/RSVP/pkg/handlers"
+	"github.com/temirov/RSVP/pkg/logging"
 	"github.com/temirov/RSVP/pkg/middleware"
 	"github.com/temirov/RSVP/pkg/utils"
 	"gorm.io/gorm"
@@ -27,31 +28,37 @@ func DeleteVenueHandler(applicationContext *config.ApplicationContext) http.Hand
 		currentUserData := request.Context().Value(middleware.ContextKeyUser).(*models.User)
 
 		var venueRecord models.Venue
-		err := venueRecord.FindByIDAndOwner(applicationContext.Database, targetVenueIdentifier, currentUserData.ID)
+		err := venueRecord.FindByID(applicationContext.Database.WithContext(request.Context()), targetVenueIdentifier)
+		if err == nil {
+			var mayDelete bool
+			if mayDelete, err = canDeleteVenue(applicationContext.Database.WithContext(request.Context()), &venueRecord, currentUserData.ID); err == nil && !mayDelete {
+				err = gorm.ErrRecordNotFound
+			}
+		}
 		if err != nil {
 			if err == gorm.ErrRecordNotFound {
-				baseHttpHandler.HandleError(responseWriter, err, utils.NotFoundError, "Venue not found or you do not have permission to delete it.")
+				baseHttpHandler.HandleError(responseWriter, request, err, utils.NotFoundError, "Venue not found or you do not have permission to delete it.")
 			} else {
-				baseHttpHandler.HandleError(responseWriter, err, utils.DatabaseError, "Failed to verify venue ownership.")
+				baseHttpHandler.HandleError(responseWriter, request, err, utils.DatabaseError, "Failed to verify venue ownership.")
 			}
 			return
 		}
 
-		tx := applicationContext.Database.Begin()
+		tx := applicationContext.Database.WithContext(request.Context()).Begin()
 		if tx.Error != nil {
-			baseHttpHandler.HandleError(responseWriter, tx.Error, utils.DatabaseError, "Failed to start transaction.")
+			baseHttpHandler.HandleError(responseWriter, request, tx.Error, utils.DatabaseError, "Failed to start transaction.")
 			return
 		}
 
 		if deleteError := venueRecord.Delete(tx); deleteError != nil {
 			tx.Rollback()
-			baseHttpHandler.HandleError(responseWriter, deleteError, utils.DatabaseError, "Failed to delete venue.")
+			baseHttpHandler.HandleError(responseWriter, request, deleteError, utils.DatabaseError, "Failed to delete venue.")
 			return
 		}
 
 		if commitErr := tx.Commit().Error; commitErr != nil {
-			applicationContext.Logger.Printf("CRITICAL: Failed to commit transaction for venue deletion %s: %v", targetVenueIdentifier, commitErr)
-			baseHttpHandler.HandleError(responseWriter, commitErr, utils.DatabaseError, "Failed to finalize venue deletion.")
+			logging.FromContext(request.Context()).Error("Failed to commit the venue deletion", "venue_id", targetVenueIdentifier, "error", commitErr)
+			baseHttpHandler.HandleError(responseWriter, request, commitErr, utils.DatabaseError, "Failed to finalize venue deletion.")
 			return
 		}
 
@@ -9,13 +9,15 @@ import (
 	"github.com/temirov/RSVP/models"
 	"github.com/temirov/RSVP/pkg/config"
 	"github.com/temirov/RSVP/pkg/handlers"
+	"github.com/temirov/RSVP/pkg/logging"
 	"github.com/temirov/RSVP/pkg/middleware"
 	"github.com/temirov/RSVP/pkg/utils"
 	"gorm.io/gorm"
 )
 
-// ListVenuesHandler returns an HTTP handler that retrieves a list of venues owned by the current user
-// and potentially prepares a specific venue for editing based on a query parameter.
+// ListVenuesHandler returns an HTTP handler that retrieves the venues of the active workspace: the organization's,
+// or the ones owned by the current user together with the venues of events they co-host as an editor.
+// It potentially prepares a specific venue for editing based on a query parameter.
 func ListVenuesHandler(applicationContext *config.ApplicationContext) http.HandlerFunc {
 	baseHttpHandler := handlers.NewBaseHttpHandler(applicationContext, config.ResourceNameVenue, config.WebVenues)
 	return func(responseWriter http.ResponseWriter, request *http.Request) {
@@ -25,27 +27,35 @@ func ListVenuesHandler(applicationContext *config.ApplicationContext) http.Handl
 
 		if requestedVenueIDForEdit != "" {
 			var venueToEdit models.Venue
-			err := venueToEdit.FindByIDAndOwner(applicationContext.Database, requestedVenueIDForEdit, currentUser.ID)
+			err := findEditableVenue(applicationContext.Database.WithContext(request.Context()), &venueToEdit, requestedVenueIDForEdit, currentUser.ID)
 			if err == nil {
 				selectedVenueForEdit = &venueToEdit
 			} else {
 				if errors.Is(err, gorm.ErrRecordNotFound) {
-					baseHttpHandler.ApplicationContext.Logger.Printf("WARN: Venue %s not found for editing or user %s does not own it.", requestedVenueIDForEdit, currentUser.ID)
+					logging.FromContext(request.Context()).Warn("Venue not found for editing or the user may not edit it", "venue_id", requestedVenueIDForEdit)
 				} else {
-					baseHttpHandler.ApplicationContext.Logger.Printf("ERROR: Failed to retrieve venue %s for edit owned by user %s: %v", requestedVenueIDForEdit, currentUser.ID, err)
-					baseHttpHandler.HandleError(responseWriter, err, utils.DatabaseError, "Failed to retrieve venue details.")
+					logging.FromContext(request.Context()).Error("Failed to retrieve the venue for editing", "venue_id", requestedVenueIDForEdit, "error", err)
+					baseHttpHandler.HandleError(responseWriter, request, err, utils.DatabaseError, "Failed to retrieve venue details.")
 					return
 				}
 			}
 		}
 
-		venueList, err := models.FindVenuesByOwner(applicationContext.Database, currentUser.ID)
+		venueList, err := findVenuesForMember(applicationContext.Database.WithContext(request.Context()), currentUser.ID, middleware.WorkspaceFromContext(request.Context()))
 		if err != nil {
-			baseHttpHandler.HandleError(responseWriter, err, utils.DatabaseError, "Failed to retrieve venues.")
+			baseHttpHandler.HandleError(responseWriter, request, err, utils.DatabaseError, "Failed to retrieve venues.")
 			return
 		}
 
 		viewData := NewListViewData(venueList, selectedVenueForEdit)
+		if selectedVenueForEdit != nil {
+			viewData.CanDeleteSelected, err = canDeleteVenue(applicationContext.Database.WithContext(request.Context()), selectedVenueForEdit, currentUser.ID)
+			if err != nil {
+				baseHttpHandler.HandleError(responseWriter, request, err, utils.DatabaseError, "Failed to verify venue permissions.")
+				return
+			}
+			viewData.CanTransferSelected = selectedVenueForEdit.UserID == currentUser.ID && selectedVenueForEdit.OrganizationID == nil
+		}
 		baseHttpHandler.RenderView(responseWriter, request, config.TemplateVenues, viewData)
 	}
 }
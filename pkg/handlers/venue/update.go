@@ -7,6 +7,7 @@ import (
 	"github.com/temirov/RSVP/models"
 	"github.com/temirov/RSVP/pkg/config"
 	"github.com/temirov/RSVP/pkg/handlers"
+	"github.com/temirov/RSVP/pkg/logging"
 	"github.com/temirov/RSVP/pkg/middleware"
 	"github.com/temirov/RSVP/pkg/utils"
 	"gorm.io/gorm"
@@ -27,17 +28,17 @@ func UpdateVenueHandler(applicationContext *config.ApplicationContext) http.Hand
 		targetVenueID := parameters[config.VenueIDParam]
 
 		var existingVenue models.Venue
-		if err := existingVenue.FindByIDAndOwner(applicationContext.Database, targetVenueID, currentUser.ID); err != nil {
+		if err := findEditableVenue(applicationContext.Database.WithContext(request.Context()), &existingVenue, targetVenueID, currentUser.ID); err != nil {
 			if err == gorm.ErrRecordNotFound {
-				baseHttpHandler.HandleError(responseWriter, err, utils.NotFoundError, "Venue not found or you do not have permission to edit it.")
+				baseHttpHandler.HandleError(responseWriter, request, err, utils.NotFoundError, "Venue not found or you do not have permission to edit it.")
 			} else {
-				baseHttpHandler.HandleError(responseWriter, err, utils.DatabaseError, "Error retrieving venue for update.")
+				baseHttpHandler.HandleError(responseWriter, request, err, utils.DatabaseError, "Error retrieving venue for update.")
 			}
 			return
 		}
 
 		if err := request.ParseForm(); err != nil {
-			baseHttpHandler.HandleError(responseWriter, err, utils.ValidationError, utils.ErrMsgInvalidFormData)
+			baseHttpHandler.HandleError(responseWriter, request, err, utils.ValidationError, utils.ErrMsgInvalidFormData)
 			return
 		}
 
@@ -55,7 +56,7 @@ func UpdateVenueHandler(applicationContext *config.ApplicationContext) http.Hand
 			if parseError == nil {
 				newVenueCapacity = parsedCapacity
 			} else {
-				applicationContext.Logger.Printf("WARN: Could not parse venue capacity '%s' for venue %s. Using 0.", newVenueCapacityString, targetVenueID)
+				logging.FromContext(request.Context()).Warn("Could not parse the venue capacity; using 0", "capacity", newVenueCapacityString, "venue_id", targetVenueID)
 			}
 		}
 
@@ -67,11 +68,11 @@ func UpdateVenueHandler(applicationContext *config.ApplicationContext) http.Hand
 		existingVenue.Email = newVenueEmail
 		existingVenue.Website = newVenueWebsite
 
-		if err := existingVenue.Update(applicationContext.Database); err != nil {
+		if err := existingVenue.Update(applicationContext.Database.WithContext(request.Context())); err != nil {
 			if validationErr := utils.IsValidationError(err); validationErr != nil {
-				baseHttpHandler.HandleError(responseWriter, validationErr, utils.ValidationError, validationErr.Error())
+				baseHttpHandler.HandleError(responseWriter, request, validationErr, utils.ValidationError, validationErr.Error())
 			} else {
-				baseHttpHandler.HandleError(responseWriter, err, utils.DatabaseError, "Failed to update venue.")
+				baseHttpHandler.HandleError(responseWriter, request, err, utils.DatabaseError, "Failed to update venue.")
 			}
 			return
 		}
@@ -0,0 +1,104 @@
+// Package invitation signs and verifies the tokens in guest invitation links. A token names an RSVP, the link
+// version of that RSVP and an optional expiry, followed by an HMAC-SHA256 signature over all three, so a guest
+// link cannot be guessed from an RSVP code or altered to point at another RSVP. Raising an RSVP's link version
+// revokes every token issued before.
+package invitation
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// signingKeyLabel separates the invitation signing key from other uses of the secret it is derived from.
+const signingKeyLabel = "rsvp invitation links"
+
+// signatureLength is the number of bytes of the HMAC kept in a token.
+const signatureLength = 16
+
+var (
+	// ErrInvalidToken is returned for a token that is malformed or not signed with this installation's key.
+	ErrInvalidToken = errors.New("invalid invitation token")
+	// ErrExpiredToken is returned for an authentic token past its expiry.
+	ErrExpiredToken = errors.New("invitation token has expired")
+)
+
+// Claims are the facts an invitation token vouches for.
+type Claims struct {
+	RSVPID      string
+	LinkVersion int
+	// ExpiresAt is zero for links that do not expire.
+	ExpiresAt time.Time
+}
+
+// Signer issues and checks invitation tokens.
+type Signer struct {
+	signingKey       []byte
+	expireAfterEvent time.Duration
+}
+
+// NewSigner creates a signer whose key is derived from secret. Links expire expireAfterEvent after their event
+// ends, or never when it is zero.
+func NewSigner(secret string, expireAfterEvent time.Duration) *Signer {
+	keyDerivation := hmac.New(sha256.New, []byte(secret))
+	keyDerivation.Write([]byte(signingKeyLabel))
+	return &Signer{signingKey: keyDerivation.Sum(nil), expireAfterEvent: expireAfterEvent}
+}
+
+// ExpiryFor returns when links to an event ending at eventEndTime stop working, or the zero time if they never do.
+func (invitationSigner *Signer) ExpiryFor(eventEndTime time.Time) time.Time {
+	if invitationSigner.expireAfterEvent <= 0 {
+		return time.Time{}
+	}
+	return eventEndTime.Add(invitationSigner.expireAfterEvent)
+}
+
+// Sign returns the token for the claims. The same claims always give the same token, so a printed QR code
+// stays valid until the RSVP's link is reissued.
+func (invitationSigner *Signer) Sign(invitationClaims Claims) string {
+	var expiresAtField string
+	if invitationClaims.ExpiresAt.IsZero() {
+		expiresAtField = "0"
+	} else {
+		expiresAtField = strconv.FormatInt(invitationClaims.ExpiresAt.Unix(), 36)
+	}
+	signedPayload := invitationClaims.RSVPID + "." + strconv.Itoa(invitationClaims.LinkVersion) + "." + expiresAtField
+	return signedPayload + "." + invitationSigner.signature(signedPayload)
+}
+
+// Verify checks the token's signature and expiry at currentTime and returns its claims. It does not check that
+// the link version is still current; that is up to the caller, which holds the RSVP.
+func (invitationSigner *Signer) Verify(invitationToken string, currentTime time.Time) (Claims, error) {
+	tokenFields := strings.Split(invitationToken, ".")
+	if len(tokenFields) != 4 {
+		return Claims{}, ErrInvalidToken
+	}
+	signedPayload := strings.Join(tokenFields[:3], ".")
+	if !hmac.Equal([]byte(tokenFields[3]), []byte(invitationSigner.signature(signedPayload))) {
+		return Claims{}, ErrInvalidToken
+	}
+	linkVersion, versionError := strconv.Atoi(tokenFields[1])
+	expiresAtUnix, expiryError := strconv.ParseInt(tokenFields[2], 36, 64)
+	if versionError != nil || expiryError != nil {
+		return Claims{}, ErrInvalidToken
+	}
+	invitationClaims := Claims{RSVPID: tokenFields[0], LinkVersion: linkVersion}
+	if expiresAtUnix != 0 {
+		invitationClaims.ExpiresAt = time.Unix(expiresAtUnix, 0)
+		if !currentTime.Before(invitationClaims.ExpiresAt) {
+			return invitationClaims, ErrExpiredToken
+		}
+	}
+	return invitationClaims, nil
+}
+
+// signature returns the truncated, URL-safe HMAC of the payload.
+func (invitationSigner *Signer) signature(signedPayload string) string {
+	payloadMAC := hmac.New(sha256.New, invitationSigner.signingKey)
+	payloadMAC.Write([]byte(signedPayload))
+	return base64.RawURLEncoding.EncodeToString(payloadMAC.Sum(nil)[:signatureLength])
+}
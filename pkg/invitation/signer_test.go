@@ -0,0 +1,121 @@
+package invitation
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testSecret = "test secret"
+
+func TestSignAndVerifyRoundTrip(t *testing.T) {
+	invitationSigner := NewSigner(testSecret, 0)
+	expiresAt := time.Date(2030, time.March, 1, 12, 0, 0, 0, time.UTC)
+	for _, signedClaims := range []Claims{
+		{RSVPID: "abc123", LinkVersion: 0},
+		{RSVPID: "abc123", LinkVersion: 7, ExpiresAt: expiresAt},
+	} {
+		invitationToken := invitationSigner.Sign(signedClaims)
+		if invitationSigner.Sign(signedClaims) != invitationToken {
+			t.Errorf("signing %+v twice gave different tokens", signedClaims)
+		}
+		verifiedClaims, verifyError := invitationSigner.Verify(invitationToken, expiresAt.Add(-time.Hour))
+		if verifyError != nil {
+			t.Fatalf("Verify(%q): %v", invitationToken, verifyError)
+		}
+		if verifiedClaims.RSVPID != signedClaims.RSVPID || verifiedClaims.LinkVersion != signedClaims.LinkVersion || !verifiedClaims.ExpiresAt.Equal(signedClaims.ExpiresAt) {
+			t.Errorf("Verify(%q) = %+v, want %+v", invitationToken, verifiedClaims, signedClaims)
+		}
+	}
+}
+
+func TestVerifyRejectsTamperedAndForeignTokens(t *testing.T) {
+	invitationSigner := NewSigner(testSecret, 0)
+	expiresAt := time.Date(2030, time.March, 1, 12, 0, 0, 0, time.UTC)
+	invitationToken := invitationSigner.Sign(Claims{RSVPID: "abc123", LinkVersion: 2, ExpiresAt: expiresAt})
+	tokenFields := strings.Split(invitationToken, ".")
+	withField := func(fieldIndex int, fieldValue string) string {
+		changedFields := append([]string(nil), tokenFields...)
+		changedFields[fieldIndex] = fieldValue
+		return strings.Join(changedFields, ".")
+	}
+
+	testCases := []struct {
+		name            string
+		invitationToken string
+		verifier        *Signer
+	}{
+		{name: "other RSVP", invitationToken: withField(0, "xyz789"), verifier: invitationSigner},
+		{name: "other link version", invitationToken: withField(1, "3"), verifier: invitationSigner},
+		{name: "later expiry", invitationToken: withField(2, "zzzzzz"), verifier: invitationSigner},
+		{name: "expiry removed", invitationToken: withField(2, "0"), verifier: invitationSigner},
+		{name: "altered signature", invitationToken: withField(3, strings.Repeat("A", len(tokenFields[3]))), verifier: invitationSigner},
+		{name: "missing signature", invitationToken: strings.Join(tokenFields[:3], "."), verifier: invitationSigner},
+		{name: "extra field", invitationToken: invitationToken + ".x", verifier: invitationSigner},
+		{name: "empty", invitationToken: "", verifier: invitationSigner},
+		{name: "bare RSVP code", invitationToken: "abc123", verifier: invitationSigner},
+		{name: "other installation", invitationToken: invitationToken, verifier: NewSigner("another secret", 0)},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, verifyError := testCase.verifier.Verify(testCase.invitationToken, expiresAt.Add(-time.Hour))
+			if !errors.Is(verifyError, ErrInvalidToken) {
+				t.Errorf("Verify(%q) error = %v, want ErrInvalidToken", testCase.invitationToken, verifyError)
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsExpiredTokensButReturnsTheirClaims(t *testing.T) {
+	invitationSigner := NewSigner(testSecret, 0)
+	expiresAt := time.Date(2030, time.March, 1, 12, 0, 0, 0, time.UTC)
+	invitationToken := invitationSigner.Sign(Claims{RSVPID: "abc123", LinkVersion: 1, ExpiresAt: expiresAt})
+
+	for _, currentTime := range []time.Time{expiresAt, expiresAt.Add(time.Second)} {
+		verifiedClaims, verifyError := invitationSigner.Verify(invitationToken, currentTime)
+		if !errors.Is(verifyError, ErrExpiredToken) {
+			t.Errorf("Verify at %v error = %v, want ErrExpiredToken", currentTime, verifyError)
+		}
+		if verifiedClaims.RSVPID != "abc123" || verifiedClaims.LinkVersion != 1 {
+			t.Errorf("Verify at %v claims = %+v, want the signed claims", currentTime, verifiedClaims)
+		}
+	}
+	if _, verifyError := invitationSigner.Verify(invitationToken, expiresAt.Add(-time.Second)); verifyError != nil {
+		t.Errorf("Verify just before the expiry: %v", verifyError)
+	}
+
+	neverExpiringToken := invitationSigner.Sign(Claims{RSVPID: "abc123", LinkVersion: 1})
+	if _, verifyError := invitationSigner.Verify(neverExpiringToken, expiresAt.AddDate(100, 0, 0)); verifyError != nil {
+		t.Errorf("Verify of a link without expiry: %v", verifyError)
+	}
+}
+
+func TestVerifyReturnsTheSignedLinkVersion(t *testing.T) {
+	invitationSigner := NewSigner(testSecret, 0)
+	revokedToken := invitationSigner.Sign(Claims{RSVPID: "abc123", LinkVersion: 1})
+	currentToken := invitationSigner.Sign(Claims{RSVPID: "abc123", LinkVersion: 2})
+	if revokedToken == currentToken {
+		t.Fatal("tokens for different link versions are equal")
+	}
+	revokedClaims, verifyError := invitationSigner.Verify(revokedToken, time.Now())
+	if verifyError != nil {
+		t.Fatalf("Verify: %v", verifyError)
+	}
+	if revokedClaims.LinkVersion != 1 {
+		t.Errorf("LinkVersion = %d, want 1 so the caller can reject the revoked link", revokedClaims.LinkVersion)
+	}
+}
+
+func TestExpiryFor(t *testing.T) {
+	eventEndTime := time.Date(2030, time.March, 1, 22, 0, 0, 0, time.UTC)
+	if expiry := NewSigner(testSecret, 0).ExpiryFor(eventEndTime); !expiry.IsZero() {
+		t.Errorf("ExpiryFor without an expiry duration = %v, want the zero time", expiry)
+	}
+	if expiry := NewSigner(testSecret, -time.Hour).ExpiryFor(eventEndTime); !expiry.IsZero() {
+		t.Errorf("ExpiryFor with a negative duration = %v, want the zero time", expiry)
+	}
+	if expiry := NewSigner(testSecret, 72*time.Hour).ExpiryFor(eventEndTime); !expiry.Equal(eventEndTime.Add(72 * time.Hour)) {
+		t.Errorf("ExpiryFor = %v, want three days after the event ends", expiry)
+	}
+}
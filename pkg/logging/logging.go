@@ -0,0 +1,148 @@
+// Package logging builds the application's structured logger and carries a logger for each request, tagged with
+// the request ID and, once it is known, the signed-in user, through the request context.
+package logging
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/temirov/RSVP/pkg/config"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// contextKey is a custom type used for keys in context.Context to avoid collisions.
+type contextKey string
+
+// contextKeyRequestState is the key of the request's *requestState in the request context.
+const contextKeyRequestState contextKey = "request_log_state"
+
+// requestState is shared by everything serving one request. The user is recorded on it by the authentication
+// middleware, deep inside the chain, and read back by the request logging middleware once the response is written.
+type requestState struct {
+	mutex     sync.Mutex
+	logger    *slog.Logger
+	requestID string
+	userID    string
+}
+
+// New creates a logger writing to output in the configured format, dropping records below the configured level.
+func New(settings config.LoggingConfig, output io.Writer) *slog.Logger {
+	handlerOptions := &slog.HandlerOptions{Level: settings.Level}
+	if settings.Format == config.LogFormatJSON {
+		return slog.New(slog.NewJSONHandler(output, handlerOptions))
+	}
+	return slog.New(slog.NewTextHandler(output, handlerOptions))
+}
+
+// WithRequest returns a context carrying a logger for the request with the given ID. When the request is traced,
+// the logger is also tagged with the trace and span IDs, so that its log lines can be found from the trace.
+func WithRequest(parentContext context.Context, logger *slog.Logger, requestID string) context.Context {
+	requestLogger := logger.With("request_id", requestID)
+	if traceID := TraceID(parentContext); traceID != "" {
+		requestLogger = requestLogger.With("trace_id", traceID, "span_id", trace.SpanContextFromContext(parentContext).SpanID().String())
+	}
+	return context.WithValue(parentContext, contextKeyRequestState, &requestState{
+		logger:    requestLogger,
+		requestID: requestID,
+	})
+}
+
+// TraceID returns the ID of the trace recorded for the request served under requestContext, or an empty string
+// when it is not traced.
+func TraceID(requestContext context.Context) string {
+	if spanContext := trace.SpanContextFromContext(requestContext); spanContext.IsValid() {
+		return spanContext.TraceID().String()
+	}
+	return ""
+}
+
+// FromContext returns the logger of the request served under requestContext, or the default logger outside a request.
+func FromContext(requestContext context.Context) *slog.Logger {
+	if state := stateFromContext(requestContext); state != nil {
+		state.mutex.Lock()
+		defer state.mutex.Unlock()
+		return state.logger
+	}
+	return slog.Default()
+}
+
+// RequestID returns the ID of the request served under requestContext, or an empty string outside a request.
+func RequestID(requestContext context.Context) string {
+	if state := stateFromContext(requestContext); state != nil {
+		return state.requestID
+	}
+	return ""
+}
+
+// SetUserID records the signed-in user of the request, so that the request's log lines from here on and its
+// access log line name them. It does nothing outside a request.
+func SetUserID(requestContext context.Context, userID string) {
+	if state := stateFromContext(requestContext); state != nil {
+		state.mutex.Lock()
+		defer state.mutex.Unlock()
+		if state.userID != userID {
+			state.userID = userID
+			state.logger = state.logger.With("user_id", userID)
+		}
+	}
+}
+
+// UserID returns the user recorded with SetUserID, or an empty string.
+func UserID(requestContext context.Context) string {
+	if state := stateFromContext(requestContext); state != nil {
+		state.mutex.Lock()
+		defer state.mutex.Unlock()
+		return state.userID
+	}
+	return ""
+}
+
+// stateFromContext returns the request's log state, or nil outside a request.
+func stateFromContext(requestContext context.Context) *requestState {
+	state, _ := requestContext.Value(contextKeyRequestState).(*requestState)
+	return state
+}
+
+// levelPrefixes maps the level markers that messages written through a standard logger start with to slog levels.
+var levelPrefixes = []struct {
+	prefix string
+	level  slog.Level
+}{
+	{prefix: "DEBUG:", level: slog.LevelDebug},
+	{prefix: "WARNING:", level: slog.LevelWarn},
+	{prefix: "WARN:", level: slog.LevelWarn},
+	{prefix: "ERROR:", level: slog.LevelError},
+	{prefix: "CRITICAL:", level: slog.LevelError},
+	{prefix: "FATAL:", level: slog.LevelError},
+}
+
+// NewStandardLogger returns a *log.Logger for the startup code, background jobs and libraries that take one. Each
+// message becomes a record of logger; a leading "DEBUG:", "WARN:" or "ERROR:" marker sets its level and is dropped.
+func NewStandardLogger(logger *slog.Logger) *log.Logger {
+	return log.New(&standardLogWriter{logger: logger}, "", 0)
+}
+
+// standardLogWriter turns each write of a standard logger into one structured record.
+type standardLogWriter struct {
+	logger *slog.Logger
+}
+
+// Write logs one message; the standard logger writes each message in a single call.
+func (writer *standardLogWriter) Write(message []byte) (int, error) {
+	recordMessage := string(bytes.TrimRight(message, "\n"))
+	recordLevel := slog.LevelInfo
+	for _, levelPrefix := range levelPrefixes {
+		if strings.HasPrefix(recordMessage, levelPrefix.prefix) {
+			recordLevel = levelPrefix.level
+			recordMessage = strings.TrimSpace(recordMessage[len(levelPrefix.prefix):])
+			break
+		}
+	}
+	writer.logger.Log(context.Background(), recordLevel, recordMessage)
+	return len(message), nil
+}
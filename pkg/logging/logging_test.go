@@ -0,0 +1,45 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestRequestLoggerIsTaggedWithTheTrace(t *testing.T) {
+	var logOutput bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logOutput, nil))
+
+	untracedContext := WithRequest(context.Background(), logger, "request-1")
+	if traceID := TraceID(untracedContext); traceID != "" {
+		t.Errorf("TraceID without a span = %q, want empty", traceID)
+	}
+	FromContext(untracedContext).Info("untraced")
+	if untracedLine := logOutput.String(); !strings.Contains(untracedLine, "request_id=request-1") || strings.Contains(untracedLine, "trace_id=") {
+		t.Errorf("untraced line %q, want a request ID and no trace ID", untracedLine)
+	}
+
+	logOutput.Reset()
+	spanContext := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x01, 0x02, 0x03},
+		SpanID:     trace.SpanID{0x04, 0x05},
+		TraceFlags: trace.FlagsSampled,
+	})
+	tracedContext := WithRequest(trace.ContextWithSpanContext(context.Background(), spanContext), logger, "request-2")
+	if traceID := TraceID(tracedContext); traceID != spanContext.TraceID().String() {
+		t.Errorf("TraceID = %q, want %q", traceID, spanContext.TraceID().String())
+	}
+	if requestID := RequestID(tracedContext); requestID != "request-2" {
+		t.Errorf("RequestID = %q, want %q", requestID, "request-2")
+	}
+	FromContext(tracedContext).Info("traced")
+	for _, expectedField := range []string{"trace_id=" + spanContext.TraceID().String(), "span_id=" + spanContext.SpanID().String()} {
+		if !strings.Contains(logOutput.String(), expectedField) {
+			t.Errorf("traced line %q does not contain %q", logOutput.String(), expectedField)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package metrics
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// queryStartKey is where the plugin keeps a statement's start time on the GORM instance running it.
+const queryStartKey = "metrics:query_start"
+
+// GormPlugin is a GORM plugin that records how long each database statement takes.
+type GormPlugin struct {
+	metrics *Metrics
+}
+
+// NewGormPlugin returns the plugin recording into applicationMetrics.
+func NewGormPlugin(applicationMetrics *Metrics) *GormPlugin {
+	return &GormPlugin{metrics: applicationMetrics}
+}
+
+// Name identifies the plugin to GORM.
+func (plugin *GormPlugin) Name() string {
+	return "rsvp:metrics"
+}
+
+// Initialize registers a callback before and after each operation, which note the start time and record the
+// elapsed time.
+func (plugin *GormPlugin) Initialize(database *gorm.DB) error {
+	callbacks := database.Callback()
+	beforeName, afterName := plugin.Name()+":before_", plugin.Name()+":after_"
+	return errors.Join(
+		callbacks.Create().Before("gorm:create").Register(beforeName+"create", plugin.noteStart),
+		callbacks.Create().After("gorm:create").Register(afterName+"create", plugin.recordDuration("create")),
+		callbacks.Query().Before("gorm:query").Register(beforeName+"query", plugin.noteStart),
+		callbacks.Query().After("gorm:query").Register(afterName+"query", plugin.recordDuration("query")),
+		callbacks.Update().Before("gorm:update").Register(beforeName+"update", plugin.noteStart),
+		callbacks.Update().After("gorm:update").Register(afterName+"update", plugin.recordDuration("update")),
+		callbacks.Delete().Before("gorm:delete").Register(beforeName+"delete", plugin.noteStart),
+		callbacks.Delete().After("gorm:delete").Register(afterName+"delete", plugin.recordDuration("delete")),
+		callbacks.Row().Before("gorm:row").Register(beforeName+"row", plugin.noteStart),
+		callbacks.Row().After("gorm:row").Register(afterName+"row", plugin.recordDuration("row")),
+		callbacks.Raw().Before("gorm:raw").Register(beforeName+"raw", plugin.noteStart),
+		callbacks.Raw().After("gorm:raw").Register(afterName+"raw", plugin.recordDuration("raw")),
+	)
+}
+
+// noteStart remembers when the statement started.
+func (plugin *GormPlugin) noteStart(database *gorm.DB) {
+	database.InstanceSet(queryStartKey, time.Now())
+}
+
+// recordDuration returns the callback recording the time since noteStart for the operation.
+func (plugin *GormPlugin) recordDuration(operation string) func(*gorm.DB) {
+	return func(database *gorm.DB) {
+		if plugin.metrics == nil {
+			return
+		}
+		startValue, isStarted := database.InstanceGet(queryStartKey)
+		queryStart, isTime := startValue.(time.Time)
+		if !isStarted || !isTime {
+			return
+		}
+		tableName := database.Statement.Table
+		if tableName == "" {
+			tableName = "unknown"
+		}
+		plugin.metrics.databaseQueryLatency.WithLabelValues(operation, tableName).Observe(time.Since(queryStart).Seconds())
+	}
+}
@@ -0,0 +1,183 @@
+// Package metrics collects the application's Prometheus metrics: HTTP requests by route and status, guest
+// responses, events created, QR codes generated, database query latency, the public rate limiter's counters and
+// the Go runtime and process metrics. It keeps its own registry, so only these metrics are exposed.
+package metrics
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace prefixes the name of every application metric.
+const namespace = "rsvp"
+
+// unmatchedRoute labels requests that matched no route, so that scanners cannot create a label per path they try.
+const unmatchedRoute = "unmatched"
+
+// Metrics holds the application's collectors. A nil *Metrics records nothing, so code that counts does not need
+// to check whether metrics are enabled.
+type Metrics struct {
+	registry             *prometheus.Registry
+	httpRequests         *prometheus.CounterVec
+	httpRequestDuration  *prometheus.HistogramVec
+	rsvpResponses        *prometheus.CounterVec
+	eventsCreated        prometheus.Counter
+	qrCodesGenerated     prometheus.Counter
+	databaseQueryLatency *prometheus.HistogramVec
+}
+
+// RateLimitStats mirrors ratelimit.Stats, which converts to it, without this package depending on the limiter.
+type RateLimitStats struct {
+	RejectedRequests      uint64
+	FailedLookups         uint64
+	SuspectedEnumerations uint64
+}
+
+// New creates the collectors and registers them, together with the Go runtime and process collectors.
+func New() *Metrics {
+	applicationMetrics := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests served, by route pattern, method and status code.",
+		}, []string{"route", "method", "status"}),
+		httpRequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Time taken to serve HTTP requests, by route pattern and method.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "method"}),
+		rsvpResponses: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "rsvp_responses_total",
+			Help:      "RSVP responses recorded, by response and by whether the guest or an organizer recorded it.",
+		}, []string{"response", "source"}),
+		eventsCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "events_created_total",
+			Help:      "Events created.",
+		}),
+		qrCodesGenerated: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "qr_codes_generated_total",
+			Help:      "Invitation QR codes generated.",
+		}),
+		databaseQueryLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "db_query_duration_seconds",
+			Help:      "Time taken by database statements, by operation and table.",
+			Buckets:   []float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5},
+		}, []string{"operation", "table"}),
+	}
+	applicationMetrics.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		applicationMetrics.httpRequests,
+		applicationMetrics.httpRequestDuration,
+		applicationMetrics.rsvpResponses,
+		applicationMetrics.eventsCreated,
+		applicationMetrics.qrCodesGenerated,
+		applicationMetrics.databaseQueryLatency,
+	)
+	return applicationMetrics
+}
+
+// ObserveHTTPRequest records a served request. Route is the pattern that matched it, or empty if none did.
+func (applicationMetrics *Metrics) ObserveHTTPRequest(route, method string, statusCode int, duration time.Duration) {
+	if applicationMetrics == nil {
+		return
+	}
+	if route == "" {
+		route = unmatchedRoute
+	}
+	// Any method a client sends would otherwise become a label value.
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions:
+	default:
+		method = "OTHER"
+	}
+	applicationMetrics.httpRequests.WithLabelValues(route, method, strconv.Itoa(statusCode)).Inc()
+	applicationMetrics.httpRequestDuration.WithLabelValues(route, method).Observe(duration.Seconds())
+}
+
+// CountRSVPResponse records a response as stored on an RSVP ("Yes", "No,0" or empty for pending), labelled
+// "yes", "no" or "pending". Source is "guest" or "organizer".
+func (applicationMetrics *Metrics) CountRSVPResponse(storedResponse, source string) {
+	if applicationMetrics == nil {
+		return
+	}
+	responseLabel := "pending"
+	if storedResponse != "" {
+		responseLabel = strings.ToLower(strings.SplitN(storedResponse, ",", 2)[0])
+	}
+	applicationMetrics.rsvpResponses.WithLabelValues(responseLabel, source).Inc()
+}
+
+// CountEventCreated records a created event.
+func (applicationMetrics *Metrics) CountEventCreated() {
+	if applicationMetrics == nil {
+		return
+	}
+	applicationMetrics.eventsCreated.Inc()
+}
+
+// CountQRCodeGenerated records a generated invitation QR code.
+func (applicationMetrics *Metrics) CountQRCodeGenerated() {
+	if applicationMetrics == nil {
+		return
+	}
+	applicationMetrics.qrCodesGenerated.Inc()
+}
+
+// ObserveRateLimiter exposes the counters of the public rate limiter, read from readStats at each scrape.
+func (applicationMetrics *Metrics) ObserveRateLimiter(readStats func() RateLimitStats) {
+	if applicationMetrics == nil {
+		return
+	}
+	statCounters := []struct {
+		name      string
+		help      string
+		readValue func(RateLimitStats) uint64
+	}{
+		{"public_rate_limited_requests_total", "Public invitation page requests refused by the rate limiter.",
+			func(stats RateLimitStats) uint64 { return stats.RejectedRequests }},
+		{"public_failed_lookups_total", "Public invitation page requests for unknown or invalid invitation codes.",
+			func(stats RateLimitStats) uint64 { return stats.FailedLookups }},
+		{"public_suspected_enumerations_total", "Clients locked out for asking for too many unknown invitation codes.",
+			func(stats RateLimitStats) uint64 { return stats.SuspectedEnumerations }},
+	}
+	for _, statCounter := range statCounters {
+		readValue := statCounter.readValue
+		applicationMetrics.registry.MustRegister(prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      statCounter.name,
+			Help:      statCounter.help,
+		}, func() float64 { return float64(readValue(readStats())) }))
+	}
+}
+
+// Handler serves the metrics in the Prometheus text format. When bearerToken is not empty, requests must carry it
+// in an "Authorization: Bearer" header.
+func (applicationMetrics *Metrics) Handler(bearerToken string) http.Handler {
+	metricsHandler := promhttp.HandlerFor(applicationMetrics.registry, promhttp.HandlerOpts{})
+	if bearerToken == "" {
+		return metricsHandler
+	}
+	expectedAuthorization := []byte("Bearer " + bearerToken)
+	return http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(request.Header.Get("Authorization")), expectedAuthorization) != 1 {
+			responseWriter.Header().Set("WWW-Authenticate", `Bearer realm="metrics"`)
+			http.Error(responseWriter, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		metricsHandler.ServeHTTP(responseWriter, request)
+	})
+}
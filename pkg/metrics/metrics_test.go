@@ -0,0 +1,71 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// scrape returns the metrics text served by handler to a request with the given Authorization header.
+func scrape(t *testing.T, metricsHandler http.Handler, authorization string) *httptest.ResponseRecorder {
+	t.Helper()
+	scrapeRequest := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	if authorization != "" {
+		scrapeRequest.Header.Set("Authorization", authorization)
+	}
+	responseRecorder := httptest.NewRecorder()
+	metricsHandler.ServeHTTP(responseRecorder, scrapeRequest)
+	return responseRecorder
+}
+
+func TestHandlerRequiresTheBearerToken(t *testing.T) {
+	metricsHandler := New().Handler("scrape-secret")
+	for _, authorization := range []string{"", "Bearer wrong", "scrape-secret"} {
+		if responseRecorder := scrape(t, metricsHandler, authorization); responseRecorder.Code != http.StatusUnauthorized {
+			t.Errorf("Authorization %q: status = %d, want %d", authorization, responseRecorder.Code, http.StatusUnauthorized)
+		}
+	}
+	if responseRecorder := scrape(t, metricsHandler, "Bearer scrape-secret"); responseRecorder.Code != http.StatusOK {
+		t.Errorf("status with the token = %d, want %d", responseRecorder.Code, http.StatusOK)
+	}
+	if responseRecorder := scrape(t, New().Handler(""), ""); responseRecorder.Code != http.StatusOK {
+		t.Errorf("status without a configured token = %d, want %d", responseRecorder.Code, http.StatusOK)
+	}
+}
+
+func TestRecordedValuesAreExposedWithBoundedLabels(t *testing.T) {
+	applicationMetrics := New()
+	applicationMetrics.ObserveHTTPRequest("GET /events/", http.MethodGet, http.StatusOK, 10*time.Millisecond)
+	applicationMetrics.ObserveHTTPRequest("", "PROPFIND", http.StatusNotFound, time.Millisecond)
+	applicationMetrics.CountRSVPResponse("Yes", "guest")
+	applicationMetrics.CountRSVPResponse("No,0", "organizer")
+	applicationMetrics.CountRSVPResponse("", "organizer")
+	applicationMetrics.CountEventCreated()
+	applicationMetrics.ObserveRateLimiter(func() RateLimitStats { return RateLimitStats{RejectedRequests: 7} })
+
+	metricsText := scrape(t, applicationMetrics.Handler(""), "").Body.String()
+	for _, expectedLine := range []string{
+		`rsvp_http_requests_total{method="GET",route="GET /events/",status="200"} 1`,
+		`rsvp_http_requests_total{method="OTHER",route="unmatched",status="404"} 1`,
+		`rsvp_rsvp_responses_total{response="yes",source="guest"} 1`,
+		`rsvp_rsvp_responses_total{response="no",source="organizer"} 1`,
+		`rsvp_rsvp_responses_total{response="pending",source="organizer"} 1`,
+		`rsvp_events_created_total 1`,
+		`rsvp_public_rate_limited_requests_total 7`,
+	} {
+		if !strings.Contains(metricsText, expectedLine+"\n") {
+			t.Errorf("metrics do not contain %q", expectedLine)
+		}
+	}
+}
+
+func TestNilMetricsRecordNothing(t *testing.T) {
+	var applicationMetrics *Metrics
+	applicationMetrics.ObserveHTTPRequest("GET /events/", http.MethodGet, http.StatusOK, time.Millisecond)
+	applicationMetrics.CountRSVPResponse("Yes", "guest")
+	applicationMetrics.CountEventCreated()
+	applicationMetrics.CountQRCodeGenerated()
+	applicationMetrics.ObserveRateLimiter(func() RateLimitStats { return RateLimitStats{} })
+}
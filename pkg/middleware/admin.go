@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/temirov/RSVP/models"
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/logging"
+	"github.com/temirov/RSVP/pkg/utils"
+)
+
+// IsAdmin reports whether the user's email address is one of the configured administrator addresses.
+func IsAdmin(userRecord *models.User, adminEmails []string) bool {
+	if userRecord == nil {
+		return false
+	}
+	return isAdminEmail(userRecord.Email, adminEmails)
+}
+
+// isAdminEmail reports whether the email address is one of the configured administrator addresses.
+func isAdminEmail(emailAddress string, adminEmails []string) bool {
+	normalizedEmail := strings.ToLower(emailAddress)
+	for _, adminEmail := range adminEmails {
+		if normalizedEmail == adminEmail {
+			return true
+		}
+	}
+	return false
+}
+
+// RequireAdmin is middleware that only lets administrators (config ADMIN_EMAILS) through.
+// It must run after the user has been placed in the request context by session or bearer authentication.
+func RequireAdmin(applicationContext *config.ApplicationContext, adminEmails []string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+			currentUser, _ := request.Context().Value(ContextKeyUser).(*models.User)
+			if !IsAdmin(currentUser, adminEmails) {
+				if currentUser != nil {
+					logging.FromContext(request.Context()).Warn("Non-admin user denied access", "path", request.URL.Path)
+				}
+				utils.HandleError(responseWriter, request, nil, utils.ForbiddenError, "Forbidden: Administrator access required.")
+				return
+			}
+			next.ServeHTTP(responseWriter, request)
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/temirov/RSVP/models"
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/logging"
+	"github.com/temirov/RSVP/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// ContextKeyAPIToken is the key used to store the *models.APIToken that authenticated the request.
+// It is only present for requests authenticated with a bearer token.
+const ContextKeyAPIToken contextKey = "api_token"
+
+// APITokenFromContext returns the API token that authenticated the request, or nil for session requests.
+func APITokenFromContext(requestContext context.Context) *models.APIToken {
+	apiToken, _ := requestContext.Value(ContextKeyAPIToken).(*models.APIToken)
+	return apiToken
+}
+
+// bearerTokenFromRequest extracts the token value from an "Authorization: Bearer <token>" header.
+// The second return value is false when the request carries no bearer credentials.
+func bearerTokenFromRequest(request *http.Request) (string, bool) {
+	authorizationValue := request.Header.Get(config.AuthorizationHeader)
+	if len(authorizationValue) < len(config.BearerSchemePrefix) ||
+		!strings.EqualFold(authorizationValue[:len(config.BearerSchemePrefix)], config.BearerSchemePrefix) {
+		return "", false
+	}
+	return strings.TrimSpace(authorizationValue[len(config.BearerSchemePrefix):]), true
+}
+
+// AuthenticateBearerToken is middleware that lets non-browser clients authenticate with a personal API token.
+// Requests carrying an "Authorization: Bearer" header are validated against the stored token hashes and, on success,
+// receive the same *models.User under ContextKeyUser that AddUserToContext provides. Requests without bearer
+// credentials are handed to sessionChain, which is expected to enforce the regular session-based authentication.
+func AuthenticateBearerToken(applicationContext *config.ApplicationContext, sessionChain func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		sessionProtectedHandler := sessionChain(next)
+		return http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+			plaintextToken, hasBearerToken := bearerTokenFromRequest(request)
+			if !hasBearerToken {
+				sessionProtectedHandler.ServeHTTP(responseWriter, request)
+				return
+			}
+			if plaintextToken == "" {
+				utils.HandleError(responseWriter, request, nil, utils.AuthenticationError, utils.ErrMsgInvalidAPIToken)
+				return
+			}
+
+			var apiToken models.APIToken
+			if findError := apiToken.FindByPlaintext(applicationContext.Database.WithContext(request.Context()), plaintextToken); findError != nil {
+				if errors.Is(findError, gorm.ErrRecordNotFound) {
+					logging.FromContext(request.Context()).Warn("Unknown API token presented", "path", request.URL.Path, "client_ip", utils.ClientIP(request))
+					utils.HandleError(responseWriter, request, nil, utils.AuthenticationError, utils.ErrMsgInvalidAPIToken)
+				} else {
+					utils.HandleError(responseWriter, request, findError, utils.DatabaseError, "Failed to verify API token.")
+				}
+				return
+			}
+			if apiToken.IsRevoked() {
+				logging.FromContext(request.Context()).Warn("Revoked API token presented", "token_id", apiToken.ID, "path", request.URL.Path)
+				utils.HandleError(responseWriter, request, nil, utils.AuthenticationError, utils.ErrMsgInvalidAPIToken)
+				return
+			}
+
+			var tokenOwner models.User
+			if findOwnerError := tokenOwner.FindByID(applicationContext.Database.WithContext(request.Context()), apiToken.UserID); findOwnerError != nil {
+				logging.FromContext(request.Context()).Error("Failed to load the owner of an API token", "owner_id", apiToken.UserID, "token_id", apiToken.ID, "error", findOwnerError)
+				utils.HandleError(responseWriter, request, nil, utils.AuthenticationError, utils.ErrMsgInvalidAPIToken)
+				return
+			}
+
+			if tokenOwner.IsSuspended() {
+				logging.FromContext(request.Context()).Warn("API token of a suspended user presented", "token_id", apiToken.ID, "owner_id", tokenOwner.ID, "path", request.URL.Path)
+				utils.HandleError(responseWriter, request, nil, utils.ForbiddenError, "Forbidden: "+config.ErrMsgAccountSuspended)
+				return
+			}
+			if !apiToken.AllowsWrites() && request.Method != http.MethodGet && request.Method != http.MethodHead {
+				utils.HandleError(responseWriter, request, nil, utils.ForbiddenError, "Forbidden: This API token is read-only.")
+				return
+			}
+			if apiToken.EventID != nil && !tokenCoversRequestedEvent(applicationContext.Database.WithContext(request.Context()), request, *apiToken.EventID) {
+				utils.HandleError(responseWriter, request, nil, utils.ForbiddenError, "Forbidden: This API token is restricted to a single event.")
+				return
+			}
+
+			logging.SetUserID(request.Context(), tokenOwner.ID)
+			if usageError := apiToken.RecordUsage(applicationContext.Database.WithContext(request.Context()), utils.ClientIP(request)); usageError != nil {
+				logging.FromContext(request.Context()).Warn("Failed to record usage of an API token", "token_id", apiToken.ID, "error", usageError)
+			}
+
+			requestContext := context.WithValue(request.Context(), ContextKeyUser, &tokenOwner)
+			requestContext = context.WithValue(requestContext, ContextKeyAPIToken, &apiToken)
+			next.ServeHTTP(responseWriter, request.WithContext(requestContext))
+		})
+	}
+}
+
+// tokenCoversRequestedEvent reports whether an event-scoped token may serve the request.
+// Every identifier in the request must resolve to the allowed event: each event_id value itself, and the parent
+// event of each rsvp_id value, since the RSVP handlers load the RSVP by its ID alone. Values are taken from both the
+// query string and the form body, so that no copy of a parameter escapes the check. Requests that do not identify an
+// event (for example venue management) are not covered.
+func tokenCoversRequestedEvent(databaseConnection *gorm.DB, request *http.Request, allowedEventID string) bool {
+	requestedEventIDs := requestParamValues(request, config.EventIDParam)
+	requestedRSVPIDs := requestParamValues(request, config.RSVPIDParam)
+	if len(requestedEventIDs) == 0 && len(requestedRSVPIDs) == 0 {
+		return false
+	}
+	for _, requestedEventID := range requestedEventIDs {
+		if requestedEventID != allowedEventID {
+			return false
+		}
+	}
+	for _, requestedRSVPID := range requestedRSVPIDs {
+		var rsvpRecord models.RSVP
+		if findError := rsvpRecord.FindByCode(databaseConnection, requestedRSVPID); findError != nil {
+			return false
+		}
+		if rsvpRecord.EventID != allowedEventID {
+			return false
+		}
+	}
+	return true
+}
+
+// requestParamValues returns every non-empty value of the named parameter in the query string and the form body.
+func requestParamValues(request *http.Request, parameterName string) []string {
+	// Parsing the form through GetParam keeps the body size limit the handlers use.
+	utils.GetParam(request, parameterName, utils.FormParam)
+	var parameterValues []string
+	for _, parameterValue := range append(request.URL.Query()[parameterName], request.PostForm[parameterName]...) {
+		if parameterValue != "" {
+			parameterValues = append(parameterValues, parameterValue)
+		}
+	}
+	return parameterValues
+}
@@ -0,0 +1,185 @@
+package middleware
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/temirov/RSVP/models"
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/testdb"
+)
+
+// bearerTestFixture holds a token owner with two events, an RSVP on each, and a handler behind
+// AuthenticateBearerToken that records which user it was called for.
+type bearerTestFixture struct {
+	applicationContext *config.ApplicationContext
+	tokenOwner         models.User
+	allowedEvent       models.Event
+	otherEvent         models.Event
+	allowedRSVP        models.RSVP
+	otherRSVP          models.RSVP
+	protectedHandler   http.Handler
+	servedUserID       string
+	sessionChainCalled bool
+}
+
+func newBearerTestFixture(t *testing.T) *bearerTestFixture {
+	t.Helper()
+	databaseConnection := testdb.OpenMigrated(t)
+	fixture := &bearerTestFixture{applicationContext: &config.ApplicationContext{
+		Database: databaseConnection,
+		Logger:   slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}}
+	fixture.tokenOwner = models.User{Email: "owner@example.com"}
+	if err := databaseConnection.Create(&fixture.tokenOwner).Error; err != nil {
+		t.Fatalf("creating the token owner: %v", err)
+	}
+	startTime := time.Date(2026, time.June, 1, 18, 0, 0, 0, time.UTC)
+	for eventIndex, eventRecord := range []*models.Event{&fixture.allowedEvent, &fixture.otherEvent} {
+		*eventRecord = models.Event{Title: "Party", StartTime: startTime, EndTime: startTime.Add(time.Hour), UserID: fixture.tokenOwner.ID}
+		if err := eventRecord.Create(databaseConnection); err != nil {
+			t.Fatalf("creating event %d: %v", eventIndex+1, err)
+		}
+	}
+	for rsvpIndex, rsvpPair := range []struct {
+		rsvpRecord *models.RSVP
+		eventID    string
+	}{{&fixture.allowedRSVP, fixture.allowedEvent.ID}, {&fixture.otherRSVP, fixture.otherEvent.ID}} {
+		*rsvpPair.rsvpRecord = models.RSVP{Name: "Guest", EventID: rsvpPair.eventID, Response: config.RSVPResponsePending}
+		if err := rsvpPair.rsvpRecord.Create(databaseConnection); err != nil {
+			t.Fatalf("creating RSVP %d: %v", rsvpIndex+1, err)
+		}
+	}
+	sessionChain := func(http.Handler) http.Handler {
+		return http.HandlerFunc(func(responseWriter http.ResponseWriter, _ *http.Request) {
+			fixture.sessionChainCalled = true
+			responseWriter.WriteHeader(http.StatusUnauthorized)
+		})
+	}
+	fixture.protectedHandler = AuthenticateBearerToken(fixture.applicationContext, sessionChain)(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		fixture.servedUserID = request.Context().Value(ContextKeyUser).(*models.User).ID
+		responseWriter.WriteHeader(http.StatusNoContent)
+	}))
+	return fixture
+}
+
+// issueToken stores a token of the fixture's owner and returns its plaintext.
+func (fixture *bearerTestFixture) issueToken(t *testing.T, tokenScope string, eventIdentifier *string) (*models.APIToken, string) {
+	t.Helper()
+	apiToken, plaintextToken, err := models.IssueAPIToken(fixture.applicationContext.Database, fixture.tokenOwner.ID, "test", tokenScope, eventIdentifier)
+	if err != nil {
+		t.Fatalf("issuing a token: %v", err)
+	}
+	return apiToken, plaintextToken
+}
+
+// serve sends a request with the token and returns the status it was answered with.
+func (fixture *bearerTestFixture) serve(httpMethod string, plaintextToken string, formValues url.Values) int {
+	fixture.servedUserID, fixture.sessionChainCalled = "", false
+	var request *http.Request
+	if httpMethod == http.MethodGet {
+		request = httptest.NewRequest(httpMethod, "/rsvps/?"+formValues.Encode(), nil)
+	} else {
+		request = httptest.NewRequest(httpMethod, "/rsvps/", strings.NewReader(formValues.Encode()))
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	request.Header.Set(config.AuthorizationHeader, config.BearerSchemePrefix+plaintextToken)
+	responseRecorder := httptest.NewRecorder()
+	fixture.protectedHandler.ServeHTTP(responseRecorder, request)
+	return responseRecorder.Code
+}
+
+func TestBearerTokenAuthenticatesItsOwner(t *testing.T) {
+	fixture := newBearerTestFixture(t)
+	_, plaintextToken := fixture.issueToken(t, config.TokenScopeReadWrite, nil)
+	if status := fixture.serve(http.MethodPost, plaintextToken, url.Values{config.EventIDParam: {fixture.otherEvent.ID}}); status != http.StatusNoContent {
+		t.Fatalf("status = %d, want %d", status, http.StatusNoContent)
+	}
+	if fixture.servedUserID != fixture.tokenOwner.ID {
+		t.Errorf("served user = %q, want the token owner %q", fixture.servedUserID, fixture.tokenOwner.ID)
+	}
+	if status := fixture.serve(http.MethodGet, "not-a-token", nil); status != http.StatusUnauthorized || fixture.sessionChainCalled {
+		t.Errorf("unknown token: status = %d, session chain called = %t, want %d without the session chain", status, fixture.sessionChainCalled, http.StatusUnauthorized)
+	}
+
+	sessionRequest := httptest.NewRequest(http.MethodGet, "/rsvps/", nil)
+	fixture.protectedHandler.ServeHTTP(httptest.NewRecorder(), sessionRequest)
+	if !fixture.sessionChainCalled {
+		t.Error("a request without a bearer token was not handed to the session chain")
+	}
+}
+
+func TestReadOnlyTokensOnlyRead(t *testing.T) {
+	fixture := newBearerTestFixture(t)
+	_, plaintextToken := fixture.issueToken(t, config.TokenScopeRead, nil)
+	eventParams := url.Values{config.EventIDParam: {fixture.allowedEvent.ID}}
+	for _, httpMethod := range []string{http.MethodGet, http.MethodHead} {
+		if status := fixture.serve(httpMethod, plaintextToken, eventParams); status != http.StatusNoContent {
+			t.Errorf("%s with a read-only token: status = %d, want %d", httpMethod, status, http.StatusNoContent)
+		}
+	}
+	for _, httpMethod := range []string{http.MethodPost, http.MethodPut, http.MethodDelete} {
+		if status := fixture.serve(httpMethod, plaintextToken, eventParams); status != http.StatusForbidden {
+			t.Errorf("%s with a read-only token: status = %d, want %d", httpMethod, status, http.StatusForbidden)
+		}
+	}
+}
+
+func TestEventScopedTokensStayOnTheirEvent(t *testing.T) {
+	fixture := newBearerTestFixture(t)
+	_, plaintextToken := fixture.issueToken(t, config.TokenScopeReadWrite, &fixture.allowedEvent.ID)
+	testCases := []struct {
+		name       string
+		formValues url.Values
+		wantStatus int
+	}{
+		{name: "its event", formValues: url.Values{config.EventIDParam: {fixture.allowedEvent.ID}}, wantStatus: http.StatusNoContent},
+		{name: "an RSVP of its event", formValues: url.Values{config.RSVPIDParam: {fixture.allowedRSVP.ID}}, wantStatus: http.StatusNoContent},
+		{name: "another event", formValues: url.Values{config.EventIDParam: {fixture.otherEvent.ID}}, wantStatus: http.StatusForbidden},
+		{name: "an RSVP of another event", formValues: url.Values{config.RSVPIDParam: {fixture.otherRSVP.ID}}, wantStatus: http.StatusForbidden},
+		{name: "its event next to another", formValues: url.Values{config.EventIDParam: {fixture.allowedEvent.ID, fixture.otherEvent.ID}}, wantStatus: http.StatusForbidden},
+		{name: "its event with an RSVP of another", formValues: url.Values{config.EventIDParam: {fixture.allowedEvent.ID}, config.RSVPIDParam: {fixture.otherRSVP.ID}}, wantStatus: http.StatusForbidden},
+		{name: "no event at all", formValues: url.Values{}, wantStatus: http.StatusForbidden},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if status := fixture.serve(http.MethodPost, plaintextToken, testCase.formValues); status != testCase.wantStatus {
+				t.Errorf("status = %d, want %d", status, testCase.wantStatus)
+			}
+		})
+	}
+}
+
+func TestRevokedTokensAndSuspendedOwnersAreRefused(t *testing.T) {
+	fixture := newBearerTestFixture(t)
+	revokedToken, revokedPlaintext := fixture.issueToken(t, config.TokenScopeReadWrite, nil)
+	_, livePlaintext := fixture.issueToken(t, config.TokenScopeReadWrite, nil)
+	if err := revokedToken.Revoke(fixture.applicationContext.Database); err != nil {
+		t.Fatalf("revoking the token: %v", err)
+	}
+	if status := fixture.serve(http.MethodGet, revokedPlaintext, nil); status != http.StatusUnauthorized {
+		t.Errorf("revoked token: status = %d, want %d", status, http.StatusUnauthorized)
+	}
+	if status := fixture.serve(http.MethodGet, livePlaintext, nil); status != http.StatusNoContent {
+		t.Fatalf("live token: status = %d, want %d", status, http.StatusNoContent)
+	}
+
+	if err := fixture.tokenOwner.SetSuspended(fixture.applicationContext.Database, true); err != nil {
+		t.Fatalf("suspending the owner: %v", err)
+	}
+	if status := fixture.serve(http.MethodGet, livePlaintext, nil); status != http.StatusForbidden || fixture.servedUserID != "" {
+		t.Errorf("token of a suspended owner: status = %d, served user = %q, want %d and no user", status, fixture.servedUserID, http.StatusForbidden)
+	}
+	if err := fixture.tokenOwner.SetSuspended(fixture.applicationContext.Database, false); err != nil {
+		t.Fatalf("reinstating the owner: %v", err)
+	}
+	if status := fixture.serve(http.MethodGet, livePlaintext, nil); status != http.StatusNoContent {
+		t.Errorf("token of a reinstated owner: status = %d, want %d", status, http.StatusNoContent)
+	}
+}
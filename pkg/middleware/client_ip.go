@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"net/http"
+	"net/netip"
+
+	"github.com/temirov/RSVP/pkg/utils"
+)
+
+// ResolveClientAddress is middleware that works out the address of the client behind each request once, believing
+// X-Forwarded-For only from trustedProxies, and stores it in the request context for utils.ClientIP. Rate limits,
+// lockouts, access logs and token usage records all read it from there.
+func ResolveClientAddress(trustedProxies []netip.Prefix) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+			clientIP := utils.ResolveClientIP(request, trustedProxies)
+			next.ServeHTTP(responseWriter, request.WithContext(utils.WithClientIP(request.Context(), clientIP)))
+		})
+	}
+}
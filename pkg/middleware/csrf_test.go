@@ -0,0 +1,194 @@
+package middleware
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/temirov/GAuss/pkg/session"
+	"github.com/temirov/RSVP/pkg/config"
+)
+
+// forgeryTestFixture holds a handler behind ProtectFromForgery together with a browser session and its token.
+type forgeryTestFixture struct {
+	protectedHandler http.Handler
+	sessionCookies   []*http.Cookie
+	csrfToken        string
+	handlerCalled    bool
+	rejected         bool
+}
+
+func newForgeryTestFixture(t *testing.T) *forgeryTestFixture {
+	t.Helper()
+	session.NewSession([]byte("0123456789abcdef0123456789abcdef"))
+	fixture := &forgeryTestFixture{}
+	applicationContext := &config.ApplicationContext{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	rejectionHandler := http.HandlerFunc(func(responseWriter http.ResponseWriter, _ *http.Request) {
+		fixture.rejected = true
+		responseWriter.WriteHeader(http.StatusForbidden)
+	})
+	fixture.protectedHandler = ProtectFromForgery(applicationContext, rejectionHandler)(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		fixture.handlerCalled = true
+		fixture.csrfToken = CSRFTokenFromContext(request.Context())
+		responseWriter.WriteHeader(http.StatusNoContent)
+	}))
+
+	pageRecorder := fixture.serve(httptest.NewRequest(http.MethodGet, config.WebEvents, nil))
+	if pageRecorder.Code != http.StatusNoContent || fixture.csrfToken == "" {
+		t.Fatalf("GET: status = %d, token = %q; want the page with a token", pageRecorder.Code, fixture.csrfToken)
+	}
+	fixture.sessionCookies = pageRecorder.Result().Cookies()
+	if len(fixture.sessionCookies) == 0 {
+		t.Fatal("GET did not set the CSRF session cookie")
+	}
+	return fixture
+}
+
+// serve runs the request through the protected handler and records whether it got through.
+func (fixture *forgeryTestFixture) serve(request *http.Request) *httptest.ResponseRecorder {
+	fixture.handlerCalled = false
+	fixture.rejected = false
+	responseRecorder := httptest.NewRecorder()
+	fixture.protectedHandler.ServeHTTP(responseRecorder, request)
+	return responseRecorder
+}
+
+// formPost returns a POST to requestPath with the form values and the fixture's session cookies.
+func (fixture *forgeryTestFixture) formPost(requestPath string, formValues url.Values) *http.Request {
+	postRequest := httptest.NewRequest(http.MethodPost, requestPath, strings.NewReader(formValues.Encode()))
+	postRequest.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	for _, sessionCookie := range fixture.sessionCookies {
+		postRequest.AddCookie(sessionCookie)
+	}
+	return postRequest
+}
+
+func TestSessionKeepsItsCSRFToken(t *testing.T) {
+	fixture := newForgeryTestFixture(t)
+	issuedToken := fixture.csrfToken
+	pageRequest := httptest.NewRequest(http.MethodGet, config.WebEvents, nil)
+	for _, sessionCookie := range fixture.sessionCookies {
+		pageRequest.AddCookie(sessionCookie)
+	}
+	fixture.serve(pageRequest)
+	if fixture.csrfToken != issuedToken {
+		t.Errorf("second page token = %q, want the session's token %q", fixture.csrfToken, issuedToken)
+	}
+}
+
+func TestStateChangingRequestsNeedTheSessionToken(t *testing.T) {
+	fixture := newForgeryTestFixture(t)
+	otherSession := newForgeryTestFixture(t)
+
+	testCases := []struct {
+		name         string
+		buildRequest func() *http.Request
+		wantAccepted bool
+	}{
+		{name: "missing token", buildRequest: func() *http.Request {
+			return fixture.formPost(config.WebEvents, url.Values{"title": {"Party"}})
+		}},
+		{name: "wrong token in form", buildRequest: func() *http.Request {
+			return fixture.formPost(config.WebEvents, url.Values{config.CSRFTokenParam: {"forged"}})
+		}},
+		{name: "token of another session", buildRequest: func() *http.Request {
+			return fixture.formPost(config.WebEvents, url.Values{config.CSRFTokenParam: {otherSession.csrfToken}})
+		}},
+		{name: "token without a session", buildRequest: func() *http.Request {
+			postRequest := httptest.NewRequest(http.MethodPost, config.WebEvents, strings.NewReader(url.Values{config.CSRFTokenParam: {fixture.csrfToken}}.Encode()))
+			postRequest.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			return postRequest
+		}},
+		{name: "token in form", wantAccepted: true, buildRequest: func() *http.Request {
+			return fixture.formPost(config.WebEvents, url.Values{config.CSRFTokenParam: {fixture.csrfToken}})
+		}},
+		{name: "token in header", wantAccepted: true, buildRequest: func() *http.Request {
+			headerRequest := fixture.formPost(config.WebEvents, nil)
+			headerRequest.Method = http.MethodDelete
+			headerRequest.Header.Set(config.CSRFHeader, fixture.csrfToken)
+			return headerRequest
+		}},
+		{name: "wrong token in header wins over the form", buildRequest: func() *http.Request {
+			headerRequest := fixture.formPost(config.WebEvents, url.Values{config.CSRFTokenParam: {fixture.csrfToken}})
+			headerRequest.Header.Set(config.CSRFHeader, "forged")
+			return headerRequest
+		}},
+		{name: "PUT without token", buildRequest: func() *http.Request {
+			putRequest := fixture.formPost(config.WebEvents, nil)
+			putRequest.Method = http.MethodPut
+			return putRequest
+		}},
+		{name: "PATCH without token", buildRequest: func() *http.Request {
+			patchRequest := fixture.formPost(config.WebEvents, nil)
+			patchRequest.Method = http.MethodPatch
+			return patchRequest
+		}},
+		{name: "DELETE without token", buildRequest: func() *http.Request {
+			deleteRequest := fixture.formPost(config.WebEvents, nil)
+			deleteRequest.Method = http.MethodDelete
+			return deleteRequest
+		}},
+		{name: "overridden DELETE form without token", buildRequest: func() *http.Request {
+			return fixture.formPost(config.WebEvents, url.Values{config.MethodOverrideParam: {http.MethodDelete}})
+		}},
+		{name: "overridden DELETE form with token", wantAccepted: true, buildRequest: func() *http.Request {
+			return fixture.formPost(config.WebEvents, url.Values{config.MethodOverrideParam: {http.MethodDelete}, config.CSRFTokenParam: {fixture.csrfToken}})
+		}},
+		{name: "HEAD without token", wantAccepted: true, buildRequest: func() *http.Request {
+			return httptest.NewRequest(http.MethodHead, config.WebEvents, nil)
+		}},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			responseRecorder := fixture.serve(testCase.buildRequest())
+			if fixture.handlerCalled != testCase.wantAccepted || fixture.rejected == testCase.wantAccepted {
+				t.Fatalf("accepted = %t, rejected = %t (status %d); want accepted = %t", fixture.handlerCalled, fixture.rejected, responseRecorder.Code, testCase.wantAccepted)
+			}
+		})
+	}
+}
+
+func TestFormStaysReadableAfterTheCheck(t *testing.T) {
+	fixture := newForgeryTestFixture(t)
+	var submittedTitle, methodOverride string
+	fixture.protectedHandler = ProtectFromForgery(&config.ApplicationContext{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))}, http.NotFoundHandler())(
+		http.HandlerFunc(func(_ http.ResponseWriter, request *http.Request) {
+			submittedTitle = request.FormValue("title")
+			methodOverride = request.FormValue(config.MethodOverrideParam)
+		}))
+	fixture.serve(fixture.formPost(config.WebEvents, url.Values{
+		"title": {"Party"}, config.MethodOverrideParam: {http.MethodDelete}, config.CSRFTokenParam: {fixture.csrfToken},
+	}))
+	if submittedTitle != "Party" || methodOverride != http.MethodDelete {
+		t.Errorf("handler read title %q and _method %q, want the submitted form", submittedTitle, methodOverride)
+	}
+}
+
+func TestBearerRequestsAndDevelopmentSignInAreNotChecked(t *testing.T) {
+	fixture := newForgeryTestFixture(t)
+
+	bearerRequest := httptest.NewRequest(http.MethodPost, config.WebRSVPs, strings.NewReader("name=Guest"))
+	bearerRequest.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	bearerRequest.Header.Set(config.AuthorizationHeader, config.BearerSchemePrefix+"rsvp_token")
+	fixture.serve(bearerRequest)
+	if !fixture.handlerCalled {
+		t.Error("a request with bearer credentials was checked for a CSRF token")
+	}
+
+	fixture.serve(fixture.formPost(config.WebAuthDev, url.Values{"email": {"dev@example.com"}}))
+	if !fixture.handlerCalled {
+		t.Error("the development sign-in was checked for a CSRF token")
+	}
+
+	// Another scheme in the Authorization header is not bearer credentials and gets no exemption.
+	basicRequest := fixture.formPost(config.WebRSVPs, nil)
+	basicRequest.Header.Set(config.AuthorizationHeader, "Basic dXNlcjpwYXNz")
+	fixture.serve(basicRequest)
+	if !fixture.rejected {
+		t.Error("a request with basic credentials and no CSRF token was accepted")
+	}
+}
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/temirov/RSVP/pkg/metrics"
+)
+
+// RecordMetrics is middleware that counts every request and its duration by the mux pattern that serves it, rather
+// than by its path, so that invitation codes and record IDs do not each become a time series.
+func RecordMetrics(applicationMetrics *metrics.Metrics, mux *http.ServeMux) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if applicationMetrics == nil {
+			return next
+		}
+		return http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+			requestStart := time.Now()
+			_, routePattern := mux.Handler(request)
+			recordingWriter := &statusRecordingWriter{ResponseWriter: responseWriter, statusCode: http.StatusOK}
+
+			next.ServeHTTP(recordingWriter, request)
+
+			applicationMetrics.ObserveHTTPRequest(routePattern, request.Method, recordingWriter.statusCode, time.Since(requestStart))
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/logging"
+	"github.com/temirov/RSVP/pkg/utils"
+)
+
+// LogRequests is middleware that gives every request an ID and a logger tagged with it, and logs the method, path,
+// status, size, duration, client address and user of the request once it has been served. A valid X-Request-ID
+// from the client or a proxy is kept so that its logs and ours line up; otherwise an ID is generated. Either way it is
+// returned in the X-Request-ID response header. Server errors are logged at error level, everything else at info,
+// except health probes: they arrive every few seconds, so they are logged at debug level, or warn when they fail.
+func LogRequests(logger *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+			requestStart := time.Now()
+			requestID := request.Header.Get(config.RequestIDHeader)
+			if !isValidRequestID(requestID) {
+				requestID = generateRequestID()
+			}
+			responseWriter.Header().Set(config.RequestIDHeader, requestID)
+			requestContext := logging.WithRequest(request.Context(), logger, requestID)
+			recordingWriter := &statusRecordingWriter{ResponseWriter: responseWriter, statusCode: http.StatusOK}
+
+			next.ServeHTTP(recordingWriter, request.WithContext(requestContext))
+
+			logLevel := slog.LevelInfo
+			switch {
+			case isHealthProbe(request) && recordingWriter.statusCode == http.StatusOK:
+				logLevel = slog.LevelDebug
+			case isHealthProbe(request):
+				logLevel = slog.LevelWarn
+			case recordingWriter.statusCode >= http.StatusInternalServerError:
+				logLevel = slog.LevelError
+			}
+			requestAttributes := []slog.Attr{
+				slog.String("request_id", requestID),
+				slog.String("method", request.Method),
+				slog.String("path", request.URL.Path),
+				slog.Int("status", recordingWriter.statusCode),
+				slog.Int64("bytes", recordingWriter.bytesWritten),
+				slog.Duration("duration", time.Since(requestStart)),
+				slog.String("client_ip", utils.ClientIP(request)),
+			}
+			if userID := logging.UserID(requestContext); userID != "" {
+				requestAttributes = append(requestAttributes, slog.String("user_id", userID))
+			}
+			if traceID := logging.TraceID(requestContext); traceID != "" {
+				requestAttributes = append(requestAttributes, slog.String("trace_id", traceID))
+			}
+			logger.LogAttrs(requestContext, logLevel, "request", requestAttributes...)
+		})
+	}
+}
+
+// statusRecordingWriter remembers the status code and counts the body bytes of a response.
+type statusRecordingWriter struct {
+	http.ResponseWriter
+	statusCode    int
+	headerWritten bool
+	bytesWritten  int64
+}
+
+// WriteHeader records the first status code written.
+func (writer *statusRecordingWriter) WriteHeader(statusCode int) {
+	if !writer.headerWritten {
+		writer.statusCode = statusCode
+		writer.headerWritten = true
+	}
+	writer.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write counts the body bytes written.
+func (writer *statusRecordingWriter) Write(body []byte) (int, error) {
+	writer.headerWritten = true
+	writtenCount, writeError := writer.ResponseWriter.Write(body)
+	writer.bytesWritten += int64(writtenCount)
+	return writtenCount, writeError
+}
+
+// Flush passes flushes through, which the live update streams rely on.
+func (writer *statusRecordingWriter) Flush() {
+	if flusher, isFlusher := writer.ResponseWriter.(http.Flusher); isFlusher {
+		flusher.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (writer *statusRecordingWriter) Unwrap() http.ResponseWriter {
+	return writer.ResponseWriter
+}
+
+// isHealthProbe reports whether the request is for a health endpoint.
+func isHealthProbe(request *http.Request) bool {
+	return request.URL.Path == config.WebHealthz || request.URL.Path == config.WebReadyz
+}
+
+// isValidRequestID reports whether a request ID received from a client is short and made only of letters, digits
+// and "-", "_", ".", ":", so it cannot break log lines or headers.
+func isValidRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > config.MaxRequestIDLength {
+		return false
+	}
+	for _, character := range requestID {
+		isAllowed := (character >= 'a' && character <= 'z') || (character >= 'A' && character <= 'Z') ||
+			(character >= '0' && character <= '9') || character == '-' || character == '_' || character == '.' || character == ':'
+		if !isAllowed {
+			return false
+		}
+	}
+	return true
+}
+
+// generateRequestID returns a random hexadecimal request ID.
+func generateRequestID() string {
+	idBytes := make([]byte, config.RequestIDLength)
+	// crypto/rand.Read does not fail on supported platforms; a zero ID would still be a usable label.
+	_, _ = rand.Read(idBytes)
+	return hex.EncodeToString(idBytes)
+}
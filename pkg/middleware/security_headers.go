@@ -0,0 +1,160 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/logging"
+)
+
+// ContextKeyCSPNonce is the key used to store the response's Content Security Policy nonce in the request context.
+const ContextKeyCSPNonce contextKey = "csp_nonce"
+
+// cspNoncePlaceholder marks where each response's nonce goes in the prepared policy.
+const cspNoncePlaceholder = "{nonce}"
+
+// cspDirective is one directive of the Content Security Policy with its space-separated sources.
+type cspDirective struct {
+	name    string
+	sources string
+}
+
+// defaultPolicyDirectives is the Content Security Policy before relaxations. Scripts and inline style blocks run
+// only from this site, with the response's nonce, or from the CDNs and Google Analytics the templates use. Style
+// attributes are blocked, since nonces cannot cover them; EasyMDE loads its icon font from bootstrapcdn.
+var defaultPolicyDirectives = []cspDirective{
+	{name: "default-src", sources: "'self'"},
+	{name: "script-src", sources: "'self' 'nonce-" + cspNoncePlaceholder + "' https://cdn.jsdelivr.net https://www.googletagmanager.com"},
+	{name: "style-src", sources: "'self' 'nonce-" + cspNoncePlaceholder + "' https://cdn.jsdelivr.net https://fonts.googleapis.com https://maxcdn.bootstrapcdn.com"},
+	{name: "font-src", sources: "'self' data: https://fonts.gstatic.com https://cdn.jsdelivr.net https://maxcdn.bootstrapcdn.com"},
+	{name: "img-src", sources: "'self' data: https:"},
+	{name: "connect-src", sources: "'self' https://*.google-analytics.com https://*.analytics.google.com https://*.googletagmanager.com"},
+	{name: "object-src", sources: "'none'"},
+	{name: "base-uri", sources: "'self'"},
+	{name: "form-action", sources: "'self'"},
+}
+
+// inlineStyleSource is what AllowInlineStyles puts in place of the nonce in style-src. Browsers ignore
+// 'unsafe-inline' in a directive that also lists a nonce, so the nonce has to go.
+const inlineStyleSource = "'unsafe-inline'"
+
+// CSPNonceFromContext returns the nonce inline scripts and style blocks must carry, or an empty string outside SecurityHeaders.
+func CSPNonceFromContext(requestContext context.Context) string {
+	cspNonce, _ := requestContext.Value(ContextKeyCSPNonce).(string)
+	return cspNonce
+}
+
+// SecurityHeaders is middleware that sends the security headers with every response: a Content Security Policy
+// with a fresh nonce, which it also stores in the request context for the templates, the matching X-Frame-Options,
+// Strict-Transport-Security when the site is served over HTTPS, and the nosniff, referrer, permissions and opener
+// policies.
+func SecurityHeaders(settings config.SecurityHeadersConfig) func(http.Handler) http.Handler {
+	preparedPolicy := buildContentSecurityPolicy(settings)
+	policyHeader := config.CSPHeader
+	if settings.ReportOnly {
+		policyHeader = config.CSPReportOnlyHeader
+	}
+	frameOptions := frameOptionsFor(settings.FrameAncestors)
+	var hstsValue string
+	if settings.HTTPS && settings.HSTSMaxAge > 0 {
+		hstsValue = "max-age=" + strconv.FormatInt(int64(settings.HSTSMaxAge.Seconds()), 10)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+			cspNonce, generationError := generateCSPNonce()
+			if generationError != nil {
+				logging.FromContext(request.Context()).Error("Generating a CSP nonce failed", "path", request.URL.Path, "error", generationError)
+				http.Error(responseWriter, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+			responseHeaders := responseWriter.Header()
+			responseHeaders.Set(policyHeader, strings.ReplaceAll(preparedPolicy, cspNoncePlaceholder, cspNonce))
+			responseHeaders.Set(config.ContentTypeOptionsHeader, "nosniff")
+			responseHeaders.Set(config.ReferrerPolicyHeader, settings.ReferrerPolicy)
+			responseHeaders.Set(config.PermissionsPolicyHeader, config.DefaultPermissionsPolicy)
+			responseHeaders.Set(config.CrossOriginOpenerPolicyHeader, config.DefaultCrossOriginOpenerPolicy)
+			if frameOptions != "" {
+				responseHeaders.Set(config.FrameOptionsHeader, frameOptions)
+			}
+			if hstsValue != "" {
+				responseHeaders.Set(config.HSTSHeader, hstsValue)
+			}
+			next.ServeHTTP(responseWriter, request.WithContext(context.WithValue(request.Context(), ContextKeyCSPNonce, cspNonce)))
+		})
+	}
+}
+
+// buildContentSecurityPolicy joins the default directives, the configured extra sources and the frame ancestors
+// into a policy that still contains the nonce placeholder.
+func buildContentSecurityPolicy(settings config.SecurityHeadersConfig) string {
+	policyDirectives := append([]cspDirective(nil), defaultPolicyDirectives...)
+	for directiveIndex := range policyDirectives {
+		if settings.AllowInlineStyles && policyDirectives[directiveIndex].name == "style-src" {
+			policyDirectives[directiveIndex].sources = strings.Replace(policyDirectives[directiveIndex].sources, "'nonce-"+cspNoncePlaceholder+"'", inlineStyleSource, 1)
+		}
+		if extraSources := settings.ExtraSources[policyDirectives[directiveIndex].name]; len(extraSources) > 0 {
+			policyDirectives[directiveIndex].sources += " " + strings.Join(extraSources, " ")
+		}
+	}
+	extraDirectiveNames := make([]string, 0, len(settings.ExtraSources))
+	for directiveName := range settings.ExtraSources {
+		if !hasDirective(policyDirectives, directiveName) {
+			extraDirectiveNames = append(extraDirectiveNames, directiveName)
+		}
+	}
+	sort.Strings(extraDirectiveNames)
+	for _, directiveName := range extraDirectiveNames {
+		policyDirectives = append(policyDirectives, cspDirective{name: directiveName, sources: strings.Join(settings.ExtraSources[directiveName], " ")})
+	}
+	policyDirectives = append(policyDirectives, cspDirective{name: config.CSPFrameAncestorsDirective, sources: strings.Join(settings.FrameAncestors, " ")})
+	if settings.ReportURI != "" {
+		policyDirectives = append(policyDirectives, cspDirective{name: "report-uri", sources: settings.ReportURI})
+	}
+
+	policyParts := make([]string, 0, len(policyDirectives))
+	for _, policyDirective := range policyDirectives {
+		policyParts = append(policyParts, policyDirective.name+" "+policyDirective.sources)
+	}
+	return strings.Join(policyParts, "; ")
+}
+
+// hasDirective reports whether the policy already contains the named directive.
+func hasDirective(policyDirectives []cspDirective, directiveName string) bool {
+	for _, policyDirective := range policyDirectives {
+		if policyDirective.name == directiveName {
+			return true
+		}
+	}
+	return false
+}
+
+// frameOptionsFor returns the X-Frame-Options value matching the frame ancestors for browsers without CSP support,
+// or an empty string when the ancestors cannot be expressed that way.
+func frameOptionsFor(frameAncestors []string) string {
+	if len(frameAncestors) != 1 {
+		return ""
+	}
+	switch frameAncestors[0] {
+	case config.CSPFrameAncestorsNone:
+		return "DENY"
+	case config.CSPFrameAncestorsSelf:
+		return "SAMEORIGIN"
+	}
+	return ""
+}
+
+// generateCSPNonce returns a random, base64-encoded nonce.
+func generateCSPNonce() (string, error) {
+	nonceBytes := make([]byte, config.CSPNonceLength)
+	if _, readError := rand.Read(nonceBytes); readError != nil {
+		return "", readError
+	}
+	return base64.RawURLEncoding.EncodeToString(nonceBytes), nil
+}
@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	rsvp "github.com/temirov/RSVP"
+	"github.com/temirov/RSVP/pkg/assets"
+	"github.com/temirov/RSVP/pkg/config"
+)
+
+// styleSources returns the sources of the style-src directive of the policy sent for settings, and the nonce the
+// request context was given.
+func styleSources(t *testing.T, settings config.SecurityHeadersConfig) (string, string) {
+	t.Helper()
+	var requestNonce string
+	policyHandler := SecurityHeaders(settings)(http.HandlerFunc(func(_ http.ResponseWriter, request *http.Request) {
+		requestNonce = CSPNonceFromContext(request.Context())
+	}))
+	responseRecorder := httptest.NewRecorder()
+	policyHandler.ServeHTTP(responseRecorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	for _, policyDirective := range strings.Split(responseRecorder.Header().Get(config.CSPHeader), ";") {
+		if directiveSources, found := strings.CutPrefix(strings.TrimSpace(policyDirective), "style-src "); found {
+			return directiveSources, requestNonce
+		}
+	}
+	t.Fatalf("policy %q has no style-src directive", responseRecorder.Header().Get(config.CSPHeader))
+	return "", ""
+}
+
+func TestStylesNeedTheNonceByDefault(t *testing.T) {
+	directiveSources, requestNonce := styleSources(t, config.SecurityHeadersConfig{FrameAncestors: []string{config.CSPFrameAncestorsNone}})
+	if requestNonce == "" || !strings.Contains(directiveSources, "'nonce-"+requestNonce+"'") {
+		t.Errorf("style-src %q does not carry the response's nonce %q", directiveSources, requestNonce)
+	}
+	if strings.Contains(directiveSources, inlineStyleSource) {
+		t.Errorf("style-src %q allows inline styles by default", directiveSources)
+	}
+}
+
+func TestAllowInlineStylesDropsTheNonce(t *testing.T) {
+	directiveSources, _ := styleSources(t, config.SecurityHeadersConfig{FrameAncestors: []string{config.CSPFrameAncestorsNone}, AllowInlineStyles: true})
+	if !strings.Contains(directiveSources, inlineStyleSource) {
+		t.Errorf("style-src %q does not allow inline styles", directiveSources)
+	}
+	if strings.Contains(directiveSources, "'nonce-") {
+		t.Errorf("style-src %q keeps a nonce, which makes browsers ignore %s", directiveSources, inlineStyleSource)
+	}
+}
+
+// TestTemplatesNeedNoInlineStyles keeps the built-in templates working under the default policy: every <style> and
+// <script> block carries the nonce, and no element has a style attribute.
+func TestTemplatesNeedNoInlineStyles(t *testing.T) {
+	templateFiles, err := assets.Templates(rsvp.EmbeddedFiles)
+	if err != nil {
+		t.Fatalf("opening the templates: %v", err)
+	}
+	styleAttributePattern := regexp.MustCompile(`\sstyle\s*=`)
+	unnoncedBlockPattern := regexp.MustCompile(`<(style|script)(\s[^>]*)?>`)
+	walkError := fs.WalkDir(templateFiles, ".", func(templatePath string, entry fs.DirEntry, walkError error) error {
+		if walkError != nil || entry.IsDir() {
+			return walkError
+		}
+		templateBytes, err := fs.ReadFile(templateFiles, templatePath)
+		if err != nil {
+			return err
+		}
+		templateText := string(templateBytes)
+		if styleAttributePattern.MatchString(templateText) {
+			t.Errorf("%s has a style attribute, which the Content Security Policy blocks", templatePath)
+		}
+		for _, blockTag := range unnoncedBlockPattern.FindAllString(templateText, -1) {
+			if !strings.Contains(blockTag, "nonce=") && !strings.Contains(blockTag, "src=") {
+				t.Errorf("%s has %s without the CSP nonce", templatePath, blockTag)
+			}
+		}
+		return nil
+	})
+	if walkError != nil {
+		t.Fatalf("reading the templates: %v", walkError)
+	}
+}
@@ -4,12 +4,15 @@ package middleware
 import (
 	"context"
 	"net/http"
+	"net/url"
 
 	gconstants "github.com/temirov/GAuss/pkg/constants"
 	"github.com/temirov/GAuss/pkg/session"
 	"github.com/temirov/RSVP/models"
 	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/logging"
 	"github.com/temirov/RSVP/pkg/utils"
+	"gorm.io/gorm"
 )
 
 // contextKey is a custom type used for keys in context.Context to avoid collisions.
@@ -19,17 +22,18 @@ type contextKey string
 const ContextKeyUser contextKey = "user"
 
 // AddUserToContext is middleware that retrieves user information based on the session email,
-// performs an Upsert operation (find or create) in the database, and adds the resulting
-// *models.User object to the request's context. If the user cannot be determined or upserted
+// performs an Upsert operation (find or create) in the database, binds any pending co-host and organization
+// invitations addressed to that email, and adds the resulting *models.User object to the request's context. If the user cannot be determined or upserted
 // after successful authentication (which implies a server issue), it stops the request chain
-// and returns an error.
-func AddUserToContext(applicationContext *config.ApplicationContext) func(http.Handler) http.Handler {
+// and returns an error. Addresses the sign-in rules do not admit, and suspended users, are signed out and sent
+// back to the sign-in page with an explanation; no account is created for them.
+func AddUserToContext(applicationContext *config.ApplicationContext, accessConfig config.AccessConfig, adminEmails []string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+			requestLogger := logging.FromContext(request.Context())
 			sessionInstance, sessionError := session.Store().Get(request, gconstants.SessionName)
 			if sessionError != nil {
-				applicationContext.Logger.Printf("ERROR: Session retrieval failed in AddUserToContext for %s: %v", request.URL.Path, sessionError)
-				utils.HandleError(responseWriter, sessionError, utils.ServerError, applicationContext.Logger, "Failed to process user session.")
+				utils.HandleError(responseWriter, request, sessionError, utils.ServerError, "Failed to process user session.")
 				return
 			}
 
@@ -38,18 +42,60 @@ func AddUserToContext(applicationContext *config.ApplicationContext) func(http.H
 			userPicture, _ := sessionInstance.Values[gconstants.SessionKeyUserPicture].(string)
 
 			if !emailOk || userEmail == "" {
-				applicationContext.Logger.Printf("ERROR: User email missing from session after authentication for %s", request.URL.Path)
-				utils.HandleError(responseWriter, nil, utils.AuthenticationError, applicationContext.Logger, utils.ErrMsgUnauthorized)
+				requestLogger.Error("User email missing from session after authentication", "path", request.URL.Path)
+				utils.HandleError(responseWriter, request, nil, utils.AuthenticationError, utils.ErrMsgUnauthorized)
 				return
 			}
 
-			user, upsertErr := models.UpsertUser(applicationContext.Database, userEmail, userName, userPicture)
+			isAllowed, accessError := signInAllowed(applicationContext.Database.WithContext(request.Context()), accessConfig, adminEmails, userEmail)
+			if accessError != nil {
+				utils.HandleError(responseWriter, request, accessError, utils.DatabaseError, "Failed to check sign-in permissions.")
+				return
+			}
+			if !isAllowed {
+				requestLogger.Warn("Sign-in refused by the sign-in rules", "email", userEmail)
+				endSession(responseWriter, request, applicationContext, config.ErrMsgSignInNotAllowed)
+				return
+			}
+
+			user, upsertErr := models.UpsertUser(applicationContext.Database.WithContext(request.Context()), userEmail, userName, userPicture)
 			if upsertErr != nil {
-				applicationContext.Logger.Printf("ERROR: Failed to upsert user (%s) in AddUserToContext middleware for %s: %v", userEmail, request.URL.Path, upsertErr)
-				utils.HandleError(responseWriter, upsertErr, utils.ServerError, applicationContext.Logger, "Failed to retrieve or create user profile.")
+				utils.HandleError(responseWriter, request, upsertErr, utils.ServerError, "Failed to retrieve or create user profile.")
 				return
 			}
 
+			logging.SetUserID(request.Context(), user.ID)
+			requestLogger = logging.FromContext(request.Context())
+			if user.IsSuspended() {
+				requestLogger.Warn("Suspended user refused", "path", request.URL.Path)
+				endSession(responseWriter, request, applicationContext, config.ErrMsgAccountSuspended)
+				return
+			}
+			if recordedEmail, _ := sessionInstance.Values[config.SessionKeySignInRecorded].(string); recordedEmail != userEmail {
+				if recordError := user.RecordSignIn(applicationContext.Database.WithContext(request.Context())); recordError != nil {
+					requestLogger.Warn("Failed to record the sign-in", "error", recordError)
+				} else {
+					sessionInstance.Values[config.SessionKeySignInRecorded] = userEmail
+					if saveError := sessionInstance.Save(request, responseWriter); saveError != nil {
+						requestLogger.Warn("Failed to save the session", "error", saveError)
+					}
+				}
+			}
+
+			acceptedCount, acceptError := models.AcceptPendingInvitations(applicationContext.Database.WithContext(request.Context()), user)
+			if acceptError != nil {
+				requestLogger.Warn("Failed to accept pending co-host invitations", "error", acceptError)
+			} else if acceptedCount > 0 {
+				requestLogger.Info("Accepted pending co-host invitations", "count", acceptedCount)
+			}
+
+			acceptedOrgCount, acceptOrgError := models.AcceptPendingOrganizationInvitations(applicationContext.Database.WithContext(request.Context()), user)
+			if acceptOrgError != nil {
+				requestLogger.Warn("Failed to accept pending organization invitations", "error", acceptOrgError)
+			} else if acceptedOrgCount > 0 {
+				requestLogger.Info("Joined organizations through pending invitations", "count", acceptedOrgCount)
+			}
+
 			ctx := context.WithValue(request.Context(), ContextKeyUser, user)
 			requestWithUser := request.WithContext(ctx)
 
@@ -57,3 +103,26 @@ func AddUserToContext(applicationContext *config.ApplicationContext) func(http.H
 		})
 	}
 }
+
+// signInAllowed reports whether the sign-in rules admit the email address. Administrators are always admitted.
+func signInAllowed(databaseConnection *gorm.DB, accessConfig config.AccessConfig, adminEmails []string, emailAddress string) (bool, error) {
+	normalizedEmail := models.NormalizeMemberEmail(emailAddress)
+	if !accessConfig.IsRestricted() || accessConfig.IsListed(normalizedEmail) || isAdminEmail(normalizedEmail, adminEmails) {
+		return true, nil
+	}
+	if !accessConfig.InviteOnly {
+		return false, nil
+	}
+	return models.HasAccountOrInvitation(databaseConnection, normalizedEmail)
+}
+
+// endSession signs the browser out and returns it to the sign-in page, which shows the message.
+func endSession(responseWriter http.ResponseWriter, request *http.Request, applicationContext *config.ApplicationContext, message string) {
+	if sessionInstance, sessionError := session.Store().Get(request, gconstants.SessionName); sessionError == nil {
+		sessionInstance.Options.MaxAge = -1
+		if saveError := sessionInstance.Save(request, responseWriter); saveError != nil {
+			logging.FromContext(request.Context()).Warn("Failed to clear the session", "path", request.URL.Path, "error", saveError)
+		}
+	}
+	http.Redirect(responseWriter, request, config.WebLogin+"?"+url.Values{config.ErrorQueryParam: {message}}.Encode(), http.StatusFound)
+}
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	gconstants "github.com/temirov/GAuss/pkg/constants"
+	"github.com/temirov/GAuss/pkg/session"
+	"github.com/temirov/RSVP/models"
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/logging"
+	"github.com/temirov/RSVP/pkg/utils"
+)
+
+// ContextKeyWorkspace is the key used to store the *models.Organization of the active workspace.
+// It is absent when the user works in their personal workspace.
+const ContextKeyWorkspace contextKey = "workspace"
+
+// WorkspaceFromContext returns the organization whose workspace is active, or nil for the personal workspace.
+func WorkspaceFromContext(requestContext context.Context) *models.Organization {
+	activeOrganization, _ := requestContext.Value(ContextKeyWorkspace).(*models.Organization)
+	return activeOrganization
+}
+
+// WorkspaceIDFromContext returns the ID of the active organization, or nil for the personal workspace,
+// in the form stored on events and venues.
+func WorkspaceIDFromContext(requestContext context.Context) *string {
+	if activeOrganization := WorkspaceFromContext(requestContext); activeOrganization != nil {
+		return &activeOrganization.ID
+	}
+	return nil
+}
+
+// ResolveWorkspace is middleware that determines which workspace the request acts in. API clients may pass
+// the organization ID in the workspace_id query parameter; browsers use the workspace chosen with the header
+// switcher, which is kept in the session. A workspace the user is not an accepted member of falls back to
+// the personal workspace. It must run after the user has been placed in the request context.
+func ResolveWorkspace(applicationContext *config.ApplicationContext) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+			currentUser, _ := request.Context().Value(ContextKeyUser).(*models.User)
+			requestedWorkspaceID := request.URL.Query().Get(config.WorkspaceIDParam)
+			if requestedWorkspaceID == "" {
+				if sessionInstance, sessionError := session.Store().Get(request, gconstants.SessionName); sessionError == nil {
+					requestedWorkspaceID, _ = sessionInstance.Values[config.SessionKeyWorkspaceID].(string)
+				}
+			}
+			if currentUser == nil || requestedWorkspaceID == "" {
+				next.ServeHTTP(responseWriter, request)
+				return
+			}
+
+			organizationRole, roleError := models.OrganizationRoleForUser(applicationContext.Database.WithContext(request.Context()), requestedWorkspaceID, currentUser.ID)
+			if roleError != nil {
+				utils.HandleError(responseWriter, request, roleError, utils.DatabaseError, "Failed to resolve the active workspace.")
+				return
+			}
+			if organizationRole == "" {
+				logging.FromContext(request.Context()).Warn("User is not a member of the requested workspace; using the personal workspace", "workspace_id", requestedWorkspaceID, "path", request.URL.Path)
+				next.ServeHTTP(responseWriter, request)
+				return
+			}
+			var activeOrganization models.Organization
+			if findError := activeOrganization.FindByID(applicationContext.Database.WithContext(request.Context()), requestedWorkspaceID); findError != nil {
+				utils.HandleError(responseWriter, request, findError, utils.DatabaseError, "Failed to resolve the active workspace.")
+				return
+			}
+			ctx := context.WithValue(request.Context(), ContextKeyWorkspace, &activeOrganization)
+			next.ServeHTTP(responseWriter, request.WithContext(ctx))
+		})
+	}
+}
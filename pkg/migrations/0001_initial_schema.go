@@ -0,0 +1,80 @@
+package migrations
+
+import (
+	"time"
+
+	"github.com/temirov/RSVP/pkg/config"
+	"gorm.io/gorm"
+)
+
+// BaseModelV1 mirrors models.BaseModel as of the initial schema.
+// It is exported only because GORM ignores the fields of unexported embedded structs.
+type BaseModelV1 struct {
+	ID        string `gorm:"primaryKey;type:varchar(8);index"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+type userV1 struct {
+	BaseModelV1
+	Email   string    `gorm:"uniqueIndex;size:255;not null"`
+	Name    string    `gorm:"size:255"`
+	Picture string    `gorm:"size:512"`
+	Events  []eventV1 `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+func (userV1) TableName() string { return config.TableUsers }
+
+type venueV1 struct {
+	BaseModelV1
+	UserID      string `gorm:"not null;index"`
+	Name        string `gorm:"not null"`
+	Address     string
+	Capacity    int
+	Website     string
+	Phone       string
+	Email       string
+	Description string
+	Events      []eventV1 `gorm:"foreignKey:VenueID"`
+}
+
+func (venueV1) TableName() string { return config.TableVenues }
+
+type eventV1 struct {
+	BaseModelV1
+	Title       string `gorm:"not null"`
+	Description string
+	StartTime   time.Time `gorm:"not null"`
+	EndTime     time.Time `gorm:"not null"`
+	UserID      string    `gorm:"not null;index"`
+	VenueID     *string   `gorm:"type:varchar(8);index"`
+	RSVPs       []rsvpV1  `gorm:"foreignKey:EventID"`
+	User        userV1    `gorm:"foreignKey:UserID"`
+	Venue       *venueV1  `gorm:"foreignKey:VenueID;references:id"`
+}
+
+func (eventV1) TableName() string { return config.TableEvents }
+
+type rsvpV1 struct {
+	BaseModelV1
+	Name        string `gorm:"column:name"`
+	Response    string `gorm:"column:response"`
+	ExtraGuests int    `gorm:"column:extra_guests;default:0"`
+	EventID     string `gorm:"type:varchar(8);not null;index"`
+}
+
+func (rsvpV1) TableName() string { return config.TableRSVPs }
+
+// initialSchemaMigration creates the users, venues, events and rsvps tables.
+// Databases created before versioned migrations already have these tables; AutoMigrate leaves them as they are.
+var initialSchemaMigration = Migration{
+	Version: 1,
+	Name:    "initial_schema",
+	Up: func(databaseTransaction *gorm.DB) error {
+		return databaseTransaction.AutoMigrate(&userV1{}, &venueV1{}, &eventV1{}, &rsvpV1{})
+	},
+	Down: func(databaseTransaction *gorm.DB) error {
+		return databaseTransaction.Migrator().DropTable(&rsvpV1{}, &eventV1{}, &venueV1{}, &userV1{})
+	},
+}
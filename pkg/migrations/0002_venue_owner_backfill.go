@@ -0,0 +1,63 @@
+package migrations
+
+import (
+	"github.com/temirov/RSVP/pkg/config"
+	"gorm.io/gorm"
+)
+
+// venueOwnerBackfillMigration assigns an owner to legacy venues created before venues had a user_id.
+// A venue referenced by an event inherits that event's owner; an unreferenced venue is assigned to the first user.
+// The backfill only fills empty values, so Down has nothing to undo.
+var venueOwnerBackfillMigration = Migration{
+	Version: 2,
+	Name:    "venue_owner_backfill",
+	Up: func(databaseTransaction *gorm.DB) error {
+		var venueIDsMissingOwner []string
+		if err := databaseTransaction.Table(config.TableVenues).
+			Where("user_id IS NULL OR user_id = ?", "").
+			Pluck("id", &venueIDsMissingOwner).Error; err != nil {
+			return err
+		}
+
+		var fallbackOwnerID string
+		for _, venueID := range venueIDsMissingOwner {
+			var referencingOwnerIDs []string
+			if err := databaseTransaction.Table(config.TableEvents).
+				Where("venue_id = ? AND user_id <> ?", venueID, "").
+				Limit(1).
+				Pluck("user_id", &referencingOwnerIDs).Error; err != nil {
+				return err
+			}
+
+			var ownerID string
+			if len(referencingOwnerIDs) > 0 {
+				ownerID = referencingOwnerIDs[0]
+			} else {
+				if fallbackOwnerID == "" {
+					var firstUserIDs []string
+					if err := databaseTransaction.Table(config.TableUsers).
+						Order("id").
+						Limit(1).
+						Pluck("id", &firstUserIDs).Error; err != nil {
+						return err
+					}
+					if len(firstUserIDs) == 0 {
+						return gorm.ErrRecordNotFound
+					}
+					fallbackOwnerID = firstUserIDs[0]
+				}
+				ownerID = fallbackOwnerID
+			}
+
+			if err := databaseTransaction.Table(config.TableVenues).
+				Where("id = ?", venueID).
+				Update("user_id", ownerID).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	Down: func(databaseTransaction *gorm.DB) error {
+		return nil
+	},
+}
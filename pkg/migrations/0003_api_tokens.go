@@ -0,0 +1,36 @@
+package migrations
+
+import (
+	"time"
+
+	"github.com/temirov/RSVP/pkg/config"
+	"gorm.io/gorm"
+)
+
+type apiTokenV3 struct {
+	BaseModelV1
+	UserID        string  `gorm:"type:varchar(8);not null;index"`
+	Name          string  `gorm:"size:100;not null"`
+	TokenHash     string  `gorm:"size:64;not null;uniqueIndex"`
+	DisplayPrefix string  `gorm:"size:16"`
+	Scope         string  `gorm:"size:20;not null"`
+	EventID       *string `gorm:"type:varchar(8);index"`
+	LastUsedAt    *time.Time
+	LastUsedIP    string `gorm:"size:64"`
+	RevokedAt     *time.Time
+	Event         *eventV1 `gorm:"foreignKey:EventID;references:id"`
+}
+
+func (apiTokenV3) TableName() string { return config.TableTokens }
+
+// apiTokensMigration creates the table holding hashed personal API tokens.
+var apiTokensMigration = Migration{
+	Version: 3,
+	Name:    "api_tokens",
+	Up: func(databaseTransaction *gorm.DB) error {
+		return databaseTransaction.AutoMigrate(&apiTokenV3{})
+	},
+	Down: func(databaseTransaction *gorm.DB) error {
+		return databaseTransaction.Migrator().DropTable(&apiTokenV3{})
+	},
+}
@@ -0,0 +1,49 @@
+package migrations
+
+import (
+	"time"
+
+	"github.com/temirov/RSVP/pkg/config"
+	"gorm.io/gorm"
+)
+
+type rsvpViewTrackingV4 struct {
+	FirstViewedAt *time.Time `gorm:"column:first_viewed_at"`
+	LastViewedAt  *time.Time `gorm:"column:last_viewed_at"`
+	ViewCount     int        `gorm:"column:view_count;default:0"`
+	RespondedAt   *time.Time `gorm:"column:responded_at"`
+}
+
+func (rsvpViewTrackingV4) TableName() string { return config.TableRSVPs }
+
+var rsvpViewTrackingColumns = []string{"FirstViewedAt", "LastViewedAt", "ViewCount", "RespondedAt"}
+
+// rsvpViewTrackingMigration adds the invitation open and response time columns to rsvps.
+var rsvpViewTrackingMigration = Migration{
+	Version: 4,
+	Name:    "rsvp_view_tracking",
+	Up: func(databaseTransaction *gorm.DB) error {
+		schemaMigrator := databaseTransaction.Migrator()
+		for _, columnField := range rsvpViewTrackingColumns {
+			if schemaMigrator.HasColumn(&rsvpViewTrackingV4{}, columnField) {
+				continue
+			}
+			if err := schemaMigrator.AddColumn(&rsvpViewTrackingV4{}, columnField); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	Down: func(databaseTransaction *gorm.DB) error {
+		schemaMigrator := databaseTransaction.Migrator()
+		for _, columnField := range rsvpViewTrackingColumns {
+			if !schemaMigrator.HasColumn(&rsvpViewTrackingV4{}, columnField) {
+				continue
+			}
+			if err := schemaMigrator.DropColumn(&rsvpViewTrackingV4{}, columnField); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	"time"
+
+	"github.com/temirov/RSVP/pkg/config"
+	"gorm.io/gorm"
+)
+
+type eventMembershipV5 struct {
+	BaseModelV1
+	EventID         string  `gorm:"type:varchar(8);not null;index;uniqueIndex:idx_event_memberships_event_email"`
+	UserID          *string `gorm:"type:varchar(8);index"`
+	InvitedEmail    string  `gorm:"size:255;not null;index;uniqueIndex:idx_event_memberships_event_email"`
+	Role            string  `gorm:"size:20;not null"`
+	InvitedByUserID string  `gorm:"type:varchar(8);not null"`
+	AcceptedAt      *time.Time
+	Event           eventV1 `gorm:"foreignKey:EventID;references:id"`
+	User            *userV1 `gorm:"foreignKey:UserID;references:id"`
+}
+
+func (eventMembershipV5) TableName() string { return config.TableMembers }
+
+// eventMembershipsMigration creates the table linking co-hosts to the events shared with them.
+var eventMembershipsMigration = Migration{
+	Version: 5,
+	Name:    "event_memberships",
+	Up: func(databaseTransaction *gorm.DB) error {
+		return databaseTransaction.AutoMigrate(&eventMembershipV5{})
+	},
+	Down: func(databaseTransaction *gorm.DB) error {
+		return databaseTransaction.Migrator().DropTable(&eventMembershipV5{})
+	},
+}
@@ -0,0 +1,87 @@
+package migrations
+
+import (
+	"time"
+
+	"github.com/temirov/RSVP/pkg/config"
+	"gorm.io/gorm"
+)
+
+type organizationV6 struct {
+	BaseModelV1
+	Name            string `gorm:"size:100;not null"`
+	CreatedByUserID string `gorm:"type:varchar(8);not null"`
+}
+
+func (organizationV6) TableName() string { return config.TableOrgs }
+
+type organizationMemberV6 struct {
+	BaseModelV1
+	OrganizationID  string  `gorm:"type:varchar(8);not null;index;uniqueIndex:idx_organization_members_org_email"`
+	UserID          *string `gorm:"type:varchar(8);index"`
+	InvitedEmail    string  `gorm:"size:255;not null;index;uniqueIndex:idx_organization_members_org_email"`
+	Role            string  `gorm:"size:20;not null"`
+	InvitedByUserID string  `gorm:"type:varchar(8);not null"`
+	AcceptedAt      *time.Time
+	Organization    organizationV6 `gorm:"foreignKey:OrganizationID;references:id"`
+	User            *userV1        `gorm:"foreignKey:UserID;references:id"`
+}
+
+func (organizationMemberV6) TableName() string { return config.TableOrgUsers }
+
+type eventOrganizationV6 struct {
+	OrganizationID *string `gorm:"type:varchar(8);index:idx_events_organization_id"`
+}
+
+func (eventOrganizationV6) TableName() string { return config.TableEvents }
+
+type venueOrganizationV6 struct {
+	OrganizationID *string `gorm:"type:varchar(8);index:idx_venues_organization_id"`
+}
+
+func (venueOrganizationV6) TableName() string { return config.TableVenues }
+
+// organizationOwnedModels are the tables that gain an optional owning organization.
+var organizationOwnedModels = []interface{}{&eventOrganizationV6{}, &venueOrganizationV6{}}
+
+// organizationsMigration creates the organizations and organization_members tables and lets events
+// and venues belong to an organization instead of only to the user who created them.
+var organizationsMigration = Migration{
+	Version: 6,
+	Name:    "organizations",
+	Up: func(databaseTransaction *gorm.DB) error {
+		if err := databaseTransaction.AutoMigrate(&organizationV6{}, &organizationMemberV6{}); err != nil {
+			return err
+		}
+		schemaMigrator := databaseTransaction.Migrator()
+		for _, ownedModel := range organizationOwnedModels {
+			if !schemaMigrator.HasColumn(ownedModel, "OrganizationID") {
+				if err := schemaMigrator.AddColumn(ownedModel, "OrganizationID"); err != nil {
+					return err
+				}
+			}
+			if !schemaMigrator.HasIndex(ownedModel, "OrganizationID") {
+				if err := schemaMigrator.CreateIndex(ownedModel, "OrganizationID"); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	},
+	Down: func(databaseTransaction *gorm.DB) error {
+		schemaMigrator := databaseTransaction.Migrator()
+		for _, ownedModel := range organizationOwnedModels {
+			if schemaMigrator.HasIndex(ownedModel, "OrganizationID") {
+				if err := schemaMigrator.DropIndex(ownedModel, "OrganizationID"); err != nil {
+					return err
+				}
+			}
+			if schemaMigrator.HasColumn(ownedModel, "OrganizationID") {
+				if err := schemaMigrator.DropColumn(ownedModel, "OrganizationID"); err != nil {
+					return err
+				}
+			}
+		}
+		return schemaMigrator.DropTable(&organizationMemberV6{}, &organizationV6{})
+	},
+}
@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	"time"
+
+	"github.com/temirov/RSVP/pkg/config"
+	"gorm.io/gorm"
+)
+
+type ownershipTransferV7 struct {
+	BaseModelV1
+	ResourceType        string `gorm:"size:10;not null;index:idx_ownership_transfers_resource"`
+	ResourceID          string `gorm:"type:varchar(8);not null;index:idx_ownership_transfers_resource"`
+	FromUserID          string `gorm:"type:varchar(8);not null;index"`
+	RecipientEmail      string `gorm:"size:255;not null;index"`
+	IncludeLinkedEvents bool   `gorm:"not null;default:false"`
+	Status              string `gorm:"size:20;not null;index"`
+	ResolvedAt          *time.Time
+	FromUser            userV1 `gorm:"foreignKey:FromUserID;references:id"`
+}
+
+func (ownershipTransferV7) TableName() string { return config.TableTransfers }
+
+// ownershipTransfersMigration creates the table of owner-initiated transfers of events and venues to other users.
+var ownershipTransfersMigration = Migration{
+	Version: 7,
+	Name:    "ownership_transfers",
+	Up: func(databaseTransaction *gorm.DB) error {
+		return databaseTransaction.AutoMigrate(&ownershipTransferV7{})
+	},
+	Down: func(databaseTransaction *gorm.DB) error {
+		return databaseTransaction.Migrator().DropTable(&ownershipTransferV7{})
+	},
+}
@@ -0,0 +1,30 @@
+package migrations
+
+import (
+	"time"
+
+	"github.com/temirov/RSVP/pkg/config"
+	"gorm.io/gorm"
+)
+
+type loginLinkV8 struct {
+	BaseModelV1
+	Email     string    `gorm:"size:255;not null;index"`
+	TokenHash string    `gorm:"size:64;not null;uniqueIndex"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+	UsedAt    *time.Time
+}
+
+func (loginLinkV8) TableName() string { return config.TableLogins }
+
+// loginLinksMigration creates the table of hashed one-time sign-in links sent by email.
+var loginLinksMigration = Migration{
+	Version: 8,
+	Name:    "login_links",
+	Up: func(databaseTransaction *gorm.DB) error {
+		return databaseTransaction.AutoMigrate(&loginLinkV8{})
+	},
+	Down: func(databaseTransaction *gorm.DB) error {
+		return databaseTransaction.Migrator().DropTable(&loginLinkV8{})
+	},
+}
@@ -0,0 +1,47 @@
+package migrations
+
+import (
+	"time"
+
+	"github.com/temirov/RSVP/pkg/config"
+	"gorm.io/gorm"
+)
+
+type userAccessV9 struct {
+	LastSignInAt *time.Time `gorm:"column:last_sign_in_at"`
+	SuspendedAt  *time.Time `gorm:"column:suspended_at"`
+}
+
+func (userAccessV9) TableName() string { return config.TableUsers }
+
+var userAccessColumns = []string{"LastSignInAt", "SuspendedAt"}
+
+// userAccessMigration adds the last sign-in time and the suspension time to users.
+var userAccessMigration = Migration{
+	Version: 9,
+	Name:    "user_access",
+	Up: func(databaseTransaction *gorm.DB) error {
+		schemaMigrator := databaseTransaction.Migrator()
+		for _, columnField := range userAccessColumns {
+			if schemaMigrator.HasColumn(&userAccessV9{}, columnField) {
+				continue
+			}
+			if err := schemaMigrator.AddColumn(&userAccessV9{}, columnField); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	Down: func(databaseTransaction *gorm.DB) error {
+		schemaMigrator := databaseTransaction.Migrator()
+		for _, columnField := range userAccessColumns {
+			if !schemaMigrator.HasColumn(&userAccessV9{}, columnField) {
+				continue
+			}
+			if err := schemaMigrator.DropColumn(&userAccessV9{}, columnField); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
@@ -0,0 +1,52 @@
+package migrations
+
+import (
+	"github.com/temirov/RSVP/pkg/config"
+	"gorm.io/gorm"
+)
+
+type invitationLinkEventV10 struct {
+	AcceptBareCodes bool `gorm:"column:accept_bare_codes;not null;default:false"`
+}
+
+func (invitationLinkEventV10) TableName() string { return config.TableEvents }
+
+type invitationLinkRSVPV10 struct {
+	LinkVersion int `gorm:"column:link_version;not null;default:0"`
+}
+
+func (invitationLinkRSVPV10) TableName() string { return config.TableRSVPs }
+
+// invitationLinksMigration adds the link version of RSVPs and the bare code opt-in of events. Events created
+// before signed links existed keep accepting bare codes, so the links and cards already sent out keep working.
+var invitationLinksMigration = Migration{
+	Version: 10,
+	Name:    "invitation_links",
+	Up: func(databaseTransaction *gorm.DB) error {
+		schemaMigrator := databaseTransaction.Migrator()
+		if !schemaMigrator.HasColumn(&invitationLinkRSVPV10{}, "LinkVersion") {
+			if err := schemaMigrator.AddColumn(&invitationLinkRSVPV10{}, "LinkVersion"); err != nil {
+				return err
+			}
+		}
+		if schemaMigrator.HasColumn(&invitationLinkEventV10{}, "AcceptBareCodes") {
+			return nil
+		}
+		if err := schemaMigrator.AddColumn(&invitationLinkEventV10{}, "AcceptBareCodes"); err != nil {
+			return err
+		}
+		return databaseTransaction.Table(config.TableEvents).Where("1 = 1").UpdateColumn("accept_bare_codes", true).Error
+	},
+	Down: func(databaseTransaction *gorm.DB) error {
+		schemaMigrator := databaseTransaction.Migrator()
+		if schemaMigrator.HasColumn(&invitationLinkEventV10{}, "AcceptBareCodes") {
+			if err := schemaMigrator.DropColumn(&invitationLinkEventV10{}, "AcceptBareCodes"); err != nil {
+				return err
+			}
+		}
+		if schemaMigrator.HasColumn(&invitationLinkRSVPV10{}, "LinkVersion") {
+			return schemaMigrator.DropColumn(&invitationLinkRSVPV10{}, "LinkVersion")
+		}
+		return nil
+	},
+}
@@ -0,0 +1,230 @@
+// Package migrations applies numbered, reversible schema migrations and records them in the
+// schema_migrations table. Each migration works against frozen snapshot structs rather than the
+// live models, so later model changes never alter what an already-released migration does.
+package migrations
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/temirov/RSVP/pkg/config"
+	"gorm.io/gorm"
+)
+
+// ErrSchemaTooNew is returned when the database was migrated by a newer release than this binary.
+var ErrSchemaTooNew = errors.New("database schema is newer than this application supports")
+
+// ErrUnknownMigration is returned when a rollback targets a version that this binary does not know.
+var ErrUnknownMigration = errors.New("applied migration is unknown to this application")
+
+// Migration is a single numbered schema change. Up and Down run inside a transaction where the dialect allows it.
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(databaseTransaction *gorm.DB) error
+	Down    func(databaseTransaction *gorm.DB) error
+}
+
+// SchemaMigration is a row of the schema_migrations table.
+type SchemaMigration struct {
+	Version   int    `gorm:"primaryKey;autoIncrement:false"`
+	Name      string `gorm:"size:255;not null"`
+	AppliedAt time.Time
+}
+
+// TableName returns the schema_migrations table name.
+func (SchemaMigration) TableName() string {
+	return config.TableSchemaMigrations
+}
+
+// Status describes a known migration and when it was applied (nil if pending).
+type Status struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	AppliedAt *time.Time `json:"appliedAt,omitempty"`
+}
+
+// registeredMigrations lists every migration in version order. New migrations are appended here.
+var registeredMigrations = []Migration{
+	initialSchemaMigration,
+	venueOwnerBackfillMigration,
+	apiTokensMigration,
+	rsvpViewTrackingMigration,
+	eventMembershipsMigration,
+	organizationsMigration,
+	ownershipTransfersMigration,
+	loginLinksMigration,
+	userAccessMigration,
+	invitationLinksMigration,
+}
+
+// All returns the known migrations sorted by version.
+func All() []Migration {
+	sortedMigrations := append([]Migration(nil), registeredMigrations...)
+	sort.Slice(sortedMigrations, func(left, right int) bool {
+		return sortedMigrations[left].Version < sortedMigrations[right].Version
+	})
+	return sortedMigrations
+}
+
+// LatestVersion returns the highest migration version known to this binary.
+func LatestVersion() int {
+	knownMigrations := All()
+	if len(knownMigrations) == 0 {
+		return 0
+	}
+	return knownMigrations[len(knownMigrations)-1].Version
+}
+
+// EnsureVersionTable creates the schema_migrations table if it does not exist yet.
+func EnsureVersionTable(databaseConnection *gorm.DB) error {
+	return databaseConnection.AutoMigrate(&SchemaMigration{})
+}
+
+// CurrentVersion returns the highest applied migration version, or 0 for an unmigrated database.
+func CurrentVersion(databaseConnection *gorm.DB) (int, error) {
+	if err := EnsureVersionTable(databaseConnection); err != nil {
+		return 0, err
+	}
+	var currentVersion int
+	err := databaseConnection.Model(&SchemaMigration{}).
+		Select("COALESCE(MAX(version), 0)").
+		Scan(&currentVersion).Error
+	return currentVersion, err
+}
+
+// CheckCompatibility fails with ErrSchemaTooNew if the database has migrations applied that this binary does not know.
+func CheckCompatibility(databaseConnection *gorm.DB) error {
+	currentVersion, err := CurrentVersion(databaseConnection)
+	if err != nil {
+		return err
+	}
+	if latestVersion := LatestVersion(); currentVersion > latestVersion {
+		return fmt.Errorf("%w: database is at version %d, application supports up to %d", ErrSchemaTooNew, currentVersion, latestVersion)
+	}
+	return nil
+}
+
+// Report returns the status of every known migration.
+func Report(databaseConnection *gorm.DB) ([]Status, error) {
+	appliedByVersion, err := appliedMigrations(databaseConnection)
+	if err != nil {
+		return nil, err
+	}
+	var statusReport []Status
+	for _, knownMigration := range All() {
+		migrationStatus := Status{Version: knownMigration.Version, Name: knownMigration.Name}
+		if appliedRecord, isApplied := appliedByVersion[knownMigration.Version]; isApplied {
+			appliedAt := appliedRecord.AppliedAt
+			migrationStatus.AppliedAt = &appliedAt
+		}
+		statusReport = append(statusReport, migrationStatus)
+	}
+	return statusReport, nil
+}
+
+// Pending returns the known migrations that have not been applied, in version order.
+func Pending(databaseConnection *gorm.DB) ([]Migration, error) {
+	appliedByVersion, err := appliedMigrations(databaseConnection)
+	if err != nil {
+		return nil, err
+	}
+	var pendingMigrations []Migration
+	for _, knownMigration := range All() {
+		if _, isApplied := appliedByVersion[knownMigration.Version]; !isApplied {
+			pendingMigrations = append(pendingMigrations, knownMigration)
+		}
+	}
+	return pendingMigrations, nil
+}
+
+// Apply runs pending migrations up to and including targetVersion (0 means the latest version).
+// It returns the migrations that were applied; on failure, migrations applied before the failing one stay applied.
+func Apply(databaseConnection *gorm.DB, targetVersion int, applicationLogger *log.Logger) ([]Migration, error) {
+	if err := CheckCompatibility(databaseConnection); err != nil {
+		return nil, err
+	}
+	pendingMigrations, err := Pending(databaseConnection)
+	if err != nil {
+		return nil, err
+	}
+	var appliedNow []Migration
+	for _, pendingMigration := range pendingMigrations {
+		if targetVersion > 0 && pendingMigration.Version > targetVersion {
+			break
+		}
+		applicationLogger.Printf("Applying migration %04d_%s", pendingMigration.Version, pendingMigration.Name)
+		transactionError := databaseConnection.Transaction(func(databaseTransaction *gorm.DB) error {
+			if upError := pendingMigration.Up(databaseTransaction); upError != nil {
+				return upError
+			}
+			return databaseTransaction.Create(&SchemaMigration{
+				Version:   pendingMigration.Version,
+				Name:      pendingMigration.Name,
+				AppliedAt: time.Now().UTC(),
+			}).Error
+		})
+		if transactionError != nil {
+			return appliedNow, fmt.Errorf("migration %04d_%s failed: %w", pendingMigration.Version, pendingMigration.Name, transactionError)
+		}
+		appliedNow = append(appliedNow, pendingMigration)
+	}
+	return appliedNow, nil
+}
+
+// Rollback reverts the most recently applied migrations, newest first, up to the given number of steps.
+func Rollback(databaseConnection *gorm.DB, steps int, applicationLogger *log.Logger) ([]Migration, error) {
+	appliedByVersion, err := appliedMigrations(databaseConnection)
+	if err != nil {
+		return nil, err
+	}
+	appliedVersions := make([]int, 0, len(appliedByVersion))
+	for appliedVersion := range appliedByVersion {
+		appliedVersions = append(appliedVersions, appliedVersion)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(appliedVersions)))
+
+	knownByVersion := make(map[int]Migration)
+	for _, knownMigration := range All() {
+		knownByVersion[knownMigration.Version] = knownMigration
+	}
+
+	var revertedNow []Migration
+	for stepIndex := 0; stepIndex < steps && stepIndex < len(appliedVersions); stepIndex++ {
+		revertMigration, isKnown := knownByVersion[appliedVersions[stepIndex]]
+		if !isKnown {
+			return revertedNow, fmt.Errorf("%w: version %d", ErrUnknownMigration, appliedVersions[stepIndex])
+		}
+		applicationLogger.Printf("Reverting migration %04d_%s", revertMigration.Version, revertMigration.Name)
+		transactionError := databaseConnection.Transaction(func(databaseTransaction *gorm.DB) error {
+			if downError := revertMigration.Down(databaseTransaction); downError != nil {
+				return downError
+			}
+			return databaseTransaction.Delete(&SchemaMigration{}, "version = ?", revertMigration.Version).Error
+		})
+		if transactionError != nil {
+			return revertedNow, fmt.Errorf("rollback of %04d_%s failed: %w", revertMigration.Version, revertMigration.Name, transactionError)
+		}
+		revertedNow = append(revertedNow, revertMigration)
+	}
+	return revertedNow, nil
+}
+
+// appliedMigrations loads the schema_migrations rows keyed by version.
+func appliedMigrations(databaseConnection *gorm.DB) (map[int]SchemaMigration, error) {
+	if err := EnsureVersionTable(databaseConnection); err != nil {
+		return nil, err
+	}
+	var appliedRecords []SchemaMigration
+	if err := databaseConnection.Order("version").Find(&appliedRecords).Error; err != nil {
+		return nil, err
+	}
+	appliedByVersion := make(map[int]SchemaMigration, len(appliedRecords))
+	for _, appliedRecord := range appliedRecords {
+		appliedByVersion[appliedRecord.Version] = appliedRecord
+	}
+	return appliedByVersion, nil
+}
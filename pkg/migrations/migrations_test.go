@@ -0,0 +1,106 @@
+package migrations_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/migrations"
+	"github.com/temirov/RSVP/pkg/testdb"
+)
+
+func TestMigrationVersionsAreUniqueAndOrdered(t *testing.T) {
+	knownMigrations := migrations.All()
+	for migrationIndex, knownMigration := range knownMigrations {
+		if knownMigration.Version != migrationIndex+1 {
+			t.Errorf("migration %d has version %d, want consecutive versions from 1", migrationIndex, knownMigration.Version)
+		}
+		if knownMigration.Name == "" || knownMigration.Up == nil || knownMigration.Down == nil {
+			t.Errorf("migration %04d lacks a name, an Up or a Down", knownMigration.Version)
+		}
+	}
+	if latestVersion := migrations.LatestVersion(); latestVersion != len(knownMigrations) {
+		t.Errorf("LatestVersion() = %d, want %d", latestVersion, len(knownMigrations))
+	}
+}
+
+func TestApplyMigratesToTheLatestVersion(t *testing.T) {
+	databaseConnection := testdb.Open(t)
+	appliedMigrations, err := migrations.Apply(databaseConnection, 0, testdb.Logger())
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if len(appliedMigrations) != len(migrations.All()) {
+		t.Errorf("Apply() applied %d migrations, want %d", len(appliedMigrations), len(migrations.All()))
+	}
+	if currentVersion, err := migrations.CurrentVersion(databaseConnection); err != nil || currentVersion != migrations.LatestVersion() {
+		t.Errorf("CurrentVersion() = %d, %v, want %d", currentVersion, err, migrations.LatestVersion())
+	}
+	for _, tableName := range []string{config.TableUsers, config.TableEvents, config.TableRSVPs, config.TableVenues, config.TableLogins} {
+		if !databaseConnection.Migrator().HasTable(tableName) {
+			t.Errorf("table %s is missing after migrating", tableName)
+		}
+	}
+	if pendingMigrations, err := migrations.Pending(databaseConnection); err != nil || len(pendingMigrations) != 0 {
+		t.Errorf("Pending() = %d migrations, %v, want none", len(pendingMigrations), err)
+	}
+	if appliedAgain, err := migrations.Apply(databaseConnection, 0, testdb.Logger()); err != nil || len(appliedAgain) != 0 {
+		t.Errorf("a second Apply() applied %d migrations, %v, want none", len(appliedAgain), err)
+	}
+}
+
+func TestApplyStopsAtTargetVersion(t *testing.T) {
+	databaseConnection := testdb.Open(t)
+	if _, err := migrations.Apply(databaseConnection, 3, testdb.Logger()); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	statusReport, err := migrations.Report(databaseConnection)
+	if err != nil {
+		t.Fatalf("Report() error = %v", err)
+	}
+	for _, migrationStatus := range statusReport {
+		if isApplied := migrationStatus.AppliedAt != nil; isApplied != (migrationStatus.Version <= 3) {
+			t.Errorf("migration %04d applied = %v after migrating to version 3", migrationStatus.Version, isApplied)
+		}
+	}
+}
+
+func TestEveryMigrationRollsBackAndReapplies(t *testing.T) {
+	databaseConnection := testdb.Open(t)
+	if _, err := migrations.Apply(databaseConnection, 0, testdb.Logger()); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	revertedMigrations, err := migrations.Rollback(databaseConnection, len(migrations.All()), testdb.Logger())
+	if err != nil {
+		t.Fatalf("Rollback() error = %v", err)
+	}
+	if len(revertedMigrations) != len(migrations.All()) {
+		t.Errorf("Rollback() reverted %d migrations, want %d", len(revertedMigrations), len(migrations.All()))
+	}
+	if currentVersion, _ := migrations.CurrentVersion(databaseConnection); currentVersion != 0 {
+		t.Errorf("CurrentVersion() after rolling everything back = %d, want 0", currentVersion)
+	}
+	for _, tableName := range []string{config.TableUsers, config.TableEvents, config.TableRSVPs, config.TableLogins} {
+		if databaseConnection.Migrator().HasTable(tableName) {
+			t.Errorf("table %s is left after rolling everything back", tableName)
+		}
+	}
+	if _, err := migrations.Apply(databaseConnection, 0, testdb.Logger()); err != nil {
+		t.Fatalf("Apply() after rolling back error = %v", err)
+	}
+}
+
+func TestApplyRefusesNewerSchema(t *testing.T) {
+	databaseConnection := testdb.OpenMigrated(t)
+	futureMigration := migrations.SchemaMigration{Version: migrations.LatestVersion() + 1, Name: "from_the_future", AppliedAt: time.Now().UTC()}
+	if err := databaseConnection.Create(&futureMigration).Error; err != nil {
+		t.Fatalf("recording a future migration: %v", err)
+	}
+	if _, err := migrations.Apply(databaseConnection, 0, testdb.Logger()); !errors.Is(err, migrations.ErrSchemaTooNew) {
+		t.Errorf("Apply() error = %v, want ErrSchemaTooNew", err)
+	}
+	if _, err := migrations.Rollback(databaseConnection, 1, testdb.Logger()); !errors.Is(err, migrations.ErrUnknownMigration) {
+		t.Errorf("Rollback() error = %v, want ErrUnknownMigration", err)
+	}
+}
@@ -0,0 +1,418 @@
+// Package portability exports an organizer's venues, events and RSVPs as a versioned JSON document
+// and imports such documents into another account, possibly on another installation.
+// Records keep their IDs when they are free on the target; colliding or malformed IDs are replaced
+// with fresh ones from models.EnsureUniqueID and listed in the import report.
+package portability
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/temirov/RSVP/models"
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/migrations"
+	"github.com/temirov/RSVP/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// ErrUnsupportedFormat is returned when a document is not an account export or was written by a newer format version.
+var ErrUnsupportedFormat = errors.New("unsupported account export format")
+
+// Record kinds used in import reports.
+const (
+	KindVenue = "venue"
+	KindEvent = "event"
+	KindRSVP  = "rsvp"
+)
+
+// Document is the top-level account export. FormatVersion changes only when the JSON layout does;
+// SchemaVersion records the database schema of the exporting installation for diagnostics.
+type Document struct {
+	Format        string        `json:"format"`
+	FormatVersion int           `json:"formatVersion"`
+	ExportedAt    time.Time     `json:"exportedAt"`
+	SchemaVersion int           `json:"schemaVersion"`
+	Account       AccountRecord `json:"account"`
+	Venues        []VenueRecord `json:"venues"`
+	Events        []EventRecord `json:"events"`
+}
+
+// AccountRecord describes the exporting user. It is informational; imports always target an explicit user.
+type AccountRecord struct {
+	ID        string    `json:"id"`
+	Email     string    `json:"email"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// VenueRecord is an exported venue.
+type VenueRecord struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Address     string    `json:"address,omitempty"`
+	Capacity    int       `json:"capacity,omitempty"`
+	Website     string    `json:"website,omitempty"`
+	Phone       string    `json:"phone,omitempty"`
+	Email       string    `json:"email,omitempty"`
+	Description string    `json:"description,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// EventRecord is an exported event with its RSVPs. VenueID refers to an entry of Document.Venues.
+type EventRecord struct {
+	ID          string    `json:"id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description,omitempty"`
+	StartTime   time.Time `json:"startTime"`
+	EndTime     time.Time `json:"endTime"`
+	VenueID     *string   `json:"venueId,omitempty"`
+	// AcceptBareCodes is set for events that accept invitation links without a signed token.
+	AcceptBareCodes bool         `json:"acceptBareCodes,omitempty"`
+	CreatedAt       time.Time    `json:"createdAt"`
+	UpdatedAt       time.Time    `json:"updatedAt"`
+	RSVPs           []RSVPRecord `json:"rsvps"`
+}
+
+// RSVPRecord is an exported RSVP. Its ID is the invitation code used in guest links.
+type RSVPRecord struct {
+	ID            string     `json:"id"`
+	Name          string     `json:"name"`
+	Response      string     `json:"response,omitempty"`
+	ExtraGuests   int        `json:"extraGuests"`
+	FirstViewedAt *time.Time `json:"firstViewedAt,omitempty"`
+	LastViewedAt  *time.Time `json:"lastViewedAt,omitempty"`
+	ViewCount     int        `json:"viewCount"`
+	RespondedAt   *time.Time `json:"respondedAt,omitempty"`
+	LinkVersion   int        `json:"linkVersion,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
+}
+
+// ImportCounts tallies the records an import created.
+type ImportCounts struct {
+	Venues int `json:"venues"`
+	Events int `json:"events"`
+	RSVPs  int `json:"rsvps"`
+}
+
+// RemappedID records a record that was imported under a new ID.
+type RemappedID struct {
+	Kind  string `json:"kind"`
+	OldID string `json:"oldId"`
+	NewID string `json:"newId"`
+}
+
+// SkippedRecord records a record, or part of one, that was not imported.
+type SkippedRecord struct {
+	Kind   string `json:"kind"`
+	ID     string `json:"id"`
+	Reason string `json:"reason"`
+}
+
+// ImportReport summarises an import.
+type ImportReport struct {
+	TargetUserID string          `json:"targetUserId"`
+	Created      ImportCounts    `json:"created"`
+	Remapped     []RemappedID    `json:"remapped"`
+	Skipped      []SkippedRecord `json:"skipped"`
+}
+
+// Export builds the account export for the given user. Soft-deleted records and API tokens are not included.
+func Export(databaseConnection *gorm.DB, ownerUser *models.User) (*Document, error) {
+	schemaVersion, err := migrations.CurrentVersion(databaseConnection)
+	if err != nil {
+		return nil, err
+	}
+	exportDocument := &Document{
+		Format:        config.ExportFormatName,
+		FormatVersion: config.ExportFormatVersion,
+		ExportedAt:    time.Now().UTC(),
+		SchemaVersion: schemaVersion,
+		Account: AccountRecord{
+			ID:        ownerUser.ID,
+			Email:     ownerUser.Email,
+			Name:      ownerUser.Name,
+			CreatedAt: ownerUser.CreatedAt,
+		},
+		Venues: []VenueRecord{},
+		Events: []EventRecord{},
+	}
+
+	ownedVenues, err := models.FindVenuesByOwner(databaseConnection, ownerUser.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, venueRecord := range ownedVenues {
+		exportDocument.Venues = append(exportDocument.Venues, VenueRecord{
+			ID:          venueRecord.ID,
+			Name:        venueRecord.Name,
+			Address:     venueRecord.Address,
+			Capacity:    venueRecord.Capacity,
+			Website:     venueRecord.Website,
+			Phone:       venueRecord.Phone,
+			Email:       venueRecord.Email,
+			Description: venueRecord.Description,
+			CreatedAt:   venueRecord.CreatedAt,
+			UpdatedAt:   venueRecord.UpdatedAt,
+		})
+	}
+
+	ownedEvents, err := models.FindEventsByUserID(databaseConnection, ownerUser.ID, true, false)
+	if err != nil {
+		return nil, err
+	}
+	for _, eventRecord := range ownedEvents {
+		exportedEvent := EventRecord{
+			ID:              eventRecord.ID,
+			Title:           eventRecord.Title,
+			Description:     eventRecord.Description,
+			StartTime:       eventRecord.StartTime,
+			EndTime:         eventRecord.EndTime,
+			VenueID:         eventRecord.VenueID,
+			AcceptBareCodes: eventRecord.AcceptBareCodes,
+			CreatedAt:       eventRecord.CreatedAt,
+			UpdatedAt:       eventRecord.UpdatedAt,
+			RSVPs:           []RSVPRecord{},
+		}
+		for _, rsvpRecord := range eventRecord.RSVPs {
+			exportedEvent.RSVPs = append(exportedEvent.RSVPs, RSVPRecord{
+				ID:            rsvpRecord.ID,
+				Name:          rsvpRecord.Name,
+				Response:      rsvpRecord.Response,
+				ExtraGuests:   rsvpRecord.ExtraGuests,
+				FirstViewedAt: rsvpRecord.FirstViewedAt,
+				LastViewedAt:  rsvpRecord.LastViewedAt,
+				ViewCount:     rsvpRecord.ViewCount,
+				RespondedAt:   rsvpRecord.RespondedAt,
+				LinkVersion:   rsvpRecord.LinkVersion,
+				CreatedAt:     rsvpRecord.CreatedAt,
+				UpdatedAt:     rsvpRecord.UpdatedAt,
+			})
+		}
+		exportDocument.Events = append(exportDocument.Events, exportedEvent)
+	}
+	return exportDocument, nil
+}
+
+// CheckFormat verifies that the document is an account export this binary can read.
+func (exportDocument *Document) CheckFormat() error {
+	if exportDocument.Format != config.ExportFormatName {
+		return fmt.Errorf("%w: format is %q, expected %q", ErrUnsupportedFormat, exportDocument.Format, config.ExportFormatName)
+	}
+	if exportDocument.FormatVersion < 1 || exportDocument.FormatVersion > config.ExportFormatVersion {
+		return fmt.Errorf("%w: version %d, this application reads up to %d", ErrUnsupportedFormat, exportDocument.FormatVersion, config.ExportFormatVersion)
+	}
+	return nil
+}
+
+// Import recreates the document's venues, events and RSVPs under targetUserID in a single transaction.
+// Records whose ID already belongs to the target user are skipped, so importing the same file twice is harmless.
+// Invalid records are skipped and reported; any database error rolls the whole import back.
+func Import(databaseConnection *gorm.DB, exportDocument *Document, targetUserID string) (*ImportReport, error) {
+	if err := exportDocument.CheckFormat(); err != nil {
+		return nil, err
+	}
+	importReport := &ImportReport{TargetUserID: targetUserID, Remapped: []RemappedID{}, Skipped: []SkippedRecord{}}
+	transactionError := databaseConnection.Transaction(func(databaseTransaction *gorm.DB) error {
+		venueIDMapping := make(map[string]string, len(exportDocument.Venues))
+		for _, venueRecord := range exportDocument.Venues {
+			if err := importVenue(databaseTransaction, importReport, venueIDMapping, venueRecord); err != nil {
+				return err
+			}
+		}
+		for _, eventRecord := range exportDocument.Events {
+			if err := importEvent(databaseTransaction, importReport, venueIDMapping, eventRecord); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if transactionError != nil {
+		return nil, transactionError
+	}
+	return importReport, nil
+}
+
+// importVenue imports one venue and records the ID it ended up with in venueIDMapping.
+func importVenue(databaseTransaction *gorm.DB, importReport *ImportReport, venueIDMapping map[string]string, venueRecord VenueRecord) error {
+	if validationError := utils.ValidateVenueName(venueRecord.Name); validationError != nil {
+		importReport.skip(KindVenue, venueRecord.ID, validationError.Error())
+		return nil
+	}
+	assignedID, alreadyImported, err := importReport.assignID(databaseTransaction, KindVenue, config.TableVenues, models.GenerateBase62ID, venueRecord.ID)
+	if err != nil {
+		return err
+	}
+	venueIDMapping[venueRecord.ID] = assignedID
+	if alreadyImported {
+		return nil
+	}
+	newVenue := models.Venue{
+		BaseModel:   models.BaseModel{ID: assignedID, CreatedAt: venueRecord.CreatedAt, UpdatedAt: venueRecord.UpdatedAt},
+		UserID:      importReport.TargetUserID,
+		Name:        venueRecord.Name,
+		Address:     venueRecord.Address,
+		Capacity:    venueRecord.Capacity,
+		Website:     venueRecord.Website,
+		Phone:       venueRecord.Phone,
+		Email:       venueRecord.Email,
+		Description: venueRecord.Description,
+	}
+	if err := newVenue.Create(databaseTransaction); err != nil {
+		return fmt.Errorf("venue %s: %w", venueRecord.ID, err)
+	}
+	importReport.Created.Venues++
+	return nil
+}
+
+// importEvent imports one event and its RSVPs.
+func importEvent(databaseTransaction *gorm.DB, importReport *ImportReport, venueIDMapping map[string]string, eventRecord EventRecord) error {
+	if validationError := utils.ValidateEventTitle(eventRecord.Title); validationError != nil {
+		importReport.skip(KindEvent, eventRecord.ID, validationError.Error())
+		return nil
+	}
+	if validationError := utils.ValidateEventStartTime(eventRecord.StartTime); validationError != nil {
+		importReport.skip(KindEvent, eventRecord.ID, validationError.Error())
+		return nil
+	}
+	if eventRecord.EndTime.Before(eventRecord.StartTime) {
+		importReport.skip(KindEvent, eventRecord.ID, "event ends before it starts")
+		return nil
+	}
+	assignedID, alreadyImported, err := importReport.assignID(databaseTransaction, KindEvent, config.TableEvents, models.GenerateBase62ID, eventRecord.ID)
+	if err != nil {
+		return err
+	}
+	if alreadyImported {
+		if len(eventRecord.RSVPs) > 0 {
+			importReport.skip(KindRSVP, eventRecord.ID, fmt.Sprintf("%d RSVP(s) of an already imported event", len(eventRecord.RSVPs)))
+		}
+		return nil
+	}
+
+	var mappedVenueID *string
+	if eventRecord.VenueID != nil {
+		if targetVenueID, isKnown := venueIDMapping[*eventRecord.VenueID]; isKnown {
+			mappedVenueID = &targetVenueID
+		} else {
+			importReport.skip(KindVenue, *eventRecord.VenueID, fmt.Sprintf("referenced by event %s but not imported; the event has no venue", eventRecord.ID))
+		}
+	}
+	newEvent := models.Event{
+		BaseModel:       models.BaseModel{ID: assignedID, CreatedAt: eventRecord.CreatedAt, UpdatedAt: eventRecord.UpdatedAt},
+		Title:           eventRecord.Title,
+		Description:     eventRecord.Description,
+		StartTime:       eventRecord.StartTime,
+		EndTime:         eventRecord.EndTime,
+		UserID:          importReport.TargetUserID,
+		VenueID:         mappedVenueID,
+		AcceptBareCodes: eventRecord.AcceptBareCodes,
+	}
+	if err := newEvent.Create(databaseTransaction); err != nil {
+		return fmt.Errorf("event %s: %w", eventRecord.ID, err)
+	}
+	importReport.Created.Events++
+
+	for _, rsvpRecord := range eventRecord.RSVPs {
+		if err := importRSVP(databaseTransaction, importReport, newEvent.ID, rsvpRecord); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// importRSVP imports one RSVP into the already created event. A remapped RSVP ID changes the guest's invitation link.
+func importRSVP(databaseTransaction *gorm.DB, importReport *ImportReport, parentEventID string, rsvpRecord RSVPRecord) error {
+	if validationError := utils.ValidateRSVPName(rsvpRecord.Name); validationError != nil {
+		importReport.skip(KindRSVP, rsvpRecord.ID, validationError.Error())
+		return nil
+	}
+	for _, validationError := range []error{
+		utils.ValidateStoredRSVPResponse(rsvpRecord.Response),
+		utils.ValidateExtraGuests(rsvpRecord.ExtraGuests),
+		utils.ValidateViewCount(rsvpRecord.ViewCount),
+		utils.ValidateLinkVersion(rsvpRecord.LinkVersion),
+	} {
+		if validationError != nil {
+			importReport.skip(KindRSVP, rsvpRecord.ID, validationError.Error())
+			return nil
+		}
+	}
+	assignedID, _, err := importReport.assignID(databaseTransaction, KindRSVP, config.TableRSVPs, models.GenerateBase36ID, rsvpRecord.ID)
+	if err != nil {
+		return err
+	}
+	newRSVP := models.RSVP{
+		BaseModel:     models.BaseModel{ID: assignedID, CreatedAt: rsvpRecord.CreatedAt, UpdatedAt: rsvpRecord.UpdatedAt},
+		Name:          rsvpRecord.Name,
+		Response:      rsvpRecord.Response,
+		ExtraGuests:   rsvpRecord.ExtraGuests,
+		EventID:       parentEventID,
+		FirstViewedAt: rsvpRecord.FirstViewedAt,
+		LastViewedAt:  rsvpRecord.LastViewedAt,
+		ViewCount:     rsvpRecord.ViewCount,
+		RespondedAt:   rsvpRecord.RespondedAt,
+		LinkVersion:   rsvpRecord.LinkVersion,
+	}
+	if err := newRSVP.Create(databaseTransaction); err != nil {
+		return fmt.Errorf("rsvp %s: %w", rsvpRecord.ID, err)
+	}
+	importReport.Created.RSVPs++
+	return nil
+}
+
+// assignID decides the ID an imported record gets. The exported ID is kept when it is well-formed and unused,
+// including by soft-deleted rows. If a live row of the same table with that ID is already owned by the target user,
+// the record counts as already imported. Otherwise a fresh ID is generated and the change is reported.
+// RSVP rows carry no owner, so they are never treated as already imported.
+func (importReport *ImportReport) assignID(databaseTransaction *gorm.DB, recordKind string, tableName string, generateFunc func(int) (string, error), exportedID string) (string, bool, error) {
+	if isWellFormedID(exportedID) {
+		var matchingRowCount int64
+		if err := databaseTransaction.Table(tableName).Where("id = ?", exportedID).Count(&matchingRowCount).Error; err != nil {
+			return "", false, err
+		}
+		if matchingRowCount == 0 {
+			return exportedID, false, nil
+		}
+		if recordKind != KindRSVP {
+			var ownedRowCount int64
+			if err := databaseTransaction.Table(tableName).
+				Where("id = ? AND user_id = ? AND deleted_at IS NULL", exportedID, importReport.TargetUserID).
+				Count(&ownedRowCount).Error; err != nil {
+				return "", false, err
+			}
+			if ownedRowCount > 0 {
+				importReport.skip(recordKind, exportedID, "already present in the target account")
+				return exportedID, true, nil
+			}
+		}
+	}
+	generatedID, err := models.EnsureUniqueID(databaseTransaction, tableName, generateFunc)
+	if err != nil {
+		return "", false, err
+	}
+	importReport.Remapped = append(importReport.Remapped, RemappedID{Kind: recordKind, OldID: exportedID, NewID: generatedID})
+	return generatedID, false, nil
+}
+
+// skip adds an entry to the report's skipped list.
+func (importReport *ImportReport) skip(recordKind string, recordID string, reason string) {
+	importReport.Skipped = append(importReport.Skipped, SkippedRecord{Kind: recordKind, ID: recordID, Reason: reason})
+}
+
+// isWellFormedID reports whether identifier fits the ID column and only uses alphanumeric characters.
+// Older RSVP codes may be shorter than config.IDLength or mix letter cases, so both are accepted.
+func isWellFormedID(identifier string) bool {
+	if identifier == "" || len(identifier) > config.IDLength {
+		return false
+	}
+	for _, identifierCharacter := range identifier {
+		if !strings.ContainsRune(config.Base62Chars, identifierCharacter) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,166 @@
+package portability
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/temirov/RSVP/models"
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/testdb"
+	"gorm.io/gorm"
+)
+
+// createExportedAccount stores a user with a venue, an event at that venue and two RSVPs, one answered and viewed.
+func createExportedAccount(t *testing.T, databaseConnection *gorm.DB) (*models.User, models.Event) {
+	t.Helper()
+	sourceUser := &models.User{Email: "source@example.com"}
+	if err := databaseConnection.Create(sourceUser).Error; err != nil {
+		t.Fatalf("creating the source user: %v", err)
+	}
+	sourceVenue := models.Venue{Name: "Hall", Address: "1 Main St", UserID: sourceUser.ID}
+	if err := sourceVenue.Create(databaseConnection); err != nil {
+		t.Fatalf("creating the venue: %v", err)
+	}
+	startTime := time.Now().Add(48 * time.Hour).Truncate(time.Second).UTC()
+	sourceEvent := models.Event{Title: "Party", StartTime: startTime, EndTime: startTime.Add(2 * time.Hour), UserID: sourceUser.ID, VenueID: &sourceVenue.ID}
+	if err := sourceEvent.Create(databaseConnection); err != nil {
+		t.Fatalf("creating the event: %v", err)
+	}
+	firstViewedAt := startTime.Add(-24 * time.Hour)
+	answeredRSVP := models.RSVP{Name: "Ann", EventID: sourceEvent.ID, Response: config.RSVPResponseYesPrefix, ExtraGuests: 2,
+		FirstViewedAt: &firstViewedAt, LastViewedAt: &firstViewedAt, ViewCount: 3, RespondedAt: &firstViewedAt, LinkVersion: 2}
+	pendingRSVP := models.RSVP{Name: "Bob", EventID: sourceEvent.ID}
+	for _, rsvpRecord := range []*models.RSVP{&answeredRSVP, &pendingRSVP} {
+		if err := rsvpRecord.Create(databaseConnection); err != nil {
+			t.Fatalf("creating the RSVP of %s: %v", rsvpRecord.Name, err)
+		}
+	}
+	return sourceUser, sourceEvent
+}
+
+// exportThroughJSON exports the user's account and reads it back the way an uploaded file would be.
+func exportThroughJSON(t *testing.T, databaseConnection *gorm.DB, sourceUser *models.User) *Document {
+	t.Helper()
+	exportDocument, err := Export(databaseConnection, sourceUser)
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	encodedDocument, err := json.Marshal(exportDocument)
+	if err != nil {
+		t.Fatalf("encoding the export: %v", err)
+	}
+	var decodedDocument Document
+	if err := json.Unmarshal(encodedDocument, &decodedDocument); err != nil {
+		t.Fatalf("decoding the export: %v", err)
+	}
+	return &decodedDocument
+}
+
+func TestExportImportRoundTripWithIDCollisions(t *testing.T) {
+	databaseConnection := testdb.OpenMigrated(t)
+	sourceUser, sourceEvent := createExportedAccount(t, databaseConnection)
+	targetUser := &models.User{Email: "target@example.com"}
+	if err := databaseConnection.Create(targetUser).Error; err != nil {
+		t.Fatalf("creating the target user: %v", err)
+	}
+	exportDocument := exportThroughJSON(t, databaseConnection, sourceUser)
+
+	// Every ID already belongs to the source account, so the copy in the target account gets new ones.
+	importReport, err := Import(databaseConnection, exportDocument, targetUser.ID)
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if importReport.Created != (ImportCounts{Venues: 1, Events: 1, RSVPs: 2}) || len(importReport.Remapped) != 4 || len(importReport.Skipped) != 0 {
+		t.Fatalf("import report = %+v, want 1 venue, 1 event and 2 RSVPs created under new IDs", importReport)
+	}
+	remappedIDs := make(map[string]string, len(importReport.Remapped))
+	for _, remappedID := range importReport.Remapped {
+		remappedIDs[remappedID.OldID] = remappedID.NewID
+	}
+	importedEvents, err := models.FindEventsByUserID(databaseConnection, targetUser.ID, true, false)
+	if err != nil || len(importedEvents) != 1 {
+		t.Fatalf("FindEventsByUserID(target) = %d events, %v, want 1", len(importedEvents), err)
+	}
+	importedEvent := importedEvents[0]
+	if importedEvent.ID != remappedIDs[sourceEvent.ID] || importedEvent.Title != sourceEvent.Title || !importedEvent.StartTime.Equal(sourceEvent.StartTime) {
+		t.Errorf("imported event = %+v, want a copy of %+v under ID %q", importedEvent, sourceEvent, remappedIDs[sourceEvent.ID])
+	}
+	if importedEvent.VenueID == nil || *importedEvent.VenueID != remappedIDs[*sourceEvent.VenueID] {
+		t.Errorf("imported event venue = %v, want the imported venue %q", importedEvent.VenueID, remappedIDs[*sourceEvent.VenueID])
+	}
+	for _, exportedRSVP := range exportDocument.Events[0].RSVPs {
+		var importedRSVP models.RSVP
+		if err := databaseConnection.First(&importedRSVP, "id = ?", remappedIDs[exportedRSVP.ID]).Error; err != nil {
+			t.Fatalf("loading the imported RSVP of %s: %v", exportedRSVP.Name, err)
+		}
+		if importedRSVP.EventID != importedEvent.ID || importedRSVP.Name != exportedRSVP.Name || importedRSVP.Response != exportedRSVP.Response ||
+			importedRSVP.ExtraGuests != exportedRSVP.ExtraGuests || importedRSVP.ViewCount != exportedRSVP.ViewCount || importedRSVP.LinkVersion != exportedRSVP.LinkVersion {
+			t.Errorf("imported RSVP = %+v, want a copy of %+v in the imported event", importedRSVP, exportedRSVP)
+		}
+	}
+	var sourceRSVPCount int64
+	if err := databaseConnection.Model(&models.RSVP{}).Where("event_id = ?", sourceEvent.ID).Count(&sourceRSVPCount).Error; err != nil || sourceRSVPCount != 2 {
+		t.Errorf("the source event has %d RSVPs after the import, %v, want its 2 untouched", sourceRSVPCount, err)
+	}
+
+	// Importing the target's own export into itself finds everything already present.
+	reimportReport, err := Import(databaseConnection, exportThroughJSON(t, databaseConnection, targetUser), targetUser.ID)
+	if err != nil {
+		t.Fatalf("reimport error = %v", err)
+	}
+	if reimportReport.Created != (ImportCounts{}) || len(reimportReport.Remapped) != 0 {
+		t.Errorf("reimport report = %+v, want nothing created or remapped", reimportReport)
+	}
+}
+
+func TestImportSkipsRSVPsWithValuesTheApplicationNeverStores(t *testing.T) {
+	databaseConnection := testdb.OpenMigrated(t)
+	sourceUser, _ := createExportedAccount(t, databaseConnection)
+	exportDocument := exportThroughJSON(t, databaseConnection, sourceUser)
+	if err := databaseConnection.Exec("DELETE FROM " + config.TableRSVPs).Error; err != nil {
+		t.Fatalf("clearing the RSVPs: %v", err)
+	}
+	if err := databaseConnection.Exec("DELETE FROM " + config.TableEvents).Error; err != nil {
+		t.Fatalf("clearing the events: %v", err)
+	}
+	templateRSVP := exportDocument.Events[0].RSVPs[0]
+	testCases := []struct {
+		name         string
+		changeRecord func(*RSVPRecord)
+	}{
+		{name: "unknown response", changeRecord: func(rsvpRecord *RSVPRecord) { rsvpRecord.Response = "Maybe" }},
+		{name: "script as response", changeRecord: func(rsvpRecord *RSVPRecord) { rsvpRecord.Response = "<script>" }},
+		{name: "too many guests", changeRecord: func(rsvpRecord *RSVPRecord) { rsvpRecord.ExtraGuests = config.MaxGuestCount + 1 }},
+		{name: "negative view count", changeRecord: func(rsvpRecord *RSVPRecord) { rsvpRecord.ViewCount = -1 }},
+		{name: "huge view count", changeRecord: func(rsvpRecord *RSVPRecord) { rsvpRecord.ViewCount = config.MaxImportedViewCount + 1 }},
+		{name: "negative link version", changeRecord: func(rsvpRecord *RSVPRecord) { rsvpRecord.LinkVersion = -1 }},
+		{name: "huge link version", changeRecord: func(rsvpRecord *RSVPRecord) { rsvpRecord.LinkVersion = config.MaxImportedLinkVersion + 1 }},
+	}
+	exportDocument.Events[0].RSVPs = nil
+	for _, testCase := range testCases {
+		invalidRSVP := templateRSVP
+		testCase.changeRecord(&invalidRSVP)
+		exportDocument.Events[0].RSVPs = append(exportDocument.Events[0].RSVPs, invalidRSVP)
+	}
+	legacyRSVP := templateRSVP
+	legacyRSVP.ID, legacyRSVP.Response, legacyRSVP.ExtraGuests = "LEGACY01", config.RSVPResponseYesPlusOne, 1
+	exportDocument.Events[0].RSVPs = append(exportDocument.Events[0].RSVPs, legacyRSVP)
+
+	importReport, err := Import(databaseConnection, exportDocument, sourceUser.ID)
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if importReport.Created.Events != 1 || importReport.Created.RSVPs != 1 {
+		t.Errorf("created = %+v, want the event and only the RSVP with an older stored response", importReport.Created)
+	}
+	skippedRSVPCount := 0
+	for _, skippedRecord := range importReport.Skipped {
+		if skippedRecord.Kind == KindRSVP {
+			skippedRSVPCount++
+		}
+	}
+	if skippedRSVPCount != len(testCases) {
+		t.Errorf("skipped %d RSVPs, want %d: %+v", skippedRSVPCount, len(testCases), importReport.Skipped)
+	}
+}
@@ -0,0 +1,130 @@
+// Package ratelimit throttles the endpoints anyone can reach without signing in. On the public invitation pages,
+// each client address and the installation as a whole get a token bucket, and clients that keep asking for
+// invitation codes that do not exist are locked out for exponentially growing periods, which makes guessing codes
+// impractical. Other endpoints, such as the sign-in link form, take quotas over longer periods with AllowQuota.
+package ratelimit
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/temirov/RSVP/pkg/config"
+	"log/slog"
+)
+
+// globalKey names the bucket shared by all clients. It cannot collide with a client address.
+const globalKey = "*"
+
+// maxBackoffDoublings bounds the exponent of the backoff so the shift cannot overflow.
+const maxBackoffDoublings = 20
+
+// Store keeps the state of the buckets, failure counts and lockouts. Every method receives the current time,
+// so implementations never read the clock themselves.
+type Store interface {
+	// Take removes a token from the bucket named key, which holds up to capacity tokens and refills at capacity
+	// tokens every refillPeriod. When the bucket is empty it returns false and how long until the next token.
+	Take(key string, capacity int, refillPeriod time.Duration, now time.Time) (bool, time.Duration)
+	// AddFailure records a failed lookup by key and returns the number of failures since the first one that is
+	// less than window old.
+	AddFailure(key string, window time.Duration, now time.Time) int
+	// Block locks key out until the given time.
+	Block(key string, until time.Time, now time.Time)
+	// BlockedUntil returns the end of key's lockout, or the zero time if it is not locked out.
+	BlockedUntil(key string, now time.Time) time.Time
+}
+
+// Stats counts what the limiter has seen since the process started.
+type Stats struct {
+	// RejectedRequests were refused because a limit was exceeded or the client was locked out.
+	RejectedRequests uint64
+	// FailedLookups asked for a missing, malformed or unknown invitation code.
+	FailedLookups uint64
+	// SuspectedEnumerations is the number of times a client reached the failure threshold.
+	SuspectedEnumerations uint64
+}
+
+// Limiter applies the configured limits to clients identified by their address.
+type Limiter struct {
+	settings config.RateLimitConfig
+	store    Store
+	logger   *slog.Logger
+	clock    func() time.Time
+
+	rejectedRequests      atomic.Uint64
+	failedLookups         atomic.Uint64
+	suspectedEnumerations atomic.Uint64
+}
+
+// NewLimiter creates a limiter keeping its state in the store.
+func NewLimiter(settings config.RateLimitConfig, store Store, logger *slog.Logger) *Limiter {
+	return &Limiter{settings: settings, store: store, logger: logger, clock: time.Now}
+}
+
+// Allow reports whether the client may be served now. When it may not, it also returns how long the client
+// should wait before trying again.
+func (limiter *Limiter) Allow(clientAddress string) (bool, time.Duration) {
+	now := limiter.clock()
+	if blockedUntil := limiter.store.BlockedUntil(clientAddress, now); blockedUntil.After(now) {
+		limiter.rejectedRequests.Add(1)
+		return false, blockedUntil.Sub(now)
+	}
+	if limiter.settings.PerClientPerMinute > 0 {
+		if allowed, retryAfter := limiter.store.Take(clientAddress, limiter.settings.PerClientPerMinute, time.Minute, now); !allowed {
+			limiter.rejectedRequests.Add(1)
+			return false, retryAfter
+		}
+	}
+	if limiter.settings.GlobalPerMinute > 0 {
+		if allowed, retryAfter := limiter.store.Take(globalKey, limiter.settings.GlobalPerMinute, time.Minute, now); !allowed {
+			limiter.rejectedRequests.Add(1)
+			return false, retryAfter
+		}
+	}
+	return true, 0
+}
+
+// AllowQuota reports whether key may make another request when it is allowed limit requests every period, with
+// the allowance refilling gradually. When it may not, it also returns how long to wait. A limit of zero allows
+// everything. Keys share the limiter's store, so callers limiting different things should name them apart.
+func (limiter *Limiter) AllowQuota(key string, limit int, period time.Duration) (bool, time.Duration) {
+	if limit <= 0 {
+		return true, 0
+	}
+	allowed, retryAfter := limiter.store.Take(key, limit, period, limiter.clock())
+	if !allowed {
+		limiter.rejectedRequests.Add(1)
+	}
+	return allowed, retryAfter
+}
+
+// RecordFailedLookup counts a request for an invitation code that does not exist. Once the client reaches the
+// failure threshold within the failure window, every further failure locks it out for twice as long as the
+// previous one, up to the maximum backoff.
+func (limiter *Limiter) RecordFailedLookup(clientAddress string) {
+	limiter.failedLookups.Add(1)
+	if limiter.settings.FailureThreshold <= 0 {
+		return
+	}
+	now := limiter.clock()
+	failureCount := limiter.store.AddFailure(clientAddress, config.LookupFailureWindow, now)
+	if failureCount < limiter.settings.FailureThreshold {
+		return
+	}
+	if failureCount == limiter.settings.FailureThreshold {
+		limiter.suspectedEnumerations.Add(1)
+		limiter.logger.Warn("Suspected invitation code enumeration", "client_ip", clientAddress,
+			"unknown_codes", failureCount, "window", time.Duration(config.LookupFailureWindow))
+	}
+	backoffDoublings := min(failureCount-limiter.settings.FailureThreshold, maxBackoffDoublings)
+	backoff := min(time.Duration(config.LookupBackoffBase)<<backoffDoublings, time.Duration(config.LookupBackoffMax))
+	limiter.store.Block(clientAddress, now.Add(backoff), now)
+}
+
+// Stats returns the counters collected so far.
+func (limiter *Limiter) Stats() Stats {
+	return Stats{
+		RejectedRequests:      limiter.rejectedRequests.Load(),
+		FailedLookups:         limiter.failedLookups.Load(),
+		SuspectedEnumerations: limiter.suspectedEnumerations.Load(),
+	}
+}
@@ -0,0 +1,155 @@
+package ratelimit
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/temirov/RSVP/pkg/config"
+)
+
+// testClock is a clock the tests move by hand.
+type testClock struct {
+	now time.Time
+}
+
+func (clock *testClock) Now() time.Time {
+	return clock.now
+}
+
+func (clock *testClock) Advance(elapsed time.Duration) {
+	clock.now = clock.now.Add(elapsed)
+}
+
+func newTestLimiter(settings config.RateLimitConfig) (*Limiter, *testClock) {
+	clock := &testClock{now: time.Date(2026, time.January, 1, 12, 0, 0, 0, time.UTC)}
+	limiter := NewLimiter(settings, NewMemoryStore(time.Hour), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	limiter.clock = clock.Now
+	return limiter, clock
+}
+
+func TestLimiterAllowsBurstThenRefills(t *testing.T) {
+	limiter, clock := newTestLimiter(config.RateLimitConfig{PerClientPerMinute: 3})
+	for requestIndex := 0; requestIndex < 3; requestIndex++ {
+		if allowed, _ := limiter.Allow("198.51.100.1"); !allowed {
+			t.Fatalf("request %d of the burst was refused", requestIndex+1)
+		}
+	}
+	allowed, retryAfter := limiter.Allow("198.51.100.1")
+	if allowed {
+		t.Fatal("request beyond the burst was allowed")
+	}
+	if retryAfter != 20*time.Second {
+		t.Errorf("retryAfter = %v, want 20s for 3 tokens a minute", retryAfter)
+	}
+	if allowed, _ := limiter.Allow("198.51.100.2"); !allowed {
+		t.Error("another client was refused by the first client's limit")
+	}
+	clock.Advance(retryAfter)
+	if allowed, _ := limiter.Allow("198.51.100.1"); !allowed {
+		t.Error("request after the refill was refused")
+	}
+	if allowed, _ := limiter.Allow("198.51.100.1"); allowed {
+		t.Error("a single refill allowed two requests")
+	}
+	if rejected := limiter.Stats().RejectedRequests; rejected != 2 {
+		t.Errorf("RejectedRequests = %d, want 2", rejected)
+	}
+}
+
+func TestLimiterGlobalLimitCoversAllClients(t *testing.T) {
+	limiter, _ := newTestLimiter(config.RateLimitConfig{PerClientPerMinute: 10, GlobalPerMinute: 2})
+	limiter.Allow("198.51.100.1")
+	limiter.Allow("198.51.100.2")
+	if allowed, _ := limiter.Allow("198.51.100.3"); allowed {
+		t.Error("a third client was allowed past the global limit")
+	}
+}
+
+func TestLimiterZeroDisablesLimits(t *testing.T) {
+	limiter, _ := newTestLimiter(config.RateLimitConfig{})
+	for requestIndex := 0; requestIndex < 1000; requestIndex++ {
+		if allowed, _ := limiter.Allow("198.51.100.1"); !allowed {
+			t.Fatalf("request %d was refused with every limit disabled", requestIndex+1)
+		}
+		limiter.RecordFailedLookup("198.51.100.1")
+	}
+}
+
+func TestLimiterLocksOutWithGrowingBackoff(t *testing.T) {
+	limiter, clock := newTestLimiter(config.RateLimitConfig{FailureThreshold: 3})
+	limiter.RecordFailedLookup("198.51.100.1")
+	limiter.RecordFailedLookup("198.51.100.1")
+	if allowed, _ := limiter.Allow("198.51.100.1"); !allowed {
+		t.Fatal("client was locked out before reaching the threshold")
+	}
+
+	expectedBackoffs := []time.Duration{
+		time.Duration(config.LookupBackoffBase),
+		2 * time.Duration(config.LookupBackoffBase),
+		4 * time.Duration(config.LookupBackoffBase),
+	}
+	for failureIndex, expectedBackoff := range expectedBackoffs {
+		limiter.RecordFailedLookup("198.51.100.1")
+		allowed, retryAfter := limiter.Allow("198.51.100.1")
+		if allowed {
+			t.Fatalf("failure %d past the threshold did not lock the client out", failureIndex+1)
+		}
+		if retryAfter != expectedBackoff {
+			t.Errorf("failure %d past the threshold: retryAfter = %v, want %v", failureIndex+1, retryAfter, expectedBackoff)
+		}
+		clock.Advance(retryAfter)
+		if allowed, _ := limiter.Allow("198.51.100.1"); !allowed {
+			t.Fatalf("client was still locked out after %v", retryAfter)
+		}
+	}
+	if allowed, _ := limiter.Allow("198.51.100.2"); !allowed {
+		t.Error("another client was locked out")
+	}
+
+	stats := limiter.Stats()
+	if stats.FailedLookups != 5 || stats.SuspectedEnumerations != 1 {
+		t.Errorf("Stats() = %+v, want 5 failed lookups and 1 suspected enumeration", stats)
+	}
+}
+
+func TestLimiterBackoffIsCapped(t *testing.T) {
+	limiter, _ := newTestLimiter(config.RateLimitConfig{FailureThreshold: 1})
+	for failureIndex := 0; failureIndex < 100; failureIndex++ {
+		limiter.RecordFailedLookup("198.51.100.1")
+	}
+	if _, retryAfter := limiter.Allow("198.51.100.1"); retryAfter != time.Duration(config.LookupBackoffMax) {
+		t.Errorf("retryAfter = %v, want the maximum backoff %v", retryAfter, time.Duration(config.LookupBackoffMax))
+	}
+}
+
+func TestLimiterFailuresExpireWithTheWindow(t *testing.T) {
+	limiter, clock := newTestLimiter(config.RateLimitConfig{FailureThreshold: 2})
+	limiter.RecordFailedLookup("198.51.100.1")
+	clock.Advance(time.Duration(config.LookupFailureWindow) + time.Second)
+	limiter.RecordFailedLookup("198.51.100.1")
+	if allowed, _ := limiter.Allow("198.51.100.1"); !allowed {
+		t.Error("failures from an earlier window counted toward the lockout")
+	}
+}
+
+func TestLimiterAllowQuota(t *testing.T) {
+	limiter, clock := newTestLimiter(config.RateLimitConfig{})
+	for requestIndex := 0; requestIndex < 2; requestIndex++ {
+		if allowed, _ := limiter.AllowQuota("recipient:a@example.com", 2, time.Hour); !allowed {
+			t.Fatalf("request %d within the quota was refused", requestIndex+1)
+		}
+	}
+	allowed, retryAfter := limiter.AllowQuota("recipient:a@example.com", 2, time.Hour)
+	if allowed || retryAfter != 30*time.Minute {
+		t.Fatalf("AllowQuota() = %v, %v, want a refusal for 30m", allowed, retryAfter)
+	}
+	clock.Advance(30 * time.Minute)
+	if allowed, _ := limiter.AllowQuota("recipient:a@example.com", 2, time.Hour); !allowed {
+		t.Error("request after the quota refilled was refused")
+	}
+	if allowed, _ := limiter.AllowQuota("recipient:a@example.com", 0, time.Hour); !allowed {
+		t.Error("a zero quota refused a request")
+	}
+}
@@ -0,0 +1,105 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often the memory store forgets clients it has not seen for a while.
+const sweepInterval = time.Minute
+
+// clientState is everything the memory store knows about one key.
+type clientState struct {
+	tokens             float64
+	refilledAt         time.Time
+	failureCount       int
+	failureWindowStart time.Time
+	blockedUntil       time.Time
+	lastSeen           time.Time
+}
+
+// MemoryStore keeps the limiter's state in process memory. Limits are therefore per instance and start over
+// when the server restarts.
+type MemoryStore struct {
+	mutex       sync.Mutex
+	clients     map[string]*clientState
+	idleTimeout time.Duration
+	lastSweep   time.Time
+}
+
+// NewMemoryStore creates an empty store that forgets clients after idleTimeout without requests or lockout.
+func NewMemoryStore(idleTimeout time.Duration) *MemoryStore {
+	return &MemoryStore{clients: make(map[string]*clientState), idleTimeout: idleTimeout}
+}
+
+// Take removes a token from the bucket named key.
+func (memoryStore *MemoryStore) Take(key string, capacity int, refillPeriod time.Duration, now time.Time) (bool, time.Duration) {
+	memoryStore.mutex.Lock()
+	defer memoryStore.mutex.Unlock()
+	state, known := memoryStore.stateFor(key, now)
+	tokensPerSecond := float64(capacity) / refillPeriod.Seconds()
+	if !known {
+		state.tokens = float64(capacity)
+	} else {
+		state.tokens = min(float64(capacity), state.tokens+now.Sub(state.refilledAt).Seconds()*tokensPerSecond)
+	}
+	state.refilledAt = now
+	if state.tokens < 1 {
+		return false, time.Duration((1 - state.tokens) / tokensPerSecond * float64(time.Second))
+	}
+	state.tokens--
+	return true, 0
+}
+
+// AddFailure records a failed lookup by key.
+func (memoryStore *MemoryStore) AddFailure(key string, window time.Duration, now time.Time) int {
+	memoryStore.mutex.Lock()
+	defer memoryStore.mutex.Unlock()
+	state, _ := memoryStore.stateFor(key, now)
+	if state.failureCount == 0 || now.Sub(state.failureWindowStart) > window {
+		state.failureCount = 0
+		state.failureWindowStart = now
+	}
+	state.failureCount++
+	return state.failureCount
+}
+
+// Block locks key out until the given time.
+func (memoryStore *MemoryStore) Block(key string, until time.Time, now time.Time) {
+	memoryStore.mutex.Lock()
+	defer memoryStore.mutex.Unlock()
+	state, _ := memoryStore.stateFor(key, now)
+	state.blockedUntil = until
+}
+
+// BlockedUntil returns the end of key's lockout.
+func (memoryStore *MemoryStore) BlockedUntil(key string, now time.Time) time.Time {
+	memoryStore.mutex.Lock()
+	defer memoryStore.mutex.Unlock()
+	if state, known := memoryStore.clients[key]; known {
+		return state.blockedUntil
+	}
+	return time.Time{}
+}
+
+// stateFor returns the state of key, creating it if needed, and reports whether it already existed. It also
+// sweeps idle clients now and then so the map does not grow without bound. The caller holds the mutex.
+func (memoryStore *MemoryStore) stateFor(key string, now time.Time) (*clientState, bool) {
+	if now.Sub(memoryStore.lastSweep) >= sweepInterval {
+		for clientKey, idleState := range memoryStore.clients {
+			if now.Sub(idleState.lastSeen) > memoryStore.idleTimeout && !idleState.blockedUntil.After(now) {
+				delete(memoryStore.clients, clientKey)
+			}
+		}
+		memoryStore.lastSweep = now
+	}
+	state, known := memoryStore.clients[key]
+	if !known {
+		state = &clientState{}
+		memoryStore.clients[key] = state
+	}
+	if now.After(state.lastSeen) {
+		state.lastSeen = now
+	}
+	return state, known
+}
@@ -0,0 +1,70 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreTakeRefillsGradually(t *testing.T) {
+	memoryStore := NewMemoryStore(time.Hour)
+	startTime := time.Date(2026, time.January, 1, 12, 0, 0, 0, time.UTC)
+	for takeIndex := 0; takeIndex < 2; takeIndex++ {
+		if taken, _ := memoryStore.Take("client", 2, time.Minute, startTime); !taken {
+			t.Fatalf("take %d from a full bucket failed", takeIndex+1)
+		}
+	}
+	if taken, retryAfter := memoryStore.Take("client", 2, time.Minute, startTime.Add(15*time.Second)); taken || retryAfter != 15*time.Second {
+		t.Errorf("Take() from a half-refilled token = %v, %v, want false, 15s", taken, retryAfter)
+	}
+	if taken, _ := memoryStore.Take("client", 2, time.Minute, startTime.Add(30*time.Second)); !taken {
+		t.Error("Take() after a full token refilled failed")
+	}
+	if taken, _ := memoryStore.Take("client", 2, time.Minute, startTime.Add(10*time.Minute)); !taken {
+		t.Error("Take() after a long pause failed")
+	}
+	if taken, _ := memoryStore.Take("client", 2, time.Minute, startTime.Add(10*time.Minute)); !taken {
+		t.Error("the bucket did not refill to its capacity")
+	}
+	if taken, _ := memoryStore.Take("client", 2, time.Minute, startTime.Add(10*time.Minute)); taken {
+		t.Error("the bucket refilled past its capacity")
+	}
+}
+
+func TestMemoryStoreAddFailureCountsWithinWindow(t *testing.T) {
+	memoryStore := NewMemoryStore(time.Hour)
+	startTime := time.Date(2026, time.January, 1, 12, 0, 0, 0, time.UTC)
+	for expectedCount := 1; expectedCount <= 3; expectedCount++ {
+		if failureCount := memoryStore.AddFailure("client", time.Hour, startTime.Add(time.Duration(expectedCount)*time.Minute)); failureCount != expectedCount {
+			t.Fatalf("AddFailure() = %d, want %d", failureCount, expectedCount)
+		}
+	}
+	if failureCount := memoryStore.AddFailure("client", time.Hour, startTime.Add(2*time.Hour)); failureCount != 1 {
+		t.Errorf("AddFailure() after the window = %d, want a new count of 1", failureCount)
+	}
+}
+
+func TestMemoryStoreBlock(t *testing.T) {
+	memoryStore := NewMemoryStore(time.Hour)
+	startTime := time.Date(2026, time.January, 1, 12, 0, 0, 0, time.UTC)
+	if blockedUntil := memoryStore.BlockedUntil("client", startTime); !blockedUntil.IsZero() {
+		t.Errorf("BlockedUntil() of an unknown client = %v, want the zero time", blockedUntil)
+	}
+	memoryStore.Block("client", startTime.Add(time.Minute), startTime)
+	if blockedUntil := memoryStore.BlockedUntil("client", startTime); !blockedUntil.Equal(startTime.Add(time.Minute)) {
+		t.Errorf("BlockedUntil() = %v, want %v", blockedUntil, startTime.Add(time.Minute))
+	}
+}
+
+func TestMemoryStoreForgetsIdleClients(t *testing.T) {
+	memoryStore := NewMemoryStore(time.Minute)
+	startTime := time.Date(2026, time.January, 1, 12, 0, 0, 0, time.UTC)
+	memoryStore.Take("idle", 1, time.Minute, startTime)
+	memoryStore.Block("blocked", startTime.Add(time.Hour), startTime)
+	memoryStore.Take("other", 1, time.Minute, startTime.Add(5*time.Minute))
+	if _, known := memoryStore.clients["idle"]; known {
+		t.Error("an idle client was not forgotten")
+	}
+	if _, known := memoryStore.clients["blocked"]; !known {
+		t.Error("a locked-out client was forgotten before its lockout ended")
+	}
+}
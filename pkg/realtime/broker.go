@@ -0,0 +1,135 @@
+// Package realtime provides in-process fan-out of live updates to connected organizer browsers.
+// Updates are delivered over Server-Sent Events by the stream handlers; this package only handles
+// subscription bookkeeping and never blocks publishers on slow consumers.
+package realtime
+
+import (
+	"sync"
+)
+
+// SubscriberBufferSize is the number of pending updates a subscriber may accumulate before it is dropped.
+// Dropped subscribers see their channel closed; browsers reconnect automatically via EventSource.
+const SubscriberBufferSize = 32
+
+// Update kinds published by the application.
+const (
+	KindRSVPCreated = "rsvp-created"
+	KindRSVPUpdated = "rsvp-updated"
+	KindRSVPDeleted = "rsvp-deleted"
+	// KindRSVPCheckedIn is an RSVP updated by check-in staff, who record guests' responses as they arrive.
+	KindRSVPCheckedIn = "rsvp-checked-in"
+)
+
+// RSVPSnapshot is the subset of RSVP fields needed to render or update a row in the RSVP list.
+type RSVPSnapshot struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Response    string `json:"response"`
+	ExtraGuests int    `json:"extraGuests"`
+}
+
+// Update is a single change notification for an event.
+type Update struct {
+	Kind              string        `json:"kind"`
+	EventID           string        `json:"eventId"`
+	RSVP              *RSVPSnapshot `json:"rsvp,omitempty"`
+	RSVPCount         int64         `json:"rsvpCount"`
+	RSVPAnsweredCount int64         `json:"rsvpAnsweredCount"`
+}
+
+// Subscription is a live feed of updates for one topic.
+// Updates is closed when the subscription is cancelled or when the subscriber falls too far behind.
+type Subscription struct {
+	Updates <-chan Update
+	topic   string
+	channel chan Update
+}
+
+// Broker fans updates out to subscribers keyed by topic.
+// A topic is either a single event (EventTopic) or all events of one organizer (OwnerTopic).
+type Broker struct {
+	mutex       sync.Mutex
+	subscribers map[string]map[*Subscription]struct{}
+}
+
+// NewBroker creates an empty Broker.
+func NewBroker() *Broker {
+	return &Broker{subscribers: make(map[string]map[*Subscription]struct{})}
+}
+
+// EventTopic returns the topic carrying updates for a single event.
+func EventTopic(eventID string) string {
+	return "event:" + eventID
+}
+
+// OwnerTopic returns the topic carrying updates for every event a user owns, co-hosts or reaches through an organization.
+func OwnerTopic(userID string) string {
+	return "owner:" + userID
+}
+
+// Subscribe registers a new subscriber for the topic. Callers must call Unsubscribe when done.
+func (broker *Broker) Subscribe(topic string) *Subscription {
+	updateChannel := make(chan Update, SubscriberBufferSize)
+	subscription := &Subscription{Updates: updateChannel, topic: topic, channel: updateChannel}
+
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+	topicSubscribers, exists := broker.subscribers[topic]
+	if !exists {
+		topicSubscribers = make(map[*Subscription]struct{})
+		broker.subscribers[topic] = topicSubscribers
+	}
+	topicSubscribers[subscription] = struct{}{}
+	return subscription
+}
+
+// Unsubscribe removes the subscriber and closes its channel. It is safe to call more than once
+// and after the broker has already dropped a lagging subscriber.
+func (broker *Broker) Unsubscribe(subscription *Subscription) {
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+	broker.removeLocked(subscription)
+}
+
+// Publish delivers the update to every subscriber of the given topics without blocking.
+// Subscribers whose buffer is full are dropped so one stalled connection cannot hold up the others.
+func (broker *Broker) Publish(update Update, topics ...string) {
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+	for _, topic := range topics {
+		for subscription := range broker.subscribers[topic] {
+			select {
+			case subscription.channel <- update:
+			default:
+				broker.removeLocked(subscription)
+			}
+		}
+	}
+}
+
+// SubscriberCount returns the number of active subscribers across all topics.
+func (broker *Broker) SubscriberCount() int {
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+	subscriberCount := 0
+	for _, topicSubscribers := range broker.subscribers {
+		subscriberCount += len(topicSubscribers)
+	}
+	return subscriberCount
+}
+
+// removeLocked detaches the subscription and closes its channel; the broker mutex must be held.
+func (broker *Broker) removeLocked(subscription *Subscription) {
+	topicSubscribers, exists := broker.subscribers[subscription.topic]
+	if !exists {
+		return
+	}
+	if _, isSubscribed := topicSubscribers[subscription]; !isSubscribed {
+		return
+	}
+	delete(topicSubscribers, subscription)
+	close(subscription.channel)
+	if len(topicSubscribers) == 0 {
+		delete(broker.subscribers, subscription.topic)
+	}
+}
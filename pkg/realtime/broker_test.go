@@ -0,0 +1,73 @@
+package realtime
+
+import "testing"
+
+func TestPublishReachesOnlyTheGivenTopics(t *testing.T) {
+	broker := NewBroker()
+	eventSubscription := broker.Subscribe(EventTopic("ev1"))
+	ownerSubscription := broker.Subscribe(OwnerTopic("user1"))
+	otherSubscription := broker.Subscribe(OwnerTopic("user2"))
+	defer broker.Unsubscribe(eventSubscription)
+	defer broker.Unsubscribe(ownerSubscription)
+	defer broker.Unsubscribe(otherSubscription)
+
+	broker.Publish(Update{Kind: KindRSVPCreated, EventID: "ev1"}, EventTopic("ev1"), OwnerTopic("user1"))
+	for _, subscription := range []*Subscription{eventSubscription, ownerSubscription} {
+		select {
+		case liveUpdate := <-subscription.Updates:
+			if liveUpdate.Kind != KindRSVPCreated || liveUpdate.EventID != "ev1" {
+				t.Errorf("subscriber of %s got %+v", subscription.topic, liveUpdate)
+			}
+		default:
+			t.Errorf("subscriber of %s got nothing", subscription.topic)
+		}
+	}
+	select {
+	case liveUpdate := <-otherSubscription.Updates:
+		t.Errorf("subscriber of another topic got %+v", liveUpdate)
+	default:
+	}
+}
+
+func TestPublishDropsASlowSubscriber(t *testing.T) {
+	broker := NewBroker()
+	slowSubscription := broker.Subscribe(EventTopic("ev1"))
+	readingSubscription := broker.Subscribe(EventTopic("ev1"))
+	defer broker.Unsubscribe(readingSubscription)
+
+	for updateIndex := 0; updateIndex <= SubscriberBufferSize; updateIndex++ {
+		broker.Publish(Update{Kind: KindRSVPUpdated, EventID: "ev1"}, EventTopic("ev1"))
+		<-readingSubscription.Updates
+	}
+	if subscriberCount := broker.SubscriberCount(); subscriberCount != 1 {
+		t.Fatalf("SubscriberCount() = %d, want only the reading subscriber", subscriberCount)
+	}
+	receivedCount := 0
+	for range slowSubscription.Updates {
+		receivedCount++
+	}
+	if receivedCount != SubscriberBufferSize {
+		t.Errorf("the dropped subscriber received %d buffered updates before its channel closed, want %d", receivedCount, SubscriberBufferSize)
+	}
+	// Unsubscribing a dropped subscriber is harmless.
+	broker.Unsubscribe(slowSubscription)
+}
+
+func TestUnsubscribeTwice(t *testing.T) {
+	broker := NewBroker()
+	subscription := broker.Subscribe(OwnerTopic("user1"))
+	keptSubscription := broker.Subscribe(OwnerTopic("user1"))
+	broker.Unsubscribe(subscription)
+	broker.Unsubscribe(subscription)
+	if _, isOpen := <-subscription.Updates; isOpen {
+		t.Error("the channel of an unsubscribed subscriber is still open")
+	}
+	if subscriberCount := broker.SubscriberCount(); subscriberCount != 1 {
+		t.Errorf("SubscriberCount() = %d, want 1", subscriberCount)
+	}
+	broker.Unsubscribe(keptSubscription)
+	if topicCount := len(broker.subscribers); topicCount != 0 {
+		t.Errorf("the broker keeps %d empty topics", topicCount)
+	}
+	broker.Publish(Update{Kind: KindRSVPDeleted}, OwnerTopic("user1"))
+}
@@ -2,36 +2,64 @@
 package routes
 
 import (
+	"fmt"
 	"github.com/temirov/GAuss/pkg/constants"
 	"github.com/temirov/GAuss/pkg/gauss"
 	"github.com/temirov/GAuss/pkg/session"
+	"github.com/temirov/RSVP/pkg/assets"
+	"github.com/temirov/RSVP/pkg/auth"
+	"github.com/temirov/RSVP/pkg/backup"
 	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/handlers"
+	"github.com/temirov/RSVP/pkg/handlers/account"
+	"github.com/temirov/RSVP/pkg/handlers/admin"
+	"github.com/temirov/RSVP/pkg/handlers/cohost"
 	"github.com/temirov/RSVP/pkg/handlers/event"
+	"github.com/temirov/RSVP/pkg/handlers/health"
+	"github.com/temirov/RSVP/pkg/handlers/organization"
 	"github.com/temirov/RSVP/pkg/handlers/response"
 	"github.com/temirov/RSVP/pkg/handlers/rsvp"
+	"github.com/temirov/RSVP/pkg/handlers/static"
+	"github.com/temirov/RSVP/pkg/handlers/token"
+	"github.com/temirov/RSVP/pkg/handlers/transfer"
+	"github.com/temirov/RSVP/pkg/handlers/trash"
 	"github.com/temirov/RSVP/pkg/handlers/venue"
+	"github.com/temirov/RSVP/pkg/logging"
+	"github.com/temirov/RSVP/pkg/metrics"
 	"github.com/temirov/RSVP/pkg/middleware"
+	"github.com/temirov/RSVP/pkg/ratelimit"
+	"github.com/temirov/RSVP/pkg/templates"
 	"github.com/temirov/RSVP/pkg/utils"
-	"html/template"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"net/http"
-	"path/filepath"
+	"os"
 )
 
 // Routes holds shared resources and environment configuration.
 type Routes struct {
 	ApplicationContext *config.ApplicationContext
 	EnvConfig          *config.EnvConfig
+	BackupManager      *backup.Manager
+	// AuthProviders holds the sign-in providers enabled by AUTH_PROVIDERS; it is set by RegisterMiddleware.
+	AuthProviders *auth.Providers
+	// PublicLimiter throttles the public invitation pages.
+	PublicLimiter *ratelimit.Limiter
+	// Readiness is marked by main when shutdown begins, which makes /readyz fail.
+	Readiness *health.Readiness
 }
 
 // New creates and returns a new Routes instance.
-func New(applicationContext *config.ApplicationContext, envConfig config.EnvConfig) *Routes {
+func New(applicationContext *config.ApplicationContext, envConfig config.EnvConfig, backupManager *backup.Manager) *Routes {
 	return &Routes{
 		ApplicationContext: applicationContext,
 		EnvConfig:          &envConfig,
+		BackupManager:      backupManager,
+		PublicLimiter:      ratelimit.NewLimiter(envConfig.RateLimit, ratelimit.NewMemoryStore(config.LookupFailureWindow), applicationContext.Logger),
+		Readiness:          &health.Readiness{},
 	}
 }
 
-// LandingPageHandler serves the landing page.
+// LandingPageHandler serves the landing page, or sends signed-in users to their events.
 func (appRoutes *Routes) LandingPageHandler(responseWriter http.ResponseWriter, request *http.Request) {
 	if request.URL.Path != config.WebRoot {
 		http.NotFound(responseWriter, request)
@@ -39,7 +67,7 @@ func (appRoutes *Routes) LandingPageHandler(responseWriter http.ResponseWriter,
 	}
 	webSession, sessionError := session.Store().Get(request, constants.SessionName)
 	if sessionError != nil {
-		appRoutes.ApplicationContext.Logger.Printf("ERROR: Session error on path %s: %v", request.URL.Path, sessionError)
+		logging.FromContext(request.Context()).Error("Reading the session failed", "path", request.URL.Path, "error", sessionError)
 	}
 	userEmail := ""
 	if webSession != nil && webSession.Values != nil {
@@ -51,20 +79,38 @@ func (appRoutes *Routes) LandingPageHandler(responseWriter http.ResponseWriter,
 		http.Redirect(responseWriter, request, config.WebEvents, http.StatusFound)
 		return
 	}
-	landingTemplatePath := filepath.Join(config.TemplatesDir, config.TemplateLanding+config.TemplateExtension)
-	landingTemplate, parseError := template.ParseFiles(landingTemplatePath)
-	if parseError != nil {
-		appRoutes.ApplicationContext.Logger.Printf("FATAL: Parsing landing template '%s' failed: %v", landingTemplatePath, parseError)
-		utils.HandleError(responseWriter, parseError, utils.ServerError, appRoutes.ApplicationContext.Logger, "Could not display the page.")
+	appRoutes.LoginPageHandler(responseWriter, request)
+}
+
+// LoginPageHandler serves the landing page with the sign-in options of the enabled providers.
+func (appRoutes *Routes) LoginPageHandler(responseWriter http.ResponseWriter, request *http.Request) {
+	landingTemplate, isLoaded := templates.PrecompiledTemplatesMap[config.TemplateLanding]
+	if !isLoaded {
+		utils.HandleError(responseWriter, request, fmt.Errorf("landing template %q is not loaded", config.TemplateLanding), utils.ServerError, "Could not display the page.")
 		return
 	}
 	templateData := map[string]interface{}{
-		config.ErrorQueryParam: request.URL.Query().Get(config.ErrorQueryParam),
+		config.ErrorQueryParam:  request.URL.Query().Get(config.ErrorQueryParam),
+		config.NoticeQueryParam: request.URL.Query().Get(config.NoticeQueryParam),
+		"appTitle":              appRoutes.ApplicationContext.AppTitle,
+		"signIn":                appRoutes.AuthProviders.LoginPageData(),
+		"devAuthLabel":          devAuthLabel(appRoutes.ApplicationContext),
+		"csrfParam":             config.CSRFTokenParam,
+		"csrfToken":             middleware.CSRFTokenFromContext(request.Context()),
+		"cspNonce":              middleware.CSPNonceFromContext(request.Context()),
 	}
 	executeError := landingTemplate.Execute(responseWriter, templateData)
 	if executeError != nil {
-		appRoutes.ApplicationContext.Logger.Printf("ERROR: Executing landing template '%s' failed: %v", landingTemplatePath, executeError)
+		logging.FromContext(request.Context()).Error("Executing the landing template failed", "template", config.TemplateLanding, "error", executeError)
+	}
+}
+
+// devAuthLabel returns the label marking pages in development sign-in mode, or an empty string otherwise.
+func devAuthLabel(applicationContext *config.ApplicationContext) string {
+	if applicationContext.DevAuth {
+		return config.LabelDevAuth
 	}
+	return ""
 }
 
 // ApplyOverrides applies HTTP method override.
@@ -74,7 +120,7 @@ func (appRoutes *Routes) ApplyOverrides(next http.Handler) http.Handler {
 			if request.Form == nil {
 				request.Body = http.MaxBytesReader(responseWriter, request.Body, 10<<20)
 				if parseError := request.ParseForm(); parseError != nil {
-					appRoutes.ApplicationContext.Logger.Printf("WARN: Parsing form data for %s failed: %v", request.URL.Path, parseError)
+					logging.FromContext(request.Context()).Warn("Parsing form data failed", "path", request.URL.Path, "error", parseError)
 				}
 			}
 			methodOverride := request.FormValue(config.MethodOverrideParam)
@@ -83,9 +129,9 @@ func (appRoutes *Routes) ApplyOverrides(next http.Handler) http.Handler {
 				case http.MethodPut, http.MethodPatch, http.MethodDelete:
 					originalMethod := request.Method
 					request.Method = methodOverride
-					appRoutes.ApplicationContext.Logger.Printf("DEBUG: Method overridden from %s to %s for %s", originalMethod, request.Method, request.URL.Path)
+					logging.FromContext(request.Context()).Debug("Method overridden", "from", originalMethod, "to", request.Method, "path", request.URL.Path)
 				default:
-					appRoutes.ApplicationContext.Logger.Printf("WARN: Invalid _method override '%s' for %s", methodOverride, request.URL.Path)
+					logging.FromContext(request.Context()).Warn("Invalid _method override", "method", methodOverride, "path", request.URL.Path)
 				}
 			}
 		}
@@ -98,45 +144,80 @@ func (appRoutes *Routes) publicChainWithOverride(handler http.Handler) http.Hand
 	return appRoutes.ApplyOverrides(handler)
 }
 
-// RegisterMiddleware registers authentication and session middleware.
+// RegisterMiddleware registers the session store, the sign-in page and the routes of the enabled sign-in providers.
 func (appRoutes *Routes) RegisterMiddleware(mux *http.ServeMux) {
 	session.NewSession([]byte(appRoutes.EnvConfig.SessionSecret))
-	landingTemplatePath := filepath.Join(config.TemplatesDir, config.TemplateLanding+config.TemplateExtension)
-	authenticationService, authServiceError := gauss.NewService(
-		appRoutes.EnvConfig.GoogleClientID,
-		appRoutes.EnvConfig.GoogleClientSecret,
-		appRoutes.EnvConfig.GoogleOauth2Base,
-		config.WebEvents,
-		landingTemplatePath,
-	)
-	if authServiceError != nil {
-		appRoutes.ApplicationContext.Logger.Fatalf("FATAL: Initializing auth service failed: %v", authServiceError)
-	}
-	gaussHandlers, gaussHandlersError := gauss.NewHandlers(authenticationService)
-	if gaussHandlersError != nil {
-		appRoutes.ApplicationContext.Logger.Fatalf("FATAL: Initializing auth handlers failed: %v", gaussHandlersError)
-	}
-	gaussHandlers.RegisterRoutes(mux)
-	appRoutes.ApplicationContext.Logger.Println("GAuss authentication middleware and routes registered.")
+	authProviders, authProvidersError := auth.NewProviders(appRoutes.EnvConfig, appRoutes.ApplicationContext.Database, appRoutes.ApplicationContext.Logger)
+	if authProvidersError != nil {
+		appRoutes.ApplicationContext.Logger.Error("Initializing sign-in providers failed", "error", authProvidersError)
+		os.Exit(1)
+	}
+	appRoutes.AuthProviders = authProviders
+	authProviders.RegisterRoutes(mux)
+	mux.HandleFunc(config.WebLogin, appRoutes.LoginPageHandler)
+	appRoutes.ApplicationContext.Logger.Info("Authentication middleware and sign-in routes registered")
+}
+
+// WrapHandler applies the middleware that every request passes through, whatever its route.
+func (appRoutes *Routes) WrapHandler(mux *http.ServeMux) http.Handler {
+	protectFromForgery := middleware.ProtectFromForgery(appRoutes.ApplicationContext, handlers.ForgeryRejectedHandler(appRoutes.ApplicationContext))
+	// Security headers come before the forgery check, so that the rejection page is covered by the policy and has a nonce.
+	securityHeaders := middleware.SecurityHeaders(appRoutes.EnvConfig.SecurityHeaders)
+	// Request logging comes first, so that every other middleware logs with the request ID and every response is logged.
+	// Metrics are recorded inside request logging, so that the time spent writing the access log is not counted.
+	recordMetrics := middleware.RecordMetrics(appRoutes.ApplicationContext.Metrics, mux)
+	logRequests := middleware.LogRequests(appRoutes.ApplicationContext.Logger)
+	// The client address is resolved before anything logs, limits or records it.
+	resolveClientAddress := middleware.ResolveClientAddress(appRoutes.EnvConfig.Server.TrustedProxies)
+	wrappedHandler := resolveClientAddress(logRequests(recordMetrics(securityHeaders(protectFromForgery(mux)))))
+	if !appRoutes.EnvConfig.Tracing.Enabled() {
+		return wrappedHandler
+	}
+	// Tracing wraps everything else, so that request logging can tag its lines with the trace ID. Spans are named
+	// after the matching route pattern rather than the path, like the metrics.
+	return otelhttp.NewHandler(wrappedHandler, "http.request",
+		otelhttp.WithSpanNameFormatter(func(_ string, request *http.Request) string {
+			_, routePattern := mux.Handler(request)
+			if routePattern == "" {
+				return request.Method
+			}
+			return request.Method + " " + routePattern
+		}))
 }
 
 // RegisterRoutes registers all application routes.
 func (appRoutes *Routes) RegisterRoutes(mux *http.ServeMux) {
 	authRequired := gauss.AuthMiddleware
-	addUserMiddleware := middleware.AddUserToContext(appRoutes.ApplicationContext)
+	addUserMiddleware := middleware.AddUserToContext(appRoutes.ApplicationContext, appRoutes.EnvConfig.Access, appRoutes.EnvConfig.AdminEmails)
 	applyOverrides := appRoutes.ApplyOverrides
+	sessionChain := func(handler http.Handler) http.Handler {
+		return authRequired(addUserMiddleware(handler))
+	}
+	bearerOrSession := middleware.AuthenticateBearerToken(appRoutes.ApplicationContext, sessionChain)
+	resolveWorkspace := middleware.ResolveWorkspace(appRoutes.ApplicationContext)
 	protectedChain := func(handler http.Handler) http.Handler {
-		return authRequired(addUserMiddleware(applyOverrides(handler)))
+		return bearerOrSession(applyOverrides(resolveWorkspace(handler)))
+	}
+	sessionOnlyChain := func(handler http.Handler) http.Handler {
+		return sessionChain(applyOverrides(resolveWorkspace(handler)))
 	}
 	mux.HandleFunc(config.WebRoot, appRoutes.LandingPageHandler)
+	// Probes carry no session or token, so the health endpoints sit outside every authentication chain.
+	mux.HandleFunc(config.WebHealthz, health.LivenessHandler(appRoutes.ApplicationContext))
+	mux.HandleFunc(config.WebReadyz, health.ReadinessHandler(appRoutes.ApplicationContext, appRoutes.EnvConfig.SessionSecret, appRoutes.Readiness))
+	// Stylesheets and images are needed by the sign-in page and the public invitation pages, so they are public too.
+	staticFiles, staticFilesError := assets.Static(appRoutes.ApplicationContext.Assets)
+	if staticFilesError != nil {
+		appRoutes.ApplicationContext.Logger.Error("Opening the static files failed", "error", staticFilesError)
+		os.Exit(1)
+	}
+	mux.HandleFunc(config.WebStatic, static.Handler(appRoutes.ApplicationContext, staticFiles))
 	responseBaseDispatcher := http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
-		appRoutes.ApplicationContext.Logger.Printf("Router: Public path %s, method %s", request.URL.Path, request.Method)
-		response.Handler(appRoutes.ApplicationContext).ServeHTTP(responseWriter, request)
+		response.Handler(appRoutes.ApplicationContext, appRoutes.PublicLimiter).ServeHTTP(responseWriter, request)
 	})
 	mux.Handle(config.WebResponse, appRoutes.publicChainWithOverride(responseBaseDispatcher))
-	mux.HandleFunc(config.WebResponseThankYou, response.ThankYouHandler(appRoutes.ApplicationContext))
+	mux.HandleFunc(config.WebResponseThankYou, response.ThankYouHandler(appRoutes.ApplicationContext, appRoutes.PublicLimiter))
 	eventBaseDispatcher := http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
-		appRoutes.ApplicationContext.Logger.Printf("Router: Protected path %s, method %s", request.URL.Path, request.Method)
 		switch request.Method {
 		case http.MethodGet:
 			event.ListEventsHandler(appRoutes.ApplicationContext).ServeHTTP(responseWriter, request)
@@ -147,13 +228,52 @@ func (appRoutes *Routes) RegisterRoutes(mux *http.ServeMux) {
 		case http.MethodDelete:
 			event.DeleteHandler(appRoutes.ApplicationContext).ServeHTTP(responseWriter, request)
 		default:
-			utils.HandleError(responseWriter, nil, utils.MethodNotAllowedError, appRoutes.ApplicationContext.Logger, http.StatusText(http.StatusMethodNotAllowed))
+			utils.HandleError(responseWriter, request, nil, utils.MethodNotAllowedError, http.StatusText(http.StatusMethodNotAllowed))
 		}
 	})
 	mux.Handle(config.WebEvents, protectedChain(eventBaseDispatcher))
-	mux.Handle(config.WebRSVPQR, authRequired(addUserMiddleware(http.HandlerFunc(rsvp.ShowHandler(appRoutes.ApplicationContext)))))
+	cohostBaseDispatcher := http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		switch request.Method {
+		case http.MethodGet:
+			cohost.ListHandler(appRoutes.ApplicationContext).ServeHTTP(responseWriter, request)
+		case http.MethodPost:
+			cohost.InviteHandler(appRoutes.ApplicationContext).ServeHTTP(responseWriter, request)
+		case http.MethodDelete:
+			cohost.RemoveHandler(appRoutes.ApplicationContext).ServeHTTP(responseWriter, request)
+		default:
+			utils.HandleError(responseWriter, request, nil, utils.MethodNotAllowedError, http.StatusText(http.StatusMethodNotAllowed))
+		}
+	})
+	// Membership and ownership changes are session-only, like token management, so a leaked token cannot hand
+	// events to someone else.
+	mux.Handle(config.WebEventCohosts, sessionOnlyChain(cohostBaseDispatcher))
+	organizationBaseDispatcher := http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		switch request.Method {
+		case http.MethodGet:
+			organization.ListHandler(appRoutes.ApplicationContext).ServeHTTP(responseWriter, request)
+		case http.MethodPost:
+			organization.CreateHandler(appRoutes.ApplicationContext).ServeHTTP(responseWriter, request)
+		default:
+			utils.HandleError(responseWriter, request, nil, utils.MethodNotAllowedError, http.StatusText(http.StatusMethodNotAllowed))
+		}
+	})
+	mux.Handle(config.WebOrganizations, protectedChain(organizationBaseDispatcher))
+	organizationMemberDispatcher := http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		switch request.Method {
+		case http.MethodPost:
+			organization.InviteMemberHandler(appRoutes.ApplicationContext).ServeHTTP(responseWriter, request)
+		case http.MethodDelete:
+			organization.RemoveMemberHandler(appRoutes.ApplicationContext).ServeHTTP(responseWriter, request)
+		default:
+			utils.HandleError(responseWriter, request, nil, utils.MethodNotAllowedError, http.StatusText(http.StatusMethodNotAllowed))
+		}
+	})
+	mux.Handle(config.WebOrgMembers, sessionOnlyChain(organizationMemberDispatcher))
+	// The active workspace lives in the browser session, so switching it is session-only.
+	mux.Handle(config.WebWorkspace, sessionOnlyChain(organization.SwitchWorkspaceHandler(appRoutes.ApplicationContext)))
+	mux.Handle(config.WebRSVPQR, bearerOrSession(http.HandlerFunc(rsvp.ShowHandler(appRoutes.ApplicationContext))))
+	mux.Handle(config.WebRSVPLink, protectedChain(rsvp.ReissueLinkHandler(appRoutes.ApplicationContext)))
 	rsvpBaseDispatcher := http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
-		appRoutes.ApplicationContext.Logger.Printf("Router: Protected path %s, method %s", request.URL.Path, request.Method)
 		switch request.Method {
 		case http.MethodGet:
 			rsvp.ListHandler(appRoutes.ApplicationContext).ServeHTTP(responseWriter, request)
@@ -164,12 +284,13 @@ func (appRoutes *Routes) RegisterRoutes(mux *http.ServeMux) {
 		case http.MethodDelete:
 			rsvp.DeleteHandler(appRoutes.ApplicationContext).ServeHTTP(responseWriter, request)
 		default:
-			utils.HandleError(responseWriter, nil, utils.MethodNotAllowedError, appRoutes.ApplicationContext.Logger, http.StatusText(http.StatusMethodNotAllowed))
+			utils.HandleError(responseWriter, request, nil, utils.MethodNotAllowedError, http.StatusText(http.StatusMethodNotAllowed))
 		}
 	})
 	mux.Handle(config.WebRSVPs, protectedChain(rsvpBaseDispatcher))
+	mux.Handle(config.WebRSVPStream, protectedChain(rsvp.StreamHandler(appRoutes.ApplicationContext)))
+	mux.Handle(config.WebEventsStream, protectedChain(event.StreamHandler(appRoutes.ApplicationContext)))
 	venueBaseDispatcher := http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
-		appRoutes.ApplicationContext.Logger.Printf("Router: Protected path %s, method %s", request.URL.Path, request.Method)
 		switch request.Method {
 		case http.MethodGet:
 			venue.ListVenuesHandler(appRoutes.ApplicationContext).ServeHTTP(responseWriter, request)
@@ -180,9 +301,76 @@ func (appRoutes *Routes) RegisterRoutes(mux *http.ServeMux) {
 		case http.MethodPut, http.MethodPatch:
 			venue.UpdateVenueHandler(appRoutes.ApplicationContext).ServeHTTP(responseWriter, request)
 		default:
-			utils.HandleError(responseWriter, nil, utils.MethodNotAllowedError, appRoutes.ApplicationContext.Logger, http.StatusText(http.StatusMethodNotAllowed))
+			utils.HandleError(responseWriter, request, nil, utils.MethodNotAllowedError, http.StatusText(http.StatusMethodNotAllowed))
 		}
 	})
 	mux.Handle(config.WebVenues, protectedChain(venueBaseDispatcher))
-	appRoutes.ApplicationContext.Logger.Println("Application-specific routes registered successfully.")
+	tokenBaseDispatcher := http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		switch request.Method {
+		case http.MethodGet:
+			token.ListHandler(appRoutes.ApplicationContext).ServeHTTP(responseWriter, request)
+		case http.MethodPost:
+			token.CreateHandler(appRoutes.ApplicationContext).ServeHTTP(responseWriter, request)
+		case http.MethodDelete:
+			token.RevokeHandler(appRoutes.ApplicationContext).ServeHTTP(responseWriter, request)
+		default:
+			utils.HandleError(responseWriter, request, nil, utils.MethodNotAllowedError, http.StatusText(http.StatusMethodNotAllowed))
+		}
+	})
+	// Token management is session-only so a leaked token cannot be used to mint further tokens.
+	mux.Handle(config.WebTokens, sessionOnlyChain(tokenBaseDispatcher))
+	trashBaseDispatcher := http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		switch request.Method {
+		case http.MethodGet:
+			trash.ListHandler(appRoutes.ApplicationContext, appRoutes.EnvConfig.Trash).ServeHTTP(responseWriter, request)
+		case http.MethodPost:
+			trash.RestoreHandler(appRoutes.ApplicationContext).ServeHTTP(responseWriter, request)
+		case http.MethodDelete:
+			trash.PurgeHandler(appRoutes.ApplicationContext).ServeHTTP(responseWriter, request)
+		default:
+			utils.HandleError(responseWriter, request, nil, utils.MethodNotAllowedError, http.StatusText(http.StatusMethodNotAllowed))
+		}
+	})
+	mux.Handle(config.WebTrash, protectedChain(trashBaseDispatcher))
+	transferBaseDispatcher := http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		switch request.Method {
+		case http.MethodGet:
+			transfer.ListHandler(appRoutes.ApplicationContext).ServeHTTP(responseWriter, request)
+		case http.MethodPost:
+			transfer.CreateHandler(appRoutes.ApplicationContext).ServeHTTP(responseWriter, request)
+		case http.MethodPut, http.MethodPatch:
+			transfer.AcceptHandler(appRoutes.ApplicationContext).ServeHTTP(responseWriter, request)
+		case http.MethodDelete:
+			transfer.CancelHandler(appRoutes.ApplicationContext).ServeHTTP(responseWriter, request)
+		default:
+			utils.HandleError(responseWriter, request, nil, utils.MethodNotAllowedError, http.StatusText(http.StatusMethodNotAllowed))
+		}
+	})
+	mux.Handle(config.WebTransfers, sessionOnlyChain(transferBaseDispatcher))
+	mux.Handle(config.WebAccount, sessionOnlyChain(account.ShowHandler(appRoutes.ApplicationContext)))
+	mux.Handle(config.WebAccountExport, protectedChain(account.ExportHandler(appRoutes.ApplicationContext)))
+	// Imports write into the account wholesale, so they are session-only as well.
+	mux.Handle(config.WebAccountImport, sessionOnlyChain(account.ImportHandler(appRoutes.ApplicationContext)))
+	requireAdmin := middleware.RequireAdmin(appRoutes.ApplicationContext, appRoutes.EnvConfig.AdminEmails)
+	// The admin pages are session-only, like token management.
+	mux.Handle(config.WebAdminBackups, sessionOnlyChain(requireAdmin(admin.BackupsHandler(appRoutes.ApplicationContext, appRoutes.BackupManager))))
+	adminUsersDispatcher := http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		switch request.Method {
+		case http.MethodGet:
+			admin.ListUsersHandler(appRoutes.ApplicationContext, appRoutes.EnvConfig.Access, appRoutes.EnvConfig.AdminEmails).ServeHTTP(responseWriter, request)
+		case http.MethodPut, http.MethodPatch:
+			admin.SuspendUserHandler(appRoutes.ApplicationContext, appRoutes.EnvConfig.AdminEmails).ServeHTTP(responseWriter, request)
+		default:
+			utils.HandleError(responseWriter, request, nil, utils.MethodNotAllowedError, http.StatusText(http.StatusMethodNotAllowed))
+		}
+	})
+	mux.Handle(config.WebAdminUsers, sessionOnlyChain(requireAdmin(adminUsersDispatcher)))
+	appRoutes.ApplicationContext.Metrics.ObserveRateLimiter(func() metrics.RateLimitStats {
+		return metrics.RateLimitStats(appRoutes.PublicLimiter.Stats())
+	})
+	// With a separate METRICS_ADDRESS the metrics are served by their own listener instead, see cmd/web.
+	if appRoutes.ApplicationContext.Metrics != nil && appRoutes.EnvConfig.Metrics.Address == "" {
+		mux.Handle(config.WebMetrics, appRoutes.ApplicationContext.Metrics.Handler(appRoutes.EnvConfig.Metrics.Token))
+	}
+	appRoutes.ApplicationContext.Logger.Info("Application-specific routes registered")
 }
@@ -0,0 +1,55 @@
+package routes
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/temirov/GAuss/pkg/session"
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/middleware"
+)
+
+func TestOverriddenDeleteFormsPassTheForgeryCheckOnlyWithTheirToken(t *testing.T) {
+	session.NewSession([]byte("0123456789abcdef0123456789abcdef"))
+	applicationContext := &config.ApplicationContext{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	appRoutes := &Routes{ApplicationContext: applicationContext}
+	var servedMethod, csrfToken string
+	rejectionHandler := http.HandlerFunc(func(responseWriter http.ResponseWriter, _ *http.Request) {
+		responseWriter.WriteHeader(http.StatusForbidden)
+	})
+	protectedHandler := middleware.ProtectFromForgery(applicationContext, rejectionHandler)(appRoutes.ApplyOverrides(
+		http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+			servedMethod = request.Method
+			csrfToken = middleware.CSRFTokenFromContext(request.Context())
+			responseWriter.WriteHeader(http.StatusNoContent)
+		})))
+
+	pageRecorder := httptest.NewRecorder()
+	protectedHandler.ServeHTTP(pageRecorder, httptest.NewRequest(http.MethodGet, config.WebEvents, nil))
+	sessionCookies := pageRecorder.Result().Cookies()
+
+	deleteForm := func(formValues url.Values) *httptest.ResponseRecorder {
+		servedMethod = ""
+		formValues.Set(config.MethodOverrideParam, http.MethodDelete)
+		postRequest := httptest.NewRequest(http.MethodPost, config.WebEvents, strings.NewReader(formValues.Encode()))
+		postRequest.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		for _, sessionCookie := range sessionCookies {
+			postRequest.AddCookie(sessionCookie)
+		}
+		responseRecorder := httptest.NewRecorder()
+		protectedHandler.ServeHTTP(responseRecorder, postRequest)
+		return responseRecorder
+	}
+
+	if responseRecorder := deleteForm(url.Values{}); responseRecorder.Code != http.StatusForbidden || servedMethod != "" {
+		t.Errorf("DELETE form without token: status = %d, served as %q; want it rejected", responseRecorder.Code, servedMethod)
+	}
+	if responseRecorder := deleteForm(url.Values{config.CSRFTokenParam: {csrfToken}}); responseRecorder.Code != http.StatusNoContent || servedMethod != http.MethodDelete {
+		t.Errorf("DELETE form with token: status = %d, served as %q; want it served as DELETE", responseRecorder.Code, servedMethod)
+	}
+}
@@ -2,88 +2,125 @@
 package services
 
 import (
+	"errors"
+	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"time"
 
-	"github.com/temirov/RSVP/models"
 	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/migrations"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
 )
 
 // InitDatabase establishes connection, runs migrations, and performs conditional data fixes.
-func InitDatabase(databaseFileName string, applicationLogger *log.Logger) *gorm.DB {
-	databaseDirectoryName := filepath.Dir(databaseFileName)
-	if databaseDirectoryName != "." && databaseDirectoryName != "" {
-		_ = os.MkdirAll(databaseDirectoryName, 0755)
-	}
-	if _, err := os.Stat(databaseFileName); os.IsNotExist(err) {
-		if f, createErr := os.Create(databaseFileName); createErr == nil {
-			_ = f.Close()
-		}
+func InitDatabase(databaseConfig config.DatabaseConfig, applicationLogger *log.Logger) *gorm.DB {
+	databaseConnection, connectionError := OpenDatabase(databaseConfig)
+	if connectionError != nil {
+		applicationLogger.Fatalf("Failed to connect to %s database: %v", databaseConfig.Driver, connectionError)
 	}
+	applicationLogger.Printf("Database connection established (%s)", DescribeDatabase(databaseConfig))
+	// GORM's own console logger would interleave colored lines with the structured log.
+	databaseConnection.Logger = gormlogger.New(gormLogWriter{applicationLogger: applicationLogger}, gormlogger.Config{
+		SlowThreshold:             time.Duration(config.SlowQueryThreshold),
+		LogLevel:                  gormlogger.Warn,
+		IgnoreRecordNotFoundError: true,
+	})
 
-	databaseConnection, connectionError := gorm.Open(sqlite.Open(databaseFileName), &gorm.Config{})
-	if connectionError != nil {
-		applicationLogger.Fatalf("Failed to connect to database %s: %v", databaseFileName, connectionError)
+	if compatibilityError := migrations.CheckCompatibility(databaseConnection); compatibilityError != nil {
+		applicationLogger.Fatalf("Refusing to start: %v", compatibilityError)
 	}
-	applicationLogger.Printf("Database connection established to %s", databaseFileName)
 
-	autoMigrationError := databaseConnection.AutoMigrate(
-		&models.User{},
-		&models.Venue{},
-		&models.Event{},
-		&models.RSVP{},
-	)
-	if autoMigrationError != nil {
-		applicationLogger.Fatalf("Failed to migrate database: %v", autoMigrationError)
+	if !databaseConfig.AutoMigrate {
+		pendingMigrations, pendingError := migrations.Pending(databaseConnection)
+		if pendingError != nil {
+			applicationLogger.Fatalf("Failed to check pending migrations: %v", pendingError)
+		}
+		if len(pendingMigrations) > 0 {
+			applicationLogger.Fatalf("Refusing to start: %d pending migration(s); apply them with the migrate command", len(pendingMigrations))
+		}
+		return databaseConnection
 	}
-	applicationLogger.Println("Database migrations completed successfully.")
 
-	migrationError := performConditionalVenueUserIdMigration(databaseConnection, applicationLogger)
+	appliedMigrations, migrationError := migrations.Apply(databaseConnection, 0, applicationLogger)
 	if migrationError != nil {
-		applicationLogger.Fatalf("Conditional venue user_id migration failed: %v", migrationError)
+		applicationLogger.Fatalf("Failed to migrate database: %v", migrationError)
 	}
+	applicationLogger.Printf("Database migrations completed successfully (%d applied, schema version %d).",
+		len(appliedMigrations), migrations.LatestVersion())
 
 	return databaseConnection
 }
 
-// performConditionalVenueUserIdMigration updates legacy venue records lacking user_id.
-func performConditionalVenueUserIdMigration(databaseConnection *gorm.DB, applicationLogger *log.Logger) error {
-	var recoverableMissingCount int64
-	recoverableQuery := "SELECT COUNT(*) FROM " + config.TableVenues + " WHERE (user_id IS NULL OR user_id = '') AND id IN (SELECT venue_id FROM " + config.TableEvents + " WHERE venue_id IS NOT NULL)"
-	if err := databaseConnection.Raw(recoverableQuery).Scan(&recoverableMissingCount).Error; err != nil {
-		applicationLogger.Printf("Failed to check recoverable missing user_id condition: %v", err)
-		return err
-	}
+// gormLogWriter hands GORM's reports of failed and slow queries to the application logger as warnings.
+type gormLogWriter struct {
+	applicationLogger *log.Logger
+}
+
+// Printf logs one GORM report.
+func (writer gormLogWriter) Printf(format string, arguments ...interface{}) {
+	writer.applicationLogger.Printf("WARN: "+format, arguments...)
+}
 
-	var nonRecoverableMissingCount int64
-	nonRecoverableQuery := "SELECT COUNT(*) FROM " + config.TableVenues + " WHERE (user_id IS NULL OR user_id = '') AND id NOT IN (SELECT venue_id FROM " + config.TableEvents + " WHERE venue_id IS NOT NULL)"
-	if err := databaseConnection.Raw(nonRecoverableQuery).Scan(&nonRecoverableMissingCount).Error; err != nil {
-		applicationLogger.Printf("Failed to check non-recoverable missing user_id condition: %v", err)
-		return err
+// OpenDatabase connects to the configured database without running migrations.
+func OpenDatabase(databaseConfig config.DatabaseConfig) (*gorm.DB, error) {
+	databaseDialector, dialectorError := OpenDialector(databaseConfig)
+	if dialectorError != nil {
+		return nil, dialectorError
 	}
+	return gorm.Open(databaseDialector, &gorm.Config{})
+}
 
-	if recoverableMissingCount > 0 {
-		updateRecoverableSQL := "UPDATE " + config.TableVenues + " SET user_id = (SELECT user_id FROM " + config.TableEvents + " WHERE " + config.TableEvents + ".venue_id = " + config.TableVenues + ".id LIMIT 1) WHERE (user_id IS NULL OR user_id = '') AND id IN (SELECT venue_id FROM " + config.TableEvents + " WHERE venue_id IS NOT NULL)"
-		if res := databaseConnection.Exec(updateRecoverableSQL); res.Error != nil {
-			applicationLogger.Printf("Failed to update recoverable venue records: %v", res.Error)
-			return res.Error
+// OpenDialector returns the GORM dialector for the configured driver.
+// For SQLite without a DSN, the database file and its directory are created if they do not exist yet.
+func OpenDialector(databaseConfig config.DatabaseConfig) (gorm.Dialector, error) {
+	switch databaseConfig.Driver {
+	case config.DatabaseDriverSQLite, "":
+		if databaseConfig.DSN != "" {
+			return sqlite.Open(databaseConfig.DSN), nil
+		}
+		ensureSQLiteFile(databaseConfig.Name)
+		return sqlite.Open(databaseConfig.Name), nil
+	case config.DatabaseDriverPostgres:
+		if databaseConfig.DSN == "" {
+			return nil, errors.New("a DSN is required for PostgreSQL")
 		}
+		return postgres.Open(databaseConfig.DSN), nil
+	case config.DatabaseDriverMySQL:
+		if databaseConfig.DSN == "" {
+			return nil, errors.New("a DSN is required for MySQL")
+		}
+		return mysql.New(mysql.Config{
+			DSN:               databaseConfig.DSN,
+			DefaultStringSize: config.MySQLDefaultStringSize,
+		}), nil
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q", databaseConfig.Driver)
 	}
+}
 
-	if nonRecoverableMissingCount > 0 {
-		var firstUser models.User
-		if err := databaseConnection.First(&firstUser).Error; err != nil {
-			applicationLogger.Printf("Failed to retrieve first user for non-recoverable venue update: %v", err)
-			return err
-		}
-		updateNonRecoverableSQL := "UPDATE " + config.TableVenues + " SET user_id = ? WHERE (user_id IS NULL OR user_id = '') AND id NOT IN (SELECT venue_id FROM " + config.TableEvents + " WHERE venue_id IS NOT NULL)"
-		if res := databaseConnection.Exec(updateNonRecoverableSQL, firstUser.ID); res.Error != nil {
-			applicationLogger.Printf("Failed to update non-recoverable venue records: %v", res.Error)
-			return res.Error
+// ensureSQLiteFile creates the SQLite database file and its parent directory when missing.
+func ensureSQLiteFile(databaseFileName string) {
+	databaseDirectoryName := filepath.Dir(databaseFileName)
+	if databaseDirectoryName != "." && databaseDirectoryName != "" {
+		_ = os.MkdirAll(databaseDirectoryName, 0755)
+	}
+	if _, err := os.Stat(databaseFileName); os.IsNotExist(err) {
+		if f, createErr := os.Create(databaseFileName); createErr == nil {
+			_ = f.Close()
 		}
 	}
-	return nil
+}
+
+// DescribeDatabase returns a log-safe description of the database target; DSNs are omitted because they may contain credentials.
+func DescribeDatabase(databaseConfig config.DatabaseConfig) string {
+	if (databaseConfig.Driver == config.DatabaseDriverSQLite || databaseConfig.Driver == "") && databaseConfig.DSN == "" {
+		return config.DatabaseDriverSQLite + " " + databaseConfig.Name
+	}
+	return databaseConfig.Driver
 }
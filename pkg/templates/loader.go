@@ -7,7 +7,7 @@ import (
 	"io/fs"
 	"log"
 	"net/url"
-	"path/filepath"
+	"path"
 	"strings"
 	"time"
 
@@ -41,6 +41,20 @@ var customTemplateFunctions = template.FuncMap{
 		}
 		return fmt.Sprintf("%dh%dm", hours, minutes)
 	},
+	"dict": func(keyValuePairs ...interface{}) (map[string]interface{}, error) {
+		if len(keyValuePairs)%2 != 0 {
+			return nil, fmt.Errorf("dict expects key/value pairs, got %d arguments", len(keyValuePairs))
+		}
+		templateValues := make(map[string]interface{}, len(keyValuePairs)/2)
+		for pairIndex := 0; pairIndex < len(keyValuePairs); pairIndex += 2 {
+			valueKey, isString := keyValuePairs[pairIndex].(string)
+			if !isString {
+				return nil, fmt.Errorf("dict key %v is not a string", keyValuePairs[pairIndex])
+			}
+			templateValues[valueKey] = keyValuePairs[pairIndex+1]
+		}
+		return templateValues, nil
+	},
 	"mapsURL": func(address string) string {
 		if address == "" {
 			return ""
@@ -49,22 +63,36 @@ var customTemplateFunctions = template.FuncMap{
 	},
 }
 
+// mainViewTemplateNames are the views rendered through the layout; each gets its own template set.
+var mainViewTemplateNames = []string{
+	config.TemplateEvents,
+	config.TemplateRSVPs,
+	config.TemplateRSVP,
+	config.TemplateResponse,
+	config.TemplateThankYou,
+	config.TemplateVenues,
+	config.TemplateTokens,
+	config.TemplateAccount,
+	config.TemplateTrash,
+	config.TemplateCohosts,
+	config.TemplateOrgs,
+	config.TemplateTransfers,
+	config.TemplateUsers,
+	config.TemplateForgery,
+}
+
+// PrecompiledTemplatesMap holds a template set per main view, and the standalone landing page template.
 var PrecompiledTemplatesMap map[string]*template.Template
 
-func LoadAllPrecompiledTemplates(templatesDirectoryPath string) {
+// LoadAllPrecompiledTemplates parses every template set from templateFiles, the templates directory of the embedded
+// or overridden assets, and stores them in PrecompiledTemplatesMap.
+func LoadAllPrecompiledTemplates(templateFiles fs.FS) {
 	PrecompiledTemplatesMap = make(map[string]*template.Template)
-	mainViewTemplateNames := []string{
-		config.TemplateEvents,
-		config.TemplateRSVPs,
-		config.TemplateRSVP,
-		config.TemplateResponse,
-		config.TemplateThankYou,
-		config.TemplateVenues,
-	}
 	var layoutFilePath string
+	var landingFilePath string
 	var partialTemplateFiles []string
 	mainViewFilePaths := make(map[string]string)
-	directoryWalkError := filepath.WalkDir(templatesDirectoryPath, func(filePath string, directoryEntry fs.DirEntry, walkError error) error {
+	directoryWalkError := fs.WalkDir(templateFiles, ".", func(filePath string, directoryEntry fs.DirEntry, walkError error) error {
 		if walkError != nil {
 			log.Printf("Warning: Error accessing path %q during template walk: %v", filePath, walkError)
 			return walkError
@@ -77,9 +105,9 @@ func LoadAllPrecompiledTemplates(templatesDirectoryPath string) {
 			return nil
 		}
 		baseTemplateName := strings.TrimSuffix(directoryEntry.Name(), config.TemplateExtension)
-		relativeFilePath, _ := filepath.Rel(templatesDirectoryPath, filePath)
 		if baseTemplateName == config.TemplateLanding {
-			log.Printf("Skipping standalone template: %s", relativeFilePath)
+			landingFilePath = filePath
+			log.Printf("Found standalone template: %s", filePath)
 			return nil
 		}
 		if baseTemplateName == config.TemplateLayout {
@@ -87,11 +115,11 @@ func LoadAllPrecompiledTemplates(templatesDirectoryPath string) {
 				log.Printf("Multiple layout files found; using '%s' and ignoring '%s'", layoutFilePath, filePath)
 			} else {
 				layoutFilePath = filePath
-				log.Printf("Found layout: %s", relativeFilePath)
+				log.Printf("Found layout: %s", filePath)
 			}
-		} else if strings.HasPrefix(relativeFilePath, config.PartialsDir+string(filepath.Separator)) || strings.HasPrefix(baseTemplateName, "_") {
+		} else if strings.HasPrefix(filePath, config.PartialsDir+"/") || strings.HasPrefix(baseTemplateName, "_") {
 			partialTemplateFiles = append(partialTemplateFiles, filePath)
-			log.Printf("Found partial: %s", relativeFilePath)
+			log.Printf("Found partial: %s", filePath)
 		} else {
 			for _, mainViewName := range mainViewTemplateNames {
 				if baseTemplateName == mainViewName {
@@ -99,7 +127,7 @@ func LoadAllPrecompiledTemplates(templatesDirectoryPath string) {
 						log.Printf("Multiple files found for main view '%s'; using '%s' and ignoring '%s'", mainViewName, existingFilePath, filePath)
 					} else {
 						mainViewFilePaths[mainViewName] = filePath
-						log.Printf("Found main view: %s (for %s)", relativeFilePath, mainViewName)
+						log.Printf("Found main view: %s (for %s)", filePath, mainViewName)
 					}
 					break
 				}
@@ -108,10 +136,19 @@ func LoadAllPrecompiledTemplates(templatesDirectoryPath string) {
 		return nil
 	})
 	if directoryWalkError != nil {
-		log.Fatalf("FATAL: Error walking template directory '%s': %v", templatesDirectoryPath, directoryWalkError)
+		log.Fatalf("FATAL: Error walking template directory: %v", directoryWalkError)
 	}
 	if layoutFilePath == "" {
-		log.Fatalf("FATAL: Layout file '%s%s' not found in directory '%s'", config.TemplateLayout, config.TemplateExtension, templatesDirectoryPath)
+		log.Fatalf("FATAL: Layout file '%s%s' not found in template directory", config.TemplateLayout, config.TemplateExtension)
+	}
+	if landingFilePath == "" {
+		log.Printf("Landing page template '%s%s' not found. The sign-in page will not be available.", config.TemplateLanding, config.TemplateExtension)
+	} else {
+		landingTemplate, parseError := template.New(path.Base(landingFilePath)).Funcs(customTemplateFunctions).ParseFS(templateFiles, landingFilePath)
+		if parseError != nil {
+			log.Fatalf("FATAL: Failed to parse landing page template '%s'. Error: %v", landingFilePath, parseError)
+		}
+		PrecompiledTemplatesMap[config.TemplateLanding] = landingTemplate
 	}
 	log.Printf("Found %d partial template files.", len(partialTemplateFiles))
 	parsedTemplateCount := 0
@@ -125,9 +162,9 @@ func LoadAllPrecompiledTemplates(templatesDirectoryPath string) {
 		filesForTemplateSet := []string{layoutFilePath}
 		filesForTemplateSet = append(filesForTemplateSet, partialTemplateFiles...)
 		filesForTemplateSet = append(filesForTemplateSet, mainViewFilePath)
-		templateSet, parseError := template.New(filepath.Base(mainViewFilePath)).
+		templateSet, parseError := template.New(path.Base(mainViewFilePath)).
 			Funcs(customTemplateFunctions).
-			ParseFiles(filesForTemplateSet...)
+			ParseFS(templateFiles, filesForTemplateSet...)
 		if parseError != nil {
 			log.Fatalf("FATAL: Failed to parse template set for view '%s'. Error: %v. Files: %v", mainViewName, parseError, filesForTemplateSet)
 		}
@@ -143,3 +180,15 @@ func LoadAllPrecompiledTemplates(templatesDirectoryPath string) {
 	}
 	log.Println("Layout-integrated template loading complete.")
 }
+
+// MissingViews returns the main views and standalone pages without a template in PrecompiledTemplatesMap, because
+// the templates have not been loaded yet or their files were not found.
+func MissingViews() []string {
+	var missingViewNames []string
+	for _, mainViewName := range append(mainViewTemplateNames, config.TemplateLanding) {
+		if _, isLoaded := PrecompiledTemplatesMap[mainViewName]; !isLoaded {
+			missingViewNames = append(missingViewNames, mainViewName)
+		}
+	}
+	return missingViewNames
+}
@@ -0,0 +1,85 @@
+// Package testdb gives tests a database of the kind the server runs on. By default each test gets its own
+// in-memory SQLite database. Setting TEST_DB_DRIVER and TEST_DB_DSN, with the values DB_DRIVER and DB_DSN take,
+// runs the same tests against PostgreSQL or MySQL instead:
+//
+//	TEST_DB_DRIVER=postgres TEST_DB_DSN="host=localhost user=rsvp dbname=rsvp_test sslmode=disable" go test -p 1 ./...
+//
+// Every table in that database is dropped before each test, so it must be a throwaway database, and -p 1 keeps
+// packages from running their tests in it at the same time.
+package testdb
+
+import (
+	"io"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/temirov/RSVP/pkg/config"
+	"github.com/temirov/RSVP/pkg/migrations"
+	"github.com/temirov/RSVP/pkg/services"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// Environment variables selecting the database the tests run against.
+const (
+	DriverEnv = "TEST_DB_DRIVER"
+	DSNEnv    = "TEST_DB_DSN"
+)
+
+// Open returns an empty database for the test, closed when the test ends.
+func Open(t testing.TB) *gorm.DB {
+	t.Helper()
+	databaseConfig := config.DatabaseConfig{Driver: os.Getenv(DriverEnv), DSN: os.Getenv(DSNEnv)}
+	inMemory := false
+	if databaseConfig.Driver == "" {
+		databaseConfig.Driver = config.DatabaseDriverSQLite
+	}
+	if databaseConfig.Driver == config.DatabaseDriverSQLite && databaseConfig.DSN == "" {
+		databaseConfig.DSN = ":memory:"
+		inMemory = true
+	}
+	databaseDialector, err := services.OpenDialector(databaseConfig)
+	if err != nil {
+		t.Fatalf("opening the %s test database: %v", databaseConfig.Driver, err)
+	}
+	databaseConnection, err := gorm.Open(databaseDialector, &gorm.Config{Logger: gormlogger.Discard})
+	if err != nil {
+		t.Fatalf("connecting to the %s test database: %v", databaseConfig.Driver, err)
+	}
+	sqlDatabase, err := databaseConnection.DB()
+	if err != nil {
+		t.Fatalf("reaching the %s test database: %v", databaseConfig.Driver, err)
+	}
+	t.Cleanup(func() { _ = sqlDatabase.Close() })
+	if inMemory {
+		// Every connection to ":memory:" opens a database of its own, so the test keeps to one.
+		sqlDatabase.SetMaxOpenConns(1)
+		return databaseConnection
+	}
+	existingTables, err := databaseConnection.Migrator().GetTables()
+	if err != nil {
+		t.Fatalf("listing the tables of the %s test database: %v", databaseConfig.Driver, err)
+	}
+	for _, existingTable := range existingTables {
+		if err := databaseConnection.Migrator().DropTable(existingTable); err != nil {
+			t.Fatalf("dropping %s from the %s test database: %v", existingTable, databaseConfig.Driver, err)
+		}
+	}
+	return databaseConnection
+}
+
+// OpenMigrated returns a database for the test with every migration applied.
+func OpenMigrated(t testing.TB) *gorm.DB {
+	t.Helper()
+	databaseConnection := Open(t)
+	if _, err := migrations.Apply(databaseConnection, 0, Logger()); err != nil {
+		t.Fatalf("migrating the test database: %v", err)
+	}
+	return databaseConnection
+}
+
+// Logger returns a logger for code under test that wants one, discarding what it writes.
+func Logger() *log.Logger {
+	return log.New(io.Discard, "", 0)
+}
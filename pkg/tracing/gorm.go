@@ -0,0 +1,81 @@
+package tracing
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// spanKey is where the plugin keeps a statement's span on the GORM instance running it.
+const spanKey = "tracing:span"
+
+// GormPlugin is a GORM plugin that records a span for each database statement, as a child of the span in the
+// statement's context. Queries run with db.WithContext(request.Context()) therefore appear under their request.
+type GormPlugin struct{}
+
+// NewGormPlugin returns the tracing plugin.
+func NewGormPlugin() *GormPlugin {
+	return &GormPlugin{}
+}
+
+// Name identifies the plugin to GORM.
+func (plugin *GormPlugin) Name() string {
+	return "rsvp:tracing"
+}
+
+// Initialize registers a callback before and after each operation, which start and end the statement's span.
+func (plugin *GormPlugin) Initialize(database *gorm.DB) error {
+	callbacks := database.Callback()
+	beforeName, afterName := plugin.Name()+":before_", plugin.Name()+":after_"
+	return errors.Join(
+		callbacks.Create().Before("gorm:create").Register(beforeName+"create", plugin.startSpan("create")),
+		callbacks.Create().After("gorm:create").Register(afterName+"create", plugin.endSpan),
+		callbacks.Query().Before("gorm:query").Register(beforeName+"query", plugin.startSpan("query")),
+		callbacks.Query().After("gorm:query").Register(afterName+"query", plugin.endSpan),
+		callbacks.Update().Before("gorm:update").Register(beforeName+"update", plugin.startSpan("update")),
+		callbacks.Update().After("gorm:update").Register(afterName+"update", plugin.endSpan),
+		callbacks.Delete().Before("gorm:delete").Register(beforeName+"delete", plugin.startSpan("delete")),
+		callbacks.Delete().After("gorm:delete").Register(afterName+"delete", plugin.endSpan),
+		callbacks.Row().Before("gorm:row").Register(beforeName+"row", plugin.startSpan("row")),
+		callbacks.Row().After("gorm:row").Register(afterName+"row", plugin.endSpan),
+		callbacks.Raw().Before("gorm:raw").Register(beforeName+"raw", plugin.startSpan("raw")),
+		callbacks.Raw().After("gorm:raw").Register(afterName+"raw", plugin.endSpan),
+	)
+}
+
+// startSpan returns the callback starting the span of an operation.
+func (plugin *GormPlugin) startSpan(operation string) func(*gorm.DB) {
+	return func(database *gorm.DB) {
+		_, statementSpan := Tracer().Start(database.Statement.Context, "db."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(attribute.String("db.system", database.Dialector.Name()), semconv.DBOperationName(operation)))
+		database.InstanceSet(spanKey, statementSpan)
+	}
+}
+
+// endSpan records the statement's SQL, table, rows and error on its span and ends it. Values are bound as
+// parameters, so the SQL does not contain guests' answers or other personal data.
+func (plugin *GormPlugin) endSpan(database *gorm.DB) {
+	spanValue, isStarted := database.InstanceGet(spanKey)
+	statementSpan, isSpan := spanValue.(trace.Span)
+	if !isStarted || !isSpan {
+		return
+	}
+	defer statementSpan.End()
+	if !statementSpan.IsRecording() {
+		return
+	}
+	statementSpan.SetAttributes(
+		semconv.DBQueryText(database.Statement.SQL.String()),
+		semconv.DBCollectionName(database.Statement.Table),
+		attribute.Int64("db.rows_affected", database.RowsAffected),
+	)
+	if database.Error != nil && !errors.Is(database.Error, gorm.ErrRecordNotFound) {
+		statementSpan.RecordError(database.Error)
+		statementSpan.SetStatus(codes.Error, database.Error.Error())
+	}
+}
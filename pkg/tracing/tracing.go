@@ -0,0 +1,59 @@
+// Package tracing sets up OpenTelemetry tracing. Spans cover each HTTP request, each view rendering and each
+// database statement, and are exported over OTLP or written to standard output for local debugging.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/temirov/RSVP/pkg/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Setup installs the global tracer provider and the W3C trace context propagator for the configured exporter,
+// and returns the function that flushes and stops it. When tracing is disabled it installs nothing, so spans are
+// no-ops, and the returned function does nothing.
+func Setup(setupContext context.Context, settings config.TracingConfig) (func(context.Context) error, error) {
+	if !settings.Enabled() {
+		return func(context.Context) error { return nil }, nil
+	}
+	var spanExporter sdktrace.SpanExporter
+	var exporterError error
+	switch settings.Exporter {
+	case config.TracingExporterOTLP:
+		spanExporter, exporterError = otlptracehttp.New(setupContext)
+	case config.TracingExporterStdout:
+		spanExporter, exporterError = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	default:
+		exporterError = fmt.Errorf("unsupported tracing exporter %q", settings.Exporter)
+	}
+	if exporterError != nil {
+		return nil, fmt.Errorf("creating the %s trace exporter: %w", settings.Exporter, exporterError)
+	}
+	serviceResource, resourceError := resource.Merge(resource.Default(),
+		resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(settings.ServiceName)))
+	if resourceError != nil {
+		return nil, fmt.Errorf("describing the traced service: %w", resourceError)
+	}
+	tracerProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(spanExporter),
+		sdktrace.WithResource(serviceResource),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(settings.SampleRatio))),
+	)
+	otel.SetTracerProvider(tracerProvider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return tracerProvider.Shutdown, nil
+}
+
+// Tracer returns the tracer for the application's own spans from the global provider.
+func Tracer() trace.Tracer {
+	return otel.Tracer(config.TracingInstrumentationName)
+}
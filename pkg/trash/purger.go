@@ -0,0 +1,64 @@
+// Package trash permanently removes soft-deleted records once their retention period has passed.
+package trash
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/temirov/RSVP/models"
+	"github.com/temirov/RSVP/pkg/config"
+	"gorm.io/gorm"
+)
+
+// Purger deletes expired trash on a schedule.
+type Purger struct {
+	databaseConnection *gorm.DB
+	trashConfig        config.TrashConfig
+	logger             *log.Logger
+}
+
+// NewPurger creates a Purger for the given database connection and settings.
+func NewPurger(databaseConnection *gorm.DB, trashConfig config.TrashConfig, applicationLogger *log.Logger) *Purger {
+	return &Purger{
+		databaseConnection: databaseConnection,
+		trashConfig:        trashConfig,
+		logger:             applicationLogger,
+	}
+}
+
+// PurgeExpired permanently deletes records that have been in the trash for longer than the retention period.
+func (purger *Purger) PurgeExpired() (models.PurgeCounts, error) {
+	if purger.trashConfig.Retention <= 0 {
+		return models.PurgeCounts{}, nil
+	}
+	purgeCounts, err := models.PurgeTrashOlderThan(purger.databaseConnection, time.Now().Add(-purger.trashConfig.Retention))
+	if err != nil {
+		return purgeCounts, err
+	}
+	if purgeCounts.Events+purgeCounts.Venues+purgeCounts.RSVPs > 0 {
+		purger.logger.Printf("Purged expired trash: %d event(s), %d venue(s), %d RSVP(s)", purgeCounts.Events, purgeCounts.Venues, purgeCounts.RSVPs)
+	}
+	return purgeCounts, nil
+}
+
+// RunSchedule purges expired trash at startup and then every config.TrashPurgeInterval until the context is cancelled.
+// It does nothing when the retention period is zero.
+func (purger *Purger) RunSchedule(scheduleContext context.Context) {
+	if purger.trashConfig.Retention <= 0 {
+		return
+	}
+	purger.logger.Printf("Deleted items are purged after %s", purger.trashConfig.Retention)
+	purgeTicker := time.NewTicker(config.TrashPurgeInterval)
+	defer purgeTicker.Stop()
+	for {
+		if _, err := purger.PurgeExpired(); err != nil {
+			purger.logger.Printf("ERROR: Purging expired trash failed: %v", err)
+		}
+		select {
+		case <-scheduleContext.Done():
+			return
+		case <-purgeTicker.C:
+		}
+	}
+}
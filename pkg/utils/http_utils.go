@@ -3,12 +3,17 @@
 package utils
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"net"
 	"net/http"
+	"net/netip"
 	"net/url"
 	"strings"
+
+	"github.com/temirov/RSVP/pkg/logging"
 )
 
 // ParamSource defines where to look for HTTP request parameters.
@@ -104,6 +109,101 @@ func BuildPublicURL(baseURLString string, pathSegment string, queryParams map[st
 	return resolvedURL.String(), nil
 }
 
+// clientIPContextKey is the context key under which WithClientIP stores the resolved client address.
+type clientIPContextKey struct{}
+
+// WithClientIP returns a copy of requestContext carrying the client address resolved by ResolveClientIP.
+func WithClientIP(requestContext context.Context, clientIP string) context.Context {
+	return context.WithValue(requestContext, clientIPContextKey{}, clientIP)
+}
+
+// ClientIP returns the address of the client that issued the request: the one resolved from trusted proxy headers
+// when the request went through the client address middleware, and the host part of RemoteAddr otherwise.
+func ClientIP(httpRequest *http.Request) string {
+	if clientIP, found := httpRequest.Context().Value(clientIPContextKey{}).(string); found && clientIP != "" {
+		return clientIP
+	}
+	return remoteHost(httpRequest)
+}
+
+// ResolveClientIP returns the address of the client that issued the request. X-Forwarded-For is only believed when
+// the connection comes from one of trustedProxies, since anyone else can send any value in it. Its entries are then
+// read from the right, skipping the trusted proxies that appended them, and the first other address is the client.
+// Without trusted proxies, or when the header is missing or malformed, the host part of RemoteAddr is used.
+func ResolveClientIP(httpRequest *http.Request, trustedProxies []netip.Prefix) string {
+	connectionHost := remoteHost(httpRequest)
+	if !isTrustedProxy(connectionHost, trustedProxies) {
+		return connectionHost
+	}
+	forwardedAddresses := strings.Split(strings.Join(httpRequest.Header.Values("X-Forwarded-For"), ","), ",")
+	clientIP := connectionHost
+	for addressIndex := len(forwardedAddresses) - 1; addressIndex >= 0; addressIndex-- {
+		forwardedAddress := strings.TrimSpace(forwardedAddresses[addressIndex])
+		if forwardedAddress == "" {
+			continue
+		}
+		parsedAddress, parseError := netip.ParseAddr(forwardedAddress)
+		if parseError != nil {
+			// A hop we do not trust wrote garbage; the last address a trusted proxy vouched for is the best we have.
+			return clientIP
+		}
+		clientIP = parsedAddress.Unmap().String()
+		if !isTrustedProxy(clientIP, trustedProxies) {
+			return clientIP
+		}
+	}
+	return clientIP
+}
+
+// isTrustedProxy reports whether address lies in one of trustedProxies.
+func isTrustedProxy(address string, trustedProxies []netip.Prefix) bool {
+	parsedAddress, parseError := netip.ParseAddr(address)
+	if parseError != nil {
+		return false
+	}
+	parsedAddress = parsedAddress.Unmap()
+	for _, trustedProxy := range trustedProxies {
+		if trustedProxy.Contains(parsedAddress) {
+			return true
+		}
+	}
+	return false
+}
+
+// remoteHost returns the host part of the request's RemoteAddr.
+func remoteHost(httpRequest *http.Request) string {
+	connectionHost, _, splitError := net.SplitHostPort(httpRequest.RemoteAddr)
+	if splitError != nil {
+		return httpRequest.RemoteAddr
+	}
+	return connectionHost
+}
+
+// linkPreviewUserAgentMarkers are lower-case fragments of User-Agent strings sent by chat apps,
+// social networks and crawlers when they unfurl a link. Requests from them are not real invitee visits.
+// The fragments name the unfurlers themselves rather than their apps: the in-app browsers of Telegram or Slack,
+// the Outlook mail client and phones such as the Cubot carry the app or brand name but are people opening the link.
+var linkPreviewUserAgentMarkers = []string{
+	"bot/", "bot;", "bot)", "+http", "crawler", "spider", "facebookexternalhit", "whatsapp/", "slackbot",
+	"slack-imgproxy", "telegrambot", "discordbot", "skypeuripreview", "embedly", "quora link preview",
+	"google-safety", "curl/", "wget/",
+}
+
+// IsLinkPreviewRequest reports whether the request appears to come from an automated link unfurler or crawler
+// rather than a person opening the link in a browser. Requests without a User-Agent are treated as automated.
+func IsLinkPreviewRequest(httpRequest *http.Request) bool {
+	userAgent := strings.ToLower(httpRequest.UserAgent())
+	if userAgent == "" {
+		return true
+	}
+	for _, marker := range linkPreviewUserAgentMarkers {
+		if strings.Contains(userAgent, marker) {
+			return true
+		}
+	}
+	return false
+}
+
 // ErrorType enumerates common categories of errors encountered in handlers.
 type ErrorType int
 
@@ -116,6 +216,7 @@ const (
 	ServerError
 	ForbiddenError
 	MethodNotAllowedError
+	TooManyRequestsError
 )
 
 // User-facing error messages (constants).
@@ -124,25 +225,21 @@ const (
 	ErrMsgUnauthorized           = "Unauthorized: Please log in."
 	ErrMsgInvalidFormData        = "Invalid form data submitted."
 	ErrMsgInvalidStartTimeFormat = "Invalid start time format. Please use YYYY-MM-DDTHH:MM."
+	ErrMsgInvalidAPIToken        = "Unauthorized: Invalid or revoked API token."
 )
 
-// HandleError provides a consistent way to log errors and send appropriate HTTP error responses.
+// HandleError provides a consistent way to log errors and send appropriate HTTP error responses. The error is logged
+// with the request's logger: server errors at error level, rejected requests at warn level, and rate-limited requests,
+// which would flood the log during an attack, at debug level.
 func HandleError(
 	httpResponseWriter http.ResponseWriter,
+	httpRequest *http.Request,
 	err error,
 	errorType ErrorType,
-	logger *log.Logger,
 	userMessage string,
 ) {
-	if logger != nil {
-		if err != nil {
-			logger.Printf("ERROR Type(%d): %s | Details: %v", errorType, userMessage, err)
-		} else {
-			logger.Printf("ERROR Type(%d): %s", errorType, userMessage)
-		}
-	}
-
 	var statusCode int
+	logLevel := slog.LevelWarn
 	switch errorType {
 	case ValidationError:
 		statusCode = http.StatusBadRequest
@@ -154,20 +251,29 @@ func HandleError(
 		statusCode = http.StatusNotFound
 	case MethodNotAllowedError:
 		statusCode = http.StatusMethodNotAllowed
+	case TooManyRequestsError:
+		statusCode = http.StatusTooManyRequests
+		logLevel = slog.LevelDebug
 	case DatabaseError, ServerError:
 		statusCode = http.StatusInternalServerError
+		logLevel = slog.LevelError
 		if userMessage == "" {
 			userMessage = ErrMsgInternalServer
 		}
 	default:
-		if logger != nil {
-			logger.Printf("WARN: Unknown error type (%d) used in HandleError. User Message: %s", errorType, userMessage)
-		}
 		statusCode = http.StatusInternalServerError
+		logLevel = slog.LevelError
 		if userMessage == "" {
 			userMessage = ErrMsgInternalServer
 		}
 	}
 
+	errorAttributes := []slog.Attr{slog.Int("error_type", int(errorType)), slog.Int("status", statusCode)}
+	if err != nil {
+		errorAttributes = append(errorAttributes, slog.Any("error", err))
+	}
+	requestContext := httpRequest.Context()
+	logging.FromContext(requestContext).LogAttrs(requestContext, logLevel, userMessage, errorAttributes...)
+
 	http.Error(httpResponseWriter, userMessage, statusCode)
 }
@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func TestResolveClientIP(t *testing.T) {
+	trustedProxies := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("127.0.0.1/32")}
+	testCases := []struct {
+		name           string
+		remoteAddr     string
+		forwardedFor   []string
+		trustedProxies []netip.Prefix
+		expectedIP     string
+	}{
+		{name: "no proxies ignores the header", remoteAddr: "203.0.113.7:5000", forwardedFor: []string{"198.51.100.1"}, expectedIP: "203.0.113.7"},
+		{name: "untrusted connection ignores the header", remoteAddr: "203.0.113.7:5000", forwardedFor: []string{"198.51.100.1"}, trustedProxies: trustedProxies, expectedIP: "203.0.113.7"},
+		{name: "trusted proxy without header", remoteAddr: "10.1.2.3:5000", trustedProxies: trustedProxies, expectedIP: "10.1.2.3"},
+		{name: "trusted proxy names the client", remoteAddr: "10.1.2.3:5000", forwardedFor: []string{"198.51.100.1"}, trustedProxies: trustedProxies, expectedIP: "198.51.100.1"},
+		{name: "spoofed leftmost entry is skipped", remoteAddr: "10.1.2.3:5000", forwardedFor: []string{"192.0.2.66, 198.51.100.1"}, trustedProxies: trustedProxies, expectedIP: "198.51.100.1"},
+		{name: "chained trusted proxies are skipped", remoteAddr: "127.0.0.1:5000", forwardedFor: []string{"198.51.100.1, 10.9.9.9"}, trustedProxies: trustedProxies, expectedIP: "198.51.100.1"},
+		{name: "repeated headers are joined", remoteAddr: "10.1.2.3:5000", forwardedFor: []string{"192.0.2.66", "198.51.100.1"}, trustedProxies: trustedProxies, expectedIP: "198.51.100.1"},
+		{name: "malformed entry stops at the last trusted hop", remoteAddr: "10.1.2.3:5000", forwardedFor: []string{"not-an-ip, 10.9.9.9"}, trustedProxies: trustedProxies, expectedIP: "10.9.9.9"},
+		{name: "only trusted hops gives the leftmost", remoteAddr: "10.1.2.3:5000", forwardedFor: []string{"10.4.4.4, 10.9.9.9"}, trustedProxies: trustedProxies, expectedIP: "10.4.4.4"},
+		{name: "IPv6 client", remoteAddr: "10.1.2.3:5000", forwardedFor: []string{"2001:db8::1"}, trustedProxies: trustedProxies, expectedIP: "2001:db8::1"},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			request := httptest.NewRequest("GET", "/", nil)
+			request.RemoteAddr = testCase.remoteAddr
+			for _, forwardedFor := range testCase.forwardedFor {
+				request.Header.Add("X-Forwarded-For", forwardedFor)
+			}
+			if resolvedIP := ResolveClientIP(request, testCase.trustedProxies); resolvedIP != testCase.expectedIP {
+				t.Errorf("ResolveClientIP() = %q, want %q", resolvedIP, testCase.expectedIP)
+			}
+		})
+	}
+}
+
+func TestClientIPPrefersResolvedAddress(t *testing.T) {
+	request := httptest.NewRequest("GET", "/", nil)
+	request.RemoteAddr = "10.1.2.3:5000"
+	request.Header.Set("X-Forwarded-For", "192.0.2.66")
+	if clientIP := ClientIP(request); clientIP != "10.1.2.3" {
+		t.Errorf("ClientIP() without middleware = %q, want the connection address", clientIP)
+	}
+	request = request.WithContext(WithClientIP(request.Context(), "198.51.100.1"))
+	if clientIP := ClientIP(request); clientIP != "198.51.100.1" {
+		t.Errorf("ClientIP() = %q, want the resolved address", clientIP)
+	}
+}
+
+func TestIsLinkPreviewRequest(t *testing.T) {
+	testCases := []struct {
+		name        string
+		userAgent   string
+		wantPreview bool
+	}{
+		{name: "no user agent", userAgent: "", wantPreview: true},
+		{name: "Slack unfurler", userAgent: "Slackbot-LinkExpanding 1.0 (+https://api.slack.com/robots)", wantPreview: true},
+		{name: "Slack image proxy", userAgent: "Slack-ImgProxy (+https://api.slack.com/robots)", wantPreview: true},
+		{name: "Facebook", userAgent: "facebookexternalhit/1.1 (+http://www.facebook.com/externalhit_uatext.php)", wantPreview: true},
+		{name: "WhatsApp", userAgent: "WhatsApp/2.23.20.0 A", wantPreview: true},
+		{name: "Telegram", userAgent: "TelegramBot (like TwitterBot)", wantPreview: true},
+		{name: "Discord", userAgent: "Mozilla/5.0 (compatible; Discordbot/2.0; +https://discordapp.com)", wantPreview: true},
+		{name: "Twitter", userAgent: "Twitterbot/1.0", wantPreview: true},
+		{name: "LinkedIn", userAgent: "LinkedInBot/1.0 (compatible; Mozilla/5.0; Apache-HttpClient +http://www.linkedin.com)", wantPreview: true},
+		{name: "Googlebot", userAgent: "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)", wantPreview: true},
+		{name: "Apple iMessage", userAgent: "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_11_1) AppleWebKit/601.2.4 (KHTML, like Gecko) Version/9.0.1 Safari/601.2.4 facebookexternalhit/1.1 Facebot Twitterbot/1.0", wantPreview: true},
+		{name: "Teams and Skype", userAgent: "Mozilla/5.0 (Windows NT 6.1; WOW64) SkypeUriPreview Preview/0.5", wantPreview: true},
+		{name: "Google Safe Browsing", userAgent: "Mozilla/5.0 (compatible; Google-Safety; +http://www.google.com/bot.html)", wantPreview: true},
+		{name: "curl", userAgent: "curl/8.4.0", wantPreview: true},
+		{name: "Wget", userAgent: "Wget/1.21.4", wantPreview: true},
+		{name: "Chrome on Windows", userAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"},
+		{name: "Safari on iPhone", userAgent: "Mozilla/5.0 (iPhone; CPU iPhone OS 17_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.1 Mobile/15E148 Safari/604.1"},
+		{name: "Outlook desktop", userAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 Microsoft Outlook 16.0.17126"},
+		{name: "Outlook for iOS", userAgent: "Mozilla/5.0 (iPhone; CPU iPhone OS 17_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Mobile/15E148 Outlook-iOS/723.4027091.prod.iphone (4.2350.0)"},
+		{name: "Telegram in-app browser", userAgent: "Mozilla/5.0 (Linux; Android 13; Pixel 7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/119.0.0.0 Mobile Safari/537.36 Telegram-Android/10.2.0"},
+		{name: "Slack desktop", userAgent: "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Slack/4.35.126 Chrome/118.0.5993.89 Electron/27.0.2 Safari/537.36 Sonic Slack_SSB/4.35.126"},
+		{name: "Cubot phone", userAgent: "Mozilla/5.0 (Linux; Android 10; CUBOT X30) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/119.0.0.0 Mobile Safari/537.36"},
+		{name: "Facebook in-app browser", userAgent: "Mozilla/5.0 (iPhone; CPU iPhone OS 17_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Mobile/15E148 [FBAN/FBIOS;FBAV/440.0.0.33.115]"},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			request := httptest.NewRequest("GET", "/response/", nil)
+			request.Header.Set("User-Agent", testCase.userAgent)
+			if isPreview := IsLinkPreviewRequest(request); isPreview != testCase.wantPreview {
+				t.Errorf("IsLinkPreviewRequest(%q) = %t, want %t", testCase.userAgent, isPreview, testCase.wantPreview)
+			}
+		})
+	}
+}
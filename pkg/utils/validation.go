@@ -5,6 +5,7 @@ package utils
 import (
 	"errors"
 	"fmt"
+	"net/mail"
 	"strconv"
 	"time"
 
@@ -27,6 +28,21 @@ var (
 	ErrVenueNameRequired     = errors.New("venue name is required")
 	ErrVenueNameTooLong      = fmt.Errorf("venue name is too long (maximum %d characters)", config.MaxVenueNameLength)
 	ErrUserIDRequired        = errors.New("user association is required") // Added error for missing UserID
+	ErrTokenNameRequired     = errors.New("token name is required")
+	ErrTokenNameTooLong      = fmt.Errorf("token name is too long (maximum %d characters)", config.MaxTokenNameLength)
+	ErrTokenScopeInvalid     = fmt.Errorf("token scope must be '%s' or '%s'", config.TokenScopeRead, config.TokenScopeReadWrite)
+	ErrCohostEmailInvalid    = errors.New("a valid email address is required to invite a co-host")
+	ErrCohostRoleInvalid     = fmt.Errorf("co-host role must be '%s', '%s' or '%s'", config.EventRoleEditor, config.EventRoleViewer, config.EventRoleCheckIn)
+	ErrOrgNameRequired       = errors.New("organization name is required")
+	ErrOrgNameTooLong        = fmt.Errorf("organization name is too long (maximum %d characters)", config.MaxOrganizationNameLength)
+	ErrOrgMemberEmailInvalid = errors.New("a valid email address is required to invite a member")
+	ErrOrgMemberRoleInvalid  = fmt.Errorf("member role must be '%s' or '%s'", config.OrgRoleAdmin, config.OrgRoleMember)
+	ErrTransferEmailInvalid  = errors.New("a valid email address is required for the new owner")
+	ErrTransferTypeInvalid   = fmt.Errorf("only an '%s' or a '%s' can be transferred", config.TransferResourceEvent, config.TransferResourceVenue)
+	ErrLoginEmailInvalid     = errors.New("a valid email address is required to sign in")
+	ErrStoredResponseInvalid = errors.New("response is not one the application stores")
+	ErrLinkVersionInvalid    = fmt.Errorf("link version must be between 0 and %d", config.MaxImportedLinkVersion)
+	ErrViewCountInvalid      = fmt.Errorf("view count must be between 0 and %d", config.MaxImportedViewCount)
 )
 
 // IsValidationError checks if the provided error is one of the known validation errors.
@@ -39,7 +55,15 @@ func IsValidationError(err error) error {
 		errors.Is(err, ErrResponseInvalidFormat) || errors.Is(err, ErrGuestCountInvalid) ||
 		errors.Is(err, ErrGuestCountRequired) ||
 		errors.Is(err, ErrVenueNameRequired) || errors.Is(err, ErrVenueNameTooLong) ||
-		errors.Is(err, ErrUserIDRequired) {
+		errors.Is(err, ErrUserIDRequired) ||
+		errors.Is(err, ErrTokenNameRequired) || errors.Is(err, ErrTokenNameTooLong) ||
+		errors.Is(err, ErrTokenScopeInvalid) ||
+		errors.Is(err, ErrCohostEmailInvalid) || errors.Is(err, ErrCohostRoleInvalid) ||
+		errors.Is(err, ErrOrgNameRequired) || errors.Is(err, ErrOrgNameTooLong) ||
+		errors.Is(err, ErrOrgMemberEmailInvalid) || errors.Is(err, ErrOrgMemberRoleInvalid) ||
+		errors.Is(err, ErrTransferEmailInvalid) || errors.Is(err, ErrTransferTypeInvalid) ||
+		errors.Is(err, ErrLoginEmailInvalid) ||
+		errors.Is(err, ErrStoredResponseInvalid) || errors.Is(err, ErrLinkVersionInvalid) || errors.Is(err, ErrViewCountInvalid) {
 		return err
 	}
 	return nil
@@ -100,6 +124,35 @@ func ValidateRSVPResponseStatus(responseStatus string) error {
 	}
 }
 
+// ValidateStoredRSVPResponse checks that a response read from outside the application, such as an account
+// import, is one of the values the application writes to an RSVP, including the older "Yes,N" and "No" forms.
+func ValidateStoredRSVPResponse(storedResponse string) error {
+	switch storedResponse {
+	case "", config.RSVPResponsePending, config.RSVPResponseYesPrefix, config.RSVPResponseNo, config.RSVPResponseNoCommaZero,
+		config.RSVPResponseYesJustMe, config.RSVPResponseYesPlusOne, config.RSVPResponseYesPlusTwo,
+		config.RSVPResponseYesPlusThree, config.RSVPResponseYesPlusFour:
+		return nil
+	default:
+		return ErrStoredResponseInvalid
+	}
+}
+
+// ValidateLinkVersion checks that an imported invitation link version is within range.
+func ValidateLinkVersion(linkVersion int) error {
+	if linkVersion < 0 || linkVersion > config.MaxImportedLinkVersion {
+		return ErrLinkVersionInvalid
+	}
+	return nil
+}
+
+// ValidateViewCount checks that an imported invitation view count is within range.
+func ValidateViewCount(viewCount int) error {
+	if viewCount < 0 || viewCount > config.MaxImportedViewCount {
+		return ErrViewCountInvalid
+	}
+	return nil
+}
+
 // ValidateExtraGuests checks if the provided guest count is valid.
 func ValidateExtraGuests(guestCount int) error {
 	if guestCount < 0 || guestCount > config.MaxGuestCount {
@@ -119,6 +172,111 @@ func ValidateVenueName(venueName string) error {
 	return nil
 }
 
+// ValidateTokenName checks if an API token name is valid.
+func ValidateTokenName(tokenName string) error {
+	if tokenName == "" {
+		return ErrTokenNameRequired
+	}
+	if len(tokenName) > config.MaxTokenNameLength {
+		return ErrTokenNameTooLong
+	}
+	return nil
+}
+
+// ValidateTokenScope checks if an API token scope is one of the supported values.
+func ValidateTokenScope(tokenScope string) error {
+	switch tokenScope {
+	case config.TokenScopeRead, config.TokenScopeReadWrite:
+		return nil
+	default:
+		return ErrTokenScopeInvalid
+	}
+}
+
+// ValidateCohostEmail checks that a co-host invitation targets a plausible email address.
+func ValidateCohostEmail(emailAddress string) error {
+	if !isPlausibleEmail(emailAddress) {
+		return ErrCohostEmailInvalid
+	}
+	return nil
+}
+
+// isPlausibleEmail reports whether the input is a bare email address of acceptable length.
+func isPlausibleEmail(emailAddress string) bool {
+	if len(emailAddress) > config.MaxEmailLength {
+		return false
+	}
+	parsedAddress, parseError := mail.ParseAddress(emailAddress)
+	return parseError == nil && parsedAddress.Address == emailAddress
+}
+
+// ValidateCohostRole checks that a co-host role is one that can be granted by invitation.
+// The owner role cannot be granted; ownership moves only through a transfer.
+func ValidateCohostRole(cohostRole string) error {
+	switch cohostRole {
+	case config.EventRoleEditor, config.EventRoleViewer, config.EventRoleCheckIn:
+		return nil
+	default:
+		return ErrCohostRoleInvalid
+	}
+}
+
+// ValidateOrganizationName checks if an organization name is valid.
+func ValidateOrganizationName(organizationName string) error {
+	if organizationName == "" {
+		return ErrOrgNameRequired
+	}
+	if len(organizationName) > config.MaxOrganizationNameLength {
+		return ErrOrgNameTooLong
+	}
+	return nil
+}
+
+// ValidateOrgMemberEmail checks that an organization invitation targets a plausible email address.
+func ValidateOrgMemberEmail(emailAddress string) error {
+	if !isPlausibleEmail(emailAddress) {
+		return ErrOrgMemberEmailInvalid
+	}
+	return nil
+}
+
+// ValidateOrgMemberRole checks that an organization role is one that can be granted by invitation.
+// Every organization has exactly one owner, the user who created it.
+func ValidateOrgMemberRole(memberRole string) error {
+	switch memberRole {
+	case config.OrgRoleAdmin, config.OrgRoleMember:
+		return nil
+	default:
+		return ErrOrgMemberRoleInvalid
+	}
+}
+
+// ValidateTransferEmail checks that an ownership transfer targets a plausible email address.
+func ValidateTransferEmail(emailAddress string) error {
+	if !isPlausibleEmail(emailAddress) {
+		return ErrTransferEmailInvalid
+	}
+	return nil
+}
+
+// ValidateTransferType checks that the kind of item to transfer is an event or a venue.
+func ValidateTransferType(resourceType string) error {
+	switch resourceType {
+	case config.TransferResourceEvent, config.TransferResourceVenue:
+		return nil
+	default:
+		return ErrTransferTypeInvalid
+	}
+}
+
+// ValidateLoginEmail checks that a sign-in link is requested for a plausible email address.
+func ValidateLoginEmail(emailAddress string) error {
+	if !isPlausibleEmail(emailAddress) {
+		return ErrLoginEmailInvalid
+	}
+	return nil
+}
+
 // MustParseInt safely parses an integer string, returning 0 on error.
 func MustParseInt(input string) int {
 	parsedValue, parseError := strconv.Atoi(input)